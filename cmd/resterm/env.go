@@ -0,0 +1,573 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"golang.org/x/term"
+
+	"github.com/unkn0wn-root/resterm/internal/cli"
+	"github.com/unkn0wn-root/resterm/internal/envcrypt"
+	"github.com/unkn0wn-root/resterm/internal/extedit"
+	str "github.com/unkn0wn-root/resterm/internal/util"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+const plainEnvFile = "resterm.env.json"
+
+// Overridden in tests, which have no terminal and no editor.
+var (
+	envPrompt   = promptTTY
+	envRunEdit  = runExternalEditor
+	envStdinTTY = func() bool { return term.IsTerminal(int(os.Stdin.Fd())) }
+)
+
+func handleEnvSubcommand(args []string) (bool, error) {
+	if len(args) == 0 || args[0] != "env" {
+		return false, nil
+	}
+	if len(args) == 1 && cli.HasFileConflict("env") {
+		return true, cli.CommandFileConflict(
+			"resterm",
+			"env",
+			"pass a subcommand like `resterm env encrypt --in ./resterm.env.json`",
+		)
+	}
+	return true, runEnv(args[1:])
+}
+
+func runEnv(args []string) error {
+	if len(args) == 0 {
+		return errors.New(envUsageText())
+	}
+	op := str.Trim(strings.ToLower(args[0]))
+	switch op {
+	case "-h", "--help", "help":
+		if err := writeln(os.Stdout, envUsageText()); err != nil {
+			return fmt.Errorf("env: write output: %w", err)
+		}
+		return nil
+	case "encrypt":
+		return runEnvEncrypt(args[1:])
+	case "decrypt":
+		return runEnvDecrypt(args[1:])
+	case "edit":
+		return runEnvEdit(args[1:])
+	default:
+		return fmt.Errorf("env: unknown subcommand %q\n\n%s", op, envUsageText())
+	}
+}
+
+// recipientFlags are the ways to name who can decrypt: age public keys,
+// files of them, or a passphrase.
+type recipientFlags struct {
+	keys       []string
+	files      []string
+	passphrase bool
+}
+
+func (f *recipientFlags) bind(fs *flag.FlagSet) {
+	cli.StringListVarAliases(fs, &f.keys, "Encrypt to an age public key (repeatable)", "recipient", "r")
+	cli.StringListVarAliases(
+		fs,
+		&f.files,
+		"Encrypt to the public keys in a file (repeatable)",
+		"recipients-file",
+		"R",
+	)
+	fs.BoolVar(
+		&f.passphrase,
+		"passphrase",
+		false,
+		"Encrypt with a passphrase from "+envcrypt.EnvPassphrase+" or a prompt",
+	)
+}
+
+func (f *recipientFlags) set() bool {
+	return len(f.keys) > 0 || len(f.files) > 0 || f.passphrase
+}
+
+// recipients resolves the flags. Without any, the file is encrypted back to
+// the keys this machine decrypts with.
+func (f *recipientFlags) recipients(cmd string, keys envcrypt.Keys) ([]age.Recipient, error) {
+	if f.passphrase {
+		if len(f.keys) > 0 || len(f.files) > 0 {
+			return nil, fmt.Errorf("%s: --passphrase cannot be combined with recipients", cmd)
+		}
+		pass, err := newPassphrase(cmd)
+		if err != nil {
+			return nil, err
+		}
+		r, err := age.NewScryptRecipient(pass)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cmd, err)
+		}
+		return []age.Recipient{r}, nil
+	}
+	if !f.set() {
+		rcpts, err := keys.Recipients()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cmd, err)
+		}
+		return rcpts, nil
+	}
+	var out []age.Recipient
+	for _, k := range f.keys {
+		r, err := age.ParseX25519Recipient(k)
+		if err != nil {
+			return nil, fmt.Errorf("%s: recipient %q: %w", cmd, k, err)
+		}
+		out = append(out, r)
+	}
+	for _, path := range f.files {
+		rcpts, err := readRecipientsFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: recipients file %s: %w", cmd, path, err)
+		}
+		out = append(out, rcpts...)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s: no recipients found", cmd)
+	}
+	return out, nil
+}
+
+func readRecipientsFile(path string) ([]age.Recipient, error) {
+	f, err := os.Open(str.ExpandHome(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return envcrypt.ParseRecipients(f)
+}
+
+func runEnvEncrypt(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "env encrypt", os.Stderr)
+	var (
+		in, out      string
+		value, force bool
+		rf           recipientFlags
+	)
+	fs.StringVar(&in, "in", plainEnvFile, "Plain environment file")
+	fs.StringVar(&out, "out", "", "Encrypted output (default: "+envcrypt.DefaultFile+" next to --in)")
+	fs.BoolVar(&value, "value", false, "Encrypt one value from stdin and print an enc: reference")
+	fs.BoolVar(&force, "force", false, "Overwrite an existing output file")
+	rf.bind(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("env encrypt: %w", err)
+	}
+	if len(fs.Args()) > 0 {
+		return fmt.Errorf("env encrypt: unexpected args: %s", strings.Join(fs.Args(), " "))
+	}
+
+	keys, err := loadEnvKeys(!rf.set())
+	if err != nil {
+		return fmt.Errorf("env encrypt: %w", err)
+	}
+	rcpts, err := rf.recipients("env encrypt", keys)
+	if err != nil {
+		return err
+	}
+
+	if value {
+		plain, err := readValue()
+		if err != nil {
+			return fmt.Errorf("env encrypt: read stdin: %w", err)
+		}
+		ref, err := envcrypt.EncryptValue(plain, rcpts...)
+		if err != nil {
+			return fmt.Errorf("env encrypt: %w", err)
+		}
+		if err := writeln(os.Stdout, ref); err != nil {
+			return fmt.Errorf("env encrypt: write output: %w", err)
+		}
+		return nil
+	}
+
+	in = str.Trim(in)
+	if envcrypt.IsEncryptedPath(in) {
+		return fmt.Errorf("env encrypt: %s is already encrypted", in)
+	}
+	// Loading first rejects a file the catalog could not read after
+	// decryption, when the mistake is still easy to see.
+	if _, err := vars.LoadEnvironmentFile(in); err != nil {
+		return fmt.Errorf("env encrypt: %w", err)
+	}
+	out = str.Trim(out)
+	if out == "" {
+		out = filepath.Join(filepath.Dir(in), envcrypt.DefaultFile)
+	}
+	if !force {
+		if _, err := os.Stat(out); err == nil {
+			return fmt.Errorf("env encrypt: %s exists (pass --force to overwrite)", out)
+		}
+	}
+	plain, err := os.ReadFile(in)
+	if err != nil {
+		return fmt.Errorf("env encrypt: %w", err)
+	}
+	if err := writeEncrypted(out, plain, rcpts); err != nil {
+		return fmt.Errorf("env encrypt: %w", err)
+	}
+	kept, err := saveRecipients(out, rcpts)
+	if err != nil {
+		return fmt.Errorf("env encrypt: %w", err)
+	}
+	if err := writef(os.Stdout, "Encrypted %s to %s\n", in, out); err != nil {
+		return fmt.Errorf("env encrypt: write output: %w", err)
+	}
+	commit := filepath.Base(out)
+	if kept {
+		commit += " and " + filepath.Base(envcrypt.RecipientsPath(out))
+	}
+	if err := writef(
+		os.Stdout,
+		"Add %s to .gitignore and commit %s instead.\n",
+		filepath.Base(in),
+		commit,
+	); err != nil {
+		return fmt.Errorf("env encrypt: write output: %w", err)
+	}
+	return nil
+}
+
+func runEnvDecrypt(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "env decrypt", os.Stderr)
+	var (
+		in, out string
+		value   bool
+	)
+	fs.StringVar(&in, "in", envcrypt.DefaultFile, "Encrypted environment file")
+	fs.StringVar(&out, "out", "", "Plain output file (default: stdout)")
+	fs.BoolVar(&value, "value", false, "Decrypt one enc: reference from stdin")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("env decrypt: %w", err)
+	}
+	if len(fs.Args()) > 0 {
+		return fmt.Errorf("env decrypt: unexpected args: %s", strings.Join(fs.Args(), " "))
+	}
+
+	keys, err := loadEnvKeys(true)
+	if err != nil {
+		return fmt.Errorf("env decrypt: %w", err)
+	}
+
+	var plain []byte
+	if value {
+		ref, err := readValue()
+		if err != nil {
+			return fmt.Errorf("env decrypt: read stdin: %w", err)
+		}
+		ref = strings.TrimPrefix(str.Trim(ref), envcrypt.ValuePrefix)
+		v, err := envcrypt.DecryptValue(ref, keys)
+		if err != nil {
+			return fmt.Errorf("env decrypt: %w", err)
+		}
+		plain = []byte(v + "\n")
+	} else {
+		data, err := os.ReadFile(str.Trim(in))
+		if err != nil {
+			return fmt.Errorf("env decrypt: %w", err)
+		}
+		if plain, err = envcrypt.Decrypt(data, keys); err != nil {
+			return fmt.Errorf("env decrypt: %s: %w", in, err)
+		}
+	}
+
+	out = str.Trim(out)
+	if out == "" {
+		if _, err := os.Stdout.Write(plain); err != nil {
+			return fmt.Errorf("env decrypt: write output: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(out, plain, 0o600); err != nil {
+		return fmt.Errorf("env decrypt: %w", err)
+	}
+	if err := writef(os.Stderr, "Decrypted %s to %s\n", in, out); err != nil {
+		return fmt.Errorf("env decrypt: write output: %w", err)
+	}
+	return nil
+}
+
+// runEnvEdit decrypts to a private temp file, opens it in the external editor
+// and encrypts the result back. The temp file is removed on every path out.
+func runEnvEdit(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "env edit", os.Stderr)
+	var (
+		in string
+		rf recipientFlags
+	)
+	fs.StringVar(&in, "in", envcrypt.DefaultFile, "Encrypted environment file")
+	rf.bind(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("env edit: %w", err)
+	}
+	if len(fs.Args()) > 0 {
+		return fmt.Errorf("env edit: unexpected args: %s", strings.Join(fs.Args(), " "))
+	}
+	in = str.Trim(in)
+
+	keys, err := loadEnvKeys(true)
+	if err != nil {
+		return fmt.Errorf("env edit: %w", err)
+	}
+	var plain []byte
+	data, err := os.ReadFile(in)
+	switch {
+	case err == nil:
+		if plain, err = envcrypt.Decrypt(data, keys); err != nil {
+			return fmt.Errorf("env edit: %s: %w", in, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		plain = []byte("{\n  \"dev\": {}\n}\n")
+	default:
+		return fmt.Errorf("env edit: %w", err)
+	}
+	// Ask for recipients before the editor runs so a bad flag does not
+	// throw away the edit.
+	rcpts, err := editRecipients(in, data, &rf, keys)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "resterm-env-*.json")
+	if err != nil {
+		return fmt.Errorf("env edit: %w", err)
+	}
+	path := tmp.Name()
+	defer func() { _ = os.Remove(path) }()
+	_, err = tmp.Write(plain)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("env edit: %w", err)
+	}
+
+	for {
+		if err := envRunEdit(path); err != nil {
+			return fmt.Errorf("env edit: %w", err)
+		}
+		_, lerr := vars.LoadEnvironmentFile(path)
+		if lerr == nil {
+			break
+		}
+		ans, err := envPrompt(fmt.Sprintf("%v\nEdit again? [Y/n] ", lerr), false)
+		if err != nil || strings.HasPrefix(strings.ToLower(str.Trim(ans)), "n") {
+			return fmt.Errorf("env edit: %s left unchanged: %w", in, lerr)
+		}
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("env edit: %w", err)
+	}
+	if string(edited) == string(plain) && data != nil && !rf.set() {
+		if err := writef(os.Stderr, "No changes to %s\n", in); err != nil {
+			return fmt.Errorf("env edit: write output: %w", err)
+		}
+		return nil
+	}
+	if err := writeEncrypted(in, edited, rcpts); err != nil {
+		return fmt.Errorf("env edit: %w", err)
+	}
+	if _, err := saveRecipients(in, rcpts); err != nil {
+		return fmt.Errorf("env edit: %w", err)
+	}
+	if err := writef(os.Stderr, "Saved %s\n", in); err != nil {
+		return fmt.Errorf("env edit: write output: %w", err)
+	}
+	return nil
+}
+
+// editRecipients keeps who can decrypt a file across an edit. Flags replace
+// the list. Otherwise a passphrase file keeps its passphrase and a key file
+// reuses the recipients file next to it. Without that list the edit is
+// refused: encrypting back to this machine alone would drop every teammate.
+func editRecipients(
+	in string,
+	data []byte,
+	rf *recipientFlags,
+	keys envcrypt.Keys,
+) ([]age.Recipient, error) {
+	if rf.set() || data == nil {
+		return rf.recipients("env edit", keys)
+	}
+	if envcrypt.UsesPassphrase(data) {
+		rcpts, err := keys.Recipients()
+		if err != nil {
+			return nil, fmt.Errorf("env edit: %w", err)
+		}
+		return rcpts, nil
+	}
+	path := envcrypt.RecipientsPath(in)
+	rcpts, err := readRecipientsFile(path)
+	switch {
+	case err == nil && len(rcpts) > 0:
+		return rcpts, nil
+	case err == nil || errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf(
+			"env edit: %s is not listed; pass -r or -R with everyone who should decrypt %s",
+			path,
+			in,
+		)
+	default:
+		return nil, fmt.Errorf("env edit: recipients file %s: %w", path, err)
+	}
+}
+
+// saveRecipients lists the public keys path is encrypted to, for the next
+// edit. A passphrase has no public key, so a stale list is removed instead.
+// kept reports whether a list was written.
+func saveRecipients(path string, rcpts []age.Recipient) (kept bool, err error) {
+	list := envcrypt.RecipientsPath(path)
+	data, ok := envcrypt.FormatRecipients(rcpts)
+	if !ok {
+		if err := os.Remove(list); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, os.WriteFile(list, data, 0o644)
+}
+
+// loadEnvKeys reads the configured keys and, when there are none and the
+// command needs one, asks for a passphrase on the terminal.
+func loadEnvKeys(need bool) (envcrypt.Keys, error) {
+	keys, err := envcrypt.LoadKeys()
+	if err != nil || !need || !keys.Empty() || !envStdinTTY() {
+		return keys, err
+	}
+	pass, err := envPrompt("Passphrase: ", true)
+	if err != nil {
+		return keys, err
+	}
+	keys.Passphrase = pass
+	return keys, nil
+}
+
+func newPassphrase(cmd string) (string, error) {
+	if pass := os.Getenv(envcrypt.EnvPassphrase); pass != "" {
+		return pass, nil
+	}
+	if !envStdinTTY() {
+		return "", fmt.Errorf("%s: set %s or run in a terminal", cmd, envcrypt.EnvPassphrase)
+	}
+	pass, err := envPrompt("New passphrase: ", true)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", cmd, err)
+	}
+	if pass == "" {
+		return "", fmt.Errorf("%s: empty passphrase", cmd)
+	}
+	again, err := envPrompt("Repeat passphrase: ", true)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", cmd, err)
+	}
+	if again != pass {
+		return "", fmt.Errorf("%s: passphrases do not match", cmd)
+	}
+	return pass, nil
+}
+
+// writeEncrypted replaces path through a temp file in the same directory, so
+// an interrupted write never leaves half a ciphertext behind.
+func writeEncrypted(path string, plain []byte, rcpts []age.Recipient) error {
+	data, err := envcrypt.Encrypt(plain, rcpts...)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".resterm-env-*")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(name, path)
+	}
+	if err != nil {
+		_ = os.Remove(name)
+	}
+	return err
+}
+
+// readValue takes a single value from stdin, dropping the newline echo or a
+// heredoc adds.
+func readValue() (string, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	s := string(data)
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+func promptTTY(prompt string, secret bool) (string, error) {
+	if _, err := fmt.Fprint(os.Stderr, prompt); err != nil {
+		return "", err
+	}
+	if secret {
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		_, _ = fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+	return bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+func runExternalEditor(path string) error {
+	ed, err := extedit.Resolve()
+	if err != nil {
+		return err
+	}
+	cmd := ed.Exec(path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func envUsageText() string {
+	return str.Trim(`
+Usage: resterm env <encrypt|decrypt|edit> [flags]
+
+Subcommands:
+  encrypt [--in <path>] [--out <path>]   Encrypt an environment file with age
+  encrypt --value                        Encrypt stdin and print an enc: value
+  decrypt [--in <path>] [--out <path>]   Decrypt an environment file
+  decrypt --value                        Decrypt an enc: value from stdin
+  edit [--in <path>]                     Edit an encrypted file in $EDITOR
+
+Recipients (encrypt, edit):
+  -r, --recipient <age1...>     Encrypt to an age public key (repeatable)
+  -R, --recipients-file <path>  Encrypt to the keys in a file (repeatable)
+  --passphrase                  Encrypt with a passphrase instead
+
+The public keys are kept in <file>` + envcrypt.RecipientsSuffix + `, and edit encrypts to them again
+unless recipients are passed.
+
+Keys are read from ` + envcrypt.EnvIdentity + ` (an age identity file), <config dir>/` + envcrypt.IdentityFile + `,
+or ` + envcrypt.EnvPassphrase + `.
+`)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+
+	"github.com/unkn0wn-root/resterm/internal/envcrypt"
+)
+
+func TestRunEnvUnknownSubcommand(t *testing.T) {
+	if err := runEnv([]string{"rotate"}); err == nil {
+		t.Fatalf("expected error for unknown subcommand")
+	}
+}
+
+func TestRunEnvEncryptDecryptRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	setEnvKey(t, dir)
+	in := filepath.Join(dir, "resterm.env.json")
+	plain := `{"dev":{"token":"s3cret"}}` + "\n"
+	if err := os.WriteFile(in, []byte(plain), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := captureHistoryIO(t, func() error {
+		return runEnv([]string{"encrypt", "--in", in})
+	})
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	out := filepath.Join(dir, envcrypt.DefaultFile)
+	if !strings.Contains(stdout, "Add resterm.env.json to .gitignore") {
+		t.Fatalf("expected a gitignore hint, got %q", stdout)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read encrypted file: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Fatalf("encrypted file contains plaintext")
+	}
+
+	if err := runEnv([]string{"encrypt", "--in", in}); err == nil ||
+		!strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected overwrite refusal, got %v", err)
+	}
+
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runEnv([]string{"decrypt", "--in", out})
+	})
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if stdout != plain {
+		t.Fatalf("decrypt = %q, want %q", stdout, plain)
+	}
+}
+
+func TestRunEnvEncryptRejectsInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	setEnvKey(t, dir)
+	in := filepath.Join(dir, "resterm.env.json")
+	if err := os.WriteFile(in, []byte(`{"dev":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := runEnv([]string{"encrypt", "--in", in}); err == nil {
+		t.Fatalf("expected a parse error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, envcrypt.DefaultFile)); !os.IsNotExist(err) {
+		t.Fatalf("nothing should be written for an invalid file, stat err %v", err)
+	}
+}
+
+func TestRunEnvEditReencrypts(t *testing.T) {
+	dir := t.TempDir()
+	setEnvKey(t, dir)
+	path := filepath.Join(dir, envcrypt.DefaultFile)
+
+	var edited string
+	old := envRunEdit
+	envRunEdit = func(p string) error {
+		edited = p
+		return os.WriteFile(p, []byte(`{"dev":{"token":"edited"}}`), 0o600)
+	}
+	t.Cleanup(func() { envRunEdit = old })
+
+	if _, _, err := captureHistoryIO(t, func() error {
+		return runEnv([]string{"edit", "--in", path})
+	}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if _, err := os.Stat(edited); !os.IsNotExist(err) {
+		t.Fatalf("temp file %s was left behind", edited)
+	}
+	keys, err := envcrypt.LoadKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read encrypted file: %v", err)
+	}
+	plain, err := envcrypt.Decrypt(data, keys)
+	if err != nil || string(plain) != `{"dev":{"token":"edited"}}` {
+		t.Fatalf("edited file = %q, %v", plain, err)
+	}
+}
+
+func TestRunEnvEditKeepsRecipients(t *testing.T) {
+	dir := t.TempDir()
+	setEnvKey(t, dir)
+	keys, err := envcrypt.LoadKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	self := keys.Identities[0].(*age.X25519Identity).Recipient().String()
+	mate, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := filepath.Join(dir, "resterm.env.json")
+	if err := os.WriteFile(in, []byte(`{"dev":{"token":"a"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, envcrypt.DefaultFile)
+	if _, _, err := captureHistoryIO(t, func() error {
+		return runEnv([]string{"encrypt", "--in", in, "-r", self, "-r", mate.Recipient().String()})
+	}); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	old := envRunEdit
+	envRunEdit = func(p string) error {
+		return os.WriteFile(p, []byte(`{"dev":{"token":"b"}}`), 0o600)
+	}
+	t.Cleanup(func() { envRunEdit = old })
+	if _, _, err := captureHistoryIO(t, func() error {
+		return runEnv([]string{"edit", "--in", out})
+	}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := envcrypt.Decrypt(data, envcrypt.Keys{Identities: []age.Identity{mate}})
+	if err != nil || string(plain) != `{"dev":{"token":"b"}}` {
+		t.Fatalf("teammate decrypt after edit = %q, %v", plain, err)
+	}
+
+	// Without the list, edit refuses rather than re-keying to this machine.
+	if err := os.Remove(envcrypt.RecipientsPath(out)); err != nil {
+		t.Fatal(err)
+	}
+	if err := runEnv([]string{"edit", "--in", out}); err == nil ||
+		!strings.Contains(err.Error(), "-r or -R") {
+		t.Fatalf("edit without recipients = %v, want a refusal", err)
+	}
+}
+
+func setEnvKey(t *testing.T, dir string) {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "age.key")
+	if err := os.WriteFile(path, []byte(id.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envcrypt.EnvIdentity, path)
+	t.Setenv(envcrypt.EnvPassphrase, "")
+}
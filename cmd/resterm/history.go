@@ -1,17 +1,24 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/unkn0wn-root/resterm/internal/analysis"
 	"github.com/unkn0wn-root/resterm/internal/cli"
+	"github.com/unkn0wn-root/resterm/internal/collection"
 	"github.com/unkn0wn-root/resterm/internal/config"
+	"github.com/unkn0wn-root/resterm/internal/duration"
 	"github.com/unkn0wn-root/resterm/internal/history"
 	histdb "github.com/unkn0wn-root/resterm/internal/history/sqlite"
 	str "github.com/unkn0wn-root/resterm/internal/util"
@@ -50,6 +57,14 @@ func runHistory(args []string) error {
 		return runHistoryBackup(args[1:])
 	case "stats":
 		return runHistoryStats(args[1:])
+	case "search":
+		return runHistorySearch(args[1:])
+	case "trends":
+		return runHistoryTrends(args[1:])
+	case "share":
+		return runHistoryShare(args[1:])
+	case "prune":
+		return runHistoryPrune(args[1:])
 	case "compact", "vacuum":
 		return runHistoryCompact(args[1:])
 	case "check":
@@ -192,6 +207,8 @@ func runHistoryStats(args []string) error {
 	if !st.Newest.IsZero() {
 		_, _ = fmt.Fprintf(&b, "Newest: %s\n", st.Newest.UTC().Format(time.RFC3339))
 	}
+	_, _ = fmt.Fprintf(&b, "Captures: %d\n", st.Captures)
+	_, _ = fmt.Fprintf(&b, "Capture Blobs: %d (%s)\n", st.Blobs, byteLabel(st.BlobBytes))
 	_, _ = fmt.Fprintf(&b, "DB Size: %s\n", byteLabel(st.DBBytes))
 	_, _ = fmt.Fprintf(&b, "WAL Size: %s\n", byteLabel(st.WALBytes))
 	_, _ = fmt.Fprintf(&b, "SHM Size: %s\n", byteLabel(st.SHMBytes))
@@ -201,6 +218,322 @@ func runHistoryStats(args []string) error {
 	return nil
 }
 
+func runHistorySearch(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "history search", os.Stderr)
+	var (
+		format string
+		limit  int
+	)
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	fs.IntVar(&limit, "limit", 50, "Maximum entries to print (0 for all)")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("history search: %w", err)
+	}
+	format = str.Trim(strings.ToLower(format))
+	if format != "text" && format != "json" {
+		return fmt.Errorf("history search: unknown format %q (want text or json)", format)
+	}
+	q, err := history.ParseQuery(strings.Join(pos, " "), time.Now())
+	if err != nil {
+		return fmt.Errorf("history search: %w", err)
+	}
+
+	s, err := openHistoryStore(true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	es, err := history.Search(s, q, limit)
+	if err != nil {
+		return fmt.Errorf("history search: %w", err)
+	}
+	if format == "json" {
+		err = writeHistoryJSON(os.Stdout, es)
+	} else {
+		err = writeHistoryTable(os.Stdout, es)
+	}
+	if err != nil {
+		return fmt.Errorf("history search: write output: %w", err)
+	}
+	return nil
+}
+
+func writeHistoryJSON(w io.Writer, es []history.Entry) error {
+	if es == nil {
+		es = []history.Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(es)
+}
+
+func writeHistoryTable(w io.Writer, es []history.Entry) error {
+	if len(es) == 0 {
+		return writeln(w, "No matching history entries.")
+	}
+	var b strings.Builder
+	for _, e := range es {
+		target := str.Trim(e.RequestName)
+		if target == "" {
+			target = e.URL
+		}
+		env := str.Trim(e.Environment)
+		if env == "" {
+			env = "-"
+		}
+		_, _ = fmt.Fprintf(
+			&b,
+			"%-19s  %s  %-7s %3d %9s  %-12s %s\n",
+			e.ID,
+			e.ExecutedAt.Local().Format("2006-01-02 15:04:05"),
+			e.Method,
+			e.StatusCode,
+			e.Duration.Round(time.Millisecond),
+			env,
+			target,
+		)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func runHistoryTrends(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "history trends", os.Stderr)
+	var (
+		request string
+		env     string
+		since   string
+		recent  int
+		format  string
+	)
+	fs.StringVar(&request, "request", "", "Request name or URL")
+	fs.StringVar(&env, "env", "", "Only runs recorded in this environment")
+	fs.StringVar(&since, "since", "", "Ignore runs older than this, e.g. 30d")
+	fs.IntVar(&recent, "recent", 0, "Runs in the recent window (default a third)")
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("history trends: %w", err)
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("history trends: unexpected args: %s", strings.Join(pos, " "))
+	}
+	request = str.Trim(request)
+	if request == "" {
+		return errors.New("history trends: --request is required")
+	}
+	format = str.Trim(strings.ToLower(format))
+	if format != "text" && format != "json" {
+		return fmt.Errorf("history trends: unknown format %q (want text or json)", format)
+	}
+	var window time.Duration
+	if str.Trim(since) != "" {
+		d, ok := duration.Parse(since)
+		if !ok || d <= 0 {
+			return fmt.Errorf("history trends: invalid --since %q", since)
+		}
+		window = d
+	}
+	if recent < 0 {
+		return fmt.Errorf("history trends: invalid --recent %d", recent)
+	}
+
+	s, err := openHistoryStore(true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	es, err := history.TrendEntries(s, request, env)
+	if err != nil {
+		return fmt.Errorf("history trends: %w", err)
+	}
+	samples := history.TrendSince(history.TrendSamples(es), window, time.Now())
+	tr := analysis.ComputeTrend(samples, analysis.TrendOptions{Recent: recent})
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(tr)
+	} else {
+		err = writeTrendReport(os.Stdout, request, env, tr)
+	}
+	if err != nil {
+		return fmt.Errorf("history trends: write output: %w", err)
+	}
+	if len(tr.Regressions()) > 0 {
+		return cli.ExitErr{Code: 1}
+	}
+	return nil
+}
+
+func writeTrendReport(w io.Writer, request, env string, tr analysis.Trend) error {
+	label := request
+	if env = str.Trim(env); env != "" {
+		label += " (" + env + ")"
+	}
+	if tr.Samples == 0 {
+		return writef(w, "No recorded runs for %s.\n", label)
+	}
+	var b strings.Builder
+	_, _ = fmt.Fprintf(
+		&b,
+		"Trend for %s: %d runs, %s to %s\n",
+		label,
+		tr.Samples,
+		tr.First.Local().Format("2006-01-02 15:04"),
+		tr.Last.Local().Format("2006-01-02 15:04"),
+	)
+	total := tr.Metrics[0]
+	_, _ = fmt.Fprintf(
+		&b,
+		"Baseline %d runs vs recent %d runs (alpha %g, min shift %.0f%%)\n\n",
+		total.Baseline.Count,
+		total.Recent.Count,
+		tr.Options.Alpha,
+		tr.Options.MinShift*100,
+	)
+
+	_, _ = fmt.Fprintf(&b, "%-16s %10s %10s %10s %10s %8s %8s\n",
+		"METRIC", "BASE P50", "NOW P50", "BASE P90", "NOW P90", "SHIFT", "P")
+	for _, m := range tr.Metrics {
+		p := "-"
+		switch {
+		case !m.Tested:
+		case m.P < 0.0001:
+			p = "<0.0001"
+		default:
+			p = fmt.Sprintf("%.4f", m.P)
+		}
+		_, _ = fmt.Fprintf(
+			&b,
+			"%-16s %10s %10s %10s %10s %+7.1f%% %8s",
+			m.Name,
+			trendDur(m.Baseline.Median),
+			trendDur(m.Recent.Median),
+			trendDur(m.Baseline.Percentiles[90]),
+			trendDur(m.Recent.Percentiles[90]),
+			m.Shift*100,
+			p,
+		)
+		if m.Regressed {
+			b.WriteString("  REGRESSED")
+		}
+		b.WriteByte('\n')
+	}
+
+	b.WriteString("\nTotal latency bands:\n")
+	for _, bk := range tr.Buckets {
+		_, _ = fmt.Fprintf(
+			&b,
+			"  %s  n=%-4d p50 %-9s p90 %-9s p99 %s\n",
+			bk.Start.Local().Format("2006-01-02 15:04"),
+			bk.Count,
+			trendDur(bk.P50),
+			trendDur(bk.P90),
+			trendDur(bk.P99),
+		)
+	}
+
+	if regs := tr.Regressions(); len(regs) > 0 {
+		names := make([]string, len(regs))
+		for i, m := range regs {
+			names[i] = m.Name
+		}
+		_, _ = fmt.Fprintf(&b, "\nRegressed: %s\n", strings.Join(names, ", "))
+	} else {
+		b.WriteString("\nNo significant regressions.\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// trendDur keeps sub-millisecond phases readable while rounding the rest.
+func trendDur(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	if d < 10*time.Millisecond {
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+func runHistoryShare(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "history share", os.Stderr)
+	var (
+		out   string
+		force bool
+	)
+	fs.StringVar(&out, "out", "", "Bundle archive to write")
+	fs.BoolVar(&force, "force", false, "Overwrite an existing archive")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("history share: %w", err)
+	}
+	if len(pos) != 1 {
+		return errors.New("history share: expected one entry id (see resterm history search)")
+	}
+	id := str.Trim(pos[0])
+	out = str.Trim(out)
+	if out == "" {
+		out = "resterm-share-" + id + ".zip"
+	}
+
+	s, err := openHistoryStore(true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	es, err := s.Entries()
+	if err != nil {
+		return fmt.Errorf("history share: %w", err)
+	}
+	i := slices.IndexFunc(es, func(e history.Entry) bool { return e.ID == id })
+	if i < 0 {
+		return fmt.Errorf("history share: no history entry %q", id)
+	}
+	c, err := history.LoadCapture(s, es[i])
+	if err != nil {
+		return fmt.Errorf("history share: %w", err)
+	}
+	res, err := collection.ShareEntry(collection.ShareOptions{
+		Entry:   es[i],
+		Capture: c,
+		OutFile: out,
+		Force:   force,
+	})
+	if err != nil {
+		return fmt.Errorf("history share: %w", err)
+	}
+	if err := writeln(os.Stdout, shareSummary(res)); err != nil {
+		return fmt.Errorf("history share: write output: %w", err)
+	}
+	return nil
+}
+
+func shareSummary(res collection.ShareResult) string {
+	msg := fmt.Sprintf("Wrote %s (%d files)", res.OutFile, res.FileCount)
+	if len(res.Placeholders) > 0 {
+		msg += fmt.Sprintf(
+			"; recipients fill in %s in resterm.env.json",
+			strings.Join(res.Placeholders, ", "),
+		)
+	}
+	return msg
+}
+
 func runHistoryCompact(args []string) error {
 	fs := cli.NewSubcommandFlagSet("resterm", "history compact", os.Stderr)
 	if err := fs.Parse(args); err != nil {
@@ -213,6 +546,10 @@ func runHistoryCompact(args []string) error {
 		return fmt.Errorf("history compact: unexpected args: %s", strings.Join(fs.Args(), " "))
 	}
 
+	ret, err := loadHistoryRetention()
+	if err != nil {
+		return fmt.Errorf("history compact: %w", err)
+	}
 	s, err := openHistoryStore(true)
 	if err != nil {
 		return err
@@ -224,9 +561,19 @@ func runHistoryCompact(args []string) error {
 		return err
 	}
 
+	s.SetRetention(ret)
+	rep, err := s.Prune(false)
+	if err != nil {
+		return err
+	}
 	if err = s.Compact(); err != nil {
 		return err
 	}
+	if rep.Entries > 0 {
+		if err := writeln(os.Stdout, pruneSummary(rep, false)); err != nil {
+			return fmt.Errorf("history compact: write output: %w", err)
+		}
+	}
 
 	a, err := s.Stats()
 	if err != nil {
@@ -244,6 +591,68 @@ func runHistoryCompact(args []string) error {
 	return nil
 }
 
+func runHistoryPrune(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "history prune", os.Stderr)
+	var dryRun bool
+	fs.BoolVar(&dryRun, "dry-run", false, "Report what would be removed without removing it")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("history prune: %w", err)
+	}
+	if len(fs.Args()) > 0 {
+		return fmt.Errorf("history prune: unexpected args: %s", strings.Join(fs.Args(), " "))
+	}
+	ret, err := loadHistoryRetention()
+	if err != nil {
+		return fmt.Errorf("history prune: %w", err)
+	}
+	if !ret.Enabled() {
+		if err := writeln(
+			os.Stdout,
+			"No history retention configured. Set max_age, max_entries_per_request or max_size_mb under [history] in settings.toml.",
+		); err != nil {
+			return fmt.Errorf("history prune: write output: %w", err)
+		}
+		return nil
+	}
+
+	s, err := openHistoryStore(true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	s.SetRetention(ret)
+	rep, err := s.Prune(dryRun)
+	if err != nil {
+		return err
+	}
+	if err := writeln(os.Stdout, pruneSummary(rep, dryRun)); err != nil {
+		return fmt.Errorf("history prune: write output: %w", err)
+	}
+	return nil
+}
+
+func pruneSummary(rep history.PruneReport, dryRun bool) string {
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	return fmt.Sprintf(
+		"%s %d entries (age %d, per-request %d, size %d) and %d capture blobs, %s of stored data; %d kept",
+		verb,
+		rep.Entries,
+		rep.ByAge,
+		rep.ByCount,
+		rep.BySize,
+		rep.Blobs,
+		byteLabel(rep.Bytes),
+		rep.Kept,
+	)
+}
+
 func runHistoryCheck(args []string) error {
 	fs := cli.NewSubcommandFlagSet("resterm", "history check", os.Stderr)
 	var full bool
@@ -303,17 +712,86 @@ func openHistoryStore(migrate bool) (history.MaintenanceStore, error) {
 	return s, nil
 }
 
+// historyCaptureOptions maps the settings file onto the store. A bad
+// retention is logged and ignored so capture still works.
+func historyCaptureOptions(h config.HistorySettings) history.CaptureOptions {
+	opt := history.CaptureOptions{Enabled: h.Capture, MaxBody: h.CaptureMaxBytes()}
+	ret, err := h.CaptureRetentionDuration()
+	if err != nil {
+		log.Printf("history settings: %v", err)
+	}
+	opt.Retention = ret
+	return opt
+}
+
+// loadHistoryRetention reads the retention policy from the settings file.
+func loadHistoryRetention() (history.Retention, error) {
+	st, _, err := config.LoadSettings()
+	if err != nil {
+		return history.Retention{}, err
+	}
+	return historyRetention(st.History)
+}
+
+// historyRetention maps the settings file onto a retention policy. Invalid
+// limits are reported and left off; the rest still apply.
+func historyRetention(h config.HistorySettings) (history.Retention, error) {
+	var errs []error
+	age := func(d time.Duration, err error) time.Duration {
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return d
+	}
+	r := history.Retention{
+		RetentionLimits: history.RetentionLimits{
+			MaxAge:        max(age(h.MaxAgeDuration()), 0),
+			MaxPerRequest: max(h.MaxEntriesPerRequest, 0),
+			FailureMaxAge: max(age(h.FailureMaxAgeDuration()), 0),
+		},
+		MaxBytes: h.MaxSizeBytes(),
+	}
+	for _, w := range h.Workspaces {
+		root := str.Trim(str.ExpandHome(str.Trim(w.Path)))
+		if root == "" {
+			errs = append(errs, errors.New("history workspace without a path"))
+			continue
+		}
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		r.Workspaces = append(r.Workspaces, history.WorkspaceRetention{
+			Root: root,
+			RetentionLimits: history.RetentionLimits{
+				MaxAge:        age(w.MaxAgeDuration()),
+				MaxPerRequest: w.MaxEntriesPerRequest,
+				FailureMaxAge: age(w.FailureMaxAgeDuration()),
+			},
+		})
+	}
+	return r, errors.Join(errs...)
+}
+
 func historyUsageText() string {
 	return str.Trim(`
-Usage: resterm history <export|import|backup|stats|check|compact> [flags]
+Usage: resterm history <export|import|backup|stats|search|trends|share|prune|check|compact> [flags]
 
 Subcommands:
   export --out <path>   Export history to JSON
   import --in <path>    Import history from JSON
   backup --out <path>   Create a SQLite-consistent backup
   stats                 Show history DB stats
+  search <query>        Find entries, e.g. 'status:5xx env:prod body:"timeout"'
+                        [--format text|json] [--limit N]
+  trends --request <name>
+                        Latency percentiles over time with regression checks
+                        [--env <name>] [--since 30d] [--recent N]
+                        [--format text|json]
+  share <id> [--out <path>]
+                        Bundle an entry as a redacted, runnable bug report
+  prune [--dry-run]     Apply the retention settings now
   check [--full]        Run SQLite integrity check
-  compact               Run VACUUM and checkpoint
+  compact               Apply retention, then run VACUUM and checkpoint
 `)
 }
 
@@ -2,13 +2,17 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/unkn0wn-root/resterm/internal/analysis"
+	"github.com/unkn0wn-root/resterm/internal/cli"
 	"github.com/unkn0wn-root/resterm/internal/history"
 	histdb "github.com/unkn0wn-root/resterm/internal/history/sqlite"
 	str "github.com/unkn0wn-root/resterm/internal/util"
@@ -50,7 +54,7 @@ func TestRunHistoryHelpFlagShowsUsage(t *testing.T) {
 	}
 	if !strings.Contains(
 		stdout,
-		"Usage: resterm history <export|import|backup|stats|check|compact> [flags]",
+		"Usage: resterm history <export|import|backup|stats|search|trends|share|prune|check|compact> [flags]",
 	) {
 		t.Fatalf("expected history usage in stdout, got %q", stdout)
 	}
@@ -304,6 +308,234 @@ func TestRunHistorySubcommandHelpShowsUsage(t *testing.T) {
 	}
 }
 
+func TestRunHistorySearch(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RESTERM_CONFIG_DIR", dir)
+
+	s := histdb.New(filepath.Join(dir, "history.db"))
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for _, e := range []history.Entry{
+		{
+			ID:          "1",
+			ExecutedAt:  at,
+			Environment: "prod",
+			Method:      "POST",
+			URL:         "https://pay.test/charges",
+			StatusCode:  502,
+			BodySnippet: `{"error":"insufficient funds"}`,
+		},
+		{ID: "2", ExecutedAt: at.Add(time.Minute), Environment: "prod", Method: "GET", StatusCode: 200},
+	} {
+		if err := s.Append(e); err != nil {
+			t.Fatalf("append %s: %v", e.ID, err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	stdout, _, err := captureHistoryIO(t, func() error {
+		return runHistory([]string{"search", `status:5xx env:prod body:"insufficient funds"`, "--format", "json"})
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	var got []history.Entry
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("decode search output %q: %v", stdout, err)
+	}
+	if len(got) != 1 || got[0].ID != "1" {
+		t.Fatalf("unexpected search result: %+v", got)
+	}
+
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runHistory([]string{"search", "method:get"})
+	})
+	if err != nil {
+		t.Fatalf("search text: %v", err)
+	}
+	if !strings.Contains(stdout, "GET") || strings.Contains(stdout, "charges") {
+		t.Fatalf("unexpected text output: %q", stdout)
+	}
+
+	if err := runHistory([]string{"search", "status:abc"}); err == nil {
+		t.Fatalf("expected invalid query error")
+	}
+}
+
+func TestRunHistoryTrends(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RESTERM_CONFIG_DIR", dir)
+
+	s := histdb.New(filepath.Join(dir, "history.db"))
+	at := time.Now().Add(-29*time.Hour - 30*time.Minute)
+	for i := range 30 {
+		d := time.Duration(100+i%5) * time.Millisecond
+		if i >= 20 {
+			d += 80 * time.Millisecond
+		}
+		e := history.Entry{
+			ID:          strconv.Itoa(i),
+			ExecutedAt:  at.Add(time.Duration(i) * time.Hour),
+			Environment: "prod",
+			RequestName: "GetUser",
+			Method:      "GET",
+			StatusCode:  200,
+			Duration:    d,
+		}
+		if err := s.Append(e); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	if err := s.Append(history.Entry{
+		ID:          "dev",
+		ExecutedAt:  at,
+		Environment: "dev",
+		RequestName: "GetUser",
+		Method:      "GET",
+		Duration:    time.Second,
+	}); err != nil {
+		t.Fatalf("append dev: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	stdout, _, err := captureHistoryIO(t, func() error {
+		return runHistory([]string{"trends", "--request", "GetUser", "--env", "prod", "--recent", "10"})
+	})
+	var exit cli.ExitErr
+	if !errors.As(err, &exit) || exit.Code != 1 {
+		t.Fatalf("expected regression exit code 1, got %v", err)
+	}
+	if !strings.Contains(stdout, "Trend for GetUser (prod): 30 runs") ||
+		!strings.Contains(stdout, "REGRESSED") || !strings.Contains(stdout, "Regressed: total") {
+		t.Fatalf("unexpected text output: %q", stdout)
+	}
+
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runHistory([]string{
+			"trends", "--request", "GetUser", "--env", "prod", "--since", "12h", "--format", "json",
+		})
+	})
+	if err != nil {
+		t.Fatalf("trends json: %v", err)
+	}
+	var tr analysis.Trend
+	if err := json.Unmarshal([]byte(stdout), &tr); err != nil {
+		t.Fatalf("decode trends output %q: %v", stdout, err)
+	}
+	if tr.Samples != 12 || len(tr.Regressions()) != 0 {
+		t.Fatalf("unexpected trend: %+v", tr)
+	}
+
+	if err := runHistory([]string{"trends"}); err == nil {
+		t.Fatalf("expected missing --request error")
+	}
+}
+
+func TestRunHistoryPrune(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RESTERM_CONFIG_DIR", dir)
+
+	stdout, _, err := captureHistoryIO(t, func() error {
+		return runHistory([]string{"prune"})
+	})
+	if err != nil || !strings.Contains(stdout, "No history retention configured") {
+		t.Fatalf("prune without settings: %q, %v", stdout, err)
+	}
+
+	s := histdb.New(filepath.Join(dir, "history.db"))
+	at := time.Now().Add(-time.Hour)
+	for i := range 3 {
+		if err := s.Append(history.Entry{
+			ID:          strconv.Itoa(i),
+			ExecutedAt:  at.Add(time.Duration(i) * time.Minute),
+			RequestName: "GetUser",
+			Method:      "GET",
+			StatusCode:  200,
+		}); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	settings := "[history]\nmax_entries_per_request = 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "settings.toml"), []byte(settings), 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runHistory([]string{"prune", "--dry-run"})
+	})
+	if err != nil || !strings.Contains(stdout, "Would remove 2 entries (age 0, per-request 2, size 0)") {
+		t.Fatalf("dry run: %q, %v", stdout, err)
+	}
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runHistory([]string{"compact"})
+	})
+	if err != nil || !strings.Contains(stdout, "Removed 2 entries") ||
+		!strings.Contains(stdout, "Compacted history db") {
+		t.Fatalf("compact: %q, %v", stdout, err)
+	}
+
+	s = histdb.New(filepath.Join(dir, "history.db"))
+	defer func() { _ = s.Close() }()
+	es, err := s.Entries()
+	if err != nil || len(es) != 1 || es[0].ID != "2" {
+		t.Fatalf("entries after prune = %+v, %v", es, err)
+	}
+}
+
+func TestRunHistoryShare(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RESTERM_CONFIG_DIR", dir)
+
+	s := histdb.New(filepath.Join(dir, "history.db"))
+	if err := s.Append(history.Entry{
+		ID:          "1700000000000000001",
+		ExecutedAt:  time.Now(),
+		RequestName: "GetUser",
+		Method:      "GET",
+		URL:         "https://api.example.com/users/1",
+		Status:      "500 Internal Server Error",
+		StatusCode:  500,
+		RequestText: "GET https://api.example.com/users/1\nAuthorization: ***\n",
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	stdout, _, err := captureHistoryIO(t, func() error {
+		return runHistory([]string{"search", "status:5xx"})
+	})
+	if err != nil || !strings.HasPrefix(stdout, "1700000000000000001  ") {
+		t.Fatalf("search should lead with the entry id: %q, %v", stdout, err)
+	}
+
+	out := filepath.Join(t.TempDir(), "bug.zip")
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runHistory([]string{"share", "1700000000000000001", "--out", out})
+	})
+	if err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	if !strings.Contains(stdout, "Wrote "+out) || !strings.Contains(stdout, "fill in authorization") {
+		t.Fatalf("unexpected share output: %q", stdout)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Fatalf("bundle not written: %v", err)
+	}
+
+	if err := runHistory([]string{"share", "missing", "--out", out, "--force"}); err == nil ||
+		!strings.Contains(err.Error(), "no history entry") {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+}
+
 func captureHistoryIO(t *testing.T, fn func() error) (string, string, error) {
 	t.Helper()
 
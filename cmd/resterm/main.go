@@ -19,15 +19,19 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/unkn0wn-root/resterm/internal/bindings"
+	"github.com/unkn0wn-root/resterm/internal/bruno"
 	"github.com/unkn0wn-root/resterm/internal/cli"
 	"github.com/unkn0wn-root/resterm/internal/config"
 	curl "github.com/unkn0wn-root/resterm/internal/curl/importer"
 	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/har"
 	histdb "github.com/unkn0wn-root/resterm/internal/history/sqlite"
+	"github.com/unkn0wn-root/resterm/internal/insomnia"
 	"github.com/unkn0wn-root/resterm/internal/openapi"
 	"github.com/unkn0wn-root/resterm/internal/openapi/generator"
 	"github.com/unkn0wn-root/resterm/internal/openapi/parser"
 	"github.com/unkn0wn-root/resterm/internal/openapi/writer"
+	"github.com/unkn0wn-root/resterm/internal/postman"
 	"github.com/unkn0wn-root/resterm/internal/rtfmt"
 	"github.com/unkn0wn-root/resterm/internal/tlsconfig"
 	"github.com/unkn0wn-root/resterm/internal/ui"
@@ -42,6 +46,7 @@ var (
 )
 
 func main() {
+	quietQUIC()
 	if err := run(os.Args[1:]); err != nil {
 		if !cli.IsExitCodeOnly(err) {
 			if msg := diag.Render(err); msg != "" {
@@ -52,6 +57,16 @@ func main() {
 	}
 }
 
+// quietQUIC stops quic-go from logging an undersized UDP receive buffer to
+// stderr, where it would draw over the TUI. The buffer only limits HTTP/3
+// throughput; setting the variable yourself keeps the warning.
+func quietQUIC() {
+	const key = "QUIC_GO_DISABLE_RECEIVE_BUFFER_WARNING"
+	if _, ok := os.LookupEnv(key); !ok {
+		_ = os.Setenv(key, "true")
+	}
+}
+
 func run(a []string) error {
 	if ok, err := handleMockSubcommand(a); ok {
 		return err
@@ -62,12 +77,18 @@ func run(a []string) error {
 	if ok, err := handleHistorySubcommand(a); ok {
 		return err
 	}
+	if ok, err := handleEnvSubcommand(a); ok {
+		return err
+	}
 	if ok, err := handleInitSubcommand(a); ok {
 		return err
 	}
 	if ok, err := handleRunSubcommand(a); ok {
 		return err
 	}
+	if ok, err := handleOpenAPISubcommand(a); ok {
+		return err
+	}
 
 	var (
 		filePath                 string
@@ -76,6 +97,15 @@ func run(a []string) error {
 		doUpdate                 bool
 		curlSrc                  string
 		openapiSpec              string
+		postmanSrc               string
+		postmanEnvs              []string
+		postmanFolders           string
+		insomniaSrc              string
+		brunoSrc                 string
+		harSrc                   string
+		harHosts                 []string
+		harExcludeStatic         bool
+		harMode                  string
 		httpOut                  string
 		openapiBase              string
 		openapiResolveRefs       bool
@@ -121,11 +151,74 @@ func run(a []string) error {
 		"from-openapi",
 		"fo",
 	)
+	cli.StringVarAliases(
+		fs,
+		&postmanSrc,
+		"",
+		"Path to a Postman v2.1 collection to convert",
+		"from-postman",
+		"fp",
+	)
+	cli.StringListVarAliases(
+		fs,
+		&postmanEnvs,
+		"Postman environment to merge into resterm.env.json (repeatable)",
+		"postman-env",
+	)
+	cli.StringVarAliases(
+		fs,
+		&postmanFolders,
+		string(postman.LayoutFiles),
+		"Postman folder layout: files (one .http per top-level folder) or tags",
+		"postman-folders",
+	)
+	cli.StringVarAliases(
+		fs,
+		&insomniaSrc,
+		"",
+		"Path to an Insomnia export (v4 JSON or v5 YAML) to convert",
+		"from-insomnia",
+	)
+	cli.StringVarAliases(
+		fs,
+		&brunoSrc,
+		"",
+		"Path to a Bruno collection directory to convert",
+		"from-bruno",
+	)
+	cli.StringVarAliases(
+		fs,
+		&harSrc,
+		"",
+		"Path to a HAR capture (browser devtools export) to convert",
+		"from-har",
+		"fh",
+	)
+	cli.StringListVarAliases(
+		fs,
+		&harHosts,
+		"Keep only HAR entries for this host; *.example.com matches subdomains (repeatable)",
+		"har-include-host",
+	)
+	cli.BoolVarAliases(
+		fs,
+		&harExcludeStatic,
+		false,
+		"Drop scripts, stylesheets, images, fonts and media from a HAR capture",
+		"har-exclude-static",
+	)
+	cli.StringVarAliases(
+		fs,
+		&harMode,
+		string(har.ModeRequests),
+		"HAR output mode: requests, mocks, or both",
+		"har-mode",
+	)
 	cli.StringVarAliases(
 		fs,
 		&httpOut,
 		"",
-		"Destination path for generated .http file",
+		"Destination path for generated .http file (a directory for --postman-folders files)",
 		"http-out",
 		"o",
 	)
@@ -246,8 +339,15 @@ func run(a []string) error {
 		return nil
 	}
 
-	if curlSrc != "" && openapiSpec != "" {
-		return errors.New("import error: choose either --from-curl or --from-openapi")
+	if err := importConflict(
+		[2]string{"--from-curl", curlSrc},
+		[2]string{"--from-openapi", openapiSpec},
+		[2]string{"--from-postman", postmanSrc},
+		[2]string{"--from-insomnia", insomniaSrc},
+		[2]string{"--from-bruno", brunoSrc},
+		[2]string{"--from-har", harSrc},
+	); err != nil {
+		return err
 	}
 
 	if curlSrc != "" {
@@ -330,6 +430,103 @@ func run(a []string) error {
 		return nil
 	}
 
+	if postmanSrc != "" {
+		layout, err := postman.ParseLayout(postmanFolders)
+		if err != nil {
+			return fmt.Errorf("postman import error: %w", err)
+		}
+		targetOut := httpOut
+		if targetOut == "" {
+			targetOut = defaultPostmanOutputPath(postmanSrc, layout)
+		}
+		opts := postman.Options{
+			Layout:       layout,
+			Environments: postmanEnvs,
+			Write: postman.WriterOptions{
+				HeaderComment:     fmt.Sprintf("Generated by resterm %s", version),
+				OverwriteExisting: true,
+			},
+		}
+		svc := postman.Service{Writer: postman.NewFileWriter()}
+		res, err := svc.Import(context.Background(), postmanSrc, targetOut, opts)
+		if err != nil {
+			return fmt.Errorf("postman import error: %w", err)
+		}
+		for _, f := range res.Files {
+			_ = rtfmt.Fprintf(os.Stdout, "Generated %s from Postman\n", nil, f)
+		}
+		if res.EnvFile != "" {
+			_ = rtfmt.Fprintf(os.Stdout, "Updated %s\n", nil, res.EnvFile)
+		}
+		return nil
+	}
+
+	if insomniaSrc != "" {
+		targetOut := httpOut
+		if targetOut == "" {
+			targetOut = defaultHTTPOutputPath(insomniaSrc)
+		}
+		svc := insomnia.Service{Writer: insomnia.NewFileWriter()}
+		res, err := svc.Import(context.Background(), insomniaSrc, targetOut, insomnia.WriterOptions{
+			HeaderComment:     fmt.Sprintf("Generated by resterm %s", version),
+			OverwriteExisting: true,
+		})
+		if err != nil {
+			return fmt.Errorf("insomnia import error: %w", err)
+		}
+		_ = rtfmt.Fprintf(os.Stdout, "Generated %s from %s\n", nil, res.File, insomniaSrc)
+		if res.EnvFile != "" {
+			_ = rtfmt.Fprintf(os.Stdout, "Updated %s\n", nil, res.EnvFile)
+		}
+		return nil
+	}
+
+	if brunoSrc != "" {
+		targetOut := httpOut
+		if targetOut == "" {
+			targetOut = defaultBrunoOutputPath(brunoSrc)
+		}
+		svc := bruno.Service{Writer: bruno.NewFileWriter()}
+		res, err := svc.Import(context.Background(), brunoSrc, targetOut, bruno.WriterOptions{
+			HeaderComment:     fmt.Sprintf("Generated by resterm %s", version),
+			OverwriteExisting: true,
+		})
+		if err != nil {
+			return fmt.Errorf("bruno import error: %w", err)
+		}
+		_ = rtfmt.Fprintf(os.Stdout, "Generated %s from %s\n", nil, res.File, brunoSrc)
+		if res.EnvFile != "" {
+			_ = rtfmt.Fprintf(os.Stdout, "Updated %s\n", nil, res.EnvFile)
+		}
+		return nil
+	}
+
+	if harSrc != "" {
+		mode, err := har.ParseMode(harMode)
+		if err != nil {
+			return fmt.Errorf("har import error: %w", err)
+		}
+		targetOut := httpOut
+		if targetOut == "" {
+			targetOut = defaultHTTPOutputPath(harSrc)
+		}
+		opts := har.Options{
+			Mode:          mode,
+			IncludeHosts:  harHosts,
+			ExcludeStatic: harExcludeStatic,
+			Write: har.WriterOptions{
+				HeaderComment:     fmt.Sprintf("Generated by resterm %s", version),
+				OverwriteExisting: true,
+			},
+		}
+		svc := har.Service{Writer: har.NewFileWriter()}
+		if _, err := svc.GenerateHTTPFile(context.Background(), harSrc, targetOut, opts); err != nil {
+			return fmt.Errorf("har import error: %w", err)
+		}
+		_ = rtfmt.Fprintf(os.Stdout, "Generated %s from %s\n", nil, targetOut, harSrc)
+		return nil
+	}
+
 	if filePath == "" && fs.NArg() > 0 {
 		filePath = fs.Arg(0)
 	}
@@ -362,7 +559,19 @@ func run(a []string) error {
 		}()
 	}
 
+	ts, themeErr := loadThemeState()
+	if themeErr != nil {
+		log.Printf("%v", themeErr)
+	}
+
 	historyStore := histdb.New(config.HistoryPath())
+	historyStore.SetCapture(historyCaptureOptions(ts.settings.History))
+	// Retention is set before Load so the store prunes as it opens.
+	ret, err := historyRetention(ts.settings.History)
+	if err != nil {
+		log.Printf("history settings: %v", err)
+	}
+	historyStore.SetRetention(ret)
 	// History failures should never block the UI startup path.
 	// We log issues and keep running with an empty in-memory view.
 	if err := historyStore.Load(); err != nil {
@@ -393,10 +602,6 @@ func run(a []string) error {
 		bindingMap = bindings.DefaultMap()
 	}
 
-	ts, themeErr := loadThemeState()
-	if themeErr != nil {
-		log.Printf("%v", themeErr)
-	}
 	updateEnabled := version != "dev"
 
 	model := ui.New(ui.Config{
@@ -463,6 +668,9 @@ func printMainUsage(w io.Writer, fs *flag.FlagSet) {
 	if _, err := fmt.Fprintln(w, "  history     Manage persisted history"); err != nil {
 		return
 	}
+	if _, err := fmt.Fprintln(w, "  env         Encrypt, decrypt or edit environment files"); err != nil {
+		return
+	}
 	if _, err := fmt.Fprintln(w); err != nil {
 		return
 	}
@@ -598,6 +806,55 @@ func defaultCurlOutputPath(src string) string {
 	return "curl.http"
 }
 
+// importConflict rejects more than one --from-* source. Each entry pairs a
+// flag name with its value.
+func importConflict(sources ...[2]string) error {
+	var set []string
+	for _, s := range sources {
+		if s[1] != "" {
+			set = append(set, s[0])
+		}
+	}
+	switch len(set) {
+	case 0, 1:
+		return nil
+	case 2:
+		return fmt.Errorf("import error: choose either %s or %s", set[0], set[1])
+	default:
+		return fmt.Errorf(
+			"import error: choose only one of %s and %s",
+			strings.Join(set[:len(set)-1], ", "),
+			set[len(set)-1],
+		)
+	}
+}
+
+// defaultPostmanOutputPath names the output after the collection file, minus
+// the .postman_collection suffix Postman adds on export.
+func defaultPostmanOutputPath(src string, layout postman.Layout) string {
+	base := strings.TrimSuffix(src, filepath.Ext(src))
+	base = strings.TrimSuffix(base, ".postman_collection")
+	if layout == postman.LayoutTags {
+		return base + ".http"
+	}
+	return base
+}
+
+// defaultBrunoOutputPath writes the file beside the collection directory,
+// named after it.
+func defaultBrunoOutputPath(dir string) string {
+	clean := filepath.Clean(dir)
+	if base := filepath.Base(clean); base == "." || base == ".." || base == string(filepath.Separator) {
+		if abs, err := filepath.Abs(clean); err == nil {
+			clean = abs
+		}
+	}
+	if filepath.Dir(clean) == clean {
+		return "bruno.http"
+	}
+	return clean + ".http"
+}
+
 func defaultHTTPOutputPath(specPath string) string {
 	ext := filepath.Ext(specPath)
 	if ext == "" {
@@ -106,6 +106,162 @@ func TestRunRejectsConflictingImportShortFlags(t *testing.T) {
 	}
 }
 
+func TestRunRejectsThreeImportSources(t *testing.T) {
+	t.Setenv("RESTERM_CONFIG_DIR", t.TempDir())
+	err := run([]string{
+		"--from-curl", "curl https://example.com",
+		"--from-openapi", "spec.yaml",
+		"--from-postman", "c.json",
+	})
+	if err == nil {
+		t.Fatalf("expected conflict error")
+	}
+	want := "choose only one of --from-curl, --from-openapi and --from-postman"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunPostmanImport(t *testing.T) {
+	t.Setenv("RESTERM_CONFIG_DIR", t.TempDir())
+	dir := t.TempDir()
+	src := filepath.Join(dir, "shop.postman_collection.json")
+	env := filepath.Join(dir, "dev.postman_environment.json")
+	if err := os.WriteFile(src, []byte(`{
+  "info": {"name": "Shop", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "item": [{"name": "Health", "request": {"method": "GET", "url": "{{baseUrl}}/health"}}]
+}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(env, []byte(`{"name": "dev", "values": [{"key": "baseUrl", "value": "http://localhost"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, err := captureRunIO(t, func() error {
+		return run([]string{"--from-postman", src, "--postman-env", env, "--postman-folders", "tags"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "shop.http")
+	if !strings.Contains(out, "Generated "+target+" from Postman") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := restparser.Parse(target, data)
+	if len(doc.Errors) > 0 || len(doc.Requests) != 1 {
+		t.Fatalf("unexpected document: errors=%v requests=%d", doc.Errors, len(doc.Requests))
+	}
+	envData, err := os.ReadFile(filepath.Join(dir, "resterm.env.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(envData), `"baseUrl": "http://localhost"`) {
+		t.Fatalf("environment not written:\n%s", envData)
+	}
+}
+
+func TestRunHARImport(t *testing.T) {
+	t.Setenv("RESTERM_CONFIG_DIR", t.TempDir())
+	dir := t.TempDir()
+	src := filepath.Join(dir, "capture.har")
+	if err := os.WriteFile(src, []byte(`{"log": {"entries": [
+  {"request": {"method": "GET", "url": "https://api.example.com/items", "headers": []},
+   "response": {"status": 200, "headers": [], "content": {"text": "[]"}}},
+  {"_resourceType": "image", "request": {"method": "GET", "url": "https://api.example.com/a.png", "headers": []},
+   "response": {"status": 200, "headers": [], "content": {}}}
+]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := captureRunIO(t, func() error {
+		return run([]string{"--from-har", src, "--har-exclude-static", "--har-mode", "both"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "capture.http")
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := restparser.Parse(target, data)
+	if len(doc.Errors) > 0 || len(doc.Requests) != 1 || len(doc.Mocks) != 1 {
+		t.Fatalf(
+			"unexpected document: errors=%v requests=%d mocks=%d",
+			doc.Errors,
+			len(doc.Requests),
+			len(doc.Mocks),
+		)
+	}
+}
+
+func TestRunInsomniaImport(t *testing.T) {
+	t.Setenv("RESTERM_CONFIG_DIR", t.TempDir())
+	dir := t.TempDir()
+	src := filepath.Join(dir, "insomnia.json")
+	if err := os.WriteFile(src, []byte(`{"_type": "export", "__export_format": 4, "resources": [
+  {"_id": "wrk_1", "_type": "workspace", "name": "Demo"},
+  {"_id": "env_1", "_type": "environment", "parentId": "wrk_1", "name": "Base", "data": {"host": "http://x"}},
+  {"_id": "req_1", "_type": "request", "parentId": "wrk_1", "name": "Ping", "method": "GET", "url": "{{ _.host }}/ping"}
+]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := captureRunIO(t, func() error {
+		return run([]string{"--from-insomnia", src})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "insomnia.http")
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := restparser.Parse(target, data)
+	if len(doc.Errors) > 0 || len(doc.Requests) != 1 || doc.Requests[0].URL != "{{host}}/ping" {
+		t.Fatalf("unexpected document: %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "resterm.env.json")); err != nil {
+		t.Fatalf("environment file: %v", err)
+	}
+}
+
+func TestRunBrunoImport(t *testing.T) {
+	t.Setenv("RESTERM_CONFIG_DIR", t.TempDir())
+	dir := t.TempDir()
+	src := filepath.Join(dir, "demo")
+	if err := os.MkdirAll(src, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"bruno.json": `{"version": "1", "name": "Demo", "type": "collection"}`,
+		"ping.bru":   "meta {\n  name: Ping\n  seq: 1\n}\n\nget {\n  url: {{host}}/ping\n  body: none\n  auth: none\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := captureRunIO(t, func() error {
+		return run([]string{"--from-bruno", src + string(filepath.Separator)})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "demo.http")
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := restparser.Parse(target, data)
+	if len(doc.Errors) > 0 || len(doc.Requests) != 1 || doc.Requests[0].Metadata.Name != "Ping" {
+		t.Fatalf("unexpected document: %s", data)
+	}
+}
+
 func TestRunOpenAPIMockMode(t *testing.T) {
 	t.Setenv("RESTERM_CONFIG_DIR", t.TempDir())
 	dir := t.TempDir()
@@ -207,7 +363,7 @@ func TestRunDispatchesHistorySubcommand(t *testing.T) {
 	}
 	if !strings.Contains(
 		out,
-		"Usage: resterm history <export|import|backup|stats|check|compact> [flags]",
+		"Usage: resterm history <export|import|backup|stats|search|trends|share|prune|check|compact> [flags]",
 	) {
 		t.Fatalf("expected history usage in stdout, got %q", out)
 	}
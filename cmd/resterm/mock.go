@@ -40,6 +40,7 @@ type mockConfig struct {
 	cors             string
 	tlsCert          string
 	tlsKey           string
+	http3            bool
 	recursive        bool
 	watch            bool
 	quiet            bool
@@ -125,6 +126,13 @@ func runMockServe(args []string) error {
 		"tls-cert",
 	)
 	cli.StringVarAliases(fs, &cfg.tlsKey, "", "PEM private key for --tls-cert", "tls-key")
+	cli.BoolVarAliases(
+		fs,
+		&cfg.http3,
+		false,
+		"Also serve HTTP/3 on the same UDP port (requires --tls-cert)",
+		"http3",
+	)
 	cli.BoolVarAliases(fs, &cfg.recursive, false, "Scan workspace recursively", "recursive", "r")
 	cli.BoolVarAliases(fs, &cfg.watch, true, "Reload changed sources and fixtures", "watch", "w")
 	cli.BoolVarAliases(fs, &cfg.quiet, false, "Suppress per-request access summaries", "quiet", "q")
@@ -190,6 +198,9 @@ func serveMocks(ctx context.Context, cfg mockConfig, out, errOut io.Writer) erro
 	if (cfg.tlsCert == "") != (cfg.tlsKey == "") {
 		return mockUsageError(errors.New("mock: --tls-cert and --tls-key must be set together"))
 	}
+	if cfg.http3 && cfg.tlsCert == "" {
+		return mockUsageError(errors.New("mock: --http3 requires --tls-cert and --tls-key"))
+	}
 	cors, warning, err := mock.ResolveCORS(cfg.cors, cfg.addr)
 	if err != nil {
 		return mockUsageError(fmt.Errorf("mock: %w", err))
@@ -223,6 +234,7 @@ func serveMocks(ctx context.Context, cfg mockConfig, out, errOut io.Writer) erro
 		EnableControl:    true,
 		TLSCert:          cfg.tlsCert,
 		TLSKey:           cfg.tlsKey,
+		HTTP3:            cfg.http3,
 		SequenceKeyLimit: cfg.sequenceKeyLimit,
 		JournalEntries:   cfg.journalEntries,
 		JournalBytes:     journalBytes,
@@ -240,11 +252,16 @@ func serveMocks(ctx context.Context, cfg mockConfig, out, errOut io.Writer) erro
 	if cfg.tlsCert != "" {
 		scheme = "https"
 	}
+	h3 := ""
+	if cfg.http3 {
+		h3 = ", HTTP/3 on udp"
+	}
 	_, _ = fmt.Fprintf(
 		out,
-		"Mock server listening on %s://%s (%d routes, %d scenarios)\n",
+		"Mock server listening on %s://%s%s (%d routes, %d scenarios)\n",
 		scheme,
 		server.Addr(),
+		h3,
 		handler.Routes(),
 		handler.Scenarios(),
 	)
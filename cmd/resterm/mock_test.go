@@ -109,6 +109,19 @@ func TestServeMocksRequiresTLSPair(t *testing.T) {
 	}
 }
 
+func TestServeMocksHTTP3RequiresTLS(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := serveMocks(context.Background(), mockConfig{
+		path:  ".",
+		addr:  "127.0.0.1:0",
+		cors:  "off",
+		http3: true,
+	}, &out, &errOut)
+	if err == nil || !strings.Contains(err.Error(), "--http3 requires --tls-cert") {
+		t.Fatalf("err = %v, want the HTTP/3 TLS error", err)
+	}
+}
+
 func TestServeMocksValidatesJournalLimitsAsUsageErrors(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/cli"
+	"github.com/unkn0wn-root/resterm/internal/config"
+	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/openapi"
+	"github.com/unkn0wn-root/resterm/internal/openapi/infer"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/openapi/parser"
+	"github.com/unkn0wn-root/resterm/internal/openapi/specdiff"
+	"github.com/unkn0wn-root/resterm/internal/openapi/specsync"
+	"github.com/unkn0wn-root/resterm/internal/openapi/specwriter"
+	str "github.com/unkn0wn-root/resterm/internal/util"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+func handleOpenAPISubcommand(args []string) (bool, error) {
+	if len(args) == 0 || args[0] != "openapi" {
+		return false, nil
+	}
+	if len(args) == 1 && cli.HasFileConflict("openapi") {
+		return true, cli.CommandFileConflict(
+			"resterm",
+			"openapi",
+			"pass a subcommand like `resterm openapi generate --out openapi.yml`",
+		)
+	}
+	return true, runOpenAPI(args[1:])
+}
+
+func runOpenAPI(args []string) error {
+	if len(args) == 0 {
+		return errors.New(openapiUsageText())
+	}
+	op := str.Trim(strings.ToLower(args[0]))
+	switch op {
+	case "-h", "--help", "help":
+		if err := writeln(os.Stdout, openapiUsageText()); err != nil {
+			return fmt.Errorf("openapi: write output: %w", err)
+		}
+		return nil
+	case "generate":
+		return runOpenAPIGenerate(args[1:])
+	case "diff":
+		return runOpenAPIDiff(args[1:])
+	case "sync":
+		return runOpenAPISync(args[1:])
+	default:
+		return fmt.Errorf("openapi: unknown subcommand %q\n\n%s", op, openapiUsageText())
+	}
+}
+
+func runOpenAPIGenerate(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "openapi generate", os.Stderr)
+	var (
+		workspace  string
+		out        string
+		title      string
+		apiVersion string
+		envFile    string
+		recursive  bool
+		noHistory  bool
+		force      bool
+	)
+	fs.StringVar(&workspace, "workspace", ".", "Workspace directory to describe")
+	fs.StringVar(&out, "out", "openapi.yml", "Output spec path (.json writes JSON, anything else YAML)")
+	fs.StringVar(&title, "title", "", "API title (default: the workspace directory name)")
+	fs.StringVar(&apiVersion, "api-version", "1.0.0", "API version written to info.version")
+	fs.StringVar(&envFile, "env-file", "", "Environment file that resolves server URLs")
+	fs.BoolVar(&recursive, "recursive", false, "Recursively scan workspace for request files")
+	fs.BoolVar(&noHistory, "no-history", false, "Do not use recorded responses as examples")
+	fs.BoolVar(&force, "force", false, "Overwrite an existing output file")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("openapi generate: %w", err)
+	}
+	if len(fs.Args()) > 0 {
+		return fmt.Errorf("openapi generate: unexpected args: %s", strings.Join(fs.Args(), " "))
+	}
+	workspace = str.Trim(workspace)
+	out = str.Trim(out)
+	if out == "" {
+		return errors.New("openapi generate: --out is required")
+	}
+	title = str.Trim(title)
+	if title == "" {
+		if abs, err := filepath.Abs(workspace); err == nil {
+			title = filepath.Base(abs)
+		}
+	}
+
+	docs, err := infer.Load(workspace, recursive)
+	if err != nil {
+		return fmt.Errorf("openapi generate: %w", err)
+	}
+	if len(docs) == 0 {
+		return fmt.Errorf("openapi generate: no request files in %s", workspace)
+	}
+	envs, err := openapiEnvs(workspace, str.Trim(envFile))
+	if err != nil {
+		return fmt.Errorf("openapi generate: %w", err)
+	}
+	opt := infer.Options{Title: title, Version: str.Trim(apiVersion), Envs: envs}
+	if !noHistory {
+		if opt.History, err = openapiHistory(); err != nil {
+			return err
+		}
+	}
+
+	spec, warns := infer.Build(docs, opt)
+	if len(spec.Operations) == 0 {
+		return fmt.Errorf("openapi generate: no HTTP requests or mocks in %s", workspace)
+	}
+	if err := specwriter.WriteFile(spec, out, force); err != nil {
+		return fmt.Errorf("openapi generate: %w", err)
+	}
+	for _, w := range warns {
+		if err := writef(os.Stderr, "warning: %s\n", w); err != nil {
+			return fmt.Errorf("openapi generate: write output: %w", err)
+		}
+	}
+	if err := writef(
+		os.Stdout,
+		"Generated %s with %d operations from %d files\n",
+		out,
+		len(spec.Operations),
+		len(docs),
+	); err != nil {
+		return fmt.Errorf("openapi generate: write output: %w", err)
+	}
+	return nil
+}
+
+// openapiEnvs loads every environment so each one that sets the base URL
+// becomes a server.
+func openapiEnvs(workspace, explicit string) ([]infer.Env, error) {
+	var (
+		cat vars.Catalog
+		err error
+	)
+	if explicit != "" {
+		cat, err = vars.LoadEnvironmentFile(explicit)
+	} else {
+		cat, _, err = vars.Discover(workspace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cat.Empty() {
+		return nil, nil
+	}
+	if cat.Grouped() {
+		env, err := cat.Resolve(cat.DefaultSelection())
+		if err != nil {
+			return nil, err
+		}
+		return []infer.Env{{Name: env.Label(), Values: env.Values()}}, nil
+	}
+	var out []infer.Env
+	for _, name := range cat.Names() {
+		sel, err := cat.Select(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		env, err := cat.Resolve(sel)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, infer.Env{Name: name, Values: env.Values()})
+	}
+	return out, nil
+}
+
+// openapiHistory reads recorded responses when a history database exists,
+// without creating one.
+func openapiHistory() ([]history.Entry, error) {
+	if _, err := os.Stat(config.HistoryPath()); err != nil {
+		return nil, nil
+	}
+	s, err := openHistoryStore(false)
+	if err != nil {
+		return nil, fmt.Errorf("openapi generate: %w", err)
+	}
+	defer func() { _ = s.Close() }()
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, fmt.Errorf("openapi generate: read history: %w", err)
+	}
+	return entries, nil
+}
+
+func runOpenAPIDiff(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "openapi diff", os.Stderr)
+	var (
+		format    string
+		workspace string
+		recursive bool
+	)
+	fs.StringVar(&format, "format", "text", "Output format: text, json or junit")
+	fs.StringVar(&workspace, "workspace", ".", "Workspace whose requests are matched to changes")
+	fs.BoolVar(&recursive, "recursive", false, "Recursively scan workspace for request files")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("openapi diff: %w", err)
+	}
+	if len(pos) != 2 {
+		return errors.New("openapi diff: expected two spec files: <old> <new>")
+	}
+	var write func(io.Writer, *specdiff.Report) error
+	switch str.Trim(strings.ToLower(format)) {
+	case "text":
+		write = specdiff.WriteText
+	case "json":
+		write = specdiff.WriteJSON
+	case "junit":
+		write = specdiff.WriteJUnit
+	default:
+		return fmt.Errorf("openapi diff: unknown format %q (want text, json or junit)", format)
+	}
+
+	old, err := loadSpec("openapi diff", pos[0])
+	if err != nil {
+		return err
+	}
+	next, err := loadSpec("openapi diff", pos[1])
+	if err != nil {
+		return err
+	}
+	rep := &specdiff.Report{Old: pos[0], New: pos[1], Changes: specdiff.Compare(old, next)}
+	if len(rep.Changes) > 0 {
+		docs, err := infer.Load(str.Trim(workspace), recursive)
+		if err != nil {
+			return fmt.Errorf("openapi diff: %w", err)
+		}
+		specdiff.Attach(rep.Changes, specdiff.Requests(docs, workspace), old, next)
+	}
+	if err := write(os.Stdout, rep); err != nil {
+		return fmt.Errorf("openapi diff: write output: %w", err)
+	}
+	if rep.Breaking() > 0 {
+		return cli.ExitErr{Code: 1}
+	}
+	return nil
+}
+
+func loadSpec(cmd, path string) (*model.Spec, error) {
+	spec, err := parser.NewLoader().Parse(
+		context.Background(),
+		path,
+		openapi.ParseOptions{ResolveExternalRefs: true},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cmd, err)
+	}
+	return spec, nil
+}
+
+func runOpenAPISync(args []string) error {
+	fs := cli.NewSubcommandFlagSet("resterm", "openapi sync", os.Stderr)
+	var (
+		dryRun     bool
+		deprecated bool
+	)
+	fs.BoolVar(&dryRun, "dry-run", false, "Report what would change without writing the file")
+	fs.BoolVar(&deprecated, "include-deprecated", false, "Add deprecated operations the file lacks")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("openapi sync: %w", err)
+	}
+	if len(pos) != 2 {
+		return errors.New("openapi sync: expected a spec and a request file: <spec> <file.http>")
+	}
+	specPath, target := pos[0], pos[1]
+	spec, err := loadSpec("openapi sync", specPath)
+	if err != nil {
+		return err
+	}
+	opt := specsync.Options{
+		Generate: openapi.GeneratorOptions{IncludeDeprecated: deprecated},
+		SpecName: filepath.Base(specPath),
+	}
+
+	mode := os.FileMode(0o644)
+	var res *specsync.Result
+	switch info, err := os.Stat(target); {
+	case errors.Is(err, os.ErrNotExist):
+		res, err = specsync.Generate(context.Background(), spec, opt)
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return fmt.Errorf("openapi sync: %w", err)
+	default:
+		mode = info.Mode().Perm()
+		src, err := os.ReadFile(target)
+		if err != nil {
+			return fmt.Errorf("openapi sync: %w", err)
+		}
+		if res, err = specsync.Sync(spec, target, src, opt); err != nil {
+			return err
+		}
+	}
+	if res.Changed && !dryRun {
+		if err := os.WriteFile(target, res.Content, mode); err != nil {
+			return fmt.Errorf("openapi sync: %w", err)
+		}
+	}
+	if err := writeSyncReport(os.Stdout, target, res, dryRun); err != nil {
+		return fmt.Errorf("openapi sync: write output: %w", err)
+	}
+	return nil
+}
+
+func writeSyncReport(w io.Writer, target string, res *specsync.Result, dryRun bool) error {
+	counts := map[specsync.ActionKind]int{}
+	for _, a := range res.Actions {
+		counts[a.Kind]++
+		line := fmt.Sprintf("%-9s %s", a.Kind, a.Request)
+		if a.Ref != "" {
+			line += " (" + a.Ref + ")"
+		}
+		switch {
+		case a.Kind == specsync.ActionFlagged:
+			line += ": no operation in spec"
+		case len(a.Details) > 0:
+			line += ": " + strings.Join(a.Details, "; ")
+		}
+		if err := writeln(w, line); err != nil {
+			return err
+		}
+	}
+	if !res.Changed {
+		return writef(w, "%s is up to date\n", target)
+	}
+	verb := "Synced"
+	if dryRun {
+		verb = "Would sync"
+	}
+	return writef(
+		w,
+		"%s %s: %d added, %d updated, %d flagged\n",
+		verb,
+		target,
+		counts[specsync.ActionAdded],
+		counts[specsync.ActionUpdated]+counts[specsync.ActionUnflagged],
+		counts[specsync.ActionFlagged],
+	)
+}
+
+// parseInterspersed lets flags follow positional arguments, as in
+// `resterm openapi diff old.yml new.yml --format json`.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return pos, nil
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(pos, rest...), nil
+		}
+		pos = append(pos, rest[0])
+		args = rest[1:]
+	}
+}
+
+func openapiUsageText() string {
+	return str.Trim(`
+Usage: resterm openapi <generate|diff|sync> [flags]
+
+Subcommands:
+  generate [--workspace <dir>] [--out <path>]   Describe a workspace as an OpenAPI 3.1 spec
+  diff <old> <new> [--format text|json|junit]   Report changes between two specs
+  sync <spec> <file.http> [--dry-run]           Merge spec changes into a request file
+
+Generate flags:
+  --workspace <dir>      Workspace directory (default .)
+  --out <path>           Output file (default openapi.yml; .json writes JSON)
+  --recursive            Recursively scan the workspace for request files
+  --title <text>         API title (default: the workspace directory name)
+  --api-version <ver>    info.version (default 1.0.0)
+  --env-file <path>      Environment file used to resolve server URLs
+  --no-history           Do not use recorded responses as examples
+  --force                Overwrite an existing output file
+
+Diff flags:
+  --format <fmt>         text (default), json or junit
+  --workspace <dir>      Workspace whose requests are listed per change (default .)
+  --recursive            Recursively scan the workspace for request files
+
+Sync flags:
+  --dry-run              Report changes without writing the file
+  --include-deprecated   Add deprecated operations the file lacks
+
+Diff exits with status 1 when any change is breaking. Sync keeps hand-written
+directives, scripts and comments, and flags requests whose operation is gone.
+`)
+}
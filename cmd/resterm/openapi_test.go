@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/cli"
+	"github.com/unkn0wn-root/resterm/internal/history"
+	histdb "github.com/unkn0wn-root/resterm/internal/history/sqlite"
+	"github.com/unkn0wn-root/resterm/internal/openapi"
+	"github.com/unkn0wn-root/resterm/internal/openapi/parser"
+)
+
+func TestRunOpenAPIHelp(t *testing.T) {
+	stdout, _, err := captureHistoryIO(t, func() error {
+		return runOpenAPI([]string{"--help"})
+	})
+	if err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	if !strings.Contains(stdout, "Usage: resterm openapi") {
+		t.Fatalf("expected usage in stdout, got %q", stdout)
+	}
+	if err := runOpenAPI([]string{"bogus"}); err == nil {
+		t.Fatalf("expected error for unknown subcommand")
+	}
+}
+
+func TestRunOpenAPIGenerate(t *testing.T) {
+	cfgDir := t.TempDir()
+	t.Setenv("RESTERM_CONFIG_DIR", cfgDir)
+
+	ws := t.TempDir()
+	file := filepath.Join(ws, "api.http")
+	writeOpenAPIFile(t, file, `### List users
+# @name listUsers
+GET {{baseUrl}}/users?limit=10
+
+### Unknown
+# @mock method=GET path=/users/{id}
+HTTP/1.1 404 Not Found
+Content-Type: application/json
+
+{"error": "missing"}
+`)
+	writeOpenAPIFile(t, filepath.Join(ws, "resterm.env.json"),
+		`{"dev": {"baseUrl": "http://localhost:8080"}}`)
+
+	store := histdb.New(filepath.Join(cfgDir, "history.db"))
+	if err := store.Load(); err != nil {
+		t.Fatalf("load history: %v", err)
+	}
+	if err := store.Append(history.Entry{
+		ID:          "1",
+		ExecutedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Method:      "GET",
+		URL:         "{{baseUrl}}/users?limit=10",
+		FilePath:    file,
+		RequestName: "listUsers",
+		StatusCode:  200,
+		BodySnippet: `[{"id": 1, "name": "Ada"}]`,
+	}); err != nil {
+		t.Fatalf("append history: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("close history: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "openapi.yml")
+	stdout, stderr, err := captureHistoryIO(t, func() error {
+		return runOpenAPI([]string{"generate", "--workspace", ws, "--out", out, "--title", "Users"})
+	})
+	if err != nil {
+		t.Fatalf("generate: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "with 2 operations from 1 files") {
+		t.Fatalf("unexpected stdout %q", stdout)
+	}
+
+	spec, err := parser.NewLoader().Parse(context.Background(), out, openapi.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse generated spec: %v", err)
+	}
+	if spec.Title != "Users" || len(spec.Servers) != 1 || spec.Servers[0].URL != "http://localhost:8080" {
+		t.Fatalf("spec = %+v", spec)
+	}
+	for _, op := range spec.Operations {
+		if op.ID == "listUsers" && (len(op.Responses) != 1 || op.Responses[0].StatusCode != "200") {
+			t.Fatalf("history response missing: %+v", op.Responses)
+		}
+	}
+
+	if err := runOpenAPI([]string{"generate", "--workspace", ws, "--out", out}); err == nil {
+		t.Fatalf("expected an existing output to be kept without --force")
+	}
+	if err := runOpenAPI([]string{"generate", "--workspace", ws, "--out", out, "--force", "--no-history"}); err != nil {
+		t.Fatalf("generate --force: %v", err)
+	}
+}
+
+func TestRunOpenAPIDiff(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.yml")
+	newPath := filepath.Join(dir, "new.yml")
+	writeOpenAPIFile(t, oldPath, `openapi: 3.1.0
+info: {title: Users, version: 1.0.0}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        "200": {description: ok}
+  /users/{id}:
+    delete:
+      operationId: deleteUser
+      responses:
+        "204": {description: gone}
+`)
+	writeOpenAPIFile(t, newPath, `openapi: 3.1.0
+info: {title: Users, version: 2.0.0}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - {name: page, in: query, schema: {type: integer}}
+      responses:
+        "200": {description: ok}
+`)
+	ws := t.TempDir()
+	writeOpenAPIFile(t, filepath.Join(ws, "users.http"), `### Delete
+DELETE {{baseUrl}}/users/7
+`)
+
+	stdout, _, err := captureHistoryIO(t, func() error {
+		return runOpenAPI([]string{"diff", oldPath, newPath, "--workspace", ws})
+	})
+	var exit cli.ExitErr
+	if !errors.As(err, &exit) || exit.Code != 1 {
+		t.Fatalf("expected exit code 1 for a breaking change, got %v", err)
+	}
+	for _, want := range []string{
+		"BREAKING operation removed",
+		"users.http:2",
+		"change   query page: optional parameter added",
+		"Summary: 2 changes, 1 breaking",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("stdout missing %q:\n%s", want, stdout)
+		}
+	}
+
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runOpenAPI([]string{"diff", "--format", "json", newPath, newPath})
+	})
+	if err != nil {
+		t.Fatalf("diff of identical specs: %v", err)
+	}
+	if !strings.Contains(stdout, `"total": 0`) {
+		t.Fatalf("json stdout = %q", stdout)
+	}
+	if err := runOpenAPI([]string{"diff", oldPath}); err == nil {
+		t.Fatalf("expected an error for a missing spec argument")
+	}
+	if err := runOpenAPI([]string{"diff", "--format", "xml", oldPath, newPath}); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
+
+func TestRunOpenAPISync(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "openapi.yml")
+	target := filepath.Join(dir, "users.http")
+	writeOpenAPIFile(t, specPath, `openapi: 3.1.0
+info: {title: Users, version: 1.0.0}
+servers:
+  - url: https://api.example.com
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        "200": {description: ok}
+`)
+
+	stdout, _, err := captureHistoryIO(t, func() error {
+		return runOpenAPI([]string{"sync", specPath, target})
+	})
+	if err != nil {
+		t.Fatalf("sync into a new file: %v", err)
+	}
+	if !strings.Contains(stdout, "added     listUsers") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("read synced file: %v", err)
+	}
+	src := strings.Replace(string(data), "GET {{baseUrl}}/users", "# @assert status == 200\nGET {{baseUrl}}/users", 1)
+	writeOpenAPIFile(t, target, src)
+
+	writeOpenAPIFile(t, specPath, `openapi: 3.1.0
+info: {title: Users, version: 1.1.0}
+servers:
+  - url: https://api.example.com
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - {name: page, in: query, schema: {type: integer, example: 1}}
+      responses:
+        "200": {description: ok}
+`)
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runOpenAPI([]string{"sync", specPath, target, "--dry-run"})
+	})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.Contains(stdout, "added query page") || !strings.Contains(stdout, "Would sync") {
+		t.Fatalf("dry run stdout = %q", stdout)
+	}
+	if data, _ := os.ReadFile(target); string(data) != src {
+		t.Fatalf("dry run wrote the file:\n%s", data)
+	}
+
+	if _, _, err := captureHistoryIO(t, func() error {
+		return runOpenAPI([]string{"sync", specPath, target})
+	}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	data, _ = os.ReadFile(target)
+	for _, want := range []string{"# @assert status == 200", "/users?page={{query_page}}"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("synced file missing %q:\n%s", want, data)
+		}
+	}
+
+	stdout, _, err = captureHistoryIO(t, func() error {
+		return runOpenAPI([]string{"sync", specPath, target})
+	})
+	if err != nil || !strings.Contains(stdout, "is up to date") {
+		t.Fatalf("second sync: %v, stdout %q", err, stdout)
+	}
+	if err := runOpenAPI([]string{"sync", specPath}); err == nil {
+		t.Fatalf("expected an error for a missing file argument")
+	}
+}
+
+func writeOpenAPIFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
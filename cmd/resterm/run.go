@@ -9,16 +9,19 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"golang.org/x/term"
 
 	"github.com/unkn0wn-root/resterm/internal/cli"
+	"github.com/unkn0wn-root/resterm/internal/history"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/runner"
 	"github.com/unkn0wn-root/resterm/internal/runx/fail"
 	"github.com/unkn0wn-root/resterm/internal/runx/view"
+	"github.com/unkn0wn-root/resterm/internal/snippet"
 	"github.com/unkn0wn-root/resterm/internal/termcolor"
 	"github.com/unkn0wn-root/resterm/internal/theme"
 	str "github.com/unkn0wn-root/resterm/internal/util"
@@ -62,6 +65,7 @@ func runRun(args []string) error {
 type runExecFn func(context.Context, runner.Options) (*runner.Report, error)
 type runClientFn func(string, cli.ExecFlags) (*httpx.Client, func() error, error)
 type runThemeFn func() (theme.Definition, error)
+type runEmitFn func(context.Context, runner.Options, snippet.Format, bool) (string, []string, error)
 
 type runFormat string
 
@@ -103,11 +107,13 @@ type runCmd struct {
 	exec cli.ExecFlags
 
 	runFn     runExecFn
+	emitFn    runEmitFn
 	newClient runClientFn
 	loadTheme runThemeFn
 
 	in        io.Reader
 	out       io.Writer
+	errOut    io.Writer
 	stdinTTY  bool
 	stdoutTTY bool
 	lookupEnv termcolor.Lookup
@@ -118,9 +124,11 @@ type runCmd struct {
 	format         string
 	exitCodeMode   string
 	color          string
+	emit           string
 	line           int
 	artifactDir    string
 	stateDir       string
+	contract       string
 	all            bool
 	body           bool
 	headers        bool
@@ -129,15 +137,19 @@ type runCmd struct {
 	persistAuth    bool
 	history        bool
 	failFast       bool
+	maskSecrets    bool
+	updateSnaps    bool
 }
 
 func newRunCmd() *runCmd {
 	cmd := &runCmd{
 		exec:         cli.NewExecFlags(),
 		runFn:        runner.RunContext,
+		emitFn:       runner.Emit,
 		newClient:    cli.NewExecClient,
 		in:           os.Stdin,
 		out:          os.Stdout,
+		errOut:       os.Stderr,
 		stdinTTY:     term.IsTerminal(int(os.Stdin.Fd())),
 		stdoutTTY:    term.IsTerminal(int(os.Stdout.Fd())),
 		lookupEnv:    os.LookupEnv,
@@ -231,6 +243,22 @@ func (c *runCmd) bind() {
 		"artifact-dir",
 		"A",
 	)
+	cli.StringVarAliases(
+		c.fs,
+		&c.contract,
+		"",
+		"Check responses against an OpenAPI spec when it documents their route",
+		"contract",
+		"K",
+	)
+	cli.BoolVarAliases(
+		c.fs,
+		&c.updateSnaps,
+		false,
+		"Rewrite @snapshot files that differ from the response",
+		"update-snapshots",
+		"U",
+	)
 	cli.StringVarAliases(
 		c.fs,
 		&c.stateDir,
@@ -263,6 +291,22 @@ func (c *runCmd) bind() {
 		"history",
 		"y",
 	)
+	cli.StringVarAliases(
+		c.fs,
+		&c.emit,
+		"",
+		"Print the request as a snippet instead of sending it: "+emitFormats(),
+		"emit",
+		"O",
+	)
+	cli.BoolVarAliases(
+		c.fs,
+		&c.maskSecrets,
+		false,
+		"Mask secrets and sensitive headers in --emit output",
+		"mask-secrets",
+		"M",
+	)
 }
 
 func (c *runCmd) parse(args []string) error {
@@ -314,6 +358,10 @@ func (c *runCmd) run() error {
 		}()
 	}
 
+	if c.emit != "" {
+		return c.runEmit(context.Background(), src, cfg, client)
+	}
+
 	rep, err := c.execRun(context.Background(), src, cfg, client)
 	if err != nil {
 		if runner.IsUsageError(err) {
@@ -466,32 +514,73 @@ func (c *runCmd) execRun(
 	return runFn(ctx, c.runOptions(src, cfg, client))
 }
 
+// runEmit prints the selected request as a snippet. Nothing is sent, so the
+// report flags do not apply.
+func (c *runCmd) runEmit(
+	ctx context.Context,
+	src cli.RunSource,
+	cfg cli.ExecConfig,
+	client *httpx.Client,
+) error {
+	f, err := snippet.ParseFormat(c.emit)
+	if err != nil {
+		return runExit(fmt.Errorf("unsupported --emit %q", c.emit), runExitCodeUsage)
+	}
+	emitFn := runner.Emit
+	if c.emitFn != nil {
+		emitFn = c.emitFn
+	}
+	out, warns, err := emitFn(ctx, c.runOptions(src, cfg, client), f, c.maskSecrets)
+	if err != nil {
+		if runner.IsUsageError(err) {
+			return runExit(err, runExitCodeUsage)
+		}
+		return runExit(err, c.failureExitCode(err))
+	}
+	for _, w := range warns {
+		_, _ = fmt.Fprintln(c.stderr(), "warning:", w)
+	}
+	_, err = io.WriteString(c.stdout(), out)
+	return err
+}
+
+func emitFormats() string {
+	names := make([]string, len(snippet.Formats))
+	for i, f := range snippet.Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
 func (c *runCmd) runOptions(
 	src cli.RunSource,
 	cfg cli.ExecConfig,
 	client *httpx.Client,
 ) runner.Options {
 	return runner.Options{
-		Version:         version,
-		FilePath:        src.Path,
-		FileContent:     src.Data,
-		WorkspaceRoot:   cfg.Workspace,
-		Recursive:       cfg.Recursive,
-		ArtifactDir:     c.artifactDir,
-		StateDir:        c.stateDir,
-		PersistGlobals:  c.persistGlobals,
-		PersistAuth:     c.persistAuth,
-		History:         c.history,
-		FailFast:        c.failFast,
-		Catalog:         cfg.Env.Catalog,
-		Selection:       cfg.Env.Selection,
-		EnvironmentFile: cfg.Env.File,
-		Compare:         cfg.Compare,
-		Profile:         c.profile,
-		HTTPOptions:     cfg.HTTPOpts,
-		GRPCOptions:     cfg.GRPCOpts,
-		Client:          client,
-		Select:          c.selector(),
+		Version:          version,
+		FilePath:         src.Path,
+		FileContent:      src.Data,
+		WorkspaceRoot:    cfg.Workspace,
+		Recursive:        cfg.Recursive,
+		ArtifactDir:      c.artifactDir,
+		StateDir:         c.stateDir,
+		PersistGlobals:   c.persistGlobals,
+		PersistAuth:      c.persistAuth,
+		History:          c.history,
+		HistoryRetention: c.historyRetention(),
+		FailFast:         c.failFast,
+		Catalog:          cfg.Env.Catalog,
+		Selection:        cfg.Env.Selection,
+		EnvironmentFile:  cfg.Env.File,
+		Compare:          cfg.Compare,
+		Contract:         c.contract,
+		UpdateSnapshots:  c.updateSnaps,
+		Profile:          c.profile,
+		HTTPOptions:      cfg.HTTPOpts,
+		GRPCOptions:      cfg.GRPCOpts,
+		Client:           client,
+		Select:           c.selector(),
 	}
 }
 
@@ -594,6 +683,26 @@ func (c *runCmd) stdout() io.Writer {
 	return os.Stdout
 }
 
+// historyRetention applies the settings file's retention to --history runs,
+// whose database would otherwise grow with every CI job.
+func (c *runCmd) historyRetention() history.Retention {
+	if !c.history {
+		return history.Retention{}
+	}
+	ret, err := loadHistoryRetention()
+	if err != nil {
+		_, _ = fmt.Fprintln(c.stderr(), "warning: history settings:", err)
+	}
+	return ret
+}
+
+func (c *runCmd) stderr() io.Writer {
+	if c != nil && c.errOut != nil {
+		return c.errOut
+	}
+	return os.Stderr
+}
+
 func (c *runCmd) hasWorkflowSelector() bool {
 	return c != nil && c.workflow != ""
 }
@@ -609,6 +718,20 @@ func (c *runCmd) validateFormat() error {
 	if !runfail.ValidExitMode(c.parsedExitCodeMode()) {
 		return fmt.Errorf("unsupported --exit-code-mode %q", c.exitCodeMode)
 	}
+	if c.maskSecrets && c.emit == "" {
+		return errors.New("--mask-secrets requires --emit")
+	}
+	if err := c.resolveContract(); err != nil {
+		return err
+	}
+	if c.emit != "" {
+		switch {
+		case c.body, c.profile, c.workflow != "":
+			return errors.New("--emit cannot be combined with --body, --profile, or --workflow")
+		case format != runFmtAuto:
+			return errors.New("--emit cannot be combined with --format")
+		}
+	}
 	if c.body {
 		switch format {
 		case runFmtAuto, runFmtPretty, runFmtRaw:
@@ -619,6 +742,28 @@ func (c *runCmd) validateFormat() error {
 	return nil
 }
 
+// resolveContract makes --contract absolute, so request files in other
+// directories still find it, and reports a missing spec before anything runs.
+func (c *runCmd) resolveContract() error {
+	path := strings.TrimSpace(c.contract)
+	if path == "" {
+		return nil
+	}
+	path, err := filepath.Abs(str.ExpandHome(path))
+	if err != nil {
+		return fmt.Errorf("resolve --contract: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("--contract: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("--contract %s is a directory", c.contract)
+	}
+	c.contract = path
+	return nil
+}
+
 func (c *runCmd) parsedExitCodeMode() runfail.ExitMode {
 	if c == nil {
 		return runfail.ExitDetailed
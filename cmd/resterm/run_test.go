@@ -130,6 +130,8 @@ func TestRunCmdShortAliases(t *testing.T) {
 		"-G",
 		"-P",
 		"-y",
+		"-O", "curl",
+		"-M",
 		"requests.http",
 	}); err != nil {
 		t.Fatalf("parse aliases: %v", err)
@@ -183,6 +185,9 @@ func TestRunCmdShortAliases(t *testing.T) {
 	if !cmd.persistGlobals || !cmd.persistAuth || !cmd.history {
 		t.Fatalf("unexpected persistence flags: %+v", cmd)
 	}
+	if cmd.emit != "curl" || !cmd.maskSecrets {
+		t.Fatalf("unexpected emit flags: emit=%q mask=%v", cmd.emit, cmd.maskSecrets)
+	}
 	if got := cmd.fs.Args(); len(got) != 1 || got[0] != "requests.http" {
 		t.Fatalf("args = %#v, want requests.http", got)
 	}
@@ -282,6 +287,59 @@ func TestRunCmdSingleRequestSetsDefaultLine(t *testing.T) {
 	}
 }
 
+func TestRunCmdEmitPrintsSnippetWithoutRunning(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "one.http")
+	src := strings.Join([]string{
+		"# @name one",
+		"# @auth bearer tok-123",
+		"GET https://example.com/one",
+		"",
+	}, "\n")
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	cmd := newRunCmd()
+	cmd.stdinTTY = false
+	cmd.stdoutTTY = false
+	cmd.out = &out
+	cmd.errOut = &errOut
+	cmd.newClient = stubRunClient
+	cmd.runFn = func(context.Context, runner.Options) (*runner.Report, error) {
+		t.Fatalf("--emit sent the request")
+		return nil, nil
+	}
+
+	if err := cmd.parse([]string{"--emit", "httpie", "--mask-secrets", file}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := cmd.run(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := out.String(); got != "http GET 'https://example.com/one' \\\n  'Authorization:•••'\n" {
+		t.Fatalf("snippet = %q", got)
+	}
+
+	cmd = newRunCmd()
+	if err := cmd.parse([]string{"--emit", "wget", file}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var exit cli.ExitErr
+	if err := cmd.run(); !errors.As(err, &exit) || exit.Code != runExitCodeUsage {
+		t.Fatalf("unknown format err = %v", err)
+	}
+
+	cmd = newRunCmd()
+	if err := cmd.parse([]string{"--mask-secrets", file}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := cmd.run(); err == nil || !strings.Contains(err.Error(), "--mask-secrets requires --emit") {
+		t.Fatalf("mask without emit err = %v", err)
+	}
+}
+
 func TestRunCmdNonInteractiveListsRequestsAndReturnsUsageExit(t *testing.T) {
 	dir := t.TempDir()
 	file := filepath.Join(dir, "many.http")
@@ -612,6 +670,49 @@ func TestRunCmdRejectsUnsupportedFormat(t *testing.T) {
 	}
 }
 
+func TestRunCmdContractSpec(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "one.http")
+	src := strings.Join([]string{
+		"# @name one",
+		"GET https://example.com/one",
+		"",
+	}, "\n")
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	cmd := newRunCmd()
+	if err := cmd.parse([]string{"--contract", filepath.Join(dir, "missing.yml"), file}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	err := cmd.run()
+	if code := cli.ExitCode(err); code != 2 {
+		t.Fatalf("expected exit code 2, got %d (err=%v)", code, err)
+	}
+
+	spec := filepath.Join(dir, "openapi.yml")
+	if err := os.WriteFile(spec, []byte("openapi: 3.1.0\n"), 0o644); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+	t.Chdir(dir)
+
+	var got string
+	cmd = newRunCmd()
+	cmd.newClient = stubRunClient
+	cmd.runFn = func(_ context.Context, opts runner.Options) (*runner.Report, error) {
+		got = opts.Contract
+		return &runner.Report{}, nil
+	}
+	if err := cmd.parse([]string{"-K", "openapi.yml", file}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	_ = cmd.run()
+	if got != spec {
+		t.Fatalf("contract = %q, want %q", got, spec)
+	}
+}
+
 func TestRunCmdRejectsUnsupportedColor(t *testing.T) {
 	dir := t.TempDir()
 	file := filepath.Join(dir, "one.http")
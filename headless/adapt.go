@@ -58,6 +58,8 @@ func resultFromFmt(res runfmt.Result) Result {
 		GRPC:                 grpcFromFmt(res.GRPC),
 		Stream:               streamFromFmt(res.Stream),
 		Trace:                traceFromFmt(res.Trace),
+		Contract:             contractFromFmt(res.Contract),
+		Snapshot:             (*Snapshot)(res.Snapshot),
 		Tests:                testsFromFmt(res.Tests),
 		Compare:              compareFromFmt(res.Compare),
 		Profile:              profileFromFmt(res.Profile),
@@ -88,6 +90,8 @@ func stepFromFmt(step runfmt.Step) Step {
 		GRPC:                 grpcFromFmt(step.GRPC),
 		Stream:               streamFromFmt(step.Stream),
 		Trace:                traceFromFmt(step.Trace),
+		Contract:             contractFromFmt(step.Contract),
+		Snapshot:             (*Snapshot)(step.Snapshot),
 		Tests:                testsFromFmt(step.Tests),
 	}
 	return out
@@ -272,3 +276,17 @@ func traceFromFmt(trace *runfmt.Trace) *Trace {
 	}
 	return out
 }
+
+func contractFromFmt(c *runfmt.Contract) *Contract {
+	if c == nil {
+		return nil
+	}
+	out := &Contract{Spec: c.Spec, Operation: c.Operation}
+	if len(c.Violations) > 0 {
+		out.Violations = make([]ContractViolation, 0, len(c.Violations))
+		for _, v := range c.Violations {
+			out.Violations = append(out.Violations, ContractViolation(v))
+		}
+	}
+	return out
+}
@@ -86,8 +86,14 @@ func (b *builder) buildPaths() error {
 	if err != nil {
 		return UsageError{err: fmt.Errorf("resolve workspaceRoot: %w", err)}
 	}
+	spec, err := absPath(b.opt.Contract)
+	if err != nil {
+		return UsageError{err: fmt.Errorf("resolve contract: %w", err)}
+	}
 	b.out.FilePath = path
 	b.out.WorkspaceRoot = work
+	b.out.Contract = spec
+	b.out.UpdateSnapshots = b.opt.UpdateSnapshots
 	return nil
 }
 
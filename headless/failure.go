@@ -11,7 +11,8 @@ type ExitCodeMode string
 
 const (
 	// ExitCodeDetailed returns classified CI exit codes such as timeout,
-	// network, TLS, auth, script, filesystem, protocol, route, or canceled.
+	// network, TLS, auth, script, filesystem, protocol, route, contract, or
+	// canceled.
 	ExitCodeDetailed ExitCodeMode = "detailed"
 	// ExitCodeSummary returns the legacy pass/fail/usage-style code for reports.
 	ExitCodeSummary ExitCodeMode = "summary"
@@ -30,6 +31,7 @@ const (
 	ExitFilesystem = 25
 	ExitProtocol   = 26
 	ExitRoute      = 27
+	ExitContract   = 28
 	ExitCanceled   = 130
 )
 
@@ -47,6 +49,7 @@ const (
 	FailureFilesystem  FailureCode = "filesystem"
 	FailureProtocol    FailureCode = "protocol"
 	FailureRoute       FailureCode = "route"
+	FailureContract    FailureCode = "contract"
 	FailureCanceled    FailureCode = "canceled"
 	FailureInternal    FailureCode = "internal"
 	FailureUnknown     FailureCode = "unknown"
@@ -65,6 +68,7 @@ const (
 	CategoryFilesystem FailureCategory = "filesystem"
 	CategoryProtocol   FailureCategory = "protocol"
 	CategoryRoute      FailureCategory = "route"
+	CategoryContract   FailureCategory = "contract"
 	CategoryCanceled   FailureCategory = "canceled"
 	CategoryInternal   FailureCategory = "internal"
 )
@@ -22,6 +22,7 @@ func TestFailureConstantsMatchInternalValues(t *testing.T) {
 		{name: "FailureFilesystem", public: FailureFilesystem, internal: runfail.CodeFilesystem},
 		{name: "FailureProtocol", public: FailureProtocol, internal: runfail.CodeProtocol},
 		{name: "FailureRoute", public: FailureRoute, internal: runfail.CodeRoute},
+		{name: "FailureContract", public: FailureContract, internal: runfail.CodeContract},
 		{name: "FailureCanceled", public: FailureCanceled, internal: runfail.CodeCanceled},
 		{name: "FailureInternal", public: FailureInternal, internal: runfail.CodeInternal},
 		{name: "FailureUnknown", public: FailureUnknown, internal: runfail.CodeUnknown},
@@ -50,6 +51,7 @@ func TestFailureConstantsMatchInternalValues(t *testing.T) {
 		},
 		{name: "CategoryProtocol", public: CategoryProtocol, internal: runfail.CategoryProtocol},
 		{name: "CategoryRoute", public: CategoryRoute, internal: runfail.CategoryRoute},
+		{name: "CategoryContract", public: CategoryContract, internal: runfail.CategoryContract},
 		{name: "CategoryCanceled", public: CategoryCanceled, internal: runfail.CategoryCanceled},
 		{name: "CategoryInternal", public: CategoryInternal, internal: runfail.CategoryInternal},
 	}
@@ -39,9 +39,16 @@ type Options struct {
 	Environment   EnvironmentOptions `json:"environment,omitempty"`
 	Compare       CompareOptions     `json:"compare,omitempty"`
 	Profile       ProfileOptions     `json:"profile,omitempty"`
-	HTTP          HTTPOptions        `json:"http,omitempty"`
-	GRPC          GRPCOptions        `json:"grpc,omitempty"`
-	Selection     Selection          `json:"selection,omitempty"`
+	// Contract is an OpenAPI spec that every HTTP response is checked against
+	// when the spec documents its route. Relative paths resolve from the
+	// working directory.
+	Contract string `json:"contract,omitempty"`
+	// UpdateSnapshots rewrites @snapshot files that differ from the response
+	// instead of failing the request.
+	UpdateSnapshots bool        `json:"updateSnapshots,omitempty"`
+	HTTP            HTTPOptions `json:"http,omitempty"`
+	GRPC            GRPCOptions `json:"grpc,omitempty"`
+	Selection       Selection   `json:"selection,omitempty"`
 }
 
 // StateOptions controls artifacts and persisted runtime state.
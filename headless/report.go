@@ -130,6 +130,8 @@ type Result struct {
 	GRPC                 *GRPC
 	Stream               *Stream
 	Trace                *Trace
+	Contract             *Contract
+	Snapshot             *Snapshot
 	Tests                []Test
 	Compare              *Compare
 	Profile              *Profile
@@ -152,7 +154,8 @@ func (r Result) effectiveStatus() Status {
 
 func (r Result) hasFailureEvidence() bool {
 	return r.Failure != nil ||
-		hasFailure(r.Canceled, r.Error, r.ScriptError, r.Trace, r.Tests)
+		hasFailure(r.Canceled, r.Error, r.ScriptError, r.Trace, r.Tests) ||
+		r.Contract.failed()
 }
 
 // Step contains one workflow or compare step result.
@@ -177,6 +180,8 @@ type Step struct {
 	GRPC                 *GRPC
 	Stream               *Stream
 	Trace                *Trace
+	Contract             *Contract
+	Snapshot             *Snapshot
 	Tests                []Test
 }
 
@@ -196,7 +201,8 @@ func (s Step) effectiveStatus() Status {
 
 func (s Step) hasFailureEvidence() bool {
 	return s.Failure != nil ||
-		hasFailure(s.Canceled, s.Error, s.ScriptError, s.Trace, s.Tests)
+		hasFailure(s.Canceled, s.Error, s.ScriptError, s.Trace, s.Tests) ||
+		s.Contract.failed()
 }
 
 // skip wins, otherwise any failure evidence makes the result fail.
@@ -344,6 +350,35 @@ type TraceBreach struct {
 	Over   time.Duration `json:"over,omitempty"`
 }
 
+// Contract lists how a response broke its OpenAPI operation.
+type Contract struct {
+	Spec       string              `json:"spec,omitempty"`
+	Operation  string              `json:"operation,omitempty"`
+	Violations []ContractViolation `json:"violations,omitempty"`
+}
+
+// ContractViolation contains one contract violation. Path is a header name
+// for header violations and a JSON pointer into the body for body ones.
+type ContractViolation struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (c *Contract) failed() bool {
+	return c != nil && len(c.Violations) > 0
+}
+
+// Snapshot is the outcome of an @snapshot check. Status is matched, created,
+// updated, mismatch or error. Diff is a unified diff from the snapshot file to
+// the response and is set only on a mismatch.
+type Snapshot struct {
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
 func toFormatReport(rep *Report) runfmt.Report {
 	if rep == nil {
 		return runfmt.Report{}
@@ -392,13 +427,15 @@ func toFormatResult(res Result) runfmt.Result {
 		ScriptErrorDetail: runfmt.ErrorDetailFromError(
 			errorsFromText(res.ScriptError),
 		),
-		Failure: toFormatResultFailure(res),
-		HTTP:    toFormatHTTP(res.HTTP),
-		GRPC:    toFormatGRPC(res.GRPC),
-		Stream:  toFormatStream(res.Stream),
-		Trace:   toFormatTrace(res.Trace),
-		Compare: toFormatCompare(res.Compare),
-		Profile: toFormatProfile(res.Profile),
+		Failure:  toFormatResultFailure(res),
+		HTTP:     toFormatHTTP(res.HTTP),
+		GRPC:     toFormatGRPC(res.GRPC),
+		Stream:   toFormatStream(res.Stream),
+		Trace:    toFormatTrace(res.Trace),
+		Contract: toFormatContract(res.Contract),
+		Snapshot: (*runfmt.Snapshot)(res.Snapshot),
+		Compare:  toFormatCompare(res.Compare),
+		Profile:  toFormatProfile(res.Profile),
 	}
 	if len(res.Tests) > 0 {
 		out.Tests = make([]runfmt.Test, 0, len(res.Tests))
@@ -443,11 +480,13 @@ func toFormatStep(step Step) runfmt.Step {
 		ScriptErrorDetail: runfmt.ErrorDetailFromError(
 			errorsFromText(step.ScriptError),
 		),
-		Failure: toFormatStepFailure(step),
-		HTTP:    toFormatHTTP(step.HTTP),
-		GRPC:    toFormatGRPC(step.GRPC),
-		Stream:  toFormatStream(step.Stream),
-		Trace:   toFormatTrace(step.Trace),
+		Failure:  toFormatStepFailure(step),
+		HTTP:     toFormatHTTP(step.HTTP),
+		GRPC:     toFormatGRPC(step.GRPC),
+		Stream:   toFormatStream(step.Stream),
+		Trace:    toFormatTrace(step.Trace),
+		Contract: toFormatContract(step.Contract),
+		Snapshot: (*runfmt.Snapshot)(step.Snapshot),
 	}
 	if len(step.Tests) > 0 {
 		out.Tests = make([]runfmt.Test, 0, len(step.Tests))
@@ -570,6 +609,8 @@ func toFormatResultFailure(res Result) *runfmt.Failure {
 		return runfmt.FromFailure(runfail.FromErrorSource(errorsFromText(res.Error), "error"))
 	case res.ScriptError != "":
 		return runfmt.FromFailure(runfail.Script(res.ScriptError, "scriptError"))
+	case res.Contract.failed():
+		return runfmt.FromFailure(runfail.Contract(runfmt.ContractMessage(toFormatContract(res.Contract))))
 	case anyTestFailed(res.Tests):
 		return runfmt.FromFailure(runfail.Assertion(publicTestFailureMessage(res.Tests), "tests"))
 	case traceFailed(res.Trace):
@@ -593,6 +634,8 @@ func toFormatStepFailure(step Step) *runfmt.Failure {
 		return runfmt.FromFailure(runfail.FromErrorSource(errorsFromText(step.Error), "error"))
 	case step.ScriptError != "":
 		return runfmt.FromFailure(runfail.Script(step.ScriptError, "scriptError"))
+	case step.Contract.failed():
+		return runfmt.FromFailure(runfail.Contract(runfmt.ContractMessage(toFormatContract(step.Contract))))
 	case anyTestFailed(step.Tests):
 		return runfmt.FromFailure(runfail.Assertion(publicTestFailureMessage(step.Tests), "tests"))
 	case traceFailed(step.Trace):
@@ -698,3 +741,17 @@ func toFormatTrace(trace *Trace) *runfmt.Trace {
 	}
 	return out
 }
+
+func toFormatContract(c *Contract) *runfmt.Contract {
+	if c == nil {
+		return nil
+	}
+	out := &runfmt.Contract{Spec: c.Spec, Operation: c.Operation}
+	if len(c.Violations) > 0 {
+		out.Violations = make([]runfmt.ContractViolation, 0, len(c.Violations))
+		for _, v := range c.Violations {
+			out.Violations = append(out.Violations, runfmt.ContractViolation(v))
+		}
+	}
+	return out
+}
@@ -0,0 +1,295 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// TrendSample is one run of a request: its total latency and, for traced
+// runs, the time spent in each network phase.
+type TrendSample struct {
+	At     time.Time
+	Total  time.Duration
+	Phases []TrendPhase
+}
+
+type TrendPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// TrendOptions tune ComputeTrend. Zero values pick the defaults.
+type TrendOptions struct {
+	// Recent is the number of newest samples compared against the rest.
+	// Zero uses a third of the samples.
+	Recent int
+	// Since, when positive, makes the recent window every sample within
+	// Since of the newest one instead.
+	Since time.Duration
+	// Buckets is the number of equal-count buckets the bands are drawn from.
+	Buckets int
+	// Alpha is the significance level of the regression test.
+	Alpha float64
+	// MinShift is the smallest relative rise of the median worth flagging,
+	// so a significant but negligible change stays quiet.
+	MinShift float64
+}
+
+const (
+	TrendMetricTotal = "total"
+
+	defaultTrendBuckets  = 12
+	defaultTrendAlpha    = 0.01
+	defaultTrendMinShift = 0.1
+	// trendMinWindow is the smallest window the rank test runs on; below it
+	// the normal approximation is too rough to trust.
+	trendMinWindow = 5
+)
+
+var trendPercentiles = []int{50, 90, 99}
+
+type Trend struct {
+	Samples int              `json:"samples"`
+	First   time.Time        `json:"first"`
+	Last    time.Time        `json:"last"`
+	Buckets []TrendBucket    `json:"buckets"`
+	Metrics []MetricTrend    `json:"metrics"`
+	Options TrendOptionsUsed `json:"options"`
+}
+
+// TrendOptionsUsed records the resolved options a trend was computed with.
+type TrendOptionsUsed struct {
+	Alpha    float64 `json:"alpha"`
+	MinShift float64 `json:"minShift"`
+}
+
+// TrendBucket holds the total-latency percentiles of consecutive samples.
+type TrendBucket struct {
+	Start time.Time     `json:"start"`
+	End   time.Time     `json:"end"`
+	Count int           `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+}
+
+// MetricTrend compares the recent window of one metric, the total or a
+// phase, against the older baseline. P is the one-sided Mann-Whitney U
+// p-value for "recent is slower"; it is 1 when a window was too small to
+// test.
+type MetricTrend struct {
+	Name      string          `json:"name"`
+	Values    []time.Duration `json:"-"`
+	Baseline  LatencyStats    `json:"baseline"`
+	Recent    LatencyStats    `json:"recent"`
+	Shift     float64         `json:"shift"`
+	P         float64         `json:"p"`
+	Tested    bool            `json:"tested"`
+	Regressed bool            `json:"regressed"`
+}
+
+// ComputeTrend aggregates samples, which must be sorted oldest first, into
+// percentile bands and per-metric regression checks. The total comes first,
+// then phases in the order runs recorded them.
+func ComputeTrend(samples []TrendSample, opt TrendOptions) Trend {
+	opt = opt.withDefaults()
+	tr := Trend{
+		Samples: len(samples),
+		Options: TrendOptionsUsed{Alpha: opt.Alpha, MinShift: opt.MinShift},
+	}
+	if len(samples) == 0 {
+		return tr
+	}
+	tr.First = samples[0].At
+	tr.Last = samples[len(samples)-1].At
+	tr.Buckets = trendBuckets(samples, opt.Buckets)
+
+	split := recentStart(samples, opt)
+	tr.Metrics = append(tr.Metrics, metricTrend(TrendMetricTotal, samples, split, opt,
+		func(s TrendSample) (time.Duration, bool) { return s.Total, s.Total > 0 }))
+	for _, name := range phaseNames(samples) {
+		tr.Metrics = append(tr.Metrics, metricTrend(name, samples, split, opt,
+			func(s TrendSample) (time.Duration, bool) {
+				for _, p := range s.Phases {
+					if p.Name == name {
+						return p.Duration, true
+					}
+				}
+				return 0, false
+			}))
+	}
+	return tr
+}
+
+// Regressions returns the metrics flagged as regressed.
+func (t Trend) Regressions() []MetricTrend {
+	var out []MetricTrend
+	for _, m := range t.Metrics {
+		if m.Regressed {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (o TrendOptions) withDefaults() TrendOptions {
+	if o.Buckets <= 0 {
+		o.Buckets = defaultTrendBuckets
+	}
+	if o.Alpha <= 0 || o.Alpha >= 1 {
+		o.Alpha = defaultTrendAlpha
+	}
+	if o.MinShift <= 0 {
+		o.MinShift = defaultTrendMinShift
+	}
+	return o
+}
+
+// recentStart is the index of the first sample in the recent window.
+func recentStart(samples []TrendSample, opt TrendOptions) int {
+	n := len(samples)
+	if opt.Since > 0 {
+		cut := samples[n-1].At.Add(-opt.Since)
+		i, _ := slices.BinarySearchFunc(samples, cut, func(s TrendSample, t time.Time) int {
+			return s.At.Compare(t)
+		})
+		return i
+	}
+	k := opt.Recent
+	if k <= 0 {
+		k = n / 3
+	}
+	return max(n-k, 0)
+}
+
+func metricTrend(
+	name string,
+	samples []TrendSample,
+	split int,
+	opt TrendOptions,
+	get func(TrendSample) (time.Duration, bool),
+) MetricTrend {
+	m := MetricTrend{Name: name, P: 1}
+	var base, recent []time.Duration
+	for i, s := range samples {
+		d, ok := get(s)
+		if !ok {
+			continue
+		}
+		m.Values = append(m.Values, d)
+		if i < split {
+			base = append(base, d)
+		} else {
+			recent = append(recent, d)
+		}
+	}
+	m.Baseline = ComputeLatencyStats(base, trendPercentiles, 0)
+	m.Recent = ComputeLatencyStats(recent, trendPercentiles, 0)
+	if m.Baseline.Median > 0 {
+		m.Shift = float64(m.Recent.Median-m.Baseline.Median) / float64(m.Baseline.Median)
+	}
+	if len(base) >= trendMinWindow && len(recent) >= trendMinWindow {
+		m.Tested = true
+		m.P = MannWhitneyGreater(recent, base)
+		m.Regressed = m.P < opt.Alpha && m.Shift >= opt.MinShift
+	}
+	return m
+}
+
+func phaseNames(samples []TrendSample) []string {
+	var (
+		names []string
+		seen  = make(map[string]struct{})
+	)
+	for _, s := range samples {
+		for _, p := range s.Phases {
+			if _, ok := seen[p.Name]; ok {
+				continue
+			}
+			seen[p.Name] = struct{}{}
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func trendBuckets(samples []TrendSample, n int) []TrendBucket {
+	size := int(math.Ceil(float64(len(samples)) / float64(n)))
+	out := make([]TrendBucket, 0, n)
+	for i := 0; i < len(samples); i += size {
+		part := samples[i:min(i+size, len(samples))]
+		vals := make([]time.Duration, 0, len(part))
+		for _, s := range part {
+			if s.Total > 0 {
+				vals = append(vals, s.Total)
+			}
+		}
+		b := TrendBucket{Start: part[0].At, End: part[len(part)-1].At, Count: len(vals)}
+		if len(vals) > 0 {
+			st := ComputeLatencyStats(vals, trendPercentiles, 0)
+			b.P50, b.P90, b.P99 = st.Percentiles[50], st.Percentiles[90], st.Percentiles[99]
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// MannWhitneyGreater is the one-sided Mann-Whitney U test that values in a
+// tend to be larger than those in b. It returns the p-value from the normal
+// approximation with tie and continuity corrections.
+func MannWhitneyGreater(a, b []time.Duration) float64 {
+	n1, n2 := len(a), len(b)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+	type obs struct {
+		v     time.Duration
+		fromA bool
+	}
+	all := make([]obs, 0, n1+n2)
+	for _, v := range a {
+		all = append(all, obs{v: v, fromA: true})
+	}
+	for _, v := range b {
+		all = append(all, obs{v: v})
+	}
+	slices.SortFunc(all, func(x, y obs) int {
+		switch {
+		case x.v < y.v:
+			return -1
+		case x.v > y.v:
+			return 1
+		}
+		return 0
+	})
+
+	var rankA, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		// Tied values share the average of the ranks they span.
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromA {
+				rankA += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	fn1, fn2 := float64(n1), float64(n2)
+	n := fn1 + fn2
+	u := rankA - fn1*(fn1+1)/2
+	mean := fn1 * fn2 / 2
+	variance := fn1 * fn2 / 12 * ((n + 1) - ties/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (u - mean - 0.5) / math.Sqrt(variance)
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
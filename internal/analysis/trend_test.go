@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func ms(vs ...int) []time.Duration {
+	out := make([]time.Duration, len(vs))
+	for i, v := range vs {
+		out[i] = time.Duration(v) * time.Millisecond
+	}
+	return out
+}
+
+func TestMannWhitneyGreater(t *testing.T) {
+	// Complete separation of two windows of five: U = 25, z = 12/sqrt(275/12).
+	p := MannWhitneyGreater(ms(6, 7, 8, 9, 10), ms(1, 2, 3, 4, 5))
+	want := 0.5 * math.Erfc(12/math.Sqrt(275.0/12)/math.Sqrt2)
+	if math.Abs(p-want) > 1e-12 {
+		t.Fatalf("p = %v, want %v", p, want)
+	}
+	if p := MannWhitneyGreater(ms(1, 2, 3, 4, 5), ms(6, 7, 8, 9, 10)); p < 0.99 {
+		t.Fatalf("faster window should not look slower, p = %v", p)
+	}
+	if p := MannWhitneyGreater(ms(5, 5, 5, 5, 5), ms(5, 5, 5, 5, 5)); p != 1 {
+		t.Fatalf("all-tied windows should be untestable, p = %v", p)
+	}
+}
+
+func trendSamples(totals []time.Duration, connect func(i int) time.Duration) []TrendSample {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]TrendSample, len(totals))
+	for i, d := range totals {
+		out[i] = TrendSample{At: start.Add(time.Duration(i) * time.Hour), Total: d}
+		if connect != nil {
+			out[i].Phases = []TrendPhase{
+				{Name: "dns", Duration: 2 * time.Millisecond},
+				{Name: "connect", Duration: connect(i)},
+			}
+		}
+	}
+	return out
+}
+
+func TestComputeTrendFlagsRegression(t *testing.T) {
+	var totals []time.Duration
+	for i := range 30 {
+		base := 100
+		if i >= 20 {
+			base = 160
+		}
+		totals = append(totals, time.Duration(base+i%7)*time.Millisecond)
+	}
+	samples := trendSamples(totals, func(i int) time.Duration {
+		if i >= 20 {
+			return time.Duration(40+i%3) * time.Millisecond
+		}
+		return time.Duration(10+i%3) * time.Millisecond
+	})
+	tr := ComputeTrend(samples, TrendOptions{Recent: 10, Buckets: 6})
+
+	if tr.Samples != 30 || len(tr.Buckets) != 6 || tr.Buckets[0].Count != 5 {
+		t.Fatalf("trend = %+v", tr)
+	}
+	if len(tr.Metrics) != 3 || tr.Metrics[1].Name != "dns" || tr.Metrics[2].Name != "connect" {
+		t.Fatalf("metrics = %+v", tr.Metrics)
+	}
+	regs := tr.Regressions()
+	if len(regs) != 2 || regs[0].Name != TrendMetricTotal || regs[1].Name != "connect" {
+		t.Fatalf("regressions = %+v", regs)
+	}
+	if regs[0].Shift < 0.5 {
+		t.Fatalf("total shift = %v", regs[0].Shift)
+	}
+}
+
+func TestComputeTrendIgnoresNoiseAndSmallWindows(t *testing.T) {
+	var totals []time.Duration
+	for i := range 30 {
+		totals = append(totals, time.Duration(100+(i*37)%20)*time.Millisecond)
+	}
+	if regs := ComputeTrend(trendSamples(totals, nil), TrendOptions{}).Regressions(); len(regs) != 0 {
+		t.Fatalf("flat series flagged: %+v", regs)
+	}
+
+	tr := ComputeTrend(trendSamples(ms(100, 100, 100, 300, 300, 300), nil), TrendOptions{})
+	if tr.Metrics[0].Tested || tr.Metrics[0].Regressed {
+		t.Fatalf("small windows should not be tested: %+v", tr.Metrics[0])
+	}
+}
+
+func TestComputeTrendSinceWindow(t *testing.T) {
+	samples := trendSamples(ms(100, 100, 100, 100, 100, 200, 200, 200), nil)
+	tr := ComputeTrend(samples, TrendOptions{Since: 2 * time.Hour})
+	if got := tr.Metrics[0].Recent.Count; got != 3 {
+		t.Fatalf("recent window = %d samples, want 3", got)
+	}
+}
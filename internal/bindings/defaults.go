@@ -48,6 +48,7 @@ var (
 	ActionClearZoom               ActionID = "clear_zoom"
 	ActionSendRequest             ActionID = "send_request"
 	ActionExplainRequest          ActionID = "explain_request"
+	ActionCopyRequestSnippet      ActionID = "copy_request_snippet"
 	ActionCancelRun               ActionID = "cancel_run"
 	ActionCopyResponseTab         ActionID = "copy_response_tab"
 	// Legacy compatibility action kept so existing binding files continue to load.
@@ -114,6 +115,7 @@ var definitions = []definition{
 	def(ActionClearZoom, false, "g shift+z"),
 	def(ActionSendRequest, false, "ctrl+enter", "cmd+enter", "alt+enter", "ctrl+j", "ctrl+m"),
 	def(ActionExplainRequest, false, "g x"),
+	def(ActionCopyRequestSnippet, false, "g shift+y"),
 	def(ActionCancelRun, false, "ctrl+c"),
 	def(ActionCopyResponseTab, false, "ctrl+shift+c", "g y"),
 	def(ActionToggleHeaderPreview, false),
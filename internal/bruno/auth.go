@@ -0,0 +1,128 @@
+package bruno
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+var oauthGrants = map[string]string{
+	"client_credentials": "client_credentials",
+	"password":           "password",
+	"authorization_code": "authorization_code",
+}
+
+// auth resolves an auth mode against the auth:<mode> block of the same file.
+// It returns nil when the mode defers to the parent. An unsupported mode is
+// reported and does not pick up folder auth in its place.
+func (cv *converter) auth(f *File, mode, where string) *authMode {
+	switch mode {
+	case "", "inherit":
+		return nil
+	case "none":
+		return &authMode{disabled: true}
+	}
+	spec, err := authSpec(f.Block("auth:"+mode), mode)
+	if err != nil {
+		cv.warn = append(cv.warn, where+": "+err.Error())
+		return &authMode{}
+	}
+	return &authMode{spec: spec}
+}
+
+func authSpec(b *Block, mode string) (*restfile.AuthSpec, error) {
+	get := func(key string) string {
+		if b == nil {
+			return ""
+		}
+		return text(b.Get(key), func(string) {})
+	}
+	switch mode {
+	case "bearer":
+		tok := get("token")
+		if tok == "" {
+			return nil, errors.New("bearer auth has no token and was skipped")
+		}
+		return authOf(restfile.AuthBearer, "token", tok), nil
+	case "basic", "digest":
+		user, pass := get("username"), get("password")
+		if user == "" || pass == "" {
+			return nil, fmt.Errorf("%s auth needs a username and password and was skipped", mode)
+		}
+		kind := restfile.AuthBasic
+		if mode == "digest" {
+			kind = restfile.AuthDigest
+		}
+		return authOf(kind, "username", user, "password", pass), nil
+	case "apikey":
+		name, val := get("key"), get("value")
+		if name == "" || val == "" {
+			return nil, errors.New("apikey auth needs a key and value and was skipped")
+		}
+		place := "header"
+		if get("placement") == "queryparams" {
+			place = "query"
+		}
+		return authOf(restfile.AuthAPIKey, "placement", place, "name", name, "value", val), nil
+	case "oauth2":
+		return oauth2Spec(get)
+	default:
+		return nil, fmt.Errorf("%s auth is not supported and was skipped", mode)
+	}
+}
+
+func oauth2Spec(get func(string) string) (*restfile.AuthSpec, error) {
+	grant := get("grant_type")
+	if grant == "" {
+		grant = "authorization_code"
+	}
+	g, ok := oauthGrants[grant]
+	if !ok {
+		return nil, fmt.Errorf("oauth2 grant %q is not supported and was skipped", grant)
+	}
+	tokenURL := get("access_token_url")
+	if tokenURL == "" {
+		return nil, errors.New("oauth2 auth has no access token URL and was skipped")
+	}
+	params := []string{"token_url", tokenURL, "grant", g}
+	for _, kv := range [][2]string{
+		{"auth_url", "authorization_url"},
+		{"redirect_uri", "callback_url"},
+		{"client_id", "client_id"},
+		{"client_secret", "client_secret"},
+		{"scope", "scope"},
+		{"username", "username"},
+		{"password", "password"},
+		{"state", "state"},
+	} {
+		if v := get(kv[1]); v != "" {
+			params = append(params, kv[0], v)
+		}
+	}
+	clientAuth := "basic"
+	if get("credentials_placement") == "body" {
+		clientAuth = "body"
+	}
+	params = append(params, "client_auth", clientAuth)
+	return authOf(restfile.AuthOAuth2, params...), nil
+}
+
+// grpcAuthErr reports auth that a gRPC call cannot send as metadata.
+func grpcAuthErr(spec *restfile.AuthSpec) error {
+	switch {
+	case spec.Kind() == restfile.AuthDigest:
+		return errors.New("digest auth is not supported on gRPC requests and was skipped")
+	case spec.Kind() == restfile.AuthAPIKey && spec.Params["placement"] == "query":
+		return errors.New("apikey auth in the query is not supported on gRPC requests and was skipped")
+	}
+	return nil
+}
+
+func authOf(kind restfile.AuthKind, kv ...string) *restfile.AuthSpec {
+	params := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		params[kv[i]] = kv[i+1]
+	}
+	return &restfile.AuthSpec{Type: kind, Params: params}
+}
@@ -0,0 +1,122 @@
+package bruno
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restimport"
+)
+
+const (
+	headerContentType = "Content-Type"
+	mimeFormURL       = "application/x-www-form-urlencoded"
+	mimeMultipart     = "multipart/form-data"
+	mimeOctetStream   = "application/octet-stream"
+)
+
+// Bruno sets a Content-Type from the body mode unless the request has one.
+var textModes = map[string]struct {
+	block string
+	mime  string
+}{
+	"json":   {"body:json", "application/json"},
+	"text":   {"body:text", "text/plain"},
+	"xml":    {"body:xml", "application/xml"},
+	"sparql": {"body:sparql", "application/sparql-query"},
+}
+
+// fileRef matches the @file(path) and @contentType(type) markers Bruno puts in
+// form and file body values.
+var (
+	fileRef     = regexp.MustCompile(`@file\(([^)]*)\)`)
+	contentType = regexp.MustCompile(`@contentType\(([^)]*)\)`)
+)
+
+func (cv *converter) body(req *restfile.Request, f *File, mode string, warn func(string)) {
+	if m, ok := textModes[mode]; ok {
+		body := blockText(f, m.block)
+		if strings.TrimSpace(body) == "" {
+			return
+		}
+		req.Body.Text = text(body, warn)
+		restimport.SetDefaultHeader(req.Headers, headerContentType, m.mime)
+		return
+	}
+	switch mode {
+	case "", "none":
+	case "formUrlEncoded":
+		var pairs []string
+		for _, p := range enabled(f.Block("body:form-urlencoded")) {
+			pairs = append(pairs, restimport.FormEscape(p.Key)+"="+restimport.FormEscape(text(p.Value, warn)))
+		}
+		if len(pairs) == 0 {
+			return
+		}
+		req.Body.Text = strings.Join(pairs, "&")
+		restimport.SetDefaultHeader(req.Headers, headerContentType, mimeFormURL)
+	case "multipartForm":
+		body, boundary := multipart(enabled(f.Block("body:multipart-form")), warn)
+		if body == "" {
+			return
+		}
+		req.Body.Text = body
+		// The boundary has to match the body, so a collection header goes.
+		restimport.DelHeader(req.Headers, headerContentType)
+		req.Headers[headerContentType] = []string{mimeMultipart + "; boundary=" + boundary}
+	case "graphql":
+		query := strings.TrimSpace(blockText(f, "body:graphql"))
+		if query == "" {
+			return
+		}
+		req.Body.GraphQL = &restfile.GraphQLBody{
+			Query:     text(query, warn),
+			Variables: text(strings.TrimSpace(blockText(f, "body:graphql:vars")), warn),
+		}
+	case "file":
+		// Bruno keeps every candidate file and disables all but the chosen one.
+		for _, p := range enabled(f.Block("body:file")) {
+			if m := fileRef.FindStringSubmatch(p.Value); m != nil && strings.TrimSpace(m[1]) != "" {
+				req.Body.FilePath = strings.TrimSpace(m[1])
+				if ct := contentType.FindStringSubmatch(p.Value); ct != nil && strings.TrimSpace(ct[1]) != "" {
+					restimport.SetDefaultHeader(req.Headers, headerContentType, strings.TrimSpace(ct[1]))
+				}
+				return
+			}
+		}
+		warn("file body has no selected file and was skipped")
+	default:
+		warn("body mode " + mode + " is not supported and was skipped")
+	}
+}
+
+// multipart reads Bruno's @file and @contentType markers; file parts point at
+// their source path.
+func multipart(pairs []Pair, warn func(string)) (string, string) {
+	var parts []restimport.Part
+	for _, p := range pairs {
+		pt := restimport.Part{Name: p.Key}
+		if ct := contentType.FindStringSubmatch(p.Value); ct != nil {
+			pt.ContentType = strings.TrimSpace(ct[1])
+		}
+		if m := fileRef.FindStringSubmatch(p.Value); m != nil {
+			// Several files in one field are separated by a pipe.
+			files := strings.Split(m[1], "|")
+			pt.File = strings.TrimSpace(files[0])
+			if pt.File == "" {
+				warn("form file " + p.Key + " has no source path and was skipped")
+				continue
+			}
+			if len(files) > 1 {
+				warn("form file " + p.Key + " lists several files; only the first was kept")
+			}
+			if pt.ContentType == "" {
+				pt.ContentType = mimeOctetStream
+			}
+		} else {
+			pt.Value = text(strings.TrimSpace(contentType.ReplaceAllString(p.Value, "")), warn)
+		}
+		parts = append(parts, pt)
+	}
+	return restimport.Multipart(parts)
+}
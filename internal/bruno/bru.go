@@ -0,0 +1,222 @@
+package bruno
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pair is one "key: value" line of a dictionary block. A leading ~ in the file
+// marks it disabled.
+type Pair struct {
+	Key      string
+	Value    string
+	Disabled bool
+}
+
+// Block is one top-level section of a .bru file. Dictionary blocks fill Pairs,
+// text blocks (bodies, scripts, docs) fill Text and list blocks fill List.
+type Block struct {
+	Name  string
+	Pairs []Pair
+	Text  string
+	List  []string
+}
+
+// File is a parsed .bru file with its blocks in source order.
+type File struct {
+	Blocks []*Block
+}
+
+func (f *File) Block(name string) *Block {
+	for _, b := range f.Blocks {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// Get returns the first enabled value for key in the named dictionary block.
+func (f *File) Get(block, key string) string {
+	if b := f.Block(block); b != nil {
+		return b.Get(key)
+	}
+	return ""
+}
+
+func (b *Block) Get(key string) string {
+	for _, p := range b.Pairs {
+		if p.Key == key && !p.Disabled {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// Text blocks keep their content verbatim; everything else is a dictionary
+// unless it opens with "[".
+var textBlocks = map[string]bool{
+	"body:json":            true,
+	"body:text":            true,
+	"body:xml":             true,
+	"body:sparql":          true,
+	"body:graphql":         true,
+	"body:graphql:vars":    true,
+	"script:pre-request":   true,
+	"script:post-response": true,
+	"tests":                true,
+	"docs":                 true,
+}
+
+// ParseBru reads the block format Bruno stores requests, folders, collection
+// settings and environments in.
+func ParseBru(data []byte) (*File, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	f := &File{}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		var name, open string
+		switch {
+		case strings.HasSuffix(line, "{"):
+			name, open = strings.TrimSpace(strings.TrimSuffix(line, "{")), "{"
+		case strings.HasSuffix(line, "["):
+			name, open = strings.TrimSpace(strings.TrimSuffix(line, "[")), "["
+		default:
+			return nil, fmt.Errorf("line %d: expected a block, got %q", i+1, line)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("line %d: block has no name", i+1)
+		}
+		closer := "}"
+		if open == "[" {
+			closer = "]"
+		}
+		// A block ends at its closer in the first column; nested braces in
+		// JSON bodies and scripts are indented.
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimRight(lines[j], " \t") == closer {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: block %q is not closed", i+1, name)
+		}
+		body := lines[i+1 : end]
+		b := &Block{Name: name}
+		switch {
+		case open == "[":
+			for _, l := range body {
+				for item := range strings.SplitSeq(l, ",") {
+					if t := strings.TrimSpace(item); t != "" {
+						b.List = append(b.List, t)
+					}
+				}
+			}
+		case textBlocks[name]:
+			b.Text = dedent(body)
+		default:
+			pairs, err := parsePairs(body)
+			if err != nil {
+				return nil, fmt.Errorf("block %q: %w", name, err)
+			}
+			b.Pairs = pairs
+		}
+		f.Blocks = append(f.Blocks, b)
+		i = end
+	}
+	return f, nil
+}
+
+func parsePairs(lines []string) ([]Pair, error) {
+	var out []Pair
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		p := Pair{}
+		if strings.HasPrefix(line, "~") {
+			p.Disabled = true
+			line = line[1:]
+		}
+		key, val, ok := cutKey(line)
+		if !ok {
+			return nil, fmt.Errorf("expected key: value, got %q", line)
+		}
+		p.Key = key
+		// '''-quoted values run over several lines.
+		if val == "'''" {
+			var buf []string
+			closed := false
+			for i++; i < len(lines); i++ {
+				if strings.TrimSpace(lines[i]) == "'''" {
+					closed = true
+					break
+				}
+				buf = append(buf, lines[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("value of %q is not closed", key)
+			}
+			val = dedent(buf)
+		}
+		p.Value = val
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// cutKey splits at the first colon outside a quoted key.
+func cutKey(line string) (string, string, bool) {
+	if strings.HasPrefix(line, `"`) {
+		end := strings.Index(line[1:], `"`)
+		if end < 0 {
+			return "", "", false
+		}
+		key := line[1 : end+1]
+		rest := strings.TrimSpace(line[end+2:])
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+	key, val, ok := strings.Cut(line, ":")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(val), true
+}
+
+// dedent strips the common indentation Bruno adds to block content.
+func dedent(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+		out[i] = strings.TrimRight(l, " \t")
+	}
+	return strings.Join(out, "\n")
+}
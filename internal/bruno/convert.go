@@ -0,0 +1,360 @@
+package bruno
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/directive"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restimport"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+var methodBlocks = []string{"get", "post", "put", "delete", "patch", "options", "head", "trace", "connect"}
+
+// inherited is what a folder passes down to the requests below it.
+type inherited struct {
+	restimport.Scope[*authMode]
+	headers []Pair
+}
+
+// authMode is a resolved auth setting: nil means nothing set yet.
+type authMode struct {
+	spec     *restfile.AuthSpec
+	disabled bool
+}
+
+type converter struct {
+	doc   *restfile.Document
+	warn  []string
+	names map[string]int
+	// fileAuth is set when collection.bru carries auth, which becomes a file
+	// profile every request uses unless it opts out.
+	fileAuth bool
+}
+
+// Convert turns a collection into a single document. Folders become tags,
+// collection auth and variables become file-level settings, and folder auth is
+// applied to the requests that inherit it.
+func Convert(c *Collection) (*restfile.Document, []string) {
+	cv := &converter{doc: &restfile.Document{}, names: map[string]int{}}
+	in := inherited{}
+	if s := c.Settings; s != nil {
+		where := "collection"
+		if m := cv.auth(s, s.Get("auth", "mode"), where); m != nil && m.spec != nil {
+			cv.doc.Auth = []restfile.AuthProfile{{Scope: directive.ScopeFile, Spec: *m.spec}}
+			cv.fileAuth = true
+		}
+		cv.doc.Variables = cv.vars(s, directive.ScopeFile, where)
+		in.headers = enabled(s.Block("headers"))
+		cv.unsupported(s, where)
+	}
+	cv.folder(c.Root, in)
+	return cv.doc, cv.warn
+}
+
+func (cv *converter) folder(f *Folder, in inherited) {
+	if s := f.Settings; s != nil {
+		where := fmt.Sprintf("folder %q", f.Name)
+		m := cv.auth(s, s.Get("auth", "mode"), where)
+		in.headers = append(append([]Pair(nil), in.headers...), enabled(s.Block("headers"))...)
+		in.Scope = in.Folder(m, m != nil, cv.vars(s, directive.ScopeRequest, where))
+		cv.unsupported(s, where)
+	}
+	for _, r := range f.Requests {
+		cv.request(r, in)
+	}
+	for _, sub := range f.Folders {
+		next := in
+		next.Scope = in.Tag(slug(sub.Name))
+		cv.folder(sub, next)
+	}
+}
+
+func (cv *converter) request(r *Request, in inherited) {
+	file := r.File
+	name := cv.uniqueName(strings.Join(strings.Fields(r.Name()), " "))
+	where := fmt.Sprintf("request %q", name)
+	warn := func(msg string) { cv.warn = append(cv.warn, where+": "+msg) }
+
+	req := &restfile.Request{Headers: http.Header{}}
+	req.Metadata.Name = name
+	req.Metadata.Description = strings.TrimSpace(blockText(file, "docs"))
+	in.Apply(req)
+	req.Variables = append(req.Variables, cv.vars(file, directive.ScopeRequest, where)...)
+
+	if g := file.Block("grpc"); g != nil {
+		cv.grpc(req, file, g, in, warn)
+		return
+	}
+	var mb *Block
+	for _, m := range methodBlocks {
+		if mb = file.Block(m); mb != nil {
+			req.Method = strings.ToUpper(m)
+			break
+		}
+	}
+	if mb == nil {
+		warn("has no HTTP method block and was skipped")
+		return
+	}
+
+	target, pathVars := pathParams(text(mb.Get("url"), warn), file.Block("params:path"))
+	req.URL = target
+	for _, v := range pathVars {
+		req.Variables = append(req.Variables, restfile.Variable{Name: v.Key, Value: text(v.Value, warn), Scope: directive.ScopeRequest})
+	}
+	cv.headers(req, append(append([]Pair(nil), in.headers...), enabled(file.Block("headers"))...), warn)
+	cv.body(req, file, mb.Get("body"), warn)
+
+	cv.applyAuth(req, file, mb.Get("auth"), in, warn)
+	cv.unsupported(file, where)
+	if len(req.Headers) == 0 {
+		req.Headers = nil
+	}
+	cv.doc.Requests = append(cv.doc.Requests, req)
+}
+
+// grpc keeps reflection on, as the proto files Bruno points at live outside the
+// collection.
+func (cv *converter) grpc(
+	req *restfile.Request,
+	file *File,
+	g *Block,
+	in inherited,
+	warn func(string),
+) {
+	method := strings.TrimSpace(g.Get("method"))
+	if method == "" {
+		warn("gRPC request has no method and was skipped")
+		return
+	}
+	target := text(strings.TrimSpace(g.Get("url")), warn)
+	rpc := &restfile.GRPCRequest{
+		Target:        target,
+		FullMethod:    "/" + strings.TrimPrefix(method, "/"),
+		UseReflection: true,
+	}
+	if b := file.Block("body:grpc"); b != nil {
+		var msgs []string
+		for _, p := range b.Pairs {
+			if p.Key == "content" && !p.Disabled {
+				msgs = append(msgs, p.Value)
+			}
+		}
+		if len(msgs) > 0 {
+			rpc.Message = text(msgs[0], warn)
+		}
+		if len(msgs) > 1 {
+			warn("gRPC request has several messages; only the first was kept")
+		}
+	}
+	for _, p := range enabled(file.Block("metadata")) {
+		rpc.Metadata = append(rpc.Metadata, restfile.MetadataPair{Key: p.Key, Value: text(p.Value, warn)})
+	}
+	cv.applyAuth(req, file, g.Get("auth"), in, warn)
+	req.Method = "GRPC"
+	req.URL = target
+	req.GRPC = rpc
+	req.Headers = nil
+	cv.unsupported(file, fmt.Sprintf("request %q", req.Metadata.Name))
+	cv.doc.Requests = append(cv.doc.Requests, req)
+}
+
+// applyAuth sets the request's own auth mode, or the folder's when the
+// request inherits. gRPC takes the same auth as HTTP, sent as metadata.
+func (cv *converter) applyAuth(
+	req *restfile.Request,
+	file *File,
+	mode string,
+	in inherited,
+	warn func(string),
+) {
+	own := cv.auth(file, mode, fmt.Sprintf("request %q", req.Metadata.Name))
+	m := in.AuthFor(own, own != nil)
+	switch {
+	case m == nil:
+	case m.disabled:
+		// Without a file profile there is nothing to switch off.
+		req.Metadata.AuthDisabled = cv.fileAuth
+	case m.spec != nil:
+		if g := file.Block("grpc"); g != nil {
+			if err := grpcAuthErr(m.spec); err != nil {
+				warn(err.Error())
+				return
+			}
+		}
+		req.Metadata.Auth = m.spec.Clone()
+	}
+}
+
+// headers applies inherited headers first so a request's own value replaces a
+// folder or collection header of the same name.
+func (cv *converter) headers(req *restfile.Request, pairs []Pair, warn func(string)) {
+	for _, p := range pairs {
+		restimport.DelHeader(req.Headers, p.Key)
+		req.Headers[http.CanonicalHeaderKey(p.Key)] = []string{text(p.Value, warn)}
+	}
+}
+
+func (cv *converter) vars(f *File, scope directive.Scope, where string) []restfile.Variable {
+	var out []restfile.Variable
+	for _, p := range enabled(f.Block("vars:pre-request")) {
+		if strings.ContainsAny(p.Value, "\r\n") {
+			cv.warn = append(cv.warn, fmt.Sprintf("%s: variable %q spans several lines and was skipped", where, p.Key))
+			continue
+		}
+		out = append(out, restfile.Variable{
+			Name:  p.Key,
+			Value: text(p.Value, func(msg string) { cv.warn = append(cv.warn, where+": "+msg) }),
+			Scope: scope,
+		})
+	}
+	return out
+}
+
+// unsupported reports the blocks that run Bruno's JavaScript API, which has no
+// direct resterm counterpart.
+func (cv *converter) unsupported(f *File, where string) {
+	var names []string
+	for _, b := range f.Blocks {
+		switch {
+		case strings.HasPrefix(b.Name, "script:"), b.Name == "tests":
+			if strings.TrimSpace(b.Text) != "" {
+				names = append(names, b.Name)
+			}
+		case b.Name == "assert", b.Name == "vars:post-response":
+			if len(enabled(b)) > 0 {
+				names = append(names, b.Name)
+			}
+		}
+	}
+	if len(names) > 0 {
+		cv.warn = append(cv.warn, fmt.Sprintf("%s: %s not imported", where, strings.Join(names, ", ")))
+	}
+}
+
+func (cv *converter) uniqueName(name string) string {
+	if name == "" {
+		name = "request"
+	}
+	cv.names[name]++
+	if n := cv.names[name]; n > 1 {
+		return fmt.Sprintf("%s %d", name, n)
+	}
+	return name
+}
+
+// Environments maps each file under environments/. Secret values stay in
+// Bruno's local store, so they are written empty and reported.
+func Environments(c *Collection) ([]restwriter.Environment, []string) {
+	var out []restwriter.Environment
+	var warn []string
+	for _, env := range c.Envs {
+		vals := map[string]any{}
+		for _, p := range enabled(env.File.Block("vars")) {
+			vals[p.Key] = envValue(p.Value)
+		}
+		var secrets []string
+		if b := env.File.Block("vars:secret"); b != nil {
+			for _, name := range b.List {
+				if strings.HasPrefix(name, "~") {
+					continue
+				}
+				vals[name] = ""
+				secrets = append(secrets, name)
+			}
+		}
+		if len(secrets) > 0 {
+			sort.Strings(secrets)
+			warn = append(warn, fmt.Sprintf("environment %q: secret values %s are not exported by Bruno and were left empty",
+				env.Name, strings.Join(secrets, ", ")))
+		}
+		out = append(out, restwriter.Environment{Name: env.Name, Values: vals})
+	}
+	return out, warn
+}
+
+var processEnv = regexp.MustCompile(`^\{\{\s*process\.env\.([A-Za-z_]\w*)\s*\}\}$`)
+
+// A value that is only a process.env reference becomes resterm's env:NAME,
+// which reads the same OS variable.
+func envValue(v string) string {
+	if m := processEnv.FindStringSubmatch(strings.TrimSpace(v)); m != nil {
+		return "env:" + m[1]
+	}
+	return text(v, func(string) {})
+}
+
+// Bruno's dynamic variables mostly share resterm's names.
+var dynamicNames = map[string]string{
+	"$randomUUID":   "$uuid",
+	"$isoTimestamp": "$timestampISO8601",
+}
+
+var templateRef = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+func text(s string, warn func(string)) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return templateRef.ReplaceAllStringFunc(s, func(m string) string {
+		name := templateRef.FindStringSubmatch(m)[1]
+		if to, ok := dynamicNames[name]; ok {
+			return "{{" + to + "}}"
+		}
+		if strings.HasPrefix(name, "process.env.") {
+			warn(fmt.Sprintf("{{%s}} reads an OS variable; declare it with @var and env:%s",
+				name, strings.TrimPrefix(name, "process.env.")))
+		}
+		return m
+	})
+}
+
+var pathVar = regexp.MustCompile(`/:([A-Za-z_][\w-]*)`)
+
+// pathParams turns /:id segments into {{id}} references and returns the values
+// Bruno stores for them.
+func pathParams(u string, b *Block) (string, []Pair) {
+	base, query, hasQuery := strings.Cut(u, "?")
+	base = pathVar.ReplaceAllString(base, "/{{$1}}")
+	if hasQuery {
+		base += "?" + query
+	}
+	var vals []Pair
+	for _, p := range enabled(b) {
+		if p.Value != "" {
+			vals = append(vals, p)
+		}
+	}
+	return base, vals
+}
+
+func enabled(b *Block) []Pair {
+	if b == nil {
+		return nil
+	}
+	var out []Pair
+	for _, p := range b.Pairs {
+		if !p.Disabled && p.Key != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func blockText(f *File, name string) string {
+	if b := f.Block(name); b != nil {
+		return b.Text
+	}
+	return ""
+}
+
+var slugSep = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(s string) string {
+	return strings.Trim(slugSep.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
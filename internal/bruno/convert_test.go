@@ -0,0 +1,191 @@
+package bruno
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+func loadCollection(t *testing.T) *Collection {
+	t.Helper()
+	c, err := Load("testdata/shop")
+	if err != nil {
+		t.Fatalf("load collection: %v", err)
+	}
+	return c
+}
+
+// The generated file has to load back through the parser with the same
+// requests, or the import is only half done.
+func roundTrip(t *testing.T, doc *restfile.Document) *restfile.Document {
+	t.Helper()
+	out, err := restwriter.Render(doc, restwriter.Options{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	back := parser.Parse("import.http", []byte(out))
+	if len(back.Errors) != 0 {
+		t.Fatalf("output did not parse: %v\n%s", back.Errors, out)
+	}
+	if len(back.Requests) != len(doc.Requests) {
+		t.Fatalf("%d requests, want %d\n%s", len(back.Requests), len(doc.Requests), out)
+	}
+	return back
+}
+
+func findRequest(t *testing.T, doc *restfile.Document, name string) *restfile.Request {
+	t.Helper()
+	for _, r := range doc.Requests {
+		if r.Metadata.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("request %q not found", name)
+	return nil
+}
+
+func TestConvertCollection(t *testing.T) {
+	c := loadCollection(t)
+	if c.Name != "Shop API" {
+		t.Fatalf("name = %q", c.Name)
+	}
+	doc, warn := Convert(c)
+	back := roundTrip(t, doc)
+
+	var names []string
+	for _, r := range back.Requests {
+		names = append(names, r.Metadata.Name)
+	}
+	if got := strings.Join(names, ","); got != "Health,Graph,Say hello,Get user,Create user,Search,Upload" {
+		t.Fatalf("requests = %s", got)
+	}
+	if len(back.Auth) != 1 || back.Auth[0].Spec.Kind() != restfile.AuthBearer {
+		t.Fatalf("collection auth = %+v", back.Auth)
+	}
+	if len(back.Variables) != 1 || back.Variables[0].Name != "apiVersion" {
+		t.Fatalf("collection vars = %+v", back.Variables)
+	}
+
+	if !findRequest(t, back, "Health").Metadata.AuthDisabled {
+		t.Fatalf("auth none should be written as @auth none under a collection profile")
+	}
+
+	get := findRequest(t, back, "Get user")
+	if get.URL != "{{baseUrl}}/users/{{id}}?verbose=true" {
+		t.Fatalf("url = %q", get.URL)
+	}
+	if len(get.Variables) != 1 || get.Variables[0].Name != "id" || get.Variables[0].Value != "42" {
+		t.Fatalf("path params = %+v", get.Variables)
+	}
+	if get.Headers.Get("X-Request-Id") != "{{$uuid}}" || get.Headers.Get("X-Off") != "" {
+		t.Fatalf("headers = %v", get.Headers)
+	}
+	if got := get.Headers.Values("X-Team"); len(got) != 1 || got[0] != "users" {
+		t.Fatalf("request header did not replace the folder one: %v", got)
+	}
+	if get.Headers.Get("X-Client") != "resterm" {
+		t.Fatalf("collection header missing: %v", get.Headers)
+	}
+	if get.Metadata.Auth == nil || get.Metadata.Auth.Kind() != restfile.AuthBasic {
+		t.Fatalf("folder auth was not inherited: %+v", get.Metadata.Auth)
+	}
+	if get.Metadata.Description != "Fetches one user." || strings.Join(get.Metadata.Tags, " ") != "users" {
+		t.Fatalf("docs/tags = %q %v", get.Metadata.Description, get.Metadata.Tags)
+	}
+
+	create := findRequest(t, back, "Create user")
+	if a := create.Metadata.Auth; a == nil || a.Kind() != restfile.AuthAPIKey || a.Params["placement"] != "query" {
+		t.Fatalf("request auth = %+v", a)
+	}
+	if create.Headers.Get("Content-Type") != "application/json" || !strings.Contains(create.Body.Text, "\n  \"nested\": {") {
+		t.Fatalf("json body = %q, %v", create.Body.Text, create.Headers)
+	}
+
+	search := findRequest(t, back, "Search")
+	if strings.TrimSpace(search.Body.Text) != "q=red+shoes" || strings.Join(search.Metadata.Tags, " ") != "users admin" {
+		t.Fatalf("form body = %q, tags = %v", search.Body.Text, search.Metadata.Tags)
+	}
+
+	upload := findRequest(t, back, "Upload")
+	if !strings.Contains(upload.Body.Text, "@files/avatar.png") || !strings.Contains(upload.Body.Text, "Content-Type: text/plain") {
+		t.Fatalf("multipart body = %q", upload.Body.Text)
+	}
+	if upload.Metadata.Auth != nil {
+		t.Fatalf("unsupported auth picked up folder auth: %+v", upload.Metadata.Auth)
+	}
+
+	gql := findRequest(t, back, "Graph").Body.GraphQL
+	if gql == nil || !strings.HasPrefix(gql.Query, "query User($id: ID!) {\n  user(id: $id) {") || !strings.Contains(gql.Variables, `"id": "1"`) {
+		t.Fatalf("graphql = %+v", gql)
+	}
+
+	say := findRequest(t, back, "Say hello")
+	if say.GRPC == nil || say.GRPC.FullMethod != "/demo.v1.Greeter/SayHello" || len(say.GRPC.Metadata) != 1 {
+		t.Fatalf("grpc = %+v", say.GRPC)
+	}
+	if say.GRPC.Message != "{\n  \"name\": \"{{user}}\"\n}" {
+		t.Fatalf("grpc message = %q", say.GRPC.Message)
+	}
+	if a := say.Metadata.Auth; a == nil || a.Kind() != restfile.AuthAPIKey || a.Params["name"] != "x-api-key" {
+		t.Fatalf("grpc auth = %+v", a)
+	}
+
+	all := strings.Join(warn, "\n")
+	for _, want := range []string{
+		`request "Health": tests not imported`,
+		`request "Create user": script:pre-request not imported`,
+		`request "Upload": awsv4 auth is not supported`,
+	} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("warnings missing %q:\n%s", want, all)
+		}
+	}
+}
+
+func TestEnvironments(t *testing.T) {
+	envs, warn := Environments(loadCollection(t))
+	if len(envs) != 1 || envs[0].Name != "Staging" {
+		t.Fatalf("environments = %+v", envs)
+	}
+	vals := envs[0].Values
+	if vals["baseUrl"] != "https://staging.example.com" || vals["home"] != "env:HOME" {
+		t.Fatalf("values = %v", vals)
+	}
+	if _, ok := vals["old"]; ok {
+		t.Fatalf("disabled value was imported")
+	}
+	if v, ok := vals["token"]; !ok || v != "" {
+		t.Fatalf("secret token = %v", v)
+	}
+	if len(warn) != 1 || !strings.Contains(warn[0], "pass, token") {
+		t.Fatalf("warnings = %v", warn)
+	}
+}
+
+func TestParseBru(t *testing.T) {
+	f, err := ParseBru([]byte("meta {\n  name: x\n}\n\nbody:json {\n  {\n    \"a\": 1\n  }\n}\n\nvars:secret [\n  a,\n  ~b\n]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Get("meta", "name") != "x" || f.Block("body:json").Text != "{\n  \"a\": 1\n}" {
+		t.Fatalf("blocks = %+v", f.Blocks)
+	}
+	if got := f.Block("vars:secret").List; len(got) != 2 || got[1] != "~b" {
+		t.Fatalf("list = %v", got)
+	}
+
+	for _, in := range []string{"meta {\n  name: x\n", "url: x\n", "headers {\n  no colon\n}\n"} {
+		if _, err := ParseBru([]byte(in)); err == nil {
+			t.Fatalf("ParseBru accepted %q", in)
+		}
+	}
+}
+
+func TestLoadRequiresManifest(t *testing.T) {
+	if _, err := Load(t.TempDir()); err == nil || !strings.Contains(err.Error(), "bruno.json") {
+		t.Fatalf("err = %v", err)
+	}
+}
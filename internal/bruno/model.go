@@ -0,0 +1,188 @@
+package bruno
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	manifestName   = "bruno.json"
+	collectionFile = "collection.bru"
+	folderFile     = "folder.bru"
+	envDir         = "environments"
+	bruExt         = ".bru"
+)
+
+// Collection is a Bruno collection directory. Settings is collection.bru and
+// may be nil.
+type Collection struct {
+	Name     string
+	Settings *File
+	Root     *Folder
+	Envs     []Env
+}
+
+type Folder struct {
+	Name string
+	// Settings is folder.bru and may be nil.
+	Settings *File
+	Requests []*Request
+	Folders  []*Folder
+	seq      int
+}
+
+type Request struct {
+	// Path is relative to the collection root, for warnings.
+	Path string
+	File *File
+	seq  int
+}
+
+func (r *Request) Name() string {
+	if n := strings.TrimSpace(r.File.Get("meta", "name")); n != "" {
+		return n
+	}
+	return strings.TrimSuffix(filepath.Base(r.Path), bruExt)
+}
+
+type Env struct {
+	Name string
+	File *File
+}
+
+// Load reads the collection rooted at dir. The directory has to carry the
+// bruno.json manifest Bruno writes for every collection.
+func Load(dir string) (*Collection, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("bruno: %s is not a Bruno collection (missing %s)", dir, manifestName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bruno: read %s: %w", manifestName, err)
+	}
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("bruno: parse %s: %w", manifestName, err)
+	}
+
+	c := &Collection{Name: strings.TrimSpace(manifest.Name)}
+	if c.Name == "" {
+		c.Name = filepath.Base(filepath.Clean(dir))
+	}
+	if c.Settings, err = readOptional(dir, collectionFile); err != nil {
+		return nil, err
+	}
+	if c.Root, err = loadFolder(dir, ""); err != nil {
+		return nil, err
+	}
+	if c.Envs, err = loadEnvs(filepath.Join(dir, envDir)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func loadFolder(root, rel string) (*Folder, error) {
+	dir := filepath.Join(root, rel)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("bruno: read %s: %w", dir, err)
+	}
+	f := &Folder{Name: filepath.Base(rel)}
+	if rel != "" {
+		if f.Settings, err = readOptional(dir, folderFile); err != nil {
+			return nil, err
+		}
+		if f.Settings != nil {
+			if n := strings.TrimSpace(f.Settings.Get("meta", "name")); n != "" {
+				f.Name = n
+			}
+			f.seq = seqOf(f.Settings)
+		}
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		path := filepath.Join(rel, name)
+		switch {
+		case strings.HasPrefix(name, "."), name == "node_modules":
+		case e.IsDir():
+			if rel == "" && name == envDir {
+				continue
+			}
+			sub, err := loadFolder(root, path)
+			if err != nil {
+				return nil, err
+			}
+			f.Folders = append(f.Folders, sub)
+		case !strings.HasSuffix(name, bruExt), name == folderFile, rel == "" && name == collectionFile:
+		default:
+			file, err := readBru(filepath.Join(root, path))
+			if err != nil {
+				return nil, err
+			}
+			f.Requests = append(f.Requests, &Request{Path: filepath.ToSlash(path), File: file, seq: seqOf(file)})
+		}
+	}
+	sort.SliceStable(f.Requests, func(i, j int) bool { return f.Requests[i].seq < f.Requests[j].seq })
+	sort.SliceStable(f.Folders, func(i, j int) bool { return f.Folders[i].seq < f.Folders[j].seq })
+	return f, nil
+}
+
+func loadEnvs(dir string) ([]Env, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bruno: read %s: %w", dir, err)
+	}
+	var out []Env
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), bruExt) {
+			continue
+		}
+		file, err := readBru(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Env{Name: strings.TrimSuffix(e.Name(), bruExt), File: file})
+	}
+	return out, nil
+}
+
+func readOptional(dir, name string) (*File, error) {
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return readBru(path)
+}
+
+func readBru(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("bruno: read %s: %w", path, err)
+	}
+	f, err := ParseBru(data)
+	if err != nil {
+		return nil, fmt.Errorf("bruno: %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Entries without a sequence sort last, in directory order.
+func seqOf(f *File) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(f.Get("meta", "seq"))); err == nil {
+		return n
+	}
+	return int(^uint(0) >> 1)
+}
@@ -0,0 +1,83 @@
+package bruno
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+const errWriterNotConfigured = "bruno: writer not configured"
+
+type DocumentWriter interface {
+	WriteDocument(ctx context.Context, doc *restfile.Document, dst string, opts WriterOptions) error
+}
+
+type WriterOptions struct {
+	OverwriteExisting bool
+	HeaderComment     string
+}
+
+// Result lists what an import wrote.
+type Result struct {
+	File    string
+	EnvFile string
+}
+
+type Service struct {
+	Writer DocumentWriter
+}
+
+// Import converts the collection directory src into the .http file dst.
+// Environments land in resterm.env.json beside it.
+func (s *Service) Import(ctx context.Context, src, dst string, opts WriterOptions) (Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if s.Writer == nil {
+		return Result{}, errors.New(errWriterNotConfigured)
+	}
+
+	c, err := Load(src)
+	if err != nil {
+		return Result{}, err
+	}
+	doc, warn := Convert(c)
+	if len(doc.Requests) == 0 {
+		return Result{}, errors.New("bruno: collection has no requests")
+	}
+	envs, envWarn := Environments(c)
+	warn = append(warn, envWarn...)
+
+	opts.HeaderComment = buildHeader(opts.HeaderComment, c.Name, warn)
+	if err := s.Writer.WriteDocument(ctx, doc, dst, opts); err != nil {
+		return Result{}, err
+	}
+	res := Result{File: dst}
+	if len(envs) > 0 {
+		res.EnvFile = filepath.Join(filepath.Dir(dst), restwriter.EnvFileName)
+		if err := restwriter.MergeEnvironments(res.EnvFile, envs); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func buildHeader(base, name string, warn []string) string {
+	var lines []string
+	for line := range strings.SplitSeq(base, "\n") {
+		if t := strings.TrimSpace(line); t != "" {
+			lines = append(lines, t)
+		}
+	}
+	lines = append(lines, "Source: Bruno collection "+name)
+	for _, w := range warn {
+		if t := strings.TrimSpace(w); t != "" {
+			lines = append(lines, "Warning: "+t)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,41 @@
+package bruno
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+func TestImportWritesFileAndEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "shop.http")
+	svc := Service{Writer: NewFileWriter()}
+	res, err := svc.Import(context.Background(), "testdata/shop", dst, WriterOptions{})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if res.File != dst || res.EnvFile != filepath.Join(dir, restwriter.EnvFileName) {
+		t.Fatalf("result = %+v", res)
+	}
+	out, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Source: Bruno collection Shop API",
+		`# Warning: environment "Staging": secret values pass, token`,
+		"# @auth file bearer {{token}}",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+	env, err := os.ReadFile(res.EnvFile)
+	if err != nil || !strings.Contains(string(env), `"home": "env:HOME"`) {
+		t.Fatalf("env file = %s, %v", env, err)
+	}
+}
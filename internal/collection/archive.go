@@ -57,72 +57,17 @@ func PackBundle(o PackOptions) (PackResult, error) {
 		return PackResult{}, err
 	}
 
-	raw := make([][]byte, len(mf.Files))
+	fs := make([]bundleFile, len(mf.Files))
 	for i, f := range mf.Files {
 		b, bErr := readBundlePayload(inAbs, inReal, f)
 		if bErr != nil {
 			return PackResult{}, bErr
 		}
-		raw[i] = b
+		fs[i] = bundleFile{Path: f.Path, Data: b}
 	}
-
-	par := filepath.Dir(outAbs)
-	if err := os.MkdirAll(par, 0o755); err != nil {
-		return PackResult{}, fmt.Errorf("create output parent dir: %w", err)
-	}
-	parReal := par
-	if p, pErr := filepath.EvalSymlinks(par); pErr == nil {
-		parReal = p
-	}
-	outReal := filepath.Join(parReal, filepath.Base(outAbs))
-
-	if !o.Force {
-		if _, err := os.Stat(outReal); err == nil {
-			return PackResult{}, fmt.Errorf("output path already exists: %s", outAbs)
-		} else if !errors.Is(err, os.ErrNotExist) {
-			return PackResult{}, fmt.Errorf("stat output path: %w", err)
-		}
-	}
-
-	tmp, err := os.CreateTemp(parReal, ".resterm-collection-pack-*.zip")
-	if err != nil {
-		return PackResult{}, fmt.Errorf("create temp archive: %w", err)
-	}
-	tmpPath := tmp.Name()
-	ok := false
-	defer func() {
-		if !ok {
-			_ = os.Remove(tmpPath)
-		}
-	}()
-
-	zw := zip.NewWriter(tmp)
-	if err := addZipFile(zw, ManifestFile, man); err != nil {
-		_ = zw.Close()
+	if err := writeBundleZip(outAbs, man, fs, o.Force); err != nil {
 		return PackResult{}, err
 	}
-	for i, f := range mf.Files {
-		if err := addZipFile(zw, f.Path, raw[i]); err != nil {
-			_ = zw.Close()
-			return PackResult{}, err
-		}
-	}
-	if err := zw.Close(); err != nil {
-		return PackResult{}, fmt.Errorf("finalize archive: %w", err)
-	}
-	if err := tmp.Close(); err != nil {
-		return PackResult{}, fmt.Errorf("close archive: %w", err)
-	}
-
-	if o.Force {
-		if err := os.RemoveAll(outReal); err != nil {
-			return PackResult{}, fmt.Errorf("remove previous output: %w", err)
-		}
-	}
-	if err := os.Rename(tmpPath, outReal); err != nil {
-		return PackResult{}, fmt.Errorf("move archive into place: %w", err)
-	}
-	ok = true
 
 	return PackResult{
 		BundleDir: inAbs,
@@ -220,6 +165,69 @@ func UnpackBundle(o UnpackOptions) (UnpackResult, error) {
 	}, nil
 }
 
+// writeBundleZip writes the manifest and files to outAbs through a temp
+// file, so a failed write never leaves a partial archive behind.
+func writeBundleZip(outAbs string, man []byte, files []bundleFile, force bool) error {
+	par := filepath.Dir(outAbs)
+	if err := os.MkdirAll(par, 0o755); err != nil {
+		return fmt.Errorf("create output parent dir: %w", err)
+	}
+	parReal := par
+	if p, pErr := filepath.EvalSymlinks(par); pErr == nil {
+		parReal = p
+	}
+	outReal := filepath.Join(parReal, filepath.Base(outAbs))
+
+	if !force {
+		if _, err := os.Stat(outReal); err == nil {
+			return fmt.Errorf("output path already exists: %s", outAbs)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("stat output path: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(parReal, ".resterm-collection-pack-*.zip")
+	if err != nil {
+		return fmt.Errorf("create temp archive: %w", err)
+	}
+	tmpPath := tmp.Name()
+	ok := false
+	defer func() {
+		if !ok {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	zw := zip.NewWriter(tmp)
+	if err := addZipFile(zw, ManifestFile, man); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	for _, f := range files {
+		if err := addZipFile(zw, f.Path, f.Data); err != nil {
+			_ = zw.Close()
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finalize archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+
+	if force {
+		if err := os.RemoveAll(outReal); err != nil {
+			return fmt.Errorf("remove previous output: %w", err)
+		}
+	}
+	if err := os.Rename(tmpPath, outReal); err != nil {
+		return fmt.Errorf("move archive into place: %w", err)
+	}
+	ok = true
+	return nil
+}
+
 func addZipFile(zw *zip.Writer, name string, data []byte) error {
 	p, err := NormRelPath(name)
 	if err != nil {
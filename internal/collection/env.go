@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/unkn0wn-root/resterm/internal/envcrypt"
 	"github.com/unkn0wn-root/resterm/internal/vars"
 )
 
@@ -119,6 +120,12 @@ func redactAny(v any) any {
 			out[i] = redactAny(t[i])
 		}
 		return out
+	case string:
+		// Ciphertext is safe to share and useless without the key.
+		if strings.HasPrefix(t, envcrypt.ValuePrefix) {
+			return t
+		}
+		return envPlaceholder
 	default:
 		return envPlaceholder
 	}
@@ -8,6 +8,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/unkn0wn-root/resterm/internal/envcrypt"
 	"github.com/unkn0wn-root/resterm/internal/files"
 	"github.com/unkn0wn-root/resterm/internal/parser"
 )
@@ -69,6 +70,13 @@ func ExportBundle(o ExportOptions) (ExportResult, error) {
 	if err := addFile(byPath, defaultEnvTemplateFile, RoleEnvTemplate, envData); err != nil {
 		return ExportResult{}, err
 	}
+	if data, ok, err := readWorkspaceFileIfExists(wsAbs, wsReal, envcrypt.DefaultFile); err != nil {
+		return ExportResult{}, err
+	} else if ok {
+		if err := addFile(byPath, envcrypt.DefaultFile, RoleEnvEncrypted, data); err != nil {
+			return ExportResult{}, err
+		}
+	}
 
 	name := strings.TrimSpace(o.Name)
 	if name == "" {
@@ -161,7 +169,7 @@ func roleRank(r FileRole) int {
 	switch r {
 	case RoleRequest:
 		return 4
-	case RoleEnvTemplate:
+	case RoleEnvTemplate, RoleEnvEncrypted:
 		return 3
 	case RoleScript:
 		return 2
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/envcrypt"
 )
 
 func TestExportBundleCollectsDepsAndEnvTemplate(t *testing.T) {
@@ -171,6 +173,53 @@ func TestExportBundlePrefersExistingEnvExample(t *testing.T) {
 	}
 }
 
+func TestExportBundleCopiesEncryptedEnv(t *testing.T) {
+	ws := t.TempDir()
+	writeFile(t, ws, "requests.http", "GET https://example.com\n")
+	writeFile(t, ws, defaultEnvSourceFile, `{"dev":{"token":"secret","key":"enc:YWdl"}}`)
+	cipher := "-----BEGIN AGE ENCRYPTED FILE-----\nYWdl\n-----END AGE ENCRYPTED FILE-----\n"
+	writeFile(t, ws, envcrypt.DefaultFile, cipher)
+
+	out := filepath.Join(t.TempDir(), "bundle")
+	res, err := ExportBundle(ExportOptions{Workspace: ws, OutDir: out})
+	if err != nil {
+		t.Fatalf("export bundle: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(out, envcrypt.DefaultFile))
+	if err != nil {
+		t.Fatalf("read exported encrypted env: %v", err)
+	}
+	if string(got) != cipher {
+		t.Fatalf("encrypted env should be copied as-is, got %q", got)
+	}
+	raw, err := os.ReadFile(res.ManifestPath)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	mf, err := DecodeManifest(raw)
+	if err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	found := false
+	for _, f := range mf.Files {
+		if f.Path == envcrypt.DefaultFile {
+			found = f.Role == RoleEnvEncrypted
+		}
+	}
+	if !found {
+		t.Fatalf("manifest has no %s entry: %#v", RoleEnvEncrypted, mf.Files)
+	}
+
+	tpl, err := os.ReadFile(filepath.Join(out, defaultEnvTemplateFile))
+	if err != nil {
+		t.Fatalf("read env template: %v", err)
+	}
+	if !strings.Contains(string(tpl), `"enc:YWdl"`) || strings.Contains(string(tpl), "secret") {
+		t.Fatalf("template should keep enc: values and redact the rest, got %s", tpl)
+	}
+}
+
 func TestExportBundleRejectsOutsideDependency(t *testing.T) {
 	ws := t.TempDir()
 	parent := filepath.Dir(ws)
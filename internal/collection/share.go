@@ -0,0 +1,406 @@
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/engine/request"
+	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+const (
+	shareRequestFile    = "request.http"
+	shareResponseFile   = "response.txt"
+	shareTranscriptFile = "transcript.txt"
+	shareTraceFile      = "trace.json"
+	shareExplainFile    = "explain.json"
+	shareHistoryFile    = "history.json"
+	shareReadmeFile     = "README.md"
+
+	shareEnvName = "shared"
+)
+
+// shareMasks are the stand-ins history writes for secret values: the engine
+// uses asterisks and the TUI bullets.
+var shareMasks = []string{"***", "•••"}
+
+var shareVarRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+type ShareOptions struct {
+	Entry history.Entry
+	// Capture is the entry's full response, when the store kept one.
+	Capture *history.Capture
+	OutFile string
+	Force   bool
+}
+
+type ShareResult struct {
+	OutFile   string
+	FileCount int
+	// Placeholders are the env variables the recipient has to fill in.
+	Placeholders []string
+}
+
+// ShareEntry packages one history entry as a bundle archive: a runnable
+// request, an env file with placeholders for its redacted secrets, the
+// response, the trace and the explain report. History only holds redacted
+// text, and credential and cookie headers are masked again here because
+// @allow-sensitive-headers lets history keep them.
+func ShareEntry(o ShareOptions) (ShareResult, error) {
+	e := o.Entry
+	e.RequestText = maskShareRequest(e.RequestText)
+	o.Capture = maskShareCapture(o.Capture)
+	switch {
+	case e.Method == restfile.HistoryMethodWorkflow:
+		return ShareResult{}, fmt.Errorf("history entry %s is a workflow run", e.ID)
+	case e.Method == restfile.HistoryMethodCompare || e.Compare != nil:
+		return ShareResult{}, fmt.Errorf("history entry %s is a compare run", e.ID)
+	case strings.TrimSpace(e.RequestText) == "":
+		return ShareResult{}, fmt.Errorf("history entry %s has no request text", e.ID)
+	}
+	outAbs, err := cleanAbsPath(o.OutFile, "output")
+	if err != nil {
+		return ShareResult{}, err
+	}
+
+	env := shareEnv(e)
+	reqText, keys := shareRequest(e)
+	vals := make(map[string]string, len(keys))
+	for _, k := range keys {
+		vals[k] = envPlaceholder
+	}
+	envData, err := json.MarshalIndent(map[string]map[string]string{env: vals}, "", "  ")
+	if err != nil {
+		return ShareResult{}, fmt.Errorf("encode share env: %w", err)
+	}
+
+	byPath := make(map[string]expFile)
+	add := func(rel string, role FileRole, data []byte) error {
+		return addFile(byPath, rel, role, ensureTrailingNewline(data))
+	}
+	if err := add(shareRequestFile, RoleRequest, []byte(reqText)); err != nil {
+		return ShareResult{}, err
+	}
+	if err := add(defaultEnvSourceFile, RoleEnvTemplate, envData); err != nil {
+		return ShareResult{}, err
+	}
+	if err := add(shareResponseFile, RoleAsset, []byte(shareResponse(e, o.Capture))); err != nil {
+		return ShareResult{}, err
+	}
+	if o.Capture != nil && len(o.Capture.Transcript) > 0 {
+		if err := add(shareTranscriptFile, RoleAsset, o.Capture.Transcript); err != nil {
+			return ShareResult{}, err
+		}
+	}
+	if e.Trace != nil {
+		data, err := json.MarshalIndent(e.Trace, "", "  ")
+		if err != nil {
+			return ShareResult{}, fmt.Errorf("encode share trace: %w", err)
+		}
+		if err := add(shareTraceFile, RoleAsset, data); err != nil {
+			return ShareResult{}, err
+		}
+	}
+	if e.Explain != nil {
+		data, err := json.MarshalIndent(e.Explain, "", "  ")
+		if err != nil {
+			return ShareResult{}, fmt.Errorf("encode share explain: %w", err)
+		}
+		if err := add(shareExplainFile, RoleAsset, data); err != nil {
+			return ShareResult{}, err
+		}
+	}
+
+	// history.json imports into the recipient's history, so the run shows
+	// up there with its full response. The reporter's file path means
+	// nothing on another machine.
+	he := e
+	he.FilePath = ""
+	he.Capture = o.Capture
+	histData, err := json.MarshalIndent([]history.Entry{he}, "", "  ")
+	if err != nil {
+		return ShareResult{}, fmt.Errorf("encode share history: %w", err)
+	}
+	if err := add(shareHistoryFile, RoleAsset, histData); err != nil {
+		return ShareResult{}, err
+	}
+	if err := add(shareReadmeFile, RoleAsset, []byte(shareReadme(e, env, keys, byPath))); err != nil {
+		return ShareResult{}, err
+	}
+
+	mf, fs := buildManifest(shareName(e), byPath)
+	man, err := EncodeManifest(mf)
+	if err != nil {
+		return ShareResult{}, err
+	}
+	if err := writeBundleZip(outAbs, man, fs, o.Force); err != nil {
+		return ShareResult{}, err
+	}
+	return ShareResult{
+		OutFile:      outAbs,
+		FileCount:    len(mf.Files),
+		Placeholders: keys,
+	}, nil
+}
+
+// shareSecretHeader reports the headers a bundle never carries, whatever the
+// request allowed history to keep: credentials and cookies.
+func shareSecretHeader(name string) bool {
+	name = strings.TrimSpace(name)
+	return request.IsSensitiveHeader(name) ||
+		strings.EqualFold(name, "Cookie") ||
+		strings.EqualFold(name, "Set-Cookie")
+}
+
+// maskShareRequest masks secret header values in recorded request text.
+// shareRequest then turns them into placeholders like any other mask.
+func maskShareRequest(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			break
+		}
+		name, _, ok := strings.Cut(lines[i], ":")
+		if ok && shareSecretHeader(name) {
+			lines[i] = name + ": " + shareMasks[0]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func maskShareCapture(c *history.Capture) *history.Capture {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	cp.Headers = maskShareHeaders(c.Headers)
+	cp.Trailers = maskShareHeaders(c.Trailers)
+	return &cp
+}
+
+func maskShareHeaders(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	out := make(http.Header, len(h))
+	for k, vals := range h {
+		if !shareSecretHeader(k) {
+			out[k] = slices.Clone(vals)
+			continue
+		}
+		masked := make([]string, len(vals))
+		for i := range masked {
+			masked[i] = shareMasks[0]
+		}
+		out[k] = masked
+	}
+	return out
+}
+
+// shareEnv names the bundle's environment after the entry's when that is a
+// plain name. Group selections have no single name to reuse.
+func shareEnv(e history.Entry) string {
+	env := strings.TrimSpace(e.Environment)
+	if env == "" || len(e.EnvironmentSelection) > 0 || strings.ContainsAny(env, "=,") {
+		return shareEnvName
+	}
+	return env
+}
+
+// shareName is the @name for the shared request. Unnamed requests are
+// recorded under their URL, which is no use as a name.
+func shareName(e history.Entry) string {
+	name := strings.TrimSpace(e.RequestName)
+	if name == "" || strings.Contains(name, "://") || strings.HasPrefix(name, "/") ||
+		strings.ContainsAny(name, "\r\n") {
+		return ""
+	}
+	return name
+}
+
+// shareRequest turns the recorded request into a file resterm can run.
+// Masked header values become a variable named after the header, and any
+// other masked value a numbered secret variable. It returns the variables
+// in order of first use.
+func shareRequest(e history.Entry) (string, []string) {
+	var keys []string
+	seen := make(map[string]bool)
+	use := func(k string) string {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+		return "{{" + k + "}}"
+	}
+
+	lines := strings.Split(strings.TrimRight(e.RequestText, "\n"), "\n")
+	for i := 1; i < len(lines); i++ {
+		ln := lines[i]
+		if strings.TrimSpace(ln) == "" {
+			break
+		}
+		name, val, ok := strings.Cut(ln, ":")
+		if !ok || !slices.Contains(shareMasks, strings.TrimSpace(val)) {
+			continue
+		}
+		k := strings.Trim(shareVarRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+		if k == "" {
+			continue
+		}
+		lines[i] = name + ": " + use(k)
+	}
+
+	n := 0
+	for i, ln := range lines {
+		for _, m := range shareMasks {
+			for strings.Contains(ln, m) {
+				n++
+				ln = strings.Replace(ln, m, use(fmt.Sprintf("secret_%d", n)), 1)
+			}
+		}
+		lines[i] = ln
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Shared from resterm history entry %s.\n", e.ID)
+	fmt.Fprintf(&b, "# Recorded %s: %s\n", e.ExecutedAt.UTC().Format(time.RFC3339), shareOutcome(e))
+	b.WriteString("\n###\n")
+	if name := shareName(e); name != "" {
+		fmt.Fprintf(&b, "# @name %s\n", name)
+	}
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n")
+	return b.String(), keys
+}
+
+func shareOutcome(e history.Entry) string {
+	status := strings.TrimSpace(e.Status)
+	if status == "" {
+		status = "no response"
+	}
+	if e.Duration > 0 {
+		return fmt.Sprintf("%s in %s", status, e.Duration.Round(time.Millisecond))
+	}
+	return status
+}
+
+// shareResponse writes the response as a status line, headers and body. It
+// falls back to the snippet when the full response was not captured.
+func shareResponse(e history.Entry, c *history.Capture) string {
+	var b strings.Builder
+	status := strings.TrimSpace(e.Status)
+	if c != nil && c.Proto != "" {
+		status = strings.TrimSpace(c.Proto + " " + status)
+	}
+	b.WriteString(status)
+	b.WriteString("\n")
+	if c == nil {
+		b.WriteString("\n")
+		b.WriteString(e.BodySnippet)
+		b.WriteString("\n\n# Full capture was off, so this is the body snippet only.\n")
+		return b.String()
+	}
+	if c.StatusMessage != "" {
+		fmt.Fprintf(&b, "# %s\n", c.StatusMessage)
+	}
+	writeShareHeaders(&b, c.Headers)
+	b.WriteString("\n")
+	b.Write(c.Body)
+	if c.Truncated && c.BodySize > int64(len(c.Body)) {
+		fmt.Fprintf(&b, "\n\n# Body cut to %d of %d bytes.", len(c.Body), c.BodySize)
+	}
+	if len(c.Trailers) > 0 {
+		b.WriteString("\n\n")
+		writeShareHeaders(&b, c.Trailers)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func writeShareHeaders(b *strings.Builder, h http.Header) {
+	for _, k := range slices.Sorted(maps.Keys(h)) {
+		for _, v := range h[k] {
+			fmt.Fprintf(b, "%s: %s\n", k, v)
+		}
+	}
+}
+
+func shareReadme(e history.Entry, env string, keys []string, byPath map[string]expFile) string {
+	var b strings.Builder
+	title := shareName(e)
+	if title == "" {
+		title = strings.TrimSpace(e.Method + " " + e.URL)
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- Recorded: %s\n", e.ExecutedAt.UTC().Format(time.RFC3339))
+	if env := strings.TrimSpace(e.Environment); env != "" {
+		fmt.Fprintf(&b, "- Environment: %s\n", env)
+	}
+	fmt.Fprintf(&b, "- Result: %s\n", shareOutcome(e))
+	if e.Description != "" {
+		fmt.Fprintf(&b, "- Description: %s\n", e.Description)
+	}
+
+	b.WriteString("\n## Reproduce\n\n")
+	if len(keys) > 0 {
+		fmt.Fprintf(
+			&b,
+			"Secrets were redacted. Replace each %s in %s first: %s.\n\n",
+			envPlaceholder,
+			defaultEnvSourceFile,
+			strings.Join(keys, ", "),
+		)
+	}
+	b.WriteString("```bash\n")
+	fmt.Fprintf(&b, "resterm run --env %s %s\n", env, shareRequestFile)
+	b.WriteString("```\n")
+	fmt.Fprintf(
+		&b,
+		"\nTo see the run in your own history, use `resterm history import --in %s`.\n",
+		shareHistoryFile,
+	)
+
+	if t := e.Trace; t != nil && len(t.Phases) > 0 {
+		b.WriteString("\n## Timeline\n\n")
+		for _, p := range t.Phases {
+			fmt.Fprintf(&b, "- %s: %s", p.Kind, p.Duration.Round(time.Microsecond))
+			if p.Error != "" {
+				fmt.Fprintf(&b, " (%s)", p.Error)
+			}
+			b.WriteString("\n")
+		}
+		for _, br := range t.Breaches {
+			fmt.Fprintf(&b, "- Over budget: %s by %s\n", br.Kind, br.Over.Round(time.Microsecond))
+		}
+	}
+	if x := e.Explain; x != nil {
+		b.WriteString("\n## Explain\n\n")
+		if x.Decision != "" {
+			fmt.Fprintf(&b, "%s\n\n", x.Decision)
+		}
+		for _, st := range x.Stages {
+			fmt.Fprintf(&b, "- %s: %s", st.Name, st.Status)
+			if st.Summary != "" {
+				fmt.Fprintf(&b, ", %s", st.Summary)
+			}
+			b.WriteString("\n")
+		}
+		for _, w := range x.Warnings {
+			fmt.Fprintf(&b, "- Warning: %s\n", w)
+		}
+	}
+
+	b.WriteString("\n## Files\n\n")
+	for _, p := range slices.Sorted(maps.Keys(byPath)) {
+		fmt.Fprintf(&b, "- `%s`\n", p)
+	}
+	fmt.Fprintf(&b, "- `%s`\n", shareReadmeFile)
+	return b.String()
+}
@@ -0,0 +1,181 @@
+package collection
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	xplain "github.com/unkn0wn-root/resterm/internal/explain"
+	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func TestShareEntryBuildsRunnableBundle(t *testing.T) {
+	e := history.Entry{
+		ID:          "42",
+		ExecutedAt:  time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		Environment: "prod",
+		RequestName: "Charge",
+		FilePath:    "/home/me/api/payments.http",
+		Method:      "POST",
+		URL:         "https://api.example.com/charges",
+		Status:      "502 Bad Gateway",
+		StatusCode:  502,
+		Duration:    1500 * time.Millisecond,
+		RequestText: "POST https://api.example.com/charges\n" +
+			"Authorization: ***\n" +
+			"Content-Type: application/json\n\n" +
+			`{"card":"***","amount":10}`,
+		Trace: &history.TraceSummary{
+			Duration: 1500 * time.Millisecond,
+			Phases:   []history.TracePhase{{Kind: "dns", Duration: 2 * time.Millisecond}},
+		},
+		Explain: &xplain.Report{
+			Name:     "Charge",
+			Status:   xplain.StatusReady,
+			Decision: "HTTP request sent",
+			Stages:   []xplain.Stage{{Name: "auth", Status: xplain.StageOK}},
+		},
+	}
+	c := &history.Capture{
+		Proto:   "HTTP/2.0",
+		Headers: http.Header{"Content-Type": {"application/json"}},
+		Body:    []byte(`{"error":"upstream"}`),
+	}
+
+	arc := filepath.Join(t.TempDir(), "bug.zip")
+	res, err := ShareEntry(ShareOptions{Entry: e, Capture: c, OutFile: arc})
+	if err != nil {
+		t.Fatalf("share entry: %v", err)
+	}
+	if !slices.Equal(res.Placeholders, []string{"authorization", "secret_1"}) {
+		t.Fatalf("placeholders = %v", res.Placeholders)
+	}
+
+	out := filepath.Join(t.TempDir(), "bug")
+	un, err := UnpackBundle(UnpackOptions{InFile: arc, OutDir: out})
+	if err != nil {
+		t.Fatalf("unpack share bundle: %v", err)
+	}
+	if un.FileCount != res.FileCount || res.FileCount != 7 {
+		t.Fatalf("file count = %d unpacked %d", res.FileCount, un.FileCount)
+	}
+
+	reqData := readFile(t, out, shareRequestFile)
+	doc := parser.Parse(filepath.Join(out, shareRequestFile), []byte(reqData))
+	if err := parser.Check(doc); err != nil {
+		t.Fatalf("shared request does not parse: %v\n%s", err, reqData)
+	}
+	if len(doc.Requests) != 1 {
+		t.Fatalf("requests = %d\n%s", len(doc.Requests), reqData)
+	}
+	req := doc.Requests[0]
+	if req.Metadata.Name != "Charge" || req.Headers.Get("Authorization") != "{{authorization}}" {
+		t.Fatalf("shared request = %+v", req)
+	}
+	if !strings.Contains(req.Body.Text, `"card":"{{secret_1}}"`) {
+		t.Fatalf("body = %q", req.Body.Text)
+	}
+
+	var env map[string]map[string]string
+	if err := json.Unmarshal([]byte(readFile(t, out, defaultEnvSourceFile)), &env); err != nil {
+		t.Fatalf("decode env: %v", err)
+	}
+	if env["prod"]["authorization"] != envPlaceholder || env["prod"]["secret_1"] != envPlaceholder {
+		t.Fatalf("env = %v", env)
+	}
+
+	resp := readFile(t, out, shareResponseFile)
+	if !strings.HasPrefix(resp, "HTTP/2.0 502 Bad Gateway\nContent-Type: application/json\n\n{\"error\"") {
+		t.Fatalf("response = %q", resp)
+	}
+	readme := readFile(t, out, shareReadmeFile)
+	for _, want := range []string{"resterm run --env prod request.http", "- dns: 2ms", "- auth: ok"} {
+		if !strings.Contains(readme, want) {
+			t.Fatalf("readme missing %q:\n%s", want, readme)
+		}
+	}
+
+	var hs []history.Entry
+	if err := json.Unmarshal([]byte(readFile(t, out, shareHistoryFile)), &hs); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if len(hs) != 1 || hs[0].FilePath != "" || hs[0].Capture == nil || hs[0].Explain == nil {
+		t.Fatalf("history = %+v", hs)
+	}
+}
+
+// @allow-sensitive-headers keeps raw credentials in history; a bundle is
+// meant to leave the machine, so it masks them anyway.
+func TestShareEntryMasksAllowedSensitiveHeaders(t *testing.T) {
+	e := history.Entry{
+		ID:         "7",
+		ExecutedAt: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		Method:     "GET",
+		URL:        "https://api.example.com/me",
+		Status:     "200 OK",
+		StatusCode: 200,
+		RequestText: "GET https://api.example.com/me\n" +
+			"Authorization: Bearer raw-token\n" +
+			"Cookie: session=raw-session\n" +
+			"Accept: application/json\n",
+	}
+	c := &history.Capture{
+		Proto: "HTTP/1.1",
+		Headers: http.Header{
+			"Content-Type": {"application/json"},
+			"Set-Cookie":   {"session=new-session; HttpOnly"},
+		},
+		Body: []byte(`{"id":1}`),
+	}
+
+	arc := filepath.Join(t.TempDir(), "me.zip")
+	res, err := ShareEntry(ShareOptions{Entry: e, Capture: c, OutFile: arc})
+	if err != nil {
+		t.Fatalf("share entry: %v", err)
+	}
+	if !slices.Equal(res.Placeholders, []string{"authorization", "cookie"}) {
+		t.Fatalf("placeholders = %v", res.Placeholders)
+	}
+	out := filepath.Join(t.TempDir(), "me")
+	if _, err := UnpackBundle(UnpackOptions{InFile: arc, OutDir: out}); err != nil {
+		t.Fatalf("unpack share bundle: %v", err)
+	}
+	for _, rel := range []string{shareRequestFile, shareResponseFile, shareHistoryFile} {
+		data := readFile(t, out, rel)
+		for _, secret := range []string{"raw-token", "raw-session", "new-session"} {
+			if strings.Contains(data, secret) {
+				t.Fatalf("%s leaks %q:\n%s", rel, secret, data)
+			}
+		}
+	}
+	if resp := readFile(t, out, shareResponseFile); !strings.Contains(resp, "Set-Cookie: ***") {
+		t.Fatalf("response = %q", resp)
+	}
+	if c.Headers.Get("Set-Cookie") != "session=new-session; HttpOnly" {
+		t.Fatal("masking must not write through to the capture")
+	}
+}
+
+func TestShareEntryRejectsWorkflowRuns(t *testing.T) {
+	e := history.Entry{ID: "1", Method: restfile.HistoryMethodWorkflow, RequestText: "steps"}
+	_, err := ShareEntry(ShareOptions{Entry: e, OutFile: filepath.Join(t.TempDir(), "x.zip")})
+	if err == nil || !strings.Contains(err.Error(), "workflow") {
+		t.Fatalf("expected workflow error, got %v", err)
+	}
+}
+
+func readFile(t *testing.T, root, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, rel))
+	if err != nil {
+		t.Fatalf("read %s: %v", rel, err)
+	}
+	return string(data)
+}
@@ -20,11 +20,14 @@ const (
 	RoleScript      FileRole = "script"
 	RoleAsset       FileRole = "asset"
 	RoleEnvTemplate FileRole = "env_template"
+	// RoleEnvEncrypted is an age-encrypted environment file, copied as is
+	// because only key holders can read it.
+	RoleEnvEncrypted FileRole = "env_encrypted"
 )
 
 func (r FileRole) valid() bool {
 	switch r {
-	case RoleRequest, RoleScript, RoleAsset, RoleEnvTemplate:
+	case RoleRequest, RoleScript, RoleAsset, RoleEnvTemplate, RoleEnvEncrypted:
 		return true
 	default:
 		return false
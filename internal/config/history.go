@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/duration"
+)
+
+// HistorySettings control full response capture and retention in the
+// history store. CaptureMaxKB caps each stored body or stream transcript;
+// zero keeps the store default. Ages accept durations such as "72h" or "2w".
+type HistorySettings struct {
+	Capture          bool   `json:"capture,omitempty"           toml:"capture,omitempty"`
+	CaptureMaxKB     int64  `json:"capture_max_kb,omitempty"    toml:"capture_max_kb,omitempty"`
+	CaptureRetention string `json:"capture_retention,omitempty" toml:"capture_retention,omitempty"`
+
+	MaxAge               string             `json:"max_age,omitempty"                 toml:"max_age,omitempty"`
+	MaxEntriesPerRequest int                `json:"max_entries_per_request,omitempty" toml:"max_entries_per_request,omitempty"`
+	MaxSizeMB            int64              `json:"max_size_mb,omitempty"             toml:"max_size_mb,omitempty"`
+	FailureMaxAge        string             `json:"failure_max_age,omitempty"         toml:"failure_max_age,omitempty"`
+	Workspaces           []HistoryWorkspace `json:"workspace,omitempty"               toml:"workspace,omitempty"`
+}
+
+// HistoryWorkspace overrides the retention limits for history recorded from
+// files under Path. Unset limits fall back to the global ones; "off" or -1
+// turns one off.
+type HistoryWorkspace struct {
+	Path                 string `json:"path"                              toml:"path"`
+	MaxAge               string `json:"max_age,omitempty"                 toml:"max_age,omitempty"`
+	MaxEntriesPerRequest int    `json:"max_entries_per_request,omitempty" toml:"max_entries_per_request,omitempty"`
+	FailureMaxAge        string `json:"failure_max_age,omitempty"         toml:"failure_max_age,omitempty"`
+}
+
+func (h HistorySettings) CaptureMaxBytes() int64 {
+	if h.CaptureMaxKB <= 0 {
+		return 0
+	}
+	return h.CaptureMaxKB << 10
+}
+
+func (h HistorySettings) CaptureRetentionDuration() (time.Duration, error) {
+	raw := strings.TrimSpace(h.CaptureRetention)
+	if raw == "" {
+		return 0, nil
+	}
+	d, ok := duration.Parse(raw)
+	if !ok || d < 0 {
+		return 0, fmt.Errorf("invalid history capture_retention %q", h.CaptureRetention)
+	}
+	return d, nil
+}
+
+func (h HistorySettings) MaxSizeBytes() int64 {
+	if h.MaxSizeMB <= 0 {
+		return 0
+	}
+	return h.MaxSizeMB << 20
+}
+
+func (h HistorySettings) MaxAgeDuration() (time.Duration, error) {
+	return retentionAge("max_age", h.MaxAge)
+}
+
+func (h HistorySettings) FailureMaxAgeDuration() (time.Duration, error) {
+	return retentionAge("failure_max_age", h.FailureMaxAge)
+}
+
+func (w HistoryWorkspace) MaxAgeDuration() (time.Duration, error) {
+	return retentionAge("workspace max_age", w.MaxAge)
+}
+
+func (w HistoryWorkspace) FailureMaxAgeDuration() (time.Duration, error) {
+	return retentionAge("workspace failure_max_age", w.FailureMaxAge)
+}
+
+// retentionAge parses an age limit. Empty is unset and "off" is -1, which
+// lets a workspace turn off a global limit.
+func retentionAge(key, raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	switch strings.ToLower(raw) {
+	case "":
+		return 0, nil
+	case "off":
+		return -1, nil
+	}
+	d, ok := duration.Parse(raw)
+	if !ok || d <= 0 {
+		return 0, fmt.Errorf("invalid history %s %q", key, raw)
+	}
+	return d, nil
+}
@@ -18,8 +18,9 @@ const (
 )
 
 type Settings struct {
-	DefaultTheme string         `json:"default_theme" toml:"default_theme"`
-	Layout       LayoutSettings `json:"layout"        toml:"layout"`
+	DefaultTheme string          `json:"default_theme" toml:"default_theme"`
+	Layout       LayoutSettings  `json:"layout"        toml:"layout"`
+	History      HistorySettings `json:"history"       toml:"history,omitempty"`
 }
 
 type SettingsFormat string
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestLoadSettingsReturnsDefaultHandleWhenMissing(t *testing.T) {
@@ -83,3 +85,53 @@ func TestLoadSettingsJSON(t *testing.T) {
 		t.Fatalf("expected handle path %q, got %q", path, handle.Path)
 	}
 }
+
+func TestSaveAndLoadHistorySettings(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RESTERM_CONFIG_DIR", dir)
+
+	want := HistorySettings{
+		Capture:              true,
+		CaptureMaxKB:         512,
+		CaptureRetention:     "2w",
+		MaxAge:               "90d",
+		MaxEntriesPerRequest: 200,
+		MaxSizeMB:            256,
+		FailureMaxAge:        "180d",
+		Workspaces: []HistoryWorkspace{
+			{Path: "~/work/payments", MaxAge: "off", MaxEntriesPerRequest: 1000},
+		},
+	}
+	if err := SaveSettings(Settings{History: want}, SettingsHandle{}); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+	got, _, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.History, want) {
+		t.Fatalf("expected history settings %+v, got %+v", want, got.History)
+	}
+	if got.History.CaptureMaxBytes() != 512<<10 {
+		t.Fatalf("unexpected capture cap %d", got.History.CaptureMaxBytes())
+	}
+	ret, err := got.History.CaptureRetentionDuration()
+	if err != nil || ret != 14*24*time.Hour {
+		t.Fatalf("unexpected retention %v, %v", ret, err)
+	}
+	if _, err := (HistorySettings{CaptureRetention: "soon"}).CaptureRetentionDuration(); err == nil {
+		t.Fatalf("expected invalid retention error")
+	}
+	if age, err := got.History.MaxAgeDuration(); err != nil || age != 90*24*time.Hour {
+		t.Fatalf("unexpected max age %v, %v", age, err)
+	}
+	if age, err := got.History.Workspaces[0].MaxAgeDuration(); err != nil || age != -1 {
+		t.Fatalf("unexpected workspace max age %v, %v", age, err)
+	}
+	if got.History.MaxSizeBytes() != 256<<20 {
+		t.Fatalf("unexpected size cap %d", got.History.MaxSizeBytes())
+	}
+	if _, err := (HistorySettings{FailureMaxAge: "-3d"}).FailureMaxAgeDuration(); err == nil {
+		t.Fatalf("expected invalid failure age error")
+	}
+}
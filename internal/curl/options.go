@@ -94,7 +94,8 @@ var defs = map[string]*optDef{
 		kind: optNone,
 		fn:   optWarn("--http2-prior-knowledge"),
 	},
-	"http3":       {key: "http3", kind: optNone, fn: optWarn("--http3")},
+	"http3":       {key: "http3", kind: optNone, fn: optSetConst("http-version", "3")},
+	"http3-only":  {key: "http3-only", kind: optNone, fn: optSetConst("http-version", "3")},
 	"resolve":     {key: "resolve", kind: optVal, fn: optWarnVal("--resolve")},
 	"connect-to":  {key: "connect-to", kind: optVal, fn: optWarnVal("--connect-to")},
 	"interface":   {key: "interface", kind: optVal, fn: optWarnVal("--interface")},
@@ -149,6 +150,7 @@ var longDefs = map[string]*optDef{
 	"http2":                 defs["http2"],
 	"http2-prior-knowledge": defs["http2-prior-knowledge"],
 	"http3":                 defs["http3"],
+	"http3-only":            defs["http3-only"],
 	"resolve":               defs["resolve"],
 	"connect-to":            defs["connect-to"],
 	"interface":             defs["interface"],
@@ -289,6 +289,18 @@ func TestParseCommandSettings(t *testing.T) {
 	}
 }
 
+func TestParseCommandHTTP3(t *testing.T) {
+	for _, flag := range []string{"--http3", "--http3-only"} {
+		req, err := ParseCommand("curl " + flag + " https://example.com")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", flag, err)
+		}
+		if got := req.Settings["http-version"]; got != "3" {
+			t.Fatalf("%s: expected http-version 3, got %q", flag, got)
+		}
+	}
+}
+
 func TestSplitTokensAnsiQuote(t *testing.T) {
 	tok, err := splitTokens("curl $'first\\nsecond'")
 	if err != nil {
@@ -41,6 +41,9 @@ const (
 	Trace               Name = "trace"
 	Profile             Name = "profile"
 	Compare             Name = "compare"
+	As                  Name = "as"
+	Contract            Name = "contract"
+	Snapshot            Name = "snapshot"
 	SSH                 Name = "ssh"
 	K8s                 Name = "k8s"
 	Workflow            Name = "workflow"
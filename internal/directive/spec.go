@@ -193,6 +193,27 @@ var specs = []Spec{
 		Repeat:  Once,
 		Topic:   "comparison",
 	},
+	{
+		Name:    As,
+		Summary: "Run the request as each named @auth profile",
+		Args:    ArgOptions,
+		Repeat:  Once,
+		Topic:   "authentication",
+	},
+	{
+		Name:    Contract,
+		Summary: "Validate the response against an OpenAPI operation",
+		Args:    ArgOptions,
+		Repeat:  Once,
+		Topic:   "contracts",
+	},
+	{
+		Name:    Snapshot,
+		Summary: "Compare the response body with a golden file",
+		Args:    ArgOptions,
+		Repeat:  Once,
+		Topic:   "snapshots",
+	},
 	// SSH and K8s parse their scope before checking for duplicates.
 	{Name: SSH, Summary: "Send request via SSH jump host", Args: ArgOptions, Repeat: Many, Topic: "ssh"},
 	{
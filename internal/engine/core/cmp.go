@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,29 +10,47 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	"github.com/unkn0wn-root/resterm/internal/engine/request"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/rts"
+	"github.com/unkn0wn-root/resterm/internal/rts/stdlib"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
 	"github.com/unkn0wn-root/resterm/internal/vars"
 	"google.golang.org/grpc/codes"
 )
 
 type ComparePlan struct {
-	Run      RunMeta
-	Doc      *restfile.Document
-	Request  *restfile.Request
-	Group    string
-	Baseline string
-	Targets  []vars.Target
+	Run        RunMeta
+	Doc        *restfile.Document
+	Request    *restfile.Request
+	Group      string
+	Baseline   string
+	Targets    []vars.Target
+	Identities []Identity
+}
+
+// Identity is one @as row. A nil Auth runs the row without credentials.
+type Identity struct {
+	Name string
+	Auth *restfile.AuthSpec
 }
 
 // CompareInput describes one compare run: the request to repeat, the resolved
 // targets to repeat it against, and which of them is the baseline. Group is set
-// only when the targets vary one environment group.
+// only when the targets vary one environment group. Identities, when set,
+// repeat the request under each @as identity for every target, and Baseline
+// then names an identity.
 type CompareInput struct {
-	Doc      *restfile.Document
-	Request  *restfile.Request
-	Targets  []vars.Target
-	Group    string
-	Baseline string
-	Run      RunMeta
+	Doc        *restfile.Document
+	Request    *restfile.Request
+	Targets    []vars.Target
+	Identities []Identity
+	Group      string
+	Baseline   string
+	Run        RunMeta
+}
+
+type cmpCell struct {
+	target vars.Target
+	id     *Identity
 }
 
 type cmpRun struct {
@@ -39,6 +58,8 @@ type cmpRun struct {
 	sink     Sink
 	ectx     context.Context
 	pl       *ComparePlan
+	rules    semdiff.Options
+	baseline *cmpBody
 	seen     bool
 	skip     bool
 	fail     bool
@@ -50,19 +71,117 @@ func PrepareCompare(in CompareInput) (*ComparePlan, error) {
 	if in.Request == nil {
 		return nil, fmt.Errorf("request is nil")
 	}
-	if len(in.Targets) < 2 {
+	switch {
+	case len(in.Identities) > 0 && len(in.Targets) == 0:
+		return nil, fmt.Errorf("identity run requires an environment")
+	case len(in.Identities) == 1:
+		return nil, fmt.Errorf("@as requires at least two identities")
+	case len(in.Identities) == 0 && len(in.Targets) < 2:
 		return nil, fmt.Errorf("compare requires at least two environments")
 	}
 	return &ComparePlan{
-		Run:      normRun(in.Run, ModeCompare, engine.ReqTitle(in.Request)),
-		Doc:      in.Doc,
-		Request:  in.Request,
-		Group:    in.Group,
-		Baseline: in.Baseline,
-		Targets:  in.Targets,
+		Run:        normRun(in.Run, ModeCompare, engine.ReqTitle(in.Request)),
+		Doc:        in.Doc,
+		Request:    in.Request,
+		Group:      in.Group,
+		Baseline:   in.Baseline,
+		Targets:    in.Targets,
+		Identities: in.Identities,
 	}, nil
 }
 
+// ResolveIdentities maps each @as name to the named @auth profile lookup finds.
+// A name without a profile is an error unless it is "anonymous", which runs
+// with auth disabled.
+func ResolveIdentities(
+	spec *restfile.IdentitySpec,
+	lookup func(name string) (*restfile.AuthSpec, bool),
+) ([]Identity, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	out := make([]Identity, 0, len(spec.Names))
+	for _, name := range spec.Names {
+		id := Identity{Name: name}
+		if auth, ok := lookup(name); ok {
+			id.Auth = auth
+		} else if !strings.EqualFold(name, restfile.AnonymousIdentity) {
+			return nil, fmt.Errorf("@as identity %q has no @auth profile named %q", name, name)
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// Rows walk every identity of a target before moving to the next target.
+func (pl *ComparePlan) cells() []cmpCell {
+	if len(pl.Identities) == 0 {
+		out := make([]cmpCell, len(pl.Targets))
+		for i, target := range pl.Targets {
+			out[i] = cmpCell{target: target}
+		}
+		return out
+	}
+	out := make([]cmpCell, 0, len(pl.Targets)*len(pl.Identities))
+	for _, target := range pl.Targets {
+		for i := range pl.Identities {
+			out = append(out, cmpCell{target: target, id: &pl.Identities[i]})
+		}
+	}
+	return out
+}
+
+// Rows returns the number of rows the plan runs.
+func (pl *ComparePlan) Rows() int {
+	if pl == nil {
+		return 0
+	}
+	if len(pl.Identities) == 0 {
+		return len(pl.Targets)
+	}
+	return len(pl.Targets) * len(pl.Identities)
+}
+
+func (c cmpCell) name() string {
+	if c.id != nil {
+		return c.id.Name
+	}
+	return c.target.Name()
+}
+
+// snapshotKey names the row's @snapshot file. Rows differ by target and by
+// identity, and one shared file would flip on every row.
+func (c cmpCell) snapshotKey() string {
+	key := c.target.Name()
+	if c.id != nil {
+		key = strings.Trim(key+"-"+c.id.Name, "-")
+	}
+	return key
+}
+
+func (c cmpCell) apply(req *restfile.Request, locals map[string]rts.Value) {
+	if c.id == nil || req == nil {
+		return
+	}
+	req.Metadata.Auth = c.id.Auth.Clone()
+	req.Metadata.AuthDisabled = c.id.Auth == nil
+	locals["identity"] = rts.Str(c.id.Name)
+}
+
+// CompareRules compiles the ignore= and match= options of the request's own
+// @compare. A run-wide --compare override keeps them.
+func CompareRules(req *restfile.Request) semdiff.Options {
+	if req == nil || req.Metadata.Compare == nil {
+		return semdiff.Options{}
+	}
+	spec := req.Metadata.Compare
+	opt, err := semdiff.Compile(spec.Ignore, spec.Match)
+	if err != nil {
+		return semdiff.Options{}
+	}
+	return opt
+}
+
 func RunCompare(ctx context.Context, dep Dep, sink Sink, pl *ComparePlan) error {
 	if dep == nil {
 		return fmt.Errorf("compare dependency is nil")
@@ -75,11 +194,12 @@ func RunCompare(ctx context.Context, dep Dep, sink Sink, pl *ComparePlan) error
 	}
 
 	r := &cmpRun{
-		dep:  dep,
-		sink: sink,
-		ectx: emitCtx(ctx),
-		pl:   pl,
-		skip: true,
+		dep:   dep,
+		sink:  sink,
+		ectx:  emitCtx(ctx),
+		pl:    pl,
+		rules: CompareRules(pl.Request),
+		skip:  true,
 	}
 	if err := r.emitRunStart(); err != nil {
 		return err
@@ -116,26 +236,37 @@ func CompareBaseline(rows []engine.CompareRow, want string) string {
 }
 
 func (r *cmpRun) run(ctx context.Context) error {
-	total := len(r.pl.Targets)
-	for i, target := range r.pl.Targets {
+	cells := r.pl.cells()
+	total := len(cells)
+	for i, cell := range cells {
 		if ctx.Err() != nil {
 			r.canceled = true
 			break
 		}
 		req := request.CloneRequest(r.pl.Request)
-		if err := r.emitRowStart(i, target, total, req); err != nil {
+		locals := map[string]rts.Value{"compare": r.compareLocal(i, cell)}
+		cell.apply(req, locals)
+		if err := r.emitRowStart(i, cell, total, req); err != nil {
 			return err
 		}
 		out, err := r.dep.ExecuteWith(
 			r.pl.Doc,
 			req,
-			target.Env,
-			request.ExecOptions{Record: false, Ctx: ctx},
+			cell.target.Env,
+			request.ExecOptions{
+				Locals:      rts.NewLocals(locals),
+				Record:      false,
+				Ctx:         ctx,
+				SnapshotRow: cell.snapshotKey(),
+			},
 		)
 		if err != nil {
 			return err
 		}
-		if err := r.emitRowDone(i, target, total, out); err != nil {
+		if r.base(i, cell) {
+			r.keepBaseline(out)
+		}
+		if err := r.emitRowDone(i, cell, total, out); err != nil {
 			return err
 		}
 		ok, skip, cancel := compareOutcome(out)
@@ -148,6 +279,53 @@ func (r *cmpRun) run(ctx context.Context) error {
 	return nil
 }
 
+// BodyDelta reports whether two response bodies differ. JSON and XML bodies
+// are compared by structure under rules, and at then names the first changed
+// path; other bodies are compared byte for byte.
+func BodyDelta(base, row []byte, baseType, rowType string, rules semdiff.Options) (bool, string) {
+	if changes, _, ok := semdiff.Bodies(base, row, baseType, rowType, rules); ok {
+		return len(changes) > 0, semdiff.Summary(changes)
+	}
+	return !bytes.Equal(base, row), ""
+}
+
+// cmpBody is the baseline response body, kept for the rows after it.
+type cmpBody struct {
+	data        []byte
+	contentType string
+}
+
+// compareLocal binds compare for the row's scripts and assertions, so
+// compare.equal(response.json()) checks the row against the baseline.
+func (r *cmpRun) compareLocal(i int, cell cmpCell) rts.Value {
+	in := stdlib.CompareRowInput{
+		Rules:      r.rules,
+		Baseline:   r.pl.Baseline,
+		IsBaseline: r.base(i, cell),
+	}
+	if in.Baseline == "" {
+		in.Baseline = r.pl.cells()[0].name()
+	}
+	if r.baseline != nil && !in.IsBaseline {
+		in.Body = r.baseline.data
+		in.ContentType = r.baseline.contentType
+		in.HasBody = true
+	}
+	return stdlib.CompareRow(in)
+}
+
+func (r *cmpRun) keepBaseline(out engine.RequestResult) {
+	switch {
+	case out.Response != nil:
+		r.baseline = &cmpBody{
+			data:        out.Response.Body,
+			contentType: out.Response.Headers.Get("Content-Type"),
+		}
+	case out.GRPC != nil:
+		r.baseline = &cmpBody{data: out.GRPC.Body, contentType: out.GRPC.ContentType}
+	}
+}
+
 func (r *cmpRun) note(ok, skip, cancel bool) {
 	r.seen = true
 	if !skip {
@@ -161,21 +339,25 @@ func (r *cmpRun) note(ok, skip, cancel bool) {
 	}
 }
 
-func (r *cmpRun) row(i int, target vars.Target, total int) RowMeta {
-	return RowMeta{
+func (r *cmpRun) row(i int, cell cmpCell, total int) RowMeta {
+	meta := RowMeta{
 		Index:   i,
-		Env:     target.Env.Label(),
-		Profile: target.Profile,
-		Base:    r.base(i, target),
+		Env:     cell.target.Env.Label(),
+		Profile: cell.target.Profile,
+		Base:    r.base(i, cell),
 		Total:   total,
 	}
+	if cell.id != nil {
+		meta.Identity = cell.id.Name
+	}
+	return meta
 }
 
-func (r *cmpRun) base(i int, target vars.Target) bool {
+func (r *cmpRun) base(i int, cell cmpCell) bool {
 	if r.pl.Baseline == "" {
 		return i == 0
 	}
-	return strings.EqualFold(target.Name(), r.pl.Baseline)
+	return strings.EqualFold(cell.name(), r.pl.Baseline)
 }
 
 func compareOutcome(out engine.RequestResult) (bool, bool, bool) {
@@ -5,7 +5,6 @@ import (
 
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
-	"github.com/unkn0wn-root/resterm/internal/vars"
 )
 
 func (r *cmpRun) emitRunStart() error {
@@ -24,13 +23,13 @@ func (r *cmpRun) emitRunDone(err error) error {
 
 func (r *cmpRun) emitRowStart(
 	i int,
-	target vars.Target,
+	cell cmpCell,
 	total int,
 	req *restfile.Request,
 ) error {
 	return Emit(r.ectx, r.sink, CmpRowStart{
 		Meta:    NewMeta(r.pl.Run, time.Now()),
-		Row:     r.row(i, target, total),
+		Row:     r.row(i, cell, total),
 		Doc:     r.pl.Doc,
 		Request: req,
 	})
@@ -38,13 +37,13 @@ func (r *cmpRun) emitRowStart(
 
 func (r *cmpRun) emitRowDone(
 	i int,
-	target vars.Target,
+	cell cmpCell,
 	total int,
 	res engine.RequestResult,
 ) error {
 	return Emit(r.ectx, r.sink, CmpRowDone{
 		Meta:   NewMeta(r.pl.Run, time.Now()),
-		Row:    r.row(i, target, total),
+		Row:    r.row(i, cell, total),
 		Result: res,
 	})
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/unkn0wn-root/resterm/internal/engine"
@@ -44,6 +45,8 @@ func NormalizeCompareSpec(spec *restfile.CompareSpec) *restfile.CompareSpec {
 		Environments: envs,
 		Baseline:     base,
 		Group:        strings.TrimSpace(spec.Group),
+		Ignore:       slices.Clone(spec.Ignore),
+		Match:        slices.Clone(spec.Match),
 	}
 }
 
@@ -68,11 +68,12 @@ type StepMeta struct {
 }
 
 type RowMeta struct {
-	Index   int
-	Env     string
-	Profile string
-	Base    bool
-	Total   int
+	Index    int
+	Env      string
+	Profile  string
+	Identity string
+	Base     bool
+	Total    int
 }
 
 type IterMeta struct {
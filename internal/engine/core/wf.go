@@ -756,7 +756,7 @@ func evalReq(step restfile.WorkflowStep, res engine.RequestResult) stepOutcome {
 		}
 		return stepFailed
 	}
-	if res.ScriptErr != nil {
+	if res.ScriptErr != nil || res.Contract.Failed() {
 		return stepFailed
 	}
 	for _, t := range res.Tests {
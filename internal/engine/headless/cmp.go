@@ -56,6 +56,59 @@ func (e *Engine) executeCompare(
 	return out, nil
 }
 
+// executeIdentities runs an @as request once per identity in the active
+// environment and reports it like a compare run.
+func (e *Engine) executeIdentities(
+	ctx context.Context,
+	doc *restfile.Document,
+	req *restfile.Request,
+	env vars.Environment,
+) (*engine.CompareResult, error) {
+	as := req.Metadata.Identities
+	ids, err := core.ResolveIdentities(as, func(name string) (*restfile.AuthSpec, bool) {
+		return e.namedAuth(doc, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	pl, err := core.PrepareCompare(core.CompareInput{
+		Doc:        doc,
+		Request:    req,
+		Targets:    []vars.Target{{Env: env}},
+		Identities: ids,
+		Baseline:   as.Baseline,
+		Run:        core.RunMeta{Env: env},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cl := newCmpCollector()
+	if err := core.RunCompare(ctx, e.rq, cl, pl); err != nil {
+		return nil, err
+	}
+	spec := &restfile.CompareSpec{Baseline: as.Baseline}
+	out := e.buildCompareResult(req, spec, env, cl.rows)
+	e.recordCompare(doc, req, out, env)
+	return out, nil
+}
+
+type authLookup interface {
+	NamedAuth(doc *restfile.Document, name string) (*restfile.AuthSpec, bool)
+}
+
+// Test doubles of core.Dep need not know about named auth, so a dependency
+// without a lookup falls back to the profiles declared in doc.
+func (e *Engine) namedAuth(doc *restfile.Document, name string) (*restfile.AuthSpec, bool) {
+	if lk, ok := e.rq.(authLookup); ok {
+		return lk.NamedAuth(doc, name)
+	}
+	pf, ok := e.cfg.Registry.NamedAuth(doc, name)
+	if !ok {
+		return nil, false
+	}
+	return pf.Spec.Clone(), true
+}
+
 type cmpCollector struct {
 	rows []engine.CompareRow
 }
@@ -80,6 +133,7 @@ func compareRow(meta core.RowMeta, out engine.RequestResult) engine.CompareRow {
 	row := engine.CompareRow{
 		Environment:    meta.Env,
 		Profile:        meta.Profile,
+		Identity:       meta.Identity,
 		Selection:      out.Selection,
 		Response:       cloneHTTP(out.Response),
 		GRPC:           out.GRPC.Clone(),
@@ -112,9 +166,10 @@ func (e *Engine) buildCompareResult(
 	rows []engine.CompareRow,
 ) *engine.CompareResult {
 	base := core.CompareBaseIndex(rows, compareBase(spec))
+	rules := core.CompareRules(req)
 	if len(rows) > 0 {
 		for i := range rows {
-			rows[i].Summary = compareSummary(rows[base], rows[i])
+			rows[i].Summary = compareSummary(rows[base], rows[i], rules)
 			rows[i].Success = compareSuccess(rows[i])
 		}
 	}
@@ -164,7 +219,7 @@ func compareRunSummary(
 	}
 	parts := make([]string, 0, len(rows))
 	for _, row := range rows {
-		name := row.Environment
+		name := row.Label()
 		if spec != nil && strings.EqualFold(spec.Baseline, row.Name()) {
 			name += "*"
 		}
@@ -194,13 +249,17 @@ func compareReport(rows []engine.CompareRow, spec *restfile.CompareSpec) string
 	base := core.CompareBaseline(rows, compareBase(spec))
 	var b strings.Builder
 	fmt.Fprintf(&b, "Baseline: %s\n\n", base)
-	b.WriteString("Env\tStatus\tCode\tDuration\tDiff\n")
+	if rows[0].Identity != "" {
+		b.WriteString("Identity\tStatus\tCode\tDuration\tDiff\n")
+	} else {
+		b.WriteString("Env\tStatus\tCode\tDuration\tDiff\n")
+	}
 	for _, row := range rows {
 		status, code := compareStatus(row)
 		fmt.Fprintf(
 			&b,
 			"%s\t%s\t%s\t%s\t%s\n",
-			row.Environment,
+			row.Label(),
 			status,
 			code,
 			row.Duration.Round(time.Millisecond),
@@ -266,6 +325,7 @@ func (e *Engine) recordCompare(
 		item := history.CompareResult{
 			Environment: row.Environment,
 			Profile:     row.Profile,
+			Identity:    row.Identity,
 			EnvironmentSelection: history.EnvironmentSelection(
 				row.Selection.Groups(),
 			),
@@ -1,14 +1,15 @@
 package headless
 
 import (
-	"bytes"
 	"fmt"
 	"net/http"
 	"slices"
 	"strings"
 
 	"github.com/unkn0wn-root/resterm/internal/engine"
+	"github.com/unkn0wn-root/resterm/internal/engine/core"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
 	"google.golang.org/grpc/codes"
 )
 
@@ -49,7 +50,7 @@ func compareStatus(row engine.CompareRow) (string, string) {
 	}
 }
 
-func compareSummary(base, row engine.CompareRow) string {
+func compareSummary(base, row engine.CompareRow, rules semdiff.Options) string {
 	if row.Canceled {
 		return "canceled"
 	}
@@ -75,13 +76,13 @@ func compareSummary(base, row engine.CompareRow) string {
 	if failedTests > 0 {
 		return fmt.Sprintf("%d test(s) failed", failedTests)
 	}
-	if strings.EqualFold(base.Environment, row.Environment) {
+	if strings.EqualFold(base.Label(), row.Label()) {
 		return "baseline"
 	}
 
 	switch {
 	case row.Response != nil && base.Response != nil:
-		return summarizeHTTP(base.Response, row.Response)
+		return summarizeHTTP(base.Response, row.Response, rules)
 	case row.GRPC != nil && base.GRPC != nil:
 		return base.GRPC.DiffSummary(row.GRPC)
 	default:
@@ -89,7 +90,7 @@ func compareSummary(base, row engine.CompareRow) string {
 	}
 }
 
-func summarizeHTTP(base, row *httpx.Response) string {
+func summarizeHTTP(base, row *httpx.Response, rules semdiff.Options) string {
 	if base == nil || row == nil {
 		return "unavailable"
 	}
@@ -101,13 +102,24 @@ func summarizeHTTP(base, row *httpx.Response) string {
 	if !headersEqual(row.Headers, base.Headers) {
 		diff = append(diff, "headers")
 	}
-	if !bytes.Equal(row.Body, base.Body) {
+	body, at := core.BodyDelta(
+		base.Body,
+		row.Body,
+		base.Headers.Get("Content-Type"),
+		row.Headers.Get("Content-Type"),
+		rules,
+	)
+	if body {
 		diff = append(diff, "body")
 	}
 	if len(diff) == 0 {
 		return "match"
 	}
-	return strings.Join(diff, ", ") + " differ"
+	out := strings.Join(diff, ", ") + " differ"
+	if at != "" {
+		out += " at " + at
+	}
+	return out
 }
 
 func headersEqual(a, b http.Header) bool {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
 )
 
 func TestHeadersEqualOrder(t *testing.T) {
@@ -35,7 +36,34 @@ func TestCompareSummaryHTTPDiffs(t *testing.T) {
 		},
 	}
 
-	if got := compareSummary(base, row); got != "status, headers, body differ" {
+	if got := compareSummary(base, row, semdiff.Options{}); got != "status, headers, body differ" {
 		t.Fatalf("compareSummary() = %q", got)
 	}
 }
+
+func TestCompareSummaryJSONBodies(t *testing.T) {
+	jsonRow := func(env, body string) engine.CompareRow {
+		return engine.CompareRow{
+			Environment: env,
+			Response: &httpx.Response{
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{"Content-Type": {"application/json"}},
+				Body:       []byte(body),
+			},
+		}
+	}
+	base := jsonRow("dev", `{"id": 1, "price": 10, "requestId": "a"}`)
+	rules, err := semdiff.Compile([]string{"$.requestId"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same := jsonRow("prod", `{"requestId": "b", "price": 10.0, "id": 1}`)
+	if got := compareSummary(base, same, rules); got != "match" {
+		t.Fatalf("reordered body summary = %q", got)
+	}
+	changed := jsonRow("prod", `{"requestId": "b", "price": 12, "id": 1}`)
+	if got := compareSummary(base, changed, rules); got != "body differ at $.price" {
+		t.Fatalf("changed body summary = %q", got)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	"github.com/unkn0wn-root/resterm/internal/engine/request"
 	rtrun "github.com/unkn0wn-root/resterm/internal/engine/runtime"
+	"github.com/unkn0wn-root/resterm/internal/parser"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/vars"
@@ -215,3 +216,138 @@ func TestExecuteGroupedCompareValidatesAllProfilesBeforeRequest(t *testing.T) {
 		t.Fatalf("compare made %d requests before validation", calls)
 	}
 }
+
+func TestExecuteRequestRunsEachIdentity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer admin-token":
+			w.WriteHeader(http.StatusOK)
+		case "Bearer viewer-token":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	src := fmt.Sprintf(`# @auth file name=admin bearer admin-token
+# @auth file name=viewer bearer viewer-token
+# @auth file bearer default-token
+
+# @name purge
+# @as admin,viewer,anonymous
+# @assert identity == "admin" ? response.statusCode == 200 : response.statusCode >= 400
+# @assert identity != "anonymous" || response.statusCode == 401
+DELETE %s/cache
+`, srv.URL)
+	doc := parser.Parse("as.http", []byte(src))
+	if len(doc.Errors) != 0 {
+		t.Fatalf("parse errors: %v", doc.Errors)
+	}
+
+	cl := newHTTPClientWithFactory(func(httpx.Options) (*http.Client, error) {
+		return srv.Client(), nil
+	})
+	store := &memHistory{}
+	rt := rtrun.New(rtrun.Config{Client: cl, History: store})
+	t.Cleanup(func() { _ = rt.Close() })
+	cfg := engine.Config{Client: cl, History: store}
+	eng := newWithDeps(request.New(cfg, rt), rt, cfg)
+
+	res, err := eng.ExecuteRequest(doc, doc.Requests[0], testSelection(""))
+	if err != nil {
+		t.Fatalf("ExecuteRequest: %v", err)
+	}
+	out := res.Compare
+	if out == nil || len(out.Rows) != 3 {
+		t.Fatalf("compare result = %+v, want 3 rows", out)
+	}
+	want := map[string]int{"admin": 200, "viewer": 403, "anonymous": 401}
+	for _, row := range out.Rows {
+		if row.Response == nil || row.Response.StatusCode != want[row.Identity] {
+			t.Fatalf("row %q = %+v", row.Identity, row.Response)
+		}
+		for _, tr := range row.Tests {
+			if !tr.Passed {
+				t.Fatalf("row %q assert %q failed: %s", row.Identity, tr.Name, tr.Message)
+			}
+		}
+	}
+	if out.Baseline != "admin" || !strings.HasPrefix(out.Rows[1].Summary, "status") {
+		t.Fatalf("baseline %q, viewer summary %q", out.Baseline, out.Rows[1].Summary)
+	}
+	if !strings.Contains(out.Report, "Identity\t") || !strings.Contains(out.Summary, "viewer") {
+		t.Fatalf("report %q, summary %q", out.Report, out.Summary)
+	}
+	if len(store.entries) != 1 || store.entries[0].Compare.Results[2].Identity != "anonymous" {
+		t.Fatalf("history = %+v", store.entries)
+	}
+}
+
+func TestExecuteRequestRejectsUnknownIdentity(t *testing.T) {
+	fx := newCmpFixture(t)
+	fx.req.Metadata.Identities = &restfile.IdentitySpec{Names: []string{"admin", "viewer"}}
+	_, err := fx.eng.ExecuteRequest(fx.doc, fx.req, testSelection(""))
+	if err == nil || !strings.Contains(err.Error(), `no @auth profile named "admin"`) {
+		t.Fatalf("expected missing profile error, got %v", err)
+	}
+	if *fx.calls != 0 {
+		t.Fatalf("made %d requests before validation", *fx.calls)
+	}
+}
+
+func TestExecuteCompareAssertsAgainstBaselineBody(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			_, _ = fmt.Fprint(w, `{"id": 1, "price": 10, "requestId": "a"}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"requestId": "b", "price": 10.0, "id": 1}`)
+	}))
+	defer srv.Close()
+
+	src := fmt.Sprintf(`# @name item
+# @compare one two ignore=$.requestId
+# @assert compare.equal(response.json())
+# @assert compare.baseline == null || compare.baseline.requestId == "a"
+GET %s/item
+`, srv.URL)
+	doc := parser.Parse("item.http", []byte(src))
+	if len(doc.Errors) != 0 {
+		t.Fatalf("parse errors: %v", doc.Errors)
+	}
+
+	cl := newHTTPClientWithFactory(func(httpx.Options) (*http.Client, error) {
+		return srv.Client(), nil
+	})
+	rt := rtrun.New(rtrun.Config{Client: cl})
+	t.Cleanup(func() { _ = rt.Close() })
+	cfg := engine.Config{Client: cl}
+	eng := newWithDeps(request.New(cfg, rt), rt, cfg)
+
+	res, err := eng.ExecuteRequest(doc, doc.Requests[0], testSelection(""))
+	if err != nil {
+		t.Fatalf("ExecuteRequest: %v", err)
+	}
+	out := res.Compare
+	if out == nil || len(out.Rows) != 2 {
+		t.Fatalf("compare result = %+v, want 2 rows", out)
+	}
+	for _, row := range out.Rows {
+		if row.ScriptErr != nil {
+			t.Fatalf("row %q script error: %v", row.Environment, row.ScriptErr)
+		}
+		for _, tr := range row.Tests {
+			if !tr.Passed {
+				t.Fatalf("row %q assert %q failed: %s", row.Environment, tr.Name, tr.Message)
+			}
+		}
+	}
+	// Content-Length differs; the bodies match once requestId is ignored.
+	if strings.Contains(out.Rows[1].Summary, "body") {
+		t.Fatalf("second summary = %q, want bodies to match", out.Rows[1].Summary)
+	}
+}
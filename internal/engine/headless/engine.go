@@ -112,6 +112,9 @@ func (e *Engine) ExecuteRequestContext(
 		if req.Metadata.Profile != nil {
 			return engine.RequestResult{}, errProfileWithForEach
 		}
+		if req.Metadata.Identities != nil {
+			return engine.RequestResult{}, errAsWithForEach
+		}
 		out, err := e.executeForEach(runCtx(ctx), doc, req, env)
 		if err != nil {
 			return engine.RequestResult{}, err
@@ -123,6 +126,26 @@ func (e *Engine) ExecuteRequestContext(
 			Skipped:     out.Skipped,
 			Workflow:    out,
 		}, nil
+	case req.Metadata.Identities != nil:
+		// A run-wide --compare still applies to the other requests; this one
+		// varies its identity instead.
+		if req.Metadata.Compare != nil {
+			return engine.RequestResult{}, errAsWithCompare
+		}
+		if req.Metadata.Profile != nil {
+			return engine.RequestResult{}, errProfileDuringCompare
+		}
+		out, err := e.executeIdentities(runCtx(ctx), doc, req, env)
+		if err != nil {
+			return engine.RequestResult{}, err
+		}
+		return engine.RequestResult{
+			Executed:    req,
+			Environment: out.Environment,
+			Selection:   out.Selection,
+			Skipped:     out.Skipped,
+			Compare:     out,
+		}, nil
 	case spec != nil:
 		if req.Metadata.Profile != nil {
 			return engine.RequestResult{}, errProfileDuringCompare
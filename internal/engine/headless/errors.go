@@ -10,4 +10,9 @@ var (
 	errCompareWithForEach   = errors.New("@compare cannot run alongside @for-each")
 	errProfileWithForEach   = errors.New("@profile cannot run alongside @for-each")
 	errProfileDuringCompare = errors.New("@profile cannot run during compare")
+	errAsWithForEach        = errors.New("@as cannot run alongside @for-each")
+	errAsWithCompare        = errors.New("@as cannot run alongside @compare")
+	errPrepareForEach       = errors.New("@for-each requests expand to several requests")
+	errPrepareAs            = errors.New("@as requests expand to several requests")
+	errPrepareUnsupported   = errors.New("request preparation is not supported")
 )
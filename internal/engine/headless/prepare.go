@@ -0,0 +1,48 @@
+package headless
+
+import (
+	"context"
+
+	"github.com/unkn0wn-root/resterm/internal/engine/request"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+type httpPreparer interface {
+	PrepareHTTP(
+		ctx context.Context,
+		doc *restfile.Document,
+		req *restfile.Request,
+		env vars.Environment,
+		mask bool,
+	) (request.Prepared, error)
+}
+
+// PrepareRequestContext builds req without sending it. Requests that fan out
+// into several runs have no single request to show.
+func (e *Engine) PrepareRequestContext(
+	ctx context.Context,
+	doc *restfile.Document,
+	req *restfile.Request,
+	sel vars.Selection,
+	mask bool,
+) (request.Prepared, error) {
+	if req == nil {
+		return request.Prepared{}, errNilRequest
+	}
+	switch {
+	case req.Metadata.ForEach != nil:
+		return request.Prepared{}, errPrepareForEach
+	case req.Metadata.Identities != nil:
+		return request.Prepared{}, errPrepareAs
+	}
+	pr, ok := e.rq.(httpPreparer)
+	if !ok {
+		return request.Prepared{}, errPrepareUnsupported
+	}
+	env, err := e.environment(sel)
+	if err != nil {
+		return request.Prepared{}, err
+	}
+	return pr.PrepareHTTP(runCtx(ctx), doc, req, env, mask)
+}
@@ -11,10 +11,12 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	"github.com/unkn0wn-root/resterm/internal/engine/core"
 	"github.com/unkn0wn-root/resterm/internal/engine/request"
+	"github.com/unkn0wn-root/resterm/internal/openapi/contract"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/scripts"
+	"github.com/unkn0wn-root/resterm/internal/snapshot"
 	"github.com/unkn0wn-root/resterm/internal/vars"
 )
 
@@ -72,6 +74,8 @@ type wfStepRes struct {
 	err     error
 	execReq *restfile.Request
 	reqText string
+	ct      *contract.Result
+	snap    *snapshot.Result
 }
 
 func workflowStatus(res wfStepRes) string {
@@ -121,6 +125,8 @@ func makeStepRes(
 		err:     out.Err,
 		execReq: request.CloneRequest(out.Executed),
 		reqText: out.RequestText,
+		ct:      out.Contract,
+		snap:    out.Snapshot,
 		msg:     strings.TrimSpace(out.SkipReason),
 		skip:    out.Skipped,
 	}
@@ -160,6 +166,10 @@ func evaluateStep(res wfStepRes) wfStepRes {
 		ok = false
 		msg = res.sErr.Error()
 	}
+	if ok && res.ct.Failed() {
+		ok = false
+		msg = "contract " + res.ct.Message()
+	}
 	if ok {
 		if failMsg, failed := firstFailedWorkflowTest(res.tests); failed {
 			ok = false
@@ -279,5 +289,7 @@ func toWorkflowStep(res wfStepRes) engine.WorkflowStep {
 		Canceled:   res.cancel,
 		Success:    res.ok,
 		Duration:   res.dur,
+		Contract:   res.ct,
+		Snapshot:   res.snap,
 	}
 }
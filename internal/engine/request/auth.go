@@ -70,6 +70,16 @@ func (e *Engine) ResolveInheritedAuth(doc *restfile.Document, req *restfile.Requ
 	}
 }
 
+// NamedAuth looks up an @auth profile declared with name= in the document or,
+// failing that, the workspace globals.
+func (e *Engine) NamedAuth(doc *restfile.Document, name string) (*restfile.AuthSpec, bool) {
+	pf, ok := e.registryIndex().NamedAuth(doc, name)
+	if !ok {
+		return nil, false
+	}
+	return pf.Spec.Clone(), true
+}
+
 func CommandAuthSecrets(res authcmd.Result) []string {
 	tok := strings.TrimSpace(res.Token)
 	val := strings.TrimSpace(res.Value)
@@ -0,0 +1,84 @@
+package request
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/contract"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/util"
+)
+
+// checkContract validates a finished HTTP response against the request's
+// @contract spec, or the run-wide one. Streams, gRPC, failed sends, skipped
+// requests and previews are never checked.
+func (e *Engine) checkContract(
+	ctx context.Context,
+	req *restfile.Request,
+	base string,
+	res xrunResult,
+) *contract.Result {
+	if e.ct == nil || res.Err != nil || res.Skipped || res.Preview ||
+		res.Response == nil || res.GRPC != nil || res.Stream != nil {
+		return nil
+	}
+	t, ok := contractTarget(req, base, e.cfg.Contract)
+	if !ok {
+		return nil
+	}
+	if t.Spec == "" {
+		return &contract.Result{Operation: t.Operation, Violations: []contract.Violation{{
+			Kind:    contract.KindSpec,
+			Message: "@contract names no spec and the run has no --contract spec",
+		}}}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	resp := res.Response
+	method := resp.ReqMethod
+	if method == "" && req != nil {
+		method = req.Method
+	}
+	out := e.ct.Check(ctx, t, contract.Response{
+		Method:     method,
+		URL:        resp.EffectiveURL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Headers,
+		Body:       resp.Body,
+	})
+	if out == nil {
+		return nil
+	}
+	// Messages quote URL paths, which may carry a resolved secret.
+	for i := range out.Violations {
+		out.Violations[i].Message = redactSecretText(out.Violations[i].Message, res.RuntimeSecrets)
+	}
+	return out
+}
+
+// contractTarget picks the spec for a request. An @contract spec is resolved
+// against the request file and checked strictly; the run-wide spec only checks
+// responses it documents unless @contract names an operation in it.
+func contractTarget(req *restfile.Request, base, runSpec string) (contract.Target, bool) {
+	var spec *restfile.ContractSpec
+	if req != nil {
+		spec = req.Metadata.Contract
+	}
+	switch {
+	case spec == nil:
+		if runSpec == "" {
+			return contract.Target{}, false
+		}
+		return contract.Target{Spec: runSpec, Optional: true}, true
+	case spec.Disabled:
+		return contract.Target{}, false
+	case spec.Spec == "":
+		return contract.Target{Spec: runSpec, Operation: spec.Operation}, true
+	}
+	path := util.ExpandHome(spec.Spec)
+	if !filepath.IsAbs(path) && base != "" {
+		path = filepath.Join(base, path)
+	}
+	return contract.Target{Spec: filepath.Clean(path), Operation: spec.Operation}, true
+}
@@ -12,6 +12,7 @@ import (
 	xexec "github.com/unkn0wn-root/resterm/internal/exec"
 	xplain "github.com/unkn0wn-root/resterm/internal/explain"
 	"github.com/unkn0wn-root/resterm/internal/k8s"
+	"github.com/unkn0wn-root/resterm/internal/openapi/contract"
 	"github.com/unkn0wn-root/resterm/internal/prerequest"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
@@ -43,6 +44,7 @@ type Engine struct {
 	sc   *scripts.Runner
 	re   *rtshost.Engine
 	rg   *registry.Index
+	ct   *contract.Checker
 	last lastState
 }
 
@@ -67,6 +69,12 @@ type ExecOptions struct {
 	AttachSSE  func(*httpx.StreamHandle, *restfile.Request)
 	AttachWS   func(*httpx.WebSocketHandle, *restfile.Request)
 	AttachGRPC func(*stream.Session, *restfile.Request)
+	// OnPrepared receives the built HTTP request in preview mode, before any
+	// redaction.
+	OnPrepared func(Prepared)
+	// SnapshotRow names the @compare or @as row being run, so each row
+	// checks a @snapshot file of its own.
+	SnapshotRow string
 }
 
 func New(cfg engine.Config, rt *rtrun.Runtime) *Engine {
@@ -90,6 +98,7 @@ func New(cfg engine.Config, rt *rtrun.Runtime) *Engine {
 		sc:  scripts.NewRunner(nil),
 		re:  rtshost.NewEngine(stdlib.New),
 		rg:  cfg.Registry,
+		ct:  contract.NewChecker(),
 	}
 }
 
@@ -106,7 +115,7 @@ func (e *Engine) ForRun(
 		return nil
 	}
 
-	out := &Engine{cfg: e.cfg, rt: e.rt, hc: e.hc, gc: e.gc, sc: e.sc, re: e.re, rg: e.rg}
+	out := &Engine{cfg: e.cfg, rt: e.rt, hc: e.hc, gc: e.gc, sc: e.sc, re: e.re, rg: e.rg, ct: e.ct}
 	out.setConfig(cfg)
 	out.seedLast(resp, grpc)
 	return out
@@ -192,15 +201,23 @@ func (e *Engine) ExecuteWith(
 			Response:       res.Response,
 			GRPC:           res.GRPC,
 			RuntimeSecrets: res.RuntimeSecrets,
+			Transcript:     res.Transcript,
 			RequestText:    res.RequestText,
 			Env:            env,
 			Skipped:        res.Skipped,
 			SkipReason:     res.SkipReason,
 			Executed:       res.Executed,
+			Explain:        res.Explain,
 		})
 	}
 	e.store(res)
-	return toResult(res), nil
+	out := toResult(res)
+	out.Contract = e.checkContract(opt.Ctx, req, opts.BaseDir, res)
+	if snap := e.checkSnapshot(req, opts.BaseDir, opt.SnapshotRow, res); snap != nil {
+		out.Snapshot = snap
+		out.Tests = append(out.Tests, snapshotTest(snap))
+	}
+	return out, nil
 }
 
 func (e *Engine) store(res xrunResult) {
@@ -286,6 +303,7 @@ type execCtx struct {
 	onSSE     func(*httpx.StreamHandle, *restfile.Request)
 	onWS      func(*httpx.WebSocketHandle, *restfile.Request)
 	onGRPC    func(*stream.Session, *restfile.Request)
+	onPrep    func(Prepared)
 }
 
 func newExec(
@@ -334,6 +352,7 @@ func newExec(
 		onSSE:     opt.AttachSSE,
 		onWS:      opt.AttachWS,
 		onGRPC:    opt.AttachGRPC,
+		onPrep:    opt.OnPrepared,
 	}
 }
 
@@ -914,13 +933,14 @@ func (f flow) PreviewResult() xexec.RequestResult {
 	out := x.base()
 	out.RequestText = x.reqText()
 	if x.req.GRPC == nil {
-		if err := x.eng.prepareExplainHTTPPreview(
+		prep, err := x.eng.prepareExplainHTTPPreview(
 			x.sendCtx,
 			x.exp.report,
 			x.req,
 			x.res,
 			x.opts,
-		); err != nil {
+		)
+		if err != nil {
 			x.exp.stage(
 				xplain.StageHTTPPrepare,
 				xplain.StageError,
@@ -933,6 +953,9 @@ func (f flow) PreviewResult() xexec.RequestResult {
 			out.Explain = x.exp.finish(xplain.StatusError, "HTTP preparation failed", err)
 			return out
 		}
+		if x.onPrep != nil && prep != nil {
+			x.onPrep(*prep)
+		}
 	}
 	out.Explain = x.exp.finish(
 		xplain.StatusReady,
@@ -1027,6 +1050,7 @@ func (f flow) ExecuteGRPC() xexec.RequestResult {
 	x.grpcOpts.Timeout = x.timeout
 	x.grpcOpts.SSH = x.sshPlan
 	x.grpcOpts.K8s = x.k8sPlan
+	x.grpcOpts.Proxy = x.opts.ProxyConfig()
 
 	// The client picks the unary or stream timeout after it knows whether the
 	// method streams.
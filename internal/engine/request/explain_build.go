@@ -104,6 +104,9 @@ func newExplainBuilder(
 	if req.Metadata.Compare != nil {
 		b.warn("@compare sweep is not executed in explain preview")
 	}
+	if req.Metadata.Identities != nil {
+		b.warn("@as identities are not executed in explain preview")
+	}
 	if req.Metadata.Profile != nil {
 		b.warn("@profile run is not executed in explain preview")
 	}
@@ -1055,7 +1058,7 @@ func AuthSecretValues(auth *restfile.AuthSpec, res *vars.Resolver) []string {
 	}
 
 	switch auth.Kind() {
-	case restfile.AuthBasic:
+	case restfile.AuthBasic, restfile.AuthDigest:
 		add(expand("password"))
 	case restfile.AuthBearer:
 		add(expand("token"))
@@ -1095,6 +1098,12 @@ func (e *Engine) prepareExplainAuthPreview(
 			summary: xplain.SummaryAuthPrepared,
 			notes:   []string{"auth headers/query are applied during HTTP request build"},
 		}, nil
+	case restfile.AuthDigest:
+		return explainAuthPreviewResult{
+			status:  xplain.StageOK,
+			summary: xplain.SummaryAuthPrepared,
+			notes:   []string{"digest challenge is answered while the HTTP request is sent"},
+		}, nil
 	case restfile.AuthCommand:
 		if hdr, ok := e.commandAuthHeader(doc, auth, res); ok && requestHeaderPresent(req, hdr) {
 			return explainAuthPreviewResult{
@@ -1232,18 +1241,18 @@ func (e *Engine) prepareExplainHTTPPreview(
 	req *restfile.Request,
 	res *vars.Resolver,
 	opts httpx.Options,
-) error {
+) (*Prepared, error) {
 	if rep == nil || req == nil || e.hc == nil {
-		return nil
+		return nil, nil
 	}
 	httpReq, _, body, err := e.hc.BuildHTTPRequest(ctx, req, res.Lenient(), opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if req.SSE != nil && httpReq.Header.Get("Accept") == "" {
 		httpReq.Header.Set("Accept", "text/event-stream")
 	}
 	addExplainPreparedHTTPStage(rep, req, httpReq, body)
 	setExplainHTTPPrepared(rep, req, httpReq, body)
-	return nil
+	return newPrepared(httpReq, body), nil
 }
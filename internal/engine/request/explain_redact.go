@@ -2,6 +2,7 @@ package request
 
 import (
 	"net/textproto"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -61,7 +62,8 @@ func (e *Engine) redactExplainReport(
 			h.Value = redactSecretText(h.Value, secs)
 		}
 		for i := range rep.Final.Settings {
-			rep.Final.Settings[i].Value = redactSecretText(rep.Final.Settings[i].Value, secs)
+			s := &rep.Final.Settings[i]
+			s.Value = redactExplainSetting(s.Key, s.Value, secs)
 		}
 		for i := range rep.Final.Details {
 			d := &rep.Final.Details[i]
@@ -133,6 +135,24 @@ func redactExplainChangeValue(field, val string, secs []string) string {
 		}
 		return explainMask
 	}
+	if key, ok := strings.CutPrefix(field, "setting."); ok {
+		return redactExplainSetting(key, val, secs)
+	}
+	return redactSecretText(val, secs)
+}
+
+// Proxy credentials may be written inline rather than drawn from a secret,
+// so the password setting and any password in a proxy URL are masked too.
+var explainProxyPass = regexp.MustCompile(`(://[^:@/\s,]*:)[^@/\s,]+@`)
+
+func redactExplainSetting(key, val string, secs []string) string {
+	if strings.EqualFold(strings.TrimSpace(key), "proxy-password") {
+		if strings.TrimSpace(val) == "" {
+			return val
+		}
+		return explainMask
+	}
+	val = explainProxyPass.ReplaceAllString(val, "${1}"+explainMask+"@")
 	return redactSecretText(val, secs)
 }
 
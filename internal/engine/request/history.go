@@ -7,6 +7,7 @@ import (
 
 	"github.com/unkn0wn-root/resterm/internal/binaryview"
 	"github.com/unkn0wn-root/resterm/internal/engine"
+	xplain "github.com/unkn0wn-root/resterm/internal/explain"
 	"github.com/unkn0wn-root/resterm/internal/history"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
@@ -84,8 +85,12 @@ func (e *Engine) recordHTTP(
 		RequestText: txt,
 		Description: strings.TrimSpace(req.Metadata.Description),
 		Tags:        engine.Tags(req.Metadata.Tags),
+		Explain:     res.Explain,
 	}
 	ent.Trace = history.NewTraceSummary(resp.Timeline, resp.TraceReport)
+	if history.Capturing(hs) {
+		ent.Capture = CaptureHTTP(resp, req, secs)
+	}
 	_ = hs.Append(ent)
 }
 
@@ -129,6 +134,7 @@ func (e *Engine) recordSkipped(
 		RequestText: txt,
 		Description: strings.TrimSpace(req.Metadata.Description),
 		Tags:        engine.Tags(req.Metadata.Tags),
+		Explain:     res.Explain,
 	}
 	_ = hs.Append(ent)
 }
@@ -173,6 +179,10 @@ func (e *Engine) recordGRPC(
 		RequestText: redactText(res.RequestText, secs, mask),
 		Description: strings.TrimSpace(req.Metadata.Description),
 		Tags:        engine.Tags(req.Metadata.Tags),
+		Explain:     res.Explain,
+	}
+	if history.Capturing(hs) {
+		ent.Capture = CaptureGRPC(resp, req, res.Transcript, secs)
 	}
 	_ = hs.Append(ent)
 }
@@ -262,9 +272,11 @@ type runResult struct {
 	Response       *httpx.Response
 	GRPC           *grpcx.Response
 	RuntimeSecrets []string
+	Transcript     []byte
 	RequestText    string
 	Env            vars.ResolvedEnv
 	Skipped        bool
 	SkipReason     string
 	Executed       *restfile.Request
+	Explain        *xplain.Report
 }
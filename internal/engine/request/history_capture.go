@@ -0,0 +1,54 @@
+package request
+
+import (
+	"net/http"
+
+	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
+	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+// CaptureHTTP builds the full response history keeps in full-capture mode.
+// It is redacted like the snippet: secret values are masked everywhere and
+// sensitive headers unless the request allows them. @no-log requests are
+// not captured.
+func CaptureHTTP(resp *httpx.Response, req *restfile.Request, secs []string) *history.Capture {
+	if resp == nil || req == nil || req.Metadata.NoLog {
+		return nil
+	}
+	mask := !req.Metadata.AllowSensitiveHeaders
+	return &history.Capture{
+		Proto:        resp.Proto,
+		Headers:      redactHeader(resp.Headers, secs, mask),
+		ContentType:  resp.Headers.Get("Content-Type"),
+		EffectiveURL: redactText(resp.EffectiveURL, secs, false),
+		Body:         redactBytes(resp.Body, secs),
+	}
+}
+
+// CaptureGRPC is CaptureHTTP for gRPC. Metadata and trailers are kept as
+// headers, and a streaming call's transcript is kept beside the message.
+func CaptureGRPC(
+	resp *grpcx.Response,
+	req *restfile.Request,
+	transcript []byte,
+	secs []string,
+) *history.Capture {
+	if resp == nil || req == nil || req.Metadata.NoLog {
+		return nil
+	}
+	mask := !req.Metadata.AllowSensitiveHeaders
+	body := resp.Body
+	if len(body) == 0 {
+		body = []byte(resp.Message)
+	}
+	return &history.Capture{
+		Headers:       redactHeader(http.Header(resp.Headers), secs, mask),
+		Trailers:      redactHeader(http.Header(resp.Trailers), secs, mask),
+		ContentType:   resp.ContentType,
+		StatusMessage: redactText(resp.StatusMessage, secs, false),
+		Body:          redactBytes(body, secs),
+		Transcript:    redactBytes(transcript, secs),
+	}
+}
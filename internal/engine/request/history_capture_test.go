@@ -0,0 +1,56 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func TestCaptureHTTPRedactsBeforeStorage(t *testing.T) {
+	resp := &httpx.Response{
+		Proto: "HTTP/1.1",
+		Headers: http.Header{
+			"Content-Type":  {"application/json"},
+			"X-Api-Key":     {"k-123"},
+			"X-Echo-Secret": {"token=" + leakedSecret},
+			"Set-Cookie":    {"session=abc123; HttpOnly"},
+		},
+		Body:         []byte(`{"token":"` + leakedSecret + `"}`),
+		EffectiveURL: "http://example.test/?t=" + leakedSecret,
+	}
+	req := &restfile.Request{Method: http.MethodGet, URL: "http://example.test"}
+
+	c := CaptureHTTP(resp, req, []string{leakedSecret})
+	if c == nil {
+		t.Fatal("expected a capture")
+	}
+	if got := string(c.Body); got != `{"token":"***"}` {
+		t.Fatalf("body = %q", got)
+	}
+	if got := c.Headers.Get("X-Api-Key"); got != "***" {
+		t.Fatalf("sensitive header = %q", got)
+	}
+	if got := c.Headers.Get("Set-Cookie"); got != "***" {
+		t.Fatalf("session cookie = %q", got)
+	}
+	if got := c.Headers.Get("X-Echo-Secret"); got != "token=***" {
+		t.Fatalf("echoed secret header = %q", got)
+	}
+	if c.EffectiveURL != "http://example.test/?t=***" || c.ContentType != "application/json" {
+		t.Fatalf("capture = %+v", c)
+	}
+	if string(resp.Body) == string(c.Body) {
+		t.Fatal("redaction must not write through to the response")
+	}
+
+	req.Metadata.AllowSensitiveHeaders = true
+	if got := CaptureHTTP(resp, req, nil).Headers.Get("X-Api-Key"); got != "k-123" {
+		t.Fatalf("allowed sensitive header = %q", got)
+	}
+	req.Metadata.NoLog = true
+	if CaptureHTTP(resp, req, nil) != nil {
+		t.Fatal("@no-log requests must not be captured")
+	}
+}
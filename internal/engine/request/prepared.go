@@ -0,0 +1,120 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	xplain "github.com/unkn0wn-root/resterm/internal/explain"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+// Prepared is an HTTP request built the way the send path builds it, after
+// variables, @apply patches, scripts and auth. Warnings lists the preparation
+// steps that were skipped, such as an OAuth token that is not cached yet.
+type Prepared struct {
+	Method   string
+	URL      string
+	Header   http.Header
+	Body     []byte
+	Warnings []string
+}
+
+func newPrepared(req *http.Request, body []byte) *Prepared {
+	p := &Prepared{
+		Method: req.Method,
+		Header: req.Header.Clone(),
+		Body:   bytes.Clone(body),
+	}
+	if p.Header == nil {
+		p.Header = http.Header{}
+	}
+	if req.URL != nil {
+		p.URL = req.URL.String()
+		// net/http sends Host from the request, not the header map.
+		if req.Host != "" && req.Host != req.URL.Host {
+			p.Header.Set("Host", req.Host)
+		}
+	}
+	return p
+}
+
+// Mask replaces sensitive header values and every secret in secs the same way
+// explain previews do.
+func (p Prepared) Mask(secs []string) Prepared {
+	out := p
+	out.URL = redactSecretText(p.URL, secs)
+	out.Header = make(http.Header, len(p.Header))
+	for name, vals := range p.Header {
+		masked := make([]string, len(vals))
+		for i, v := range vals {
+			switch {
+			case IsSensitiveHeader(name) && strings.TrimSpace(v) != "":
+				masked[i] = explainMask
+			default:
+				masked[i] = redactSecretText(v, secs)
+			}
+		}
+		out.Header[name] = masked
+	}
+	if containsSecret(string(p.Body), secs) {
+		out.Body = []byte(redactSecretText(string(p.Body), secs))
+	}
+	return out
+}
+
+// PrepareHTTP resolves req in preview mode and returns the request that would
+// be sent. Nothing goes over the wire, so OAuth and command auth only apply
+// when a token is already cached. With mask set, secrets are redacted.
+func (e *Engine) PrepareHTTP(
+	ctx context.Context,
+	doc *restfile.Document,
+	req *restfile.Request,
+	env vars.Environment,
+	mask bool,
+) (Prepared, error) {
+	if req == nil {
+		return Prepared{}, diag.New(diag.ClassUI, "request is nil")
+	}
+	if req.GRPC != nil || req.WebSocket != nil {
+		return Prepared{}, diag.New(diag.ClassProtocol, "only HTTP requests can be prepared")
+	}
+	var prep *Prepared
+	res, err := e.ExecuteWith(doc, req, env, ExecOptions{
+		Ctx:        ctx,
+		Mode:       ExecModePreview,
+		OnPrepared: func(p Prepared) { prep = &p },
+	})
+	switch {
+	case err != nil:
+		return Prepared{}, err
+	case res.Err != nil:
+		return Prepared{}, res.Err
+	case res.Skipped:
+		return Prepared{}, diag.New(diag.ClassUI, "request skipped: "+res.SkipReason)
+	case prep == nil:
+		return Prepared{}, diag.New(diag.ClassProtocol, "request was not prepared")
+	}
+	out := *prep
+	out.Warnings = skippedStages(res.Explain)
+	if mask {
+		out = out.Mask(res.RuntimeSecrets)
+	}
+	return out, nil
+}
+
+func skippedStages(rep *xplain.Report) []string {
+	if rep == nil {
+		return nil
+	}
+	var out []string
+	for _, st := range rep.Stages {
+		if st.Status == xplain.StageSkipped && st.Name == xplain.StageAuth {
+			out = append(out, st.Name+": "+st.Summary)
+		}
+	}
+	return out
+}
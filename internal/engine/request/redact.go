@@ -1,6 +1,8 @@
 package request
 
 import (
+	"bytes"
+	"net/http"
 	"strings"
 
 	"github.com/unkn0wn-root/resterm/internal/diag"
@@ -93,3 +95,44 @@ func containsSecret(s string, secs []string) bool {
 	}
 	return false
 }
+
+// redactBytes masks secret values in a response body or transcript kept by
+// full history capture.
+func redactBytes(data []byte, secs []string) []byte {
+	out := data
+	for _, sec := range secs {
+		if sec == "" || !bytes.Contains(out, []byte(sec)) {
+			continue
+		}
+		out = bytes.ReplaceAll(out, []byte(sec), []byte("***"))
+	}
+	return out
+}
+
+// isCookieHeader reports the cookie headers. They are kept out of sensHdr so
+// the response view can still show them, but a stored session cookie is as
+// good as a token.
+func isCookieHeader(name string) bool {
+	return strings.EqualFold(name, "Cookie") || strings.EqualFold(name, "Set-Cookie")
+}
+
+// redactHeader copies h with secret values masked and, when maskHdr is set,
+// sensitive and cookie headers replaced outright.
+func redactHeader(h http.Header, secs []string, maskHdr bool) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(http.Header, len(h))
+	for name, vals := range h {
+		cp := make([]string, len(vals))
+		for i, v := range vals {
+			if maskHdr && (IsSensitiveHeader(name) || isCookieHeader(name)) {
+				cp[i] = "***"
+				continue
+			}
+			cp[i] = redactText(v, secs, false)
+		}
+		out[name] = cp
+	}
+	return out
+}
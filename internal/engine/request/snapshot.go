@@ -0,0 +1,75 @@
+package request
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/scripts"
+	"github.com/unkn0wn-root/resterm/internal/snapshot"
+	"github.com/unkn0wn-root/resterm/internal/util"
+)
+
+const snapshotDir = "__snapshots__"
+
+// checkSnapshot compares a finished HTTP response body with the request's
+// @snapshot file under the same rules as checkContract. A matrix row gets
+// its own file, get-user.prod.json beside get-user.json, since rows answer
+// differently by design.
+func (e *Engine) checkSnapshot(
+	req *restfile.Request,
+	base string,
+	row string,
+	res xrunResult,
+) *snapshot.Result {
+	if req == nil || req.Metadata.Snapshot == nil || res.Err != nil || res.Skipped ||
+		res.Preview || res.Response == nil || res.GRPC != nil || res.Stream != nil {
+		return nil
+	}
+	spec := req.Metadata.Snapshot
+	name := spec.Path
+	if name == "" {
+		stem := snapshotStem(req.Metadata.Name)
+		if stem == "" {
+			return &snapshot.Result{
+				Status: snapshot.StatusError,
+				Err:    "@snapshot without a path needs a named request",
+			}
+		}
+		name = filepath.Join(snapshotDir, stem+".json")
+	}
+	if row = snapshotStem(row); row != "" {
+		ext := filepath.Ext(name)
+		name = strings.TrimSuffix(name, ext) + "." + row + ext
+	}
+	path := util.ExpandHome(name)
+	if !filepath.IsAbs(path) && base != "" {
+		path = filepath.Join(base, path)
+	}
+	return snapshot.Check(filepath.Clean(path), res.Response.Body, snapshot.Options{
+		Name:   name,
+		Ignore: spec.Ignore,
+		Update: e.cfg.UpdateSnapshots,
+	})
+}
+
+func snapshotStem(name string) string {
+	name = strings.TrimSpace(name)
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ':
+			return '-'
+		}
+		return r
+	}, name)
+}
+
+// snapshotTest turns a snapshot result into a test row, so a mismatch fails
+// the request like any other assertion.
+func snapshotTest(res *snapshot.Result) scripts.TestResult {
+	name := "snapshot"
+	if res.Name != "" {
+		name += " " + res.Name
+	}
+	return scripts.TestResult{Name: name, Message: res.Message(), Passed: !res.Failed()}
+}
@@ -281,7 +281,7 @@ func entries(source variableSource, src varSources, refs *vars.EnvRefs) []variab
 	ds := declarations(source, src.doc, src.req)
 	out := make([]variableEntry, 0, len(ds))
 	for _, d := range ds {
-		if src.sec == omitSecrets && d.secret && !isEnvRef(d.authored, d.text) {
+		if src.sec == omitSecrets && d.secret && !isSecretRef(d.authored, d.text) {
 			continue
 		}
 		out = append(out, variableEntry{name: d.name, val: declaredValue(refs, d.authored, d.text)})
@@ -296,11 +296,11 @@ func declaredValue(refs *vars.EnvRefs, authored bool, text string) vars.Value {
 	return refs.ResolveDeclared(text)
 }
 
-func isEnvRef(authored bool, text string) bool {
+func isSecretRef(authored bool, text string) bool {
 	if !authored {
 		return false
 	}
-	_, ok := vars.EnvRefKey(text)
+	_, ok := vars.ParseRef(text)
 	return ok
 }
 
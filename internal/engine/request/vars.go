@@ -187,7 +187,8 @@ func (e *Engine) buildResolver(
 }
 
 // DisplayResolver uses normal variable precedence for UI text but leaves out
-// known secret values so labels and previews do not expose them.
+// known secret values so labels and previews do not expose them. It runs on
+// every redraw, so references are withheld rather than read.
 func (e *Engine) DisplayResolver(
 	ctx context.Context,
 	doc *restfile.Document,
@@ -196,7 +197,7 @@ func (e *Engine) DisplayResolver(
 	base string,
 	locals rts.Locals,
 ) *vars.Resolver {
-	env := ResolveEnvironment(src, doc, req).WithoutRefValues()
+	env := src.ResolveWith(vars.WithheldEnvRefs())
 	globs := e.collectStoredGlobalValues(env)
 	globs.DeleteFunc(func(_ string, v vars.GlobalMutation) bool { return v.Secret })
 
@@ -271,15 +272,34 @@ func (e *Engine) collectGlobalValues(doc *restfile.Document, env vars.ResolvedEn
 	return effectiveGlobalValues(doc, e.collectStoredGlobalValues(env), env.Refs())
 }
 
-// ResolveEnvironment snapshots every declared env: reference for one run,
+// ResolveEnvironment snapshots every declared reference for one run,
 // including unused values that may need redaction.
 func ResolveEnvironment(
 	src vars.Environment,
 	doc *restfile.Document,
 	req *restfile.Request,
 ) vars.ResolvedEnv {
-	env := src.ResolveWith(vars.NewEnvRefs(declaredNames(doc, req, src)))
-	refs := env.Refs()
+	return resolveEnvironment(src, doc, req, vars.NewEnvRefs(declaredNames(doc, req, src)))
+}
+
+// CachedEnvironment is ResolveEnvironment for the UI goroutine. It only sees
+// values an earlier run read, which is all redaction of that run needs, and
+// never runs a command or waits on the keyring.
+func CachedEnvironment(
+	src vars.Environment,
+	doc *restfile.Document,
+	req *restfile.Request,
+) vars.ResolvedEnv {
+	return resolveEnvironment(src, doc, req, vars.CachedEnvRefs(declaredNames(doc, req, src)))
+}
+
+func resolveEnvironment(
+	src vars.Environment,
+	doc *restfile.Document,
+	req *restfile.Request,
+	refs *vars.EnvRefs,
+) vars.ResolvedEnv {
+	env := src.ResolveWith(refs)
 	read := func(authored bool, text string) { declaredValue(refs, authored, text) }
 	if doc != nil {
 		for _, c := range doc.Constants {
@@ -328,7 +348,7 @@ func collectDocumentGlobalValues(doc *restfile.Document, refs *vars.EnvRefs) var
 		out.Set(name, vars.GlobalMutation{
 			Name:   name,
 			Value:  val.Text,
-			Secret: v.Secret || isEnvRef(v.Authored, v.Value),
+			Secret: v.Secret || isSecretRef(v.Authored, v.Value),
 		})
 	}
 	return out
@@ -11,12 +11,14 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/k8s"
 	"github.com/unkn0wn-root/resterm/internal/mock"
 	"github.com/unkn0wn-root/resterm/internal/oauth"
+	"github.com/unkn0wn-root/resterm/internal/openapi/contract"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/registry"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	runfail "github.com/unkn0wn-root/resterm/internal/runx/fail"
 	"github.com/unkn0wn-root/resterm/internal/scripts"
+	"github.com/unkn0wn-root/resterm/internal/snapshot"
 	"github.com/unkn0wn-root/resterm/internal/ssh"
 	str "github.com/unkn0wn-root/resterm/internal/util"
 	"github.com/unkn0wn-root/resterm/internal/vars"
@@ -41,6 +43,12 @@ type Config struct {
 	Bindings              *bindings.Map
 	SourceDiagnostics     bool
 	MockInspector         mock.Inspector
+	// Contract is an OpenAPI spec every HTTP response is checked against when
+	// it documents the response's route.
+	Contract string
+	// UpdateSnapshots rewrites @snapshot files that differ from the response
+	// instead of failing the request.
+	UpdateSnapshots bool
 }
 
 // CompareConfig overrides a request's @compare directive for a whole run.
@@ -110,6 +118,8 @@ type RequestResult struct {
 	Compare        *CompareResult
 	Profile        *ProfileResult
 	Workflow       *WorkflowResult
+	Contract       *contract.Result
+	Snapshot       *snapshot.Result
 }
 
 type Timing struct {
@@ -132,18 +142,31 @@ type CompareResult struct {
 	Rows        []CompareRow
 }
 
-// Name is the label a compare row is matched by. Grouped compares use the
-// profile, flat compares the environment.
+// Name is the label a compare row is matched by. Identity runs use the @as
+// identity, grouped compares the profile, flat compares the environment.
 func (r CompareRow) Name() string {
+	if r.Identity != "" {
+		return r.Identity
+	}
 	if r.Profile != "" {
 		return r.Profile
 	}
 	return r.Environment
 }
 
+// Label is what a compare row is shown as. Identity rows share one
+// environment, so they are told apart by identity.
+func (r CompareRow) Label() string {
+	if r.Identity != "" {
+		return r.Identity
+	}
+	return r.Environment
+}
+
 type CompareRow struct {
 	Environment string
 	Profile     string
+	Identity    string
 	Selection   vars.Selection
 	Summary     string
 	Response    *httpx.Response
@@ -229,6 +252,8 @@ type WorkflowStep struct {
 	Canceled   bool
 	Success    bool
 	Duration   time.Duration
+	Contract   *contract.Result
+	Snapshot   *snapshot.Result
 }
 
 type RuntimeState struct {
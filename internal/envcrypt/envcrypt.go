@@ -0,0 +1,259 @@
+// Package envcrypt encrypts environment files and single values with age, so
+// they can be committed next to the requests that use them. A whole file is
+// stored as resterm.env.enc.json and decrypted when the catalog loads. A
+// single value is an enc: reference inside a plain environment file.
+package envcrypt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/unkn0wn-root/resterm/internal/config"
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/util"
+)
+
+const (
+	// ValuePrefix marks an encrypted value inside an environment file.
+	ValuePrefix = "enc:"
+	// FileSuffix names an encrypted environment file.
+	FileSuffix = ".env.enc.json"
+	// DefaultFile is the encrypted file discovery opens.
+	DefaultFile = "resterm" + FileSuffix
+
+	EnvIdentity   = "RESTERM_AGE_IDENTITY"
+	EnvPassphrase = "RESTERM_ENV_PASSPHRASE"
+	// IdentityFile is read from the config directory when EnvIdentity is
+	// unset, so a key made with age-keygen works without configuration.
+	IdentityFile = "age.key"
+	// RecipientsSuffix names the public key list kept next to an encrypted
+	// file, so an edit encrypts to the same people the file was shared with.
+	RecipientsSuffix = ".recipients"
+)
+
+var ErrNoKey = errors.New(
+	"no decryption key: set " + EnvIdentity + " to an age identity file, set " +
+		EnvPassphrase + ", or create " + filepath.Join("<config dir>", IdentityFile),
+)
+
+// IsEncryptedPath reports whether path names an encrypted environment file.
+func IsEncryptedPath(path string) bool {
+	return strings.HasSuffix(strings.ToLower(filepath.Base(path)), FileSuffix)
+}
+
+// Keys are what a process can decrypt with. A passphrase is kept as text so a
+// file decrypted with it can be encrypted with it again.
+type Keys struct {
+	Identities []age.Identity
+	Passphrase string
+}
+
+func (k Keys) Empty() bool {
+	return len(k.Identities) == 0 && k.Passphrase == ""
+}
+
+// LoadKeys reads EnvIdentity, or the config directory identity file when the
+// variable is unset, and EnvPassphrase. No key at all is not an error here;
+// Decrypt reports it when a key is needed.
+func LoadKeys() (Keys, error) {
+	var k Keys
+	path := util.ExpandHome(strings.TrimSpace(os.Getenv(EnvIdentity)))
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(config.Dir(), IdentityFile)
+	}
+	ids, err := readIdentities(path)
+	switch {
+	case err == nil:
+		k.Identities = ids
+	case explicit || !errors.Is(err, os.ErrNotExist):
+		return Keys{}, diag.WrapAsf(diag.ClassConfig, err, "read age identity %s", path)
+	}
+	k.Passphrase = os.Getenv(EnvPassphrase)
+	return k, nil
+}
+
+func readIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return age.ParseIdentities(f)
+}
+
+func (k Keys) identities() ([]age.Identity, error) {
+	ids := append([]age.Identity(nil), k.Identities...)
+	if k.Passphrase != "" {
+		id, err := age.NewScryptIdentity(k.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoKey
+	}
+	return ids, nil
+}
+
+// Recipients encrypts back to the keys themselves. age does not allow a
+// passphrase next to other recipients, so the passphrase wins when both are
+// set.
+func (k Keys) Recipients() ([]age.Recipient, error) {
+	if k.Passphrase != "" {
+		r, err := age.NewScryptRecipient(k.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{r}, nil
+	}
+	var out []age.Recipient
+	for _, id := range k.Identities {
+		if x, ok := id.(*age.X25519Identity); ok {
+			out = append(out, x.Recipient())
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrNoKey
+	}
+	return out, nil
+}
+
+// ParseRecipients reads age1... public keys, one per line, ignoring blank lines
+// and # comments like age -R does.
+func ParseRecipients(r io.Reader) ([]age.Recipient, error) {
+	var out []age.Recipient
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rcpt, err := age.ParseX25519Recipient(line)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rcpt)
+	}
+	return out, sc.Err()
+}
+
+// RecipientsPath is where the recipients of the encrypted file at path are
+// listed.
+func RecipientsPath(path string) string {
+	return path + RecipientsSuffix
+}
+
+// FormatRecipients lists public keys one per line, in the form
+// ParseRecipients reads. ok is false for a passphrase, which has no public key
+// to list.
+func FormatRecipients(rcpts []age.Recipient) ([]byte, bool) {
+	var b bytes.Buffer
+	b.WriteString("# age public keys resterm env edit encrypts to\n")
+	for _, r := range rcpts {
+		x, ok := r.(*age.X25519Recipient)
+		if !ok {
+			return nil, false
+		}
+		b.WriteString(x.String() + "\n")
+	}
+	return b.Bytes(), len(rcpts) > 0
+}
+
+// UsesPassphrase reports whether the ciphertext header has a passphrase
+// stanza. Such a file is encrypted back with the passphrase that opened it.
+func UsesPassphrase(data []byte) bool {
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	sc := bufio.NewScanner(src)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "---") {
+			return false
+		}
+		if strings.HasPrefix(line, "-> scrypt ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Encrypt returns ASCII-armored ciphertext, which diffs and merges as text.
+func Encrypt(plain []byte, rcpts ...age.Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	if err := encryptTo(aw, plain, rcpts); err != nil {
+		return nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decrypt accepts armored or binary ciphertext.
+func Decrypt(data []byte, keys Keys) ([]byte, error) {
+	ids, err := keys.identities()
+	if err != nil {
+		return nil, err
+	}
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	r, err := age.Decrypt(src, ids...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// EncryptValue returns an enc: reference. The ciphertext is base64 without
+// armor so the value stays on one line.
+func EncryptValue(plain string, rcpts ...age.Recipient) (string, error) {
+	var buf bytes.Buffer
+	if err := encryptTo(&buf, []byte(plain), rcpts); err != nil {
+		return "", err
+	}
+	return ValuePrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecryptValue decrypts the part of an enc: reference after the prefix.
+func DecryptValue(key string, keys Keys) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	plain, err := Decrypt(data, keys)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func encryptTo(dst io.Writer, plain []byte, rcpts []age.Recipient) error {
+	if len(rcpts) == 0 {
+		return errors.New("no recipients to encrypt to")
+	}
+	w, err := age.Encrypt(dst, rcpts...)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(plain); err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,117 @@
+package envcrypt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestEncryptDecryptWithIdentity(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := Keys{Identities: []age.Identity{id}}
+	rcpts, err := keys.Recipients()
+	if err != nil {
+		t.Fatalf("recipients: %v", err)
+	}
+
+	data, err := Encrypt([]byte(`{"dev":{"token":"s3cret"}}`), rcpts...)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "-----BEGIN AGE ENCRYPTED FILE-----") {
+		t.Fatalf("ciphertext is not armored: %q", data)
+	}
+	plain, err := Decrypt(data, keys)
+	if err != nil || string(plain) != `{"dev":{"token":"s3cret"}}` {
+		t.Fatalf("Decrypt = %q, %v", plain, err)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	if _, err := Decrypt(data, Keys{Identities: []age.Identity{other}}); err == nil {
+		t.Fatal("decrypted with the wrong identity")
+	}
+	if _, err := Decrypt(data, Keys{}); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("Decrypt without keys = %v, want ErrNoKey", err)
+	}
+}
+
+func TestValueRoundTripWithPassphrase(t *testing.T) {
+	keys := Keys{Passphrase: "correct horse"}
+	rcpts, err := keys.Recipients()
+	if err != nil {
+		t.Fatalf("recipients: %v", err)
+	}
+	ref, err := EncryptValue("tok\nwith newline", rcpts...)
+	if err != nil {
+		t.Fatalf("encrypt value: %v", err)
+	}
+	if !strings.HasPrefix(ref, ValuePrefix) || strings.Contains(ref, "\n") {
+		t.Fatalf("EncryptValue = %q, want a one-line enc: reference", ref)
+	}
+	got, err := DecryptValue(strings.TrimPrefix(ref, ValuePrefix), keys)
+	if err != nil || got != "tok\nwith newline" {
+		t.Fatalf("DecryptValue = %q, %v", got, err)
+	}
+	if _, err := DecryptValue("not base64!", keys); err == nil {
+		t.Fatal("accepted invalid ciphertext")
+	}
+}
+
+func TestLoadKeysReadsIdentityFile(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.txt")
+	if err := os.WriteFile(path, []byte("# created: now\n"+id.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvIdentity, path)
+	t.Setenv(EnvPassphrase, "")
+
+	keys, err := LoadKeys()
+	if err != nil {
+		t.Fatalf("LoadKeys: %v", err)
+	}
+	if len(keys.Identities) != 1 || keys.Passphrase != "" {
+		t.Fatalf("LoadKeys = %+v", keys)
+	}
+
+	t.Setenv(EnvIdentity, filepath.Join(t.TempDir(), "missing"))
+	if _, err := LoadKeys(); err == nil {
+		t.Fatal("a missing explicit identity file was ignored")
+	}
+}
+
+func TestParseRecipientsSkipsComments(t *testing.T) {
+	id, _ := age.GenerateX25519Identity()
+	src := "# team\n\n" + id.Recipient().String() + "\n"
+	rcpts, err := ParseRecipients(strings.NewReader(src))
+	if err != nil || len(rcpts) != 1 {
+		t.Fatalf("ParseRecipients = %d, %v", len(rcpts), err)
+	}
+	if _, err := ParseRecipients(strings.NewReader("ssh-ed25519 AAAA\n")); err == nil {
+		t.Fatal("accepted a non-age recipient")
+	}
+}
+
+func TestIsEncryptedPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"resterm.env.enc.json":         true,
+		"/ws/Staging.ENV.ENC.JSON":     true,
+		"resterm.env.json":             false,
+		"resterm.env.enc.json.bak":     false,
+		"/ws/resterm.env.enc.json/x.x": false,
+	} {
+		if got := IsEncryptedPath(path); got != want {
+			t.Errorf("IsEncryptedPath(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
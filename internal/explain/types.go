@@ -17,69 +17,69 @@ const (
 )
 
 type Report struct {
-	Name     string
-	Method   string
-	URL      string
-	Env      string
-	Status   Status
-	Decision string
-	Failure  string
-	Vars     []Var
-	Stages   []Stage
-	Final    *Final
-	Warnings []string
+	Name     string   `json:"name"`
+	Method   string   `json:"method,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Env      string   `json:"env,omitempty"`
+	Status   Status   `json:"status"`
+	Decision string   `json:"decision,omitempty"`
+	Failure  string   `json:"failure,omitempty"`
+	Vars     []Var    `json:"vars,omitempty"`
+	Stages   []Stage  `json:"stages,omitempty"`
+	Final    *Final   `json:"final,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type Stage struct {
-	Name    string
-	Status  StageStatus
-	Summary string
-	Changes []Change
-	Notes   []string
+	Name    string      `json:"name"`
+	Status  StageStatus `json:"status"`
+	Summary string      `json:"summary,omitempty"`
+	Changes []Change    `json:"changes,omitempty"`
+	Notes   []string    `json:"notes,omitempty"`
 }
 
 type Change struct {
-	Field  string
-	Before string
-	After  string
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
 }
 
 type Var struct {
-	Name     string
-	Source   string
-	Value    string
-	Shadowed []string
-	Uses     int
-	Missing  bool
-	Dynamic  bool
+	Name     string   `json:"name"`
+	Source   string   `json:"source,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Shadowed []string `json:"shadowed,omitempty"`
+	Uses     int      `json:"uses,omitempty"`
+	Missing  bool     `json:"missing,omitempty"`
+	Dynamic  bool     `json:"dynamic,omitempty"`
 }
 
 type Final struct {
-	Mode     string
-	Protocol string
-	Method   string
-	URL      string
-	Headers  []Header
-	Body     string
-	BodyNote string
-	Settings []Pair
-	Route    *Route
-	Details  []Pair
-	Steps    []string
+	Mode     string   `json:"mode,omitempty"`
+	Protocol string   `json:"protocol,omitempty"`
+	Method   string   `json:"method,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Headers  []Header `json:"headers,omitempty"`
+	Body     string   `json:"body,omitempty"`
+	BodyNote string   `json:"bodyNote,omitempty"`
+	Settings []Pair   `json:"settings,omitempty"`
+	Route    *Route   `json:"route,omitempty"`
+	Details  []Pair   `json:"details,omitempty"`
+	Steps    []string `json:"steps,omitempty"`
 }
 
 type Header struct {
-	Name  string
-	Value string
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 type Pair struct {
-	Key   string
-	Value string
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 type Route struct {
-	Kind    string
-	Summary string
-	Notes   []string
+	Kind    string   `json:"kind,omitempty"`
+	Summary string   `json:"summary,omitempty"`
+	Notes   []string `json:"notes,omitempty"`
 }
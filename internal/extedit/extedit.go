@@ -41,7 +41,7 @@ func ResolveWith(getenv func(string) string, lookPath func(string) (string, erro
 		if raw == "" {
 			continue
 		}
-		args, err := split(raw)
+		args, err := Split(raw)
 		if err != nil {
 			return Cmd{}, fmt.Errorf("%s: %w", env, err)
 		}
@@ -56,7 +56,9 @@ func ResolveWith(getenv func(string) string, lookPath func(string) (string, erro
 	return Cmd{}, ErrNoEditor
 }
 
-func split(s string) ([]string, error) {
+// Split breaks a command line into arguments. Quotes group words the way a
+// shell does, but nothing is expanded.
+func Split(s string) ([]string, error) {
 	var args []string
 	var b strings.Builder
 	quote := rune(0)
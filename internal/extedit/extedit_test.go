@@ -125,7 +125,7 @@ func TestSplit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := split(tt.in)
+			got, err := Split(tt.in)
 			if err != nil {
 				t.Fatalf("Split returned error: %v", err)
 			}
@@ -137,7 +137,7 @@ func TestSplit(t *testing.T) {
 }
 
 func TestSplitUnterminatedQuote(t *testing.T) {
-	if _, err := split(`"code --wait`); err == nil {
+	if _, err := Split(`"code --wait`); err == nil {
 		t.Fatalf("expected unterminated quote error")
 	}
 }
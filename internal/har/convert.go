@@ -0,0 +1,447 @@
+package har
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/http/httpguts"
+
+	"github.com/unkn0wn-root/resterm/internal/directive"
+	"github.com/unkn0wn-root/resterm/internal/engine/request"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+// Mode picks the blocks generated from a capture, as --openapi-mode does for
+// a spec.
+type Mode string
+
+const (
+	ModeRequests Mode = "requests"
+	ModeMocks    Mode = "mocks"
+	ModeBoth     Mode = "both"
+)
+
+func ParseMode(raw string) (Mode, error) {
+	switch Mode(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", ModeRequests:
+		return ModeRequests, nil
+	case ModeMocks:
+		return ModeMocks, nil
+	case ModeBoth:
+		return ModeBoth, nil
+	default:
+		return "", fmt.Errorf("unsupported HAR mode %q (want requests, mocks, or both)", raw)
+	}
+}
+
+func (m Mode) requests() bool { return m != ModeMocks }
+func (m Mode) mocks() bool    { return m == ModeMocks || m == ModeBoth }
+
+type Options struct {
+	Mode Mode
+	// IncludeHosts keeps only entries whose host matches one of the patterns.
+	// A leading "*." matches any subdomain.
+	IncludeHosts []string
+	// ExcludeStatic drops scripts, stylesheets, images, fonts and media.
+	ExcludeStatic bool
+	Write         WriterOptions
+}
+
+// Result is the generated document and what was left out of it.
+type Result struct {
+	Doc        *restfile.Document
+	Warn       []string
+	Skipped    int
+	Duplicates int
+}
+
+const baseURLVar = "baseUrl"
+
+// A browser adds these on its own, and replaying them either breaks the
+// request (Content-Length, Host) or asks for an encoding the client will not
+// decode.
+var droppedRequestHeaders = map[string]bool{
+	"accept-encoding":   true,
+	"connection":        true,
+	"content-length":    true,
+	"host":              true,
+	"keep-alive":        true,
+	"proxy-connection":  true,
+	"te":                true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+}
+
+// Repeated values of these headers describe each request, not the session.
+var unpromotedHeaders = map[string]bool{
+	"accept":       true,
+	"content-type": true,
+}
+
+var staticResourceTypes = map[string]bool{
+	"font":       true,
+	"image":      true,
+	"manifest":   true,
+	"media":      true,
+	"script":     true,
+	"stylesheet": true,
+	"texttrack":  true,
+}
+
+var staticExtensions = map[string]bool{
+	".avif": true, ".bmp": true, ".css": true, ".eot": true, ".gif": true,
+	".ico": true, ".jpeg": true, ".jpg": true, ".js": true, ".map": true,
+	".mjs": true, ".mp3": true, ".mp4": true, ".otf": true, ".png": true,
+	".svg": true, ".ttf": true, ".webm": true, ".webp": true, ".woff": true,
+	".woff2": true,
+}
+
+type call struct {
+	method  string
+	url     *url.URL
+	headers []NameVal
+	body    string
+	resp    Response
+}
+
+// Convert builds one document from a capture. Calls repeated with the same
+// method, URL and body are kept once.
+func Convert(f *File, opts Options) Result {
+	var res Result
+	seen := map[string]bool{}
+	var calls []*call
+	for _, e := range f.Log.Entries {
+		c, ok := keep(e, opts)
+		if !ok {
+			res.Skipped++
+			continue
+		}
+		key := c.method + "\x00" + c.url.String() + "\x00" + c.body
+		if seen[key] {
+			res.Duplicates++
+			continue
+		}
+		seen[key] = true
+		calls = append(calls, c)
+	}
+
+	doc := &restfile.Document{}
+	res.Doc = doc
+	if len(calls) == 0 {
+		return res
+	}
+	origin := sharedOrigin(calls)
+	if origin != "" && opts.Mode.requests() {
+		doc.Variables = append(doc.Variables, restfile.Variable{
+			Name:  baseURLVar,
+			Value: origin,
+			Scope: directive.ScopeFile,
+		})
+	}
+	promoted := map[string]string{}
+	if opts.Mode.requests() {
+		doc.Variables = append(doc.Variables, promote(calls, promoted)...)
+	}
+
+	names := map[string]struct{}{}
+	mockNames := map[string]struct{}{}
+	routes := map[string]bool{}
+	for _, c := range calls {
+		label := restfile.MockNameSlug(c.method + " " + c.url.Path)
+		if opts.Mode.requests() {
+			req, warn := buildRequest(c, origin, promoted)
+			req.Metadata.Name = restfile.UniqueMockName(label, names)
+			doc.Requests = append(doc.Requests, req)
+			res.Warn = append(res.Warn, warn...)
+		}
+		if opts.Mode.mocks() {
+			route := c.method + " " + c.url.EscapedPath() + "?" + c.url.RawQuery
+			if routes[route] {
+				continue
+			}
+			routes[route] = true
+			mock, warn := buildMock(c)
+			res.Warn = append(res.Warn, warn...)
+			if mock != nil {
+				mock.Name = restfile.UniqueMockName(label, mockNames)
+				doc.Mocks = append(doc.Mocks, mock)
+			}
+		}
+	}
+	return res
+}
+
+// keep applies the filters and reports whether the entry is worth replaying.
+// CORS preflights are always dropped, since the browser sends them on its own.
+func keep(e Entry, opts Options) (*call, bool) {
+	u, err := url.Parse(strings.TrimSpace(e.Request.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, false
+	}
+	method := strings.ToUpper(strings.TrimSpace(e.Request.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	if method == http.MethodOptions && headerValue(e.Request.Headers, "Access-Control-Request-Method") != "" {
+		return nil, false
+	}
+	if len(opts.IncludeHosts) > 0 && !hostMatches(u.Hostname(), opts.IncludeHosts) {
+		return nil, false
+	}
+	if opts.ExcludeStatic && isStatic(e, u) {
+		return nil, false
+	}
+	u.Fragment = ""
+	return &call{
+		method:  method,
+		url:     u,
+		headers: e.Request.Headers,
+		body:    requestBody(e.Request.PostData),
+		resp:    e.Response,
+	}, true
+}
+
+func hostMatches(host string, patterns []string) bool {
+	host = strings.ToLower(host)
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if sub, ok := strings.CutPrefix(p, "*."); ok {
+			if host == sub || strings.HasSuffix(host, "."+sub) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p, host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isStatic(e Entry, u *url.URL) bool {
+	if staticResourceTypes[strings.ToLower(e.ResourceType)] {
+		return true
+	}
+	if staticExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return true
+	}
+	mt := strings.ToLower(e.Response.Content.MimeType)
+	for _, p := range []string{"image/", "font/", "audio/", "video/", "text/css", "text/javascript", "application/javascript"} {
+		if strings.HasPrefix(mt, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func requestBody(pd *PostData) string {
+	if pd == nil {
+		return ""
+	}
+	if pd.Text != "" || len(pd.Params) == 0 {
+		return pd.Text
+	}
+	pairs := make([]string, 0, len(pd.Params))
+	for _, p := range pd.Params {
+		pairs = append(pairs, url.QueryEscape(p.Name)+"="+url.QueryEscape(p.Value))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// sharedOrigin returns the scheme and host every call shares, or "" when the
+// capture spans several.
+func sharedOrigin(calls []*call) string {
+	origin := ""
+	for _, c := range calls {
+		o := c.url.Scheme + "://" + c.url.Host
+		if origin == "" {
+			origin = o
+		} else if o != origin {
+			return ""
+		}
+	}
+	return origin
+}
+
+// promote turns a header value sent by more than one call into a file
+// variable, so a session token lives in one place. Sensitive headers are
+// declared as secrets. into maps "name\x00value" to the variable name.
+func promote(calls []*call, into map[string]string) []restfile.Variable {
+	type pair struct{ name, value string }
+	count := map[pair]int{}
+	var order []pair
+	for _, c := range calls {
+		for _, h := range c.headers {
+			name := strings.ToLower(strings.TrimSpace(h.Name))
+			if !replayable(name) || unpromotedHeaders[name] || h.Value == "" {
+				continue
+			}
+			p := pair{name, h.Value}
+			if count[p] == 0 {
+				order = append(order, p)
+			}
+			count[p]++
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].name < order[j].name })
+
+	var out []restfile.Variable
+	used := map[string]int{}
+	for _, p := range order {
+		if count[p] < 2 {
+			continue
+		}
+		name := camel(p.name)
+		used[name]++
+		if n := used[name]; n > 1 {
+			name = fmt.Sprintf("%s%d", name, n)
+		}
+		into[p.name+"\x00"+p.value] = name
+		out = append(out, restfile.Variable{
+			Name:   name,
+			Value:  p.value,
+			Scope:  directive.ScopeFile,
+			Secret: p.name == "cookie" || request.IsSensitiveHeader(p.name),
+		})
+	}
+	return out
+}
+
+func replayable(name string) bool {
+	return name != "" &&
+		!strings.HasPrefix(name, ":") &&
+		!strings.HasPrefix(name, "sec-fetch-") &&
+		!strings.HasPrefix(name, "sec-ch-") &&
+		!droppedRequestHeaders[name]
+}
+
+func camel(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' })
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func buildRequest(c *call, origin string, promoted map[string]string) (*restfile.Request, []string) {
+	target := c.url.String()
+	if origin != "" {
+		target = "{{" + baseURLVar + "}}" + strings.TrimPrefix(target, origin)
+	}
+	req := &restfile.Request{Method: c.method, URL: target}
+	where := c.method + " " + c.url.Path
+	var warn []string
+
+	for _, h := range c.headers {
+		name := strings.TrimSpace(h.Name)
+		lower := strings.ToLower(name)
+		if !replayable(lower) {
+			continue
+		}
+		val := h.Value
+		if v, ok := promoted[lower+"\x00"+val]; ok {
+			val = "{{" + v + "}}"
+		}
+		if req.Headers == nil {
+			req.Headers = http.Header{}
+		}
+		// HTTP/2 captures spell every name in lowercase.
+		key := http.CanonicalHeaderKey(name)
+		req.Headers[key] = append(req.Headers[key], val)
+	}
+
+	if c.body != "" {
+		body, err := restwriter.CheckInlineBody(c.body)
+		if err != nil {
+			warn = append(warn, fmt.Sprintf("%s: request body was dropped: %v", where, err))
+		} else {
+			req.Body.Text = body
+		}
+	}
+	return req, warn
+}
+
+func buildMock(c *call) (*restfile.Mock, []string) {
+	where := c.method + " " + c.url.Path
+	status := c.resp.Status
+	if !restfile.ValidMockStatus(status) {
+		return nil, []string{fmt.Sprintf("%s: status %d cannot be mocked", where, status)}
+	}
+	p := c.url.EscapedPath()
+	if p == "" {
+		p = "/"
+	}
+	if err := restfile.ValidateMockPath(p); err != nil {
+		return nil, []string{fmt.Sprintf("%s: path cannot be mocked: %v", where, err)}
+	}
+
+	var warn []string
+	resp := restfile.MockResponse{Status: status, Headers: http.Header{}}
+	for _, h := range c.resp.Headers {
+		name := strings.TrimSpace(h.Name)
+		// The recorded body is already decoded, so its encoding no longer applies.
+		if strings.HasPrefix(name, ":") || strings.EqualFold(name, "Content-Encoding") ||
+			restfile.IsManagedMockResponseHeader(name) ||
+			!httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(h.Value) {
+			continue
+		}
+		resp.Headers.Add(name, h.Value)
+	}
+	if body, err := responseBody(c.resp, status); err != nil {
+		warn = append(warn, fmt.Sprintf("%s: response body was dropped: %v", where, err))
+	} else {
+		resp.Body.Text = body
+	}
+
+	mock := &restfile.Mock{
+		Title:     strings.ToUpper(c.method) + " " + c.url.Path,
+		Method:    c.method,
+		Path:      p,
+		Responses: []restfile.MockResponse{resp},
+	}
+	if q := c.url.Query(); len(q) > 0 {
+		mock.Match.Query = make(map[string]restfile.MockQueryRule, len(q))
+		for k, vals := range q {
+			mock.Match.Query[k] = restfile.MockQueryRule{Op: restfile.MockOpExact, Values: vals}
+		}
+	}
+	mock.DisableInterpolation = resp.HasTemplate()
+	return mock, warn
+}
+
+func responseBody(r Response, status int) (string, error) {
+	text := r.Content.Text
+	if text == "" || !restfile.ResponseAllowsBody(status) {
+		return "", nil
+	}
+	if r.Content.Encoding == "base64" {
+		raw, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return "", fmt.Errorf("decode base64: %w", err)
+		}
+		if !utf8.Valid(raw) {
+			return "", fmt.Errorf("binary %s content", r.Content.MimeType)
+		}
+		text = string(raw)
+	}
+	return restwriter.CheckMockBody(text)
+}
+
+func headerValue(hs []NameVal, name string) string {
+	for _, h := range hs {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package har
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+func loadCapture(t *testing.T) *File {
+	t.Helper()
+	data, err := os.ReadFile("testdata/capture.har")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return f
+}
+
+func roundTrip(t *testing.T, doc *restfile.Document) *restfile.Document {
+	t.Helper()
+	out, err := restwriter.Render(doc, restwriter.Options{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	back := parser.Parse("capture.http", []byte(out))
+	if len(back.Errors) != 0 {
+		t.Fatalf("rendered document did not parse: %v\n%s", back.Errors, out)
+	}
+	return back
+}
+
+func TestConvertFiltersAndDeduplicates(t *testing.T) {
+	res := Convert(loadCapture(t), Options{
+		Mode:          ModeRequests,
+		IncludeHosts:  []string{"*.example.com"},
+		ExcludeStatic: true,
+	})
+	if res.Duplicates != 1 {
+		t.Fatalf("duplicates = %d, want 1", res.Duplicates)
+	}
+	// preflight, script, image, tracker host and data: URL
+	if res.Skipped != 5 {
+		t.Fatalf("skipped = %d, want 5", res.Skipped)
+	}
+	doc := roundTrip(t, res.Doc)
+	if len(doc.Requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(doc.Requests))
+	}
+
+	get := doc.Requests[0]
+	if get.URL != "{{baseUrl}}/api/users?page=2" || get.Metadata.Name != "get-api-users" {
+		t.Fatalf("first request = %s %q", get.Metadata.Name, get.URL)
+	}
+	for _, h := range []string{":authority", "Accept-Encoding", "Sec-Fetch-Mode"} {
+		if get.Headers.Get(h) != "" {
+			t.Fatalf("browser header %s was kept", h)
+		}
+	}
+	if got := get.Headers.Get("Authorization"); got != "{{authorization}}" {
+		t.Fatalf("Authorization = %q, want the promoted variable", got)
+	}
+
+	post := doc.Requests[1]
+	if post.Headers.Get("Content-Length") != "" {
+		t.Fatalf("Content-Length was kept")
+	}
+	if strings.TrimSpace(post.Body.Text) != `{"name":"Ann"}` {
+		t.Fatalf("body = %q", post.Body.Text)
+	}
+
+	vars := map[string]restfile.Variable{}
+	for _, v := range doc.Variables {
+		vars[v.Name] = v
+	}
+	if vars["baseUrl"].Value != "https://app.example.com" {
+		t.Fatalf("baseUrl = %q", vars["baseUrl"].Value)
+	}
+	if v := vars["authorization"]; v.Value != "Bearer abc.def.ghi" || !v.Secret {
+		t.Fatalf("authorization = %+v, want a secret file variable", v)
+	}
+}
+
+func TestConvertMocksFromResponses(t *testing.T) {
+	res := Convert(loadCapture(t), Options{Mode: ModeMocks, ExcludeStatic: true})
+	doc := roundTrip(t, res.Doc)
+	if len(doc.Requests) != 0 {
+		t.Fatalf("mocks mode wrote %d requests", len(doc.Requests))
+	}
+	if len(doc.Mocks) != 3 {
+		t.Fatalf("mocks = %d, want 3", len(doc.Mocks))
+	}
+
+	list := doc.Mocks[0]
+	if list.Path != "/api/users" || list.Match.Query["page"].Values[0] != "2" {
+		t.Fatalf("list mock = %s %+v", list.Path, list.Match.Query)
+	}
+	resp := list.Responses[0]
+	if resp.Headers.Get("Content-Encoding") != "" || resp.Headers.Get("Content-Length") != "" {
+		t.Fatalf("transport headers were kept: %v", resp.Headers)
+	}
+	if strings.TrimSpace(resp.Body.Text) != `{"users":[{"id":1}],"page":2}` {
+		t.Fatalf("list body = %q", resp.Body.Text)
+	}
+
+	created := doc.Mocks[1]
+	if created.Responses[0].Status != 201 || strings.TrimSpace(created.Responses[0].Body.Text) != `{"id":2}` {
+		t.Fatalf("base64 body was not decoded: %+v", created.Responses[0])
+	}
+}
+
+func TestConvertBothKeepsOriginPerHostWhenMixed(t *testing.T) {
+	res := Convert(loadCapture(t), Options{Mode: ModeBoth, ExcludeStatic: true})
+	doc := roundTrip(t, res.Doc)
+	if len(doc.Requests) != 3 || len(doc.Mocks) != 3 {
+		t.Fatalf("requests = %d, mocks = %d", len(doc.Requests), len(doc.Mocks))
+	}
+	for _, v := range doc.Variables {
+		if v.Name == "baseUrl" {
+			t.Fatalf("baseUrl declared for a capture spanning two hosts")
+		}
+	}
+	if !strings.HasPrefix(doc.Requests[2].URL, "https://metrics.tracker.io/") {
+		t.Fatalf("url = %q", doc.Requests[2].URL)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": ModeRequests, "mocks": ModeMocks, "BOTH": ModeBoth} {
+		got, err := ParseMode(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMode(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseMode("all"); err == nil {
+		t.Fatalf("ParseMode accepted an unknown mode")
+	}
+}
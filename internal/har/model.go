@@ -0,0 +1,66 @@
+package har
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// File is the subset of a HAR 1.2 capture the importer reads.
+type File struct {
+	Log struct {
+		Entries []Entry `json:"entries"`
+	} `json:"log"`
+}
+
+type Entry struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+	// ResourceType is the devtools category Chromium records per entry.
+	ResourceType string `json:"_resourceType"`
+}
+
+type Request struct {
+	Method   string    `json:"method"`
+	URL      string    `json:"url"`
+	Headers  []NameVal `json:"headers"`
+	PostData *PostData `json:"postData"`
+}
+
+type NameVal struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Params   []struct {
+		Name        string `json:"name"`
+		Value       string `json:"value"`
+		FileName    string `json:"fileName"`
+		ContentType string `json:"contentType"`
+	} `json:"params"`
+}
+
+type Response struct {
+	Status  int       `json:"status"`
+	Headers []NameVal `json:"headers"`
+	Content struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+		Encoding string `json:"encoding"`
+	} `json:"content"`
+}
+
+// Parse decodes a HAR capture.
+func Parse(data []byte) (*File, error) {
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("har: parse: %w", err)
+	}
+	if f.Log.Entries == nil {
+		return nil, errors.New("har: not a HAR file (missing log.entries)")
+	}
+	return &f, nil
+}
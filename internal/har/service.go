@@ -0,0 +1,79 @@
+package har
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/util"
+)
+
+const errWriterNotConfigured = "har: writer not configured"
+
+type DocumentWriter interface {
+	WriteDocument(ctx context.Context, doc *restfile.Document, dst string, opts WriterOptions) error
+}
+
+type WriterOptions struct {
+	OverwriteExisting bool
+	HeaderComment     string
+}
+
+type Service struct {
+	Writer DocumentWriter
+}
+
+// GenerateHTTPFile converts the capture at src and writes it to dst.
+func (s *Service) GenerateHTTPFile(ctx context.Context, src, dst string, opts Options) (Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if s.Writer == nil {
+		return Result{}, errors.New(errWriterNotConfigured)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return Result{}, fmt.Errorf("har: read capture: %w", err)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return Result{}, err
+	}
+	res := Convert(f, opts)
+	if len(res.Doc.Requests)+len(res.Doc.Mocks) == 0 {
+		return res, errors.New("har: no entries left to convert after filtering")
+	}
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	w := opts.Write
+	w.HeaderComment = buildHeader(w.HeaderComment, src, res)
+	return res, s.Writer.WriteDocument(ctx, res.Doc, dst, w)
+}
+
+func buildHeader(base, src string, res Result) string {
+	var lines []string
+	for line := range strings.SplitSeq(base, "\n") {
+		if t := strings.TrimSpace(line); t != "" {
+			lines = append(lines, t)
+		}
+	}
+	lines = append(lines, "Source: HAR capture "+filepath.Base(src))
+	if res.Duplicates > 0 {
+		lines = append(lines, fmt.Sprintf("Merged %d repeated calls", res.Duplicates))
+	}
+	if res.Skipped > 0 {
+		lines = append(lines, fmt.Sprintf("Skipped %d filtered entries", res.Skipped))
+	}
+	warn := append([]string(nil), res.Warn...)
+	sort.Strings(warn)
+	for _, w := range util.DedupeSortedStrings(warn) {
+		lines = append(lines, "Warning: "+w)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -249,6 +249,18 @@ func topicDefs() []topicDef {
 			aliases: []string{"mock", "mock-server"},
 			doc:     manual("Mock Servers"),
 		},
+		{
+			id: "contracts", title: "Contract Tests",
+			summary: "Validate responses against an OpenAPI spec",
+			aliases: []string{"contract", "contract-tests"},
+			doc:     manual("Contract Tests"),
+		},
+		{
+			id: "snapshots", title: "Snapshot Testing",
+			summary: "Diff responses against golden files",
+			aliases: []string{"snapshot", "golden"},
+			doc:     manual("Snapshot Testing"),
+		},
 		{
 			id: "workflows", title: "Workflows",
 			summary: "Chain named requests with conditions, branches, and loops",
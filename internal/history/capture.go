@@ -0,0 +1,77 @@
+package history
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultCaptureMaxBody caps each stored body or transcript when full capture
+// is on and no other limit is set.
+const DefaultCaptureMaxBody int64 = 4 << 20
+
+// Capture is the complete response kept for an entry in full-capture mode.
+// Listings carry it without Body and Transcript; LoadCapture fills them in.
+// Everything in it is redacted before it reaches the store.
+type Capture struct {
+	Proto        string      `json:"proto,omitempty"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Trailers     http.Header `json:"trailers,omitempty"`
+	ContentType  string      `json:"contentType,omitempty"`
+	EffectiveURL string      `json:"effectiveUrl,omitempty"`
+	// StatusMessage is the gRPC status message.
+	StatusMessage string `json:"statusMessage,omitempty"`
+	Body          []byte `json:"body,omitempty"`
+	// BodySize is the length of the response body before the store cut it
+	// to its size cap.
+	BodySize   int64  `json:"bodySize,omitempty"`
+	Transcript []byte `json:"transcript,omitempty"`
+	// TranscriptSize is the stream transcript length before the size cap.
+	TranscriptSize int64 `json:"transcriptSize,omitempty"`
+	// Truncated is set when the body or transcript hit the size cap.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CaptureOptions configure full capture. MaxBody caps each body and
+// transcript; zero means DefaultCaptureMaxBody. Retention drops captures
+// older than it while keeping their entries; zero keeps them as long as the
+// entry.
+type CaptureOptions struct {
+	Enabled   bool
+	MaxBody   int64
+	Retention time.Duration
+}
+
+// Limit is the effective per-blob size cap.
+func (o CaptureOptions) Limit() int64 {
+	if o.MaxBody > 0 {
+		return o.MaxBody
+	}
+	return DefaultCaptureMaxBody
+}
+
+// CaptureStore is a Store that can keep full responses.
+type CaptureStore interface {
+	Store
+	Capturing() bool
+	LoadCapture(id string) (*Capture, error)
+}
+
+// Capturing reports whether s keeps full responses, so callers can skip
+// building captures the store would drop.
+func Capturing(s Store) bool {
+	cs, ok := s.(CaptureStore)
+	return ok && cs.Capturing()
+}
+
+// LoadCapture returns the full capture of an entry, or nil when s does not
+// keep captures or the entry has none.
+func LoadCapture(s Store, e Entry) (*Capture, error) {
+	if e.Capture == nil {
+		return nil, nil
+	}
+	cs, ok := s.(CaptureStore)
+	if !ok {
+		return e.Capture, nil
+	}
+	return cs.LoadCapture(e.ID)
+}
@@ -0,0 +1,434 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/unkn0wn-root/resterm/internal/duration"
+)
+
+// QueryField names the entry field a query term tests. FieldText matches the
+// request name, URL, description, tags, environment and compare labels.
+type QueryField string
+
+const (
+	FieldText   QueryField = ""
+	FieldStatus QueryField = "status"
+	FieldEnv    QueryField = "env"
+	FieldMethod QueryField = "method"
+	FieldDur    QueryField = "dur"
+	FieldAfter  QueryField = "after"
+	FieldBefore QueryField = "before"
+	FieldDate   QueryField = "date"
+	FieldTag    QueryField = "tag"
+	FieldBody   QueryField = "body"
+	FieldName   QueryField = "name"
+	FieldURL    QueryField = "url"
+)
+
+var queryFields = map[string]QueryField{
+	"status": FieldStatus,
+	"env":    FieldEnv,
+	"method": FieldMethod,
+	"dur":    FieldDur,
+	"after":  FieldAfter,
+	"before": FieldBefore,
+	"date":   FieldDate,
+	"tag":    FieldTag,
+	"body":   FieldBody,
+	"name":   FieldName,
+	"url":    FieldURL,
+}
+
+// Query is a parsed history search such as
+// `status:5xx env:prod dur:>800ms body:"insufficient funds"`.
+// An entry matches when it matches every term.
+type Query struct {
+	Terms []QueryTerm
+}
+
+// QueryTerm is one `key:value` or free-text part of a query. Text values are
+// lower-cased, except Method which is upper-cased. Min and Max bound the
+// status code or the duration in nanoseconds, both inclusive. Ranges hold
+// the time windows of date, after and before terms.
+type QueryTerm struct {
+	Field  QueryField
+	Neg    bool
+	Value  string
+	Min    int64
+	Max    int64
+	Ranges []TimeRange
+}
+
+// TimeRange is a half-open window; a zero Start or End leaves that side open.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (r TimeRange) Contains(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	if !r.Start.IsZero() && t.Before(r.Start) {
+		return false
+	}
+	return r.End.IsZero() || t.Before(r.End)
+}
+
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0
+}
+
+// HasBody reports whether q searches response bodies, which only a store
+// with a body index can answer beyond the snippet.
+func (q Query) HasBody() bool {
+	for _, t := range q.Terms {
+		if t.Field == FieldBody {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseQuery parses a history query. Keys are case-insensitive, values may
+// be quoted, a leading "-" negates a term, and "key: value" with a space
+// is accepted. Unknown keys are searched as text.
+func ParseQuery(s string, now time.Time) (Query, error) {
+	var (
+		q    Query
+		errs []error
+	)
+	toks := splitQuery(s)
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		neg := false
+		if len(tok.text) > 1 && tok.text[0] == '-' && !tok.quoted {
+			neg = true
+			tok.text = tok.text[1:]
+		}
+		key, val, ok := strings.Cut(tok.text, ":")
+		f, known := queryFields[strings.ToLower(key)]
+		if !ok || !known || tok.quoted {
+			q.Terms = append(q.Terms, QueryTerm{
+				Field: FieldText,
+				Neg:   neg,
+				Value: strings.ToLower(tok.text),
+			})
+			continue
+		}
+		if val == "" && i+1 < len(toks) {
+			i++
+			val = toks[i].text
+		}
+		t, err := parseTerm(f, strings.TrimSpace(val), now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		t.Neg = neg
+		q.Terms = append(q.Terms, t)
+	}
+	return q, errors.Join(errs...)
+}
+
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits on spaces outside double quotes and drops the quotes.
+// A token that is quoted as a whole is always free text.
+func splitQuery(s string) []queryToken {
+	var (
+		out   []queryToken
+		cur   strings.Builder
+		inQ   bool
+		whole bool
+		start = true
+	)
+	flush := func() {
+		if cur.Len() > 0 || whole {
+			out = append(out, queryToken{text: cur.String(), quoted: whole})
+		}
+		cur.Reset()
+		whole = false
+		start = true
+	}
+	for _, r := range s {
+		switch {
+		case r == '"':
+			if start {
+				whole = true
+			}
+			inQ = !inQ
+		case unicode.IsSpace(r) && !inQ:
+			flush()
+			continue
+		default:
+			cur.WriteRune(r)
+		}
+		start = false
+	}
+	flush()
+	return out
+}
+
+func parseTerm(f QueryField, val string, now time.Time) (QueryTerm, error) {
+	t := QueryTerm{Field: f}
+	if val == "" {
+		return t, fmt.Errorf("%s: missing value", f)
+	}
+	var ok bool
+	switch f {
+	case FieldStatus:
+		t.Min, t.Max, ok = parseStatusRange(val)
+	case FieldDur:
+		t.Min, t.Max, ok = parseRange(val, true, func(s string) (int64, bool) {
+			d, ok := duration.Parse(s)
+			return int64(d), ok && d >= 0
+		})
+	case FieldAfter:
+		var at time.Time
+		if at, ok = parseWhen(val, now); ok {
+			t.Ranges = []TimeRange{{Start: at}}
+		}
+	case FieldBefore:
+		var at time.Time
+		if at, ok = parseWhen(val, now); ok {
+			t.Ranges = []TimeRange{{End: at}}
+		}
+	case FieldDate:
+		t.Ranges, ok = parseDateRanges(val, now)
+	case FieldMethod:
+		t.Value, ok = strings.ToUpper(val), true
+	default:
+		t.Value, ok = strings.ToLower(val), true
+	}
+	if !ok {
+		return t, fmt.Errorf("%s: invalid value %q", f, val)
+	}
+	return t, nil
+}
+
+// parseStatusRange accepts an exact code, a class such as 5xx, a comparison
+// such as >=400, or a range such as 400..499.
+func parseStatusRange(v string) (int64, int64, bool) {
+	if len(v) == 3 && strings.EqualFold(v[1:], "xx") && v[0] >= '1' && v[0] <= '9' {
+		base := int64(v[0]-'0') * 100
+		return base, base + 99, true
+	}
+	return parseRange(v, false, func(s string) (int64, bool) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		return n, err == nil && n >= 0
+	})
+}
+
+// parseRange reads ">x", ">=x", "<x", "<=x", "=x", "a..b" or a bare value,
+// which is exact unless atLeast makes it a lower bound.
+func parseRange(v string, atLeast bool, num func(string) (int64, bool)) (int64, int64, bool) {
+	if a, b, ok := strings.Cut(v, ".."); ok {
+		lo, ok1 := num(a)
+		hi, ok2 := num(b)
+		return lo, hi, ok1 && ok2 && lo <= hi
+	}
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		rest, ok := strings.CutPrefix(v, op)
+		if !ok {
+			continue
+		}
+		n, ok := num(strings.TrimSpace(rest))
+		if !ok {
+			return 0, 0, false
+		}
+		switch op {
+		case ">=":
+			return n, math.MaxInt64, true
+		case "<=":
+			return 0, n, true
+		case ">":
+			return n + 1, math.MaxInt64, n < math.MaxInt64
+		case "<":
+			return 0, n - 1, n > 0
+		default:
+			return n, n, true
+		}
+	}
+	n, ok := num(v)
+	if atLeast {
+		return n, math.MaxInt64, ok
+	}
+	return n, n, ok
+}
+
+// parseWhen reads an ISO date (the start of that local day), an RFC 3339
+// time, today, yesterday, or a duration such as 7d meaning that long ago.
+func parseWhen(v string, now time.Time) (time.Time, bool) {
+	switch strings.ToLower(v) {
+	case "today":
+		return dayStart(now), true
+	case "yesterday":
+		return dayStart(now.AddDate(0, 0, -1)), true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, now.Location()); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, true
+	}
+	if d, ok := duration.Parse(v); ok && d > 0 {
+		return now.Add(-d), true
+	}
+	return time.Time{}, false
+}
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"02-01-2006",
+	"01-02-2006",
+	"02-Jan-2006",
+	"2-Jan-2006",
+	"02-January-2006",
+	"2-January-2006",
+}
+
+// parseDateRanges resolves a day. Ambiguous dates such as 05-06-2024 match
+// both readings.
+func parseDateRanges(v string, now time.Time) ([]TimeRange, bool) {
+	switch strings.ToLower(v) {
+	case "today":
+		return []TimeRange{dayRange(now)}, true
+	case "yesterday":
+		return []TimeRange{dayRange(now.AddDate(0, 0, -1))}, true
+	}
+	var out []TimeRange
+	seen := make(map[time.Time]struct{})
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, v, now.Location())
+		if err != nil {
+			continue
+		}
+		r := dayRange(t)
+		if _, ok := seen[r.Start]; ok {
+			continue
+		}
+		seen[r.Start] = struct{}{}
+		out = append(out, r)
+	}
+	return out, len(out) > 0
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func dayRange(t time.Time) TimeRange {
+	start := dayStart(t)
+	return TimeRange{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+// Match reports whether e satisfies every term. Body terms only see the
+// snippet here; stores with a body index search the full response.
+func (q Query) Match(e Entry) bool {
+	for _, t := range q.Terms {
+		if t.match(e) == t.Neg {
+			return false
+		}
+	}
+	return true
+}
+
+func (t QueryTerm) match(e Entry) bool {
+	switch t.Field {
+	case FieldStatus:
+		return int64(e.StatusCode) >= t.Min && int64(e.StatusCode) <= t.Max
+	case FieldDur:
+		return int64(e.Duration) >= t.Min && int64(e.Duration) <= t.Max
+	case FieldAfter, FieldBefore, FieldDate:
+		for _, r := range t.Ranges {
+			if r.Contains(e.ExecutedAt) {
+				return true
+			}
+		}
+		return false
+	case FieldEnv:
+		return strings.EqualFold(strings.TrimSpace(e.Environment), t.Value)
+	case FieldMethod:
+		return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(e.Method)), t.Value)
+	case FieldTag:
+		for _, tag := range e.Tags {
+			if strings.EqualFold(strings.TrimSpace(tag), t.Value) {
+				return true
+			}
+		}
+		return false
+	case FieldBody:
+		return strings.Contains(strings.ToLower(e.BodySnippet), t.Value)
+	case FieldName:
+		return strings.Contains(strings.ToLower(e.RequestName), t.Value)
+	case FieldURL:
+		return strings.Contains(strings.ToLower(e.URL), t.Value)
+	default:
+		return strings.Contains(SearchText(e), t.Value)
+	}
+}
+
+// SearchText is the lower-cased text free-text terms are matched against.
+func SearchText(e Entry) string {
+	parts := []string{
+		e.RequestName,
+		e.URL,
+		e.Description,
+		strings.Join(e.Tags, " "),
+		e.Environment,
+	}
+	if e.Compare != nil {
+		for _, res := range e.Compare.Results {
+			parts = append(parts, res.Label(), res.Status)
+		}
+	}
+	return strings.ToLower(strings.Join(parts, " "))
+}
+
+// Filter returns the entries of es that match q.
+func Filter(es []Entry, q Query) []Entry {
+	if q.Empty() {
+		return es
+	}
+	out := make([]Entry, 0, len(es))
+	for _, e := range es {
+		if q.Match(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// SearchStore is a Store that runs queries itself, searching full response
+// bodies instead of only snippets.
+type SearchStore interface {
+	Store
+	Search(q Query, limit int) ([]Entry, error)
+}
+
+// Search runs q against s, newest first, returning at most limit entries
+// when limit is positive.
+func Search(s Store, q Query, limit int) ([]Entry, error) {
+	if ss, ok := s.(SearchStore); ok {
+		return ss.Search(q, limit)
+	}
+	es, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+	es = Filter(es, q)
+	if limit > 0 && len(es) > limit {
+		es = es[:limit]
+	}
+	return es, nil
+}
@@ -0,0 +1,183 @@
+package history
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	q, err := ParseQuery(
+		`status:5xx env:prod method:post dur:>800ms after:2026-10-01 tag:smoke `+
+			`body:"insufficient funds" -url:health "List Users"`,
+		now,
+	)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []QueryTerm{
+		{Field: FieldStatus, Min: 500, Max: 599},
+		{Field: FieldEnv, Value: "prod"},
+		{Field: FieldMethod, Value: "POST"},
+		{Field: FieldDur, Min: int64(800*time.Millisecond) + 1, Max: math.MaxInt64},
+		{
+			Field:  FieldAfter,
+			Ranges: []TimeRange{{Start: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}},
+		},
+		{Field: FieldTag, Value: "smoke"},
+		{Field: FieldBody, Value: "insufficient funds"},
+		{Field: FieldURL, Value: "health", Neg: true},
+		{Field: FieldText, Value: "list users"},
+	}
+	if len(q.Terms) != len(want) {
+		t.Fatalf("terms = %+v", q.Terms)
+	}
+	for i, w := range want {
+		got := q.Terms[i]
+		if got.Field != w.Field || got.Value != w.Value || got.Neg != w.Neg ||
+			got.Min != w.Min || got.Max != w.Max || len(got.Ranges) != len(w.Ranges) {
+			t.Fatalf("term %d = %+v, want %+v", i, got, w)
+		}
+		for j := range w.Ranges {
+			if !got.Ranges[j].Start.Equal(w.Ranges[j].Start) ||
+				!got.Ranges[j].End.Equal(w.Ranges[j].End) {
+				t.Fatalf("term %d ranges = %+v, want %+v", i, got.Ranges, w.Ranges)
+			}
+		}
+	}
+}
+
+func TestParseQueryRanges(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in       string
+		min, max int64
+	}{
+		{"status:404", 404, 404},
+		{"status:>=400", 400, math.MaxInt64},
+		{"status:<300", 0, 299},
+		{"status:400..499", 400, 499},
+		{"dur:1s", int64(time.Second), math.MaxInt64},
+		{"dur:<=250ms", 0, int64(250 * time.Millisecond)},
+		{"dur:100ms..1s", int64(100 * time.Millisecond), int64(time.Second)},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.in, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		if got := q.Terms[0]; got.Min != tt.min || got.Max != tt.max {
+			t.Fatalf("%s: got %d..%d, want %d..%d", tt.in, got.Min, got.Max, tt.min, tt.max)
+		}
+	}
+}
+
+func TestParseQueryRejectsBadValues(t *testing.T) {
+	now := time.Now()
+	for _, in := range []string{"status:abc", "dur:slow", "after:someday", "date:32-13-2024", "env:"} {
+		if _, err := ParseQuery(in, now); err == nil {
+			t.Fatalf("%s: expected error", in)
+		}
+	}
+}
+
+func TestParseQueryKeepsUnknownKeysAsText(t *testing.T) {
+	q, err := ParseQuery("https://api.example.com foo:bar", time.Now())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(q.Terms) != 2 || q.Terms[0].Field != FieldText || q.Terms[1].Value != "foo:bar" {
+		t.Fatalf("terms = %+v", q.Terms)
+	}
+}
+
+func TestParseQueryRelativeAndAmbiguousDates(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	q, err := ParseQuery("after:7d date:05-06-2024", now)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := q.Terms[0].Ranges[0].Start; !got.Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("after:7d start = %v", got)
+	}
+	rs := q.Terms[1].Ranges
+	may := time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)
+	jun := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+	if len(rs) != 2 || !(rs[0].Contains(may) || rs[1].Contains(may)) ||
+		!(rs[0].Contains(jun) || rs[1].Contains(jun)) {
+		t.Fatalf("ambiguous date ranges = %+v", rs)
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	e := Entry{
+		ExecutedAt:  time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC),
+		Environment: "Prod",
+		RequestName: "Charge card",
+		Method:      "POST",
+		URL:         "https://api.example.com/charges",
+		StatusCode:  502,
+		Duration:    900 * time.Millisecond,
+		Tags:        []string{"Smoke"},
+		BodySnippet: `{"error":"Insufficient funds"}`,
+	}
+	tests := []struct {
+		q    string
+		want bool
+	}{
+		{`status:5xx env:prod method:PO dur:>800ms after:2026-10-01 tag:smoke`, true},
+		{`body:"insufficient funds" charge`, true},
+		{`status:5xx -tag:smoke`, false},
+		{`before:2026-10-12`, false},
+		{`date:today`, false},
+		{`name:refund`, false},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.q, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.q, err)
+		}
+		if got := q.Match(e); got != tt.want {
+			t.Fatalf("%s: match = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryDateLayouts(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"10-01-2024", time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{"01-10-2024", time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{"05-Jun-2024", time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)},
+		{"2024-06-05", time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery("date:"+tt.in, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		e := Entry{ExecutedAt: tt.want}
+		if !q.Match(e) {
+			t.Fatalf("%s: expected range to include %v", tt.in, tt.want)
+		}
+		if q.Match(Entry{ExecutedAt: tt.want.AddDate(0, 0, 2)}) {
+			t.Fatalf("%s: range too wide", tt.in)
+		}
+	}
+}
+
+func TestParseQueryKeyWithSpace(t *testing.T) {
+	q, err := ParseQuery("method: GET users", time.Now())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(q.Terms) != 2 || q.Terms[0].Field != FieldMethod || q.Terms[0].Value != "GET" ||
+		q.Terms[1].Value != "users" {
+		t.Fatalf("terms = %+v", q.Terms)
+	}
+}
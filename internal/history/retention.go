@@ -0,0 +1,103 @@
+package history
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Retention bounds how much history the store keeps. Zero limits are off.
+type Retention struct {
+	RetentionLimits
+	// MaxBytes caps the stored size of entries and their captures. The
+	// oldest entries go first once it is exceeded, failures included.
+	MaxBytes int64
+	// Workspaces override the limits for entries recorded from files under
+	// Root. The longest matching root wins.
+	Workspaces []WorkspaceRetention
+}
+
+// RetentionLimits are the limits a workspace can override. In an override,
+// zero falls back to the global limit and a negative value turns it off.
+type RetentionLimits struct {
+	MaxAge time.Duration
+	// MaxPerRequest keeps only the newest runs of each request.
+	MaxPerRequest int
+	// FailureMaxAge, when set, replaces MaxAge for failed runs and keeps
+	// them regardless of MaxPerRequest until they reach it.
+	FailureMaxAge time.Duration
+}
+
+type WorkspaceRetention struct {
+	Root string
+	RetentionLimits
+}
+
+// PruneReport describes what a prune removed, or would remove on a dry run.
+// Bytes counts stored data, not file size; compacting returns it to the
+// file system.
+type PruneReport struct {
+	Entries int64
+	ByAge   int64
+	ByCount int64
+	BySize  int64
+	Blobs   int64
+	Bytes   int64
+	Kept    int64
+}
+
+func (r Retention) Enabled() bool {
+	if r.MaxBytes > 0 || r.RetentionLimits.enabled() {
+		return true
+	}
+	for _, w := range r.Workspaces {
+		if w.RetentionLimits.enabled() {
+			return true
+		}
+	}
+	return false
+}
+
+func (l RetentionLimits) enabled() bool {
+	return l.MaxAge > 0 || l.MaxPerRequest > 0 || l.FailureMaxAge > 0
+}
+
+// LimitsFor resolves the limits for an entry recorded from path, which
+// should be normalized with NormPath. The result has no negative values.
+func (r Retention) LimitsFor(path string) RetentionLimits {
+	out := r.RetentionLimits
+	best := -1
+	var ov *RetentionLimits
+	for i := range r.Workspaces {
+		w := &r.Workspaces[i]
+		root := NormPath(w.Root)
+		if root == "" || len(root) <= best || !underRoot(path, root) {
+			continue
+		}
+		best = len(root)
+		ov = &w.RetentionLimits
+	}
+	if ov != nil {
+		if ov.MaxAge != 0 {
+			out.MaxAge = ov.MaxAge
+		}
+		if ov.MaxPerRequest != 0 {
+			out.MaxPerRequest = ov.MaxPerRequest
+		}
+		if ov.FailureMaxAge != 0 {
+			out.FailureMaxAge = ov.FailureMaxAge
+		}
+	}
+	out.MaxAge = max(out.MaxAge, 0)
+	out.MaxPerRequest = max(out.MaxPerRequest, 0)
+	out.FailureMaxAge = max(out.FailureMaxAge, 0)
+	return out
+}
+
+func underRoot(path, root string) bool {
+	if path == root {
+		return true
+	}
+	sep := string(filepath.Separator)
+	return strings.HasPrefix(path, strings.TrimSuffix(root, sep)+sep)
+}
@@ -0,0 +1,37 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRetentionLimitsFor(t *testing.T) {
+	r := Retention{
+		RetentionLimits: RetentionLimits{MaxAge: 24 * time.Hour, MaxPerRequest: 10},
+		Workspaces: []WorkspaceRetention{
+			{Root: filepath.FromSlash("/work"), RetentionLimits: RetentionLimits{MaxPerRequest: 50}},
+			{
+				Root:            filepath.FromSlash("/work/payments/"),
+				RetentionLimits: RetentionLimits{MaxAge: -1, FailureMaxAge: 72 * time.Hour},
+			},
+		},
+	}
+	tests := []struct {
+		path string
+		want RetentionLimits
+	}{
+		{"/other/a.http", RetentionLimits{MaxAge: 24 * time.Hour, MaxPerRequest: 10}},
+		{"/workshop/a.http", RetentionLimits{MaxAge: 24 * time.Hour, MaxPerRequest: 10}},
+		{"/work/api/a.http", RetentionLimits{MaxAge: 24 * time.Hour, MaxPerRequest: 50}},
+		{"/work/payments/a.http", RetentionLimits{MaxPerRequest: 10, FailureMaxAge: 72 * time.Hour}},
+	}
+	for _, tt := range tests {
+		if got := r.LimitsFor(NormPath(filepath.FromSlash(tt.path))); got != tt.want {
+			t.Fatalf("%s: limits = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+	if (Retention{}).Enabled() {
+		t.Fatalf("zero retention should be off")
+	}
+}
@@ -0,0 +1,202 @@
+package sqlite
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/history"
+)
+
+var _ history.CaptureStore = (*Store)(nil)
+
+// SetCapture turns full-response capture on or off for later appends.
+// Entries appended while it is off keep only their snippet.
+func (s *Store) SetCapture(opt history.CaptureOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cap = opt
+}
+
+func (s *Store) Capturing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cap.Enabled
+}
+
+func (s *Store) captureOptions() history.CaptureOptions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cap
+}
+
+// LoadCapture returns the entry's capture with its body and transcript, or
+// nil when the entry has none or retention already dropped it.
+func (s *Store) LoadCapture(id string) (*history.Capture, error) {
+	if err := s.ensure(); err != nil {
+		return nil, err
+	}
+
+	var (
+		capJSON        []byte
+		bodyRef, txRef sql.NullString
+	)
+	err := s.db.QueryRow(
+		`SELECT cap_json, body_ref, tx_ref FROM hist WHERE id = ?`,
+		id,
+	).Scan(&capJSON, &bodyRef, &txRef)
+	if errors.Is(err, sql.ErrNoRows) || len(capJSON) == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, diag.WrapAs(diag.ClassHistory, err, "query history capture")
+	}
+	c, err := dec[history.Capture](capJSON)
+	if err != nil {
+		return nil, diag.WrapAs(diag.ClassHistory, err, "decode history capture")
+	}
+	if c.Body, err = s.blob(bodyRef); err != nil {
+		return nil, err
+	}
+	if c.Transcript, err = s.blob(txRef); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *Store) blob(ref sql.NullString) ([]byte, error) {
+	if !ref.Valid || ref.String == "" {
+		return nil, nil
+	}
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM blob WHERE hash = ?`, ref.String).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, diag.WrapAs(diag.ClassHistory, err, "read history blob")
+	}
+	return data, nil
+}
+
+// fillCaptures loads bodies into listed entries, for exports that must
+// round-trip through ImportJSON.
+func (s *Store) fillCaptures(es []history.Entry) error {
+	for i := range es {
+		if es[i].Capture == nil {
+			continue
+		}
+		c, err := s.LoadCapture(es[i].ID)
+		if err != nil {
+			return err
+		}
+		es[i].Capture = c
+	}
+	return nil
+}
+
+type blobRow struct {
+	hash string
+	data []byte
+}
+
+// capRow splits a capture into its metadata and the blobs it references.
+// Equal bodies hash to the same key, so INSERT OR IGNORE stores them once.
+func capRow(c *history.Capture, r *row) error {
+	if c == nil {
+		return nil
+	}
+	meta := *c
+	meta.Body, meta.Transcript = nil, nil
+	if meta.BodySize == 0 {
+		meta.BodySize = int64(len(c.Body))
+	}
+	if meta.TranscriptSize == 0 {
+		meta.TranscriptSize = int64(len(c.Transcript))
+	}
+	var err error
+	if r.capJSON, err = enc(meta); err != nil {
+		return diag.WrapAs(diag.ClassHistory, err, "encode history capture")
+	}
+	r.bodyRef = r.addBlob(c.Body)
+	r.txRef = r.addBlob(c.Transcript)
+	return nil
+}
+
+func (r *row) addBlob(data []byte) sql.NullString {
+	if len(data) == 0 {
+		return sql.NullString{}
+	}
+	sum := sha256.Sum256(data)
+	h := hex.EncodeToString(sum[:])
+	r.blobs = append(r.blobs, blobRow{hash: h, data: data})
+	return sql.NullString{String: h, Valid: true}
+}
+
+func insertBlobs(db execer, r *row) error {
+	for _, b := range r.blobs {
+		if _, err := db.Exec(
+			`INSERT OR IGNORE INTO blob (hash, size, data) VALUES (?, ?, ?)`,
+			b.hash,
+			len(b.data),
+			b.data,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitCapture cuts the body and transcript to the size cap. The sizes
+// recorded before the cut let the UI say how much was dropped.
+func limitCapture(c *history.Capture, limit int64) *history.Capture {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.BodySize = max(out.BodySize, int64(len(c.Body)))
+	out.TranscriptSize = max(out.TranscriptSize, int64(len(c.Transcript)))
+	if int64(len(out.Body)) > limit {
+		out.Body = out.Body[:limit]
+		out.Truncated = true
+	}
+	if int64(len(out.Transcript)) > limit {
+		out.Transcript = out.Transcript[:limit]
+		out.Truncated = true
+	}
+	return &out
+}
+
+// pruneCaptures drops captures older than the retention window. Their
+// entries stay, with the snippet as before.
+func pruneCaptures(db execer, retention time.Duration, now time.Time) error {
+	if retention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-retention).UnixNano()
+	res, err := db.Exec(
+		`UPDATE hist SET cap_json = NULL, body_ref = NULL, tx_ref = NULL
+		WHERE cap_json IS NOT NULL AND exec_ns < ?`,
+		cutoff,
+	)
+	if err != nil {
+		return diag.WrapAs(diag.ClassHistory, err, "prune history captures")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil
+	}
+	return gcBlobs(db)
+}
+
+// gcBlobs removes blobs no entry references any more.
+func gcBlobs(db execer) error {
+	if _, err := db.Exec(`DELETE FROM blob WHERE
+		NOT EXISTS (SELECT 1 FROM hist WHERE hist.body_ref = blob.hash) AND
+		NOT EXISTS (SELECT 1 FROM hist WHERE hist.tx_ref = blob.hash)`); err != nil {
+		return diag.WrapAs(diag.ClassHistory, err, "collect history blobs")
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package sqlite
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/history"
+)
+
+func newCaptureStore(t *testing.T, opt history.CaptureOptions) *Store {
+	t.Helper()
+	s := New(filepath.Join(t.TempDir(), "history.db"))
+	s.SetCapture(opt)
+	if err := s.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func captureEntry(id string, at time.Time, body string) history.Entry {
+	return history.Entry{
+		ID:          id,
+		ExecutedAt:  at,
+		BodySnippet: body,
+		Capture: &history.Capture{
+			Headers:     http.Header{"Content-Type": {"application/json"}},
+			ContentType: "application/json",
+			Body:        []byte(body),
+		},
+	}
+}
+
+func blobCount(t *testing.T, s *Store) int64 {
+	t.Helper()
+	st, err := s.Stats()
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	return st.Blobs
+}
+
+func TestCaptureRoundTripsAndDeduplicates(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{Enabled: true})
+	now := time.Now()
+	for _, id := range []string{"1", "2"} {
+		if err := s.Append(captureEntry(id, now, `{"ok":true}`)); err != nil {
+			t.Fatalf("append %s: %v", id, err)
+		}
+	}
+	if err := s.Append(captureEntry("3", now, `{"ok":false}`)); err != nil {
+		t.Fatalf("append 3: %v", err)
+	}
+	if n := blobCount(t, s); n != 2 {
+		t.Fatalf("blobs = %d, want 2", n)
+	}
+
+	es, err := s.Entries()
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if es[0].Capture == nil || es[0].Capture.Body != nil {
+		t.Fatalf("listing should carry capture metadata only: %+v", es[0].Capture)
+	}
+	c, err := s.LoadCapture("2")
+	if err != nil {
+		t.Fatalf("load capture: %v", err)
+	}
+	if string(c.Body) != `{"ok":true}` || c.Headers.Get("Content-Type") != "application/json" {
+		t.Fatalf("capture = %+v", c)
+	}
+
+	if _, err := s.Delete("1"); err != nil {
+		t.Fatalf("delete 1: %v", err)
+	}
+	if n := blobCount(t, s); n != 2 {
+		t.Fatalf("blobs after deleting a shared body = %d, want 2", n)
+	}
+	if _, err := s.Delete("2"); err != nil {
+		t.Fatalf("delete 2: %v", err)
+	}
+	if n := blobCount(t, s); n != 1 {
+		t.Fatalf("blobs after deleting the last reference = %d, want 1", n)
+	}
+}
+
+func TestCaptureOffDropsCapture(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{})
+	if err := s.Append(captureEntry("1", time.Now(), "body")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	c, err := s.LoadCapture("1")
+	if err != nil || c != nil {
+		t.Fatalf("capture = %+v, %v; want none", c, err)
+	}
+}
+
+func TestCaptureTruncatesToLimit(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{Enabled: true, MaxBody: 4})
+	if err := s.Append(captureEntry("1", time.Now(), "abcdefgh")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	c, err := s.LoadCapture("1")
+	if err != nil {
+		t.Fatalf("load capture: %v", err)
+	}
+	if string(c.Body) != "abcd" || !c.Truncated || c.BodySize != 8 {
+		t.Fatalf("capture = %+v", c)
+	}
+}
+
+func TestCaptureRetentionKeepsEntries(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{Enabled: true, Retention: time.Hour})
+	old := time.Now().Add(-2 * time.Hour)
+	if err := s.Append(captureEntry("1", old, "old")); err != nil {
+		t.Fatalf("append old: %v", err)
+	}
+	if err := s.Append(captureEntry("2", time.Now(), "new")); err != nil {
+		t.Fatalf("append new: %v", err)
+	}
+	es, err := s.Entries()
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(es) != 2 {
+		t.Fatalf("entries = %d, want 2", len(es))
+	}
+	if c, _ := s.LoadCapture("1"); c != nil {
+		t.Fatalf("expired capture kept: %+v", c)
+	}
+	if c, _ := s.LoadCapture("2"); c == nil || string(c.Body) != "new" {
+		t.Fatalf("recent capture = %+v", c)
+	}
+	if n := blobCount(t, s); n != 1 {
+		t.Fatalf("blobs = %d, want 1", n)
+	}
+}
+
+func TestCaptureSurvivesExportImport(t *testing.T) {
+	src := newCaptureStore(t, history.CaptureOptions{Enabled: true})
+	if err := src.Append(captureEntry("1", time.Now(), `{"id":7}`)); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "export.json")
+	if _, err := src.ExportJSON(out); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	dst := newCaptureStore(t, history.CaptureOptions{})
+	if _, err := dst.ImportJSON(out); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	c, err := dst.LoadCapture("1")
+	if err != nil {
+		t.Fatalf("load capture: %v", err)
+	}
+	if c == nil || !strings.Contains(string(c.Body), `"id":7`) {
+		t.Fatalf("imported capture = %+v", c)
+	}
+}
+
+func TestImportReplacingCaptureCollectsBlobs(t *testing.T) {
+	src := newCaptureStore(t, history.CaptureOptions{Enabled: true})
+	if err := src.Append(captureEntry("1", time.Now(), "new")); err != nil {
+		t.Fatalf("append src: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "export.json")
+	if _, err := src.ExportJSON(out); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	dst := newCaptureStore(t, history.CaptureOptions{Enabled: true})
+	if err := dst.Append(captureEntry("1", time.Now(), "old")); err != nil {
+		t.Fatalf("append dst: %v", err)
+	}
+	if _, err := dst.ImportJSON(out); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if n := blobCount(t, dst); n != 1 {
+		t.Fatalf("blobs = %d, want the replaced body collected", n)
+	}
+}
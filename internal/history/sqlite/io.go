@@ -27,6 +27,9 @@ func (s *Store) ExportJSON(path string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	if err := s.fillCaptures(es); err != nil {
+		return 0, err
+	}
 
 	data, err := enc(es)
 	if err != nil {
@@ -77,6 +80,10 @@ func (s *Store) ImportJSON(path string) (int, error) {
 		}
 		n++
 	}
+	// A replaced row may have been the last to reference its blobs.
+	if err := gcBlobs(tx); err != nil {
+		return 0, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return 0, diag.WrapAs(diag.ClassHistory, err, "commit history import tx")
@@ -21,6 +21,12 @@ func (s *Store) Stats() (history.Stats, error) {
 	}
 	st.Oldest = nsToTime(minNS)
 	st.Newest = nsToTime(maxNS)
+	if err := s.db.QueryRow(
+		`SELECT (SELECT COUNT(*) FROM hist WHERE cap_json IS NOT NULL),
+			COUNT(*), COALESCE(SUM(size), 0) FROM blob`,
+	).Scan(&st.Captures, &st.Blobs, &st.BlobBytes); err != nil {
+		return history.Stats{}, diag.WrapAs(diag.ClassHistory, err, "query history capture stats")
+	}
 
 	v, err := schemaVersion(s.db)
 	if err != nil {
@@ -40,10 +46,15 @@ func (s *Store) Check(full bool) error {
 	return checkDB(s.db, full)
 }
 
+// Compact applies retention, then checkpoints and vacuums so the space it
+// frees goes back to the file system.
 func (s *Store) Compact() error {
 	if err := s.ensure(); err != nil {
 		return err
 	}
+	if _, err := s.Prune(false); err != nil {
+		return err
+	}
 	if _, err := s.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE);`); err != nil {
 		return diag.WrapAs(diag.ClassHistory, err, "checkpoint history db")
 	}
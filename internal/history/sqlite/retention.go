@@ -0,0 +1,301 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+// pruneEvery spaces out the retention passes a long session runs on append.
+const pruneEvery = time.Hour
+
+const pruneChunk = 500
+
+// qPruneRows lists what retention needs per entry, newest first. Workflow
+// and compare rows summarize several requests and never count as failures.
+// size approximates the row's stored bytes; captured bodies are counted
+// separately through their blobs.
+const qPruneRows = `SELECT rowid, exec_ns, COALESCE(file_norm, ''), COALESCE(req_name, ''),
+	COALESCE(method, ''),
+	CASE
+		WHEN method IN (?, ?) THEN 0
+		WHEN UPPER(method) = 'GRPC' THEN status_code != 0
+		ELSE status_code >= 400
+	END,
+	64 + COALESCE(LENGTH(CAST(url AS BLOB)), 0) + COALESCE(LENGTH(CAST(snippet AS BLOB)), 0) +
+		COALESCE(LENGTH(CAST(req_text AS BLOB)), 0) + COALESCE(LENGTH(CAST(descr AS BLOB)), 0) +
+		COALESCE(LENGTH(tags_json), 0) + COALESCE(LENGTH(prof_json), 0) +
+		COALESCE(LENGTH(trace_json), 0) + COALESCE(LENGTH(cmp_json), 0) +
+		COALESCE(LENGTH(cap_json), 0) + COALESCE(LENGTH(env_sel_json), 0) +
+		COALESCE(LENGTH(xpl_json), 0),
+	body_ref, tx_ref
+FROM hist ORDER BY exec_ns DESC, id_num DESC, id DESC`
+
+type pruneRow struct {
+	rowid  int64
+	exec   int64
+	file   string
+	name   string
+	method string
+	failed bool
+	size   int64
+	refs   [2]sql.NullString
+}
+
+type pruneKey struct {
+	file   string
+	name   string
+	method string
+}
+
+// SetRetention sets the policy the store enforces when it opens, on
+// Compact, and periodically on Append.
+func (s *Store) SetRetention(r history.Retention) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ret = r
+}
+
+// Prune applies the retention policy now. A dry run reports what would go
+// and changes nothing.
+func (s *Store) Prune(dryRun bool) (history.PruneReport, error) {
+	if err := s.ensure(); err != nil {
+		return history.PruneReport{}, err
+	}
+	s.mu.Lock()
+	r := s.ret
+	s.mu.Unlock()
+	return prune(s.db, r, time.Now(), dryRun)
+}
+
+// pruneDue reports whether Append should run a retention pass, and claims
+// it when so.
+func (s *Store) pruneDue(now time.Time) (history.Retention, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ret.Enabled() || now.Sub(s.prunedAt) < pruneEvery {
+		return history.Retention{}, false
+	}
+	s.prunedAt = now
+	return s.ret, true
+}
+
+func prune(
+	db *sql.DB,
+	r history.Retention,
+	now time.Time,
+	dryRun bool,
+) (history.PruneReport, error) {
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return history.PruneReport{}, diag.WrapAs(diag.ClassHistory, err, "begin history prune tx")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rep, err := pruneTx(tx, r, now)
+	if err != nil || dryRun {
+		return rep, err
+	}
+	if err := tx.Commit(); err != nil {
+		return history.PruneReport{}, diag.WrapAs(diag.ClassHistory, err, "commit history prune tx")
+	}
+	return rep, nil
+}
+
+func pruneTx(tx *sql.Tx, r history.Retention, now time.Time) (history.PruneReport, error) {
+	var rep history.PruneReport
+	if !r.Enabled() {
+		return rep, nil
+	}
+	rows, err := pruneRows(tx)
+	if err != nil {
+		return rep, err
+	}
+
+	drop := make([]bool, len(rows))
+	limits := make(map[string]history.RetentionLimits)
+	counts := make(map[pruneKey]int)
+	for i, pr := range rows {
+		lim, ok := limits[pr.file]
+		if !ok {
+			lim = r.LimitsFor(pr.file)
+			limits[pr.file] = lim
+		}
+		age := now.Sub(nsToTime(pr.exec))
+		// Failures under their own age limit are exempt from the count
+		// cap and do not use up a slot for the request's other runs.
+		if pr.failed && lim.FailureMaxAge > 0 {
+			if age > lim.FailureMaxAge {
+				drop[i] = true
+				rep.ByAge++
+			}
+			continue
+		}
+		if lim.MaxAge > 0 && age > lim.MaxAge {
+			drop[i] = true
+			rep.ByAge++
+			continue
+		}
+		if lim.MaxPerRequest > 0 {
+			k := pruneKey{file: pr.file, name: pr.name, method: pr.method}
+			counts[k]++
+			if counts[k] > lim.MaxPerRequest {
+				drop[i] = true
+				rep.ByCount++
+			}
+		}
+	}
+	if r.MaxBytes > 0 {
+		sizes, err := blobSizes(tx)
+		if err != nil {
+			return rep, err
+		}
+		rep.BySize = dropForSize(rows, drop, sizes, r.MaxBytes)
+	}
+
+	var ids []any
+	for i, pr := range rows {
+		if !drop[i] {
+			continue
+		}
+		ids = append(ids, pr.rowid)
+		rep.Bytes += pr.size
+	}
+	rep.Entries = int64(len(ids))
+	rep.Kept = int64(len(rows)) - rep.Entries
+	if len(ids) == 0 {
+		return rep, nil
+	}
+	for start := 0; start < len(ids); start += pruneChunk {
+		part := ids[start:min(start+pruneChunk, len(ids))]
+		q := `DELETE FROM hist WHERE rowid IN (?` + strings.Repeat(`, ?`, len(part)-1) + `)`
+		if _, err := tx.Exec(q, part...); err != nil {
+			return rep, diag.WrapAs(diag.ClassHistory, err, "prune history rows")
+		}
+	}
+
+	n0, b0, err := blobTotals(tx)
+	if err != nil {
+		return rep, err
+	}
+	if err := gcBlobs(tx); err != nil {
+		return rep, err
+	}
+	n1, b1, err := blobTotals(tx)
+	if err != nil {
+		return rep, err
+	}
+	rep.Blobs = n0 - n1
+	rep.Bytes += b0 - b1
+	return rep, nil
+}
+
+func pruneRows(tx *sql.Tx) ([]pruneRow, error) {
+	rs, err := tx.Query(qPruneRows, restfile.HistoryMethodWorkflow, restfile.HistoryMethodCompare)
+	if err != nil {
+		return nil, diag.WrapAs(diag.ClassHistory, err, "query history retention rows")
+	}
+	defer func() { _ = rs.Close() }()
+
+	var out []pruneRow
+	for rs.Next() {
+		var pr pruneRow
+		if err := rs.Scan(
+			&pr.rowid,
+			&pr.exec,
+			&pr.file,
+			&pr.name,
+			&pr.method,
+			&pr.failed,
+			&pr.size,
+			&pr.refs[0],
+			&pr.refs[1],
+		); err != nil {
+			return nil, diag.WrapAs(diag.ClassHistory, err, "scan history retention row")
+		}
+		out = append(out, pr)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, diag.WrapAs(diag.ClassHistory, err, "iterate history retention rows")
+	}
+	return out, nil
+}
+
+// dropForSize marks the oldest kept rows until the kept rows and the blobs
+// they still reference fit in limit. It returns how many it marked.
+func dropForSize(rows []pruneRow, drop []bool, sizes map[string]int64, limit int64) int64 {
+	var total int64
+	refs := make(map[string]int)
+	for i, pr := range rows {
+		if drop[i] {
+			continue
+		}
+		total += pr.size
+		for _, ref := range pr.refs {
+			if !ref.Valid {
+				continue
+			}
+			if refs[ref.String] == 0 {
+				total += sizes[ref.String]
+			}
+			refs[ref.String]++
+		}
+	}
+
+	var n int64
+	for i := len(rows) - 1; i >= 0 && total > limit; i-- {
+		if drop[i] {
+			continue
+		}
+		drop[i] = true
+		n++
+		total -= rows[i].size
+		for _, ref := range rows[i].refs {
+			if !ref.Valid {
+				continue
+			}
+			refs[ref.String]--
+			if refs[ref.String] == 0 {
+				total -= sizes[ref.String]
+			}
+		}
+	}
+	return n
+}
+
+func blobSizes(tx *sql.Tx) (map[string]int64, error) {
+	rs, err := tx.Query(`SELECT hash, size FROM blob`)
+	if err != nil {
+		return nil, diag.WrapAs(diag.ClassHistory, err, "query history blob sizes")
+	}
+	defer func() { _ = rs.Close() }()
+
+	out := make(map[string]int64)
+	for rs.Next() {
+		var (
+			h string
+			n int64
+		)
+		if err := rs.Scan(&h, &n); err != nil {
+			return nil, diag.WrapAs(diag.ClassHistory, err, "scan history blob size")
+		}
+		out[h] = n
+	}
+	if err := rs.Err(); err != nil {
+		return nil, diag.WrapAs(diag.ClassHistory, err, "iterate history blob sizes")
+	}
+	return out, nil
+}
+
+func blobTotals(tx *sql.Tx) (int64, int64, error) {
+	var n, size int64
+	if err := tx.QueryRow(`SELECT COUNT(*), COALESCE(SUM(size), 0) FROM blob`).Scan(&n, &size); err != nil {
+		return 0, 0, diag.WrapAs(diag.ClassHistory, err, "query history blob totals")
+	}
+	return n, size, nil
+}
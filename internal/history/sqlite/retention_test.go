@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/history"
+)
+
+func entryIDs(t *testing.T, s *Store) map[string]bool {
+	t.Helper()
+	es, err := s.Entries()
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	out := make(map[string]bool, len(es))
+	for _, e := range es {
+		out[e.ID] = true
+	}
+	return out
+}
+
+func TestPruneByAgeKeepsFailuresLonger(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{})
+	now := time.Now()
+	day := 24 * time.Hour
+	for _, e := range []history.Entry{
+		{ID: "new", ExecutedAt: now.Add(-day), Method: "GET", StatusCode: 200},
+		{ID: "old", ExecutedAt: now.Add(-10 * day), Method: "GET", StatusCode: 200},
+		{ID: "old-fail", ExecutedAt: now.Add(-10 * day), Method: "GET", StatusCode: 500},
+		{ID: "old-grpc-fail", ExecutedAt: now.Add(-10 * day), Method: "GRPC", StatusCode: 14},
+		{ID: "ancient-fail", ExecutedAt: now.Add(-40 * day), Method: "GET", StatusCode: 502},
+	} {
+		if err := s.Append(e); err != nil {
+			t.Fatalf("append %s: %v", e.ID, err)
+		}
+	}
+	s.SetRetention(history.Retention{RetentionLimits: history.RetentionLimits{
+		MaxAge:        7 * day,
+		FailureMaxAge: 30 * day,
+	}})
+
+	rep, err := s.Prune(true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if rep.Entries != 2 || rep.ByAge != 2 || rep.Kept != 3 || rep.Bytes <= 0 {
+		t.Fatalf("dry run report = %+v", rep)
+	}
+	if ids := entryIDs(t, s); len(ids) != 5 {
+		t.Fatalf("dry run removed entries: %v", ids)
+	}
+
+	if _, err := s.Prune(false); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	ids := entryIDs(t, s)
+	if len(ids) != 3 || !ids["new"] || !ids["old-fail"] || !ids["old-grpc-fail"] {
+		t.Fatalf("kept = %v", ids)
+	}
+}
+
+func TestPruneMaxPerRequestWithWorkspaceOverride(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{})
+	root := filepath.FromSlash("/work/payments")
+	now := time.Now()
+	for i := range 5 {
+		at := now.Add(time.Duration(i) * time.Minute)
+		for _, e := range []history.Entry{
+			{FilePath: filepath.FromSlash("/work/api/users.http"), RequestName: "GetUser"},
+			{FilePath: filepath.Join(root, "charges.http"), RequestName: "Charge"},
+		} {
+			e.ID = e.RequestName + strconv.Itoa(i)
+			e.ExecutedAt = at
+			e.Method = "GET"
+			e.StatusCode = 200
+			if err := s.Append(e); err != nil {
+				t.Fatalf("append %s: %v", e.ID, err)
+			}
+		}
+	}
+	s.SetRetention(history.Retention{
+		RetentionLimits: history.RetentionLimits{MaxPerRequest: 2},
+		Workspaces: []history.WorkspaceRetention{
+			{Root: root, RetentionLimits: history.RetentionLimits{MaxPerRequest: 4}},
+		},
+	})
+
+	rep, err := s.Prune(false)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if rep.ByCount != 4 {
+		t.Fatalf("report = %+v", rep)
+	}
+	ids := entryIDs(t, s)
+	if !ids["GetUser4"] || !ids["GetUser3"] || ids["GetUser2"] || !ids["Charge1"] || ids["Charge0"] {
+		t.Fatalf("kept = %v", ids)
+	}
+}
+
+func TestPruneMaxBytesDropsOldestWithCaptures(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{Enabled: true})
+	now := time.Now()
+	for i := range 3 {
+		body := strings.Repeat(strconv.Itoa(i), 10<<10)
+		if err := s.Append(captureEntry(strconv.Itoa(i), now.Add(time.Duration(i)*time.Minute), body)); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	s.SetRetention(history.Retention{MaxBytes: 45 << 10})
+
+	rep, err := s.Prune(false)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if rep.BySize != 1 || rep.Blobs != 1 || rep.Bytes < 20<<10 {
+		t.Fatalf("report = %+v", rep)
+	}
+	ids := entryIDs(t, s)
+	if len(ids) != 2 || ids["0"] {
+		t.Fatalf("kept = %v", ids)
+	}
+	if n := blobCount(t, s); n != 2 {
+		t.Fatalf("blobs = %d, want 2", n)
+	}
+}
+
+func TestRetentionRunsOnOpenAndCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	s := New(path)
+	now := time.Now()
+	for i, age := range []time.Duration{time.Hour, 48 * time.Hour} {
+		if err := s.Append(history.Entry{ID: strconv.Itoa(i), ExecutedAt: now.Add(-age)}); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	s = New(path)
+	t.Cleanup(func() { _ = s.Close() })
+	s.SetRetention(history.Retention{RetentionLimits: history.RetentionLimits{MaxAge: 24 * time.Hour}})
+	if err := s.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if ids := entryIDs(t, s); len(ids) != 1 || !ids["0"] {
+		t.Fatalf("kept after open = %v", ids)
+	}
+
+	s.SetRetention(history.Retention{RetentionLimits: history.RetentionLimits{MaxAge: time.Minute}})
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if ids := entryIDs(t, s); len(ids) != 0 {
+		t.Fatalf("kept after compact = %v", ids)
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 const (
-	schemaVer = 3
+	schemaVer = 6
 )
 
 type mig struct {
@@ -64,6 +64,9 @@ var pragmas = []string{
 	`PRAGMA synchronous=FULL;`,
 	`PRAGMA foreign_keys=ON;`,
 	`PRAGMA temp_store=MEMORY;`,
+	// INSERT OR REPLACE must fire the delete trigger that keeps hist_fts
+	// in step with hist.
+	`PRAGMA recursive_triggers=ON;`,
 }
 
 var migs = []mig{
@@ -114,6 +117,59 @@ var migs = []mig{
 			`ALTER TABLE hist ADD COLUMN env_sel_json BLOB;`,
 		},
 	},
+	{
+		// Full capture keeps response bodies and stream transcripts in blob,
+		// keyed by content hash so repeated responses are stored once.
+		ver: 4,
+		qs: []string{
+			`CREATE TABLE IF NOT EXISTS blob (
+				hash TEXT PRIMARY KEY,
+				size INTEGER NOT NULL,
+				data BLOB NOT NULL
+			);`,
+			`ALTER TABLE hist ADD COLUMN cap_json BLOB;`,
+			`ALTER TABLE hist ADD COLUMN body_ref TEXT;`,
+			`ALTER TABLE hist ADD COLUMN tx_ref TEXT;`,
+			`CREATE INDEX IF NOT EXISTS idx_hist_body_ref ON hist(body_ref);`,
+			`CREATE INDEX IF NOT EXISTS idx_hist_tx_ref ON hist(tx_ref);`,
+		},
+	},
+	{
+		// hist_fts indexes the snippet and captured body of each row for
+		// body: searches. Triggers keep it in step, including when
+		// retention drops a capture, and rowids follow hist.
+		ver: 5,
+		qs: []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS hist_fts USING fts5(body);`,
+			`CREATE TRIGGER IF NOT EXISTS hist_fts_ins AFTER INSERT ON hist BEGIN
+				DELETE FROM hist_fts WHERE rowid = new.rowid;
+				INSERT INTO hist_fts(rowid, body) VALUES (new.rowid, ` + ftsBody("new") + `);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS hist_fts_del AFTER DELETE ON hist BEGIN
+				DELETE FROM hist_fts WHERE rowid = old.rowid;
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS hist_fts_upd AFTER UPDATE OF snippet, body_ref ON hist BEGIN
+				DELETE FROM hist_fts WHERE rowid = old.rowid;
+				INSERT INTO hist_fts(rowid, body) VALUES (new.rowid, ` + ftsBody("new") + `);
+			END;`,
+			`INSERT INTO hist_fts(rowid, body) SELECT rowid, ` + ftsBody("hist") + ` FROM hist;`,
+		},
+	},
+	{
+		// xpl_json keeps the redacted explain report so a shared entry can
+		// show how the request was prepared.
+		ver: 6,
+		qs: []string{
+			`ALTER TABLE hist ADD COLUMN xpl_json BLOB;`,
+		},
+	},
+}
+
+// ftsBody is the text indexed for a hist row: its snippet followed by the
+// captured body, if any.
+func ftsBody(r string) string {
+	return `COALESCE(` + r + `.snippet, '') || ' ' || COALESCE(
+		(SELECT CAST(data AS TEXT) FROM blob WHERE hash = ` + r + `.body_ref), '')`
 }
 
 func applyPragmas(db *sql.DB) error {
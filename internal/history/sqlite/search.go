@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/unkn0wn-root/resterm/internal/history"
+)
+
+var _ history.SearchStore = (*Store)(nil)
+
+const (
+	searchTextExpr = `LOWER(COALESCE(req_name, '') || ' ' || COALESCE(url, '') || ' ' ||
+		COALESCE(descr, '') || ' ' || COALESCE(CAST(tags_json AS TEXT), '') || ' ' ||
+		COALESCE(env, '') || ' ' || COALESCE(CAST(cmp_json AS TEXT), ''))`
+
+	tagsExpr = `CASE WHEN json_valid(CAST(hist.tags_json AS TEXT))
+		THEN CAST(hist.tags_json AS TEXT) ELSE '[]' END`
+)
+
+// Search runs q in SQL, newest first. Body terms use the full-text index,
+// so they match words in captured bodies as well as snippets.
+func (s *Store) Search(q history.Query, limit int) ([]history.Entry, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	for _, t := range q.Terms {
+		c, a := termSQL(t)
+		if t.Neg {
+			c = "NOT (" + c + ")"
+		}
+		conds = append(conds, c)
+		args = append(args, a...)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	return s.rowsN(where, args, limit)
+}
+
+func termSQL(t history.QueryTerm) (string, []any) {
+	switch t.Field {
+	case history.FieldStatus:
+		return `status_code BETWEEN ? AND ?`, []any{t.Min, t.Max}
+	case history.FieldDur:
+		return `dur_ns BETWEEN ? AND ?`, []any{t.Min, t.Max}
+	case history.FieldAfter, history.FieldBefore, history.FieldDate:
+		return rangesSQL(t.Ranges)
+	case history.FieldEnv:
+		return `LOWER(TRIM(COALESCE(env, ''))) = ?`, []any{t.Value}
+	case history.FieldMethod:
+		return `INSTR(UPPER(TRIM(COALESCE(method, ''))), ?) = 1`, []any{t.Value}
+	case history.FieldTag:
+		return `EXISTS (SELECT 1 FROM json_each(` + tagsExpr + `)
+			WHERE LOWER(TRIM(json_each.value)) = ?)`, []any{t.Value}
+	case history.FieldBody:
+		if m := ftsPhrase(t.Value); m != "" {
+			return `hist.rowid IN (SELECT rowid FROM hist_fts WHERE hist_fts MATCH ?)`, []any{m}
+		}
+		return `INSTR(LOWER(COALESCE(snippet, '')), ?) > 0`, []any{t.Value}
+	case history.FieldName:
+		return `INSTR(LOWER(COALESCE(req_name, '')), ?) > 0`, []any{t.Value}
+	case history.FieldURL:
+		return `INSTR(LOWER(COALESCE(url, '')), ?) > 0`, []any{t.Value}
+	default:
+		return `INSTR(` + searchTextExpr + `, ?) > 0`, []any{t.Value}
+	}
+}
+
+func rangesSQL(rs []history.TimeRange) (string, []any) {
+	var (
+		ors  []string
+		args []any
+	)
+	for _, r := range rs {
+		var and []string
+		if !r.Start.IsZero() {
+			and = append(and, `exec_ns >= ?`)
+			args = append(args, r.Start.UnixNano())
+		}
+		if !r.End.IsZero() {
+			and = append(and, `exec_ns < ?`)
+			args = append(args, r.End.UnixNano())
+		}
+		if len(and) == 0 {
+			and = append(and, `1 = 1`)
+		}
+		ors = append(ors, "("+strings.Join(and, " AND ")+")")
+	}
+	if len(ors) == 0 {
+		return `1 = 0`, nil
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// ftsPhrase quotes v as an FTS5 phrase whose last word may be a prefix, or
+// returns "" when v has no word characters to index.
+func ftsPhrase(v string) string {
+	if strings.IndexFunc(v, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) < 0 {
+		return ""
+	}
+	return `"` + strings.ReplaceAll(v, `"`, `""`) + `"*`
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/history"
+)
+
+func searchIDs(t *testing.T, s *Store, query string) []string {
+	t.Helper()
+	q, err := history.ParseQuery(query, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("parse %q: %v", query, err)
+	}
+	es, err := s.Search(q, 0)
+	if err != nil {
+		t.Fatalf("search %q: %v", query, err)
+	}
+	ids := make([]string, 0, len(es))
+	for _, e := range es {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestSearchMatchesQueryTerms(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{Enabled: true, Retention: 30 * 24 * time.Hour})
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 9, 0, 0, 0, time.UTC) }
+	es := []history.Entry{
+		{
+			ID:          "1",
+			ExecutedAt:  day(2),
+			Environment: "prod",
+			Method:      "POST",
+			URL:         "https://api.example.com/charges",
+			RequestName: "Charge",
+			StatusCode:  502,
+			Duration:    900 * time.Millisecond,
+			Tags:        []string{"smoke"},
+			BodySnippet: `{"error":"upstream"}`,
+			Capture: &history.Capture{
+				Body: []byte(`{"error":"upstream","detail":"insufficient funds on card"}`),
+			},
+		},
+		{
+			ID:          "2",
+			ExecutedAt:  day(3),
+			Environment: "dev",
+			Method:      "GET",
+			URL:         "https://api.example.com/users",
+			RequestName: "Users",
+			StatusCode:  200,
+			Duration:    100 * time.Millisecond,
+			BodySnippet: `[]`,
+		},
+		{
+			ID:          "3",
+			ExecutedAt:  day(5),
+			Environment: "prod",
+			Method:      "POST",
+			URL:         "https://api.example.com/refunds",
+			RequestName: "Refund",
+			StatusCode:  503,
+			Duration:    300 * time.Millisecond,
+			BodySnippet: `{"error":"insufficient balance"}`,
+		},
+	}
+	for _, e := range es {
+		if err := s.Append(e); err != nil {
+			t.Fatalf("append %s: %v", e.ID, err)
+		}
+	}
+
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{`status:5xx env:prod`, []string{"3", "1"}},
+		{`status:5xx env:prod method:POST dur:>800ms tag:smoke`, []string{"1"}},
+		{`body:"insufficient funds"`, []string{"1"}},
+		{`body:insuff`, []string{"3", "1"}},
+		{`after:2026-10-03 before:2026-10-05`, []string{"2"}},
+		{`-env:prod`, []string{"2"}},
+		{`refund`, []string{"3"}},
+		{`url:users name:user`, []string{"2"}},
+	}
+	for _, tt := range tests {
+		if got := searchIDs(t, s, tt.q); !slices.Equal(got, tt.want) {
+			t.Fatalf("%s: got %v, want %v", tt.q, got, tt.want)
+		}
+	}
+
+	if _, err := s.Delete("1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := searchIDs(t, s, `body:funds`); len(got) != 0 {
+		t.Fatalf("deleted entry still indexed: %v", got)
+	}
+}
+
+func TestSearchDropsBodyIndexWithCapture(t *testing.T) {
+	s := newCaptureStore(t, history.CaptureOptions{Enabled: true, Retention: time.Hour})
+	old := history.Entry{
+		ID:          "1",
+		ExecutedAt:  time.Now().Add(-2 * time.Hour),
+		BodySnippet: "short",
+		Capture:     &history.Capture{Body: []byte("short and a secret tail")},
+	}
+	if err := s.Append(old); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	// The entry was already past retention, so only its snippet stays searchable.
+	if got := searchIDs(t, s, `body:tail`); len(got) != 0 {
+		t.Fatalf("pruned body still indexed: %v", got)
+	}
+	if got := searchIDs(t, s, `body:short`); !slices.Equal(got, []string{"1"}) {
+		t.Fatalf("snippet search = %v", got)
+	}
+}
+
+func TestSearchIndexesRowsFromBeforeMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	s := New(path)
+	if err := s.Append(history.Entry{ID: "1", ExecutedAt: time.Now(), BodySnippet: "legacy body"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM hist_fts`); err != nil {
+		t.Fatalf("clear index: %v", err)
+	}
+	if _, err := s.db.Exec(`PRAGMA user_version = 4`); err != nil {
+		t.Fatalf("downgrade: %v", err)
+	}
+	for _, q := range []string{
+		`DROP TRIGGER hist_fts_ins`,
+		`DROP TRIGGER hist_fts_del`,
+		`DROP TRIGGER hist_fts_upd`,
+		`DROP TABLE hist_fts`,
+		`ALTER TABLE hist DROP COLUMN xpl_json`,
+	} {
+		if _, err := s.db.Exec(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	s = New(path)
+	t.Cleanup(func() { _ = s.Close() })
+	if got := searchIDs(t, s, `body:legacy`); !slices.Equal(got, []string{"1"}) {
+		t.Fatalf("migrated search = %v", got)
+	}
+}
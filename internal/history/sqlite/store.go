@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	sqlite3 "modernc.org/sqlite/lib"
 
 	"github.com/unkn0wn-root/resterm/internal/diag"
+	xplain "github.com/unkn0wn-root/resterm/internal/explain"
 	"github.com/unkn0wn-root/resterm/internal/history"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 )
@@ -23,8 +25,9 @@ const (
 	drv = "sqlite"
 
 	histCols = `(id, id_num, exec_ns, env, env_sel_json, req_name, file_path, file_norm, method, url, status,
-		status_code, dur_ns, snippet, req_text, descr, tags_json, prof_json, trace_json, cmp_json)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		status_code, dur_ns, snippet, req_text, descr, tags_json, prof_json, trace_json, cmp_json,
+		cap_json, body_ref, tx_ref, xpl_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	// Regular writes replace by ID so reruns can refresh the same row,
 	// while legacy migration keeps the first copy and skips duplicates.
@@ -38,6 +41,11 @@ type Store struct {
 	mu  sync.Mutex
 	db  *sql.DB
 	rec *RecoverInfo
+	cap history.CaptureOptions
+	ret history.Retention
+	// prunedAt is when retention last ran, so Append only repeats it
+	// every pruneEvery.
+	prunedAt time.Time
 }
 
 type RecoverInfo struct {
@@ -87,14 +95,38 @@ func (s *Store) Append(e history.Entry) error {
 		return err
 	}
 
+	opt := s.captureOptions()
+	if opt.Enabled {
+		e.Capture = limitCapture(e.Capture, opt.Limit())
+	} else {
+		e.Capture = nil
+	}
 	r, err := mkRow(e)
 	if err != nil {
 		return err
 	}
 
-	if _, err = insertRow(s.db, qReplace, &r); err != nil {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return diag.WrapAs(diag.ClassHistory, err, "begin history append tx")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = insertRow(tx, qReplace, &r); err != nil {
 		return diag.WrapAs(diag.ClassHistory, err, "insert history row")
 	}
+	now := time.Now()
+	if err := pruneCaptures(tx, opt.Retention, now); err != nil {
+		return err
+	}
+	if ret, ok := s.pruneDue(now); ok {
+		if _, err := pruneTx(tx, ret, now); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return diag.WrapAs(diag.ClassHistory, err, "commit history append tx")
+	}
 	return nil
 }
 
@@ -153,17 +185,28 @@ func (s *Store) Delete(id string) (bool, error) {
 	if err != nil {
 		return false, diag.WrapAs(diag.ClassHistory, err, "history rows affected")
 	}
+	if n > 0 {
+		if err := gcBlobs(s.db); err != nil {
+			return true, err
+		}
+	}
 	return n > 0, nil
 }
 
 func (s *Store) rows(where string, args []any) ([]history.Entry, error) {
+	return s.rowsN(where, args, 0)
+}
+
+// rowsN is rows capped at limit entries when limit is positive.
+func (s *Store) rowsN(where string, args []any, limit int) ([]history.Entry, error) {
 	if err := s.ensure(); err != nil {
 		return nil, err
 	}
 
 	q := `SELECT
 		id, id_num, exec_ns, env, env_sel_json, req_name, file_path, method, url, status, status_code, dur_ns,
-		snippet, req_text, descr, tags_json, prof_json, trace_json, cmp_json
+		snippet, req_text, descr, tags_json, prof_json, trace_json, cmp_json, cap_json,
+		xpl_json
 	FROM hist`
 	if strings.TrimSpace(where) != "" {
 		q += " " + where
@@ -171,6 +214,9 @@ func (s *Store) rows(where string, args []any) ([]history.Entry, error) {
 	// This ordering is shared across list and migration paths so
 	// every caller sees the same history precedence for tied timestamps.
 	q += ` ORDER BY exec_ns DESC, id_num DESC, id DESC`
+	if limit > 0 {
+		q += ` LIMIT ` + strconv.Itoa(limit)
+	}
 
 	rs, err := s.db.Query(q, args...)
 	if err != nil {
@@ -196,7 +242,7 @@ func scanRow(rs *sql.Rows) (history.Entry, error) {
 	var (
 		id, env, reqName, filePath, method, url, status, snippet, reqText, descr string
 		idNum, execNs, statusCode, durNs                                         int64
-		envSelJSON, tagsJSON, profJSON, traceJSON, cmpJSON                       []byte
+		envSelJSON, tagsJSON, profJSON, traceJSON, cmpJSON, capJSON, xplJSON     []byte
 	)
 	err := rs.Scan(
 		&id,
@@ -218,6 +264,8 @@ func scanRow(rs *sql.Rows) (history.Entry, error) {
 		&profJSON,
 		&traceJSON,
 		&cmpJSON,
+		&capJSON,
+		&xplJSON,
 	)
 	if err != nil {
 		return history.Entry{}, diag.WrapAs(diag.ClassHistory, err, "scan history row")
@@ -274,6 +322,20 @@ func scanRow(rs *sql.Rows) (history.Entry, error) {
 		}
 		e.Compare = &c
 	}
+	if len(capJSON) > 0 {
+		c, err := dec[history.Capture](capJSON)
+		if err != nil {
+			return history.Entry{}, diag.WrapAs(diag.ClassHistory, err, "decode history capture")
+		}
+		e.Capture = &c
+	}
+	if len(xplJSON) > 0 {
+		x, err := dec[xplain.Report](xplJSON)
+		if err != nil {
+			return history.Entry{}, diag.WrapAs(diag.ClassHistory, err, "decode history explain")
+		}
+		e.Explain = &x
+	}
 
 	return e, nil
 }
@@ -328,6 +390,15 @@ func mkRow(e history.Entry) (row, error) {
 			return row{}, diag.WrapAs(diag.ClassHistory, err, "encode history compare")
 		}
 	}
+	if e.Explain != nil {
+		r.xplJSON, err = enc(e.Explain)
+		if err != nil {
+			return row{}, diag.WrapAs(diag.ClassHistory, err, "encode history explain")
+		}
+	}
+	if err := capRow(e.Capture, &r); err != nil {
+		return row{}, err
+	}
 
 	return r, nil
 }
@@ -353,6 +424,11 @@ type row struct {
 	profJSON   []byte
 	traceJSON  []byte
 	cmpJSON    []byte
+	capJSON    []byte
+	bodyRef    sql.NullString
+	txRef      sql.NullString
+	xplJSON    []byte
+	blobs      []blobRow
 }
 
 func (r *row) args() []any {
@@ -360,6 +436,7 @@ func (r *row) args() []any {
 		r.id, r.idNum, r.execNs, r.env, r.envSelJSON, r.reqName, r.filePath, r.fileNorm,
 		r.method, r.url, r.status, r.statusCode, r.durNs, r.snippet,
 		r.reqText, r.descr, r.tagsJSON, r.profJSON, r.traceJSON, r.cmpJSON,
+		r.capJSON, r.bodyRef, r.txRef, r.xplJSON,
 	}
 }
 
@@ -368,6 +445,9 @@ type execer interface {
 }
 
 func insertRow(db execer, q string, r *row) (sql.Result, error) {
+	if err := insertBlobs(db, r); err != nil {
+		return nil, err
+	}
 	return db.Exec(q, r.args()...)
 }
 
@@ -415,6 +495,15 @@ func (s *Store) ensure() error {
 
 	s.db = db
 	s.rec = rec
+	// Retention catches up on open, which is when a long break has let
+	// the most history pile up. The store stays usable if it fails; the
+	// next Append finds the pass still due and tries again.
+	if s.ret.Enabled() {
+		now := time.Now()
+		if _, err := prune(db, s.ret, now, false); err == nil {
+			s.prunedAt = now
+		}
+	}
 	return nil
 }
 
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	xplain "github.com/unkn0wn-root/resterm/internal/explain"
 	"github.com/unkn0wn-root/resterm/internal/history"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 )
@@ -128,6 +129,32 @@ func TestEnvironmentSelectionRoundTrip(t *testing.T) {
 	}
 }
 
+func TestExplainRoundTrip(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "history.db"))
+	t.Cleanup(func() { _ = s.Close() })
+	entry := history.Entry{
+		ID:         "1",
+		ExecutedAt: time.Unix(10, 0),
+		Explain: &xplain.Report{
+			Name:     "GetUser",
+			Status:   xplain.StatusReady,
+			Decision: "HTTP request sent",
+			Stages:   []xplain.Stage{{Name: "auth", Status: xplain.StageOK, Summary: "prepared"}},
+			Final:    &xplain.Final{Method: "GET", URL: "https://api.example.com/users/1"},
+		},
+	}
+	if err := s.Append(entry); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	got, err := s.Entries()
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Explain, entry.Explain) {
+		t.Fatalf("explain = %#v, want %#v", got, entry.Explain)
+	}
+}
+
 func TestByRequestSkipsWorkflowRows(t *testing.T) {
 	dir := t.TempDir()
 	p := filepath.Join(dir, "history.db")
@@ -25,6 +25,8 @@ type MaintenanceStore interface {
 	Stats() (Stats, error)
 	Check(full bool) error
 	Compact() error
+	SetRetention(Retention)
+	Prune(dryRun bool) (PruneReport, error)
 	Backup(path string) error
 	ExportJSON(path string) (int, error)
 	ImportJSON(path string) (int, error)
@@ -40,6 +42,11 @@ type Stats struct {
 	DBBytes  int64
 	WALBytes int64
 	SHMBytes int64
+	// Captures counts entries holding a full response; Blobs and BlobBytes
+	// describe the deduplicated bodies they share.
+	Captures  int64
+	Blobs     int64
+	BlobBytes int64
 }
 
 func NormalizeWorkflowName(name string) string {
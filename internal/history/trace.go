@@ -29,6 +29,8 @@ type TracePhaseMeta struct {
 	Addr   string `json:"addr,omitempty"`
 	Reused bool   `json:"reused,omitempty"`
 	Cached bool   `json:"cached,omitempty"`
+	// Override is set when a resolve rule answered the DNS phase.
+	Override bool `json:"override,omitempty"`
 }
 
 type TraceDetails struct {
@@ -108,9 +110,10 @@ func NewTraceSummary(tl *nettrace.Timeline, rep *nettrace.Report) *TraceSummary
 			Duration: phase.Duration,
 			Error:    phase.Err,
 			Meta: TracePhaseMeta{
-				Addr:   phase.Meta.Addr,
-				Reused: phase.Meta.Reused,
-				Cached: phase.Meta.Cached,
+				Addr:     phase.Meta.Addr,
+				Reused:   phase.Meta.Reused,
+				Cached:   phase.Meta.Cached,
+				Override: phase.Meta.Override,
 			},
 		}
 	}
@@ -183,9 +186,10 @@ func (s *TraceSummary) Timeline() *nettrace.Timeline {
 			Duration: dur,
 			Err:      phase.Error,
 			Meta: nettrace.PhaseMeta{
-				Addr:   phase.Meta.Addr,
-				Reused: phase.Meta.Reused,
-				Cached: phase.Meta.Cached,
+				Addr:     phase.Meta.Addr,
+				Reused:   phase.Meta.Reused,
+				Cached:   phase.Meta.Cached,
+				Override: phase.Meta.Override,
 			},
 		}
 		if !anchor.IsZero() {
@@ -0,0 +1,78 @@
+package history
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/analysis"
+	"github.com/unkn0wn-root/resterm/internal/nettrace"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+// TrendEntries returns the recorded runs of a request, optionally limited to
+// one environment, in the order the store keeps them.
+func TrendEntries(s Store, request, env string) ([]Entry, error) {
+	es, err := s.ByRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	env = strings.TrimSpace(env)
+	if env == "" {
+		return es, nil
+	}
+	out := es[:0]
+	for _, e := range es {
+		if strings.EqualFold(e.Environment, env) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// TrendSamples turns single-request runs into trend samples, oldest first.
+// Workflow and compare rows aggregate several requests and are skipped, as
+// are runs without a duration. Profile runs contribute their median so one
+// profile counts as one sample.
+func TrendSamples(es []Entry) []analysis.TrendSample {
+	out := make([]analysis.TrendSample, 0, len(es))
+	for _, e := range es {
+		if e.Method == restfile.HistoryMethodWorkflow ||
+			e.Method == restfile.HistoryMethodCompare || e.Compare != nil {
+			continue
+		}
+		total := e.Duration
+		if p := e.ProfileResults; p != nil && p.Latency != nil && p.Latency.Median > 0 {
+			total = p.Latency.Median
+		}
+		if total <= 0 {
+			continue
+		}
+		s := analysis.TrendSample{At: e.ExecutedAt, Total: total}
+		if e.Trace != nil {
+			for _, ph := range e.Trace.Phases {
+				if ph.Kind == "" || ph.Kind == string(nettrace.PhaseTotal) {
+					continue
+				}
+				s.Phases = append(s.Phases, analysis.TrendPhase{Name: ph.Kind, Duration: ph.Duration})
+			}
+		}
+		out = append(out, s)
+	}
+	slices.SortStableFunc(out, func(a, b analysis.TrendSample) int {
+		return a.At.Compare(b.At)
+	})
+	return out
+}
+
+// TrendSince drops samples older than d before now. A zero d keeps all.
+func TrendSince(samples []analysis.TrendSample, d time.Duration, now time.Time) []analysis.TrendSample {
+	if d <= 0 {
+		return samples
+	}
+	cut := now.Add(-d)
+	i, _ := slices.BinarySearchFunc(samples, cut, func(s analysis.TrendSample, t time.Time) int {
+		return s.At.Compare(t)
+	})
+	return samples[i:]
+}
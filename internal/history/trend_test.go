@@ -0,0 +1,45 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func TestTrendSamples(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	es := []Entry{
+		{
+			ExecutedAt: t0.Add(2 * time.Hour),
+			Duration:   120 * time.Millisecond,
+			Trace: &TraceSummary{Phases: []TracePhase{
+				{Kind: "dns", Duration: 5 * time.Millisecond},
+				{Kind: "total", Duration: 120 * time.Millisecond},
+			}},
+		},
+		{ExecutedAt: t0.Add(3 * time.Hour), Method: restfile.HistoryMethodWorkflow, Duration: time.Second},
+		{ExecutedAt: t0.Add(4 * time.Hour), Method: restfile.HistoryMethodCompare, Duration: time.Second},
+		{ExecutedAt: t0.Add(5 * time.Hour)},
+		{
+			ExecutedAt:     t0,
+			Duration:       3 * time.Second,
+			ProfileResults: &ProfileResults{Latency: &ProfileLatency{Median: 90 * time.Millisecond}},
+		},
+	}
+	got := TrendSamples(es)
+	if len(got) != 2 {
+		t.Fatalf("samples = %+v", got)
+	}
+	if !got[0].At.Equal(t0) || got[0].Total != 90*time.Millisecond {
+		t.Fatalf("profile sample = %+v", got[0])
+	}
+	if got[1].Total != 120*time.Millisecond || len(got[1].Phases) != 1 || got[1].Phases[0].Name != "dns" {
+		t.Fatalf("traced sample = %+v", got[1])
+	}
+
+	recent := TrendSince(got, time.Hour, t0.Add(150*time.Minute))
+	if len(recent) != 1 || recent[0].Total != 120*time.Millisecond {
+		t.Fatalf("since = %+v", recent)
+	}
+}
@@ -3,6 +3,8 @@ package history
 import (
 	"strings"
 	"time"
+
+	xplain "github.com/unkn0wn-root/resterm/internal/explain"
 )
 
 type Entry struct {
@@ -24,6 +26,8 @@ type Entry struct {
 	ProfileResults       *ProfileResults      `json:"profileResults,omitempty"`
 	Trace                *TraceSummary        `json:"trace,omitempty"`
 	Compare              *CompareEntry        `json:"compare,omitempty"`
+	Capture              *Capture             `json:"capture,omitempty"`
+	Explain              *xplain.Report       `json:"explain,omitempty"`
 }
 
 type EnvironmentSelection map[string]string
@@ -36,7 +40,8 @@ type CompareEntry struct {
 
 // BaselineResult resolves Baseline to its result row. Grouped compares record
 // the baseline as a profile name while rows carry the full selection label, so
-// match either. A blank or unknown baseline falls back to the first row.
+// match either. @as runs record the baseline identity. A blank or unknown
+// baseline falls back to the first row.
 func (c *CompareEntry) BaselineResult() *CompareResult {
 	if c == nil || len(c.Results) == 0 {
 		return nil
@@ -44,7 +49,9 @@ func (c *CompareEntry) BaselineResult() *CompareResult {
 	if base := c.Baseline; base != "" {
 		for i := range c.Results {
 			res := &c.Results[i]
-			if strings.EqualFold(res.Profile, base) || strings.EqualFold(res.Environment, base) {
+			if strings.EqualFold(res.Identity, base) ||
+				strings.EqualFold(res.Profile, base) ||
+				strings.EqualFold(res.Environment, base) {
 				return res
 			}
 		}
@@ -55,6 +62,7 @@ func (c *CompareEntry) BaselineResult() *CompareResult {
 type CompareResult struct {
 	Environment          string               `json:"environment"`
 	Profile              string               `json:"profile,omitempty"`
+	Identity             string               `json:"identity,omitempty"`
 	EnvironmentSelection EnvironmentSelection `json:"environmentSelection,omitempty"`
 	Status               string               `json:"status"`
 	StatusCode           int                  `json:"statusCode"`
@@ -64,6 +72,15 @@ type CompareResult struct {
 	Error                string               `json:"error,omitempty"`
 }
 
+// Label names the row in listings: the @as identity when there is one,
+// otherwise the environment.
+func (r CompareResult) Label() string {
+	if r.Identity != "" {
+		return r.Identity
+	}
+	return r.Environment
+}
+
 type ProfileResults struct {
 	TotalRuns      int                   `json:"totalRuns"`
 	WarmupRuns     int                   `json:"warmupRuns"`
@@ -11,8 +11,15 @@ const (
 	V10
 	V11
 	V2
+	V3
+	// AltSvc starts on HTTP/1.1 or HTTP/2 and moves to HTTP/3 once the origin
+	// advertises it in an Alt-Svc header.
+	AltSvc
 )
 
+// AltSvcValue is the setting value that selects AltSvc.
+const AltSvcValue = "alt-svc"
+
 func ParseToken(raw string) (HTTP, bool) {
 	return parse(raw, false)
 }
@@ -40,6 +47,10 @@ func Format(v HTTP) string {
 		return "1.1"
 	case V2:
 		return "2"
+	case V3:
+		return "3"
+	case AltSvc:
+		return AltSvcValue
 	default:
 		return ""
 	}
@@ -67,6 +78,9 @@ func parse(raw string, allowBare bool) (HTTP, bool) {
 		return Unknown, false
 	}
 	s = strings.ToLower(s)
+	if allowBare && s == AltSvcValue {
+		return AltSvc, true
+	}
 	if after, ok := strings.CutPrefix(s, "http/"); ok {
 		s = after
 	} else if !allowBare {
@@ -79,6 +93,8 @@ func parse(raw string, allowBare bool) (HTTP, bool) {
 		return V11, true
 	case "2", "2.0":
 		return V2, true
+	case "3", "3.0":
+		return V3, true
 	default:
 		return Unknown, false
 	}
@@ -8,6 +8,7 @@ func TestParseToken(t *testing.T) {
 		"HTTP/1.1": V11,
 		"http/2":   V2,
 		"http/2.0": V2,
+		"HTTP/3":   V3,
 	}
 	for raw, want := range cases {
 		got, ok := ParseToken(raw)
@@ -19,6 +20,9 @@ func TestParseToken(t *testing.T) {
 	if _, ok := ParseToken("1.1"); ok {
 		t.Fatalf("expected bare version to be rejected")
 	}
+	if _, ok := ParseToken("alt-svc"); ok {
+		t.Fatalf("expected alt-svc to be rejected on the request line")
+	}
 }
 
 func TestParseValue(t *testing.T) {
@@ -29,6 +33,9 @@ func TestParseValue(t *testing.T) {
 		"2.0":      V2,
 		"HTTP/1.1": V11,
 		"HTTP/2":   V2,
+		"3":        V3,
+		"HTTP/3":   V3,
+		"Alt-Svc":  AltSvc,
 	}
 	for raw, want := range cases {
 		got, ok := ParseValue(raw)
@@ -37,7 +44,7 @@ func TestParseValue(t *testing.T) {
 		}
 	}
 
-	if _, ok := ParseValue("HTTP/3"); ok {
+	if _, ok := ParseValue("HTTP/4"); ok {
 		t.Fatalf("expected unknown version to be rejected")
 	}
 }
@@ -0,0 +1,132 @@
+package insomnia
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+var oauthGrants = map[string]string{
+	"client_credentials": "client_credentials",
+	"password":           "password",
+	"authorization_code": "authorization_code",
+}
+
+// authType is empty for a block that defers to the folder above, which is
+// what an empty or disabled block means in Insomnia.
+func authType(a Auth) string {
+	if len(a) == 0 || a.flag("disabled") {
+		return ""
+	}
+	t := a.str("type")
+	if t == "inherit" {
+		return ""
+	}
+	return t
+}
+
+// authSpec maps an Insomnia auth block. "none" yields no spec: folder auth is
+// applied per request, so there is nothing above it to switch off.
+func authSpec(a Auth) (*restfile.AuthSpec, error) {
+	get := func(key string) string { return text(a.str(key), func(string) {}) }
+	switch t := authType(a); t {
+	case "", "none":
+		return nil, nil
+	case "bearer":
+		tok := get("token")
+		if tok == "" {
+			return nil, errors.New("bearer auth has no token and was skipped")
+		}
+		if p := get("prefix"); p != "" && !strings.EqualFold(p, "bearer") {
+			return authOf(restfile.AuthHeader, "header", "Authorization", "value", p+" "+tok), nil
+		}
+		return authOf(restfile.AuthBearer, "token", tok), nil
+	case "basic", "digest":
+		user, pass := get("username"), get("password")
+		if user == "" || pass == "" {
+			return nil, fmt.Errorf("%s auth needs a username and password and was skipped", t)
+		}
+		kind := restfile.AuthBasic
+		if t == "digest" {
+			kind = restfile.AuthDigest
+		}
+		return authOf(kind, "username", user, "password", pass), nil
+	case "apikey":
+		name, val := get("key"), get("value")
+		if name == "" || val == "" {
+			return nil, errors.New("apikey auth needs a key and value and was skipped")
+		}
+		place := "header"
+		switch a.str("addTo") {
+		case "", "header":
+		case "queryParams":
+			place = "query"
+		default:
+			return nil, fmt.Errorf("apikey auth sent as %s is not supported and was skipped", a.str("addTo"))
+		}
+		return authOf(restfile.AuthAPIKey, "placement", place, "name", name, "value", val), nil
+	case "oauth2":
+		return oauth2Spec(a, get)
+	default:
+		return nil, fmt.Errorf("%s auth is not supported and was skipped", t)
+	}
+}
+
+func oauth2Spec(a Auth, get func(string) string) (*restfile.AuthSpec, error) {
+	grant := get("grantType")
+	if grant == "" {
+		grant = "authorization_code"
+	}
+	g, ok := oauthGrants[grant]
+	if !ok {
+		return nil, fmt.Errorf("oauth2 grant %q is not supported and was skipped", grant)
+	}
+	tokenURL := get("accessTokenUrl")
+	if tokenURL == "" {
+		return nil, errors.New("oauth2 auth has no access token URL and was skipped")
+	}
+	params := []string{"token_url", tokenURL, "grant", g}
+	for _, kv := range [][2]string{
+		{"auth_url", "authorizationUrl"},
+		{"redirect_uri", "redirectUrl"},
+		{"client_id", "clientId"},
+		{"client_secret", "clientSecret"},
+		{"scope", "scope"},
+		{"username", "username"},
+		{"password", "password"},
+		{"state", "state"},
+	} {
+		if v := get(kv[1]); v != "" {
+			params = append(params, kv[0], v)
+		}
+	}
+	clientAuth := "basic"
+	if a.flag("credentialsInBody") {
+		clientAuth = "body"
+	}
+	params = append(params, "client_auth", clientAuth)
+	return authOf(restfile.AuthOAuth2, params...), nil
+}
+
+// grpcAuthErr reports auth that a gRPC call cannot send as metadata.
+func grpcAuthErr(spec *restfile.AuthSpec) error {
+	switch {
+	case spec == nil:
+		return nil
+	case spec.Kind() == restfile.AuthDigest:
+		return errors.New("digest auth is not supported on gRPC requests and was skipped")
+	case spec.Kind() == restfile.AuthAPIKey && spec.Params["placement"] == "query":
+		return errors.New("apikey auth in the query is not supported on gRPC requests and was skipped")
+	}
+	return nil
+}
+
+func authOf(kind restfile.AuthKind, kv ...string) *restfile.AuthSpec {
+	params := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		params[kv[i]] = kv[i+1]
+	}
+	return &restfile.AuthSpec{Type: kind, Params: params}
+}
@@ -0,0 +1,115 @@
+package insomnia
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restimport"
+)
+
+const (
+	headerContentType = "Content-Type"
+	mimeGraphQL       = "application/graphql"
+	mimeFormURL       = "application/x-www-form-urlencoded"
+	mimeMultipart     = "multipart/form-data"
+	mimeOctetStream   = "application/octet-stream"
+)
+
+// body maps the body by its mime type, as Insomnia does. Text bodies keep the
+// Content-Type header the request already carries.
+func (cv *converter) body(req *restfile.Request, b Body, warn func(string)) {
+	mime := strings.TrimSpace(b.MimeType)
+	switch {
+	case mime == mimeGraphQL:
+		cv.graphql(req, b.Text, warn)
+	case mime == mimeFormURL:
+		var pairs []string
+		for _, p := range b.Params {
+			if p.Disabled || p.Name == "" {
+				continue
+			}
+			pairs = append(pairs, restimport.FormEscape(p.Name)+"="+restimport.FormEscape(text(p.Value, warn)))
+		}
+		if len(pairs) == 0 {
+			return
+		}
+		req.Body.Text = strings.Join(pairs, "&")
+		restimport.SetDefaultHeader(req.Headers, headerContentType, mimeFormURL)
+	case mime == mimeMultipart:
+		body, boundary := multipart(b.Params, warn)
+		if body == "" {
+			return
+		}
+		req.Body.Text = body
+		// The boundary has to match the body, so the exported header goes.
+		restimport.DelHeader(req.Headers, headerContentType)
+		req.Headers[headerContentType] = []string{mimeMultipart + "; boundary=" + boundary}
+	case strings.TrimSpace(b.FileName) != "":
+		req.Body.FilePath = strings.TrimSpace(b.FileName)
+	case strings.TrimSpace(b.Text) != "":
+		req.Body.Text = text(b.Text, warn)
+		if mime != "" {
+			restimport.SetDefaultHeader(req.Headers, headerContentType, mime)
+		}
+	}
+}
+
+// Insomnia stores a GraphQL body as the JSON payload it sends.
+func (cv *converter) graphql(req *restfile.Request, raw string, warn func(string)) {
+	if strings.TrimSpace(raw) == "" {
+		return
+	}
+	var payload struct {
+		Query         string          `json:"query"`
+		Variables     json.RawMessage `json:"variables"`
+		OperationName string          `json:"operationName"`
+	}
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		warn("graphql body is not valid JSON and was kept as text")
+		req.Body.Text = text(raw, warn)
+		return
+	}
+	gql := &restfile.GraphQLBody{
+		Query:         text(strings.TrimSpace(payload.Query), warn),
+		OperationName: strings.TrimSpace(payload.OperationName),
+	}
+	if v := strings.TrimSpace(string(payload.Variables)); v != "" && v != "null" && v != "{}" {
+		var parsed any
+		if err := json.Unmarshal(payload.Variables, &parsed); err == nil {
+			if out, err := json.MarshalIndent(parsed, "", "  "); err == nil {
+				v = string(out)
+			}
+		}
+		gql.Variables = text(v, warn)
+	}
+	req.Body.GraphQL = gql
+	// resterm sends GraphQL as JSON; the exported application/graphql header
+	// describes Insomnia's editor mode, not the wire format.
+	restimport.DelHeader(req.Headers, headerContentType)
+}
+
+// multipart keeps the enabled params. Insomnia records no content type for a
+// file, so file parts are sent as octet streams.
+func multipart(params []Param, warn func(string)) (string, string) {
+	var parts []restimport.Part
+	for _, p := range params {
+		if p.Disabled || p.Name == "" {
+			continue
+		}
+		if p.Type == "file" {
+			if strings.TrimSpace(p.FileName) == "" {
+				warn("form file " + p.Name + " has no source path and was skipped")
+				continue
+			}
+			parts = append(parts, restimport.Part{
+				Name:        p.Name,
+				File:        strings.TrimSpace(p.FileName),
+				ContentType: mimeOctetStream,
+			})
+			continue
+		}
+		parts = append(parts, restimport.Part{Name: p.Name, Value: text(p.Value, warn)})
+	}
+	return restimport.Multipart(parts)
+}
@@ -0,0 +1,290 @@
+package insomnia
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/directive"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restimport"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+// inherited is what a folder passes down to the requests below it.
+type inherited = restimport.Scope[Auth]
+
+type converter struct {
+	doc   *restfile.Document
+	warn  []string
+	names map[string]int
+}
+
+// Convert turns an export into a single document. Folders become tags, and a
+// request without auth of its own takes the nearest folder's. Features with no
+// resterm equivalent are reported as warnings.
+func Convert(exp *Export) (*restfile.Document, []string) {
+	cv := &converter{doc: &restfile.Document{}, names: map[string]int{}}
+	if exp.Skipped > 0 {
+		cv.warn = append(cv.warn, fmt.Sprintf("%d resources of unsupported types (such as WebSocket requests) were skipped", exp.Skipped))
+	}
+	for _, it := range exp.Items {
+		cv.walk(it, inherited{})
+	}
+	return cv.doc, cv.warn
+}
+
+func (cv *converter) walk(it *Item, in inherited) {
+	switch it.Kind {
+	case KindRequest:
+		cv.request(it, in)
+		return
+	case KindGRPC:
+		cv.grpc(it, in)
+		return
+	}
+
+	in = in.Tag(slug(it.Name)).Folder(it.Auth, authType(it.Auth) != "",
+		cv.folderVars(it.Env, fmt.Sprintf("folder %q", it.Name)))
+	for _, child := range it.Children {
+		cv.walk(child, in)
+	}
+}
+
+func (cv *converter) base(it *Item, in inherited) (*restfile.Request, func(string)) {
+	name := cv.uniqueName(strings.Join(strings.Fields(it.Name), " "))
+	where := fmt.Sprintf("request %q", name)
+	req := &restfile.Request{Headers: http.Header{}}
+	req.Metadata.Name = name
+	req.Metadata.Description = it.Description
+	in.Apply(req)
+	return req, func(msg string) { cv.warn = append(cv.warn, where+": "+msg) }
+}
+
+func (cv *converter) request(it *Item, in inherited) {
+	req, warn := cv.base(it, in)
+	req.Method = strings.ToUpper(strings.TrimSpace(it.Method))
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	req.URL = cv.buildURL(it, warn)
+	for _, h := range it.Headers {
+		key := strings.TrimSpace(h.Name)
+		if h.Disabled || key == "" {
+			continue
+		}
+		req.Headers[key] = append(req.Headers[key], text(h.Value, warn))
+	}
+	cv.body(req, it.Body, warn)
+
+	if auth := in.AuthFor(it.Auth, authType(it.Auth) != ""); auth != nil {
+		spec, err := authSpec(auth)
+		switch {
+		case err != nil:
+			warn(err.Error())
+		case spec != nil:
+			req.Metadata.Auth = spec
+		}
+	}
+	if len(req.Headers) == 0 {
+		req.Headers = nil
+	}
+	cv.doc.Requests = append(cv.doc.Requests, req)
+}
+
+// grpc keeps reflection on, as Insomnia only records the proto file id and the
+// file itself is not something the importer can write out.
+func (cv *converter) grpc(it *Item, in inherited) {
+	req, warn := cv.base(it, in)
+	target := text(strings.TrimSpace(it.URL), warn)
+	method := strings.TrimSpace(it.ProtoMethod)
+	if method == "" {
+		warn("gRPC request has no method and was skipped")
+		return
+	}
+	g := &restfile.GRPCRequest{
+		Target:        target,
+		FullMethod:    "/" + strings.TrimPrefix(method, "/"),
+		UseReflection: true,
+		Message:       text(strings.TrimSpace(it.Body.Text), warn),
+	}
+	for _, md := range it.Metadata {
+		key := strings.TrimSpace(md.Name)
+		if md.Disabled || key == "" {
+			continue
+		}
+		g.Metadata = append(g.Metadata, restfile.MetadataPair{Key: key, Value: text(md.Value, warn)})
+	}
+	if auth := in.AuthFor(it.Auth, authType(it.Auth) != ""); auth != nil {
+		spec, err := authSpec(auth)
+		if err == nil {
+			err = grpcAuthErr(spec)
+		}
+		switch {
+		case err != nil:
+			warn(err.Error())
+		case spec != nil:
+			req.Metadata.Auth = spec
+		}
+	}
+	req.Method = "GRPC"
+	req.URL = target
+	req.GRPC = g
+	req.Headers = nil
+	cv.doc.Requests = append(cv.doc.Requests, req)
+}
+
+// buildURL appends the enabled parameters Insomnia keeps beside the URL.
+func (cv *converter) buildURL(it *Item, warn func(string)) string {
+	u := text(strings.TrimSpace(it.URL), warn)
+	var qs []string
+	for _, p := range it.Parameters {
+		name := strings.TrimSpace(p.Name)
+		if p.Disabled || name == "" {
+			continue
+		}
+		if p.Value == "" {
+			qs = append(qs, name)
+			continue
+		}
+		qs = append(qs, name+"="+text(p.Value, warn))
+	}
+	if len(qs) == 0 {
+		return u
+	}
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + strings.Join(qs, "&")
+}
+
+// folderVars keeps the scalar values of a folder environment. Nested objects
+// would need dotted names, which @var does not take.
+func (cv *converter) folderVars(env map[string]any, where string) []restfile.Variable {
+	var out []restfile.Variable
+	for _, key := range sortedKeys(env) {
+		val, ok := scalar(env[key])
+		if !ok || strings.ContainsAny(val, "\r\n") {
+			cv.warn = append(cv.warn, fmt.Sprintf("%s: environment value %q is not a single-line scalar and was skipped", where, key))
+			continue
+		}
+		out = append(out, restfile.Variable{
+			Name:  key,
+			Value: text(val, func(msg string) { cv.warn = append(cv.warn, where+": "+msg) }),
+			Scope: directive.ScopeRequest,
+		})
+	}
+	return out
+}
+
+func (cv *converter) uniqueName(name string) string {
+	if name == "" {
+		name = "request"
+	}
+	cv.names[name]++
+	if n := cv.names[name]; n > 1 {
+		return fmt.Sprintf("%s %d", name, n)
+	}
+	return name
+}
+
+// Environments maps the base environment to $shared when there are sub
+// environments to inherit it, and to an environment of its own otherwise.
+func Environments(exp *Export) []restwriter.Environment {
+	var out []restwriter.Environment
+	if exp.Base != nil && (len(exp.Base.Data) > 0 || len(exp.Subs) == 0) {
+		name := vars.SharedEnvKey
+		if len(exp.Subs) == 0 {
+			name = exp.Base.Name
+			if strings.TrimSpace(name) == "" {
+				name = "default"
+			}
+		}
+		out = append(out, restwriter.Environment{Name: name, Values: envValues(exp.Base.Data)})
+	}
+	for _, sub := range exp.Subs {
+		out = append(out, restwriter.Environment{Name: sub.Name, Values: envValues(sub.Data)})
+	}
+	return out
+}
+
+// Values are written back through text so templates inside them point at
+// resterm names too.
+func envValues(data map[string]any) map[string]any {
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		switch t := v.(type) {
+		case string:
+			out[k] = text(t, func(string) {})
+		case map[string]any:
+			out[k] = envValues(t)
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func scalar(v any) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case bool, int, int64, float64:
+		return fmt.Sprint(t), true
+	case json.Number:
+		return t.String(), true
+	default:
+		return "", false
+	}
+}
+
+var (
+	// {{ _.name }} and the older {{ name }} both read an environment value.
+	nunjucksVar = regexp.MustCompile(`\{\{\s*(?:_\.)?([A-Za-z_$][\w.\-]*)\s*\}\}`)
+	nunjucksTag = regexp.MustCompile(`\{%\s*(\w+)\s*([^%]*?)\s*%\}`)
+)
+
+// text rewrites Insomnia templates into resterm references. Template tags with
+// no equivalent are left in place and reported.
+func text(s string, warn func(string)) string {
+	if !strings.Contains(s, "{{") && !strings.Contains(s, "{%") {
+		return s
+	}
+	s = nunjucksVar.ReplaceAllString(s, "{{$1}}")
+	return nunjucksTag.ReplaceAllStringFunc(s, func(m string) string {
+		sub := nunjucksTag.FindStringSubmatch(m)
+		tag, args := sub[1], strings.Trim(sub[2], `'" `)
+		switch {
+		case tag == "uuid":
+			return "{{$uuid}}"
+		case tag == "now" && (args == "" || args == "iso-8601"):
+			return "{{$timestampISO8601}}"
+		case tag == "now" && args == "unix":
+			return "{{$timestamp}}"
+		case tag == "now" && args == "millis":
+			return "{{$timestampMs}}"
+		}
+		warn(fmt.Sprintf("template tag {%% %s %%} has no resterm equivalent", tag))
+		return m
+	})
+}
+
+var slugSep = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(s string) string {
+	return strings.Trim(slugSep.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
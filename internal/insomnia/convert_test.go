@@ -0,0 +1,182 @@
+package insomnia
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+func loadExport(t *testing.T, path string) *Export {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	exp, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse export: %v", err)
+	}
+	return exp
+}
+
+// The generated file has to load back through the parser with the same
+// requests, or the import is only half done.
+func roundTrip(t *testing.T, doc *restfile.Document) *restfile.Document {
+	t.Helper()
+	out, err := restwriter.Render(doc, restwriter.Options{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	back := parser.Parse("import.http", []byte(out))
+	if len(back.Errors) != 0 {
+		t.Fatalf("output did not parse: %v\n%s", back.Errors, out)
+	}
+	if len(back.Requests) != len(doc.Requests) {
+		t.Fatalf("%d requests, want %d\n%s", len(back.Requests), len(doc.Requests), out)
+	}
+	return back
+}
+
+func findRequest(t *testing.T, doc *restfile.Document, name string) *restfile.Request {
+	t.Helper()
+	for _, r := range doc.Requests {
+		if r.Metadata.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("request %q not found", name)
+	return nil
+}
+
+func TestConvertV4Export(t *testing.T) {
+	exp := loadExport(t, "testdata/export.json")
+	doc, warn := Convert(exp)
+	back := roundTrip(t, doc)
+
+	var names []string
+	for _, r := range back.Requests {
+		names = append(names, r.Metadata.Name)
+	}
+	if got := strings.Join(names, ","); got != "Get user,Create user,Public,Search,Graph,Say hello" {
+		t.Fatalf("requests = %s", got)
+	}
+
+	get := findRequest(t, back, "Get user")
+	if get.URL != "{{baseUrl}}/users/42?verbose=true" {
+		t.Fatalf("url = %q", get.URL)
+	}
+	if got := get.Headers.Get("X-Request-Id"); got != "{{$uuid}}" || get.Headers.Get("X-Off") != "" {
+		t.Fatalf("headers = %v", get.Headers)
+	}
+	if get.Metadata.Auth == nil || get.Metadata.Auth.Kind() != restfile.AuthBearer ||
+		get.Metadata.Auth.Params["token"] != "{{token}}" {
+		t.Fatalf("folder auth was not inherited: %+v", get.Metadata.Auth)
+	}
+	if len(get.Variables) != 1 || get.Variables[0].Name != "team" {
+		t.Fatalf("folder environment = %+v", get.Variables)
+	}
+	if got := strings.Join(get.Metadata.Tags, " "); got != "users" {
+		t.Fatalf("tags = %q", got)
+	}
+
+	create := findRequest(t, back, "Create user")
+	if create.Metadata.Auth == nil || create.Metadata.Auth.Kind() != restfile.AuthBasic {
+		t.Fatalf("request auth = %+v", create.Metadata.Auth)
+	}
+	if !strings.Contains(create.Body.Text, "{{$timestampISO8601}}") {
+		t.Fatalf("body = %q", create.Body.Text)
+	}
+	if findRequest(t, back, "Public").Metadata.Auth != nil {
+		t.Fatalf("auth type none still inherited the folder auth")
+	}
+
+	search := findRequest(t, back, "Search")
+	if strings.TrimSpace(search.Body.Text) != "q=red+shoes" || search.Headers.Get("Content-Type") != mimeFormURL {
+		t.Fatalf("form body = %q, %v", search.Body.Text, search.Headers)
+	}
+	if got := strings.Join(search.Metadata.Tags, " "); got != "users admin" {
+		t.Fatalf("nested tags = %q", got)
+	}
+
+	graph := findRequest(t, back, "Graph")
+	gql := graph.Body.GraphQL
+	if gql == nil || gql.OperationName != "User" || !strings.Contains(gql.Query, "user(id: $id)") ||
+		!strings.Contains(gql.Variables, `"id": "1"`) {
+		t.Fatalf("graphql = %+v", gql)
+	}
+	if graph.Headers.Get("Content-Type") != "" {
+		t.Fatalf("application/graphql header was kept")
+	}
+
+	say := findRequest(t, back, "Say hello")
+	if say.GRPC == nil || say.GRPC.FullMethod != "/demo.v1.Greeter/SayHello" || say.GRPC.Target != "localhost:50051" {
+		t.Fatalf("grpc = %+v", say.GRPC)
+	}
+	if say.GRPC.Message != `{"name": "{{user}}"}` || len(say.GRPC.Metadata) != 1 {
+		t.Fatalf("grpc message = %q, metadata = %v", say.GRPC.Message, say.GRPC.Metadata)
+	}
+	if a := say.Metadata.Auth; a == nil || a.Kind() != restfile.AuthBearer || a.Params["token"] != "{{grpcToken}}" {
+		t.Fatalf("grpc auth = %+v", a)
+	}
+
+	all := strings.Join(warn, "\n")
+	for _, want := range []string{"1 resources of unsupported types", "sent as cookie", `"nested"`} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("warnings missing %q:\n%s", want, all)
+		}
+	}
+
+	envs := Environments(exp)
+	if len(envs) != 2 || envs[0].Name != vars.SharedEnvKey || envs[1].Name != "Staging" {
+		t.Fatalf("environments = %+v", envs)
+	}
+	if api, ok := envs[0].Values["api"].(map[string]any); !ok || api["version"] != "v1" {
+		t.Fatalf("nested base values = %+v", envs[0].Values)
+	}
+}
+
+func TestConvertV5Collection(t *testing.T) {
+	exp := loadExport(t, "testdata/collection.yaml")
+	doc, _ := Convert(exp)
+	back := roundTrip(t, doc)
+
+	list := findRequest(t, back, "List orders")
+	if back.Requests[0] != list {
+		t.Fatalf("sort keys were not applied")
+	}
+	auth := list.Metadata.Auth
+	if auth == nil || auth.Kind() != restfile.AuthOAuth2 || auth.Params["client_auth"] != "body" ||
+		auth.Params["token_url"] != "{{baseUrl}}/token" {
+		t.Fatalf("folder oauth2 = %+v", auth)
+	}
+	create := findRequest(t, back, "Create order")
+	if create.Metadata.Auth == nil || create.Metadata.Auth.Kind() != restfile.AuthHeader ||
+		create.Metadata.Auth.Params["value"] != "Token t" {
+		t.Fatalf("prefixed bearer = %+v", create.Metadata.Auth)
+	}
+	if ping := findRequest(t, back, "Ping"); ping.GRPC == nil || ping.GRPC.FullMethod != "/demo.Health/Ping" {
+		t.Fatalf("v5 grpc = %+v", ping.GRPC)
+	}
+
+	envs := Environments(exp)
+	if len(envs) != 2 || envs[1].Name != "Prod" || envs[1].Values["baseUrl"] != "https://example.com" {
+		t.Fatalf("environments = %+v", envs)
+	}
+}
+
+func TestParseRejectsOtherFiles(t *testing.T) {
+	for _, in := range []string{
+		`{"info": {"name": "x"}, "item": []}`,
+		`{"_type": "export", "__export_format": 3, "resources": []}`,
+		"openapi: 3.0.0\n",
+	} {
+		if _, err := Parse([]byte(in)); err == nil {
+			t.Fatalf("Parse accepted %q", in)
+		}
+	}
+}
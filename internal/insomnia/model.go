@@ -0,0 +1,282 @@
+package insomnia
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind tells folders apart from the request types the importer knows.
+type Kind string
+
+const (
+	KindFolder  Kind = "request_group"
+	KindRequest Kind = "request"
+	KindGRPC    Kind = "grpc_request"
+)
+
+// Export is an Insomnia export reduced to the tree both formats describe. The
+// v4 export lists resources flat with parent ids; v5 nests them.
+type Export struct {
+	Name  string
+	Items []*Item
+	// Base holds the values every sub environment inherits.
+	Base *Environment
+	Subs []*Environment
+	// Skipped counts resources of a type the importer does not read, such as
+	// WebSocket requests.
+	Skipped int
+}
+
+type Environment struct {
+	Name string
+	Data map[string]any
+}
+
+type Item struct {
+	Kind        Kind
+	Name        string
+	Description string
+	Method      string
+	URL         string
+	Headers     []Param
+	Parameters  []Param
+	Body        Body
+	Auth        Auth
+	// Env is the folder environment, visible to the requests below it.
+	Env map[string]any
+	// ProtoMethod and Metadata are only set on gRPC requests.
+	ProtoMethod string
+	Metadata    []Param
+	Children    []*Item
+}
+
+type Param struct {
+	Name     string `json:"name" yaml:"name"`
+	Value    string `json:"value" yaml:"value"`
+	Disabled bool   `json:"disabled" yaml:"disabled"`
+	Type     string `json:"type" yaml:"type"`
+	FileName string `json:"fileName" yaml:"fileName"`
+}
+
+type Body struct {
+	MimeType string  `json:"mimeType" yaml:"mimeType"`
+	Text     string  `json:"text" yaml:"text"`
+	Params   []Param `json:"params" yaml:"params"`
+	FileName string  `json:"fileName" yaml:"fileName"`
+}
+
+// Auth keeps the block as a map: every type stores different keys, and some of
+// them are booleans.
+type Auth map[string]any
+
+func (a Auth) str(key string) string {
+	switch v := a[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+func (a Auth) flag(key string) bool {
+	v, _ := a[key].(bool)
+	return v
+}
+
+// resource is one entry of either format. v4 fills the underscore fields and
+// links entries by parent id; v5 nests them under children.
+type resource struct {
+	ID          string         `json:"_id"`
+	Type        string         `json:"_type"`
+	ParentID    string         `json:"parentId"`
+	SortKey     float64        `json:"metaSortKey"`
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description" yaml:"description"`
+	Method      string         `json:"method" yaml:"method"`
+	URL         string         `json:"url" yaml:"url"`
+	Headers     []Param        `json:"headers" yaml:"headers"`
+	Parameters  []Param        `json:"parameters" yaml:"parameters"`
+	Body        Body           `json:"body" yaml:"body"`
+	Auth        Auth           `json:"authentication" yaml:"authentication"`
+	Environment map[string]any `json:"environment" yaml:"environment"`
+	Data        map[string]any `json:"data" yaml:"data"`
+	ProtoMethod string         `json:"protoMethodName" yaml:"protoMethodName"`
+	Metadata    []Param        `json:"metadata" yaml:"metadata"`
+	Meta        struct {
+		ID          string  `yaml:"id"`
+		SortKey     float64 `yaml:"sortKey"`
+		Description string  `yaml:"description"`
+	} `json:"-" yaml:"meta"`
+	Children []resource `json:"-" yaml:"children"`
+}
+
+func (r resource) item(kind Kind) *Item {
+	desc := r.Description
+	if desc == "" {
+		desc = r.Meta.Description
+	}
+	return &Item{
+		Kind:        kind,
+		Name:        strings.TrimSpace(r.Name),
+		Description: strings.TrimSpace(desc),
+		Method:      r.Method,
+		URL:         r.URL,
+		Headers:     r.Headers,
+		Parameters:  r.Parameters,
+		Body:        r.Body,
+		Auth:        r.Auth,
+		Env:         r.Environment,
+		ProtoMethod: r.ProtoMethod,
+		Metadata:    r.Metadata,
+	}
+}
+
+// Parse reads a v4 JSON export or a v5 YAML collection.
+func Parse(data []byte) (*Export, error) {
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] == '{' {
+		var probe struct {
+			Format int `json:"__export_format"`
+		}
+		if err := json.Unmarshal(t, &probe); err != nil {
+			return nil, fmt.Errorf("insomnia: parse: %w", err)
+		}
+		if probe.Format != 0 {
+			return parseV4(t, probe.Format)
+		}
+	}
+	return parseV5(data)
+}
+
+func parseV4(data []byte, format int) (*Export, error) {
+	if format != 4 {
+		return nil, fmt.Errorf("insomnia: export format %d is not supported (want 4 or a v5 collection)", format)
+	}
+	var doc struct {
+		Resources []resource `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("insomnia: parse: %w", err)
+	}
+
+	exp := &Export{}
+	byParent := map[string][]resource{}
+	var workspaces []resource
+	for _, r := range doc.Resources {
+		if r.Type == "workspace" {
+			workspaces = append(workspaces, r)
+			continue
+		}
+		byParent[r.ParentID] = append(byParent[r.ParentID], r)
+	}
+	if len(workspaces) == 0 {
+		return nil, errors.New("insomnia: export has no workspace")
+	}
+	for _, list := range byParent {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].SortKey < list[j].SortKey })
+	}
+
+	var walk func(parent string) []*Item
+	walk = func(parent string) []*Item {
+		var out []*Item
+		for _, r := range byParent[parent] {
+			switch Kind(r.Type) {
+			case KindFolder:
+				it := r.item(KindFolder)
+				it.Children = walk(r.ID)
+				out = append(out, it)
+			case KindRequest, KindGRPC:
+				out = append(out, r.item(Kind(r.Type)))
+			case "environment", "cookie_jar", "api_spec", "proto_file", "proto_directory",
+				"unit_test", "unit_test_suite", "request_group_meta", "request_meta", "workspace_meta":
+			default:
+				exp.Skipped++
+			}
+		}
+		return out
+	}
+
+	// Several workspaces in one export are kept apart as top-level folders.
+	for _, ws := range workspaces {
+		items := walk(ws.ID)
+		if len(workspaces) > 1 {
+			items = []*Item{{Kind: KindFolder, Name: ws.Name, Children: items}}
+		}
+		exp.Items = append(exp.Items, items...)
+		if exp.Name == "" {
+			exp.Name = ws.Name
+		}
+		for _, env := range byParent[ws.ID] {
+			if env.Type != "environment" || exp.Base != nil {
+				continue
+			}
+			exp.Base = &Environment{Name: env.Name, Data: env.Data}
+			for _, sub := range byParent[env.ID] {
+				if sub.Type == "environment" {
+					exp.Subs = append(exp.Subs, &Environment{Name: sub.Name, Data: sub.Data})
+				}
+			}
+		}
+	}
+	return exp, nil
+}
+
+func parseV5(data []byte) (*Export, error) {
+	var doc struct {
+		Type         string     `yaml:"type"`
+		Name         string     `yaml:"name"`
+		Collection   []resource `yaml:"collection"`
+		Environments *struct {
+			Name string         `yaml:"name"`
+			Data map[string]any `yaml:"data"`
+			Subs []struct {
+				Name string         `yaml:"name"`
+				Data map[string]any `yaml:"data"`
+			} `yaml:"subEnvironments"`
+		} `yaml:"environments"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("insomnia: parse: %w", err)
+	}
+	if !strings.HasPrefix(doc.Type, "collection.insomnia.rest/5") {
+		return nil, errors.New("insomnia: not an Insomnia export (want a v4 export or a v5 collection)")
+	}
+
+	exp := &Export{Name: doc.Name}
+	var walk func(list []resource) []*Item
+	walk = func(list []resource) []*Item {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Meta.SortKey < list[j].Meta.SortKey })
+		var out []*Item
+		for _, r := range list {
+			id := r.Meta.ID
+			switch {
+			case r.Children != nil || strings.HasPrefix(id, "fld_"):
+				it := r.item(KindFolder)
+				it.Children = walk(r.Children)
+				out = append(out, it)
+			case r.ProtoMethod != "" || strings.HasPrefix(id, "greq_"):
+				out = append(out, r.item(KindGRPC))
+			case strings.HasPrefix(id, "req_") || (id == "" && r.URL != ""):
+				out = append(out, r.item(KindRequest))
+			default:
+				exp.Skipped++
+			}
+		}
+		return out
+	}
+	exp.Items = walk(doc.Collection)
+	if envs := doc.Environments; envs != nil {
+		exp.Base = &Environment{Name: envs.Name, Data: envs.Data}
+		for _, sub := range envs.Subs {
+			exp.Subs = append(exp.Subs, &Environment{Name: sub.Name, Data: sub.Data})
+		}
+	}
+	return exp, nil
+}
@@ -0,0 +1,87 @@
+package insomnia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+const errWriterNotConfigured = "insomnia: writer not configured"
+
+type DocumentWriter interface {
+	WriteDocument(ctx context.Context, doc *restfile.Document, dst string, opts WriterOptions) error
+}
+
+type WriterOptions struct {
+	OverwriteExisting bool
+	HeaderComment     string
+}
+
+// Result lists what an import wrote.
+type Result struct {
+	File    string
+	EnvFile string
+}
+
+type Service struct {
+	Writer DocumentWriter
+}
+
+// Import converts the export at src into the .http file dst. Environments land
+// in resterm.env.json beside it.
+func (s *Service) Import(ctx context.Context, src, dst string, opts WriterOptions) (Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if s.Writer == nil {
+		return Result{}, errors.New(errWriterNotConfigured)
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return Result{}, fmt.Errorf("insomnia: read export: %w", err)
+	}
+	exp, err := Parse(data)
+	if err != nil {
+		return Result{}, err
+	}
+	doc, warn := Convert(exp)
+	if len(doc.Requests) == 0 {
+		return Result{}, errors.New("insomnia: export has no requests")
+	}
+
+	opts.HeaderComment = buildHeader(opts.HeaderComment, src, warn)
+	if err := s.Writer.WriteDocument(ctx, doc, dst, opts); err != nil {
+		return Result{}, err
+	}
+	res := Result{File: dst}
+	if envs := Environments(exp); len(envs) > 0 {
+		res.EnvFile = filepath.Join(filepath.Dir(dst), restwriter.EnvFileName)
+		if err := restwriter.MergeEnvironments(res.EnvFile, envs); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func buildHeader(base, src string, warn []string) string {
+	var lines []string
+	for line := range strings.SplitSeq(base, "\n") {
+		if t := strings.TrimSpace(line); t != "" {
+			lines = append(lines, t)
+		}
+	}
+	lines = append(lines, "Source: Insomnia export "+filepath.Base(src))
+	for _, w := range warn {
+		if t := strings.TrimSpace(w); t != "" {
+			lines = append(lines, "Warning: "+t)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,61 @@
+package insomnia
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+func TestImportWritesFileAndEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, restwriter.EnvFileName)
+	if err := os.WriteFile(envPath, []byte(`{"local": {"baseUrl": "http://127.0.0.1"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "shop.http")
+
+	svc := Service{Writer: NewFileWriter()}
+	res, err := svc.Import(context.Background(), "testdata/export.json", dst, WriterOptions{HeaderComment: "Generated by tests"})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if res.File != dst || res.EnvFile != envPath {
+		t.Fatalf("result = %+v", res)
+	}
+
+	out, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Generated by tests",
+		"# Source: Insomnia export export.json",
+		"# @graphql",
+		"GRPC localhost:50051",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	data, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envs map[string]map[string]any
+	if err := json.Unmarshal(data, &envs); err != nil {
+		t.Fatalf("env file: %v", err)
+	}
+	if envs["local"]["baseUrl"] != "http://127.0.0.1" || envs["Staging"]["token"] != "abc" {
+		t.Fatalf("environments = %v", envs)
+	}
+
+	if _, err := svc.Import(context.Background(), "testdata/export.json", dst, WriterOptions{}); err == nil {
+		t.Fatalf("import overwrote an existing file")
+	}
+}
@@ -123,10 +123,28 @@ var settingArgs = []Item{
 	},
 	{
 		Label:       "proxy=",
-		Summary:     "HTTP proxy URL",
+		Summary:     "Proxy URL (http, https, socks5, socks5h)",
 		Insert:      "proxy=http://proxy",
 		Placeholder: "http://proxy",
 	},
+	{
+		Label:       "proxy-rules=",
+		Summary:     "Per-host proxy rules (host glob=proxy or DIRECT)",
+		Insert:      "proxy-rules=*.internal=DIRECT,*=http://proxy:3128",
+		Placeholder: "*.internal=DIRECT,*=http://proxy:3128",
+	},
+	{
+		Label:       "proxy-user=",
+		Summary:     "Proxy username",
+		Insert:      "proxy-user={{proxy.user}}",
+		Placeholder: "{{proxy.user}}",
+	},
+	{
+		Label:       "proxy-password=",
+		Summary:     "Proxy password",
+		Insert:      "proxy-password={{proxy.password}}",
+		Placeholder: "{{proxy.password}}",
+	},
 	{
 		Label:       "followredirects=",
 		Summary:     "Follow redirects (true/false)",
@@ -147,10 +165,28 @@ var settingArgs = []Item{
 	},
 	{
 		Label:       "http-version=",
-		Summary:     "HTTP protocol version (1.0|1.1|2)",
+		Summary:     "HTTP protocol version (1.0|1.1|2|3|alt-svc)",
 		Insert:      "http-version=1.1",
 		Placeholder: "1.1",
 	},
+	{
+		Label:       "http-unix-socket=",
+		Summary:     "Send over a Unix domain socket",
+		Insert:      "http-unix-socket=/var/run/docker.sock",
+		Placeholder: "/var/run/docker.sock",
+	},
+	{
+		Label:       "http-resolve=",
+		Summary:     "Pin host:port to an address (like curl --resolve)",
+		Insert:      "http-resolve=example.com:443=127.0.0.1",
+		Placeholder: "example.com:443=127.0.0.1",
+	},
+	{
+		Label:       "http-connect-to=",
+		Summary:     "Send host:port to another host:port (like curl --connect-to)",
+		Insert:      "http-connect-to=example.com:443=localhost:8443",
+		Placeholder: "example.com:443=localhost:8443",
+	},
 	{
 		Label:       "http-insecure=",
 		Summary:     "Skip TLS verify (HTTP)",
@@ -294,6 +330,12 @@ var directiveArgs = map[directive.Name][]Item{
 			Insert:      "basic user pass",
 			Placeholder: "user pass",
 		},
+		{
+			Label:       "digest",
+			Summary:     "HTTP Digest auth (RFC 7616) with username and password",
+			Insert:      "digest user pass",
+			Placeholder: "user pass",
+		},
 		{
 			Label:       "bearer",
 			Summary:     "Bearer token auth",
@@ -700,6 +742,47 @@ var directiveArgs = map[directive.Name][]Item{
 			Insert:      "group=api",
 			Placeholder: "api",
 		},
+		{
+			Label:       "ignore=",
+			Summary:     "Skip body paths when diffing, comma-separated",
+			Insert:      "ignore=$.requestId",
+			Placeholder: "$.requestId",
+		},
+		{
+			Label:       "match=",
+			Summary:     "Match array elements by key field",
+			Insert:      "match=id",
+			Placeholder: "id",
+		},
+	},
+	directive.As: {
+		{
+			Label:       "base=",
+			Summary:     "Set the baseline identity",
+			Insert:      "base=admin",
+			Placeholder: "admin",
+		},
+		{
+			Label:   anonymousIdentity,
+			Summary: "Run without credentials",
+		},
+	},
+	directive.Contract: {
+		{
+			Label:       "operation=",
+			Summary:     "Check against this operationId instead of matching method and path",
+			Insert:      "operation=getUser",
+			Placeholder: "getUser",
+		},
+		{Label: "off", Summary: "Skip the run-wide --contract check"},
+	},
+	directive.Snapshot: {
+		{
+			Label:       "ignore=",
+			Summary:     "Comma-separated JSON paths masked before comparing",
+			Insert:      "ignore=$.id",
+			Placeholder: "$.id",
+		},
 	},
 	directive.SSH: {
 		{
@@ -894,6 +977,9 @@ func (directiveSource) Provide(ctx Context, sc Scope) []Item {
 		if name == directive.Compare {
 			return compareItems(ctx, sc)
 		}
+		if name == directive.As {
+			return asItems(ctx, sc)
+		}
 		opts := directiveArgs[name]
 		if len(opts) == 0 {
 			return nil
@@ -926,6 +1012,18 @@ func compareItems(ctx Context, sc Scope) []Item {
 	return filter(opts, ctx.Query)
 }
 
+// anonymousIdentity mirrors restfile.AnonymousIdentity.
+const anonymousIdentity = "anonymous"
+
+func asItems(ctx Context, sc Scope) []Item {
+	ids := environmentItems(sc.Profiles.Auth, "auth profile")
+	if ctx.ArgKey == "base" || ctx.ArgKey == "baseline" {
+		ids = append(ids, Item{Label: anonymousIdentity, Summary: "identity"})
+		return filter(ids, ctx.Query)
+	}
+	return filter(slices.Concat(directiveArgs[directive.As], ids), ctx.Query)
+}
+
 func profileItems(name directive.Name, profiles ProfileSet) []Item {
 	var names []string
 	switch name {
@@ -170,13 +170,13 @@ func TestCompareArgsIncludeEnvironments(t *testing.T) {
 	sc := Scope{Environments: []string{"dev", "prod"}}
 	ctx := Context{Kind: KindDirectiveArg, Directive: "compare", Query: ""}
 	items := directiveSource{}.Provide(ctx, sc)
-	for _, label := range []string{"base=", "baseline=", "group=", "dev", "prod"} {
+	for _, label := range []string{"base=", "baseline=", "group=", "ignore=", "match=", "dev", "prod"} {
 		if !contains(items, label) {
 			t.Fatalf("compare suggestions missing %q: %v", label, items)
 		}
 	}
 	// Static options must not be mutated by appended environments.
-	if got := len(directiveArgs[directive.Compare]); got != 3 {
+	if got := len(directiveArgs[directive.Compare]); got != 5 {
 		t.Fatalf("compare static args mutated, len = %d", got)
 	}
 }
@@ -223,6 +223,28 @@ func TestCompareArgsIncludeGroupsAndProfiles(t *testing.T) {
 	}
 }
 
+func TestAsArgsOfferNamedAuthProfiles(t *testing.T) {
+	sc := Scope{Profiles: ProfileSet{Auth: []string{"admin", "viewer"}}}
+	items := directiveSource{}.Provide(Context{Kind: KindDirectiveArg, Directive: "as"}, sc)
+	for _, label := range []string{"base=", "anonymous", "admin", "viewer"} {
+		if !contains(items, label) {
+			t.Fatalf("@as suggestions missing %q: %v", label, items)
+		}
+	}
+	if got := len(directiveArgs[directive.As]); got != 2 {
+		t.Fatalf("@as static args mutated, len = %d", got)
+	}
+
+	bases := directiveSource{}.Provide(Context{
+		Kind:      KindDirectiveArg,
+		Directive: "as",
+		ArgKey:    "base",
+	}, sc)
+	if !contains(bases, "viewer") || contains(bases, "base=") {
+		t.Fatalf("unexpected baseline suggestions: %v", bases)
+	}
+}
+
 func TestUseValueOffersProfileNames(t *testing.T) {
 	sc := Scope{
 		Profiles: ProfileSet{
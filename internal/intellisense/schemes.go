@@ -8,6 +8,7 @@ var schemes = []Item{
 	{Label: "http://", Summary: "HTTP"},
 	{Label: "wss://", Summary: "WebSocket over TLS"},
 	{Label: "ws://", Summary: "WebSocket"},
+	{Label: "unix://", Summary: "HTTP over a Unix domain socket"},
 }
 
 type schemeSource struct{}
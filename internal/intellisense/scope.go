@@ -17,4 +17,6 @@ type ProfileSet struct {
 	Patch []string
 	SSH   []string
 	K8s   []string
+	// Auth holds only named @auth profiles; unnamed ones cannot be selected.
+	Auth []string
 }
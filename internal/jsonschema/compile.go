@@ -0,0 +1,667 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type node struct {
+	// boolean is set for the true and false schemas, which have no keywords.
+	boolean *bool
+
+	ref *node
+
+	types    []string
+	enum     []any
+	hasEnum  bool
+	constVal any
+	hasConst bool
+
+	multipleOf       *float64
+	maximum          *float64
+	exclusiveMaximum *float64
+	minimum          *float64
+	exclusiveMinimum *float64
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+	minContains *int
+	maxContains *int
+	prefixItems []*node
+	items       *node
+	contains    *node
+	unevalItems *node
+
+	minProperties     *int
+	maxProperties     *int
+	required          []string
+	dependentRequired map[string][]string
+	dependentSchemas  map[string]*node
+	properties        map[string]*node
+	patternProps      []patternNode
+	additional        *node
+	propertyNames     *node
+	unevalProps       *node
+
+	allOf []*node
+	anyOf []*node
+	oneOf []*node
+	not   *node
+	ifN   *node
+	thenN *node
+	elseN *node
+}
+
+type patternNode struct {
+	re *regexp.Regexp
+	n  *node
+}
+
+// loc is a schema location: a resource URI and a JSON pointer within it.
+type loc struct {
+	res string
+	ptr string
+}
+
+type compiler struct {
+	read ReadFunc
+	// resources holds every document and embedded $id resource by URI.
+	resources map[string]any
+	// alias maps a file URI to the $id its document declares.
+	alias map[string]string
+	// files maps such an $id back to its file.
+	files   map[string]string
+	anchors map[string]loc
+	nodes   map[string]*node
+}
+
+func newCompiler(read ReadFunc) *compiler {
+	return &compiler{
+		read:      read,
+		resources: make(map[string]any),
+		alias:     make(map[string]string),
+		files:     make(map[string]string),
+		anchors:   make(map[string]loc),
+		nodes:     make(map[string]*node),
+	}
+}
+
+var errUnknownResource = errors.New("unknown resource")
+
+// register records a document and every resource and anchor inside it.
+func (c *compiler) register(uri string, doc any) {
+	c.resources[uri] = doc
+	res := uri
+	if m, ok := doc.(map[string]any); ok {
+		if id, ok := m["$id"].(string); ok && id != "" {
+			if r, err := resolveURI(uri, id); err == nil {
+				r = stripFragment(r)
+				if r != uri {
+					c.resources[r] = doc
+					c.alias[uri] = r
+					c.files[r] = uri
+					res = r
+				}
+			}
+		}
+	}
+	c.scan(doc, res, "")
+}
+
+// skipScan lists keywords whose values are data, not schemas.
+var skipScan = map[string]bool{
+	"enum":     true,
+	"const":    true,
+	"default":  true,
+	"examples": true,
+}
+
+func (c *compiler) scan(v any, res, ptr string) {
+	switch x := v.(type) {
+	case map[string]any:
+		if id, ok := x["$id"].(string); ok && id != "" && ptr != "" {
+			if r, err := resolveURI(res, id); err == nil {
+				res, ptr = stripFragment(r), ""
+				c.resources[res] = x
+			}
+		}
+		for _, kw := range []string{"$anchor", "$dynamicAnchor"} {
+			if a, ok := x[kw].(string); ok && a != "" {
+				c.anchors[res+"#"+a] = loc{res: res, ptr: ptr}
+			}
+		}
+		for k, child := range x {
+			if !skipScan[k] {
+				c.scan(child, res, ptr+"/"+escapePointer(k))
+			}
+		}
+	case []any:
+		for i, child := range x {
+			c.scan(child, res, ptr+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func (c *compiler) canon(uri string) string {
+	if a, ok := c.alias[uri]; ok {
+		return a
+	}
+	return uri
+}
+
+func (c *compiler) loadFile(uri string) (any, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return nil, fmt.Errorf("%w %s: only local references are supported", errUnknownResource, uri)
+	}
+	if c.read == nil {
+		return nil, fmt.Errorf("%w %s: file access not available", errUnknownResource, uri)
+	}
+	path := filePath(u)
+	data, err := c.read(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	doc, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	c.register(uri, doc)
+	return doc, nil
+}
+
+// resolve compiles the schema an absolute URI points at, loading its file
+// when no known resource has that URI.
+func (c *compiler) resolve(uri string) (*node, error) {
+	base, frag, _ := strings.Cut(uri, "#")
+	base = c.canon(base)
+	if _, ok := c.resources[base]; !ok {
+		if _, err := c.loadFile(base); err != nil {
+			return nil, err
+		}
+		base = c.canon(base)
+	}
+	frag, err := url.PathUnescape(frag)
+	if err != nil {
+		return nil, fmt.Errorf("$ref %s: %w", uri, err)
+	}
+	at := loc{res: base}
+	switch {
+	case frag == "":
+	case strings.HasPrefix(frag, "/"):
+		at.ptr = frag
+	default:
+		l, ok := c.anchors[base+"#"+frag]
+		if !ok {
+			return nil, fmt.Errorf("$ref %s: no anchor %q", uri, frag)
+		}
+		at = l
+	}
+	v, ok := pointer(c.resources[at.res], at.ptr)
+	if !ok {
+		return nil, fmt.Errorf("$ref %s: nothing at %s", uri, at.ptr)
+	}
+	return c.compile(v, at.res, at.ptr)
+}
+
+func (c *compiler) compile(v any, res, ptr string) (*node, error) {
+	key := res + "#" + ptr
+	if n, ok := c.nodes[key]; ok {
+		return n, nil
+	}
+	n := &node{}
+	c.nodes[key] = n
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s: a schema must be an object or boolean", where(ptr))
+		}
+		n.boolean = &b
+		return n, nil
+	}
+	if id, ok := m["$id"].(string); ok && id != "" && ptr != "" {
+		if r, err := resolveURI(res, id); err == nil {
+			res, ptr = stripFragment(r), ""
+			c.nodes[res+"#"] = n
+		}
+	}
+	kc := &kwCompiler{c: c, m: m, res: res, ptr: ptr}
+	kc.compile(n)
+	if kc.err != nil {
+		return nil, kc.err
+	}
+	return n, nil
+}
+
+// kwCompiler reads the keywords of one schema object and keeps the first
+// error, so each keyword reads as a single line.
+type kwCompiler struct {
+	c   *compiler
+	m   map[string]any
+	res string
+	ptr string
+	err error
+}
+
+func (k *kwCompiler) fail(kw, format string, args ...any) {
+	if k.err == nil {
+		k.err = fmt.Errorf("%s: %s", where(k.ptr+"/"+kw), fmt.Sprintf(format, args...))
+	}
+}
+
+func (k *kwCompiler) compile(n *node) {
+	n.ref = k.ref("$ref")
+	if n.ref == nil {
+		// Dynamic scopes are not tracked; a $dynamicRef resolves like $ref,
+		// which matches the common case of a schema extending itself.
+		n.ref = k.ref("$dynamicRef")
+	}
+
+	n.types = k.types()
+	if e, ok := k.m["enum"]; ok {
+		list, ok := e.([]any)
+		if !ok {
+			k.fail("enum", "must be an array")
+		}
+		n.enum, n.hasEnum = list, true
+	}
+	n.constVal, n.hasConst = k.m["const"]
+
+	n.multipleOf = k.num("multipleOf")
+	if n.multipleOf != nil && *n.multipleOf <= 0 {
+		k.fail("multipleOf", "must be greater than 0")
+	}
+	n.maximum = k.num("maximum")
+	n.exclusiveMaximum = k.num("exclusiveMaximum")
+	n.minimum = k.num("minimum")
+	n.exclusiveMinimum = k.num("exclusiveMinimum")
+
+	n.minLength = k.count("minLength")
+	n.maxLength = k.count("maxLength")
+	n.pattern = k.regex("pattern")
+
+	n.minItems = k.count("minItems")
+	n.maxItems = k.count("maxItems")
+	n.uniqueItems = k.bool("uniqueItems")
+	n.minContains = k.count("minContains")
+	n.maxContains = k.count("maxContains")
+	if _, ok := k.m["items"].([]any); ok {
+		// Draft 7 tuples: an items array, then additionalItems for the rest.
+		n.prefixItems = k.list("items")
+		n.items = k.sub("additionalItems")
+	} else {
+		n.prefixItems = k.list("prefixItems")
+		n.items = k.sub("items")
+	}
+	n.contains = k.sub("contains")
+	n.unevalItems = k.sub("unevaluatedItems")
+
+	n.minProperties = k.count("minProperties")
+	n.maxProperties = k.count("maxProperties")
+	n.required = k.strings("required")
+	n.dependentRequired = k.stringsMap("dependentRequired")
+	n.dependentSchemas, _ = k.subMap("dependentSchemas")
+	n.properties, _ = k.subMap("properties")
+	n.patternProps = k.patterns("patternProperties")
+	n.additional = k.sub("additionalProperties")
+	n.propertyNames = k.sub("propertyNames")
+	n.unevalProps = k.sub("unevaluatedProperties")
+
+	n.allOf = k.list("allOf")
+	n.anyOf = k.list("anyOf")
+	n.oneOf = k.list("oneOf")
+	n.not = k.sub("not")
+	n.ifN = k.sub("if")
+	n.thenN = k.sub("then")
+	n.elseN = k.sub("else")
+}
+
+func (k *kwCompiler) ref(kw string) *node {
+	raw, ok := k.m[kw]
+	if !ok {
+		return nil
+	}
+	ref, ok := raw.(string)
+	if !ok {
+		k.fail(kw, "must be a string")
+		return nil
+	}
+	uri, err := resolveURI(k.res, ref)
+	if err != nil {
+		k.fail(kw, "%v", err)
+		return nil
+	}
+	n, err := k.c.resolve(uri)
+	if errors.Is(err, errUnknownResource) {
+		// A document with an http $id usually sits next to the files its
+		// relative refs name, so try them beside the file itself.
+		if file, ok := k.c.files[stripFragment(k.res)]; ok && !isAbsURI(ref) {
+			if alt, aerr := resolveURI(file, ref); aerr == nil {
+				n, err = k.c.resolve(alt)
+			}
+		}
+	}
+	if err != nil {
+		if k.err == nil {
+			k.err = err
+		}
+		return nil
+	}
+	return n
+}
+
+func (k *kwCompiler) child(v any, kw string) *node {
+	if k.err != nil {
+		return nil
+	}
+	n, err := k.c.compile(v, k.res, k.ptr+"/"+kw)
+	if err != nil {
+		k.err = err
+		return nil
+	}
+	return n
+}
+
+func (k *kwCompiler) sub(kw string) *node {
+	v, ok := k.m[kw]
+	if !ok {
+		return nil
+	}
+	return k.child(v, kw)
+}
+
+func (k *kwCompiler) list(kw string) []*node {
+	v, ok := k.m[kw]
+	if !ok {
+		return nil
+	}
+	items, ok := v.([]any)
+	if !ok || len(items) == 0 {
+		k.fail(kw, "must be a non-empty array of schemas")
+		return nil
+	}
+	out := make([]*node, 0, len(items))
+	for i, item := range items {
+		if n := k.child(item, kw+"/"+strconv.Itoa(i)); n != nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func (k *kwCompiler) subMap(kw string) (map[string]*node, []string) {
+	v, ok := k.m[kw]
+	if !ok {
+		return nil, nil
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		k.fail(kw, "must be an object of schemas")
+		return nil, nil
+	}
+	names := sortedKeys(obj)
+	out := make(map[string]*node, len(obj))
+	for _, name := range names {
+		if n := k.child(obj[name], kw+"/"+escapePointer(name)); n != nil {
+			out[name] = n
+		}
+	}
+	return out, names
+}
+
+func (k *kwCompiler) patterns(kw string) []patternNode {
+	v, ok := k.m[kw]
+	if !ok {
+		return nil
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		k.fail(kw, "must be an object of schemas")
+		return nil
+	}
+	var out []patternNode
+	for _, src := range sortedKeys(obj) {
+		re, err := regexp.Compile(src)
+		if err != nil {
+			k.fail(kw, "pattern %q is not supported: %v", src, err)
+			return nil
+		}
+		if n := k.child(obj[src], kw+"/"+escapePointer(src)); n != nil {
+			out = append(out, patternNode{re: re, n: n})
+		}
+	}
+	return out
+}
+
+func (k *kwCompiler) types() []string {
+	switch x := k.m["type"].(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{x}
+	case []any:
+		out := make([]string, 0, len(x))
+		for _, t := range x {
+			s, ok := t.(string)
+			if !ok {
+				k.fail("type", "must hold type names")
+				return nil
+			}
+			out = append(out, s)
+		}
+		return out
+	default:
+		k.fail("type", "must be a type name or an array of them")
+		return nil
+	}
+}
+
+func (k *kwCompiler) num(kw string) *float64 {
+	v, ok := k.m[kw]
+	if !ok {
+		return nil
+	}
+	f, ok := toFloat(v)
+	if !ok {
+		k.fail(kw, "must be a number")
+		return nil
+	}
+	return &f
+}
+
+func (k *kwCompiler) count(kw string) *int {
+	f := k.num(kw)
+	if f == nil {
+		return nil
+	}
+	if *f < 0 || *f != math.Trunc(*f) {
+		k.fail(kw, "must be a non-negative integer")
+		return nil
+	}
+	n := int(*f)
+	return &n
+}
+
+func (k *kwCompiler) bool(kw string) bool {
+	v, ok := k.m[kw]
+	if !ok {
+		return false
+	}
+	b, ok := v.(bool)
+	if !ok {
+		k.fail(kw, "must be a boolean")
+	}
+	return b
+}
+
+func (k *kwCompiler) regex(kw string) *regexp.Regexp {
+	v, ok := k.m[kw]
+	if !ok {
+		return nil
+	}
+	src, ok := v.(string)
+	if !ok {
+		k.fail(kw, "must be a string")
+		return nil
+	}
+	re, err := regexp.Compile(src)
+	if err != nil {
+		// Go regexps have no lookarounds or backreferences; failing here
+		// beats passing every value unchecked.
+		k.fail(kw, "%q is not supported: %v", src, err)
+		return nil
+	}
+	return re
+}
+
+func (k *kwCompiler) strings(kw string) []string {
+	v, ok := k.m[kw]
+	if !ok {
+		return nil
+	}
+	out, ok := stringList(v)
+	if !ok {
+		k.fail(kw, "must be an array of strings")
+	}
+	return out
+}
+
+func (k *kwCompiler) stringsMap(kw string) map[string][]string {
+	v, ok := k.m[kw]
+	if !ok {
+		return nil
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		k.fail(kw, "must be an object of string arrays")
+		return nil
+	}
+	out := make(map[string][]string, len(obj))
+	for name, raw := range obj {
+		list, ok := stringList(raw)
+		if !ok {
+			k.fail(kw, "must be an object of string arrays")
+			return nil
+		}
+		out[name] = list
+	}
+	return out
+}
+
+func stringList(v any) ([]string, bool) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, s)
+	}
+	return out, true
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pointer walks a JSON pointer through a decoded document.
+func pointer(doc any, ptr string) (any, bool) {
+	if ptr == "" {
+		return doc, true
+	}
+	cur := doc
+	for _, tok := range strings.Split(ptr[1:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch x := cur.(type) {
+		case map[string]any:
+			v, ok := x[tok]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, false
+			}
+			cur = x[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func escapePointer(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+func where(ptr string) string {
+	if ptr == "" {
+		return "schema"
+	}
+	return "schema " + ptr
+}
+
+func fileURI(abs string) string {
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
+
+func filePath(u *url.URL) string {
+	p := u.Path
+	// file:///C:/schemas/user.json keeps its drive letter on Windows.
+	if len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+func resolveURI(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+func isAbsURI(ref string) bool {
+	u, err := url.Parse(ref)
+	return err == nil && u.IsAbs()
+}
+
+func stripFragment(uri string) string {
+	base, _, _ := strings.Cut(uri, "#")
+	return base
+}
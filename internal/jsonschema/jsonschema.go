@@ -0,0 +1,110 @@
+// Package jsonschema validates decoded JSON values against JSON Schema draft
+// 2020-12. References are resolved locally: JSON pointers, $anchor names,
+// embedded $id resources, and other schema files relative to the one that
+// refers to them. Nothing is fetched over the network, and format is treated
+// as an annotation.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ReadFunc reads a schema file. A nil ReadFunc leaves file references
+// unresolved.
+type ReadFunc func(path string) ([]byte, error)
+
+// Error is one way a value breaks a schema. Path is a JSON pointer into the
+// value, empty for the value itself.
+type Error struct {
+	Path    string
+	Message string
+}
+
+func (e Error) String() string {
+	if e.Path == "" {
+		return "(root): " + e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Schema is a compiled schema with every reference resolved. It is safe for
+// concurrent use.
+type Schema struct {
+	root *node
+}
+
+// Load reads and compiles the schema file at path.
+func Load(path string, read ReadFunc) (*Schema, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	c := newCompiler(read)
+	uri := fileURI(abs)
+	if _, err := c.loadFile(uri); err != nil {
+		return nil, err
+	}
+	root, err := c.resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// Parse compiles a schema held in memory. Relative file references resolve
+// against dir.
+func Parse(data []byte, dir string, read ReadFunc) (*Schema, error) {
+	doc, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(doc, dir, read)
+}
+
+// Compile compiles an already decoded schema. Relative file references
+// resolve against dir.
+func Compile(doc any, dir string, read ReadFunc) (*Schema, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := newCompiler(read)
+	// The trailing slash makes the directory the base of relative refs.
+	uri := strings.TrimSuffix(fileURI(abs), "/") + "/"
+	c.register(uri, doc)
+	root, err := c.resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// maxErrors keeps one badly broken value from producing a flood of errors.
+const maxErrors = 100
+
+// Validate checks v, as decoded by encoding/json or exported from a script
+// runtime, and returns every error found in instance order.
+func (s *Schema) Validate(v any) []Error {
+	if s == nil || s.root == nil {
+		return nil
+	}
+	vl := &validator{}
+	vl.check(s.root, v, "")
+	return vl.errs
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the schema")
+	}
+	return v, nil
+}
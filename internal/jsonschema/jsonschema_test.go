@@ -0,0 +1,258 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, src string) *Schema {
+	t.Helper()
+	s, err := Parse([]byte(src), t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	return s
+}
+
+func mustDecode(t *testing.T, src string) any {
+	t.Helper()
+	v, err := decode([]byte(src))
+	if err != nil {
+		t.Fatalf("decode value: %v", err)
+	}
+	return v
+}
+
+func errorLines(errs []Error) string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func assertErrors(t *testing.T, s *Schema, value string, want ...string) {
+	t.Helper()
+	got := errorLines(s.Validate(mustDecode(t, value)))
+	if got != strings.Join(want, "\n") {
+		t.Fatalf("errors for %s:\n%s\nwant:\n%s", value, got, strings.Join(want, "\n"))
+	}
+}
+
+const orderSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["id", "items"],
+  "properties": {
+    "id": {"type": "integer", "minimum": 1},
+    "status": {"enum": ["open", "paid"]},
+    "items": {
+      "type": "array",
+      "minItems": 1,
+      "items": {"$ref": "#/$defs/item"}
+    },
+    "note": {"type": ["string", "null"], "maxLength": 5}
+  },
+  "additionalProperties": false,
+  "$defs": {
+    "item": {
+      "type": "object",
+      "required": ["sku", "price"],
+      "properties": {
+        "sku": {"type": "string", "pattern": "^[A-Z]+-[0-9]+$"},
+        "price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01}
+      }
+    }
+  }
+}`
+
+func TestValidateReportsPaths(t *testing.T) {
+	s := mustParse(t, orderSchema)
+	assertErrors(t, s, `{"id": 3, "status": "open", "items": [{"sku": "AB-1", "price": 9.99}], "note": null}`)
+	assertErrors(t, s,
+		`{"id": 0, "status": "lost", "items": [{"sku": "AB-1", "price": 1}, {"sku": "x", "price": 0}, {"price": 1.005}, {"sku": "C-1", "price": "9"}], "note": "too long", "extra": 1}`,
+		`(root): unexpected property "extra"`,
+		`/id: must be >= 1`,
+		`/items/1/price: must be > 0`,
+		`/items/1/sku: must match pattern ^[A-Z]+-[0-9]+$`,
+		`/items/2: missing required property "sku"`,
+		`/items/2/price: must be a multiple of 0.01`,
+		`/items/3/price: expected number`,
+		`/note: must be at most 5 characters`,
+		`/status: must be one of "open", "paid"`,
+	)
+	assertErrors(t, s, `[]`, `(root): expected object`)
+	assertErrors(t, s, `{"items": []}`,
+		`(root): missing required property "id"`,
+		`/items: must have at least 1 items`,
+	)
+}
+
+func TestValidateApplicators(t *testing.T) {
+	s := mustParse(t, `{
+	  "oneOf": [
+	    {"type": "object", "properties": {"kind": {"const": "card"}}, "required": ["kind", "last4"]},
+	    {"type": "object", "properties": {"kind": {"const": "bank"}}, "required": ["kind", "iban"]}
+	  ],
+	  "if": {"properties": {"kind": {"const": "card"}}},
+	  "then": {"properties": {"last4": {"type": "string", "minLength": 4, "maxLength": 4}}},
+	  "not": {"required": ["password"]},
+	  "dependentRequired": {"iban": ["bic"]}
+	}`)
+	assertErrors(t, s, `{"kind": "card", "last4": "4242"}`)
+	assertErrors(t, s, `{"kind": "card", "last4": "42"}`, `/last4: must be at least 4 characters`)
+	assertErrors(t, s, `{"kind": "bank", "iban": "DE00"}`, `(root): property "iban" requires "bic"`)
+	assertErrors(t, s, `{"kind": "cash"}`, `(root): does not match any of the oneOf schemas`)
+	assertErrors(t, s, `{"kind": "card", "last4": "4242", "password": "p"}`,
+		`(root): must not match the not schema`,
+	)
+
+	s = mustParse(t, `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`)
+	assertErrors(t, s, `-1`)
+	assertErrors(t, s, `1`, `(root): matches 2 of the oneOf schemas, expected exactly one`)
+}
+
+func TestValidateArrays(t *testing.T) {
+	s := mustParse(t, `{
+	  "type": "array",
+	  "prefixItems": [{"type": "string"}, {"type": "integer"}],
+	  "items": false,
+	  "uniqueItems": true
+	}`)
+	assertErrors(t, s, `["a", 1]`)
+	assertErrors(t, s, `[1, 1.5, true]`,
+		`/0: expected string`,
+		`/1: expected integer`,
+		`/2: unexpected item, expected at most 2`,
+	)
+
+	s = mustParse(t, `{"contains": {"const": "admin"}, "minContains": 2, "uniqueItems": true}`)
+	assertErrors(t, s, `["admin", "user"]`,
+		`(root): must contain at least 2 items matching the contains schema, found 1`,
+	)
+	assertErrors(t, s, `[{"a": 1, "b": [2.0]}, {"b": [2], "a": 1}]`,
+		`(root): items 0 and 1 are equal, expected unique items`,
+		`(root): must contain at least 2 items matching the contains schema, found 0`,
+	)
+}
+
+func TestValidateUnevaluatedProperties(t *testing.T) {
+	s := mustParse(t, `{
+	  "allOf": [{"properties": {"id": {"type": "integer"}}}],
+	  "anyOf": [{"properties": {"name": {"type": "string"}}}, {"required": ["email"]}],
+	  "patternProperties": {"^x-": true},
+	  "unevaluatedProperties": false
+	}`)
+	assertErrors(t, s, `{"id": 1, "name": "a", "x-trace": "t"}`)
+	assertErrors(t, s, `{"id": 1, "name": "a", "email": "e", "role": "r"}`,
+		`(root): unexpected property "email"`,
+		`(root): unexpected property "role"`,
+	)
+}
+
+func TestValidateAnchorsAndRecursion(t *testing.T) {
+	s := mustParse(t, `{
+	  "$ref": "#node",
+	  "$defs": {
+	    "node": {
+	      "$anchor": "node",
+	      "type": "object",
+	      "properties": {
+	        "value": {"type": "integer"},
+	        "children": {"type": "array", "items": {"$ref": "#node"}}
+	      }
+	    }
+	  }
+	}`)
+	assertErrors(t, s, `{"value": 1, "children": [{"value": 2, "children": [{"value": "3"}]}]}`,
+		`/children/0/children/0/value: expected integer`,
+	)
+
+	s = mustParse(t, `{"$ref": "#"}`)
+	if errs := s.Validate(1); len(errs) != 1 || errs[0].Message != "schema recursion is too deep" {
+		t.Fatalf("self reference: %v", errs)
+	}
+}
+
+func TestLoadResolvesFileRefs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	user := write("user.json", `{
+	  "$id": "https://example.com/schemas/user.json",
+	  "type": "object",
+	  "properties": {
+	    "address": {"$ref": "common/address.json"},
+	    "tags": {"$ref": "common/address.json#/$defs/tags"}
+	  }
+	}`)
+	write("common/address.json", `{
+	  "type": "object",
+	  "required": ["city"],
+	  "$defs": {"tags": {"type": "array", "items": {"type": "string"}}}
+	}`)
+
+	s, err := Load(user, os.ReadFile)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	assertErrors(t, s, `{"address": {}, "tags": ["a", 2]}`,
+		`/address: missing required property "city"`,
+		`/tags/1: expected string`,
+	)
+
+	if _, err := Load(user, nil); err == nil || !strings.Contains(err.Error(), "file access not available") {
+		t.Fatalf("load without reader: %v", err)
+	}
+	if _, err := Parse([]byte(`{"$ref": "missing.json"}`), dir, os.ReadFile); err == nil ||
+		!strings.Contains(err.Error(), "missing.json") {
+		t.Fatalf("missing file ref: %v", err)
+	}
+}
+
+func TestCompileRejectsBadSchemas(t *testing.T) {
+	tests := map[string]string{
+		`{"type": 3}`:                       "schema /type: must be a type name or an array of them",
+		`{"properties": {"a": 1}}`:          "schema /properties/a: a schema must be an object or boolean",
+		`{"pattern": "(?=x)"}`:              "schema /pattern:",
+		`{"minLength": -1}`:                 "schema /minLength: must be a non-negative integer",
+		`{"$ref": "#/$defs/missing"}`:       "nothing at /$defs/missing",
+		`{"$ref": "https://example.com/x"}`: "only local references are supported",
+	}
+	for src, want := range tests {
+		_, err := Parse([]byte(src), t.TempDir(), nil)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", src, err, want)
+		}
+	}
+}
+
+func TestValidateScriptValues(t *testing.T) {
+	// Script runtimes hand over int64 and float64 rather than json.Number.
+	var schema any
+	if err := json.Unmarshal([]byte(`{"type": "object", "properties": {"n": {"type": "integer", "enum": [1, 2]}}}`), &schema); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Compile(schema, t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := s.Validate(map[string]any{"n": int64(2)}); len(errs) != 0 {
+		t.Fatalf("int64 value: %v", errs)
+	}
+	if errs := s.Validate(map[string]any{"n": 2.5}); errorLines(errs) != "/n: expected integer" {
+		t.Fatalf("float value: %v", errs)
+	}
+}
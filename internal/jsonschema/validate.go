@@ -0,0 +1,478 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDepth stops a schema that refers to itself without consuming any of the
+// value, such as {"$ref": "#"}.
+const maxDepth = 256
+
+// evaluated holds the properties and items that applicators on one instance
+// location have looked at, which unevaluatedProperties and unevaluatedItems
+// exclude.
+type evaluated struct {
+	props map[string]bool
+	items map[int]bool
+}
+
+func (e *evaluated) prop(name string) {
+	if e.props == nil {
+		e.props = make(map[string]bool)
+	}
+	e.props[name] = true
+}
+
+func (e *evaluated) item(i int) {
+	if e.items == nil {
+		e.items = make(map[int]bool)
+	}
+	e.items[i] = true
+}
+
+func (e *evaluated) merge(o evaluated) {
+	for name := range o.props {
+		e.prop(name)
+	}
+	for i := range o.items {
+		e.item(i)
+	}
+}
+
+type validator struct {
+	errs  []Error
+	depth int
+}
+
+func (vl *validator) fail(path, format string, args ...any) {
+	if len(vl.errs) >= maxErrors {
+		return
+	}
+	vl.errs = append(vl.errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// sub checks v in a validator of its own, for branches that may fail without
+// the value failing.
+func (vl *validator) sub(n *node, v any, path string) (evaluated, bool) {
+	s := &validator{depth: vl.depth}
+	ev := s.check(n, v, path)
+	return ev, len(s.errs) == 0
+}
+
+func (vl *validator) check(n *node, v any, path string) evaluated {
+	var ev evaluated
+	if n == nil {
+		return ev
+	}
+	if vl.depth >= maxDepth {
+		vl.fail(path, "schema recursion is too deep")
+		return ev
+	}
+	vl.depth++
+	defer func() { vl.depth-- }()
+
+	if n.boolean != nil {
+		if !*n.boolean {
+			vl.fail(path, "is not allowed")
+		}
+		return ev
+	}
+	if n.ref != nil {
+		ev.merge(vl.check(n.ref, v, path))
+	}
+	if !vl.typeOK(n, v, path) {
+		return ev
+	}
+	if n.hasEnum && !slices.ContainsFunc(n.enum, func(e any) bool { return equal(e, v) }) {
+		vl.fail(path, "must be one of %s", valueList(n.enum))
+	}
+	if n.hasConst && !equal(n.constVal, v) {
+		vl.fail(path, "must equal %s", valueList([]any{n.constVal}))
+	}
+	switch x := v.(type) {
+	case string:
+		vl.string(n, x, path)
+	case []any:
+		ev.merge(vl.array(n, x, path))
+	case map[string]any:
+		ev.merge(vl.object(n, x, path))
+	default:
+		if f, ok := toFloat(v); ok {
+			vl.number(n, f, path)
+		}
+	}
+	ev.merge(vl.applicators(n, v, path))
+	vl.unevaluated(n, v, path, ev)
+	return ev
+}
+
+// typeOK reports a type mismatch once and skips the other keywords, which
+// would only restate it.
+func (vl *validator) typeOK(n *node, v any, path string) bool {
+	if len(n.types) == 0 {
+		return true
+	}
+	got := typeOf(v)
+	for _, t := range n.types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	vl.fail(path, "expected %s", strings.Join(n.types, " or "))
+	return false
+}
+
+func (vl *validator) number(n *node, f float64, path string) {
+	if n.multipleOf != nil && !isMultiple(f, *n.multipleOf) {
+		vl.fail(path, "must be a multiple of %s", formatFloat(*n.multipleOf))
+	}
+	if n.maximum != nil && f > *n.maximum {
+		vl.fail(path, "must be <= %s", formatFloat(*n.maximum))
+	}
+	if n.exclusiveMaximum != nil && f >= *n.exclusiveMaximum {
+		vl.fail(path, "must be < %s", formatFloat(*n.exclusiveMaximum))
+	}
+	if n.minimum != nil && f < *n.minimum {
+		vl.fail(path, "must be >= %s", formatFloat(*n.minimum))
+	}
+	if n.exclusiveMinimum != nil && f <= *n.exclusiveMinimum {
+		vl.fail(path, "must be > %s", formatFloat(*n.exclusiveMinimum))
+	}
+}
+
+func (vl *validator) string(n *node, s, path string) {
+	count := utf8.RuneCountInString(s)
+	if n.minLength != nil && count < *n.minLength {
+		vl.fail(path, "must be at least %d characters", *n.minLength)
+	}
+	if n.maxLength != nil && count > *n.maxLength {
+		vl.fail(path, "must be at most %d characters", *n.maxLength)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		vl.fail(path, "must match pattern %s", n.pattern)
+	}
+}
+
+func (vl *validator) array(n *node, arr []any, path string) evaluated {
+	var ev evaluated
+	if n.minItems != nil && len(arr) < *n.minItems {
+		vl.fail(path, "must have at least %d items", *n.minItems)
+	}
+	if n.maxItems != nil && len(arr) > *n.maxItems {
+		vl.fail(path, "must have at most %d items", *n.maxItems)
+	}
+	if n.uniqueItems {
+		if i, j, ok := duplicate(arr); ok {
+			vl.fail(path, "items %d and %d are equal, expected unique items", i, j)
+		}
+	}
+	for i, item := range arr {
+		p := path + "/" + strconv.Itoa(i)
+		switch {
+		case i < len(n.prefixItems):
+			vl.check(n.prefixItems[i], item, p)
+		case n.items != nil:
+			if isFalse(n.items) {
+				vl.fail(p, "unexpected item, expected at most %d", len(n.prefixItems))
+				continue
+			}
+			vl.check(n.items, item, p)
+		default:
+			continue
+		}
+		ev.item(i)
+	}
+	if n.contains == nil {
+		return ev
+	}
+	matched := 0
+	for i, item := range arr {
+		if _, ok := vl.sub(n.contains, item, path+"/"+strconv.Itoa(i)); ok {
+			matched++
+			ev.item(i)
+		}
+	}
+	least := 1
+	if n.minContains != nil {
+		least = *n.minContains
+	}
+	switch {
+	case matched < least && least == 1:
+		vl.fail(path, "must contain an item matching the contains schema")
+	case matched < least:
+		vl.fail(path, "must contain at least %d items matching the contains schema, found %d", least, matched)
+	case n.maxContains != nil && matched > *n.maxContains:
+		vl.fail(path, "must contain at most %d items matching the contains schema, found %d", *n.maxContains, matched)
+	}
+	return ev
+}
+
+func (vl *validator) object(n *node, obj map[string]any, path string) evaluated {
+	var ev evaluated
+	for _, name := range n.required {
+		if _, ok := obj[name]; !ok {
+			vl.fail(path, "missing required property %q", name)
+		}
+	}
+	if n.minProperties != nil && len(obj) < *n.minProperties {
+		vl.fail(path, "must have at least %d properties", *n.minProperties)
+	}
+	if n.maxProperties != nil && len(obj) > *n.maxProperties {
+		vl.fail(path, "must have at most %d properties", *n.maxProperties)
+	}
+	keys := sortedKeys(obj)
+	for _, name := range keys {
+		for _, dep := range n.dependentRequired[name] {
+			if _, ok := obj[dep]; !ok {
+				vl.fail(path, "property %q requires %q", name, dep)
+			}
+		}
+	}
+	for _, name := range keys {
+		if n.propertyNames != nil {
+			if _, ok := vl.sub(n.propertyNames, name, path); !ok {
+				vl.fail(path, "invalid property name %q", name)
+			}
+		}
+		p := path + "/" + escapePointer(name)
+		seen := false
+		if prop, ok := n.properties[name]; ok {
+			vl.check(prop, obj[name], p)
+			seen = true
+		}
+		for _, pp := range n.patternProps {
+			if pp.re.MatchString(name) {
+				vl.check(pp.n, obj[name], p)
+				seen = true
+			}
+		}
+		if !seen && n.additional != nil {
+			if isFalse(n.additional) {
+				vl.fail(path, "unexpected property %q", name)
+			} else {
+				vl.check(n.additional, obj[name], p)
+			}
+			seen = true
+		}
+		if seen {
+			ev.prop(name)
+		}
+	}
+	for _, name := range keys {
+		if ds, ok := n.dependentSchemas[name]; ok {
+			ev.merge(vl.check(ds, obj, path))
+		}
+	}
+	return ev
+}
+
+func (vl *validator) applicators(n *node, v any, path string) evaluated {
+	var ev evaluated
+	for _, s := range n.allOf {
+		ev.merge(vl.check(s, v, path))
+	}
+	if len(n.anyOf) > 0 {
+		ok := false
+		for _, s := range n.anyOf {
+			// Every branch runs, since each passing one adds annotations.
+			if sev, pass := vl.sub(s, v, path); pass {
+				ev.merge(sev)
+				ok = true
+			}
+		}
+		if !ok {
+			vl.fail(path, "does not match any of the anyOf schemas")
+		}
+	}
+	if len(n.oneOf) > 0 {
+		passed := 0
+		for _, s := range n.oneOf {
+			if sev, pass := vl.sub(s, v, path); pass {
+				ev.merge(sev)
+				passed++
+			}
+		}
+		switch {
+		case passed == 0:
+			vl.fail(path, "does not match any of the oneOf schemas")
+		case passed > 1:
+			vl.fail(path, "matches %d of the oneOf schemas, expected exactly one", passed)
+		}
+	}
+	if n.not != nil {
+		if _, pass := vl.sub(n.not, v, path); pass {
+			vl.fail(path, "must not match the not schema")
+		}
+	}
+	if n.ifN != nil {
+		if iev, pass := vl.sub(n.ifN, v, path); pass {
+			ev.merge(iev)
+			ev.merge(vl.check(n.thenN, v, path))
+		} else {
+			ev.merge(vl.check(n.elseN, v, path))
+		}
+	}
+	return ev
+}
+
+func (vl *validator) unevaluated(n *node, v any, path string, ev evaluated) {
+	switch x := v.(type) {
+	case map[string]any:
+		if n.unevalProps == nil {
+			return
+		}
+		for _, name := range sortedKeys(x) {
+			if ev.props[name] {
+				continue
+			}
+			if isFalse(n.unevalProps) {
+				vl.fail(path, "unexpected property %q", name)
+				continue
+			}
+			vl.check(n.unevalProps, x[name], path+"/"+escapePointer(name))
+		}
+	case []any:
+		if n.unevalItems == nil {
+			return
+		}
+		for i, item := range x {
+			if ev.items[i] {
+				continue
+			}
+			p := path + "/" + strconv.Itoa(i)
+			if isFalse(n.unevalItems) {
+				vl.fail(p, "unexpected item")
+				continue
+			}
+			vl.check(n.unevalItems, item, p)
+		}
+	}
+}
+
+func isFalse(n *node) bool {
+	return n.boolean != nil && !*n.boolean
+}
+
+func typeOf(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		f, ok := toFloat(x)
+		if !ok {
+			return fmt.Sprintf("%T", v)
+		}
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+}
+
+func isMultiple(f, m float64) bool {
+	q := f / m
+	if math.IsInf(q, 0) || math.IsNaN(q) {
+		return false
+	}
+	// Decimal multiples such as 0.1 are not exact in binary floating point.
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
+func duplicate(arr []any) (int, int, bool) {
+	for i := range arr {
+		for j := i + 1; j < len(arr); j++ {
+			if equal(arr[i], arr[j]) {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// equal compares JSON values, treating numbers of any Go type by value so a
+// schema's 1 equals a script's 1.0.
+func equal(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	switch x := a.(type) {
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+func valueList(vals []any) string {
+	parts := make([]string, 0, len(vals))
+	for _, v := range vals {
+		b, err := json.Marshal(v)
+		if err != nil {
+			parts = append(parts, fmt.Sprint(v))
+			continue
+		}
+		parts = append(parts, string(b))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
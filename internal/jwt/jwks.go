@@ -0,0 +1,222 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	// Registers the hashes Verify looks up through crypto.Hash.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+var (
+	ErrNoKey     = errors.New("jwt: no matching key in the key set")
+	ErrSignature = errors.New("jwt: signature does not match")
+	ErrAlgorithm = errors.New("jwt: unsupported algorithm")
+)
+
+// Key is one entry of a JWK Set. Only the members needed for verification
+// are kept.
+type Key struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+type KeySet struct {
+	Keys []Key `json:"keys"`
+}
+
+// ParseKeySet accepts a JWK Set or a single bare JWK, since both are common
+// in files people keep next to their requests.
+func ParseKeySet(data []byte) (KeySet, error) {
+	var set KeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return KeySet{}, fmt.Errorf("jwt: parse jwks: %w", err)
+	}
+	if len(set.Keys) > 0 {
+		return set, nil
+	}
+	var key Key
+	if err := json.Unmarshal(data, &key); err == nil && key.Kty != "" {
+		return KeySet{Keys: []Key{key}}, nil
+	}
+	return KeySet{}, errors.New("jwt: jwks has no keys")
+}
+
+// Verify checks the token signature against the key set. Keys are matched by
+// kid when the token names one. Otherwise every key of a compatible type is
+// tried.
+func Verify(tok Token, set KeySet) (Key, error) {
+	alg := tok.Alg()
+	kind, hash, err := algorithm(alg)
+	if err != nil {
+		return Key{}, err
+	}
+	kid := tok.Kid()
+	var lastErr error = ErrNoKey
+	for _, key := range set.Keys {
+		if kid != "" && key.Kid != "" && key.Kid != kid {
+			continue
+		}
+		if key.Alg != "" && key.Alg != alg {
+			continue
+		}
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if !strings.EqualFold(key.Kty, kind) {
+			continue
+		}
+		err := verifyWith(key, alg, hash, []byte(tok.signed), tok.Signature)
+		if err == nil {
+			return key, nil
+		}
+		lastErr = err
+	}
+	return Key{}, lastErr
+}
+
+func algorithm(alg string) (string, crypto.Hash, error) {
+	switch alg {
+	case "HS256", "RS256", "PS256", "ES256":
+		return kty(alg), crypto.SHA256, nil
+	case "HS384", "RS384", "PS384", "ES384":
+		return kty(alg), crypto.SHA384, nil
+	case "HS512", "RS512", "PS512", "ES512":
+		return kty(alg), crypto.SHA512, nil
+	case "EdDSA":
+		return "OKP", 0, nil
+	default:
+		return "", 0, fmt.Errorf("%w %q", ErrAlgorithm, alg)
+	}
+}
+
+func kty(alg string) string {
+	switch alg[:2] {
+	case "HS":
+		return "oct"
+	case "ES":
+		return "EC"
+	default:
+		return "RSA"
+	}
+}
+
+func verifyWith(key Key, alg string, hash crypto.Hash, signed, sig []byte) error {
+	var digest []byte
+	if hash != 0 {
+		h := hash.New()
+		_, _ = h.Write(signed)
+		digest = h.Sum(nil)
+	}
+
+	switch alg[:2] {
+	case "HS":
+		secret, err := decodeB64(key.K)
+		if err != nil {
+			return fmt.Errorf("jwt: key %q: %w", key.Kid, err)
+		}
+		mac := hmac.New(hash.New, secret)
+		_, _ = mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), sig) {
+			return ErrSignature
+		}
+		return nil
+	case "RS", "PS":
+		pub, err := rsaKey(key)
+		if err != nil {
+			return err
+		}
+		if alg[0] == 'P' {
+			err = rsa.VerifyPSS(pub, hash, digest, sig, nil)
+		} else {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, sig)
+		}
+		if err != nil {
+			return ErrSignature
+		}
+		return nil
+	case "ES":
+		pub, err := ecKey(key)
+		if err != nil {
+			return err
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return ErrSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return ErrSignature
+		}
+		return nil
+	default:
+		if key.Crv != "Ed25519" {
+			return fmt.Errorf("%w: curve %q", ErrAlgorithm, key.Crv)
+		}
+		x, err := decodeB64(key.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return fmt.Errorf("jwt: key %q: invalid Ed25519 key", key.Kid)
+		}
+		if !ed25519.Verify(ed25519.PublicKey(x), signed, sig) {
+			return ErrSignature
+		}
+		return nil
+	}
+}
+
+func rsaKey(key Key) (*rsa.PublicKey, error) {
+	n, errN := decodeB64(key.N)
+	e, errE := decodeB64(key.E)
+	if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+		return nil, fmt.Errorf("jwt: key %q: invalid RSA key", key.Kid)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func ecKey(key Key) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch key.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("%w: curve %q", ErrAlgorithm, key.Crv)
+	}
+	x, errX := decodeB64(key.X)
+	y, errY := decodeB64(key.Y)
+	if errX != nil || errY != nil {
+		return nil, fmt.Errorf("jwt: key %q: invalid EC key", key.Kid)
+	}
+	// ecdsa.Verify rejects a point that is not on the curve, so it is not
+	// checked here.
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
@@ -0,0 +1,197 @@
+// Package jwt decodes and verifies JSON Web Tokens locally. It backs the TUI
+// token inspector and the RTS jwt helpers, so neither has to send a token to a
+// third-party decoder.
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed = errors.New("jwt: malformed token")
+	ErrEncrypted = errors.New("jwt: encrypted tokens (JWE) cannot be decoded")
+)
+
+// Token is a decoded JWS compact token. Decoding does not verify anything.
+type Token struct {
+	Raw       string
+	Header    map[string]any
+	Claims    map[string]any
+	Signature []byte
+	// signed is the "header.payload" prefix the signature covers.
+	signed string
+}
+
+func (t Token) Alg() string { return stringClaim(t.Header, "alg") }
+func (t Token) Kid() string { return stringClaim(t.Header, "kid") }
+
+// Decode splits and base64url-decodes a compact JWS. A "Bearer " prefix and
+// surrounding whitespace are ignored so header values can be passed as is.
+func Decode(raw string) (Token, error) {
+	raw = strings.TrimSpace(raw)
+	if scheme, rest, ok := strings.Cut(raw, " "); ok && strings.EqualFold(scheme, "bearer") {
+		raw = strings.TrimSpace(rest)
+	}
+	parts := strings.Split(raw, ".")
+	switch len(parts) {
+	case 3:
+	case 5:
+		return Token{}, ErrEncrypted
+	default:
+		return Token{}, ErrMalformed
+	}
+
+	tok := Token{Raw: raw, signed: parts[0] + "." + parts[1]}
+	if err := decodeSegment(parts[0], &tok.Header); err != nil {
+		return Token{}, fmt.Errorf("%w: header: %v", ErrMalformed, err)
+	}
+	if err := decodeSegment(parts[1], &tok.Claims); err != nil {
+		return Token{}, fmt.Errorf("%w: claims: %v", ErrMalformed, err)
+	}
+	sig, err := decodeB64(parts[2])
+	if err != nil {
+		return Token{}, fmt.Errorf("%w: signature: %v", ErrMalformed, err)
+	}
+	tok.Signature = sig
+	return tok, nil
+}
+
+func decodeSegment(seg string, dst *map[string]any) error {
+	data, err := decodeB64(seg)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return err
+	}
+	if *dst == nil {
+		return errors.New("not a JSON object")
+	}
+	return nil
+}
+
+// Tokens in the wild are meant to be unpadded base64url, but some issuers pad
+// them anyway.
+func decodeB64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// A JWS header always starts with {" which base64url-encodes to eyJ. Requiring
+// it for both the header and the payload keeps dotted identifiers and version
+// strings out of the results.
+var tokenPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{4,}\.eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*`)
+
+// Find returns the distinct strings in text that decode as tokens, in the
+// order they first appear.
+func Find(text string) []string {
+	var out []string
+	seen := make(map[string]struct{})
+	for _, m := range tokenPattern.FindAllString(text, -1) {
+		if _, ok := seen[m]; ok {
+			continue
+		}
+		seen[m] = struct{}{}
+		if _, err := Decode(m); err == nil {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Time reads a NumericDate claim. ok is false when the claim is absent or is
+// not a number.
+func (t Token) Time(claim string) (time.Time, bool) {
+	v, ok := t.Claims[claim].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return unixFloat(v), true
+}
+
+func unixFloat(f float64) time.Time {
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*float64(time.Second)))
+}
+
+// Level ranks a Finding. Problems make a token unusable now, warnings are
+// suspicious but do not.
+type Level int
+
+const (
+	LevelOK Level = iota
+	LevelWarn
+	LevelProblem
+)
+
+type Finding struct {
+	Level   Level
+	Message string
+}
+
+// Leeway is the clock skew tolerated before iat or nbf is reported as being in
+// the future.
+const Leeway = time.Minute
+
+// Check runs the exp, nbf and iat sanity checks against now.
+func (t Token) Check(now time.Time) []Finding {
+	var out []Finding
+	add := func(l Level, format string, args ...any) {
+		out = append(out, Finding{Level: l, Message: fmt.Sprintf(format, args...)})
+	}
+
+	exp, hasExp := t.Time("exp")
+	nbf, hasNbf := t.Time("nbf")
+	iat, hasIat := t.Time("iat")
+
+	switch {
+	case !hasExp:
+		if _, ok := t.Claims["exp"]; ok {
+			add(LevelProblem, "exp is not a numeric date")
+		} else {
+			add(LevelWarn, "no exp claim, token never expires")
+		}
+	case now.After(exp):
+		add(LevelProblem, "expired %s ago", Round(now.Sub(exp)))
+	default:
+		add(LevelOK, "expires in %s", Round(exp.Sub(now)))
+	}
+	if hasNbf && now.Add(Leeway).Before(nbf) {
+		add(LevelProblem, "not valid for another %s (nbf)", Round(nbf.Sub(now)))
+	}
+	if hasIat && now.Add(Leeway).Before(iat) {
+		add(LevelWarn, "issued %s in the future (iat), check clock skew", Round(iat.Sub(now)))
+	}
+	if hasIat && hasExp && !exp.After(iat) {
+		add(LevelProblem, "exp is not after iat")
+	}
+	if hasNbf && hasExp && !exp.After(nbf) {
+		add(LevelProblem, "exp is not after nbf")
+	}
+	if strings.EqualFold(t.Alg(), "none") {
+		add(LevelProblem, "alg is none, the token is unsigned")
+	}
+	return out
+}
+
+// Round trims a duration to a readable precision for countdowns.
+func Round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Hour:
+		return d.Round(time.Minute)
+	case d >= time.Minute:
+		return d.Round(time.Second)
+	default:
+		return d.Round(time.Millisecond)
+	}
+}
+
+func stringClaim(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
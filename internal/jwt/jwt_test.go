@@ -0,0 +1,190 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func signingInput(t *testing.T, header, claims map[string]any) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b64(h) + "." + b64(c)
+}
+
+func hsToken(t *testing.T, secret []byte, claims map[string]any) string {
+	t.Helper()
+	in := signingInput(t, map[string]any{"alg": "HS256", "typ": "JWT", "kid": "k1"}, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(in))
+	return in + "." + b64(mac.Sum(nil))
+}
+
+func TestDecodeReadsHeaderAndClaims(t *testing.T) {
+	raw := hsToken(t, []byte("s"), map[string]any{"sub": "42", "scope": "read write"})
+	tok, err := Decode("Bearer " + raw)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if tok.Alg() != "HS256" || tok.Kid() != "k1" {
+		t.Fatalf("header = %v", tok.Header)
+	}
+	if tok.Claims["scope"] != "read write" {
+		t.Fatalf("claims = %v", tok.Claims)
+	}
+	if tok.Raw != raw {
+		t.Fatalf("Raw kept the bearer prefix: %q", tok.Raw)
+	}
+}
+
+func TestDecodeRejectsNonTokens(t *testing.T) {
+	for _, raw := range []string{"", "a.b", "v1.2.3", "eyJhbGciOiJub25lIn0.bm90anNvbg.", "a.b.c.d.e"} {
+		if _, err := Decode(raw); err == nil {
+			t.Errorf("Decode(%q) succeeded", raw)
+		}
+	}
+	if _, err := Decode("a.b.c.d.e"); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("JWE error = %v, want ErrEncrypted", err)
+	}
+}
+
+func TestFindSkipsDuplicatesAndLookalikes(t *testing.T) {
+	raw := hsToken(t, []byte("s"), map[string]any{"sub": "1"})
+	text := `{"access_token":"` + raw + `","id":"eyJnotreally.eyJ.x","again":"` + raw + `"}`
+	got := Find(text)
+	if len(got) != 1 || got[0] != raw {
+		t.Fatalf("Find = %v", got)
+	}
+}
+
+func TestCheckReportsTimeClaims(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name   string
+		claims map[string]any
+		want   string
+		level  Level
+	}{
+		{"valid", map[string]any{"exp": 1_700_000_600}, "expires in 10m0s", LevelOK},
+		{"expired", map[string]any{"exp": 1_699_999_940}, "expired 1m0s ago", LevelProblem},
+		{"no exp", map[string]any{}, "never expires", LevelWarn},
+		{"nbf future", map[string]any{"exp": 1_700_009_000, "nbf": 1_700_000_300}, "nbf", LevelProblem},
+		{"iat future", map[string]any{"exp": 1_700_009_000, "iat": 1_700_000_300}, "iat", LevelWarn},
+		{"exp before iat", map[string]any{"exp": 1_700_000_100, "iat": 1_700_000_200}, "exp is not after iat", LevelProblem},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := Decode(hsToken(t, []byte("s"), tt.claims))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range tok.Check(now) {
+				if strings.Contains(f.Message, tt.want) {
+					if f.Level != tt.level {
+						t.Fatalf("%q level = %v, want %v", f.Message, f.Level, tt.level)
+					}
+					return
+				}
+			}
+			t.Fatalf("no finding mentions %q: %v", tt.want, tok.Check(now))
+		})
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	secret := []byte("top-secret")
+	raw := hsToken(t, secret, map[string]any{"sub": "1"})
+	tok, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := KeySet{Keys: []Key{{Kty: "oct", Kid: "k1", K: b64(secret)}}}
+	if _, err := Verify(tok, set); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	set.Keys[0].K = b64([]byte("other"))
+	if _, err := Verify(tok, set); !errors.Is(err, ErrSignature) {
+		t.Fatalf("Verify with wrong secret = %v, want ErrSignature", err)
+	}
+}
+
+func TestVerifyRSAAndECFromKeySetJSON(t *testing.T) {
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]any{
+		{
+			"kty": "RSA", "kid": "rsa", "use": "sig",
+			"n": b64(rsaPriv.N.Bytes()),
+			"e": b64(big.NewInt(int64(rsaPriv.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": "ec", "crv": "P-256",
+			"x": b64(ecPriv.X.FillBytes(make([]byte, 32))),
+			"y": b64(ecPriv.Y.FillBytes(make([]byte, 32))),
+		},
+	}})
+	set, err := ParseKeySet(jwks)
+	if err != nil {
+		t.Fatalf("ParseKeySet: %v", err)
+	}
+
+	in := signingInput(t, map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"sub": "1"})
+	sum := sha256.Sum256([]byte(in))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaPriv, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, _ := Decode(in + "." + b64(sig))
+	if key, err := Verify(tok, set); err != nil || key.Kid != "rsa" {
+		t.Fatalf("RS256 Verify = %v, %v", key.Kid, err)
+	}
+
+	in = signingInput(t, map[string]any{"alg": "ES256"}, map[string]any{"sub": "1"})
+	sum = sha256.Sum256([]byte(in))
+	r, s, err := ecdsa.Sign(rand.Reader, ecPriv, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	tok, _ = Decode(in + "." + b64(sig))
+	if key, err := Verify(tok, set); err != nil || key.Kid != "ec" {
+		t.Fatalf("ES256 Verify = %v, %v", key.Kid, err)
+	}
+}
+
+func TestVerifyRejectsNone(t *testing.T) {
+	in := signingInput(t, map[string]any{"alg": "none"}, map[string]any{"sub": "1"})
+	tok, err := Decode(in + ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(tok, KeySet{Keys: []Key{{Kty: "oct", K: "eA"}}}); !errors.Is(err, ErrAlgorithm) {
+		t.Fatalf("Verify(alg=none) = %v, want ErrAlgorithm", err)
+	}
+}
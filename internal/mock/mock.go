@@ -61,6 +61,9 @@ type Options struct {
 	// TLSCert and TLSKey are PEM file paths. When set, the server speaks HTTPS.
 	TLSCert string
 	TLSKey  string
+	// HTTP3 also serves HTTP/3 on the UDP port of the listen address and
+	// advertises it to TCP clients with Alt-Svc. It requires TLSCert.
+	HTTP3 bool
 }
 
 type Stats struct {
@@ -2,8 +2,12 @@ package mock
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,6 +16,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/http3"
 	"github.com/unkn0wn-root/resterm/internal/parser"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 )
@@ -406,6 +411,63 @@ new`)
 	<-server.Done()
 }
 
+func TestServerServesHTTP3AndAdvertisesIt(t *testing.T) {
+	handler := compileSource(t, `# @mock method=GET path=/value
+HTTP/1.1 200 OK
+
+ok`)
+	cert, key := writeTestCert(t)
+	server, err := Start("127.0.0.1:0", handler, Options{TLSCert: cert, TLSKey: key, HTTP3: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = server.Close(context.Background()) })
+
+	tlsCfg := &tls.Config{InsecureSkipVerify: true}
+	tcp := &http.Client{Timeout: 2 * time.Second, Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+	response := get(t, tcp, "https://"+server.Addr()+"/value", "")
+	_, port, _ := net.SplitHostPort(server.Addr())
+	if body := readBody(t, response); body != "ok" ||
+		response.Header.Get("Alt-Svc") != `h3=":`+port+`"; ma=86400` {
+		t.Fatalf("tcp response = %q headers=%v", body, response.Header)
+	}
+
+	h3 := &http3.Transport{TLSClientConfig: tlsCfg}
+	t.Cleanup(func() { _ = h3.Close() })
+	h3Client := &http.Client{Timeout: 2 * time.Second, Transport: h3}
+	response = get(t, h3Client, "https://"+server.Addr()+"/value", "")
+	if body := readBody(t, response); body != "ok" || response.ProtoMajor != 3 {
+		t.Fatalf("h3 response = %s %q", response.Proto, body)
+	}
+}
+
+func TestStartHTTP3RequiresTLS(t *testing.T) {
+	handler := compileSource(t, `# @mock method=GET path=/value
+HTTP/1.1 200 OK`)
+	if _, err := Start("127.0.0.1:0", handler, Options{HTTP3: true}); err == nil {
+		t.Fatal("expected HTTP/3 without a certificate to fail")
+	}
+}
+
+// writeTestCert saves the httptest certificate as PEM files for Options.
+func writeTestCert(t *testing.T) (string, string) {
+	t.Helper()
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	cert := ts.TLS.Certificates[0]
+	ts.Close()
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeFile(t, certFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})))
+	writeFile(t, keyFile, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})))
+	return certFile, keyFile
+}
+
 func compileSource(t *testing.T, source string) *Handler {
 	t.Helper()
 	handler, err := Compile([]*restfile.Document{parser.Parse("mocks.http", []byte(source))})
@@ -10,12 +10,16 @@ import (
 	"strings"
 	"sync/atomic"
 	"time"
+
+	"github.com/quic-go/quic-go/http3"
 )
 
 type Server struct {
 	opts    Options
 	handler atomic.Pointer[Handler]
 	srv     *http.Server
+	h3      *http3.Server
+	altSvc  string
 	addr    string
 	done    chan struct{}
 	err     error
@@ -95,6 +99,12 @@ func Start(addr string, handler *Handler, opts Options) (*Server, error) {
 			return s.srv.ServeTLS(ln, "", "")
 		}
 	}
+	if opts.HTTP3 {
+		if err := s.startHTTP3(); err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+	}
 
 	go func() {
 		err := serve(ln)
@@ -107,6 +117,29 @@ func Start(addr string, handler *Handler, opts Options) (*Server, error) {
 	return s, nil
 }
 
+// startHTTP3 listens on the UDP port matching the TCP listener, so the
+// Alt-Svc header only has to name the port.
+func (s *Server) startHTTP3() error {
+	if s.srv.TLSConfig == nil {
+		return errors.New("HTTP/3 requires a TLS certificate")
+	}
+	pc, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listen on udp %s: %w", s.addr, err)
+	}
+	_, port, _ := net.SplitHostPort(s.addr)
+	s.altSvc = fmt.Sprintf(`h3=":%s"; ma=86400`, port)
+	s.h3 = &http3.Server{
+		Handler:   s,
+		TLSConfig: http3.ConfigureTLSConfig(s.srv.TLSConfig.Clone()),
+	}
+	go func() {
+		_ = s.h3.Serve(pc)
+		_ = pc.Close()
+	}()
+	return nil
+}
+
 func (s *Server) Reload(handler *Handler) {
 	handler.setSequenceKeyLimit(s.opts.SequenceKeyLimit)
 	s.handler.Store(handler)
@@ -130,6 +163,9 @@ func (s *Server) RecordReload(err error) {
 }
 
 func (s *Server) Close(ctx context.Context) error {
+	if s.h3 != nil {
+		_ = s.h3.Close()
+	}
 	if err := s.srv.Shutdown(ctx); err != nil {
 		_ = s.srv.Close()
 		return err
@@ -138,6 +174,9 @@ func (s *Server) Close(ctx context.Context) error {
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.altSvc != "" && r.ProtoMajor < 3 {
+		w.Header().Set("Alt-Svc", s.altSvc)
+	}
 	if s.opts.EnableControl && s.handleControl(w, r) {
 		return
 	}
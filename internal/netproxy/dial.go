@@ -0,0 +1,148 @@
+package netproxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+const dialTimeout = 30 * time.Second
+
+// DialContext dials addr through the proxy Lookup picks for it, treating
+// the connection as https for the environment variables. It suits
+// transports such as gRPC that dial host:port without a URL.
+func (c Config) DialContext(ctx context.Context, _, addr string) (net.Conn, error) {
+	p, err := c.Lookup(&url.URL{Scheme: "https", Host: addr})
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		d := &net.Dialer{Timeout: dialTimeout}
+		return d.DialContext(ctx, "tcp", addr)
+	}
+	return Dial(ctx, p, addr)
+}
+
+// Dial connects to addr through the proxy p: a CONNECT tunnel for http and
+// https proxies, SOCKS5 for socks5 and socks5h. Both SOCKS schemes leave
+// name resolution to the proxy, as net/http does.
+func Dial(ctx context.Context, p *url.URL, addr string) (net.Conn, error) {
+	switch strings.ToLower(p.Scheme) {
+	case "socks5", "socks5h":
+		return dialSOCKS(ctx, p, addr)
+	case "http", "https":
+		return dialConnect(ctx, p, addr)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", p.Scheme)
+	}
+}
+
+func dialSOCKS(ctx context.Context, p *url.URL, addr string) (net.Conn, error) {
+	var auth *xproxy.Auth
+	if p.User != nil {
+		pass, _ := p.User.Password()
+		auth = &xproxy.Auth{User: p.User.Username(), Password: pass}
+	}
+	d, err := xproxy.SOCKS5("tcp", proxyAddr(p), auth, &net.Dialer{Timeout: dialTimeout})
+	if err != nil {
+		return nil, err
+	}
+	cd, ok := d.(xproxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("socks5 dialer does not take a context")
+	}
+	conn, err := cd.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("socks5 proxy %s: %w", p.Host, err)
+	}
+	return conn, nil
+}
+
+func dialConnect(ctx context.Context, p *url.URL, addr string) (_ net.Conn, err error) {
+	d := &net.Dialer{Timeout: dialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr(p))
+	if err != nil {
+		return nil, err
+	}
+	// Closing the connection is the only way to unblock the handshake once
+	// the context ends.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer func() {
+		if !stop() && err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	if strings.EqualFold(p.Scheme, "https") {
+		tc := tls.Client(conn, &tls.Config{ServerName: p.Hostname()})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("proxy %s tls handshake: %w", p.Host, err)
+		}
+		conn = tc
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if p.User != nil {
+		pass, _ := p.User.Password()
+		cred := base64.StdEncoding.EncodeToString([]byte(p.User.Username() + ":" + pass))
+		req.Header.Set("Proxy-Authorization", "Basic "+cred)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("proxy %s: %w", p.Host, err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s: %w", p.Host, err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy %s refused CONNECT %s: %s", p.Host, addr, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// proxyAddr adds the scheme's default port when the URL has none.
+func proxyAddr(p *url.URL) string {
+	if p.Port() != "" {
+		return p.Host
+	}
+	port := "80"
+	switch strings.ToLower(p.Scheme) {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(p.Hostname(), port)
+}
+
+// bufConn hands back bytes the proxy sent right after its CONNECT reply.
+type bufConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
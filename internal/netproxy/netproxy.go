@@ -0,0 +1,138 @@
+// Package netproxy picks the proxy for a request and dials through it. HTTP
+// hands the choice to net/http; gRPC has no proxy support of its own once a
+// custom dialer is set, so it dials through Dial.
+package netproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Direct is the rule target that skips the proxy.
+const Direct = "DIRECT"
+
+// Rule routes hosts matching Pattern through Proxy, or directly when Proxy
+// is empty. Pattern is a glob such as *.corp.internal; * alone matches every
+// host.
+type Rule struct {
+	Pattern string
+	Proxy   string
+}
+
+// Config is the proxy setup for one request. The first matching rule wins.
+// A host no rule matches uses URL, and without URL the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables. User and Password are
+// sent to any proxy whose URL carries no credentials of its own.
+type Config struct {
+	URL      string
+	Rules    []Rule
+	User     string
+	Password string
+}
+
+// Explicit reports whether the request names a proxy itself instead of
+// leaving it to the environment.
+func (c Config) Explicit() bool {
+	return c.URL != "" || len(c.Rules) > 0
+}
+
+// ParseURL reads a proxy URL. http, https, socks5 and socks5h are supported.
+func ParseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy url %q has no host", raw)
+	}
+	return u, nil
+}
+
+// ParseRules reads pattern=proxy pairs separated by commas or spaces, as in
+// "*.corp.internal=DIRECT, *=http://proxy.corp:3128".
+func ParseRules(val string) ([]Rule, error) {
+	fields := strings.FieldsFunc(val, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	out := make([]Rule, 0, len(fields))
+	for _, f := range fields {
+		pat, target, ok := strings.Cut(f, "=")
+		pat = strings.ToLower(strings.TrimSpace(pat))
+		if !ok || pat == "" {
+			return nil, fmt.Errorf("proxy rule %q is not pattern=proxy", f)
+		}
+		if _, err := path.Match(pat, ""); err != nil {
+			return nil, fmt.Errorf("proxy rule %q: bad pattern", f)
+		}
+		if strings.EqualFold(target, Direct) {
+			out = append(out, Rule{Pattern: pat})
+			continue
+		}
+		if _, err := ParseURL(target); err != nil {
+			return nil, fmt.Errorf("proxy rule %q: %w", f, err)
+		}
+		out = append(out, Rule{Pattern: pat, Proxy: target})
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no proxy rules in %q", val)
+	}
+	return out, nil
+}
+
+// Match reports whether host matches a rule pattern. Hosts compare without
+// case, and * also matches dots, so *.example.com covers every subdomain.
+func Match(pattern, host string) bool {
+	ok, _ := path.Match(pattern, strings.ToLower(host))
+	return ok
+}
+
+// Lookup returns the proxy for a request to u, or nil to connect directly.
+func (c Config) Lookup(u *url.URL) (*url.URL, error) {
+	if u == nil {
+		return nil, nil
+	}
+	host := u.Hostname()
+	for _, r := range c.Rules {
+		if !Match(r.Pattern, host) {
+			continue
+		}
+		if r.Proxy == "" {
+			return nil, nil
+		}
+		return c.parse(r.Proxy)
+	}
+	if c.URL != "" {
+		return c.parse(c.URL)
+	}
+	p, err := http.ProxyFromEnvironment(&http.Request{URL: u})
+	if err != nil || p == nil {
+		return p, err
+	}
+	return c.withAuth(p), nil
+}
+
+func (c Config) parse(raw string) (*url.URL, error) {
+	u, err := ParseURL(raw)
+	if err != nil {
+		return nil, err
+	}
+	return c.withAuth(u), nil
+}
+
+func (c Config) withAuth(u *url.URL) *url.URL {
+	if u.User != nil || c.User == "" {
+		return u
+	}
+	cp := *u
+	cp.User = url.UserPassword(c.User, c.Password)
+	return &cp
+}
@@ -12,6 +12,7 @@ const (
 	PhaseDNS      PhaseKind = "dns"
 	PhaseConnect  PhaseKind = "connect"
 	PhaseTLS      PhaseKind = "tls"
+	PhaseQUIC     PhaseKind = "quic"
 	PhaseReqHdrs  PhaseKind = "request_headers"
 	PhaseReqBody  PhaseKind = "request_body"
 	PhaseTTFB     PhaseKind = "ttfb"
@@ -23,6 +24,9 @@ type PhaseMeta struct {
 	Addr   string
 	Reused bool
 	Cached bool
+	// Override marks a DNS phase answered by a resolve or connect-to rule
+	// instead of a lookup.
+	Override bool
 }
 
 type Phase struct {
@@ -0,0 +1,373 @@
+// Package contract checks live HTTP responses against the operations of an
+// OpenAPI spec: the status code must be documented, required headers present,
+// and bodies must match the response schema for their content type.
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/openapi/parser"
+)
+
+// Target names the spec a response is checked against. Without an Operation
+// the response's method and path pick one. Optional targets let responses the
+// spec does not describe pass unchecked, which suits a run-wide spec.
+type Target struct {
+	Spec      string
+	Operation string
+	Optional  bool
+}
+
+type Response struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type Kind string
+
+const (
+	KindSpec        Kind = "spec"
+	KindOperation   Kind = "operation"
+	KindStatus      Kind = "status"
+	KindHeader      Kind = "header"
+	KindContentType Kind = "content-type"
+	KindBody        Kind = "body"
+)
+
+// Violation is one way a response breaks the contract. Path is a header name
+// for header violations and a JSON pointer into the body for body ones.
+type Violation struct {
+	Kind    Kind
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	switch {
+	case v.Kind == KindBody && v.Path != "":
+		return "body " + v.Path + ": " + v.Message
+	case v.Kind == KindBody:
+		return "body: " + v.Message
+	case v.Kind == KindHeader && v.Path != "":
+		return "header " + v.Path + ": " + v.Message
+	default:
+		return v.Message
+	}
+}
+
+type Result struct {
+	Spec string
+	// Operation is the operationId, or the method and path template when the
+	// operation has none.
+	Operation  string
+	Violations []Violation
+}
+
+func (r *Result) Failed() bool {
+	return r != nil && len(r.Violations) > 0
+}
+
+// Message summarizes the first violation, with a count of the rest.
+func (r *Result) Message() string {
+	if !r.Failed() {
+		return ""
+	}
+	msg := r.Violations[0].String()
+	if n := len(r.Violations) - 1; n > 0 {
+		msg += fmt.Sprintf(" (+%d more)", n)
+	}
+	if r.Operation != "" {
+		msg = r.Operation + ": " + msg
+	}
+	return msg
+}
+
+// maxViolations keeps one badly drifted body from flooding a report.
+const maxViolations = 25
+
+// Checker caches parsed specs and reloads one when its file changes. It is
+// safe for concurrent use.
+type Checker struct {
+	parse func(ctx context.Context, path string) (*model.Spec, error)
+
+	mu    sync.Mutex
+	specs map[string]loaded
+}
+
+type loaded struct {
+	spec *model.Spec
+	mod  time.Time
+	size int64
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		parse: func(ctx context.Context, path string) (*model.Spec, error) {
+			return parser.NewLoader().Parse(ctx, path, openapi.ParseOptions{})
+		},
+		specs: make(map[string]loaded),
+	}
+}
+
+// Check validates resp against t. It returns nil only for an Optional target
+// whose spec has no operation for the response's method and path.
+func (c *Checker) Check(ctx context.Context, t Target, resp Response) *Result {
+	res := &Result{Spec: t.Spec}
+	spec, err := c.load(ctx, t.Spec)
+	if err != nil {
+		res.add(KindSpec, "", fmt.Sprintf("load spec %s: %v", t.Spec, err))
+		return res
+	}
+
+	var op *model.Operation
+	if t.Operation != "" {
+		op = operationByID(spec, t.Operation)
+		if op == nil {
+			res.Operation = t.Operation
+			res.add(KindOperation, "", fmt.Sprintf("operation %q is not in the spec", t.Operation))
+			return res
+		}
+	} else {
+		op = operationFor(spec, resp.Method, resp.URL)
+		if op == nil {
+			if t.Optional {
+				return nil
+			}
+			res.add(KindOperation, "", fmt.Sprintf(
+				"no operation in the spec matches %s %s",
+				strings.ToUpper(resp.Method),
+				urlPath(resp.URL),
+			))
+			return res
+		}
+	}
+	res.Operation = label(op)
+	res.check(op, resp)
+	return res
+}
+
+func (c *Checker) load(ctx context.Context, path string) (*model.Spec, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if l, ok := c.specs[path]; ok && l.mod.Equal(info.ModTime()) && l.size == info.Size() {
+		return l.spec, nil
+	}
+	spec, err := c.parse(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	c.specs[path] = loaded{spec: spec, mod: info.ModTime(), size: info.Size()}
+	return spec, nil
+}
+
+func label(op *model.Operation) string {
+	if op.ID != "" {
+		return op.ID
+	}
+	return string(op.Method) + " " + op.Path
+}
+
+func (r *Result) add(kind Kind, path, msg string) {
+	if len(r.Violations) >= maxViolations {
+		return
+	}
+	r.Violations = append(r.Violations, Violation{Kind: kind, Path: path, Message: msg})
+}
+
+func (r *Result) check(op *model.Operation, resp Response) {
+	out, ok := pickResponse(op.Responses, resp.StatusCode)
+	if !ok {
+		codes := make([]string, 0, len(op.Responses))
+		for _, r := range op.Responses {
+			codes = append(codes, r.StatusCode)
+		}
+		r.add(KindStatus, "", fmt.Sprintf(
+			"status %d is not documented (expected %s)",
+			resp.StatusCode,
+			strings.Join(codes, ", "),
+		))
+		return
+	}
+	r.headers(out.Headers, resp.Header)
+	r.body(out.MediaTypes, resp)
+}
+
+// pickResponse prefers an exact code, then its range such as 2XX, then the
+// default response.
+func pickResponse(rs []model.Response, code int) (model.Response, bool) {
+	exact := strconv.Itoa(code)
+	rng := exact[:1] + "XX"
+	for _, want := range []string{exact, rng, "default"} {
+		for _, r := range rs {
+			if strings.EqualFold(strings.TrimSpace(r.StatusCode), want) {
+				return r, true
+			}
+		}
+	}
+	return model.Response{}, false
+}
+
+func (r *Result) headers(hs []model.Header, got http.Header) {
+	for _, h := range hs {
+		// OpenAPI ignores a Content-Type header definition; content covers it.
+		if strings.EqualFold(h.Name, "Content-Type") {
+			continue
+		}
+		vals := got.Values(h.Name)
+		if len(vals) == 0 {
+			if h.Required {
+				r.add(KindHeader, h.Name, "missing required header")
+			}
+			continue
+		}
+		if h.Schema == nil {
+			continue
+		}
+		for _, is := range validate(h.Schema, headerValue(vals, h.Schema.Node)) {
+			r.add(KindHeader, h.Name, is.msg)
+		}
+	}
+}
+
+// headerValue reads a header as the type its schema declares, so a number
+// header is checked as a number. A value that does not parse stays a string
+// and fails the type check.
+func headerValue(vals []string, s *model.Schema) any {
+	raw := strings.TrimSpace(vals[0])
+	switch model.InferSchemaType(s, model.TypeString).PrimaryType {
+	case model.TypeInteger, model.TypeNumber:
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw)
+		}
+	case model.TypeBoolean:
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	case model.TypeArray:
+		var out []any
+		for _, v := range vals {
+			for part := range strings.SplitSeq(v, ",") {
+				out = append(out, strings.TrimSpace(part))
+			}
+		}
+		return out
+	}
+	return raw
+}
+
+func (r *Result) body(mts []model.MediaType, resp Response) {
+	if len(mts) == 0 {
+		return
+	}
+	if len(resp.Body) == 0 {
+		// HEAD and not-modified responses carry the headers of a body
+		// without the body itself.
+		if strings.EqualFold(resp.Method, http.MethodHead) ||
+			resp.StatusCode == http.StatusNoContent ||
+			resp.StatusCode == http.StatusNotModified {
+			return
+		}
+		r.add(KindBody, "", "body is empty, expected "+mediaNames(mts))
+		return
+	}
+	ct := mediaType(resp.Header.Get("Content-Type"))
+	if ct == "" {
+		r.add(KindContentType, "", "response has no Content-Type, expected "+mediaNames(mts))
+		return
+	}
+	mt, ok := pickMedia(mts, ct)
+	if !ok {
+		r.add(KindContentType, "", fmt.Sprintf("content type %s is not documented (expected %s)", ct, mediaNames(mts)))
+		return
+	}
+	if mt.Schema == nil || !isJSON(ct) {
+		return
+	}
+	v, err := decode(resp.Body)
+	if err != nil {
+		r.add(KindBody, "", "body is not valid JSON: "+err.Error())
+		return
+	}
+	for _, is := range validate(mt.Schema, v) {
+		r.add(KindBody, is.path, is.msg)
+	}
+}
+
+func decode(body []byte) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(string(body)))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return v, nil
+}
+
+func mediaType(v string) string {
+	if v == "" {
+		return ""
+	}
+	mt, _, err := mime.ParseMediaType(v)
+	if err != nil {
+		mt, _, _ = strings.Cut(v, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+func isJSON(ct string) bool {
+	return ct == "application/json" || strings.HasSuffix(ct, "+json")
+}
+
+// pickMedia matches the exact media type first, then type/*, then */*.
+func pickMedia(mts []model.MediaType, ct string) (model.MediaType, bool) {
+	major, _, _ := strings.Cut(ct, "/")
+	for _, want := range []string{ct, major + "/*", "*/*"} {
+		for _, mt := range mts {
+			if mediaType(mt.ContentType) == want {
+				return mt, true
+			}
+		}
+	}
+	return model.MediaType{}, false
+}
+
+func mediaNames(mts []model.MediaType) string {
+	names := make([]string, 0, len(mts))
+	for _, mt := range mts {
+		if !slices.Contains(names, mt.ContentType) {
+			names = append(names, mt.ContentType)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func urlPath(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
@@ -0,0 +1,321 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+)
+
+const specYAML = `openapi: 3.1.0
+info:
+  title: Shop
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /items/{id}:
+    get:
+      operationId: getItem
+      responses:
+        "200":
+          description: ok
+          headers:
+            X-Rate-Limit:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Item"
+        4XX:
+          description: client error
+          content:
+            application/problem+json:
+              schema:
+                type: object
+                required: [title]
+  /items/featured:
+    get:
+      operationId: getFeatured
+      responses:
+        "200":
+          description: ok
+  /items:
+    get:
+      operationId: listItems
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                additionalProperties: false
+                required: [items]
+                properties:
+                  items:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Item"
+                  next:
+                    type: [string, "null"]
+components:
+  schemas:
+    Item:
+      type: object
+      required: [id, price, status]
+      properties:
+        id:
+          type: integer
+          minimum: 1
+        price:
+          type: number
+        status:
+          type: string
+          enum: [active, retired]
+        sku:
+          type: string
+          pattern: "^[A-Z]{3}-[0-9]+$"
+          maxLength: 12
+        parent:
+          $ref: "#/components/schemas/Item"
+`
+
+func writeSpec(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "openapi.yml")
+	if err := os.WriteFile(path, []byte(specYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func jsonResp(method, url string, code int, body string) Response {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("X-Rate-Limit", "100")
+	return Response{Method: method, URL: url, StatusCode: code, Header: h, Body: []byte(body)}
+}
+
+func messages(r *Result) []string {
+	var out []string
+	for _, v := range r.Violations {
+		out = append(out, v.String())
+	}
+	return out
+}
+
+func TestCheckPasses(t *testing.T) {
+	spec := writeSpec(t)
+	c := NewChecker()
+	res := c.Check(context.Background(), Target{Spec: spec}, jsonResp(
+		"GET", "https://api.example.com/v1/items/7?x=1", 200,
+		`{"id": 7, "price": 9.5, "status": "active", "sku": "ABC-12", "parent": {"id": 1, "price": 3, "status": "retired"}}`,
+	))
+	if res == nil || res.Failed() {
+		t.Fatalf("unexpected violations: %v", messages(res))
+	}
+	if res.Operation != "getItem" {
+		t.Fatalf("operation = %q", res.Operation)
+	}
+}
+
+func TestCheckReportsBodyViolations(t *testing.T) {
+	spec := writeSpec(t)
+	res := NewChecker().Check(context.Background(), Target{Spec: spec, Operation: "listItems"}, jsonResp(
+		"GET", "https://api.example.com/v1/items", 200,
+		`{"items": [{"id": 1, "price": 1, "status": "active"}, {"id": 0, "price": "9", "status": "gone", "sku": "abc"}, {"price": 1.5, "status": "active", "parent": {"id": 2.5, "price": 1, "status": "active"}}], "next": null, "total": 3}`,
+	))
+	got := messages(res)
+	want := []string{
+		"body /items/1/id: must be >= 1",
+		"body /items/1/price: expected number",
+		"body /items/1/sku: must match pattern ^[A-Z]{3}-[0-9]+$",
+		"body /items/1/status: must be one of \"active\", \"retired\"",
+		"body /items/2: missing required property \"id\"",
+		"body /items/2/parent/id: expected integer",
+		"body: unexpected property \"total\"",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("violations:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if msg := res.Message(); msg != "listItems: body /items/1/id: must be >= 1 (+6 more)" {
+		t.Fatalf("message = %q", msg)
+	}
+}
+
+func TestCheckStatusHeadersAndContentType(t *testing.T) {
+	spec := writeSpec(t)
+	c := NewChecker()
+	ctx := context.Background()
+
+	resp := jsonResp("GET", "https://api.example.com/v1/items/7", 500, `{}`)
+	if got := messages(c.Check(ctx, Target{Spec: spec}, resp)); len(got) != 1 ||
+		got[0] != "status 500 is not documented (expected 200, 4XX)" {
+		t.Fatalf("status: %v", got)
+	}
+
+	// 404 falls into the 4XX range, which documents a problem body.
+	resp = jsonResp("GET", "https://api.example.com/v1/items/7", 404, `{"title": "missing"}`)
+	if got := messages(c.Check(ctx, Target{Spec: spec}, resp)); len(got) != 1 ||
+		!strings.Contains(got[0], "content type application/json is not documented") {
+		t.Fatalf("content type: %v", got)
+	}
+
+	resp = jsonResp("GET", "https://api.example.com/v1/items/7", 200, `{"id": 7, "price": 1, "status": "active"}`)
+	resp.Header.Del("X-Rate-Limit")
+	if got := messages(c.Check(ctx, Target{Spec: spec}, resp)); len(got) != 1 ||
+		got[0] != "header X-Rate-Limit: missing required header" {
+		t.Fatalf("missing header: %v", got)
+	}
+	resp.Header.Set("X-Rate-Limit", "lots")
+	if got := messages(c.Check(ctx, Target{Spec: spec}, resp)); len(got) != 1 ||
+		got[0] != "header X-Rate-Limit: expected integer" {
+		t.Fatalf("header type: %v", got)
+	}
+
+	resp = jsonResp("GET", "https://api.example.com/v1/items/7", 200, `{"id": 7,`)
+	if got := messages(c.Check(ctx, Target{Spec: spec}, resp)); len(got) != 1 ||
+		!strings.HasPrefix(got[0], "body: body is not valid JSON") {
+		t.Fatalf("invalid json: %v", got)
+	}
+}
+
+func TestCheckMatchesOperations(t *testing.T) {
+	spec := writeSpec(t)
+	c := NewChecker()
+	ctx := context.Background()
+
+	// The literal route wins over the templated one.
+	res := c.Check(ctx, Target{Spec: spec}, Response{Method: "GET", URL: "http://localhost/v1/items/featured/", StatusCode: 200})
+	if res.Operation != "getFeatured" || res.Failed() {
+		t.Fatalf("featured = %+v", res)
+	}
+	// Hosts are ignored, and so is a missing server base path.
+	res = c.Check(ctx, Target{Spec: spec}, Response{Method: "GET", URL: "http://localhost:8080/items/featured", StatusCode: 200})
+	if res.Operation != "getFeatured" {
+		t.Fatalf("without base = %+v", res)
+	}
+
+	unknown := Response{Method: "DELETE", URL: "https://api.example.com/v1/items/7", StatusCode: 204}
+	if res := c.Check(ctx, Target{Spec: spec, Optional: true}, unknown); res != nil {
+		t.Fatalf("optional target checked an undocumented route: %+v", res)
+	}
+	if got := messages(c.Check(ctx, Target{Spec: spec}, unknown)); len(got) != 1 ||
+		got[0] != "no operation in the spec matches DELETE /v1/items/7" {
+		t.Fatalf("unknown route: %v", got)
+	}
+	if got := messages(c.Check(ctx, Target{Spec: spec, Operation: "nope"}, unknown)); len(got) != 1 ||
+		got[0] != `operation "nope" is not in the spec` {
+		t.Fatalf("unknown operation: %v", got)
+	}
+	if got := messages(c.Check(ctx, Target{Spec: filepath.Join(t.TempDir(), "missing.yml")}, unknown)); len(got) != 1 ||
+		!strings.HasPrefix(got[0], "load spec ") {
+		t.Fatalf("missing spec: %v", got)
+	}
+}
+
+func TestCheckerReloadsChangedSpec(t *testing.T) {
+	spec := writeSpec(t)
+	c := NewChecker()
+	ctx := context.Background()
+	resp := Response{Method: "GET", URL: "http://localhost/v1/items/featured", StatusCode: 200}
+	if res := c.Check(ctx, Target{Spec: spec}, resp); res.Failed() {
+		t.Fatalf("first check: %v", messages(res))
+	}
+	changed := strings.Replace(specYAML, "operationId: getFeatured", "operationId: featured", 1)
+	if err := os.WriteFile(spec, []byte(changed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(spec, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if res := c.Check(ctx, Target{Spec: spec}, resp); res.Operation != "featured" {
+		t.Fatalf("spec was not reloaded: %+v", res)
+	}
+}
+
+// Spec schemas are checked by internal/jsonschema; a tree that refers to
+// itself has to convert without looping, and a JavaScript-only pattern must
+// not fail the check.
+func TestValidateRecursiveSchema(t *testing.T) {
+	nullable := true
+	node := &model.Schema{
+		Types:    []model.SchemaType{model.TypeObject},
+		Required: []string{"name"},
+	}
+	node.Properties = map[string]*model.SchemaRef{
+		"name":     {Node: &model.Schema{Types: []model.SchemaType{model.TypeString}, Pattern: "^(?!x)"}},
+		"children": {Node: &model.Schema{Types: []model.SchemaType{model.TypeArray}, Items: &model.SchemaRef{Node: node}}},
+		"note":     {Node: &model.Schema{Types: []model.SchemaType{model.TypeString}, Nullable: &nullable}},
+	}
+	ref := &model.SchemaRef{Node: node}
+
+	ok := map[string]any{"name": "xa", "note": nil, "children": []any{map[string]any{"name": "b"}}}
+	if got := validate(ref, ok); len(got) != 0 {
+		t.Fatalf("valid tree: %+v", got)
+	}
+	bad := map[string]any{"name": "a", "children": []any{map[string]any{"note": "n"}}}
+	got := validate(ref, bad)
+	if len(got) != 1 || got[0].path != "/children/0" || !strings.Contains(got[0].msg, `"name"`) {
+		t.Fatalf("nested issue = %+v", got)
+	}
+}
+
+// A writeOnly property is only ever sent, so a response that leaves out a
+// required password still honors the contract.
+func TestCheckSkipsWriteOnlyProperties(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "openapi.yml")
+	data := `openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                additionalProperties: false
+                required: [name, password]
+                properties:
+                  name:
+                    type: string
+                  password:
+                    type: string
+                    writeOnly: true
+`
+	if err := os.WriteFile(spec, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewChecker()
+	check := func(body string) *Result {
+		return c.Check(context.Background(), Target{Spec: spec}, jsonResp("GET", "http://localhost/users/1", 200, body))
+	}
+	if res := check(`{"name": "ada"}`); res == nil || res.Failed() {
+		t.Fatalf("writeOnly property was required: %v", messages(res))
+	}
+	res := check(`{}`)
+	if got := messages(res); len(got) != 1 || !strings.Contains(got[0], `missing required property "name"`) {
+		t.Fatalf("violations = %v", got)
+	}
+}
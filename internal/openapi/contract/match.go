@@ -0,0 +1,99 @@
+package contract
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+)
+
+func operationByID(spec *model.Spec, id string) *model.Operation {
+	for i := range spec.Operations {
+		if spec.Operations[i].ID == id {
+			return &spec.Operations[i]
+		}
+	}
+	return nil
+}
+
+// operationFor finds the operation whose path template matches the URL once
+// a server's base path is removed. When several match, the one with the most
+// literal text wins, so /users/me beats /users/{id}.
+func operationFor(spec *model.Spec, method, rawURL string) *model.Operation {
+	path := trimSlash(urlPath(rawURL))
+	var (
+		best  *model.Operation
+		score = -1
+	)
+	for i := range spec.Operations {
+		op := &spec.Operations[i]
+		if !strings.EqualFold(string(op.Method), method) {
+			continue
+		}
+		re, lit := pathPattern(op.Path)
+		for _, base := range basePaths(spec.Servers, op.Servers) {
+			rest, ok := strings.CutPrefix(path, base)
+			if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+				continue
+			}
+			if re.MatchString(trimSlash(rest)) && lit > score {
+				best, score = op, lit
+			}
+		}
+	}
+	return best
+}
+
+var templateParam = regexp.MustCompile(`\{[^}/]+\}`)
+
+// pathPattern turns /users/{id} into a pattern where each parameter matches
+// one segment, and counts the literal characters left.
+func pathPattern(tmpl string) (*regexp.Regexp, int) {
+	tmpl = trimSlash(tmpl)
+	var b strings.Builder
+	b.WriteString("^")
+	lit, last := 0, 0
+	for _, loc := range templateParam.FindAllStringIndex(tmpl, -1) {
+		b.WriteString(regexp.QuoteMeta(tmpl[last:loc[0]]))
+		b.WriteString("[^/]+")
+		lit += loc[0] - last
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(tmpl[last:]))
+	b.WriteString("$")
+	lit += len(tmpl) - last
+	return regexp.MustCompile(b.String()), lit
+}
+
+// basePaths lists the path part of every server URL, with the bare path
+// last for specs whose servers are hosts only.
+func basePaths(groups ...[]model.Server) []string {
+	var out []string
+	for _, servers := range groups {
+		for _, s := range servers {
+			u, err := url.Parse(s.URL)
+			if err != nil {
+				continue
+			}
+			if p := trimSlash(u.Path); p != "/" && !slices.Contains(out, p) {
+				out = append(out, p)
+			}
+		}
+	}
+	return append(out, "")
+}
+
+func trimSlash(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if len(p) > 1 {
+		p = strings.TrimRight(p, "/")
+	}
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
@@ -0,0 +1,151 @@
+package contract
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/unkn0wn-root/resterm/internal/jsonschema"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+)
+
+type issue struct {
+	path string
+	msg  string
+}
+
+// validate checks a value decoded with json.Number against a schema. The
+// checking itself is internal/jsonschema's, so a contract and an RTS schema
+// assertion agree on what a schema means.
+func validate(ref *model.SchemaRef, v any) []issue {
+	if ref == nil || ref.Node == nil {
+		return nil
+	}
+	s, err := jsonschema.Compile(toJSONSchema(ref), ".", nil)
+	if err != nil {
+		return []issue{{msg: "schema: " + err.Error()}}
+	}
+	errs := s.Validate(v)
+	out := make([]issue, len(errs))
+	for i, e := range errs {
+		out[i] = issue{path: e.Path, msg: e.Message}
+	}
+	return out
+}
+
+// toJSONSchema writes a resolved spec schema as a JSON Schema document. Every
+// node lands in $defs once and is used through $ref, so a schema that refers
+// to itself stays finite.
+func toJSONSchema(ref *model.SchemaRef) map[string]any {
+	c := &converter{ids: map[*model.Schema]string{}, defs: map[string]any{}}
+	root := c.ref(ref)
+	root["$defs"] = c.defs
+	return root
+}
+
+type converter struct {
+	ids  map[*model.Schema]string
+	defs map[string]any
+}
+
+func (c *converter) ref(ref *model.SchemaRef) map[string]any {
+	if ref == nil || ref.Node == nil {
+		return map[string]any{}
+	}
+	id, ok := c.ids[ref.Node]
+	if !ok {
+		id = strconv.Itoa(len(c.ids))
+		c.ids[ref.Node] = id
+		c.defs[id] = c.schema(ref.Node)
+	}
+	return map[string]any{"$ref": "#/$defs/" + id}
+}
+
+func (c *converter) refs(refs []*model.SchemaRef) []any {
+	out := make([]any, len(refs))
+	for i, r := range refs {
+		out[i] = c.ref(r)
+	}
+	return out
+}
+
+func (c *converter) schema(s *model.Schema) map[string]any {
+	m := map[string]any{}
+	if len(s.Types) > 0 {
+		types := make([]any, 0, len(s.Types)+1)
+		null := false
+		for _, t := range s.Types {
+			types = append(types, string(t))
+			null = null || t == model.TypeNull
+		}
+		// OpenAPI 3.0 spells a nullable type with a flag.
+		if s.Nullable != nil && *s.Nullable && !null {
+			types = append(types, string(model.TypeNull))
+		}
+		m["type"] = types
+	}
+	if len(s.Enum) > 0 {
+		m["enum"] = s.Enum
+	}
+	if s.Min != nil {
+		m["minimum"] = *s.Min
+	}
+	if s.Max != nil {
+		m["maximum"] = *s.Max
+	}
+	if s.MinLen != nil {
+		m["minLength"] = *s.MinLen
+	}
+	if s.MaxLen != nil {
+		m["maxLength"] = *s.MaxLen
+	}
+	// Specs are often written against JavaScript regexps. Patterns Go cannot
+	// compile, such as lookaheads, are skipped rather than failing the check.
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err == nil {
+			m["pattern"] = s.Pattern
+		}
+	}
+	if s.Items != nil {
+		m["items"] = c.ref(s.Items)
+	}
+	// Contracts check responses, which never carry writeOnly properties
+	// such as a password, even when the schema requires them.
+	props := make(map[string]any, len(s.Properties))
+	for k, p := range s.Properties {
+		if !writeOnly(p) {
+			props[k] = c.ref(p)
+		}
+	}
+	if len(props) > 0 {
+		m["properties"] = props
+	}
+	var req []any
+	for _, r := range s.Required {
+		if !writeOnly(s.Properties[r]) {
+			req = append(req, r)
+		}
+	}
+	if len(req) > 0 {
+		m["required"] = req
+	}
+	switch {
+	case s.AdditionalProperties != nil:
+		m["additionalProperties"] = c.ref(s.AdditionalProperties)
+	case s.NoAdditionalProperties:
+		m["additionalProperties"] = false
+	}
+	if len(s.AllOf) > 0 {
+		m["allOf"] = c.refs(s.AllOf)
+	}
+	if len(s.AnyOf) > 0 {
+		m["anyOf"] = c.refs(s.AnyOf)
+	}
+	if len(s.OneOf) > 0 {
+		m["oneOf"] = c.refs(s.OneOf)
+	}
+	return m
+}
+
+func writeOnly(ref *model.SchemaRef) bool {
+	return ref != nil && ref.Node != nil && ref.Node.WriteOnly != nil && *ref.Node.WriteOnly
+}
@@ -72,11 +72,7 @@ func (b *Builder) Generate(
 	includeRequests := mode == openapi.GenerationRequests || mode == openapi.GenerationBoth
 	includeMocks := mode == openapi.GenerationMocks || mode == openapi.GenerationBoth
 
-	baseVar := opts.BaseURLVariable
-	if strings.TrimSpace(baseVar) == "" {
-		baseVar = openapi.DefaultBaseURLVariable
-	}
-
+	baseVar := baseVariable(opts)
 	doc := &restfile.Document{}
 	baseURL := ""
 	if includeRequests {
@@ -127,6 +123,26 @@ func (b *Builder) Generate(
 	return doc, nil
 }
 
+// Request builds the request Generate writes for op, for callers that update
+// an existing file one operation at a time. Globals it needs are not returned.
+func (b *Builder) Request(
+	spec *model.Spec,
+	op model.Operation,
+	opts openapi.GeneratorOptions,
+) (*restfile.Request, error) {
+	if spec == nil {
+		return nil, errors.New("openapi: spec is nil")
+	}
+	return b.buildRequest(op, spec, baseVariable(opts), selectBaseURL(spec, opts.PreferredServerIndex))
+}
+
+func baseVariable(opts openapi.GeneratorOptions) string {
+	if strings.TrimSpace(opts.BaseURLVariable) == "" {
+		return openapi.DefaultBaseURLVariable
+	}
+	return opts.BaseURLVariable
+}
+
 func (b *Builder) Warnings() []string {
 	return append([]string(nil), b.warnings...)
 }
@@ -0,0 +1,679 @@
+// Package infer describes a workspace as an OpenAPI document. Requests in
+// .http files give the paths, parameters and request bodies; @mock scenarios
+// and recorded history give the responses and their examples.
+package infer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/unkn0wn-root/resterm/internal/engine"
+	"github.com/unkn0wn-root/resterm/internal/files"
+	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+// historyLimit caps how many recorded responses feed one operation's schemas.
+const historyLimit = 50
+
+// Env is one environment's variables, used to turn a request base such as
+// {{baseUrl}} into a server URL.
+type Env struct {
+	Name   string
+	Values map[string]string
+}
+
+type Options struct {
+	Title       string
+	Version     string
+	Description string
+	Envs        []Env
+	// History holds recorded responses. Entries are matched to requests by
+	// name, method and file.
+	History []history.Entry
+}
+
+// Load parses the request files under root.
+func Load(root string, recursive bool) ([]*restfile.Document, error) {
+	entries, err := files.ListRequests(root, files.ListOptions{Recursive: recursive})
+	if err != nil {
+		return nil, fmt.Errorf("infer: list %s: %w", root, err)
+	}
+	var docs []*restfile.Document
+	for _, e := range entries {
+		if e.Kind != files.KindRequest {
+			continue
+		}
+		data, err := os.ReadFile(e.Path)
+		if err != nil {
+			return nil, fmt.Errorf("infer: read %s: %w", e.Path, err)
+		}
+		docs = append(docs, parser.Parse(e.Path, data))
+	}
+	return docs, nil
+}
+
+// Build describes docs as a spec. Requests that share a method and path
+// template become one operation. Warnings name what could not be described.
+func Build(docs []*restfile.Document, opt Options) (*model.Spec, []string) {
+	b := &builder{
+		opt:     opt,
+		ops:     map[string]*operation{},
+		paths:   map[string]string{},
+		ids:     map[string]bool{},
+		schemes: map[string]model.SecurityScheme{},
+		warned:  map[string]bool{},
+	}
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		if len(doc.Errors) > 0 {
+			b.warnf("%s: has parse errors; requests that parsed are still described", doc.Path)
+		}
+		for _, req := range doc.Requests {
+			b.request(doc, req)
+		}
+	}
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for _, m := range doc.Mocks {
+			b.mock(doc, m)
+		}
+	}
+
+	spec := &model.Spec{
+		Title:       opt.Title,
+		Version:     opt.Version,
+		Description: opt.Description,
+	}
+	if len(b.schemes) > 0 {
+		spec.SecuritySchemes = b.schemes
+	}
+	// The first request's server is the document's; operations on another
+	// server list their own.
+	var primary *operation
+	for _, op := range b.order {
+		if op.hasBase {
+			primary = op
+			spec.Servers = b.servers(op.base, op.doc)
+			break
+		}
+	}
+	for _, op := range b.order {
+		if op.id == "" {
+			op.id = b.uniqueID(deriveID(op.method, op.path))
+		}
+		out := op.finish()
+		if op.hasBase && op.base != primary.base {
+			out.Servers = b.servers(op.base, op.doc)
+		}
+		spec.Operations = append(spec.Operations, out)
+	}
+	return spec, b.warn
+}
+
+type builder struct {
+	opt   Options
+	ops   map[string]*operation
+	order []*operation
+	// paths maps a path shape to the first template written for it, so
+	// /users/{{id}} and /users/{{userId}} share one parameter name.
+	paths   map[string]string
+	ids     map[string]bool
+	schemes map[string]model.SecurityScheme
+	bases   map[string][]model.Server
+	warn    []string
+	warned  map[string]bool
+}
+
+func (b *builder) warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if b.warned[msg] {
+		return
+	}
+	b.warned[msg] = true
+	b.warn = append(b.warn, msg)
+}
+
+func (b *builder) request(doc *restfile.Document, req *restfile.Request) {
+	if req == nil {
+		return
+	}
+	id := engine.ReqID(req)
+	switch {
+	case req.GRPC != nil:
+		b.warnf("request %q: gRPC requests are not described", id)
+		return
+	case req.WebSocket != nil:
+		b.warnf("request %q: WebSocket requests are not described", id)
+		return
+	case req.Body.GraphQL != nil:
+		b.warnf("request %q: GraphQL requests are not described", id)
+		return
+	}
+	method := model.HTTPMethod(strings.ToUpper(strings.TrimSpace(req.Method)))
+	if !knownMethod(method) {
+		b.warnf("request %q: method %s is not described", id, req.Method)
+		return
+	}
+
+	res := requestVars(doc, req)
+	base, rawPath, query := splitURL(req.URL)
+	path, params := templatePath(rawPath)
+	op := b.operation(method, path, doc)
+	op.addRequest(req)
+	if op.id == "" {
+		op.id = b.uniqueID(strings.TrimSpace(req.Metadata.Name))
+	}
+	if !op.hasBase {
+		op.base, op.hasBase, op.doc = base, true, doc
+	}
+
+	for i, p := range params {
+		name := op.pathNames[i]
+		pr := op.param(model.InPath, name)
+		pr.required = true
+		// An unresolved value says nothing about the type.
+		if v, ok := res(p.ref); ok {
+			pr.add(literalSchema(v), literalValue(v), true)
+		}
+	}
+
+	auth := b.auth(doc, req, op)
+	b.query(op, query, res, auth)
+	b.headers(op, req, res, auth)
+	b.body(op, doc, req, res)
+	b.history(op, doc, req)
+}
+
+// Route is the method and path template Build files req under, such as
+// GET /users/{id} for GET {{baseUrl}}/users/{{id}}. It is false for requests
+// Build does not describe.
+func Route(req *restfile.Request) (model.HTTPMethod, string, bool) {
+	if req == nil || req.GRPC != nil || req.WebSocket != nil || req.Body.GraphQL != nil {
+		return "", "", false
+	}
+	method := model.HTTPMethod(strings.ToUpper(strings.TrimSpace(req.Method)))
+	if !knownMethod(method) {
+		return "", "", false
+	}
+	_, rawPath, _ := splitURL(req.URL)
+	path, _ := templatePath(rawPath)
+	return method, path, true
+}
+
+func knownMethod(m model.HTTPMethod) bool {
+	switch m {
+	case model.MethodGet, model.MethodPost, model.MethodPut, model.MethodPatch,
+		model.MethodDelete, model.MethodHead, model.MethodOptions, model.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// operation finds or starts the operation for method and path.
+func (b *builder) operation(method model.HTTPMethod, path string, doc *restfile.Document) *operation {
+	sh := shape(path)
+	if first, ok := b.paths[sh]; ok {
+		path = first
+	} else {
+		b.paths[sh] = path
+	}
+	key := string(method) + " " + sh
+	if op, ok := b.ops[key]; ok {
+		return op
+	}
+	op := newOperation(method, path, doc)
+	b.ops[key] = op
+	b.order = append(b.order, op)
+	return op
+}
+
+func (b *builder) uniqueID(id string) string {
+	if id == "" {
+		return ""
+	}
+	out := id
+	for n := 2; b.ids[out]; n++ {
+		out = id + "_" + strconv.Itoa(n)
+	}
+	b.ids[out] = true
+	return out
+}
+
+// requestVars resolves the request's own and its file's variables. Secret
+// values never end up in the spec, and neither do environment values.
+func requestVars(doc *restfile.Document, req *restfile.Request) resolver {
+	return func(name string) (string, bool) {
+		name = strings.TrimSpace(name)
+		for _, v := range req.Variables {
+			if v.Name == name {
+				return literal(v.Value, v.Secret)
+			}
+		}
+		for _, set := range [][]restfile.Variable{doc.Variables, doc.Globals} {
+			for _, v := range set {
+				if v.Name == name {
+					return literal(v.Value, v.Secret)
+				}
+			}
+		}
+		for _, c := range doc.Constants {
+			if c.Name == name {
+				return literal(c.Value, false)
+			}
+		}
+		return "", false
+	}
+}
+
+func literal(v string, secret bool) (string, bool) {
+	if secret || vars.HasPlaceholder(v) {
+		return "", false
+	}
+	return v, true
+}
+
+// splitURL separates the server part of a request URL from its path and
+// query. The server part is a scheme and host, a leading {{variable}}, or
+// empty for a bare path.
+func splitURL(raw string) (base, path, query string) {
+	raw = strings.TrimSpace(raw)
+	if i := strings.Index(raw, "#"); i >= 0 {
+		raw = raw[:i]
+	}
+	start := 0
+	switch {
+	case strings.HasPrefix(raw, "/"):
+		start = -1
+	case strings.HasPrefix(raw, "{{"):
+		if end := strings.Index(raw, "}}"); end >= 0 {
+			start = end + 2
+		}
+	default:
+		if i := strings.Index(raw, "://"); i >= 0 {
+			start = i + 3
+		}
+	}
+	rest := raw
+	if start >= 0 {
+		cut := len(raw)
+		if i := strings.IndexAny(raw[start:], "/?"); i >= 0 {
+			cut = start + i
+		}
+		base, rest = raw[:cut], raw[cut:]
+	}
+	path, query, _ = strings.Cut(rest, "?")
+	if path == "" {
+		path = "/"
+	}
+	return strings.TrimRight(base, "/"), path, query
+}
+
+// pathParam is a templated path segment: name is the OpenAPI parameter and
+// ref the variable the request wrote there.
+type pathParam struct {
+	name string
+	ref  string
+}
+
+var paramChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// templatePath rewrites {{var}} in a request path as {var}.
+func templatePath(p string) (string, []pathParam) {
+	if !vars.HasPlaceholder(p) {
+		return p, nil
+	}
+	var params []pathParam
+	used := map[string]bool{}
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		segs[i] = vars.ReplaceTemplateVars(seg, func(match, name string) string {
+			fields := strings.Fields(name)
+			if len(fields) == 0 {
+				return match
+			}
+			pn := strings.Trim(paramChars.ReplaceAllString(strings.TrimPrefix(fields[0], "$"), "_"), "_")
+			if pn == "" {
+				pn = "param"
+			}
+			base := pn
+			for n := 2; used[pn]; n++ {
+				pn = base + strconv.Itoa(n)
+			}
+			used[pn] = true
+			params = append(params, pathParam{name: pn, ref: name})
+			return "{" + pn + "}"
+		})
+	}
+	return strings.Join(segs, "/"), params
+}
+
+var (
+	templateSeg = regexp.MustCompile(`\{[^{}]*\}`)
+	catchAll    = regexp.MustCompile(`\{([^{}]+)\.\.\.\}`)
+)
+
+// shape drops parameter names, so two spellings of one route compare equal.
+func shape(path string) string {
+	return templateSeg.ReplaceAllString(path, "{}")
+}
+
+func pathNames(path string) []string {
+	var out []string
+	for _, m := range templateSeg.FindAllString(path, -1) {
+		out = append(out, strings.Trim(m, "{}"))
+	}
+	return out
+}
+
+func (b *builder) query(op *operation, query string, res resolver, auth *authUse) {
+	if query == "" {
+		return
+	}
+	seen := map[string]int{}
+	type qv struct {
+		name string
+		vals []string
+	}
+	var list []*qv
+	for part := range strings.SplitSeq(query, "&") {
+		k, v, _ := strings.Cut(part, "=")
+		if key, err := url.QueryUnescape(k); err == nil {
+			k = key
+		}
+		if k == "" || vars.HasPlaceholder(k) {
+			continue
+		}
+		if auth != nil && auth.in == model.InQuery && auth.name == k {
+			continue
+		}
+		if i, ok := seen[k]; ok {
+			list[i].vals = append(list[i].vals, v)
+			continue
+		}
+		seen[k] = len(list)
+		list = append(list, &qv{name: k, vals: []string{v}})
+	}
+	for _, q := range list {
+		pr := op.param(model.InQuery, q.name)
+		var items *model.Schema
+		var examples []any
+		complete := true
+		for _, raw := range q.vals {
+			v := expand(raw, res)
+			if val, err := url.QueryUnescape(v); err == nil {
+				v = val
+			}
+			if vars.HasPlaceholder(v) {
+				complete = false
+				items = merge(items, &model.Schema{Types: []model.SchemaType{model.TypeString}})
+				continue
+			}
+			items = merge(items, literalSchema(v))
+			examples = append(examples, literalValue(v))
+		}
+		if len(q.vals) > 1 {
+			s := &model.Schema{Types: []model.SchemaType{model.TypeArray}, Items: ref(items)}
+			pr.add(s, examples, complete)
+			continue
+		}
+		var ex any
+		if len(examples) > 0 {
+			ex = examples[0]
+		}
+		pr.add(items, ex, complete)
+	}
+}
+
+// Headers the spec describes elsewhere or that OpenAPI ignores as parameters.
+var skipHeaders = map[string]bool{
+	"Accept":            true,
+	"Authorization":     true,
+	"Content-Type":      true,
+	"Content-Length":    true,
+	"Cookie":            true,
+	"Host":              true,
+	"Transfer-Encoding": true,
+	"User-Agent":        true,
+}
+
+func (b *builder) headers(op *operation, req *restfile.Request, res resolver, auth *authUse) {
+	names := make([]string, 0, len(req.Headers))
+	for k := range req.Headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		name := http.CanonicalHeaderKey(k)
+		if skipHeaders[name] {
+			continue
+		}
+		if auth != nil && auth.in == model.InHeader && strings.EqualFold(auth.name, name) {
+			continue
+		}
+		pr := op.param(model.InHeader, name)
+		v := expand(req.Headers.Get(k), res)
+		if vars.HasPlaceholder(v) || sensitiveHeader(name) {
+			pr.add(&model.Schema{Types: []model.SchemaType{model.TypeString}}, nil, false)
+			continue
+		}
+		pr.add(literalSchema(v), literalValue(v), true)
+	}
+}
+
+func sensitiveHeader(name string) bool {
+	n := strings.ToLower(name)
+	for _, part := range []string{"auth", "token", "secret", "key", "password", "session", "csrf"} {
+		if strings.Contains(n, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *builder) body(
+	op *operation,
+	doc *restfile.Document,
+	req *restfile.Request,
+	res resolver,
+) {
+	ct := req.Headers.Get("Content-Type")
+	text := req.Body.Text
+	if fp := strings.TrimSpace(req.Body.FilePath); fp != "" {
+		data, err := readRelative(doc, fp)
+		if err != nil || !isJSON(mediaType(ct)) {
+			if ct == "" {
+				ct = "application/octet-stream"
+			}
+			op.requestBody().add(body{
+				contentType: mediaType(ct),
+				schema:      &model.Schema{Types: []model.SchemaType{model.TypeString}, Format: "binary"},
+			}, "")
+			return
+		}
+		text = string(data)
+	}
+	bd, ok := readBody(ct, text, res)
+	if !ok {
+		return
+	}
+	op.requestBody().add(bd, "")
+}
+
+func readRelative(doc *restfile.Document, p string) ([]byte, error) {
+	if !filepath.IsAbs(p) && doc != nil && doc.Path != "" {
+		p = filepath.Join(filepath.Dir(doc.Path), p)
+	}
+	return os.ReadFile(p)
+}
+
+// history adds the responses recorded for req. Entries carry a body snippet
+// rather than the full body, so only snippets that still parse as JSON give a
+// schema; the rest add their status alone.
+func (b *builder) history(op *operation, doc *restfile.Document, req *restfile.Request) {
+	id := engine.ReqID(req)
+	n := 0
+	for _, e := range b.opt.History {
+		if n >= historyLimit {
+			return
+		}
+		if e.RequestName != id || !strings.EqualFold(e.Method, req.Method) || !sameFile(e.FilePath, doc.Path) {
+			continue
+		}
+		if e.StatusCode < 100 || e.StatusCode > 599 {
+			continue
+		}
+		n++
+		r := op.response(strconv.Itoa(e.StatusCode))
+		v, ok := decodePlainJSON(e.BodySnippet)
+		if !ok {
+			continue
+		}
+		summary := "Recorded response"
+		if !e.ExecutedAt.IsZero() {
+			summary = "Recorded " + e.ExecutedAt.UTC().Format("2006-01-02 15:04:05 UTC")
+		}
+		r.media.add(body{
+			contentType: "application/json",
+			schema:      schemaOf(v),
+			example:     v,
+			hasExample:  true,
+		}, "history", summary)
+	}
+}
+
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	if aa, err := filepath.Abs(a); err == nil {
+		a = aa
+	}
+	if bb, err := filepath.Abs(b); err == nil {
+		b = bb
+	}
+	return history.NormPath(a) == history.NormPath(b)
+}
+
+// mock adds a scenario's responses to the operation on its route, creating
+// the operation when no request calls that route.
+func (b *builder) mock(doc *restfile.Document, m *restfile.Mock) {
+	if m == nil {
+		return
+	}
+	method := model.HTTPMethod(strings.ToUpper(strings.TrimSpace(m.Method)))
+	if !knownMethod(method) {
+		return
+	}
+	path := catchAll.ReplaceAllString(m.Path, "{$1}")
+	op := b.operation(method, path, doc)
+	if op.summary == "" && op.reqs == 0 {
+		op.summary = strings.TrimSpace(m.Title)
+	}
+	if op.reqs == 0 {
+		for _, name := range op.pathNames {
+			op.param(model.InPath, name)
+		}
+		if op.id == "" {
+			op.id = b.uniqueID(mockID(m, method, path))
+		}
+	}
+	for i, resp := range m.Responses {
+		r := op.response(strconv.Itoa(resp.Status))
+		for _, name := range sortedHeaderNames(resp.Headers) {
+			if name == "Content-Type" {
+				continue
+			}
+			v := resp.Headers.Get(name)
+			if vars.HasPlaceholder(v) {
+				r.header(name, nil, false)
+			} else {
+				r.header(name, v, true)
+			}
+		}
+		text := resp.Body.Text
+		if fp := strings.TrimSpace(resp.Body.FilePath); fp != "" {
+			data, err := readRelative(doc, fp)
+			if err != nil {
+				b.warnf("mock %s %s: read %s: %v", m.Method, m.Path, fp, err)
+				continue
+			}
+			text = string(data)
+		}
+		bd, ok := readBody(resp.Headers.Get("Content-Type"), text, noVars)
+		if !ok {
+			continue
+		}
+		r.media.add(bd, mockExampleName(m, i), strings.TrimSpace(m.Title))
+	}
+}
+
+func sortedHeaderNames(h http.Header) []string {
+	out := make([]string, 0, len(h))
+	for k := range h {
+		out = append(out, http.CanonicalHeaderKey(k))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func mockExampleName(m *restfile.Mock, i int) string {
+	name := strings.TrimSpace(m.Name)
+	if name == "" {
+		name = strings.TrimSpace(m.Sequence)
+	}
+	if name == "" {
+		name = "mock"
+	}
+	if len(m.Responses) > 1 {
+		name += "-" + strconv.Itoa(i+1)
+	}
+	return name
+}
+
+func mockID(m *restfile.Mock, method model.HTTPMethod, path string) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return deriveID(method, path)
+}
+
+// deriveID names an operation from its route, as in getUsersById.
+func deriveID(method model.HTTPMethod, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(string(method)))
+	for _, seg := range strings.Split(path, "/") {
+		by := strings.HasPrefix(seg, "{")
+		if by {
+			b.WriteString("By")
+		}
+		up := true
+		for _, r := range seg {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				up = true
+				continue
+			}
+			if up {
+				r = unicode.ToUpper(r)
+				up = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,291 @@
+package infer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+const workspace = `@id = 42
+
+### Get user
+# @name getUser
+# @tag users
+# @auth bearer {{token}}
+GET {{baseUrl}}/users/{{id}}?verbose=true&tag=a&tag=b
+X-Tenant: acme
+X-Session-Token: {{session}}
+
+### Get user again
+GET {{baseUrl}}/users/{{userId}}
+
+### Create user
+# @name createUser
+# @description Creates one user.
+# @auth apikey header X-API-Key {{key}}
+POST {{baseUrl}}/users
+Content-Type: application/json
+X-API-Key: {{key}}
+
+{"name": "Ada", "age": {{age}}, "email": "ada@example.com", "tags": ["a"]}
+
+### Login
+POST https://auth.example.com/login
+Content-Type: application/x-www-form-urlencoded
+
+user=ada&remember=true
+
+### Found
+# @mock method=GET path=/users/{userId} name=found
+HTTP/1.1 200 OK
+Content-Type: application/json
+X-Request-Id: abc
+
+{"id": {{json.path.userId}}, "name": "Ada", "manager": null}
+
+### Missing
+# @mock method=GET path=/users/{userId} name=missing
+HTTP/1.1 404 Not Found
+Content-Type: application/json
+
+{"error": "not found"}
+
+### Health
+# @mock method=GET path=/health
+HTTP/1.1 200 OK
+Content-Type: text/plain
+
+ok
+`
+
+func build(t *testing.T, opt Options) (*model.Spec, []string) {
+	t.Helper()
+	doc := parser.Parse("/ws/api.http", []byte(workspace))
+	if len(doc.Errors) != 0 {
+		t.Fatalf("parse: %v", doc.Errors)
+	}
+	return Build([]*restfile.Document{doc}, opt)
+}
+
+func findOp(t *testing.T, spec *model.Spec, method model.HTTPMethod, path string) model.Operation {
+	t.Helper()
+	for _, op := range spec.Operations {
+		if op.Method == method && op.Path == path {
+			return op
+		}
+	}
+	var have []string
+	for _, op := range spec.Operations {
+		have = append(have, string(op.Method)+" "+op.Path)
+	}
+	t.Fatalf("%s %s not found in %v", method, path, have)
+	return model.Operation{}
+}
+
+func findParam(t *testing.T, op model.Operation, in model.ParameterLocation, name string) model.Parameter {
+	t.Helper()
+	for _, p := range op.Parameters {
+		if p.Location == in && p.Name == name {
+			return p
+		}
+	}
+	t.Fatalf("%s parameter %q not found in %+v", in, name, op.Parameters)
+	return model.Parameter{}
+}
+
+func findResponse(t *testing.T, op model.Operation, status string) model.Response {
+	t.Helper()
+	for _, r := range op.Responses {
+		if r.StatusCode == status {
+			return r
+		}
+	}
+	t.Fatalf("response %s not found", status)
+	return model.Response{}
+}
+
+func types(s *model.SchemaRef) string {
+	if s == nil || s.Node == nil {
+		return ""
+	}
+	var out []string
+	for _, t := range s.Node.Types {
+		out = append(out, string(t))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestBuildPathsAndParameters(t *testing.T) {
+	spec, warns := build(t, Options{
+		Title: "Users",
+		Envs: []Env{
+			{Name: "dev", Values: map[string]string{"baseUrl": "http://localhost:8080/"}},
+			{Name: "prod", Values: map[string]string{"baseUrl": "https://api.example.com"}},
+		},
+	})
+	if len(warns) != 0 {
+		t.Fatalf("warnings = %v", warns)
+	}
+	if len(spec.Servers) != 2 || spec.Servers[0].URL != "http://localhost:8080" || spec.Servers[1].Description != "prod" {
+		t.Fatalf("servers = %+v", spec.Servers)
+	}
+
+	get := findOp(t, spec, model.MethodGet, "/users/{id}")
+	if get.ID != "getUser" || strings.Join(get.Tags, ",") != "users" {
+		t.Fatalf("operation = %+v", get)
+	}
+	id := findParam(t, get, model.InPath, "id")
+	if !id.Required || types(id.Schema) != "integer" || id.Example.Value != json.Number("42") {
+		t.Fatalf("path param = %+v", id)
+	}
+	if p := findParam(t, get, model.InQuery, "verbose"); types(p.Schema) != "boolean" || p.Example.Value != true {
+		t.Fatalf("verbose = %+v", p)
+	}
+	if p := findParam(t, get, model.InQuery, "tag"); types(p.Schema) != "array" || types(p.Schema.Node.Items) != "string" {
+		t.Fatalf("repeated query = %+v", p)
+	}
+	if p := findParam(t, get, model.InHeader, "X-Tenant"); p.Example.Value != "acme" {
+		t.Fatalf("header = %+v", p)
+	}
+	if p := findParam(t, get, model.InHeader, "X-Session-Token"); p.Example.HasValue {
+		t.Fatalf("unresolved header got an example: %+v", p)
+	}
+	if len(get.Security) != 1 || get.Security[0].SchemeName != "bearerAuth" {
+		t.Fatalf("security = %+v", get.Security)
+	}
+
+	// The second request spells the parameter differently but is the same
+	// route, and it has no name of its own.
+	for _, op := range spec.Operations {
+		if op.Path == "/users/{userId}" {
+			t.Fatalf("same route was described twice")
+		}
+	}
+
+	create := findOp(t, spec, model.MethodPost, "/users")
+	if create.Description != "Creates one user." {
+		t.Fatalf("description = %q", create.Description)
+	}
+	for _, p := range create.Parameters {
+		if p.Name == "X-API-Key" {
+			t.Fatalf("api key header listed as a parameter")
+		}
+	}
+	scheme := spec.SecuritySchemes[create.Security[0].SchemeName]
+	if scheme.Type != model.SecurityAPIKey || scheme.Name != "X-API-Key" || scheme.In != model.InHeader {
+		t.Fatalf("api key scheme = %+v", scheme)
+	}
+	body := create.RequestBody.MediaTypes[0]
+	if body.ContentType != "application/json" || strings.Join(body.Schema.Node.Required, ",") != "age,email,name,tags" {
+		t.Fatalf("body = %+v", body.Schema.Node)
+	}
+	if f := body.Schema.Node.Properties["email"].Node.Format; f != "email" {
+		t.Fatalf("email format = %q", f)
+	}
+	ex := body.Examples[0].Value.(map[string]any)
+	if _, ok := ex["age"]; ok || ex["name"] != "Ada" {
+		t.Fatalf("unresolved value kept in example: %v", ex)
+	}
+
+	login := findOp(t, spec, model.MethodPost, "/login")
+	if len(login.Servers) != 1 || login.Servers[0].URL != "https://auth.example.com" {
+		t.Fatalf("operation servers = %+v", login.Servers)
+	}
+	form := login.RequestBody.MediaTypes[0]
+	if form.ContentType != "application/x-www-form-urlencoded" || types(form.Schema.Node.Properties["remember"]) != "boolean" {
+		t.Fatalf("form = %+v", form)
+	}
+}
+
+func TestBuildResponsesFromMocks(t *testing.T) {
+	spec, _ := build(t, Options{})
+	get := findOp(t, spec, model.MethodGet, "/users/{id}")
+
+	ok := findResponse(t, get, "200")
+	if len(ok.Headers) != 1 || ok.Headers[0].Name != "X-Request-Id" {
+		t.Fatalf("headers = %+v", ok.Headers)
+	}
+	mt := ok.MediaTypes[0]
+	if types(mt.Schema.Node.Properties["manager"]) != "null" || mt.Schema.Node.Properties["id"].Node.Types != nil {
+		t.Fatalf("schema = %+v", mt.Schema.Node.Properties)
+	}
+	if len(mt.Examples) != 1 || mt.Examples[0].Name != "found" {
+		t.Fatalf("examples = %+v", mt.Examples)
+	}
+	if v := mt.Examples[0].Value.(map[string]any); v["name"] != "Ada" || len(v) != 2 {
+		t.Fatalf("example = %v", v)
+	}
+	if r := findResponse(t, get, "404"); r.MediaTypes[0].Examples[0].Name != "missing" {
+		t.Fatalf("404 = %+v", r)
+	}
+
+	// A mock with no request behind it still becomes an operation.
+	health := findOp(t, spec, model.MethodGet, "/health")
+	if health.ID != "getHealth" || health.Summary != "Health" {
+		t.Fatalf("health = %+v", health)
+	}
+	if mt := findResponse(t, health, "200").MediaTypes[0]; mt.ContentType != "text/plain" || mt.Examples[0].Value != "ok" {
+		t.Fatalf("text response = %+v", mt)
+	}
+}
+
+func TestBuildResponsesFromHistory(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	spec, _ := build(t, Options{History: []history.Entry{
+		{RequestName: "createUser", Method: "POST", FilePath: "/ws/api.http", StatusCode: 201, ExecutedAt: at,
+			BodySnippet: `{"id": 7, "score": 1}`},
+		{RequestName: "createUser", Method: "POST", FilePath: "/ws/api.http", StatusCode: 201,
+			BodySnippet: `{"id": 8, "score": 1.5, "note": "x"}`},
+		{RequestName: "createUser", Method: "POST", FilePath: "/ws/api.http", StatusCode: 422,
+			BodySnippet: `{"truncated": `},
+		{RequestName: "createUser", Method: "POST", FilePath: "/elsewhere/api.http", StatusCode: 500},
+		{RequestName: "createUser", Method: "POST", Status: "SKIPPED"},
+	}})
+	create := findOp(t, spec, model.MethodPost, "/users")
+	if len(create.Responses) != 2 {
+		t.Fatalf("responses = %+v", create.Responses)
+	}
+	mt := findResponse(t, create, "201").MediaTypes[0]
+	s := mt.Schema.Node
+	if types(s.Properties["score"]) != "number" || strings.Join(s.Required, ",") != "id,score" {
+		t.Fatalf("merged schema = %+v", s)
+	}
+	if len(mt.Examples) != 1 || mt.Examples[0].Name != "history" || !strings.Contains(mt.Examples[0].Summary, "2026-01-02") {
+		t.Fatalf("examples = %+v", mt.Examples)
+	}
+	if r := findResponse(t, create, "422"); len(r.MediaTypes) != 0 {
+		t.Fatalf("a cut-off snippet gave a body: %+v", r)
+	}
+}
+
+func TestSplitURL(t *testing.T) {
+	cases := []struct{ in, base, path, query string }{
+		{"{{baseUrl}}/users/{{id}}?a=1", "{{baseUrl}}", "/users/{{id}}", "a=1"},
+		{"https://api.example.com:8443/v1/x#frag", "https://api.example.com:8443", "/v1/x", ""},
+		{"/health", "", "/health", ""},
+		{"{{host}}:8080/x", "{{host}}:8080", "/x", ""},
+		{"https://example.com", "https://example.com", "/", ""},
+	}
+	for _, c := range cases {
+		base, path, query := splitURL(c.in)
+		if base != c.base || path != c.path || query != c.query {
+			t.Errorf("splitURL(%q) = %q %q %q", c.in, base, path, query)
+		}
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	got, params := templatePath("/orgs/{{org.id}}/users/{{ $uuid }}/files/v{{n}}-{{n}}")
+	if got != "/orgs/{org.id}/users/{uuid}/files/v{n}-{n2}" {
+		t.Fatalf("path = %q", got)
+	}
+	if len(params) != 4 || params[0].ref != "org.id" || params[1].ref != "$uuid" {
+		t.Fatalf("params = %+v", params)
+	}
+}
@@ -0,0 +1,310 @@
+package infer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+// operation gathers every request and response seen for one method and path
+// until finish turns it into a model.Operation.
+type operation struct {
+	method      model.HTTPMethod
+	path        string
+	pathNames   []string
+	id          string
+	summary     string
+	description string
+	tags        []string
+	security    []model.SecurityRequirement
+	// base is the server part of the first request's URL. Operations
+	// started by a mock have none.
+	base    string
+	hasBase bool
+	doc     *restfile.Document
+	reqs    int
+
+	params    []*param
+	body      *mediaSet
+	responses []*response
+}
+
+func newOperation(method model.HTTPMethod, path string, doc *restfile.Document) *operation {
+	return &operation{method: method, path: path, pathNames: pathNames(path), doc: doc}
+}
+
+// addRequest takes the documentation of the first request that names it.
+func (op *operation) addRequest(req *restfile.Request) {
+	op.reqs++
+	if op.description == "" {
+		op.description = strings.TrimSpace(req.Metadata.Description)
+	}
+	for _, t := range req.Metadata.Tags {
+		if t = strings.TrimSpace(t); t != "" && !containsString(op.tags, t) {
+			op.tags = append(op.tags, t)
+		}
+	}
+}
+
+func (op *operation) addSecurity(req model.SecurityRequirement) {
+	for _, s := range op.security {
+		if s.SchemeName == req.SchemeName {
+			return
+		}
+	}
+	op.security = append(op.security, req)
+}
+
+type param struct {
+	name     string
+	in       model.ParameterLocation
+	required bool
+	schema   *model.Schema
+	example  any
+	hasEx    bool
+}
+
+func (op *operation) param(in model.ParameterLocation, name string) *param {
+	for _, p := range op.params {
+		if p.in == in && p.name == name {
+			return p
+		}
+	}
+	p := &param{name: name, in: in}
+	op.params = append(op.params, p)
+	return p
+}
+
+func (p *param) add(s *model.Schema, example any, ok bool) {
+	p.schema = merge(p.schema, s)
+	if ok && !p.hasEx {
+		p.example, p.hasEx = example, true
+	}
+}
+
+func (op *operation) requestBody() *mediaSet {
+	if op.body == nil {
+		op.body = &mediaSet{}
+	}
+	return op.body
+}
+
+type response struct {
+	status  string
+	headers []*header
+	media   *mediaSet
+}
+
+type header struct {
+	name    string
+	example any
+	hasEx   bool
+}
+
+func (op *operation) response(status string) *response {
+	for _, r := range op.responses {
+		if r.status == status {
+			return r
+		}
+	}
+	r := &response{status: status, media: &mediaSet{}}
+	op.responses = append(op.responses, r)
+	return r
+}
+
+func (r *response) header(name string, example any, ok bool) {
+	for _, h := range r.headers {
+		if h.name == name {
+			if ok && !h.hasEx {
+				h.example, h.hasEx = example, true
+			}
+			return
+		}
+	}
+	r.headers = append(r.headers, &header{name: name, example: example, hasEx: ok})
+}
+
+// mediaSet merges the bodies seen for each content type and keeps one example
+// per name.
+type mediaSet struct {
+	types []*media
+}
+
+type media struct {
+	contentType string
+	schema      *model.Schema
+	examples    []model.Example
+}
+
+// add merges bd. An example gets name and summary when given; a request body
+// keeps only its first example.
+func (ms *mediaSet) add(bd body, name string, summary ...string) {
+	var m *media
+	for _, x := range ms.types {
+		if x.contentType == bd.contentType {
+			m = x
+			break
+		}
+	}
+	if m == nil {
+		m = &media{contentType: bd.contentType}
+		ms.types = append(ms.types, m)
+	}
+	m.schema = merge(m.schema, bd.schema)
+	if !bd.hasExample {
+		return
+	}
+	if name == "" {
+		if len(m.examples) == 0 {
+			m.examples = append(m.examples, model.Example{Value: bd.example, HasValue: true})
+		}
+		return
+	}
+	for _, ex := range m.examples {
+		if ex.Name == name {
+			return
+		}
+	}
+	ex := model.Example{Name: name, Value: bd.example, HasValue: true, Source: model.ExampleFromExplicit}
+	if len(summary) > 0 {
+		ex.Summary = summary[0]
+	}
+	m.examples = append(m.examples, ex)
+}
+
+func (ms *mediaSet) model() []model.MediaType {
+	if ms == nil {
+		return nil
+	}
+	out := make([]model.MediaType, 0, len(ms.types))
+	for _, m := range ms.types {
+		mt := model.MediaType{ContentType: m.contentType, Examples: m.examples}
+		if m.schema != nil {
+			mt.Schema = ref(m.schema)
+		}
+		out = append(out, mt)
+	}
+	return out
+}
+
+func (op *operation) finish() model.Operation {
+	out := model.Operation{
+		ID:          op.id,
+		Method:      op.method,
+		Path:        op.path,
+		Summary:     op.summary,
+		Description: op.description,
+		Tags:        op.tags,
+		Security:    op.security,
+	}
+	// Path parameters first, in path order, then query and header ones in
+	// the order requests wrote them.
+	rank := map[model.ParameterLocation]int{model.InPath: 0, model.InQuery: 1, model.InHeader: 2, model.InCookie: 3}
+	params := append([]*param(nil), op.params...)
+	sort.SliceStable(params, func(i, j int) bool { return rank[params[i].in] < rank[params[j].in] })
+	for _, p := range params {
+		mp := model.Parameter{
+			Name:     p.name,
+			Location: p.in,
+			Required: p.required || p.in == model.InPath,
+		}
+		// Parameters seen only through unresolved values are strings.
+		if p.schema != nil {
+			mp.Schema = ref(p.schema)
+		} else {
+			mp.Schema = ref(&model.Schema{Types: []model.SchemaType{model.TypeString}})
+		}
+		if p.hasEx {
+			mp.Example = model.Example{Value: p.example, HasValue: true}
+		}
+		out.Parameters = append(out.Parameters, mp)
+	}
+	if op.body != nil && len(op.body.types) > 0 {
+		out.RequestBody = &model.RequestBody{Required: true, MediaTypes: op.body.model()}
+	}
+
+	resps := append([]*response(nil), op.responses...)
+	sort.SliceStable(resps, func(i, j int) bool {
+		a, _ := strconv.Atoi(resps[i].status)
+		b, _ := strconv.Atoi(resps[j].status)
+		return a < b
+	})
+	for _, r := range resps {
+		mr := model.Response{StatusCode: r.status, MediaTypes: r.media.model()}
+		for _, h := range r.headers {
+			mh := model.Header{
+				Name:   h.name,
+				Schema: ref(&model.Schema{Types: []model.SchemaType{model.TypeString}}),
+			}
+			if h.hasEx {
+				mh.Example = model.Example{Value: h.example, HasValue: true}
+			}
+			mr.Headers = append(mr.Headers, mh)
+		}
+		out.Responses = append(out.Responses, mr)
+	}
+	return out
+}
+
+func containsString(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// servers resolves a request base into one server per environment that
+// gives it a value. Literal bases are servers as written.
+func (b *builder) servers(base string, doc *restfile.Document) []model.Server {
+	if base == "" {
+		return nil
+	}
+	if !vars.HasPlaceholder(base) {
+		return []model.Server{{URL: base}}
+	}
+	if b.bases == nil {
+		b.bases = map[string][]model.Server{}
+	}
+	if s, ok := b.bases[base]; ok {
+		return s
+	}
+	var out []model.Server
+	add := func(u, desc string) {
+		u = strings.TrimRight(u, "/")
+		if u == "" || vars.HasPlaceholder(u) {
+			return
+		}
+		for _, s := range out {
+			if s.URL == u {
+				return
+			}
+		}
+		out = append(out, model.Server{URL: u, Description: desc})
+	}
+	var fileVars resolver = noVars
+	if doc != nil {
+		fileVars = requestVars(doc, &restfile.Request{})
+	}
+	for _, env := range b.opt.Envs {
+		add(expand(base, func(name string) (string, bool) {
+			if v, ok := env.Values[strings.TrimSpace(name)]; ok && !vars.HasPlaceholder(v) {
+				return v, true
+			}
+			return fileVars(name)
+		}), env.Name)
+	}
+	if len(out) == 0 {
+		add(expand(base, fileVars), "")
+	}
+	if len(out) == 0 {
+		b.warnf("server %s has no value in any environment and was left out", base)
+	}
+	b.bases[base] = out
+	return out
+}
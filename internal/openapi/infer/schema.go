@@ -0,0 +1,449 @@
+package infer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+// hole stands in for a placeholder written where a JSON value goes, as in
+// {"id": {{id}}}. Its type is unknown, so it adds nothing to the schema.
+const (
+	hole     = "\x00resterm:hole"
+	holeJSON = `"\u0000resterm:hole"`
+)
+
+// resolver returns the literal value of a template variable, if there is one.
+type resolver func(name string) (string, bool)
+
+func noVars(string) (string, bool) { return "", false }
+
+// body is what one request or response body says about its media type.
+type body struct {
+	contentType string
+	schema      *model.Schema
+	example     any
+	hasExample  bool
+}
+
+// readBody infers a schema from text. Values behind placeholders nothing could
+// resolve are left out of the example.
+func readBody(contentType, text string, res resolver) (body, bool) {
+	ct := mediaType(contentType)
+	trimmed := strings.TrimSpace(text)
+	if ct == "" {
+		if trimmed == "" {
+			return body{}, false
+		}
+		if trimmed[0] == '{' || trimmed[0] == '[' {
+			ct = "application/json"
+		} else {
+			ct = "text/plain"
+		}
+	}
+	out := body{contentType: ct}
+	switch {
+	case isJSON(ct):
+		v, err := decodeJSON(trimmed, res)
+		if err != nil {
+			out.schema = &model.Schema{}
+			return out, true
+		}
+		out.schema = schemaOf(v)
+		out.example, out.hasExample = example(v)
+	case ct == "application/x-www-form-urlencoded":
+		out.schema, out.example, out.hasExample = formSchema(trimmed, res)
+	case ct == "multipart/form-data":
+		out.schema = multipartSchema(text)
+	default:
+		out.schema = &model.Schema{Types: []model.SchemaType{model.TypeString}}
+		if text := expand(trimmed, res); trimmed != "" && !vars.HasPlaceholder(text) {
+			out.example, out.hasExample = text, true
+		}
+	}
+	return out, true
+}
+
+func mediaType(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return ""
+	}
+	if mt, _, err := mime.ParseMediaType(v); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(strings.Split(v, ";")[0]))
+}
+
+func isJSON(ct string) bool {
+	return ct == "application/json" || strings.HasSuffix(ct, "+json")
+}
+
+// expand replaces the placeholders res knows and leaves the rest.
+func expand(s string, res resolver) string {
+	if !vars.HasPlaceholder(s) {
+		return s
+	}
+	return vars.ReplaceTemplateVars(s, func(match, name string) string {
+		if v, ok := res(name); ok {
+			return v
+		}
+		return match
+	})
+}
+
+// decodeJSON parses a JSON body that may carry placeholders. Inside strings a
+// known value is spliced in; a bare placeholder becomes its value when that is
+// valid JSON, a string otherwise, or a hole when nothing resolves it.
+func decodeJSON(text string, res resolver) (any, error) {
+	var b strings.Builder
+	inStr, esc := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '{' && strings.HasPrefix(text[i:], "{{") {
+			end := strings.Index(text[i+2:], "}}")
+			if end >= 0 {
+				match := text[i : i+2+end+2]
+				name := strings.TrimSpace(text[i+2 : i+2+end])
+				v, ok := res(name)
+				switch {
+				case inStr && ok:
+					q, _ := json.Marshal(v)
+					b.Write(q[1 : len(q)-1])
+				case inStr:
+					b.WriteString(match)
+				case ok && json.Valid([]byte(v)):
+					b.WriteString(v)
+				case ok:
+					q, _ := json.Marshal(v)
+					b.Write(q)
+				default:
+					b.WriteString(holeJSON)
+				}
+				i += len(match) - 1
+				continue
+			}
+		}
+		switch {
+		case esc:
+			esc = false
+		case inStr && c == '\\':
+			esc = true
+		case c == '"':
+			inStr = !inStr
+		}
+		b.WriteByte(c)
+	}
+	dec := json.NewDecoder(strings.NewReader(b.String()))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errTrailingData
+	}
+	return v, nil
+}
+
+var errTrailingData = errors.New("unexpected data after the JSON value")
+
+// example drops the values a placeholder still stands in for, so what is left
+// is real data. Nothing is left when the whole value was a placeholder.
+func example(v any) (any, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, x != hole && !vars.HasPlaceholder(x)
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, item := range x {
+			if item, ok := example(item); ok {
+				out = append(out, item)
+			}
+		}
+		return out, len(out) > 0 || len(x) == 0
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, item := range x {
+			if item, ok := example(item); ok {
+				out[k] = item
+			}
+		}
+		return out, len(out) > 0 || len(x) == 0
+	}
+	return v, true
+}
+
+// decodePlainJSON reads a recorded body, where {{ is ordinary text.
+func decodePlainJSON(text string) (any, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || (text[0] != '{' && text[0] != '[') {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(text)))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil, false
+	}
+	return v, true
+}
+
+func formSchema(text string, res resolver) (*model.Schema, any, bool) {
+	s := &model.Schema{
+		Types:      []model.SchemaType{model.TypeObject},
+		Properties: map[string]*model.SchemaRef{},
+	}
+	ex := map[string]any{}
+	complete := true
+	for part := range strings.SplitSeq(text, "&") {
+		k, v, _ := strings.Cut(part, "=")
+		if key, err := url.QueryUnescape(k); err == nil {
+			k = key
+		}
+		if k == "" || vars.HasPlaceholder(k) {
+			continue
+		}
+		v = expand(v, res)
+		if val, err := url.QueryUnescape(v); err == nil {
+			v = val
+		}
+		if vars.HasPlaceholder(v) {
+			complete = false
+			s.Properties[k] = ref(&model.Schema{Types: []model.SchemaType{model.TypeString}})
+			continue
+		}
+		s.Properties[k] = ref(literalSchema(v))
+		ex[k] = literalValue(v)
+	}
+	if len(s.Properties) == 0 {
+		return s, nil, false
+	}
+	return s, ex, complete
+}
+
+var formPart = regexp.MustCompile(`(?i)content-disposition:\s*form-data;\s*name="([^"]+)"(;\s*filename=)?`)
+
+// multipartSchema lists the parts a multipart body names. File parts become
+// binary strings.
+func multipartSchema(text string) *model.Schema {
+	s := &model.Schema{Types: []model.SchemaType{model.TypeObject}}
+	for _, m := range formPart.FindAllStringSubmatch(text, -1) {
+		if s.Properties == nil {
+			s.Properties = map[string]*model.SchemaRef{}
+		}
+		p := &model.Schema{Types: []model.SchemaType{model.TypeString}}
+		if m[2] != "" {
+			p.Format = "binary"
+		}
+		s.Properties[m[1]] = ref(p)
+	}
+	return s
+}
+
+// literalSchema types a query, header or form value written as text.
+func literalSchema(v string) *model.Schema {
+	switch {
+	case v == "true" || v == "false":
+		return &model.Schema{Types: []model.SchemaType{model.TypeBoolean}}
+	case isInteger(v):
+		return &model.Schema{Types: []model.SchemaType{model.TypeInteger}}
+	case isNumber(v):
+		return &model.Schema{Types: []model.SchemaType{model.TypeNumber}}
+	}
+	return &model.Schema{Types: []model.SchemaType{model.TypeString}, Format: stringFormat(v)}
+}
+
+// literalValue is the typed example for a value literalSchema typed.
+func literalValue(v string) any {
+	switch {
+	case v == "true" || v == "false":
+		return v == "true"
+	case isInteger(v), isNumber(v):
+		return json.Number(v)
+	}
+	return v
+}
+
+func isInteger(v string) bool {
+	if v == "" || (len(v) > 1 && v[0] == '0') {
+		return false
+	}
+	_, err := strconv.ParseInt(v, 10, 64)
+	return err == nil
+}
+
+func isNumber(v string) bool {
+	if v == "" || strings.ContainsAny(v, "xXnN_") {
+		return false
+	}
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+func ref(s *model.Schema) *model.SchemaRef {
+	return &model.SchemaRef{Node: s}
+}
+
+// schemaOf describes one decoded JSON value. Objects list every key as
+// required; merging later samples narrows that to the keys all of them share.
+func schemaOf(v any) *model.Schema {
+	switch x := v.(type) {
+	case nil:
+		return &model.Schema{Types: []model.SchemaType{model.TypeNull}}
+	case bool:
+		return &model.Schema{Types: []model.SchemaType{model.TypeBoolean}}
+	case json.Number:
+		if _, err := x.Int64(); err == nil {
+			return &model.Schema{Types: []model.SchemaType{model.TypeInteger}}
+		}
+		return &model.Schema{Types: []model.SchemaType{model.TypeNumber}}
+	case string:
+		switch {
+		case x == hole:
+			return &model.Schema{}
+		case vars.HasPlaceholder(x):
+			return &model.Schema{Types: []model.SchemaType{model.TypeString}}
+		}
+		return &model.Schema{Types: []model.SchemaType{model.TypeString}, Format: stringFormat(x)}
+	case []any:
+		var items *model.Schema
+		for _, item := range x {
+			items = merge(items, schemaOf(item))
+		}
+		if items == nil {
+			items = &model.Schema{}
+		}
+		return &model.Schema{Types: []model.SchemaType{model.TypeArray}, Items: ref(items)}
+	case map[string]any:
+		s := &model.Schema{
+			Types:      []model.SchemaType{model.TypeObject},
+			Properties: make(map[string]*model.SchemaRef, len(x)),
+		}
+		for k, item := range x {
+			s.Properties[k] = ref(schemaOf(item))
+			s.Required = append(s.Required, k)
+		}
+		sort.Strings(s.Required)
+		return s
+	}
+	return &model.Schema{}
+}
+
+var (
+	uuidRe  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	emailRe = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+func stringFormat(v string) string {
+	switch {
+	case uuidRe.MatchString(v):
+		return "uuid"
+	case emailRe.MatchString(v):
+		return "email"
+	}
+	if _, err := time.Parse(time.RFC3339, v); err == nil {
+		return "date-time"
+	}
+	if _, err := time.Parse(time.DateOnly, v); err == nil {
+		return "date"
+	}
+	if u, err := url.Parse(v); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		return "uri"
+	}
+	return ""
+}
+
+// isAny reports a schema nothing is known about yet.
+func isAny(s *model.Schema) bool {
+	return len(s.Types) == 0 && len(s.Properties) == 0 && s.Items == nil
+}
+
+// merge combines the schemas of two samples of the same value. Types are
+// unioned, with integer widened to number next to a number; object keys are
+// required only when every sample has them.
+func merge(a, b *model.Schema) *model.Schema {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case isAny(a):
+		return b
+	case isAny(b):
+		return a
+	}
+	out := &model.Schema{Types: mergeTypes(a.Types, b.Types)}
+	if a.Format == b.Format {
+		out.Format = a.Format
+	}
+	switch {
+	case a.Items != nil && b.Items != nil:
+		out.Items = ref(merge(a.Items.Node, b.Items.Node))
+	case a.Items != nil:
+		out.Items = a.Items
+	case b.Items != nil:
+		out.Items = b.Items
+	}
+	if len(a.Properties) > 0 || len(b.Properties) > 0 {
+		out.Properties = map[string]*model.SchemaRef{}
+		for k, p := range a.Properties {
+			out.Properties[k] = p
+		}
+		for k, p := range b.Properties {
+			if prev, ok := out.Properties[k]; ok {
+				out.Properties[k] = ref(merge(prev.Node, p.Node))
+			} else {
+				out.Properties[k] = p
+			}
+		}
+	}
+	aObj, bObj := slices.Contains(a.Types, model.TypeObject), slices.Contains(b.Types, model.TypeObject)
+	switch {
+	case aObj && bObj:
+		for _, k := range a.Required {
+			if slices.Contains(b.Required, k) {
+				out.Required = append(out.Required, k)
+			}
+		}
+	case aObj:
+		out.Required = a.Required
+	case bObj:
+		out.Required = b.Required
+	}
+	return out
+}
+
+func mergeTypes(a, b []model.SchemaType) []model.SchemaType {
+	out := append([]model.SchemaType(nil), a...)
+	for _, t := range b {
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	if slices.Contains(out, model.TypeNumber) && slices.Contains(out, model.TypeInteger) {
+		kept := out[:0]
+		for _, t := range out {
+			if t != model.TypeInteger {
+				kept = append(kept, t)
+			}
+		}
+		out = kept
+	}
+	// null reads best last, as in ["string", "null"].
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i] != model.TypeNull && out[j] == model.TypeNull
+	})
+	return out
+}
@@ -0,0 +1,89 @@
+package infer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+)
+
+func TestDecodeJSONPlaceholders(t *testing.T) {
+	res := func(name string) (string, bool) {
+		switch name {
+		case "n":
+			return "3", true
+		case "who":
+			return `Ada "A"`, true
+		}
+		return "", false
+	}
+	v, err := decodeJSON(`{"n": {{n}}, "who": {{who}}, "msg": "hi {{who}}", "raw": "{{missing}}", "id": {{missing}}}`, res)
+	if err != nil {
+		t.Fatalf("decodeJSON: %v", err)
+	}
+	got, ok := example(v)
+	if !ok {
+		t.Fatalf("example dropped everything")
+	}
+	want := map[string]any{"n": json.Number("3"), "who": `Ada "A"`, "msg": `hi Ada "A"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("example = %#v", got)
+	}
+
+	s := schemaOf(v)
+	if !isAny(s.Properties["id"].Node) || s.Properties["raw"].Node.Types[0] != model.TypeString {
+		t.Fatalf("schema = %+v", s.Properties)
+	}
+	if _, err := decodeJSON(`{"a": 1} trailing`, noVars); err == nil {
+		t.Fatalf("trailing data was accepted")
+	}
+}
+
+func TestExampleWholePlaceholder(t *testing.T) {
+	if _, ok := example(hole); ok {
+		t.Fatalf("a bare placeholder became an example")
+	}
+	if v, ok := example([]any{}); !ok || len(v.([]any)) != 0 {
+		t.Fatalf("empty array = %v %v", v, ok)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := schemaOf(map[string]any{"id": json.Number("1"), "name": "x", "tags": []any{"a"}})
+	b := schemaOf(map[string]any{"id": json.Number("1.5"), "name": nil, "extra": true})
+	m := merge(a, b)
+
+	if got := strings.Join(m.Required, ","); got != "id,name" {
+		t.Fatalf("required = %q", got)
+	}
+	if got := m.Properties["id"].Node.Types; !reflect.DeepEqual(got, []model.SchemaType{model.TypeNumber}) {
+		t.Fatalf("id types = %v", got)
+	}
+	if got := m.Properties["name"].Node.Types; !reflect.DeepEqual(got, []model.SchemaType{model.TypeString, model.TypeNull}) {
+		t.Fatalf("name types = %v", got)
+	}
+	if m.Properties["extra"] == nil || m.Properties["tags"] == nil {
+		t.Fatalf("properties from one side were lost: %+v", m.Properties)
+	}
+	if merge(nil, b) != b || merge(a, &model.Schema{}) != a {
+		t.Fatalf("an empty side should leave the other as is")
+	}
+}
+
+func TestStringFormat(t *testing.T) {
+	cases := map[string]string{
+		"5f0c6a8e-0d2b-4f53-9a55-3c2d1f0b7e11": "uuid",
+		"ada@example.com":                      "email",
+		"2026-01-02T03:04:05Z":                 "date-time",
+		"2026-01-02":                           "date",
+		"https://example.com/x":                "uri",
+		"plain":                                "",
+	}
+	for in, want := range cases {
+		if got := stringFormat(in); got != want {
+			t.Errorf("stringFormat(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
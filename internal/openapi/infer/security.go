@@ -0,0 +1,175 @@
+package infer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/engine"
+	"github.com/unkn0wn-root/resterm/internal/openapi"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/registry"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+// authUse is where a request's credential travels, so the same header or
+// query value is not also listed as a parameter.
+type authUse struct {
+	in   model.ParameterLocation
+	name string
+}
+
+// auth maps the request's @auth, or the file's default profile, onto a
+// security scheme. An Authorization header written by hand counts as well.
+func (b *builder) auth(doc *restfile.Document, req *restfile.Request, op *operation) *authUse {
+	if req.Metadata.AuthDisabled {
+		return nil
+	}
+	spec := req.Metadata.Auth
+	if spec == nil {
+		if pf, ok := (*registry.Index)(nil).DefaultAuth(doc); ok {
+			spec = &pf.Spec
+		}
+	}
+	if spec == nil {
+		b.authHeader(req, op)
+		return nil
+	}
+	res := requestVars(doc, req)
+	param := func(k string) string { return expand(strings.TrimSpace(spec.Params[k]), res) }
+	// Token endpoints are not secret, so environments may fill them in.
+	urlParam := func(k string) string { return expand(param(k), b.envVar) }
+
+	var (
+		name   string
+		scheme model.SecurityScheme
+		scopes []string
+		use    *authUse
+	)
+	switch spec.Kind() {
+	case restfile.AuthBasic:
+		name, scheme = "basicAuth", model.SecurityScheme{Type: model.SecurityHTTP, Subtype: "basic"}
+	case restfile.AuthBearer:
+		name, scheme = "bearerAuth", model.SecurityScheme{Type: model.SecurityHTTP, Subtype: "bearer"}
+	case restfile.AuthDigest:
+		name, scheme = "digestAuth", model.SecurityScheme{Type: model.SecurityHTTP, Subtype: "digest"}
+	case restfile.AuthAPIKey:
+		in := model.InHeader
+		if strings.EqualFold(param("placement"), "query") {
+			in = model.InQuery
+		}
+		key := param("name")
+		if key == "" && in == model.InHeader {
+			key = "X-API-Key"
+		}
+		name = "apiKeyAuth"
+		scheme = model.SecurityScheme{Type: model.SecurityAPIKey, Name: key, In: in}
+		use = &authUse{in: in, name: key}
+	case restfile.AuthOAuth2:
+		flow, ok := oauthFlow(param, urlParam)
+		if !ok {
+			b.warnf("request %q: oauth2 grant %q is not described", engine.ReqID(req), param(openapi.OAuthParamGrant))
+			return nil
+		}
+		scopes = flow.Scopes
+		name = "oauth2"
+		scheme = model.SecurityScheme{Type: model.SecurityOAuth2, OAuthFlows: []model.OAuthFlow{flow}}
+	default:
+		b.warnf("request %q: %s auth has no OpenAPI security scheme", engine.ReqID(req), spec.Kind())
+		return nil
+	}
+	op.addSecurity(model.SecurityRequirement{SchemeName: b.scheme(name, scheme), Scopes: scopes})
+	return use
+}
+
+func (b *builder) authHeader(req *restfile.Request, op *operation) {
+	v := strings.TrimSpace(req.Headers.Get("Authorization"))
+	kind, _, _ := strings.Cut(v, " ")
+	switch strings.ToLower(kind) {
+	case "bearer":
+		op.addSecurity(model.SecurityRequirement{SchemeName: b.scheme("bearerAuth",
+			model.SecurityScheme{Type: model.SecurityHTTP, Subtype: "bearer"})})
+	case "basic":
+		op.addSecurity(model.SecurityRequirement{SchemeName: b.scheme("basicAuth",
+			model.SecurityScheme{Type: model.SecurityHTTP, Subtype: "basic"})})
+	}
+}
+
+// scheme registers s under name, or under name2, name3... when a different
+// scheme already has it. OAuth flows of one grant merge their scopes.
+func (b *builder) scheme(name string, s model.SecurityScheme) string {
+	try := name
+	for n := 2; ; n++ {
+		prev, ok := b.schemes[try]
+		if !ok {
+			b.schemes[try] = s
+			return try
+		}
+		if merged, ok := sameScheme(prev, s); ok {
+			b.schemes[try] = merged
+			return try
+		}
+		try = name + strconv.Itoa(n)
+	}
+}
+
+func sameScheme(a, b model.SecurityScheme) (model.SecurityScheme, bool) {
+	if a.Type != b.Type || a.Subtype != b.Subtype || a.Name != b.Name || a.In != b.In {
+		return a, false
+	}
+	if a.Type != model.SecurityOAuth2 {
+		return a, true
+	}
+	if len(a.OAuthFlows) != 1 || len(b.OAuthFlows) != 1 {
+		return a, false
+	}
+	fa, fb := a.OAuthFlows[0], b.OAuthFlows[0]
+	if fa.Type != fb.Type || fa.TokenURL != fb.TokenURL || fa.AuthorizationURL != fb.AuthorizationURL {
+		return a, false
+	}
+	for _, sc := range fb.Scopes {
+		if !containsString(fa.Scopes, sc) {
+			fa.Scopes = append(fa.Scopes, sc)
+		}
+	}
+	a.OAuthFlows = []model.OAuthFlow{fa}
+	return a, true
+}
+
+func oauthFlow(param, urlParam func(string) string) (model.OAuthFlow, bool) {
+	f := model.OAuthFlow{
+		TokenURL: urlOrEmpty(urlParam(openapi.OAuthParamTokenURL)),
+		Scopes:   strings.Fields(param(openapi.OAuthParamScope)),
+	}
+	switch strings.ToLower(param(openapi.OAuthParamGrant)) {
+	case "", openapi.OAuthGrantClientCredentials:
+		f.Type = model.OAuthFlowClientCredentials
+	case openapi.OAuthGrantPassword:
+		f.Type = model.OAuthFlowPassword
+	case openapi.OAuthGrantAuthorizationCode:
+		f.Type = model.OAuthFlowAuthorizationCode
+		f.AuthorizationURL = urlOrEmpty(urlParam(openapi.OAuthParamAuthURL))
+	default:
+		return f, false
+	}
+	return f, true
+}
+
+// A URL that still holds a placeholder would not be a valid URL in the spec.
+func urlOrEmpty(u string) string {
+	if vars.HasPlaceholder(u) {
+		return ""
+	}
+	return u
+}
+
+// envVar reads name from the first environment that sets it to a literal.
+func (b *builder) envVar(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	for _, env := range b.opt.Envs {
+		if v, ok := env.Values[name]; ok && !vars.HasPlaceholder(v) {
+			return v, true
+		}
+	}
+	return "", false
+}
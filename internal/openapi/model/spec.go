@@ -117,6 +117,7 @@ type Response struct {
 type Header struct {
 	Name        string
 	Description string
+	Required    bool
 	Example     Example
 	Schema      *SchemaRef
 }
@@ -172,9 +173,11 @@ type Schema struct {
 	Items                *SchemaRef
 	Properties           map[string]*SchemaRef
 	AdditionalProperties *SchemaRef
-	OneOf                []*SchemaRef
-	AnyOf                []*SchemaRef
-	AllOf                []*SchemaRef
+	// NoAdditionalProperties is set when additionalProperties is false.
+	NoAdditionalProperties bool
+	OneOf                  []*SchemaRef
+	AnyOf                  []*SchemaRef
+	AllOf                  []*SchemaRef
 }
 
 func (s *Schema) ExampleValue() (any, bool) {
@@ -622,6 +622,7 @@ func convertResponseHeader(name string, raw *h3.Header, sm *schMap) model.Header
 	header := model.Header{
 		Name:        name,
 		Description: raw.Description,
+		Required:    raw.Required,
 		Example:     extractExample(raw.Example, raw.Examples, sm, "response header "+name),
 	}
 	if raw.Schema != nil {
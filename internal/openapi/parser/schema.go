@@ -91,6 +91,8 @@ func (m *schMap) toSch(src *hbase.Schema) *model.Schema {
 
 	if ap := dynRef(src.AdditionalProperties); ap != nil {
 		out.AdditionalProperties = m.toRef(ap)
+	} else if ap := src.AdditionalProperties; ap != nil && ap.IsB() && !ap.B {
+		out.NoAdditionalProperties = true
 	}
 
 	if len(src.OneOf) > 0 {
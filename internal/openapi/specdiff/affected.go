@@ -0,0 +1,164 @@
+package specdiff
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/infer"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+// Request is a workspace request reduced to what route matching needs. Ref
+// is "file:line" relative to the workspace, followed by the request name when
+// it has one.
+type Request struct {
+	Method string
+	Path   string
+	Ref    string
+}
+
+// Requests lists the HTTP requests in docs. Files are shown relative to root.
+func Requests(docs []*restfile.Document, root string) []Request {
+	var out []Request
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for _, req := range doc.Requests {
+			method, path, ok := infer.Route(req)
+			if !ok {
+				continue
+			}
+			out = append(out, Request{Method: string(method), Path: path, Ref: requestRef(req, root)})
+		}
+	}
+	return out
+}
+
+func requestRef(req *restfile.Request, root string) string {
+	file := req.SourcePath
+	if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+	ref := filepath.ToSlash(file)
+	if req.LineRange.Start > 0 {
+		ref += ":" + strconv.Itoa(req.LineRange.Start)
+	}
+	if name := strings.TrimSpace(req.Metadata.Name); name != "" {
+		ref += " " + name
+	}
+	return ref
+}
+
+// Attach fills Requests on each change with the requests that call its
+// operation. Server base paths from both specs are tried, so a request to
+// {{baseUrl}}/v1/users matches /users under a /v1 server.
+func Attach(changes []Change, reqs []Request, specs ...*model.Spec) {
+	bases := BasePaths(specs...)
+	cache := map[string][]string{}
+	for i := range changes {
+		c := &changes[i]
+		key := strings.ToUpper(c.Method) + " " + c.Path
+		refs, ok := cache[key]
+		if !ok {
+			refs = matching(c.Method, c.Path, reqs, bases)
+			cache[key] = refs
+		}
+		c.Requests = refs
+	}
+}
+
+func matching(method, path string, reqs []Request, bases []string) []string {
+	var out []string
+	for _, r := range reqs {
+		if !strings.EqualFold(r.Method, method) {
+			continue
+		}
+		if _, ok := MatchRoute(r.Path, path, bases); ok {
+			out = append(out, r.Ref)
+		}
+	}
+	return out
+}
+
+// MatchRoute reports whether a request path calls specPath once one of bases
+// is stripped from it. A templated segment on either side matches any value,
+// since requests often hard-code ids the spec templates. The score counts the
+// literal segments that agree, so the most specific of several matching
+// operations can be picked.
+func MatchRoute(reqPath, specPath string, bases []string) (int, bool) {
+	best, found := 0, false
+	for _, base := range bases {
+		if base != "" && !strings.HasPrefix(reqPath, base) {
+			continue
+		}
+		if score, ok := samePath(strings.TrimPrefix(reqPath, base), specPath); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+func samePath(a, b string) (int, bool) {
+	as := segments(a)
+	bs := segments(b)
+	if len(as) != len(bs) {
+		return 0, false
+	}
+	score := 0
+	for i := range as {
+		switch {
+		case templated(as[i]) || templated(bs[i]):
+		case as[i] == bs[i]:
+			score++
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+func segments(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+func templated(seg string) bool {
+	return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+// BasePaths lists the server path prefixes of specs, such as /v1, followed by
+// the empty prefix.
+func BasePaths(specs ...*model.Spec) []string {
+	var out []string
+	seen := map[string]bool{}
+	addServers := func(servers []model.Server) {
+		for _, s := range servers {
+			u, err := url.Parse(s.URL)
+			if err != nil {
+				continue
+			}
+			p := strings.TrimRight(u.Path, "/")
+			if p != "" && !seen[p] {
+				seen[p] = true
+				out = append(out, p)
+			}
+		}
+	}
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+		addServers(spec.Servers)
+		for _, op := range spec.Operations {
+			addServers(op.Servers)
+		}
+	}
+	return append(out, "")
+}
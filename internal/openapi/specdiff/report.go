@@ -0,0 +1,163 @@
+package specdiff
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report is the result of comparing two spec files.
+type Report struct {
+	Old     string
+	New     string
+	Changes []Change
+}
+
+func (r *Report) Breaking() int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Breaking() {
+			n++
+		}
+	}
+	return n
+}
+
+func opLabel(c Change) string {
+	label := strings.ToUpper(c.Method) + " " + c.Path
+	if c.Operation != "" {
+		label += " (" + c.Operation + ")"
+	}
+	return label
+}
+
+// WriteText prints changes grouped by operation, each group followed by the
+// requests it affects.
+func WriteText(w io.Writer, r *Report) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "Comparing %s -> %s\n", r.Old, r.New)
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(bw, "No changes.")
+		return bw.Flush()
+	}
+	for i := 0; i < len(r.Changes); {
+		j := i
+		for j < len(r.Changes) && opLabel(r.Changes[j]) == opLabel(r.Changes[i]) {
+			j++
+		}
+		fmt.Fprintf(bw, "\n%s\n", opLabel(r.Changes[i]))
+		for _, c := range r.Changes[i:j] {
+			mark := "change  "
+			if c.Breaking() {
+				mark = "BREAKING"
+			}
+			fmt.Fprintf(bw, "  %s %s\n", mark, c.Where())
+		}
+		if reqs := r.Changes[i].Requests; len(reqs) > 0 {
+			fmt.Fprintln(bw, "  Affected requests:")
+			for _, ref := range reqs {
+				fmt.Fprintf(bw, "    %s\n", ref)
+			}
+		}
+		i = j
+	}
+	fmt.Fprintf(bw, "\nSummary: %d changes, %d breaking\n", len(r.Changes), r.Breaking())
+	return bw.Flush()
+}
+
+type jsonReport struct {
+	Old     string       `json:"old"`
+	New     string       `json:"new"`
+	Summary jsonSummary  `json:"summary"`
+	Changes []jsonChange `json:"changes"`
+}
+
+type jsonSummary struct {
+	Total       int `json:"total"`
+	Breaking    int `json:"breaking"`
+	NonBreaking int `json:"nonBreaking"`
+}
+
+type jsonChange struct {
+	Severity  Severity `json:"severity"`
+	Kind      Kind     `json:"kind"`
+	Method    string   `json:"method"`
+	Path      string   `json:"path"`
+	Operation string   `json:"operationId,omitempty"`
+	Location  string   `json:"location,omitempty"`
+	Message   string   `json:"message"`
+	Requests  []string `json:"requests,omitempty"`
+}
+
+func WriteJSON(w io.Writer, r *Report) error {
+	br := r.Breaking()
+	out := jsonReport{
+		Old: r.Old,
+		New: r.New,
+		Summary: jsonSummary{
+			Total:       len(r.Changes),
+			Breaking:    br,
+			NonBreaking: len(r.Changes) - br,
+		},
+		Changes: make([]jsonChange, 0, len(r.Changes)),
+	}
+	for _, c := range r.Changes {
+		out.Changes = append(out.Changes, jsonChange(c))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit reports one test case per change. Breaking changes fail, so a
+// CI job can gate on them without parsing the output.
+func WriteJUnit(w io.Writer, r *Report) error {
+	suite := junitSuite{
+		Name:     "openapi diff " + r.Old + " -> " + r.New,
+		Tests:    len(r.Changes),
+		Failures: r.Breaking(),
+		Cases:    make([]junitCase, 0, len(r.Changes)),
+	}
+	for _, c := range r.Changes {
+		tc := junitCase{Name: c.Where(), ClassName: opLabel(c)}
+		if c.Breaking() {
+			body := c.Where()
+			if len(c.Requests) > 0 {
+				body += "\nAffected requests:\n  " + strings.Join(c.Requests, "\n  ")
+			}
+			tc.Failure = &junitFailure{Message: c.Message, Type: string(c.Kind), Body: body}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	_, _ = io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,296 @@
+package specdiff
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+)
+
+// schemaWalk compares one schema pair. Input schemas describe what clients
+// send, so narrowing them breaks callers; output schemas describe what they
+// read, so removing or loosening guarantees breaks them instead.
+type schemaWalk struct {
+	d    *differ
+	base string
+	in   bool
+	seen map[[2]string]bool
+}
+
+func (d *differ) schema(loc string, o, n *model.SchemaRef, in bool) {
+	w := &schemaWalk{d: d, base: loc, in: in, seen: map[[2]string]bool{}}
+	w.walk("", o, n)
+}
+
+func node(ref *model.SchemaRef) *model.Schema {
+	if ref == nil {
+		return nil
+	}
+	return ref.Node
+}
+
+func refKey(ref *model.SchemaRef) string {
+	if ref.Identifier != "" {
+		return ref.Identifier
+	}
+	return fmt.Sprintf("%p", ref.Node)
+}
+
+func (w *schemaWalk) add(sev Severity, kind Kind, ptr, format string, args ...any) {
+	loc := w.base
+	if ptr != "" {
+		loc += " " + ptr
+	}
+	w.d.add(sev, kind, loc, format, args...)
+}
+
+// sev picks the severity for a change that narrows the schema when narrow
+// is true and widens it otherwise.
+func (w *schemaWalk) sev(narrow bool) Severity {
+	if narrow == w.in {
+		return Breaking
+	}
+	return NonBreaking
+}
+
+// walk visits each pair of named components once, so a recursive schema
+// reports its changes at the shallowest path that reaches them.
+func (w *schemaWalk) walk(ptr string, oref, nref *model.SchemaRef) {
+	o, n := node(oref), node(nref)
+	if o == nil || n == nil {
+		return
+	}
+	key := [2]string{refKey(oref), refKey(nref)}
+	if w.seen[key] {
+		return
+	}
+	w.seen[key] = true
+	o, n = flatten(o), flatten(n)
+
+	w.types(ptr, o, n)
+	w.enum(ptr, o, n)
+	if w.in {
+		w.constraints(ptr, o, n)
+	}
+	w.properties(ptr, o, n)
+	if o.Items != nil && n.Items != nil {
+		w.walk(ptr+"/*", o.Items, n.Items)
+	}
+}
+
+func (w *schemaWalk) types(ptr string, o, n *model.Schema) {
+	ot, on := typeSet(o)
+	nt, nn := typeSet(n)
+	if len(ot) > 0 && len(nt) > 0 && !slices.Equal(ot, nt) {
+		lost := slices.ContainsFunc(ot, func(t model.SchemaType) bool { return !slices.Contains(nt, t) })
+		gained := slices.ContainsFunc(nt, func(t model.SchemaType) bool { return !slices.Contains(ot, t) })
+		sev := NonBreaking
+		if (w.in && lost) || (!w.in && gained) {
+			sev = Breaking
+		}
+		w.add(sev, KindTypeChanged, ptr, "type changed from %s to %s", joinTypes(ot), joinTypes(nt))
+	}
+	switch {
+	case on && !nn:
+		w.add(w.sev(true), KindNullable, ptr, "no longer nullable")
+	case !on && nn:
+		w.add(w.sev(false), KindNullable, ptr, "became nullable")
+	}
+}
+
+func typeSet(s *model.Schema) ([]model.SchemaType, bool) {
+	nullable := s.Nullable != nil && *s.Nullable
+	var out []model.SchemaType
+	for _, t := range model.SchemaTypesFromStrings(typeStrings(s.Types)) {
+		if t == model.TypeNull {
+			nullable = true
+			continue
+		}
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	slices.Sort(out)
+	return out, nullable
+}
+
+func typeStrings(ts []model.SchemaType) []string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = string(t)
+	}
+	return out
+}
+
+func joinTypes(ts []model.SchemaType) string {
+	return strings.Join(typeStrings(ts), "|")
+}
+
+func (w *schemaWalk) enum(ptr string, o, n *model.Schema) {
+	if len(n.Enum) == 0 {
+		if len(o.Enum) > 0 {
+			w.add(w.sev(false), KindEnumWidened, ptr, "enum removed")
+		}
+		return
+	}
+	if len(o.Enum) == 0 {
+		w.add(w.sev(true), KindEnumNarrowed, ptr, "enum added (%s)", enumList(n.Enum))
+		return
+	}
+	removed := enumMissing(o.Enum, n.Enum)
+	added := enumMissing(n.Enum, o.Enum)
+	if len(removed) > 0 {
+		w.add(w.sev(true), KindEnumNarrowed, ptr, "enum narrowed, removed %s", enumList(removed))
+	}
+	if len(added) > 0 {
+		// A response enum growing is reported but not failed: most clients
+		// pass unknown values through rather than reject them.
+		w.add(NonBreaking, KindEnumWidened, ptr, "enum values added %s", enumList(added))
+	}
+}
+
+func enumMissing(from, in []any) []any {
+	have := make(map[string]bool, len(in))
+	for _, v := range in {
+		have[enumKey(v)] = true
+	}
+	var out []any
+	for _, v := range from {
+		if !have[enumKey(v)] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func enumKey(v any) string {
+	return fmt.Sprintf("%T:%v", v, v)
+}
+
+func enumList(vs []any) string {
+	parts := make([]string, len(vs))
+	for i, v := range vs {
+		if s, ok := v.(string); ok {
+			parts[i] = fmt.Sprintf("%q", s)
+		} else {
+			parts[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// constraints only reports tightened bounds on input; a server accepting
+// more than before breaks nobody.
+func (w *schemaWalk) constraints(ptr string, o, n *model.Schema) {
+	if tighterMin(o.Min, n.Min) {
+		w.add(Breaking, KindConstraint, ptr, "minimum raised to %v", *n.Min)
+	}
+	if tighterMax(o.Max, n.Max) {
+		w.add(Breaking, KindConstraint, ptr, "maximum lowered to %v", *n.Max)
+	}
+	if tighterMin(o.MinLen, n.MinLen) {
+		w.add(Breaking, KindConstraint, ptr, "minLength raised to %d", *n.MinLen)
+	}
+	if tighterMax(o.MaxLen, n.MaxLen) {
+		w.add(Breaking, KindConstraint, ptr, "maxLength lowered to %d", *n.MaxLen)
+	}
+}
+
+func tighterMin[T int64 | float64](o, n *T) bool {
+	return n != nil && (o == nil || *n > *o)
+}
+
+func tighterMax[T int64 | float64](o, n *T) bool {
+	return n != nil && (o == nil || *n < *o)
+}
+
+func (w *schemaWalk) properties(ptr string, o, n *model.Schema) {
+	if len(o.Properties) == 0 && len(n.Properties) == 0 {
+		return
+	}
+	oreq, nreq := setOf(o.Required), setOf(n.Required)
+	for _, name := range slices.Sorted(maps.Keys(o.Properties)) {
+		p := ptr + "/" + name
+		np, ok := n.Properties[name]
+		if !ok {
+			if w.in {
+				w.add(NonBreaking, KindPropertyRemoved, p, "property removed")
+			} else {
+				w.add(Breaking, KindPropertyRemoved, p, "property removed")
+			}
+			continue
+		}
+		switch {
+		case w.in && nreq[name] && !oreq[name]:
+			w.add(Breaking, KindPropertyRequired, p, "property became required")
+		case !w.in && oreq[name] && !nreq[name]:
+			w.add(Breaking, KindPropertyOptional, p, "property is no longer required")
+		}
+		w.walk(p, o.Properties[name], np)
+	}
+	for _, name := range slices.Sorted(maps.Keys(n.Properties)) {
+		if _, ok := o.Properties[name]; ok {
+			continue
+		}
+		p := ptr + "/" + name
+		if w.in && nreq[name] {
+			w.add(Breaking, KindPropertyAdded, p, "required property added")
+		} else {
+			w.add(NonBreaking, KindPropertyAdded, p, "property added")
+		}
+	}
+}
+
+func setOf(names []string) map[string]bool {
+	out := make(map[string]bool, len(names))
+	for _, n := range names {
+		out[n] = true
+	}
+	return out
+}
+
+// flatten folds allOf members into one schema so composed objects compare
+// by their combined properties.
+func flatten(s *model.Schema) *model.Schema {
+	if len(s.AllOf) == 0 {
+		return s
+	}
+	out := *s
+	out.AllOf = nil
+	out.Properties = maps.Clone(s.Properties)
+	out.Required = slices.Clone(s.Required)
+	seen := map[*model.Schema]bool{s: true}
+	var merge func(refs []*model.SchemaRef)
+	merge = func(refs []*model.SchemaRef) {
+		for _, ref := range refs {
+			part := node(ref)
+			if part == nil || seen[part] {
+				continue
+			}
+			seen[part] = true
+			if len(out.Types) == 0 {
+				out.Types = part.Types
+			}
+			if len(out.Enum) == 0 {
+				out.Enum = part.Enum
+			}
+			if out.Items == nil {
+				out.Items = part.Items
+			}
+			for name, p := range part.Properties {
+				if out.Properties == nil {
+					out.Properties = map[string]*model.SchemaRef{}
+				}
+				if _, ok := out.Properties[name]; !ok {
+					out.Properties[name] = p
+				}
+			}
+			out.Required = append(out.Required, part.Required...)
+			merge(part.AllOf)
+		}
+	}
+	merge(s.AllOf)
+	return &out
+}
@@ -0,0 +1,319 @@
+// Package specdiff compares two versions of an OpenAPI spec and classifies
+// each change as breaking or not for existing clients. A change is breaking
+// when a request that worked against the old spec may now be rejected, or a
+// client reading a response may now find something it did not expect.
+package specdiff
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+)
+
+type Severity string
+
+const (
+	Breaking    Severity = "breaking"
+	NonBreaking Severity = "non-breaking"
+)
+
+type Kind string
+
+const (
+	KindOperationRemoved    Kind = "operation-removed"
+	KindOperationAdded      Kind = "operation-added"
+	KindOperationDeprecated Kind = "operation-deprecated"
+	KindParamAdded          Kind = "param-added"
+	KindParamRemoved        Kind = "param-removed"
+	KindParamRequired       Kind = "param-required"
+	KindBodyRequired        Kind = "body-required"
+	KindMediaTypeRemoved    Kind = "media-type-removed"
+	KindMediaTypeAdded      Kind = "media-type-added"
+	KindResponseRemoved     Kind = "response-removed"
+	KindResponseAdded       Kind = "response-added"
+	KindHeaderRemoved       Kind = "header-removed"
+	KindPropertyRemoved     Kind = "property-removed"
+	KindPropertyAdded       Kind = "property-added"
+	KindPropertyRequired    Kind = "property-required"
+	KindPropertyOptional    Kind = "property-optional"
+	KindTypeChanged         Kind = "type-changed"
+	KindNullable            Kind = "nullable-changed"
+	KindEnumNarrowed        Kind = "enum-narrowed"
+	KindEnumWidened         Kind = "enum-widened"
+	KindConstraint          Kind = "constraint-changed"
+)
+
+// Change is one difference between the specs. Method and Path name the
+// operation as the old spec wrote it, or the new one for added operations.
+// Location says where in the operation the change is, such as
+// "query limit" or "response 200 body /items/*/price", and is empty for
+// operation-level changes. Requests lists the workspace requests that call
+// the operation, as file:line references.
+type Change struct {
+	Severity  Severity
+	Kind      Kind
+	Method    string
+	Path      string
+	Operation string
+	Location  string
+	Message   string
+	Requests  []string
+}
+
+func (c Change) Breaking() bool {
+	return c.Severity == Breaking
+}
+
+// Where joins the location and message for one-line output.
+func (c Change) Where() string {
+	if c.Location == "" {
+		return c.Message
+	}
+	return c.Location + ": " + c.Message
+}
+
+// Compare lists what changed from old to new, grouped by operation in the
+// old spec's order with added operations last.
+func Compare(old, new *model.Spec) []Change {
+	d := &differ{}
+	oldOps := opsOf(old)
+	newOps := opsOf(new)
+	newByKey := make(map[string]*model.Operation, len(newOps))
+	for _, op := range newOps {
+		newByKey[opKey(op)] = op
+	}
+	seen := make(map[string]bool, len(oldOps))
+	for _, o := range oldOps {
+		key := opKey(o)
+		seen[key] = true
+		n, ok := newByKey[key]
+		if !ok {
+			d.op = o
+			d.add(Breaking, KindOperationRemoved, "", "operation removed")
+			continue
+		}
+		d.operation(o, n)
+	}
+	for _, n := range newOps {
+		if !seen[opKey(n)] {
+			d.op = n
+			d.add(NonBreaking, KindOperationAdded, "", "operation added")
+		}
+	}
+	return d.out
+}
+
+type differ struct {
+	op  *model.Operation
+	out []Change
+}
+
+func (d *differ) add(sev Severity, kind Kind, loc, format string, args ...any) {
+	d.out = append(d.out, Change{
+		Severity:  sev,
+		Kind:      kind,
+		Method:    string(d.op.Method),
+		Path:      d.op.Path,
+		Operation: d.op.ID,
+		Location:  loc,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+func opsOf(spec *model.Spec) []*model.Operation {
+	if spec == nil {
+		return nil
+	}
+	out := make([]*model.Operation, len(spec.Operations))
+	for i := range spec.Operations {
+		out[i] = &spec.Operations[i]
+	}
+	return out
+}
+
+var templateSeg = regexp.MustCompile(`\{[^{}]*\}`)
+
+// opKey drops parameter names, so renaming {id} to {userId} keeps the
+// operation matched.
+func opKey(op *model.Operation) string {
+	path := strings.TrimRight(op.Path, "/")
+	return strings.ToUpper(string(op.Method)) + " " + templateSeg.ReplaceAllString(path, "{}")
+}
+
+func (d *differ) operation(o, n *model.Operation) {
+	d.op = o
+	if n.Deprecated && !o.Deprecated {
+		d.add(NonBreaking, KindOperationDeprecated, "", "operation deprecated")
+	}
+	d.params(o.Parameters, n.Parameters)
+	d.requestBody(o.RequestBody, n.RequestBody)
+	d.responses(o.Responses, n.Responses)
+}
+
+func paramKey(p model.Parameter) string {
+	name := p.Name
+	if p.Location == model.InHeader {
+		name = strings.ToLower(name)
+	}
+	return string(p.Location) + " " + name
+}
+
+// Path parameters are matched by position through opKey, so only their
+// schemas are compared.
+func (d *differ) params(old, new []model.Parameter) {
+	newByKey := make(map[string]model.Parameter, len(new))
+	for _, p := range new {
+		newByKey[paramKey(p)] = p
+	}
+	oldPath, newPath := pathParams(old), pathParams(new)
+	seen := make(map[string]bool, len(old))
+	for _, o := range old {
+		if o.Location == model.InPath {
+			continue
+		}
+		key := paramKey(o)
+		seen[key] = true
+		loc := string(o.Location) + " " + o.Name
+		n, ok := newByKey[key]
+		if !ok {
+			d.add(NonBreaking, KindParamRemoved, loc, "parameter removed")
+			continue
+		}
+		if n.Required && !o.Required {
+			d.add(Breaking, KindParamRequired, loc, "parameter became required")
+		}
+		d.schema(loc, o.Schema, n.Schema, true)
+	}
+	for _, n := range new {
+		if n.Location == model.InPath || seen[paramKey(n)] {
+			continue
+		}
+		loc := string(n.Location) + " " + n.Name
+		if n.Required {
+			d.add(Breaking, KindParamAdded, loc, "required parameter added")
+		} else {
+			d.add(NonBreaking, KindParamAdded, loc, "optional parameter added")
+		}
+	}
+	for i, o := range oldPath {
+		if i < len(newPath) {
+			d.schema("path "+o.Name, o.Schema, newPath[i].Schema, true)
+		}
+	}
+}
+
+func pathParams(ps []model.Parameter) []model.Parameter {
+	var out []model.Parameter
+	for _, p := range ps {
+		if p.Location == model.InPath {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (d *differ) requestBody(o, n *model.RequestBody) {
+	switch {
+	case o == nil && n == nil:
+		return
+	case o == nil:
+		if n.Required {
+			d.add(Breaking, KindBodyRequired, "request body", "required request body added")
+		}
+		return
+	case n == nil:
+		return
+	}
+	if n.Required && !o.Required {
+		d.add(Breaking, KindBodyRequired, "request body", "request body became required")
+	}
+	d.media("request body", o.MediaTypes, n.MediaTypes, true)
+}
+
+func (d *differ) responses(old, new []model.Response) {
+	newByCode := make(map[string]model.Response, len(new))
+	for _, r := range new {
+		newByCode[r.StatusCode] = r
+	}
+	seen := make(map[string]bool, len(old))
+	for _, o := range old {
+		seen[o.StatusCode] = true
+		loc := "response " + o.StatusCode
+		n, ok := newByCode[o.StatusCode]
+		if !ok {
+			// Clients handle success responses; losing an error one only
+			// means the server stopped documenting it.
+			if strings.HasPrefix(o.StatusCode, "2") {
+				d.add(Breaking, KindResponseRemoved, loc, "success response removed")
+			} else {
+				d.add(NonBreaking, KindResponseRemoved, loc, "response removed")
+			}
+			continue
+		}
+		d.headers(loc, o.Headers, n.Headers)
+		d.media(loc+" body", o.MediaTypes, n.MediaTypes, false)
+	}
+	for _, n := range new {
+		if !seen[n.StatusCode] {
+			d.add(NonBreaking, KindResponseAdded, "response "+n.StatusCode, "response added")
+		}
+	}
+}
+
+func (d *differ) headers(loc string, old, new []model.Header) {
+	newByName := make(map[string]model.Header, len(new))
+	for _, h := range new {
+		newByName[strings.ToLower(h.Name)] = h
+	}
+	for _, o := range old {
+		hloc := loc + " header " + o.Name
+		n, ok := newByName[strings.ToLower(o.Name)]
+		switch {
+		case !ok && o.Required:
+			d.add(Breaking, KindHeaderRemoved, hloc, "required header removed")
+		case !ok:
+			d.add(NonBreaking, KindHeaderRemoved, hloc, "header removed")
+		case o.Required && !n.Required:
+			d.add(Breaking, KindPropertyOptional, hloc, "header is no longer required")
+		default:
+			d.schema(hloc, o.Schema, n.Schema, false)
+		}
+	}
+}
+
+// media compares content types. A request type the server stops accepting
+// breaks clients that send it, and so does a response type clients expect.
+func (d *differ) media(loc string, old, new []model.MediaType, in bool) {
+	newByType := make(map[string]model.MediaType, len(new))
+	for _, m := range new {
+		newByType[strings.ToLower(m.ContentType)] = m
+	}
+	oldTypes := make(map[string]bool, len(old))
+	for _, o := range old {
+		ct := strings.ToLower(o.ContentType)
+		oldTypes[ct] = true
+		n, ok := newByType[ct]
+		if !ok {
+			d.add(Breaking, KindMediaTypeRemoved, loc, "media type %s removed", o.ContentType)
+			continue
+		}
+		mloc := loc
+		if len(old) > 1 {
+			mloc += " (" + o.ContentType + ")"
+		}
+		d.schema(mloc, o.Schema, n.Schema, in)
+	}
+	var added []string
+	for _, n := range new {
+		if !oldTypes[strings.ToLower(n.ContentType)] {
+			added = append(added, n.ContentType)
+		}
+	}
+	sort.Strings(added)
+	for _, ct := range added {
+		d.add(NonBreaking, KindMediaTypeAdded, loc, "media type %s added", ct)
+	}
+}
@@ -0,0 +1,279 @@
+package specdiff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi"
+	"github.com/unkn0wn-root/resterm/internal/openapi/infer"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/openapi/parser"
+)
+
+const oldSpec = `openapi: 3.1.0
+info: {title: Shop, version: 1.0.0}
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /items:
+    get:
+      operationId: listItems
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, maximum: 100}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [items]
+                properties:
+                  items:
+                    type: array
+                    items: {$ref: "#/components/schemas/Item"}
+    post:
+      operationId: createItem
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/Item"}
+      responses:
+        "201": {description: created}
+  /items/{id}:
+    get:
+      operationId: getItem
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Item"}
+    delete:
+      operationId: deleteItem
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "204": {description: gone}
+components:
+  schemas:
+    Item:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        price: {type: number}
+        status: {type: string, enum: [new, used, broken]}
+        parent: {$ref: "#/components/schemas/Item"}
+`
+
+const newSpec = `openapi: 3.1.0
+info: {title: Shop, version: 2.0.0}
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /items:
+    get:
+      operationId: listItems
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, maximum: 50}}
+        - {name: tenant, in: header, required: true, schema: {type: string}}
+        - {name: q, in: query, schema: {type: string}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [items]
+                properties:
+                  items:
+                    type: array
+                    items: {$ref: "#/components/schemas/Item"}
+                  next: {type: string}
+    post:
+      operationId: createItem
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/Item"}
+      responses:
+        "201": {description: created}
+  /items/{itemId}:
+    get:
+      operationId: getItem
+      parameters:
+        - {name: itemId, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Item"}
+  /health:
+    get:
+      operationId: health
+      responses:
+        "200": {description: ok}
+components:
+  schemas:
+    Item:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        status: {type: string, enum: [new, used]}
+        parent: {$ref: "#/components/schemas/Item"}
+`
+
+func loadSpec(t *testing.T, dir, name, src string) *model.Spec {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := parser.NewLoader().Parse(context.Background(), path, openapi.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return spec
+}
+
+func find(changes []Change, method, path, where string) *Change {
+	for i, c := range changes {
+		if c.Method == method && c.Path == path && c.Where() == where {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+func TestCompareClassifiesChanges(t *testing.T) {
+	dir := t.TempDir()
+	changes := Compare(loadSpec(t, dir, "old.yml", oldSpec), loadSpec(t, dir, "new.yml", newSpec))
+
+	cases := []struct {
+		method, path, where string
+		sev                 Severity
+	}{
+		{"DELETE", "/items/{id}", "operation removed", Breaking},
+		{"GET", "/health", "operation added", NonBreaking},
+		{"GET", "/items", "header tenant: required parameter added", Breaking},
+		{"GET", "/items", "query q: optional parameter added", NonBreaking},
+		{"GET", "/items", "query limit: maximum lowered to 50", Breaking},
+		{"GET", "/items", "response 200 body /items/*/price: property removed", Breaking},
+		{"GET", "/items", "response 200 body /items/*/status: enum narrowed, removed \"broken\"", NonBreaking},
+		{"GET", "/items", "response 200 body /next: property added", NonBreaking},
+		{"POST", "/items", "request body /status: enum narrowed, removed \"broken\"", Breaking},
+		{"POST", "/items", "request body /price: property removed", NonBreaking},
+		{"GET", "/items/{id}", "response 200 body /price: property removed", Breaking},
+		{"GET", "/items/{id}", "response 200 body /status: enum narrowed, removed \"broken\"", NonBreaking},
+	}
+	for _, tc := range cases {
+		c := find(changes, tc.method, tc.path, tc.where)
+		if c == nil {
+			t.Errorf("missing %s %s %q", tc.method, tc.path, tc.where)
+			continue
+		}
+		if c.Severity != tc.sev {
+			t.Errorf("%s %s %q = %s, want %s", tc.method, tc.path, tc.where, c.Severity, tc.sev)
+		}
+	}
+	if len(changes) != len(cases) {
+		for _, c := range changes {
+			t.Logf("%s %s %s [%s]", c.Method, c.Path, c.Where(), c.Severity)
+		}
+		t.Fatalf("got %d changes, want %d", len(changes), len(cases))
+	}
+}
+
+func TestAttachMatchesWorkspaceRequests(t *testing.T) {
+	dir := t.TempDir()
+	old := loadSpec(t, dir, "old.yml", oldSpec)
+	next := loadSpec(t, dir, "new.yml", newSpec)
+	http := `### list
+# @name listItems
+GET {{baseUrl}}/v1/items?limit=10
+
+### one
+GET https://api.example.com/v1/items/42
+
+### drop
+DELETE {{baseUrl}}/v1/items/{{id}}
+
+### other
+GET {{baseUrl}}/v1/orders
+`
+	if err := os.WriteFile(filepath.Join(dir, "shop.http"), []byte(http), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := infer.Load(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := Compare(old, next)
+	Attach(changes, Requests(docs, dir), old, next)
+
+	got := func(method, path, where string) string {
+		c := find(changes, method, path, where)
+		if c == nil {
+			t.Fatalf("missing %s %s %q", method, path, where)
+		}
+		return strings.Join(c.Requests, ",")
+	}
+	if refs := got("GET", "/items", "header tenant: required parameter added"); refs != "shop.http:2 listItems" {
+		t.Errorf("list refs = %q", refs)
+	}
+	if refs := got("DELETE", "/items/{id}", "operation removed"); refs != "shop.http:9" {
+		t.Errorf("delete refs = %q", refs)
+	}
+	if refs := got("GET", "/items/{id}", "response 200 body /price: property removed"); refs != "shop.http:6" {
+		t.Errorf("get refs = %q", refs)
+	}
+
+	rep := &Report{Old: "old.yml", New: "new.yml", Changes: changes}
+	var text bytes.Buffer
+	if err := WriteText(&text, rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"DELETE /items/{id} (deleteItem)\n  BREAKING operation removed\n  Affected requests:\n    shop.http:9\n",
+		"Summary: 12 changes, 6 breaking",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := WriteJSON(&js, rep); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Summary struct{ Total, Breaking int }
+		Changes []struct{ Requests []string }
+	}
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Summary.Total != 12 || decoded.Summary.Breaking != 6 {
+		t.Errorf("json summary = %+v", decoded.Summary)
+	}
+
+	var junit bytes.Buffer
+	if err := WriteJUnit(&junit, rep); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(junit.String(), `tests="12" failures="6"`) ||
+		!strings.Contains(junit.String(), `<failure message="operation removed" type="operation-removed">`) {
+		t.Errorf("junit:\n%s", junit.String())
+	}
+}
@@ -0,0 +1,359 @@
+package specsync
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/directive"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+// block is the lines of one request, from the line after its separator.
+type block struct {
+	lines []string
+}
+
+func (b *block) flagged() bool {
+	return slices.ContainsFunc(b.lines, isMarker)
+}
+
+func (b *block) flag(marker string) {
+	b.lines = append([]string{marker}, b.lines...)
+}
+
+func (b *block) unflag() {
+	b.lines = slices.DeleteFunc(b.lines, isMarker)
+}
+
+func isMarker(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, markerPrefix) && strings.HasSuffix(line, markerSuffix)
+}
+
+// Generated placeholders are named after where the parameter goes, as in
+// {{query_limit}}. Only these are removed when the spec drops a parameter;
+// anything else was written by hand.
+const (
+	pathPrefix   = "path_"
+	queryPrefix  = "query_"
+	headerPrefix = "header_"
+)
+
+// placeholder returns the variable name when s is exactly one {{name}}.
+func placeholder(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{{") || !strings.HasSuffix(s, "}}") {
+		return "", false
+	}
+	name := strings.TrimSpace(s[2 : len(s)-2])
+	if name == "" || strings.ContainsAny(name, "{} ") {
+		return "", false
+	}
+	return name, true
+}
+
+func generated(s, prefix string) (string, bool) {
+	name, ok := placeholder(s)
+	return name, ok && strings.HasPrefix(name, prefix)
+}
+
+// edits collects what update changes before any line is touched.
+type edits struct {
+	details []string
+	renames [][2]string
+	addVars []string
+	dropVar []string
+}
+
+func (e *edits) note(format string, args ...any) {
+	e.details = append(e.details, fmt.Sprintf(format, args...))
+}
+
+// update brings the request's placeholders in line with gen, the request the
+// importer would write for its operation today. It returns what changed.
+func (b *block) update(req *restfile.Request, gen *restfile.Request) []string {
+	at, rawURL := b.requestLine(req)
+	if at < 0 {
+		return nil
+	}
+	e := &edits{}
+	oldPath, oldQuery, hasQuery := strings.Cut(rawURL, "?")
+	genPath, genQuery, _ := strings.Cut(gen.URL, "?")
+
+	e.pathRenames(oldPath, genPath)
+	query := e.query(splitQuery(oldQuery, hasQuery), splitQuery(genQuery, genQuery != ""))
+	newURL := oldPath
+	if len(query) > 0 {
+		newURL += "?" + strings.Join(query, "&")
+	}
+	addHeaders, dropHeaders := b.headers(e, at, gen)
+
+	if len(e.details) == 0 {
+		return nil
+	}
+	if newURL != rawURL {
+		b.lines[at] = strings.Replace(b.lines[at], rawURL, newURL, 1)
+	}
+	b.editHeaders(at, addHeaders, dropHeaders)
+	for _, r := range e.renames {
+		b.rename(r[0], r[1])
+	}
+	b.editVars(req, gen, e)
+	return e.details
+}
+
+// requestLine finds the method line and the URL as written on it. A URL the
+// parser assembled from continuation lines is left alone.
+func (b *block) requestLine(req *restfile.Request) (int, string) {
+	at, url := b.methodLine(req.Method)
+	if at < 0 || url != req.URL {
+		return -1, ""
+	}
+	return at, url
+}
+
+func (b *block) methodLine(method string) (int, string) {
+	for i, line := range b.lines {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && strings.EqualFold(fields[0], strings.TrimSpace(method)) {
+			return i, fields[1]
+		}
+	}
+	return -1, ""
+}
+
+// pathRenames follows a renamed path parameter, so {{path_id}} becomes
+// {{path_itemid}} when the spec renames {id} to {itemId}. The value the
+// author gave the variable is kept.
+func (e *edits) pathRenames(oldPath, genPath string) {
+	olds := strings.Split(oldPath, "/")
+	gens := strings.Split(genPath, "/")
+	if len(olds) != len(gens) {
+		return
+	}
+	for i := range olds {
+		from, ok := generated(olds[i], pathPrefix)
+		if !ok {
+			continue
+		}
+		to, ok := generated(gens[i], pathPrefix)
+		if !ok || to == from {
+			continue
+		}
+		e.renames = append(e.renames, [2]string{from, to})
+		e.note("renamed {{%s}} to {{%s}}", from, to)
+	}
+}
+
+type queryPart struct {
+	raw string
+	key string
+	ref string
+}
+
+func splitQuery(q string, ok bool) []queryPart {
+	if !ok || q == "" {
+		return nil
+	}
+	var out []queryPart
+	for raw := range strings.SplitSeq(q, "&") {
+		if raw == "" {
+			continue
+		}
+		p := queryPart{raw: raw}
+		if name, ok := placeholder(raw); ok {
+			p.ref = name
+		} else {
+			key, val, _ := strings.Cut(raw, "=")
+			p.key = key
+			p.ref, _ = placeholder(val)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// query appends the parameters the spec added and drops generated ones it
+// removed. Parameters written by hand are never dropped.
+func (e *edits) query(old, gen []queryPart) []string {
+	genKeys := map[string]bool{}
+	genRefs := map[string]bool{}
+	for _, g := range gen {
+		genKeys[g.key] = g.key != ""
+		genRefs[g.ref] = g.ref != ""
+	}
+	var out []string
+	oldKeys := map[string]bool{}
+	oldRefs := map[string]bool{}
+	for _, p := range old {
+		if strings.HasPrefix(p.ref, queryPrefix) && !genRefs[p.ref] && (p.key == "" || !genKeys[p.key]) {
+			e.note("removed query %s", partName(p))
+			e.dropVar = append(e.dropVar, p.ref)
+			continue
+		}
+		oldKeys[p.key] = p.key != ""
+		oldRefs[p.ref] = p.ref != ""
+		out = append(out, p.raw)
+	}
+	for _, g := range gen {
+		if (g.key != "" && oldKeys[g.key]) || (g.ref != "" && oldRefs[g.ref]) {
+			continue
+		}
+		out = append(out, g.raw)
+		e.note("added query %s", partName(g))
+		if g.ref != "" {
+			e.addVars = append(e.addVars, g.ref)
+		}
+	}
+	return out
+}
+
+func partName(p queryPart) string {
+	if p.key != "" {
+		return p.key
+	}
+	return strings.TrimPrefix(p.ref, queryPrefix)
+}
+
+// headerEnd is the index after the header lines that follow the request line.
+func (b *block) headerEnd(at int) int {
+	i := at + 1
+	for i < len(b.lines) && strings.TrimSpace(b.lines[i]) != "" {
+		i++
+	}
+	return i
+}
+
+func headerLine(line string) (string, string, bool) {
+	name, val, ok := strings.Cut(line, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(val), true
+}
+
+// headers compares header parameters. Only headers whose value is a
+// generated {{header_...}} placeholder count, so Accept, Content-Type and
+// hand-written headers are left as they are.
+func (b *block) headers(e *edits, at int, gen *restfile.Request) ([]string, []int) {
+	genNames := map[string]bool{}
+	var want []string
+	for _, name := range sortedNames(gen.Headers) {
+		for _, v := range gen.Headers[name] {
+			if _, ok := generated(v, headerPrefix); !ok {
+				continue
+			}
+			genNames[strings.ToLower(name)] = true
+			want = append(want, name+": "+v)
+		}
+	}
+	have := map[string]bool{}
+	var drop []int
+	for i := at + 1; i < b.headerEnd(at); i++ {
+		name, val, ok := headerLine(b.lines[i])
+		if !ok {
+			continue
+		}
+		have[strings.ToLower(name)] = true
+		if ref, ok := generated(val, headerPrefix); ok && !genNames[strings.ToLower(name)] {
+			drop = append(drop, i)
+			e.dropVar = append(e.dropVar, ref)
+			e.note("removed header %s", name)
+		}
+	}
+	var add []string
+	for _, line := range want {
+		name, val, _ := headerLine(line)
+		if have[strings.ToLower(name)] {
+			continue
+		}
+		add = append(add, line)
+		ref, _ := placeholder(val)
+		e.addVars = append(e.addVars, ref)
+		e.note("added header %s", name)
+	}
+	return add, drop
+}
+
+func (b *block) editHeaders(at int, add []string, drop []int) {
+	end := b.headerEnd(at)
+	lines := make([]string, 0, len(b.lines)+len(add))
+	for i, line := range b.lines {
+		if i == end {
+			lines = append(lines, add...)
+		}
+		if !slices.Contains(drop, i) {
+			lines = append(lines, line)
+		}
+	}
+	if end == len(b.lines) {
+		lines = append(lines, add...)
+	}
+	b.lines = lines
+}
+
+// rename swaps a placeholder everywhere in the block, including its @var line.
+func (b *block) rename(from, to string) {
+	decl := varLine(from)
+	for i, line := range b.lines {
+		if m := decl.FindStringSubmatchIndex(line); m != nil {
+			line = line[:m[2]] + to + line[m[3]:]
+		}
+		b.lines[i] = strings.ReplaceAll(line, "{{"+from+"}}", "{{"+to+"}}")
+	}
+}
+
+func varLine(name string) *regexp.Regexp {
+	return regexp.MustCompile(`^\s*(?:#|//)\s*@var\s+(?:request(?:-secret)?\s+)?(` + regexp.QuoteMeta(name) + `)(?:\s|$)`)
+}
+
+// editVars declares the variables new placeholders use, with the importer's
+// sample values, and removes declarations nothing references any more.
+func (b *block) editVars(req *restfile.Request, gen *restfile.Request, e *edits) {
+	for _, name := range e.dropVar {
+		if strings.Contains(strings.Join(b.lines, "\n"), "{{"+name+"}}") {
+			continue
+		}
+		decl := varLine(name)
+		b.lines = slices.DeleteFunc(b.lines, decl.MatchString)
+	}
+	var decls []string
+	for _, name := range e.addVars {
+		if declared(req, name) || slices.ContainsFunc(b.lines, varLine(name).MatchString) {
+			continue
+		}
+		arg := directive.ScopeRequest.String() + " " + name
+		for _, v := range gen.Variables {
+			if v.Name == name && strings.TrimSpace(v.Value) != "" {
+				arg += " " + strings.TrimSpace(v.Value)
+			}
+		}
+		decls = append(decls, directive.Var.Comment()+" "+arg)
+	}
+	if len(decls) == 0 {
+		return
+	}
+	at, _ := b.methodLine(req.Method)
+	b.lines = slices.Insert(b.lines, max(at, 0), decls...)
+}
+
+func declared(req *restfile.Request, name string) bool {
+	for _, v := range req.Variables {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedNames[M ~map[string]V, V any](m M) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,80 @@
+package specsync
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+// file holds the lines of the .http file being edited. Line endings are
+// stripped on read and written back in the file's own style.
+type file struct {
+	lines []string
+	eol   string
+	final bool
+}
+
+func newFile(src []byte) *file {
+	f := &file{eol: "\n"}
+	if bytes.Contains(src, []byte("\r\n")) {
+		f.eol = "\r\n"
+	}
+	text := strings.ReplaceAll(string(src), "\r\n", "\n")
+	f.final = strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text != "" {
+		f.lines = strings.Split(text, "\n")
+	}
+	return f
+}
+
+func (f *file) block(r restfile.LineRange) *block {
+	start, end := f.span(r)
+	return &block{lines: append([]string(nil), f.lines[start:end]...)}
+}
+
+func (f *file) replace(r restfile.LineRange, lines []string) {
+	start, end := f.span(r)
+	out := make([]string, 0, len(f.lines)-(end-start)+len(lines))
+	out = append(out, f.lines[:start]...)
+	out = append(out, lines...)
+	out = append(out, f.lines[end:]...)
+	f.lines = out
+}
+
+// withMarkers widens r to take in warning comments just above it. The parser
+// leaves comments before a request's first directive out of its range.
+func (f *file) withMarkers(r restfile.LineRange) restfile.LineRange {
+	for r.Start > 1 && r.Start-2 < len(f.lines) && isMarker(f.lines[r.Start-2]) {
+		r.Start--
+	}
+	return r
+}
+
+// span turns a 1-based inclusive range into slice bounds clamped to the file.
+func (f *file) span(r restfile.LineRange) (int, int) {
+	start := min(max(r.Start-1, 0), len(f.lines))
+	end := min(max(r.End, start), len(f.lines))
+	return start, end
+}
+
+// appendText adds rendered blocks after one blank line.
+func (f *file) appendText(text string) {
+	for len(f.lines) > 0 && strings.TrimSpace(f.lines[len(f.lines)-1]) == "" {
+		f.lines = f.lines[:len(f.lines)-1]
+	}
+	if len(f.lines) > 0 {
+		f.lines = append(f.lines, "")
+	}
+	f.lines = append(f.lines, strings.Split(strings.TrimSuffix(text, "\n"), "\n")...)
+	f.final = true
+}
+
+func (f *file) bytes() []byte {
+	out := strings.Join(f.lines, f.eol)
+	if f.final && len(f.lines) > 0 {
+		out += f.eol
+	}
+	return []byte(out)
+}
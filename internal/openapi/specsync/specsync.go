@@ -0,0 +1,261 @@
+// Package specsync brings an existing .http file up to date with an OpenAPI
+// spec. Requests are edited line by line rather than re-rendered, so
+// directives, scripts and comments an author added survive every sync.
+// Operations the file lacks are rendered with restwriter and appended.
+package specsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi"
+	"github.com/unkn0wn-root/resterm/internal/openapi/generator"
+	"github.com/unkn0wn-root/resterm/internal/openapi/infer"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/openapi/specdiff"
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+type ActionKind string
+
+const (
+	ActionAdded     ActionKind = "added"
+	ActionUpdated   ActionKind = "updated"
+	ActionFlagged   ActionKind = "flagged"
+	ActionUnflagged ActionKind = "unflagged"
+)
+
+// Action is one thing a sync did to the file. Request is the request name,
+// or its method and URL, and Ref its "file:line" before the sync.
+type Action struct {
+	Kind    ActionKind
+	Request string
+	Ref     string
+	Details []string
+}
+
+type Options struct {
+	Generate openapi.GeneratorOptions
+	// SpecName is how warning comments refer to the spec, such as openapi.yml.
+	SpecName string
+}
+
+// Result is the synced file. Changed is false when the content is the same
+// as the input, in which case Actions only lists requests already flagged.
+type Result struct {
+	Content []byte
+	Changed bool
+	Actions []Action
+}
+
+// Sync updates src, the content of the .http file at path, to match spec.
+// Existing requests are matched to operations by operationId and then by
+// method and path. Matched requests get placeholders for parameters the spec
+// added and lose generated ones it removed; unmatched requests are flagged
+// with a warning comment; operations no request calls are appended.
+func Sync(spec *model.Spec, path string, src []byte, opt Options) (*Result, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("openapi sync: spec is nil")
+	}
+	doc := parser.Parse(path, src)
+	f := newFile(src)
+	s := &syncer{spec: spec, opt: opt, gen: generator.NewBuilder(), bases: specdiff.BasePaths(spec)}
+	res := &Result{}
+
+	matched := make([]bool, len(spec.Operations))
+	type pending struct {
+		req *restfile.Request
+		op  int
+	}
+	var work []pending
+	for _, req := range doc.Requests {
+		if req == nil || req.LineRange.Start <= 0 {
+			continue
+		}
+		method, reqPath, ok := infer.Route(req)
+		if !ok {
+			continue
+		}
+		op := s.match(req, string(method), reqPath)
+		if op >= 0 {
+			matched[op] = true
+		}
+		work = append(work, pending{req: req, op: op})
+	}
+
+	// Later blocks are edited first so earlier line numbers stay valid.
+	for i := len(work) - 1; i >= 0; i-- {
+		w := work[i]
+		act, err := s.request(f, path, w.req, w.op)
+		if err != nil {
+			return nil, err
+		}
+		if act != nil {
+			res.Actions = append([]Action{*act}, res.Actions...)
+		}
+	}
+
+	var added []*restfile.Request
+	for i, op := range spec.Operations {
+		if matched[i] || op.Method == "" || op.Path == "" {
+			continue
+		}
+		if op.Deprecated && !opt.Generate.IncludeDeprecated {
+			continue
+		}
+		req, err := s.gen.Request(spec, op, opt.Generate)
+		if err != nil {
+			return nil, fmt.Errorf("openapi sync: %w", err)
+		}
+		added = append(added, req)
+		res.Actions = append(res.Actions, Action{
+			Kind:    ActionAdded,
+			Request: label(req),
+			Details: []string{strings.ToUpper(string(op.Method)) + " " + op.Path},
+		})
+	}
+	if len(added) > 0 {
+		text, err := restwriter.Render(&restfile.Document{Requests: added}, restwriter.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("openapi sync: %w", err)
+		}
+		f.appendText(text)
+	}
+
+	res.Content = f.bytes()
+	res.Changed = !bytes.Equal(res.Content, src)
+	if err := verify(path, doc, res.Content, len(added)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Generate renders spec as a new file, for a sync target that does not exist
+// yet.
+func Generate(ctx context.Context, spec *model.Spec, opt Options) (*Result, error) {
+	doc, err := generator.NewBuilder().Generate(ctx, spec, opt.Generate)
+	if err != nil {
+		return nil, fmt.Errorf("openapi sync: %w", err)
+	}
+	text, err := restwriter.Render(doc, restwriter.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("openapi sync: %w", err)
+	}
+	res := &Result{Content: []byte(text), Changed: true}
+	for _, req := range doc.Requests {
+		res.Actions = append(res.Actions, Action{Kind: ActionAdded, Request: label(req)})
+	}
+	return res, nil
+}
+
+type syncer struct {
+	spec  *model.Spec
+	opt   Options
+	gen   *generator.Builder
+	bases []string
+}
+
+// match prefers an operationId equal to the request name, then the most
+// specific route, so /items/featured does not fall to /items/{id}.
+func (s *syncer) match(req *restfile.Request, method, reqPath string) int {
+	if name := strings.TrimSpace(req.Metadata.Name); name != "" {
+		for i, op := range s.spec.Operations {
+			if op.ID == name {
+				return i
+			}
+		}
+	}
+	best, score := -1, -1
+	for i, op := range s.spec.Operations {
+		if !strings.EqualFold(string(op.Method), method) {
+			continue
+		}
+		bases := s.bases
+		if len(op.Servers) > 0 {
+			bases = specdiff.BasePaths(&model.Spec{Servers: op.Servers})
+		}
+		if n, ok := specdiff.MatchRoute(reqPath, op.Path, bases); ok && n > score {
+			best, score = i, n
+		}
+	}
+	return best
+}
+
+func (s *syncer) request(f *file, path string, req *restfile.Request, op int) (*Action, error) {
+	act := &Action{Request: label(req), Ref: fmt.Sprintf("%s:%d", path, req.LineRange.Start)}
+	span := f.withMarkers(req.LineRange)
+	b := f.block(span)
+	if op < 0 {
+		act.Kind = ActionFlagged
+		if !b.flagged() {
+			b.flag(s.marker())
+			f.replace(span, b.lines)
+		}
+		return act, nil
+	}
+
+	gen, err := s.gen.Request(s.spec, s.spec.Operations[op], s.opt.Generate)
+	if err != nil {
+		return nil, fmt.Errorf("openapi sync: %w", err)
+	}
+	act.Kind = ActionUpdated
+	if b.flagged() {
+		b.unflag()
+		act.Kind = ActionUnflagged
+		act.Details = append(act.Details, "operation is back in the spec")
+	}
+	act.Details = append(act.Details, b.update(req, gen)...)
+	if len(act.Details) == 0 {
+		return nil, nil
+	}
+	f.replace(span, b.lines)
+	return act, nil
+}
+
+func (s *syncer) marker() string {
+	name := s.opt.SpecName
+	if name == "" {
+		name = "the spec"
+	}
+	return markerPrefix + name + markerSuffix
+}
+
+const (
+	markerPrefix = "# WARNING: "
+	markerSuffix = " has no operation for this request; it may have been removed."
+)
+
+func label(req *restfile.Request) string {
+	if name := strings.TrimSpace(req.Metadata.Name); name != "" {
+		return name
+	}
+	return strings.ToUpper(req.Method) + " " + req.URL
+}
+
+// verify re-parses the result and checks that every request kept its
+// assertions, captures and scripts, so a bad edit fails loudly instead of
+// losing an author's checks.
+func verify(path string, before *restfile.Document, out []byte, added int) error {
+	after := parser.Parse(path, out)
+	if len(after.Requests) != len(before.Requests)+added {
+		return fmt.Errorf(
+			"openapi sync: result has %d requests, want %d; file left unchanged",
+			len(after.Requests),
+			len(before.Requests)+added,
+		)
+	}
+	for i, old := range before.Requests {
+		got := after.Requests[i]
+		if old.Metadata.Name != got.Metadata.Name ||
+			len(old.Metadata.Asserts) != len(got.Metadata.Asserts) ||
+			len(old.Metadata.Captures) != len(got.Metadata.Captures) ||
+			len(old.Metadata.Scripts) != len(got.Metadata.Scripts) {
+			return fmt.Errorf("openapi sync: editing %s would change its directives; file left unchanged", label(old))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package specsync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/openapi/parser"
+	rparser "github.com/unkn0wn-root/resterm/internal/parser"
+)
+
+const specV1 = `openapi: 3.1.0
+info: {title: Shop, version: 1.0.0}
+servers:
+  - url: https://api.example.com
+paths:
+  /items:
+    get:
+      operationId: listItems
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, example: 10}}
+        - {name: legacy, in: query, schema: {type: string, example: x}}
+      responses:
+        "200": {description: ok}
+  /items/{id}:
+    get:
+      operationId: getItem
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string, example: i1}}
+      responses:
+        "200": {description: ok}
+    delete:
+      operationId: deleteItem
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string, example: i1}}
+      responses:
+        "204": {description: gone}
+`
+
+const specV2 = `openapi: 3.1.0
+info: {title: Shop, version: 2.0.0}
+servers:
+  - url: https://api.example.com
+paths:
+  /items:
+    get:
+      operationId: listItems
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, example: 10}}
+        - {name: page, in: query, schema: {type: integer, example: 2}}
+        - {name: X-Tenant, in: header, required: true, schema: {type: string, example: acme}}
+      responses:
+        "200": {description: ok}
+  /items/{itemId}:
+    get:
+      operationId: getItem
+      parameters:
+        - {name: itemId, in: path, required: true, schema: {type: string, example: i1}}
+      responses:
+        "200": {description: ok}
+  /health:
+    get:
+      operationId: health
+      responses:
+        "200": {description: ok}
+`
+
+func loadSpec(t *testing.T, src string) *model.Spec {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "openapi.yml")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := parser.NewLoader().Parse(context.Background(), path, openapi.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse spec: %v", err)
+	}
+	return spec
+}
+
+func TestSyncKeepsHandEditsAndUpdatesPlaceholders(t *testing.T) {
+	opt := Options{SpecName: "openapi.yml"}
+	gen, err := Generate(context.Background(), loadSpec(t, specV1), opt)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	// Hand edits an author makes after the first import.
+	src := strings.Replace(string(gen.Content),
+		"GET {{baseUrl}}/items?",
+		"# keep this note\n# @assert status == 200\n# @capture file-secret total response.json.total\n# @script test\n> client.test(\"ok\", function() {})\nGET {{baseUrl}}/items?",
+		1)
+	src = strings.Replace(src, "# @var request path_id i1\nGET", "# @var request path_id 42\n# @snapshot\nGET", 1)
+	if !strings.Contains(src, "# @snapshot") {
+		t.Fatalf("fixture did not apply:\n%s", src)
+	}
+
+	res, err := Sync(loadSpec(t, specV2), "shop.http", []byte(src), opt)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	out := string(res.Content)
+	for _, want := range []string{
+		"# keep this note\n# @assert status == 200\n",
+		"# @capture file-secret total response.json.total\n",
+		"> client.test(\"ok\", function() {})\n",
+		"GET {{baseUrl}}/items?limit={{query_limit}}&page={{query_page}}\n",
+		"# @var request query_page 2\n",
+		"X-Tenant: {{header_x_tenant}}\n",
+		"# @var request header_x_tenant acme\n",
+		"# @var request path_itemid 42\n# @snapshot\nGET {{baseUrl}}/items/{{path_itemid}}\n",
+		"# WARNING: openapi.yml has no operation for this request; it may have been removed.\n",
+		"### health\n# @name health\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "legacy") {
+		t.Errorf("removed query param is still used:\n%s", out)
+	}
+	// Only the flagged DELETE still uses the old path parameter.
+	if n := strings.Count(out, "path_id"); n != 2 {
+		t.Errorf("path_id appears %d times, want 2:\n%s", n, out)
+	}
+
+	kinds := map[string]ActionKind{}
+	for _, a := range res.Actions {
+		kinds[a.Request] = a.Kind
+	}
+	want := map[string]ActionKind{
+		"listItems":  ActionUpdated,
+		"getItem":    ActionUpdated,
+		"deleteItem": ActionFlagged,
+		"health":     ActionAdded,
+	}
+	for name, kind := range want {
+		if kinds[name] != kind {
+			t.Errorf("%s = %q, want %q (actions %+v)", name, kinds[name], kind, res.Actions)
+		}
+	}
+
+	doc := rparser.Parse("shop.http", res.Content)
+	if len(doc.Errors) > 0 {
+		t.Fatalf("synced file has errors: %+v", doc.Errors)
+	}
+	list := doc.Requests[0]
+	if list.Headers.Get("X-Tenant") != "{{header_x_tenant}}" || len(list.Metadata.Asserts) != 1 {
+		t.Fatalf("list request = %+v", list)
+	}
+
+	again, err := Sync(loadSpec(t, specV2), "shop.http", res.Content, opt)
+	if err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	if again.Changed {
+		t.Fatalf("second sync changed the file:\n%s", again.Content)
+	}
+	if len(again.Actions) != 1 || again.Actions[0].Kind != ActionFlagged {
+		t.Fatalf("second sync actions = %+v", again.Actions)
+	}
+
+	back, err := Sync(loadSpec(t, specV1), "shop.http", res.Content, opt)
+	if err != nil {
+		t.Fatalf("sync back: %v", err)
+	}
+	if strings.Contains(string(back.Content), "removed.\n# @name deleteItem") {
+		t.Fatalf("restored operation still flagged:\n%s", back.Content)
+	}
+	for _, a := range back.Actions {
+		if a.Request == "deleteItem" && a.Kind != ActionUnflagged {
+			t.Fatalf("deleteItem action = %+v", a)
+		}
+	}
+}
+
+func TestSyncKeepsCRLF(t *testing.T) {
+	src := "### listItems\r\n# @name listItems\r\nGET {{baseUrl}}/items?limit={{query_limit}}\r\n"
+	res, err := Sync(loadSpec(t, specV2), "crlf.http", []byte(src), Options{})
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if strings.Contains(strings.ReplaceAll(string(res.Content), "\r\n", ""), "\n") {
+		t.Fatalf("mixed line endings: %q", res.Content)
+	}
+}
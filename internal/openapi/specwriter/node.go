@@ -0,0 +1,205 @@
+package specwriter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	yaml "go.yaml.in/yaml/v4"
+)
+
+const (
+	tagStr   = "!!str"
+	tagInt   = "!!int"
+	tagFloat = "!!float"
+	tagBool  = "!!bool"
+	tagNull  = "!!null"
+)
+
+// The document is built as a YAML node tree so keys keep the order the spec
+// reads best in. JSON output walks the same tree.
+
+func mapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode}
+}
+
+func sequence() *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode}
+}
+
+func scalar(tag, v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v}
+}
+
+func str(v string) *yaml.Node {
+	return scalar(tagStr, v)
+}
+
+func boolean(v bool) *yaml.Node {
+	return scalar(tagBool, strconv.FormatBool(v))
+}
+
+func float(v float64) *yaml.Node {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return scalar(tagInt, strconv.FormatInt(int64(v), 10))
+	}
+	return scalar(tagFloat, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func set(m *yaml.Node, key string, v *yaml.Node) {
+	if v == nil {
+		return
+	}
+	m.Content = append(m.Content, str(key), v)
+}
+
+func setStr(m *yaml.Node, key, v string) {
+	if v != "" {
+		set(m, key, str(v))
+	}
+}
+
+func setTrue(m *yaml.Node, key string, v bool) {
+	if v {
+		set(m, key, boolean(true))
+	}
+}
+
+func empty(n *yaml.Node) bool {
+	return n == nil || len(n.Content) == 0
+}
+
+// value converts a decoded example into nodes. Map keys are sorted so the
+// output does not change between runs.
+func value(v any) (*yaml.Node, error) {
+	switch x := v.(type) {
+	case nil:
+		return scalar(tagNull, "null"), nil
+	case string:
+		return str(x), nil
+	case bool:
+		return boolean(x), nil
+	case json.Number:
+		if _, err := x.Int64(); err == nil {
+			return scalar(tagInt, x.String()), nil
+		}
+		return scalar(tagFloat, x.String()), nil
+	case float64:
+		return float(x), nil
+	case float32:
+		return float(float64(x)), nil
+	case int:
+		return scalar(tagInt, strconv.Itoa(x)), nil
+	case int64:
+		return scalar(tagInt, strconv.FormatInt(x, 10)), nil
+	case []any:
+		seq := sequence()
+		for _, item := range x {
+			n, err := value(item)
+			if err != nil {
+				return nil, err
+			}
+			seq.Content = append(seq.Content, n)
+		}
+		return seq, nil
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		m := mapping()
+		for _, k := range keys {
+			n, err := value(x[k])
+			if err != nil {
+				return nil, err
+			}
+			set(m, k, n)
+		}
+		return m, nil
+	default:
+		// Anything else goes through JSON, which every example value the
+		// parser and the inferrer produce survives.
+		data, err := json.Marshal(x)
+		if err != nil {
+			return nil, fmt.Errorf("specwriter: example value %T: %w", v, err)
+		}
+		var back any
+		if err := json.Unmarshal(data, &back); err != nil {
+			return nil, fmt.Errorf("specwriter: example value %T: %w", v, err)
+		}
+		return value(back)
+	}
+}
+
+// encodeJSON writes the node tree as indented JSON, keeping key order.
+func encodeJSON(n *yaml.Node) ([]byte, error) {
+	v, err := jsonValue(n)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+type orderedObject []*yaml.Node
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for i := 0; i+1 < len(o); i += 2 {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		k, err := json.Marshal(o[i].Value)
+		if err != nil {
+			return nil, err
+		}
+		v, err := jsonValue(o[i+1])
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, k...)
+		buf = append(buf, ':')
+		buf = append(buf, data...)
+	}
+	return append(buf, '}'), nil
+}
+
+func jsonValue(n *yaml.Node) (any, error) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return orderedObject(n.Content), nil
+	case yaml.SequenceNode:
+		out := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := jsonValue(c)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case yaml.ScalarNode:
+		switch n.Tag {
+		case tagInt, tagFloat:
+			return json.Number(n.Value), nil
+		case tagBool:
+			return n.Value == "true", nil
+		case tagNull:
+			return nil, nil
+		default:
+			return n.Value, nil
+		}
+	default:
+		return nil, fmt.Errorf("specwriter: unexpected node kind %v", n.Kind)
+	}
+}
@@ -0,0 +1,558 @@
+// Package specwriter renders a model.Spec as an OpenAPI 3.1 document, the
+// reverse of the parser package.
+package specwriter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v4"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+)
+
+const Version = "3.1.0"
+
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// FormatForPath picks JSON for a .json destination and YAML otherwise.
+func FormatForPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// Render returns spec as an OpenAPI 3.1 document. Schemas are written inline;
+// a schema that refers back to itself is cut off with an empty schema.
+func Render(spec *model.Spec, f Format) ([]byte, error) {
+	if spec == nil {
+		return nil, errors.New("specwriter: spec is nil")
+	}
+	w := &writer{}
+	root, err := w.document(spec)
+	if err != nil {
+		return nil, err
+	}
+	if f == FormatJSON {
+		return encodeJSON(root)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("specwriter: encode: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("specwriter: encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile renders spec in the format dst's extension asks for. An existing
+// file is only replaced when overwrite is set.
+func WriteFile(spec *model.Spec, dst string, overwrite bool) error {
+	if strings.TrimSpace(dst) == "" {
+		return errors.New("specwriter: destination path is empty")
+	}
+	data, err := Render(spec, FormatForPath(dst))
+	if err != nil {
+		return err
+	}
+	if !overwrite {
+		if _, err := os.Stat(dst); err == nil {
+			return fmt.Errorf("specwriter: destination %s already exists", dst)
+		}
+	}
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("specwriter: create directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".openapi-*")
+	if err != nil {
+		return fmt.Errorf("specwriter: create temp file: %w", err)
+	}
+	name := tmp.Name()
+	defer func() { _ = os.Remove(name) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("specwriter: write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("specwriter: close temp file: %w", err)
+	}
+	if err := os.Chmod(name, 0o644); err != nil {
+		return fmt.Errorf("specwriter: chmod temp file: %w", err)
+	}
+	if err := os.Rename(name, dst); err != nil {
+		return fmt.Errorf("specwriter: rename temp file: %w", err)
+	}
+	return nil
+}
+
+type writer struct {
+	// stack holds the schemas being written, to stop on cycles.
+	stack []*model.Schema
+}
+
+func (w *writer) document(spec *model.Spec) (*yaml.Node, error) {
+	root := mapping()
+	set(root, "openapi", str(Version))
+
+	info := mapping()
+	title := spec.Title
+	if title == "" {
+		title = "API"
+	}
+	version := spec.Version
+	if version == "" {
+		version = "0.0.0"
+	}
+	setStr(info, "title", title)
+	setStr(info, "version", version)
+	setStr(info, "description", spec.Description)
+	set(root, "info", info)
+
+	if s := servers(spec.Servers); s != nil {
+		set(root, "servers", s)
+	}
+
+	paths := mapping()
+	items := map[string]*yaml.Node{}
+	for _, op := range spec.Operations {
+		item, ok := items[op.Path]
+		if !ok {
+			item = mapping()
+			items[op.Path] = item
+			set(paths, op.Path, item)
+		}
+		n, err := w.operation(op)
+		if err != nil {
+			return nil, fmt.Errorf("specwriter: %s %s: %w", op.Method, op.Path, err)
+		}
+		set(item, strings.ToLower(string(op.Method)), n)
+	}
+	set(root, "paths", paths)
+
+	if len(spec.SecuritySchemes) > 0 {
+		comps := mapping()
+		set(comps, "securitySchemes", securitySchemes(spec.SecuritySchemes))
+		set(root, "components", comps)
+	}
+	return root, nil
+}
+
+func servers(src []model.Server) *yaml.Node {
+	if len(src) == 0 {
+		return nil
+	}
+	seq := sequence()
+	for _, s := range src {
+		m := mapping()
+		setStr(m, "url", s.URL)
+		setStr(m, "description", s.Description)
+		seq.Content = append(seq.Content, m)
+	}
+	return seq
+}
+
+func (w *writer) operation(op model.Operation) (*yaml.Node, error) {
+	m := mapping()
+	if len(op.Tags) > 0 {
+		tags := sequence()
+		for _, t := range op.Tags {
+			tags.Content = append(tags.Content, str(t))
+		}
+		set(m, "tags", tags)
+	}
+	setStr(m, "summary", op.Summary)
+	setStr(m, "description", op.Description)
+	setStr(m, "operationId", op.ID)
+	setTrue(m, "deprecated", op.Deprecated)
+	if s := servers(op.Servers); s != nil {
+		set(m, "servers", s)
+	}
+
+	if len(op.Parameters) > 0 {
+		params := sequence()
+		for _, p := range op.Parameters {
+			n, err := w.parameter(p)
+			if err != nil {
+				return nil, err
+			}
+			params.Content = append(params.Content, n)
+		}
+		set(m, "parameters", params)
+	}
+
+	if rb := op.RequestBody; rb != nil {
+		body := mapping()
+		setStr(body, "description", rb.Description)
+		content, err := w.content(rb.MediaTypes)
+		if err != nil {
+			return nil, err
+		}
+		set(body, "content", content)
+		setTrue(body, "required", rb.Required)
+		set(m, "requestBody", body)
+	}
+
+	responses := mapping()
+	for _, r := range op.Responses {
+		n, err := w.response(r)
+		if err != nil {
+			return nil, err
+		}
+		set(responses, r.StatusCode, n)
+	}
+	if empty(responses) {
+		set(responses, "default", descOnly("Response not recorded"))
+	}
+	set(m, "responses", responses)
+
+	if len(op.Security) > 0 {
+		seq := sequence()
+		req := mapping()
+		for _, s := range op.Security {
+			scopes := sequence()
+			for _, sc := range s.Scopes {
+				scopes.Content = append(scopes.Content, str(sc))
+			}
+			set(req, s.SchemeName, scopes)
+		}
+		seq.Content = append(seq.Content, req)
+		set(m, "security", seq)
+	}
+	return m, nil
+}
+
+func descOnly(desc string) *yaml.Node {
+	m := mapping()
+	setStr(m, "description", desc)
+	return m
+}
+
+func (w *writer) parameter(p model.Parameter) (*yaml.Node, error) {
+	m := mapping()
+	setStr(m, "name", p.Name)
+	setStr(m, "in", string(p.Location))
+	setStr(m, "description", p.Description)
+	// Path parameters are always required.
+	setTrue(m, "required", p.Required || p.Location == model.InPath)
+	setStr(m, "style", p.Style)
+	if p.Explode != nil {
+		set(m, "explode", boolean(*p.Explode))
+	}
+	if err := w.setSchema(m, p.Schema); err != nil {
+		return nil, err
+	}
+	if p.Example.HasValue {
+		v, err := value(p.Example.Value)
+		if err != nil {
+			return nil, err
+		}
+		set(m, "example", v)
+	}
+	return m, nil
+}
+
+func (w *writer) response(r model.Response) (*yaml.Node, error) {
+	m := mapping()
+	desc := r.Description
+	if desc == "" {
+		desc = statusDescription(r.StatusCode)
+	}
+	setStr(m, "description", desc)
+	if len(r.Headers) > 0 {
+		hs := mapping()
+		for _, h := range r.Headers {
+			n := mapping()
+			setStr(n, "description", h.Description)
+			setTrue(n, "required", h.Required)
+			if err := w.setSchema(n, h.Schema); err != nil {
+				return nil, err
+			}
+			if h.Example.HasValue {
+				v, err := value(h.Example.Value)
+				if err != nil {
+					return nil, err
+				}
+				set(n, "example", v)
+			}
+			set(hs, h.Name, n)
+		}
+		set(m, "headers", hs)
+	}
+	if len(r.MediaTypes) > 0 {
+		content, err := w.content(r.MediaTypes)
+		if err != nil {
+			return nil, err
+		}
+		set(m, "content", content)
+	}
+	return m, nil
+}
+
+func statusDescription(code string) string {
+	if n, err := strconv.Atoi(code); err == nil {
+		if t := http.StatusText(n); t != "" {
+			return t
+		}
+	}
+	return "Response"
+}
+
+func (w *writer) content(mts []model.MediaType) (*yaml.Node, error) {
+	m := mapping()
+	for _, mt := range mts {
+		n := mapping()
+		if err := w.setSchema(n, mt.Schema); err != nil {
+			return nil, err
+		}
+		var named []model.Example
+		for _, ex := range mt.Examples {
+			if ex.HasValue {
+				named = append(named, ex)
+			}
+		}
+		switch {
+		case len(named) == 1 && named[0].Name == "":
+			v, err := value(named[0].Value)
+			if err != nil {
+				return nil, err
+			}
+			set(n, "example", v)
+		case len(named) > 0:
+			exs := mapping()
+			for i, ex := range named {
+				name := ex.Name
+				if name == "" {
+					name = "example" + strconv.Itoa(i+1)
+				}
+				e := mapping()
+				setStr(e, "summary", ex.Summary)
+				v, err := value(ex.Value)
+				if err != nil {
+					return nil, err
+				}
+				set(e, "value", v)
+				set(exs, name, e)
+			}
+			set(n, "examples", exs)
+		}
+		set(m, mt.ContentType, n)
+	}
+	return m, nil
+}
+
+func (w *writer) setSchema(m *yaml.Node, ref *model.SchemaRef) error {
+	if ref == nil || ref.Node == nil {
+		return nil
+	}
+	n, err := w.schema(ref.Node)
+	if err != nil {
+		return err
+	}
+	set(m, "schema", n)
+	return nil
+}
+
+func (w *writer) schemaRef(ref *model.SchemaRef) (*yaml.Node, error) {
+	if ref == nil || ref.Node == nil {
+		return mapping(), nil
+	}
+	return w.schema(ref.Node)
+}
+
+func (w *writer) schema(s *model.Schema) (*yaml.Node, error) {
+	for _, open := range w.stack {
+		if open == s {
+			return mapping(), nil
+		}
+	}
+	w.stack = append(w.stack, s)
+	defer func() { w.stack = w.stack[:len(w.stack)-1] }()
+
+	m := mapping()
+	setStr(m, "title", s.Title)
+	setStr(m, "description", s.Description)
+
+	types := s.Types
+	if s.Nullable != nil && *s.Nullable && len(types) > 0 && !hasType(types, model.TypeNull) {
+		types = append(append([]model.SchemaType(nil), types...), model.TypeNull)
+	}
+	switch len(types) {
+	case 0:
+	case 1:
+		set(m, "type", str(string(types[0])))
+	default:
+		seq := sequence()
+		for _, t := range types {
+			seq.Content = append(seq.Content, str(string(t)))
+		}
+		seq.Style = yaml.FlowStyle
+		set(m, "type", seq)
+	}
+	setStr(m, "format", s.Format)
+	setStr(m, "pattern", s.Pattern)
+
+	if len(s.Enum) > 0 {
+		v, err := value(s.Enum)
+		if err != nil {
+			return nil, err
+		}
+		set(m, "enum", v)
+	}
+	if s.Min != nil {
+		set(m, "minimum", float(*s.Min))
+	}
+	if s.Max != nil {
+		set(m, "maximum", float(*s.Max))
+	}
+	if s.MinLen != nil {
+		set(m, "minLength", scalar(tagInt, strconv.FormatInt(*s.MinLen, 10)))
+	}
+	if s.MaxLen != nil {
+		set(m, "maxLength", scalar(tagInt, strconv.FormatInt(*s.MaxLen, 10)))
+	}
+	if s.ReadOnly != nil && *s.ReadOnly {
+		set(m, "readOnly", boolean(true))
+	}
+	if s.WriteOnly != nil && *s.WriteOnly {
+		set(m, "writeOnly", boolean(true))
+	}
+
+	if s.Items != nil {
+		n, err := w.schemaRef(s.Items)
+		if err != nil {
+			return nil, err
+		}
+		set(m, "items", n)
+	}
+	if len(s.Properties) > 0 {
+		names := make([]string, 0, len(s.Properties))
+		for k := range s.Properties {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		props := mapping()
+		for _, k := range names {
+			n, err := w.schemaRef(s.Properties[k])
+			if err != nil {
+				return nil, err
+			}
+			set(props, k, n)
+		}
+		set(m, "properties", props)
+	}
+	if len(s.Required) > 0 {
+		req := sequence()
+		for _, r := range s.Required {
+			req.Content = append(req.Content, str(r))
+		}
+		set(m, "required", req)
+	}
+	if s.AdditionalProperties != nil {
+		n, err := w.schemaRef(s.AdditionalProperties)
+		if err != nil {
+			return nil, err
+		}
+		set(m, "additionalProperties", n)
+	} else if s.NoAdditionalProperties {
+		set(m, "additionalProperties", boolean(false))
+	}
+	for _, c := range []struct {
+		key  string
+		refs []*model.SchemaRef
+	}{{"oneOf", s.OneOf}, {"anyOf", s.AnyOf}, {"allOf", s.AllOf}} {
+		if len(c.refs) == 0 {
+			continue
+		}
+		seq := sequence()
+		for _, r := range c.refs {
+			n, err := w.schemaRef(r)
+			if err != nil {
+				return nil, err
+			}
+			seq.Content = append(seq.Content, n)
+		}
+		set(m, c.key, seq)
+	}
+
+	if v, ok := s.DefaultValue(); ok {
+		n, err := value(v)
+		if err != nil {
+			return nil, err
+		}
+		set(m, "default", n)
+	}
+	if v, ok := s.ExampleValue(); ok {
+		n, err := value(v)
+		if err != nil {
+			return nil, err
+		}
+		set(m, "example", n)
+	}
+	return m, nil
+}
+
+func hasType(types []model.SchemaType, t model.SchemaType) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
+
+func securitySchemes(src map[string]model.SecurityScheme) *yaml.Node {
+	names := make([]string, 0, len(src))
+	for k := range src {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	out := mapping()
+	for _, name := range names {
+		s := src[name]
+		m := mapping()
+		setStr(m, "type", string(s.Type))
+		setStr(m, "description", s.Description)
+		switch s.Type {
+		case model.SecurityHTTP:
+			setStr(m, "scheme", s.Subtype)
+			setStr(m, "bearerFormat", s.BearerFormat)
+		case model.SecurityAPIKey:
+			setStr(m, "name", s.Name)
+			setStr(m, "in", string(s.In))
+		case model.SecurityOAuth2:
+			flows := mapping()
+			for _, f := range s.OAuthFlows {
+				fm := mapping()
+				setStr(fm, "authorizationUrl", f.AuthorizationURL)
+				setStr(fm, "tokenUrl", f.TokenURL)
+				setStr(fm, "refreshUrl", f.RefreshURL)
+				scopes := mapping()
+				for _, sc := range f.Scopes {
+					set(scopes, sc, str(""))
+				}
+				set(fm, "scopes", scopes)
+				set(flows, string(f.Type), fm)
+			}
+			set(m, "flows", flows)
+		}
+		set(out, name, m)
+	}
+	return out
+}
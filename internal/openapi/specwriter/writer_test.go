@@ -0,0 +1,142 @@
+package specwriter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/openapi"
+	"github.com/unkn0wn-root/resterm/internal/openapi/model"
+	"github.com/unkn0wn-root/resterm/internal/openapi/parser"
+)
+
+func sample() *model.Spec {
+	str := func() *model.SchemaRef {
+		return &model.SchemaRef{Node: &model.Schema{Types: []model.SchemaType{model.TypeString}}}
+	}
+	user := &model.Schema{
+		Types: []model.SchemaType{model.TypeObject},
+		Properties: map[string]*model.SchemaRef{
+			"name": str(),
+			"id":   {Node: &model.Schema{Types: []model.SchemaType{model.TypeInteger}}},
+			"nick": {Node: &model.Schema{Types: []model.SchemaType{model.TypeString, model.TypeNull}}},
+		},
+		Required: []string{"id", "name"},
+	}
+	return &model.Spec{
+		Title:   "Users",
+		Version: "1.2.3",
+		Servers: []model.Server{{URL: "https://api.example.com", Description: "prod"}},
+		SecuritySchemes: map[string]model.SecurityScheme{
+			"bearerAuth": {Type: model.SecurityHTTP, Subtype: "bearer"},
+		},
+		Operations: []model.Operation{
+			{
+				ID:     "getUser",
+				Method: model.MethodGet,
+				Path:   "/users/{id}",
+				Tags:   []string{"users"},
+				Parameters: []model.Parameter{
+					{Name: "id", Location: model.InPath, Schema: str()},
+					{Name: "expand", Location: model.InQuery, Schema: str(),
+						Example: model.Example{Value: "team", HasValue: true}},
+				},
+				Responses: []model.Response{{
+					StatusCode: "200",
+					MediaTypes: []model.MediaType{{
+						ContentType: "application/json",
+						Schema:      &model.SchemaRef{Node: user},
+						Examples: []model.Example{{
+							Name:     "found",
+							Value:    map[string]any{"id": json.Number("7"), "name": "Ada"},
+							HasValue: true,
+						}},
+					}},
+				}},
+				Security: []model.SecurityRequirement{{SchemeName: "bearerAuth"}},
+			},
+			{
+				ID:     "ping",
+				Method: model.MethodPost,
+				Path:   "/ping",
+			},
+		},
+	}
+}
+
+func TestWriteFileParsesBack(t *testing.T) {
+	for _, name := range []string{"openapi.yml", "openapi.json"} {
+		t.Run(name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), name)
+			if err := WriteFile(sample(), dst, false); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			spec, err := parser.NewLoader().Parse(context.Background(), dst, openapi.ParseOptions{})
+			if err != nil {
+				t.Fatalf("parse back: %v", err)
+			}
+			if spec.Title != "Users" || spec.Version != "1.2.3" || len(spec.Operations) != 2 {
+				t.Fatalf("spec = %+v", spec)
+			}
+			var get model.Operation
+			for _, op := range spec.Operations {
+				if op.ID == "getUser" {
+					get = op
+				}
+			}
+			if len(get.Parameters) != 2 || !get.Parameters[0].Required {
+				t.Fatalf("parameters = %+v", get.Parameters)
+			}
+			if len(get.Security) != 1 || get.Security[0].SchemeName != "bearerAuth" {
+				t.Fatalf("security = %+v", get.Security)
+			}
+			mt := get.Responses[0].MediaTypes[0]
+			if mt.Schema == nil || mt.Schema.Node == nil || len(mt.Schema.Node.Properties) != 3 {
+				t.Fatalf("schema = %+v", mt.Schema)
+			}
+			if len(mt.Examples) != 1 || mt.Examples[0].Name != "found" {
+				t.Fatalf("examples = %+v", mt.Examples)
+			}
+		})
+	}
+}
+
+func TestRenderYAML(t *testing.T) {
+	out, err := Render(sample(), FormatYAML)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	text := string(out)
+	for _, want := range []string{
+		"openapi: 3.1.0\n",
+		"type: [string, \"null\"]",
+		"default:\n          description: Response not recorded",
+		"example: team",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "/users/{id}") > strings.Index(text, "/ping") {
+		t.Errorf("paths are not in operation order:\n%s", text)
+	}
+}
+
+func TestWriteFileKeepsExisting(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "openapi.yml")
+	if err := os.WriteFile(dst, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(sample(), dst, false); err == nil {
+		t.Fatalf("existing file was overwritten")
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "keep" {
+		t.Fatalf("file changed: %q", data)
+	}
+	if err := WriteFile(sample(), dst, true); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/duration"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/rts"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
+	"github.com/unkn0wn-root/resterm/internal/snapshot"
 	"github.com/unkn0wn-root/resterm/internal/tracebudget"
 	"github.com/unkn0wn-root/resterm/internal/vars"
 )
@@ -268,6 +270,20 @@ func parseAuthDirective(rest string) (authDirective, error) {
 		}
 	}
 
+	if key, name, ok := strings.Cut(fields[0], "="); ok && strings.EqualFold(key, "name") {
+		if !explicitScope || dir.Scope == directive.ScopeRequest {
+			return dir, errors.New("@auth name= requires file or global scope")
+		}
+		dir.Name = strings.TrimSpace(name)
+		if dir.Name == "" {
+			return dir, errors.New("@auth name cannot be empty")
+		}
+		fields = fields[1:]
+		if len(fields) == 0 {
+			return dir, fmt.Errorf("@auth profile %q requires an auth spec", dir.Name)
+		}
+	}
+
 	if strings.EqualFold(fields[0], restfile.AuthDisableWord) {
 		if dir.Scope != directive.ScopeRequest {
 			return dir, fmt.Errorf("@auth %s scope does not support none", dir.Scope.String())
@@ -303,7 +319,7 @@ func parseAuthSpec(fields []string) (*restfile.AuthSpec, error) {
 	authType := restfile.AuthKind(fields[0]).Canonical()
 	params := make(map[string]string)
 	switch authType {
-	case restfile.AuthBasic:
+	case restfile.AuthBasic, restfile.AuthDigest:
 		if len(fields) >= 3 {
 			params["username"] = fields[1]
 			params["password"] = strings.Join(fields[2:], " ")
@@ -519,11 +535,19 @@ func parseCompareDirective(rest string) (*restfile.CompareSpec, error) {
 		return nil, err
 	}
 
-	baseline, err := compareOption(opts, compareBaselineKeys...)
+	baseline, err := compareOption(directive.Compare, opts, compareBaselineKeys...)
 	if err != nil {
 		return nil, err
 	}
-	group, err := compareOption(opts, "group")
+	group, err := compareOption(directive.Compare, opts, "group")
+	if err != nil {
+		return nil, err
+	}
+	ignore, err := compareOption(directive.Compare, opts, "ignore")
+	if err != nil {
+		return nil, err
+	}
+	match, err := compareOption(directive.Compare, opts, "match")
 	if err != nil {
 		return nil, err
 	}
@@ -541,7 +565,16 @@ func parseCompareDirective(rest string) (*restfile.CompareSpec, error) {
 	if err != nil {
 		return nil, err
 	}
-	spec := &restfile.CompareSpec{Environments: envs, Baseline: envs[0], Group: group}
+	spec := &restfile.CompareSpec{
+		Environments: envs,
+		Baseline:     envs[0],
+		Group:        group,
+		Ignore:       semdiff.SplitList(ignore),
+		Match:        semdiff.SplitList(match),
+	}
+	if _, err := semdiff.Compile(spec.Ignore, spec.Match); err != nil {
+		return nil, fmt.Errorf("@compare ignore: %w", err)
+	}
 	if baseline == "" {
 		return spec, nil
 	}
@@ -554,11 +587,12 @@ func parseCompareDirective(rest string) (*restfile.CompareSpec, error) {
 	return nil, fmt.Errorf("@compare baseline %q must match one of the environments", baseline)
 }
 
-// Compare options reject empty values even when another alias supplies one.
-func compareOption(opts directive.Options, keys ...string) (string, error) {
+// Compare and @as options reject empty values even when another alias supplies
+// one.
+func compareOption(name directive.Name, opts directive.Options, keys ...string) (string, error) {
 	for _, key := range keys {
 		if raw, ok := opts.Lookup(key); ok && strings.TrimSpace(raw) == "" {
-			return "", fmt.Errorf("@compare %s cannot be empty", key)
+			return "", fmt.Errorf("@%s %s cannot be empty", name, key)
 		}
 	}
 	value, _ := opts.PopAny(keys...)
@@ -589,6 +623,130 @@ func compareEnvironments(fields []string) ([]string, error) {
 	return envs, nil
 }
 
+// Names may be split by commas or spaces, so "admin, viewer" and
+// "admin viewer" read the same.
+func parseAsDirective(rest string) (*restfile.IdentitySpec, error) {
+	fields := directive.Fields(strings.ReplaceAll(rest, ",", " "))
+	opts, err := directive.OptionFields(directive.As, fields)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := compareOption(directive.As, opts, compareBaselineKeys...)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Leftover(directive.As); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		name := strings.TrimSpace(field)
+		if name == "" || strings.Contains(name, "=") {
+			continue
+		}
+		lowered := strings.ToLower(name)
+		if _, exists := seen[lowered]; exists {
+			return nil, fmt.Errorf("@as duplicate identity %q", name)
+		}
+		seen[lowered] = struct{}{}
+		names = append(names, name)
+	}
+	if len(names) < 2 {
+		return nil, errors.New("@as requires at least two identities")
+	}
+
+	spec := &restfile.IdentitySpec{Names: names, Baseline: names[0]}
+	if baseline == "" {
+		return spec, nil
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, baseline) {
+			spec.Baseline = name
+			return spec, nil
+		}
+	}
+	return nil, fmt.Errorf("@as baseline %q must match one of the identities", baseline)
+}
+
+// parseContractDirective reads "[spec] [operation=id]" or "off". Without a
+// spec the run-wide --contract spec is used.
+func parseContractDirective(rest string) (*restfile.ContractSpec, error) {
+	fields := directive.Fields(rest)
+	opts, err := directive.OptionFields(directive.Contract, fields)
+	if err != nil {
+		return nil, err
+	}
+	op, err := compareOption(directive.Contract, opts, "operation", "op")
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Leftover(directive.Contract); err != nil {
+		return nil, err
+	}
+
+	var args []string
+	for _, field := range fields {
+		if !strings.Contains(field, "=") {
+			args = append(args, field)
+		}
+	}
+	switch {
+	case len(args) > 1:
+		return nil, fmt.Errorf("@contract takes one spec path, got %d", len(args))
+	case len(args) == 1 && strings.EqualFold(args[0], "off"):
+		if op != "" {
+			return nil, errors.New("@contract off does not take an operation")
+		}
+		return &restfile.ContractSpec{Disabled: true}, nil
+	case len(args) == 0 && op == "":
+		return nil, errors.New("@contract requires a spec path or operation=")
+	}
+	spec := &restfile.ContractSpec{Operation: op}
+	if len(args) == 1 {
+		spec.Spec = args[0]
+	}
+	return spec, nil
+}
+
+// parseSnapshotDirective reads "[path] [ignore=$.a,$.b]". Without a path the
+// snapshot is named after the request.
+func parseSnapshotDirective(rest string) (*restfile.SnapshotSpec, error) {
+	fields := directive.Fields(rest)
+	opts, err := directive.OptionFields(directive.Snapshot, fields)
+	if err != nil {
+		return nil, err
+	}
+	ignore, err := compareOption(directive.Snapshot, opts, "ignore")
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Leftover(directive.Snapshot); err != nil {
+		return nil, err
+	}
+
+	spec := &restfile.SnapshotSpec{}
+	for _, field := range fields {
+		if strings.Contains(field, "=") {
+			continue
+		}
+		if spec.Path != "" {
+			return nil, errors.New("@snapshot takes one file path")
+		}
+		spec.Path = field
+	}
+	for _, path := range strings.Split(ignore, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			if err := snapshot.CheckPath(path); err != nil {
+				return nil, err
+			}
+			spec.Ignore = append(spec.Ignore, path)
+		}
+	}
+	return spec, nil
+}
+
 func parseDuration(value string) time.Duration {
 	dur, ok := duration.Parse(value)
 	if !ok {
@@ -105,6 +105,12 @@ func (b *documentBuilder) handleRequestMetadataDirective(d parsedDirective) dire
 		return directiveApplied
 	case directive.Compare:
 		return b.setCompare(d)
+	case directive.As:
+		return b.setIdentities(d)
+	case directive.Contract:
+		return b.setContract(d)
+	case directive.Snapshot:
+		return b.setSnapshot(d)
 	}
 	return directiveIgnored
 }
@@ -189,6 +195,33 @@ func (b *documentBuilder) setCompare(d parsedDirective) directiveOutcome {
 	return directiveApplied
 }
 
+func (b *documentBuilder) setIdentities(d parsedDirective) directiveOutcome {
+	spec, err := parseAsDirective(d.Args)
+	if err != nil {
+		return b.reject(d, err.Error())
+	}
+	b.request.metadata.Identities = spec
+	return directiveApplied
+}
+
+func (b *documentBuilder) setContract(d parsedDirective) directiveOutcome {
+	spec, err := parseContractDirective(d.Args)
+	if err != nil {
+		return b.reject(d, err.Error())
+	}
+	b.request.metadata.Contract = spec
+	return directiveApplied
+}
+
+func (b *documentBuilder) setSnapshot(d parsedDirective) directiveOutcome {
+	spec, err := parseSnapshotDirective(d.Args)
+	if err != nil {
+		return b.reject(d, err.Error())
+	}
+	b.request.metadata.Snapshot = spec
+	return directiveApplied
+}
+
 func appendDesc(existing, add string) string {
 	if existing != "" {
 		existing += "\n"
@@ -1961,6 +1961,28 @@ GET https://example.com/health
 	}
 }
 
+func TestParseCompareIgnoreRules(t *testing.T) {
+	src := `# @name Compare
+# @compare dev prod ignore=$.requestId,$.items[*].updatedAt match=id,sku
+GET https://example.com/items
+`
+	doc := Parse("compare.http", []byte(src))
+	if len(doc.Errors) != 0 {
+		t.Fatalf("parse errors: %v", doc.Errors)
+	}
+	spec := doc.Requests[0].Metadata.Compare
+	if spec == nil {
+		t.Fatal("expected compare metadata")
+	}
+	wantIgnore := []string{"$.requestId", "$.items[*].updatedAt"}
+	if !reflect.DeepEqual(spec.Ignore, wantIgnore) {
+		t.Fatalf("ignore = %#v, want %#v", spec.Ignore, wantIgnore)
+	}
+	if !reflect.DeepEqual(spec.Match, []string{"id", "sku"}) {
+		t.Fatalf("match = %#v", spec.Match)
+	}
+}
+
 func TestParseCompareDirectiveErrors(t *testing.T) {
 	src := `# @name Compare
 # @compare dev dev
@@ -2003,6 +2025,11 @@ func TestParseCompareDirectiveOptionErrors(t *testing.T) {
 			src:  "# @compare dev stage base=dev baseline=\nGET https://example.com\n",
 			want: "@compare baseline cannot be empty",
 		},
+		{
+			name: "bad ignore path",
+			src:  "# @compare dev stage ignore=$.items[x]\nGET https://example.com\n",
+			want: `@compare ignore: path "$.items[x]": bad index [x]`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -2018,6 +2045,144 @@ func TestParseCompareDirectiveOptionErrors(t *testing.T) {
 	}
 }
 
+func TestParseNamedAuthProfile(t *testing.T) {
+	src := `# @auth file name=admin bearer {{adminToken}}
+# @auth global Authorization Bearer shared
+GET https://example.com
+`
+	doc := Parse("named-auth.http", []byte(src))
+	if len(doc.Errors) != 0 {
+		t.Fatalf("expected no parse errors, got %v", doc.Errors)
+	}
+	if len(doc.Auth) != 2 {
+		t.Fatalf("expected 2 auth profiles, got %d", len(doc.Auth))
+	}
+	named := doc.Auth[0]
+	if named.Name != "admin" || named.Spec.Type != "bearer" ||
+		named.Spec.Params["token"] != "{{adminToken}}" {
+		t.Fatalf("named profile = %+v", named)
+	}
+	// A header called Authorization is not mistaken for a profile name.
+	hdr := doc.Auth[1]
+	if hdr.Name != "" || hdr.Spec.Type != "header" || hdr.Spec.Params["value"] != "Bearer shared" {
+		t.Fatalf("header profile = %+v", hdr)
+	}
+
+	for _, bad := range []string{
+		"# @auth name=admin bearer x\nGET https://example.com\n",
+		"# @auth file name= bearer x\nGET https://example.com\n",
+		"# @auth file name=admin\nGET https://example.com\n",
+	} {
+		if doc := Parse("bad.http", []byte(bad)); len(doc.Errors) == 0 {
+			t.Fatalf("expected a parse error for %q", bad)
+		}
+	}
+}
+
+func TestParseAsDirective(t *testing.T) {
+	src := `# @as admin, viewer anonymous base=viewer
+GET https://example.com
+`
+	doc := Parse("as.http", []byte(src))
+	if len(doc.Errors) != 0 {
+		t.Fatalf("expected no parse errors, got %v", doc.Errors)
+	}
+	spec := doc.Requests[0].Metadata.Identities
+	if spec == nil {
+		t.Fatal("expected identity metadata")
+	}
+	want := []string{"admin", "viewer", "anonymous"}
+	if !reflect.DeepEqual(spec.Names, want) || spec.Baseline != "viewer" {
+		t.Fatalf("identity spec = %+v", spec)
+	}
+
+	for src, msg := range map[string]string{
+		"# @as admin\nGET https://example.com\n":                  "@as requires at least two identities",
+		"# @as admin,Admin\nGET https://example.com\n":            `@as duplicate identity "Admin"`,
+		"# @as admin,viewer base=root\nGET https://example.com\n": `@as baseline "root" must match one of the identities`,
+		"# @as admin,viewer base=\nGET https://example.com\n":     "@as base cannot be empty",
+	} {
+		doc := Parse("as.http", []byte(src))
+		if !hasParseMessage(doc.Errors, msg) {
+			t.Fatalf("expected %q, got %v", msg, doc.Errors)
+		}
+		if doc.Requests[0].Metadata.Identities != nil {
+			t.Fatal("expected identity metadata to be nil on error")
+		}
+	}
+}
+
+func TestParseContractDirective(t *testing.T) {
+	cases := map[string]restfile.ContractSpec{
+		"# @contract ./openapi.yml operation=getUser": {Spec: "./openapi.yml", Operation: "getUser"},
+		`# @contract "specs/my api.yml"`:              {Spec: "specs/my api.yml"},
+		"# @contract op=getUser":                      {Operation: "getUser"},
+		"# @contract off":                             {Disabled: true},
+	}
+	for line, want := range cases {
+		doc := Parse("contract.http", []byte(line+"\nGET https://example.com\n"))
+		if len(doc.Errors) != 0 {
+			t.Fatalf("%s: unexpected errors %v", line, doc.Errors)
+		}
+		got := doc.Requests[0].Metadata.Contract
+		if got == nil || *got != want {
+			t.Fatalf("%s: contract = %+v, want %+v", line, got, want)
+		}
+	}
+
+	for src, msg := range map[string]string{
+		"# @contract\nGET https://example.com\n":                  "@contract requires a spec path or operation=",
+		"# @contract a.yml b.yml\nGET https://example.com\n":      "@contract takes one spec path, got 2",
+		"# @contract off operation=x\nGET https://example.com\n":  "@contract off does not take an operation",
+		"# @contract a.yml operation=\nGET https://example.com\n": "@contract operation cannot be empty",
+		"# @contract a.yml status=200\nGET https://example.com\n": "status",
+	} {
+		doc := Parse("contract.http", []byte(src))
+		if !hasParseMessage(doc.Errors, msg) {
+			t.Fatalf("expected %q, got %v", msg, doc.Errors)
+		}
+		if doc.Requests[0].Metadata.Contract != nil {
+			t.Fatal("expected contract metadata to be nil on error")
+		}
+	}
+}
+
+func TestParseSnapshotDirective(t *testing.T) {
+	cases := map[string]restfile.SnapshotSpec{
+		"# @snapshot ./__snapshots__/get-user.json ignore=$.id,$.createdAt": {
+			Path:   "./__snapshots__/get-user.json",
+			Ignore: []string{"$.id", "$.createdAt"},
+		},
+		"# @snapshot":                     {},
+		"# @snapshot ignore=$..updatedAt": {Ignore: []string{"$..updatedAt"}},
+	}
+	for line, want := range cases {
+		doc := Parse("snapshot.http", []byte(line+"\nGET https://example.com\n"))
+		if len(doc.Errors) != 0 {
+			t.Fatalf("%s: unexpected errors %v", line, doc.Errors)
+		}
+		got := doc.Requests[0].Metadata.Snapshot
+		if got == nil || !reflect.DeepEqual(*got, want) {
+			t.Fatalf("%s: snapshot = %+v, want %+v", line, got, want)
+		}
+	}
+
+	for src, msg := range map[string]string{
+		"# @snapshot a.json b.json\nGET https://example.com\n":   "@snapshot takes one file path",
+		"# @snapshot a.json ignore=\nGET https://example.com\n":  "@snapshot ignore cannot be empty",
+		"# @snapshot a.json ignore=$\nGET https://example.com\n": "matches the whole body",
+		"# @snapshot a.json mode=x\nGET https://example.com\n":   "mode",
+	} {
+		doc := Parse("snapshot.http", []byte(src))
+		if !hasParseMessage(doc.Errors, msg) {
+			t.Fatalf("expected %q, got %v", msg, doc.Errors)
+		}
+		if doc.Requests[0].Metadata.Snapshot != nil {
+			t.Fatal("expected snapshot metadata to be nil on error")
+		}
+	}
+}
+
 func TestParseCompareDirectiveBaselineAliases(t *testing.T) {
 	for _, key := range compareBaselineKeys {
 		t.Run(key, func(t *testing.T) {
@@ -97,8 +97,8 @@ func (b *documentBuilder) addConstant(name, value string, line int) {
 }
 
 func (b *documentBuilder) checkEnvRef(line int, value string) {
-	if key, ok := vars.EnvRefKey(value); ok && key == "" {
-		b.addError(line, "env: reference is missing a variable name")
+	if ref, ok := vars.ParseRef(value); ok && ref.Key == "" {
+		b.addError(line, ref.Scheme+": reference is missing a "+ref.Noun())
 	}
 }
 
@@ -0,0 +1,108 @@
+package postman
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+var oauthGrants = map[string]string{
+	"client_credentials":           "client_credentials",
+	"password_credentials":         "password",
+	"authorization_code":           "authorization_code",
+	"authorization_code_with_pkce": "authorization_code",
+}
+
+// authSpec maps a Postman auth block. disabled is set for noauth, which turns
+// off whatever the request would otherwise inherit. A nil spec with no error
+// means the block defers to its parent.
+func authSpec(a *Auth) (spec *restfile.AuthSpec, disabled bool, err error) {
+	p := a.Params
+	get := func(key string) string { return strings.TrimSpace(p[key]) }
+	switch a.Type {
+	case "", "inherit":
+		return nil, false, nil
+	case "noauth":
+		return nil, true, nil
+	case "bearer":
+		tok := get("token")
+		if tok == "" {
+			return nil, false, errors.New("bearer auth has no token and was skipped")
+		}
+		return authOf(restfile.AuthBearer, "token", tok), false, nil
+	case "basic", "digest":
+		user, pass := get("username"), get("password")
+		if user == "" || pass == "" {
+			return nil, false, fmt.Errorf("%s auth needs a username and password and was skipped", a.Type)
+		}
+		kind := restfile.AuthBasic
+		if a.Type == "digest" {
+			kind = restfile.AuthDigest
+		}
+		return authOf(kind, "username", user, "password", pass), false, nil
+	case "apikey":
+		name, val := get("key"), get("value")
+		if name == "" || val == "" {
+			return nil, false, errors.New("apikey auth needs a key and value and was skipped")
+		}
+		place := "header"
+		if strings.EqualFold(get("in"), "query") {
+			place = "query"
+		}
+		return authOf(restfile.AuthAPIKey, "placement", place, "name", name, "value", val), false, nil
+	case "oauth2":
+		return oauth2Spec(get)
+	default:
+		return nil, false, fmt.Errorf("%s auth is not supported and was skipped", a.Type)
+	}
+}
+
+func oauth2Spec(get func(string) string) (*restfile.AuthSpec, bool, error) {
+	tokenURL := get("accessTokenUrl")
+	if tokenURL == "" {
+		// A collection that only stores a fetched token is a bearer token.
+		if tok := get("accessToken"); tok != "" {
+			return authOf(restfile.AuthBearer, "token", tok), false, nil
+		}
+		return nil, false, errors.New("oauth2 auth has no access token URL and was skipped")
+	}
+	grant := get("grant_type")
+	if grant == "" {
+		grant = "authorization_code"
+	}
+	g, ok := oauthGrants[grant]
+	if !ok {
+		return nil, false, fmt.Errorf("oauth2 grant %q is not supported and was skipped", grant)
+	}
+	params := []string{"token_url", tokenURL, "grant", g}
+	for _, kv := range [][2]string{
+		{"auth_url", "authUrl"},
+		{"redirect_uri", "redirect_uri"},
+		{"client_id", "clientId"},
+		{"client_secret", "clientSecret"},
+		{"scope", "scope"},
+		{"username", "username"},
+		{"password", "password"},
+		{"state", "state"},
+	} {
+		if v := get(kv[1]); v != "" {
+			params = append(params, kv[0], v)
+		}
+	}
+	clientAuth := "basic"
+	if get("client_authentication") == "body" {
+		clientAuth = "body"
+	}
+	params = append(params, "client_auth", clientAuth)
+	return authOf(restfile.AuthOAuth2, params...), false, nil
+}
+
+func authOf(kind restfile.AuthKind, kv ...string) *restfile.AuthSpec {
+	params := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		params[kv[i]] = kv[i+1]
+	}
+	return &restfile.AuthSpec{Type: kind, Params: params}
+}
@@ -0,0 +1,127 @@
+package postman
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restimport"
+)
+
+const (
+	headerContentType = "Content-Type"
+	mimeJSON          = "application/json"
+	mimeFormURL       = "application/x-www-form-urlencoded"
+	mimeMultipart     = "multipart/form-data"
+	mimeOctetStream   = "application/octet-stream"
+)
+
+// Postman adds a Content-Type for raw bodies from the language picker
+// unless the request sets one itself.
+var rawLanguageTypes = map[string]string{
+	"json":       mimeJSON,
+	"xml":        "application/xml",
+	"html":       "text/html",
+	"text":       "text/plain",
+	"javascript": "application/javascript",
+}
+
+func (cv *converter) body(req *restfile.Request, b *Body, warn func(string)) {
+	if b == nil || b.Disabled {
+		return
+	}
+	switch b.Mode {
+	case "", "none":
+	case "raw":
+		if strings.TrimSpace(b.Raw) == "" {
+			return
+		}
+		req.Body.Text = cv.text(b.Raw, warn)
+		if ct, ok := rawLanguageTypes[b.Options.Raw.Language]; ok {
+			restimport.SetDefaultHeader(req.Headers, headerContentType, ct)
+		}
+	case "urlencoded":
+		var pairs []string
+		for _, f := range b.URLEncoded {
+			if f.Disabled || f.Key == "" {
+				continue
+			}
+			pairs = append(pairs, restimport.FormEscape(f.Key)+"="+restimport.FormEscape(cv.text(string(f.Value), warn)))
+		}
+		if len(pairs) == 0 {
+			return
+		}
+		req.Body.Text = strings.Join(pairs, "&")
+		restimport.SetDefaultHeader(req.Headers, headerContentType, mimeFormURL)
+	case "formdata":
+		text, boundary := cv.multipart(b.FormData, warn)
+		if text == "" {
+			return
+		}
+		req.Body.Text = text
+		// The boundary has to match the body, so a Content-Type from the
+		// collection is replaced rather than kept.
+		restimport.DelHeader(req.Headers, headerContentType)
+		req.Headers[headerContentType] = []string{mimeMultipart + "; boundary=" + boundary}
+	case "file":
+		if b.File == nil || strings.TrimSpace(b.File.Src) == "" {
+			warn("file body has no source path and was skipped")
+			return
+		}
+		req.Body.FilePath = strings.TrimSpace(b.File.Src)
+	case "graphql":
+		if b.GraphQL == nil {
+			return
+		}
+		payload := map[string]any{"query": b.GraphQL.Query}
+		if v := strings.TrimSpace(b.GraphQL.Variables); v != "" {
+			var parsed any
+			if err := json.Unmarshal([]byte(v), &parsed); err != nil {
+				warn("graphql variables are not valid JSON and were dropped")
+			} else {
+				payload["variables"] = parsed
+			}
+		}
+		data, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			warn("graphql body could not be encoded: " + err.Error())
+			return
+		}
+		req.Body.Text = cv.text(string(data), warn)
+		restimport.SetDefaultHeader(req.Headers, headerContentType, mimeJSON)
+	default:
+		warn("body mode " + b.Mode + " is not supported and was skipped")
+	}
+}
+
+// multipart keeps the enabled fields; file parts point at their source path.
+func (cv *converter) multipart(fields []KeyValue, warn func(string)) (string, string) {
+	var parts []restimport.Part
+	for _, f := range fields {
+		if f.Disabled || f.Key == "" {
+			continue
+		}
+		p := restimport.Part{Name: f.Key, ContentType: f.ContentType}
+		if f.Type == "file" {
+			var src string
+			if len(f.Src) > 0 {
+				src = strings.TrimSpace(f.Src[0])
+			}
+			if src == "" {
+				warn("form file " + f.Key + " has no source path and was skipped")
+				continue
+			}
+			if len(f.Src) > 1 {
+				warn("form file " + f.Key + " lists several files; only the first was kept")
+			}
+			p.File = src
+			if p.ContentType == "" {
+				p.ContentType = mimeOctetStream
+			}
+		} else {
+			p.Value = cv.text(string(f.Value), warn)
+		}
+		parts = append(parts, p)
+	}
+	return restimport.Multipart(parts)
+}
@@ -0,0 +1,403 @@
+package postman
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/directive"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restimport"
+)
+
+// Layout decides how folders are written out.
+type Layout string
+
+const (
+	// LayoutFiles writes one .http file per top-level folder and tags the
+	// requests of deeper folders.
+	LayoutFiles Layout = "files"
+	// LayoutTags writes a single .http file and tags every request with the
+	// folders above it.
+	LayoutTags Layout = "tags"
+)
+
+func ParseLayout(s string) (Layout, error) {
+	switch Layout(strings.ToLower(strings.TrimSpace(s))) {
+	case "", LayoutFiles:
+		return LayoutFiles, nil
+	case LayoutTags:
+		return LayoutTags, nil
+	default:
+		return "", fmt.Errorf("postman: unknown folder layout %q (want files or tags)", s)
+	}
+}
+
+// File is one generated document. Name is relative to the output directory.
+type File struct {
+	Name string
+	Doc  *restfile.Document
+	Warn []string
+}
+
+// inherited is what a folder passes down to the requests below it.
+type inherited struct {
+	restimport.Scope[*Auth]
+	scripts []restfile.ScriptBlock
+}
+
+type converter struct {
+	c      *Collection
+	layout Layout
+	files  []*File
+	byKey  map[string]*File
+	names  map[*File]map[string]int
+	common []string
+	auth   *restfile.AuthSpec
+	vars   []restfile.Variable
+}
+
+// Convert turns a collection into one or more documents. Postman features
+// with no equivalent are reported as warnings on the file they affect.
+func Convert(c *Collection, layout Layout) []File {
+	cv := &converter{
+		c:      c,
+		layout: layout,
+		byKey:  map[string]*File{},
+		names:  map[*File]map[string]int{},
+	}
+	cv.vars = cv.variables(c.Variable, directive.ScopeFile, "collection")
+	if c.Auth != nil {
+		spec, _, err := authSpec(c.Auth)
+		switch {
+		case err != nil:
+			cv.common = append(cv.common, "collection: "+err.Error())
+		case spec != nil:
+			cv.auth = spec
+		}
+	}
+
+	root := inherited{}
+	root.scripts = cv.scripts(c.Event, "collection", &cv.common)
+	for _, it := range c.Item {
+		cv.walk(it, root, nil, 0)
+	}
+
+	out := make([]File, 0, len(cv.files))
+	for _, f := range cv.files {
+		if len(cv.common) > 0 {
+			f.Warn = append(append([]string(nil), cv.common...), f.Warn...)
+		}
+		out = append(out, *f)
+	}
+	return out
+}
+
+func (cv *converter) walk(it Item, in inherited, file *File, depth int) {
+	if !it.isFolder() {
+		if it.Request == nil {
+			return
+		}
+		if file == nil {
+			file = cv.file("", cv.c.Info.Name)
+		}
+		cv.request(file, it, in)
+		return
+	}
+
+	if cv.layout == LayoutFiles && depth == 0 {
+		file = cv.file(it.Name, it.Name)
+	} else {
+		in.Scope = in.Tag(slug(it.Name))
+	}
+	where := fmt.Sprintf("folder %q", it.Name)
+	if file != nil {
+		in.scripts = append(append([]restfile.ScriptBlock(nil), in.scripts...),
+			cv.scripts(it.Event, where, &file.Warn)...)
+	} else {
+		in.scripts = append(append([]restfile.ScriptBlock(nil), in.scripts...),
+			cv.scripts(it.Event, where, &cv.common)...)
+	}
+	in.Scope = in.Folder(it.Auth, it.Auth != nil,
+		cv.variables(it.Variable, directive.ScopeRequest, where))
+	for _, child := range it.Item {
+		cv.walk(child, in, file, depth+1)
+	}
+}
+
+// file returns the document for a top-level folder, creating it on first use so
+// empty folders produce no file. key is empty for requests outside any folder.
+func (cv *converter) file(key, name string) *File {
+	if cv.layout == LayoutTags {
+		key = ""
+	}
+	if f, ok := cv.byKey[key]; ok {
+		return f
+	}
+	base := slug(name)
+	if base == "" {
+		base = "collection"
+	}
+	fname := base + ".http"
+	for n := 2; cv.taken(fname); n++ {
+		fname = fmt.Sprintf("%s-%d.http", base, n)
+	}
+	f := &File{Name: fname, Doc: &restfile.Document{}}
+	f.Doc.Variables = append([]restfile.Variable(nil), cv.vars...)
+	if cv.auth != nil {
+		f.Doc.Auth = []restfile.AuthProfile{{Scope: directive.ScopeFile, Spec: *cv.auth.Clone()}}
+	}
+	cv.byKey[key] = f
+	cv.files = append(cv.files, f)
+	return f
+}
+
+func (cv *converter) taken(name string) bool {
+	for _, f := range cv.files {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (cv *converter) request(f *File, it Item, in inherited) {
+	src := it.Request
+	name := cv.uniqueName(f, strings.Join(strings.Fields(it.Name), " "))
+	where := fmt.Sprintf("request %q", name)
+	warn := func(msg string) { f.Warn = append(f.Warn, where+": "+msg) }
+
+	method := strings.ToUpper(strings.TrimSpace(src.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	target, pathVars := buildURL(src.URL)
+	req := &restfile.Request{
+		Method:  method,
+		URL:     cv.text(target, warn),
+		Headers: http.Header{},
+	}
+	req.Metadata.Name = name
+	in.Apply(req)
+	desc := string(it.Description)
+	if desc == "" {
+		desc = string(src.Description)
+	}
+	req.Metadata.Description = strings.TrimSpace(desc)
+
+	for _, v := range pathVars {
+		if val := string(v.Value); val != "" {
+			req.Variables = append(req.Variables, restfile.Variable{
+				Name:  v.Key,
+				Value: cv.text(val, warn),
+				Scope: directive.ScopeRequest,
+			})
+		}
+	}
+
+	for _, h := range src.Header {
+		if h.Disabled || strings.TrimSpace(h.Key) == "" {
+			continue
+		}
+		key := strings.TrimSpace(h.Key)
+		req.Headers[key] = append(req.Headers[key], cv.text(string(h.Value), warn))
+	}
+	cv.body(req, src.Body, warn)
+
+	auth := in.AuthFor(src.Auth, src.Auth != nil && src.Auth.Type != "inherit")
+	if auth != nil {
+		spec, disabled, err := authSpec(auth)
+		switch {
+		case err != nil:
+			warn(err.Error())
+		case disabled:
+			req.Metadata.AuthDisabled = true
+		case spec != nil:
+			req.Metadata.Auth = spec
+		}
+	}
+
+	req.Metadata.Scripts = append(req.Metadata.Scripts, in.scripts...)
+	req.Metadata.Scripts = append(req.Metadata.Scripts, cv.scripts(it.Event, where, &f.Warn)...)
+	if len(req.Headers) == 0 {
+		req.Headers = nil
+	}
+	f.Doc.Requests = append(f.Doc.Requests, req)
+}
+
+func (cv *converter) uniqueName(f *File, name string) string {
+	if name == "" {
+		name = "request"
+	}
+	seen := cv.names[f]
+	if seen == nil {
+		seen = map[string]int{}
+		cv.names[f] = seen
+	}
+	seen[name]++
+	if n := seen[name]; n > 1 {
+		return fmt.Sprintf("%s %d", name, n)
+	}
+	return name
+}
+
+func (cv *converter) variables(in []Variable, scope directive.Scope, where string) []restfile.Variable {
+	var out []restfile.Variable
+	for _, v := range in {
+		key := strings.TrimSpace(v.Key)
+		if v.Disabled || key == "" {
+			continue
+		}
+		val := string(v.Value)
+		if strings.ContainsAny(val, "\r\n") {
+			cv.common = append(cv.common,
+				fmt.Sprintf("%s: variable %q spans several lines and was skipped", where, key))
+			continue
+		}
+		out = append(out, restfile.Variable{
+			Name:   key,
+			Value:  cv.text(val, func(msg string) { cv.common = append(cv.common, where+": "+msg) }),
+			Scope:  scope,
+			Secret: v.Type == "secret",
+		})
+	}
+	return out
+}
+
+func (cv *converter) scripts(events []Event, where string, warn *[]string) []restfile.ScriptBlock {
+	var out []restfile.ScriptBlock
+	for _, ev := range events {
+		if ev.Disabled {
+			continue
+		}
+		var kind string
+		switch ev.Listen {
+		case "prerequest":
+			kind = "pre-request"
+		case "test":
+			kind = "test"
+		default:
+			continue
+		}
+		body, left := convertScript(ev.Script.Exec)
+		if body == "" {
+			continue
+		}
+		if len(left) > 0 {
+			*warn = append(*warn, fmt.Sprintf("%s: %s script uses unsupported Postman APIs: %s",
+				where, kind, strings.Join(left, ", ")))
+		}
+		out = append(out, restfile.ScriptBlock{Kind: kind, Lang: "js", Body: body})
+	}
+	return out
+}
+
+// Postman's dynamic variables mostly have a resterm twin. $guid needs no entry
+// because it is already an alias of $uuid.
+var dynamicNames = map[string]string{
+	"$randomUUID":           "$uuid",
+	"$isoTimestamp":         "$timestampISO8601",
+	"$randomFirstName":      "$fake.firstName",
+	"$randomLastName":       "$fake.lastName",
+	"$randomFullName":       "$randomName",
+	"$randomUserName":       "$fake.username",
+	"$randomCompanyName":    "$fake.company",
+	"$randomDomainName":     "$fake.domain",
+	"$randomCity":           "$fake.city",
+	"$randomCountry":        "$fake.country",
+	"$randomPhoneNumber":    "$fake.phone",
+	"$randomWord":           "$fake.word",
+	"$randomLoremSentence":  "$fake.sentence",
+	"$randomExampleEmail":   "$randomEmail",
+	"$randomAlphaNumeric":   "$randomString(1)",
+	"$randomLoremWord":      "$fake.word",
+	"$randomCountryCode":    "$fake.country",
+	"$randomPhoneNumberExt": "$fake.phone",
+}
+
+var knownDynamic = map[string]bool{
+	"$guid":         true,
+	"$uuid":         true,
+	"$timestamp":    true,
+	"$randomInt":    true,
+	"$randomEmail":  true,
+	"$randomName":   true,
+	"$randomString": true,
+}
+
+var templateRef = regexp.MustCompile(`\{\{\s*(\$[A-Za-z][\w.]*)\s*\}\}`)
+
+// text keeps {{var}} references as they are, since resterm reads the same
+// syntax, and renames the dynamic ones.
+func (cv *converter) text(s string, warn func(string)) string {
+	if !strings.Contains(s, "{{$") && !strings.Contains(s, "{{ $") {
+		return s
+	}
+	return templateRef.ReplaceAllStringFunc(s, func(m string) string {
+		name := templateRef.FindStringSubmatch(m)[1]
+		if to, ok := dynamicNames[name]; ok {
+			return "{{" + to + "}}"
+		}
+		if !knownDynamic[name] && !strings.HasPrefix(name, "$fake.") {
+			warn(fmt.Sprintf("dynamic variable {{%s}} has no resterm equivalent", name))
+		}
+		return m
+	})
+}
+
+var pathVar = regexp.MustCompile(`/:([A-Za-z_][\w-]*)`)
+
+// buildURL prefers the raw URL, which is what Postman sends, but rebuilds the
+// query from the parameter list so disabled parameters are dropped. Path
+// variables (/:id) become {{id}} references.
+func buildURL(u URL) (string, []KeyValue) {
+	raw := strings.TrimSpace(u.Raw)
+	if raw == "" {
+		raw = joinURL(u)
+	}
+	base, query, hasQuery := strings.Cut(raw, "?")
+	if u.Query != nil {
+		var qs []string
+		for _, q := range u.Query {
+			if q.Disabled || q.Key == "" {
+				continue
+			}
+			if q.Value == "" {
+				qs = append(qs, q.Key)
+				continue
+			}
+			qs = append(qs, q.Key+"="+string(q.Value))
+		}
+		query, hasQuery = strings.Join(qs, "&"), len(qs) > 0
+	}
+	base = pathVar.ReplaceAllString(base, "/{{$1}}")
+	if hasQuery && query != "" {
+		base += "?" + query
+	}
+	return base, u.Variable
+}
+
+func joinURL(u URL) string {
+	var b strings.Builder
+	if u.Protocol != "" {
+		b.WriteString(u.Protocol)
+		b.WriteString("://")
+	}
+	b.WriteString(strings.Join(u.Host, "."))
+	if u.Port != "" {
+		b.WriteString(":")
+		b.WriteString(u.Port)
+	}
+	if len(u.Path) > 0 {
+		b.WriteString("/")
+		b.WriteString(strings.Join(u.Path, "/"))
+	}
+	return b.String()
+}
+
+var slugSep = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(s string) string {
+	return strings.Trim(slugSep.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
@@ -0,0 +1,183 @@
+package postman
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+func loadCollection(t *testing.T) *Collection {
+	t.Helper()
+	data, err := os.ReadFile("testdata/collection.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	c, err := ParseCollection(data)
+	if err != nil {
+		t.Fatalf("parse collection: %v", err)
+	}
+	return c
+}
+
+// Every generated file has to load back through the parser with the same
+// requests, or the import is only half done.
+func roundTrip(t *testing.T, f File) *restfile.Document {
+	t.Helper()
+	out, err := restwriter.Render(f.Doc, restwriter.Options{})
+	if err != nil {
+		t.Fatalf("render %s: %v", f.Name, err)
+	}
+	doc := parser.Parse(f.Name, []byte(out))
+	if len(doc.Errors) != 0 {
+		t.Fatalf("%s did not parse: %v\n%s", f.Name, doc.Errors, out)
+	}
+	if len(doc.Requests) != len(f.Doc.Requests) {
+		t.Fatalf("%s: %d requests, want %d\n%s", f.Name, len(doc.Requests), len(f.Doc.Requests), out)
+	}
+	return doc
+}
+
+func findRequest(t *testing.T, doc *restfile.Document, name string) *restfile.Request {
+	t.Helper()
+	for _, r := range doc.Requests {
+		if r.Metadata.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("request %q not found", name)
+	return nil
+}
+
+func TestConvertFilesLayout(t *testing.T) {
+	files := Convert(loadCollection(t), LayoutFiles)
+
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "shop-api.http,users.http,orders.http" {
+		t.Fatalf("files = %s", got)
+	}
+
+	root := roundTrip(t, files[0])
+	if len(root.Auth) != 1 || root.Auth[0].Spec.Kind() != restfile.AuthBearer {
+		t.Fatalf("collection auth = %+v, want a file bearer profile", root.Auth)
+	}
+	if len(root.Variables) != 1 || root.Variables[0].Name != "baseUrl" {
+		t.Fatalf("collection variables = %+v", root.Variables)
+	}
+	health := findRequest(t, root, "Health")
+	if !health.Metadata.AuthDisabled {
+		t.Fatalf("noauth request should be written with @auth none")
+	}
+	if len(health.Metadata.Scripts) != 1 || !strings.Contains(health.Metadata.Scripts[0].Body,
+		`tests.assert(response.statusCode === 200, "status is 200")`) {
+		t.Fatalf("collection test script = %+v", health.Metadata.Scripts)
+	}
+
+	users := roundTrip(t, files[1])
+	get := findRequest(t, users, "Get user")
+	if get.URL != "{{baseUrl}}/users/{{id}}?verbose=true" {
+		t.Fatalf("url = %q", get.URL)
+	}
+	if len(get.Variables) != 1 || get.Variables[0].Name != "id" || get.Variables[0].Value != "42" {
+		t.Fatalf("path variables = %+v", get.Variables)
+	}
+	if get.Headers.Get("X-Debug") != "" {
+		t.Fatalf("disabled header was kept")
+	}
+	if got := get.Headers.Get("X-Request-Id"); got != "{{$uuid}}" {
+		t.Fatalf("dynamic variable = %q, want {{$uuid}}", got)
+	}
+	if get.Metadata.Auth == nil || get.Metadata.Auth.Kind() != restfile.AuthBasic {
+		t.Fatalf("folder auth was not applied: %+v", get.Metadata.Auth)
+	}
+	if len(get.Metadata.Scripts) != 3 {
+		t.Fatalf("scripts = %d, want collection test plus pre-request and test", len(get.Metadata.Scripts))
+	}
+	if s := get.Metadata.Scripts[1]; s.Kind != "pre-request" || !strings.Contains(s.Body, `vars.global.set("started"`) {
+		t.Fatalf("pre-request script = %+v", s)
+	}
+
+	create := findRequest(t, users, "Create user")
+	if len(create.Metadata.Tags) != 1 || create.Metadata.Tags[0] != "admin" {
+		t.Fatalf("nested folder tags = %v", create.Metadata.Tags)
+	}
+	if create.Metadata.Auth == nil || create.Metadata.Auth.Kind() != restfile.AuthAPIKey {
+		t.Fatalf("request auth = %+v", create.Metadata.Auth)
+	}
+	if create.Headers.Get("Content-Type") != "application/json" {
+		t.Fatalf("raw json body has no content type")
+	}
+	if !strings.Contains(create.Body.Text, "{{$randomName}}") {
+		t.Fatalf("body = %q", create.Body.Text)
+	}
+
+	upload := findRequest(t, users, "Upload avatar")
+	if !strings.HasPrefix(upload.Headers.Get("Content-Type"), "multipart/form-data; boundary=") {
+		t.Fatalf("multipart content type = %q", upload.Headers.Get("Content-Type"))
+	}
+	if !strings.Contains(upload.Body.Text, "@avatar.png") {
+		t.Fatalf("multipart body lost the file part:\n%s", upload.Body.Text)
+	}
+
+	warn := strings.Join(files[1].Warn, "\n")
+	for _, want := range []string{"awsv4 auth is not supported", "pm.expect"} {
+		if !strings.Contains(warn, want) {
+			t.Fatalf("warnings missing %q:\n%s", want, warn)
+		}
+	}
+
+	orders := roundTrip(t, files[2])
+	search := findRequest(t, orders, "Search")
+	if strings.TrimSpace(search.Body.Text) != "q=red+shoes&user={{userId}}" {
+		t.Fatalf("form body = %q", search.Body.Text)
+	}
+	graph := findRequest(t, orders, "Graph")
+	if !strings.Contains(graph.Body.Text, `"first": 2`) || graph.Headers.Get("Content-Type") != "application/json" {
+		t.Fatalf("graphql body = %q", graph.Body.Text)
+	}
+}
+
+func TestConvertTagsLayout(t *testing.T) {
+	files := Convert(loadCollection(t), LayoutTags)
+	if len(files) != 1 {
+		t.Fatalf("files = %d, want 1", len(files))
+	}
+	doc := roundTrip(t, files[0])
+	if len(doc.Requests) != 6 {
+		t.Fatalf("requests = %d, want 6", len(doc.Requests))
+	}
+	create := findRequest(t, doc, "Create user")
+	if got := strings.Join(create.Metadata.Tags, " "); got != "users admin" {
+		t.Fatalf("tags = %q, want users admin", got)
+	}
+	if got := findRequest(t, doc, "Health").Metadata.Tags; len(got) != 0 {
+		t.Fatalf("root request tags = %v", got)
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	for in, want := range map[string]Layout{"": LayoutFiles, "files": LayoutFiles, "TAGS": LayoutTags} {
+		got, err := ParseLayout(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseLayout(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseLayout("nested"); err == nil {
+		t.Fatalf("ParseLayout accepted an unknown layout")
+	}
+}
+
+func TestParseCollectionRejectsOtherJSON(t *testing.T) {
+	if _, err := ParseCollection([]byte(`{"name": "Staging", "values": []}`)); err == nil {
+		t.Fatalf("an environment export was accepted as a collection")
+	}
+	if _, err := ParseCollection([]byte(`{"info": {"schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}, "item": []}`)); err == nil {
+		t.Fatalf("a v1 collection was accepted")
+	}
+}
@@ -0,0 +1,27 @@
+package postman
+
+import (
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restwriter"
+)
+
+// EnvFileName is the environment file resterm picks up next to .http files.
+const EnvFileName = restwriter.EnvFileName
+
+// MergeEnvironments adds each environment to the env file at path, replacing
+// one of the same name and keeping every other entry as it was. Disabled values
+// are dropped, as Postman does not send them either.
+func MergeEnvironments(path string, envs []*Environment) error {
+	out := make([]restwriter.Environment, 0, len(envs))
+	for _, env := range envs {
+		vals := map[string]any{}
+		for _, v := range env.Values {
+			if key := strings.TrimSpace(v.Key); key != "" && v.enabled() {
+				vals[key] = string(v.Value)
+			}
+		}
+		out = append(out, restwriter.Environment{Name: env.Name, Values: vals})
+	}
+	return restwriter.MergeEnvironments(path, out)
+}
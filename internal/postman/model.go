@@ -0,0 +1,337 @@
+package postman
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Collection is the subset of the v2.1 collection format the importer reads.
+// v2.0 files share the same shape and load through the same types.
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Auth     *Auth      `json:"auth"`
+	Event    []Event    `json:"event"`
+	Variable []Variable `json:"variable"`
+}
+
+type Info struct {
+	Name        string `json:"name"`
+	Schema      string `json:"schema"`
+	Description Text   `json:"description"`
+}
+
+// Item is either a folder, which carries child items, or a request.
+type Item struct {
+	Name        string     `json:"name"`
+	Description Text       `json:"description"`
+	Item        []Item     `json:"item"`
+	Request     *Request   `json:"request"`
+	Auth        *Auth      `json:"auth"`
+	Event       []Event    `json:"event"`
+	Variable    []Variable `json:"variable"`
+}
+
+func (it Item) isFolder() bool {
+	return it.Request == nil && it.Item != nil
+}
+
+type Request struct {
+	Method      string     `json:"method"`
+	Header      []KeyValue `json:"header"`
+	URL         URL        `json:"url"`
+	Body        *Body      `json:"body"`
+	Auth        *Auth      `json:"auth"`
+	Description Text       `json:"description"`
+}
+
+// A request may be stored as a bare URL string, which means a GET.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*r = Request{Method: "GET", URL: URL{Raw: raw}}
+		return nil
+	}
+	type plain Request
+	var out plain
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	*r = Request(out)
+	return nil
+}
+
+// KeyValue covers headers, query parameters, path variables and form fields.
+// Src and ContentType are only set on form-data file parts.
+type KeyValue struct {
+	Key         string `json:"key"`
+	Value       Scalar `json:"value"`
+	Disabled    bool   `json:"disabled"`
+	Type        string `json:"type"`
+	Src         Lines  `json:"src"`
+	ContentType string `json:"contentType"`
+}
+
+type URL struct {
+	Raw      string
+	Protocol string
+	Host     []string
+	Port     string
+	Path     []string
+	Query    []KeyValue
+	Variable []KeyValue
+}
+
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*u = URL{Raw: raw}
+		return nil
+	}
+	var obj struct {
+		Raw      string     `json:"raw"`
+		Protocol string     `json:"protocol"`
+		Host     Segments   `json:"host"`
+		Port     Scalar     `json:"port"`
+		Path     Segments   `json:"path"`
+		Query    []KeyValue `json:"query"`
+		Variable []KeyValue `json:"variable"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*u = URL{
+		Raw:      obj.Raw,
+		Protocol: obj.Protocol,
+		Host:     obj.Host,
+		Port:     string(obj.Port),
+		Path:     obj.Path,
+		Query:    obj.Query,
+		Variable: obj.Variable,
+	}
+	return nil
+}
+
+type Body struct {
+	Mode       string     `json:"mode"`
+	Raw        string     `json:"raw"`
+	URLEncoded []KeyValue `json:"urlencoded"`
+	FormData   []KeyValue `json:"formdata"`
+	File       *struct {
+		Src string `json:"src"`
+	} `json:"file"`
+	GraphQL *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled"`
+}
+
+// Auth keeps the parameters of its own type and drops the rest, since an
+// exported collection often carries leftovers from types it no longer uses.
+type Auth struct {
+	Type   string
+	Params map[string]string
+}
+
+func (a *Auth) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var typ string
+	if v, ok := raw["type"]; ok {
+		if err := json.Unmarshal(v, &typ); err != nil {
+			return fmt.Errorf("auth type: %w", err)
+		}
+	}
+	out := Auth{Type: strings.ToLower(strings.TrimSpace(typ)), Params: map[string]string{}}
+	if v, ok := raw[out.Type]; ok {
+		var params []KeyValue
+		if err := json.Unmarshal(v, &params); err != nil {
+			// v2.0 stores the parameters as a plain object.
+			var obj map[string]Scalar
+			if err2 := json.Unmarshal(v, &obj); err2 != nil {
+				return fmt.Errorf("auth %s: %w", out.Type, err)
+			}
+			for k, val := range obj {
+				out.Params[k] = string(val)
+			}
+		}
+		for _, p := range params {
+			out.Params[p.Key] = string(p.Value)
+		}
+	}
+	*a = out
+	return nil
+}
+
+type Event struct {
+	Listen   string `json:"listen"`
+	Disabled bool   `json:"disabled"`
+	Script   struct {
+		Type string `json:"type"`
+		Exec Lines  `json:"exec"`
+		Src  string `json:"src"`
+	} `json:"script"`
+}
+
+type Variable struct {
+	Key      string `json:"key"`
+	Value    Scalar `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"`
+}
+
+// Environment is an exported Postman environment.
+type Environment struct {
+	Name   string     `json:"name"`
+	Values []EnvValue `json:"values"`
+}
+
+type EnvValue struct {
+	Key     string `json:"key"`
+	Value   Scalar `json:"value"`
+	Enabled *bool  `json:"enabled"`
+	Type    string `json:"type"`
+}
+
+func (v EnvValue) enabled() bool {
+	return v.Enabled == nil || *v.Enabled
+}
+
+// Text is a description, stored either as a string or as {content: ...}.
+type Text string
+
+func (t *Text) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = Text(s)
+		return nil
+	}
+	var obj struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*t = Text(obj.Content)
+	return nil
+}
+
+// Scalar reads a string, number or boolean as its text. Null reads as empty.
+type Scalar string
+
+func (s *Scalar) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*s = ""
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = Scalar(str)
+		return nil
+	}
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err == nil {
+		*s = Scalar(num.String())
+		return nil
+	}
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Scalar(strconv.FormatBool(b))
+		return nil
+	}
+	// Objects and arrays are kept as JSON so nothing is silently lost.
+	*s = Scalar(data)
+	return nil
+}
+
+// Lines is a script body or file source, stored as one string or a list.
+type Lines []string
+
+func (l *Lines) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = strings.Split(s, "\n")
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Segments is a URL host or path, stored as a dotted/slashed string or a list.
+type Segments []string
+
+func (s *Segments) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = []string{str}
+		return nil
+	}
+	var list []Scalar
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		out = append(out, string(v))
+	}
+	*s = out
+	return nil
+}
+
+var errNotCollection = errors.New("postman: not a Postman collection (missing info or item)")
+
+// ParseCollection decodes a collection export.
+func ParseCollection(data []byte) (*Collection, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("postman: parse collection: %w", err)
+	}
+	// Exports through the API wrap the collection in {"collection": {...}}.
+	if inner, ok := probe["collection"]; ok && probe["info"] == nil {
+		data = inner
+		probe = nil
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, fmt.Errorf("postman: parse collection: %w", err)
+		}
+	}
+	if probe["info"] == nil || probe["item"] == nil {
+		return nil, errNotCollection
+	}
+	var c Collection
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("postman: parse collection: %w", err)
+	}
+	if s := c.Info.Schema; s != "" && !strings.Contains(s, "v2.") {
+		return nil, fmt.Errorf("postman: unsupported collection schema %s (want v2.0 or v2.1)", s)
+	}
+	return &c, nil
+}
+
+// ParseEnvironment decodes an environment export.
+func ParseEnvironment(data []byte) (*Environment, error) {
+	var env Environment
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("postman: parse environment: %w", err)
+	}
+	if strings.TrimSpace(env.Name) == "" {
+		return nil, errors.New("postman: environment has no name")
+	}
+	return &env, nil
+}
@@ -0,0 +1,85 @@
+package postman
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// scriptRewrite maps one Postman sandbox call onto the resterm scripting API.
+// Order matters: longer receivers come before the prefixes they share.
+type scriptRewrite struct {
+	from *regexp.Regexp
+	to   string
+}
+
+func rewrite(pattern, to string) scriptRewrite {
+	return scriptRewrite{from: regexp.MustCompile(pattern), to: to}
+}
+
+var scriptRewrites = []scriptRewrite{
+	rewrite(`\bpm\.test\(`, "client.test("),
+	rewrite(`\bpm\.response\.to\.have\.status\(\s*(\d+)\s*\)`,
+		`tests.assert(response.statusCode === $1, "status is $1")`),
+	rewrite(`\bpm\.response\.json\(\)`, "response.json()"),
+	rewrite(`\bpm\.response\.text\(\)`, "response.body()"),
+	rewrite(`\bpm\.response\.code\b`, "response.statusCode"),
+	rewrite(`\bpm\.response\.status\b`, "response.status"),
+	// Postman reports milliseconds; response.duration is seconds.
+	rewrite(`\bpm\.response\.responseTime\b`, "(response.duration * 1000)"),
+	rewrite(`\bpm\.response\.headers\.get\(`, "response.headers.get("),
+	rewrite(`\bpm\.response\.headers\.has\(`, "response.headers.has("),
+	rewrite(`\bpm\.(?:environment|globals|collectionVariables)\.set\(`, "vars.global.set("),
+	rewrite(`\bpm\.(?:environment|globals|collectionVariables)\.unset\(`, "vars.global.delete("),
+	rewrite(`\bpm\.globals\.get\(`, "vars.global.get("),
+	rewrite(`\bpm\.globals\.has\(`, "vars.global.has("),
+	rewrite(`\bpm\.(?:environment|collectionVariables|variables)\.get\(`, "vars.get("),
+	rewrite(`\bpm\.(?:environment|collectionVariables|variables)\.has\(`, "vars.has("),
+	rewrite(`\bpm\.variables\.set\(`, "vars.set("),
+	rewrite(`\bpm\.request\.headers\.remove\(`, "request.removeHeader("),
+	rewrite(`\bpostman\.setEnvironmentVariable\(`, "vars.global.set("),
+	rewrite(`\bpostman\.setGlobalVariable\(`, "vars.global.set("),
+	rewrite(`\bpostman\.getEnvironmentVariable\(`, "vars.get("),
+	rewrite(`\bpostman\.getGlobalVariable\(`, "vars.global.get("),
+}
+
+var leftoverAPI = regexp.MustCompile(`\b(?:pm|postman)(?:\.[A-Za-z_$][\w$]*)+`)
+
+// convertScript rewrites a Postman script and reports the sandbox APIs it
+// could not translate. Those are listed in a comment at the top of the block
+// so the script still loads and the gap is visible where it matters.
+func convertScript(lines []string) (string, []string) {
+	body := strings.TrimRight(strings.Join(lines, "\n"), "\n\t ")
+	if strings.TrimSpace(body) == "" {
+		return "", nil
+	}
+	for _, r := range scriptRewrites {
+		body = r.from.ReplaceAllString(body, r.to)
+	}
+
+	seen := map[string]bool{}
+	var left []string
+	for _, m := range leftoverAPI.FindAllString(body, -1) {
+		m = apiName(m)
+		if !seen[m] {
+			seen[m] = true
+			left = append(left, m)
+		}
+	}
+	if len(left) == 0 {
+		return body, nil
+	}
+	sort.Strings(left)
+	note := "// resterm: unsupported Postman APIs: " + strings.Join(left, ", ")
+	return note + "\n" + body, left
+}
+
+// apiName trims a call chain to the object that owns it, so pm.expect(a).to.eql
+// and pm.expect(b).to.be.ok are reported once as pm.expect.
+func apiName(chain string) string {
+	parts := strings.Split(chain, ".")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,55 @@
+package postman
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertScriptRewritesSandboxCalls(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`pm.test("a", fn)`, `client.test("a", fn)`},
+		{`pm.response.to.have.status(201);`, `tests.assert(response.statusCode === 201, "status is 201");`},
+		{`var b = pm.response.json();`, `var b = response.json();`},
+		{`pm.response.text()`, `response.body()`},
+		{`pm.response.code === 200`, `response.statusCode === 200`},
+		{`pm.response.responseTime < 200`, `(response.duration * 1000) < 200`},
+		{`pm.response.headers.get("ETag")`, `response.headers.get("ETag")`},
+		{`pm.environment.set("t", 1)`, `vars.global.set("t", 1)`},
+		{`pm.collectionVariables.set("t", 1)`, `vars.global.set("t", 1)`},
+		{`pm.globals.unset("t")`, `vars.global.delete("t")`},
+		{`pm.globals.get("t")`, `vars.global.get("t")`},
+		{`pm.environment.get("t")`, `vars.get("t")`},
+		{`pm.variables.set("t", 1)`, `vars.set("t", 1)`},
+		{`postman.setEnvironmentVariable("t", 1)`, `vars.global.set("t", 1)`},
+	}
+	for _, tt := range tests {
+		got, left := convertScript([]string{tt.in})
+		if got != tt.want || len(left) != 0 {
+			t.Fatalf("convertScript(%q) = %q, %v; want %q", tt.in, got, left, tt.want)
+		}
+	}
+}
+
+func TestConvertScriptFlagsUnsupportedAPIs(t *testing.T) {
+	got, left := convertScript([]string{
+		`pm.expect(1).to.eql(1);`,
+		`pm.expect(2).to.be.ok;`,
+		`pm.sendRequest("https://x", cb);`,
+	})
+	if strings.Join(left, ",") != "pm.expect,pm.sendRequest" {
+		t.Fatalf("unsupported = %v", left)
+	}
+	first, _, _ := strings.Cut(got, "\n")
+	if first != "// resterm: unsupported Postman APIs: pm.expect, pm.sendRequest" {
+		t.Fatalf("first line = %q", first)
+	}
+}
+
+func TestConvertScriptSkipsEmptyBodies(t *testing.T) {
+	if got, _ := convertScript([]string{"", "  "}); got != "" {
+		t.Fatalf("empty script = %q", got)
+	}
+}
@@ -0,0 +1,124 @@
+package postman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+const errWriterNotConfigured = "postman: writer not configured"
+
+type DocumentWriter interface {
+	WriteDocument(ctx context.Context, doc *restfile.Document, dst string, opts WriterOptions) error
+}
+
+type WriterOptions struct {
+	OverwriteExisting bool
+	HeaderComment     string
+}
+
+type Options struct {
+	Layout Layout
+	// Environments are paths to Postman environment exports.
+	Environments []string
+	Write        WriterOptions
+}
+
+// Result lists what an import wrote, in the order it was written.
+type Result struct {
+	Files   []string
+	EnvFile string
+}
+
+type Service struct {
+	Writer DocumentWriter
+}
+
+// Import converts the collection at src. With LayoutFiles dst is a directory
+// that receives one file per top-level folder; with LayoutTags it is the path
+// of the single .http file. Environments land in resterm.env.json beside the
+// generated files.
+func (s *Service) Import(ctx context.Context, src, dst string, opts Options) (Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if s.Writer == nil {
+		return Result{}, errors.New(errWriterNotConfigured)
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return Result{}, fmt.Errorf("postman: read collection: %w", err)
+	}
+	coll, err := ParseCollection(data)
+	if err != nil {
+		return Result{}, err
+	}
+	envs := make([]*Environment, 0, len(opts.Environments))
+	for _, p := range opts.Environments {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return Result{}, fmt.Errorf("postman: read environment: %w", err)
+		}
+		env, err := ParseEnvironment(data)
+		if err != nil {
+			return Result{}, fmt.Errorf("%w (%s)", err, p)
+		}
+		envs = append(envs, env)
+	}
+
+	files := Convert(coll, opts.Layout)
+	if len(files) == 0 {
+		return Result{}, errors.New("postman: collection has no requests")
+	}
+
+	dir := dst
+	if opts.Layout == LayoutTags {
+		dir = filepath.Dir(dst)
+	}
+	var res Result
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		out := filepath.Join(dir, f.Name)
+		if opts.Layout == LayoutTags {
+			out = dst
+		}
+		w := opts.Write
+		w.HeaderComment = buildHeader(w.HeaderComment, src, f.Warn)
+		if err := s.Writer.WriteDocument(ctx, f.Doc, out, w); err != nil {
+			return res, err
+		}
+		res.Files = append(res.Files, out)
+	}
+
+	if len(envs) > 0 {
+		res.EnvFile = filepath.Join(dir, EnvFileName)
+		if err := MergeEnvironments(res.EnvFile, envs); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func buildHeader(base, src string, warn []string) string {
+	var lines []string
+	for line := range strings.SplitSeq(base, "\n") {
+		if t := strings.TrimSpace(line); t != "" {
+			lines = append(lines, t)
+		}
+	}
+	lines = append(lines, "Source: Postman collection "+filepath.Base(src))
+	for _, w := range warn {
+		if t := strings.TrimSpace(w); t != "" {
+			lines = append(lines, "Warning: "+t)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
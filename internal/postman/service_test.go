@@ -0,0 +1,89 @@
+package postman
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportWritesFilesAndEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, EnvFileName)
+	keep := `{"local": {"baseUrl": "http://localhost"}, "Staging": {"stale": "1"}}`
+	if err := os.WriteFile(envPath, []byte(keep), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := Service{Writer: NewFileWriter()}
+	res, err := svc.Import(context.Background(), "testdata/collection.json", dir, Options{
+		Layout:       LayoutFiles,
+		Environments: []string{"testdata/env.json"},
+		Write:        WriterOptions{HeaderComment: "Generated by tests", OverwriteExisting: true},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(res.Files) != 3 || res.EnvFile != envPath {
+		t.Fatalf("result = %+v", res)
+	}
+
+	users, err := os.ReadFile(filepath.Join(dir, "users.http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Generated by tests",
+		"# Source: Postman collection collection.json",
+		"# Warning: request \"Upload avatar\": awsv4 auth is not supported",
+		"# @auth file bearer {{token}}",
+		"# @script test",
+	} {
+		if !strings.Contains(string(users), want) {
+			t.Fatalf("users.http missing %q:\n%s", want, users)
+		}
+	}
+
+	data, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envs map[string]map[string]string
+	if err := json.Unmarshal(data, &envs); err != nil {
+		t.Fatalf("env file: %v", err)
+	}
+	if envs["local"]["baseUrl"] != "http://localhost" {
+		t.Fatalf("existing environment was not kept: %v", envs)
+	}
+	staging := envs["Staging"]
+	if staging["baseUrl"] != "https://staging.example.com" || staging["token"] != "abc" {
+		t.Fatalf("staging = %v", staging)
+	}
+	if _, ok := staging["old"]; ok {
+		t.Fatalf("disabled value was imported")
+	}
+	if _, ok := staging["stale"]; ok {
+		t.Fatalf("environment of the same name was merged instead of replaced")
+	}
+}
+
+func TestImportTagsLayoutWritesOneFile(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "shop.http")
+	svc := Service{Writer: NewFileWriter()}
+	res, err := svc.Import(context.Background(), "testdata/collection.json", dst, Options{
+		Layout:       LayoutTags,
+		Environments: []string{"testdata/env.json"},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(res.Files) != 1 || res.Files[0] != dst {
+		t.Fatalf("files = %v", res.Files)
+	}
+	if res.EnvFile != filepath.Join(dir, EnvFileName) {
+		t.Fatalf("env file = %q", res.EnvFile)
+	}
+}
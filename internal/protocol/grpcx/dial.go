@@ -13,7 +13,7 @@ import (
 )
 
 // passthroughScheme forces gRPC to skip name resolution and dial the target
-// required when traffic is routed through an SSH/k8s tunnel dialer.
+// required when traffic is routed through an SSH/k8s tunnel or proxy dialer.
 const passthroughScheme = "passthrough:///"
 
 func buildDial(gr *restfile.GRPCRequest, opt Options) (string, []grpc.DialOption, error) {
@@ -27,6 +27,19 @@ func buildDial(gr *restfile.GRPCRequest, opt Options) (string, []grpc.DialOption
 		)
 	}
 
+	proxyOn := opt.Proxy.Explicit()
+	if proxyOn && (sshOn || k8sOn) {
+		return "", nil, diag.New(
+			diag.ClassRoute,
+			"proxy cannot be combined with ssh or k8s tunneling",
+			grpcComponent,
+		)
+	}
+
+	// Credentials for an environment proxy need our dialer as well; gRPC's
+	// own HTTPS_PROXY support would connect without them.
+	proxyOn = proxyOn || (opt.Proxy.User != "" && !sshOn && !k8sOn)
+
 	var dialOpts []grpc.DialOption
 	if shouldUsePlaintext(gr, opt) {
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -48,11 +61,14 @@ func buildDial(gr *restfile.GRPCRequest, opt Options) (string, []grpc.DialOption
 		cfg := *plan.Config
 		dialOpts = append(dialOpts, tunnel.GRPCDialOption(tunnel.DialerFor(plan.Manager, cfg)))
 	}
+	if proxyOn {
+		dialOpts = append(dialOpts, tunnel.GRPCDialOption(opt.Proxy.DialContext))
+	}
 	if gr.Authority != "" {
 		dialOpts = append(dialOpts, grpc.WithAuthority(gr.Authority))
 	}
 
-	return dialTarget(gr.Target, sshOn || k8sOn), dialOpts, nil
+	return dialTarget(gr.Target, sshOn || k8sOn || proxyOn), dialOpts, nil
 }
 
 func dialGRPC(target string, opts []grpc.DialOption) (Conn, error) {
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/unkn0wn-root/resterm/internal/k8s"
+	"github.com/unkn0wn-root/resterm/internal/netproxy"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/ssh"
 	"github.com/unkn0wn-root/resterm/internal/tlsconfig"
@@ -31,4 +32,7 @@ type Options struct {
 	RootMode      tlsconfig.RootMode
 	SSH           *ssh.Plan
 	K8s           *k8s.Plan
+	// Proxy comes from the HTTP proxy settings. Without an explicit proxy
+	// grpc-go keeps reading HTTPS_PROXY itself.
+	Proxy netproxy.Config
 }
@@ -0,0 +1,112 @@
+package grpcx
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/netproxy"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/ssh"
+	"google.golang.org/grpc/codes"
+)
+
+// startConnectProxy runs a CONNECT proxy and reports the target and
+// Proxy-Authorization of each tunnel it opens.
+func startConnectProxy(t *testing.T) (string, <-chan [2]string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	seen := make(chan [2]string, 4)
+	go func() {
+		for {
+			c, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = c.Close() }()
+				req, err := http.ReadRequest(bufio.NewReader(c))
+				if err != nil || req.Method != http.MethodConnect {
+					return
+				}
+				seen <- [2]string{req.Host, req.Header.Get("Proxy-Authorization")}
+				up, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					_, _ = io.WriteString(c, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+					return
+				}
+				defer func() { _ = up.Close() }()
+				_, _ = io.WriteString(c, "HTTP/1.1 200 Connection established\r\n\r\n")
+				go func() { _, _ = io.Copy(up, c) }()
+				_, _ = io.Copy(c, up)
+			}()
+		}
+	}()
+	return lis.Addr().String(), seen
+}
+
+func TestExecuteDialsThroughProxy(t *testing.T) {
+	addr := startTestServer(t)
+	proxyAddr, seen := startConnectProxy(t)
+
+	req := &restfile.Request{GRPC: baseStreamReq(addr, "UnimplementedCall")}
+	opts := Options{
+		DefaultPlaintext: restfile.OptOf(true),
+		DialTimeout:      5 * time.Second,
+		Proxy: netproxy.Config{
+			Rules:    []netproxy.Rule{{Pattern: "*", Proxy: "http://" + proxyAddr}},
+			User:     "ops",
+			Password: "pw",
+		},
+	}
+	resp, err := NewClient().Execute(context.Background(), req, opts, nil)
+	if resp == nil || resp.StatusCode != codes.Unimplemented {
+		t.Fatalf("expected an Unimplemented response through the proxy, got %v / %v", resp, err)
+	}
+	select {
+	case got := <-seen:
+		if got[0] != addr || got[1] != "Basic b3BzOnB3" {
+			t.Fatalf("proxy saw CONNECT %q with auth %q", got[0], got[1])
+		}
+	default:
+		t.Fatal("expected the call to go through the proxy")
+	}
+}
+
+func TestBuildDialRejectsProxyWithTunnel(t *testing.T) {
+	gr := &restfile.GRPCRequest{Target: "svc:443"}
+	opt := Options{
+		Proxy: netproxy.Config{URL: "http://proxy:3128"},
+		SSH: &ssh.Plan{
+			Manager: &ssh.Manager{},
+			Config:  &ssh.Config{Host: "jump", Port: 22, User: "ops"},
+		},
+	}
+	if _, _, err := buildDial(gr, opt); err == nil {
+		t.Fatal("expected proxy and ssh to be rejected together")
+	}
+}
+
+// Proxy credentials without a proxy URL are for the environment's proxy,
+// which only the resterm dialer authenticates to.
+func TestBuildDialRoutesCredentialsOnlyProxy(t *testing.T) {
+	gr := &restfile.GRPCRequest{Target: "svc:443"}
+	target, _, err := buildDial(gr, Options{Proxy: netproxy.Config{User: "ops", Password: "pw"}})
+	if err != nil {
+		t.Fatalf("build dial: %v", err)
+	}
+	if target != passthroughScheme+"svc:443" {
+		t.Fatalf("target = %q, want the proxy dialer", target)
+	}
+	if target, _, _ = buildDial(gr, Options{}); target != "svc:443" {
+		t.Fatalf("target without proxy settings = %q", target)
+	}
+}
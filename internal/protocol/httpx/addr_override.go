@@ -0,0 +1,148 @@
+package httpx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// AddrOverride rewrites the address a connection dials, the way curl's
+// --resolve and --connect-to do. An empty Host or Port matches any, and an
+// empty ToHost or ToPort keeps the dialed one. The URL is left alone, so the
+// Host header and TLS server name still carry the original host.
+type AddrOverride struct {
+	Host   string
+	Port   string
+	ToHost string
+	ToPort string
+}
+
+func (o AddrOverride) matches(host, port string) bool {
+	return (o.Host == "" || strings.EqualFold(o.Host, host)) && (o.Port == "" || o.Port == port)
+}
+
+// parseResolve reads http-resolve rules: host:port=address, separated by
+// commas or spaces. A port of * matches any port.
+func parseResolve(val string) ([]AddrOverride, bool) {
+	var out []AddrOverride
+	for _, f := range overrideFields(val) {
+		from, to, ok := strings.Cut(f, "=")
+		if !ok {
+			return nil, false
+		}
+		host, port, ok := overrideHostPort(from)
+		if !ok || host == "" {
+			return nil, false
+		}
+		ip := strings.TrimSuffix(strings.TrimPrefix(to, "["), "]")
+		if net.ParseIP(ip) == nil {
+			return nil, false
+		}
+		out = append(out, AddrOverride{Host: host, Port: port, ToHost: ip})
+	}
+	return out, len(out) > 0
+}
+
+// parseConnectTo reads http-connect-to rules: host:port=host2:port2. Either
+// side may leave out its host or port, so ":443=lb.internal:" sends every
+// connection on 443 to lb.internal on the same port.
+func parseConnectTo(val string) ([]AddrOverride, bool) {
+	var out []AddrOverride
+	for _, f := range overrideFields(val) {
+		from, to, ok := strings.Cut(f, "=")
+		if !ok {
+			return nil, false
+		}
+		host, port, ok := overrideHostPort(from)
+		if !ok {
+			return nil, false
+		}
+		toHost, toPort, ok := overrideHostPort(to)
+		if !ok || toHost == "" && toPort == "" {
+			return nil, false
+		}
+		out = append(out, AddrOverride{Host: host, Port: port, ToHost: toHost, ToPort: toPort})
+	}
+	return out, len(out) > 0
+}
+
+func overrideFields(val string) []string {
+	return strings.FieldsFunc(val, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+func overrideHostPort(s string) (string, string, bool) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return "", "", false
+	}
+	if host == "*" {
+		host = ""
+	}
+	if port == "*" {
+		port = ""
+	}
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+			return "", "", false
+		}
+	}
+	return host, port, true
+}
+
+// overrideAddr applies the first matching connect-to rule and then the first
+// resolve rule for the host it ended up on, as curl does. It reports whether
+// the result skips a lookup the original address needed.
+func overrideAddr(addr string, connectTo, resolve []AddrOverride) (string, bool) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, false
+	}
+	orig := host
+	for _, o := range connectTo {
+		if o.matches(host, port) {
+			if o.ToHost != "" {
+				host = o.ToHost
+			}
+			if o.ToPort != "" {
+				port = o.ToPort
+			}
+			break
+		}
+	}
+	for _, o := range resolve {
+		if o.matches(host, port) {
+			host = o.ToHost
+			break
+		}
+	}
+	skipped := net.ParseIP(orig) == nil && net.ParseIP(host) != nil
+	return net.JoinHostPort(host, port), skipped
+}
+
+// applyAddrOverrides wraps whatever dialer the transport ended up with, so
+// the rules also hold through ssh and k8s tunnels. A unix socket ignores the
+// address, so there is nothing to override.
+func applyAddrOverrides(transport *http.Transport, opts Options) {
+	if len(opts.Resolve) == 0 && len(opts.ConnectTo) == 0 {
+		return
+	}
+	if transport.DialContext == nil || unixSocketPath(opts) != "" {
+		return
+	}
+	next := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		to, skipped := overrideAddr(addr, opts.ConnectTo, opts.Resolve)
+		if skipped {
+			if s := traceSessionFrom(ctx); s != nil {
+				host, _, _ := net.SplitHostPort(to)
+				s.onDNSOverride(host)
+			}
+		}
+		return next(ctx, network, to)
+	}
+}
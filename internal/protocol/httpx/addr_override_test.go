@@ -0,0 +1,141 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/nettrace"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+func TestParseAddrOverrides(t *testing.T) {
+	rules, ok := parseResolve("api.example.com:443=127.0.0.1, web.example.com:*=[::1]")
+	if !ok {
+		t.Fatal("expected resolve rules to parse")
+	}
+	want := []AddrOverride{
+		{Host: "api.example.com", Port: "443", ToHost: "127.0.0.1"},
+		{Host: "web.example.com", ToHost: "::1"},
+	}
+	if !slices.Equal(rules, want) {
+		t.Fatalf("resolve rules = %+v", rules)
+	}
+
+	rules, ok = parseConnectTo("api.example.com:443=lb.internal:8443 :80=:8080")
+	if !ok {
+		t.Fatal("expected connect-to rules to parse")
+	}
+	want = []AddrOverride{
+		{Host: "api.example.com", Port: "443", ToHost: "lb.internal", ToPort: "8443"},
+		{Port: "80", ToPort: "8080"},
+	}
+	if !slices.Equal(rules, want) {
+		t.Fatalf("connect-to rules = %+v", rules)
+	}
+
+	for _, bad := range []string{"true", "api.example.com=127.0.0.1", "api:443=lb", ":443=127.0.0.1", "api:99999=::1"} {
+		if _, ok := parseResolve(bad); ok {
+			t.Fatalf("expected resolve %q to be rejected", bad)
+		}
+	}
+	for _, bad := range []string{"true", "api:443", "api:443=:", "api:443=lb:http"} {
+		if _, ok := parseConnectTo(bad); ok {
+			t.Fatalf("expected connect-to %q to be rejected", bad)
+		}
+	}
+}
+
+func TestOverrideAddr(t *testing.T) {
+	connectTo := []AddrOverride{{Host: "api.example.com", Port: "443", ToHost: "lb.internal"}}
+	resolve := []AddrOverride{
+		{Host: "lb.internal", ToHost: "10.0.0.7"},
+		{Host: "db.example.com", Port: "5432", ToHost: "10.0.0.9"},
+	}
+	tests := []struct {
+		addr    string
+		want    string
+		skipped bool
+	}{
+		{addr: "api.example.com:443", want: "10.0.0.7:443", skipped: true},
+		{addr: "API.example.com:443", want: "10.0.0.7:443", skipped: true},
+		{addr: "api.example.com:80", want: "api.example.com:80"},
+		{addr: "db.example.com:5432", want: "10.0.0.9:5432", skipped: true},
+		{addr: "10.0.0.1:443", want: "10.0.0.1:443"},
+	}
+	for _, tc := range tests {
+		got, skipped := overrideAddr(tc.addr, connectTo, resolve)
+		if got != tc.want || skipped != tc.skipped {
+			t.Fatalf("overrideAddr(%q) = %q, %v; want %q, %v", tc.addr, got, skipped, tc.want, tc.skipped)
+		}
+	}
+}
+
+func TestExecuteResolveKeepsHostAndSNI(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s %s", r.Host, r.TLS.ServerName)
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse server url: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(u.Host)
+
+	req := &restfile.Request{
+		Method:   http.MethodGet,
+		URL:      "https://example.com:" + port + "/",
+		Settings: map[string]string{"http-resolve": "example.com:" + port + "=127.0.0.1"},
+	}
+	opts := Options{InsecureSkipVerify: true, Trace: true}
+	resp, err := NewClient(nil).Execute(context.Background(), req, vars.NewResolver(), opts)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got, want := string(resp.Body), "example.com:"+port+" example.com"; got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+
+	if resp.Timeline == nil {
+		t.Fatal("expected a trace timeline")
+	}
+	i := slices.IndexFunc(resp.Timeline.Phases, func(p nettrace.Phase) bool {
+		return p.Kind == nettrace.PhaseDNS
+	})
+	if i < 0 {
+		t.Fatalf("expected a dns phase, got %+v", resp.Timeline.Phases)
+	}
+	if meta := resp.Timeline.Phases[i].Meta; !meta.Override || meta.Addr != "127.0.0.1" {
+		t.Fatalf("dns phase meta = %+v", meta)
+	}
+}
+
+func TestExecuteConnectToKeepsHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.Host)
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse server url: %v", err)
+	}
+
+	req := &restfile.Request{
+		Method:   http.MethodGet,
+		URL:      "http://api.example.test/health",
+		Settings: map[string]string{"http-connect-to": "api.example.test:80=" + u.Host},
+	}
+	resp, err := NewClient(nil).Execute(context.Background(), req, vars.NewResolver(), Options{})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := string(resp.Body); got != "api.example.test" {
+		t.Fatalf("body = %q", got)
+	}
+}
@@ -41,24 +41,7 @@ func AuthValues(
 
 	kind := auth.Kind()
 	expandResult := func(param string) (vars.Expansion, error) {
-		value := auth.Params[param]
-		if value == "" || resolver == nil {
-			return vars.Expansion{Value: value}, nil
-		}
-		out, err := resolver.ExpandTemplatesResult(value)
-		if err != nil {
-			op := fmt.Sprintf("expand %s auth %s", kind, param)
-			if at := auth.Origin(); at != "" {
-				op += " (" + at + ")"
-			}
-			return vars.Expansion{}, diag.WrapAs(
-				diag.ClassAuth,
-				err,
-				op,
-				diag.WithComponent(component),
-			)
-		}
-		return out, nil
+		return expandAuthParam(auth, resolver, param, component)
 	}
 	expand := func(param string) (string, error) {
 		out, err := expandResult(param)
@@ -136,6 +119,18 @@ func AuthValues(
 			return nil, diag.New(diag.ClassAuth, msg, diag.WithComponent(component))
 		}
 
+	case restfile.AuthDigest:
+		// The header depends on the server's challenge, so the HTTP client
+		// computes it while sending. gRPC has no 401 round trip to answer.
+		if component != diag.ComponentHTTP {
+			return nil, diag.New(
+				diag.ClassAuth,
+				"digest auth is only supported for http requests",
+				diag.WithComponent(component),
+			)
+		}
+		return nil, nil
+
 	case restfile.AuthHeader:
 		name, err := expand(authParamHeader)
 		if err != nil {
@@ -152,3 +147,29 @@ func AuthValues(
 	}
 	return nil, nil
 }
+
+func expandAuthParam(
+	auth *restfile.AuthSpec,
+	resolver *vars.Resolver,
+	param string,
+	component diag.Component,
+) (vars.Expansion, error) {
+	value := auth.Params[param]
+	if value == "" || resolver == nil {
+		return vars.Expansion{Value: value}, nil
+	}
+	out, err := resolver.ExpandTemplatesResult(value)
+	if err != nil {
+		op := fmt.Sprintf("expand %s auth %s", auth.Kind(), param)
+		if at := auth.Origin(); at != "" {
+			op += " (" + at + ")"
+		}
+		return vars.Expansion{}, diag.WrapAs(
+			diag.ClassAuth,
+			err,
+			op,
+			diag.WithComponent(component),
+		)
+	}
+	return out, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/filelookup"
 	"github.com/unkn0wn-root/resterm/internal/http/version"
 	"github.com/unkn0wn-root/resterm/internal/k8s"
+	"github.com/unkn0wn-root/resterm/internal/netproxy"
 	"github.com/unkn0wn-root/resterm/internal/nettrace"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/ssh"
@@ -24,19 +25,42 @@ type Options struct {
 	FollowRedirects    bool
 	InsecureSkipVerify bool
 	ProxyURL           string
-	RootCAs            []string
-	RootMode           tlsconfig.RootMode
-	ClientCert         string
-	ClientKey          string
-	HTTPVersion        version.HTTP
-	BaseDir            string
-	FallbackBaseDirs   []string
-	NoFallback         bool
-	Trace              bool
-	TraceBudget        *nettrace.Budget
-	SSH                *ssh.Plan
-	K8s                *k8s.Plan
-	CookieJar          http.CookieJar
+	// ProxyRules pick a proxy per host ahead of ProxyURL. ProxyUser and
+	// ProxyPassword go to any proxy without credentials in its URL.
+	ProxyRules       []netproxy.Rule
+	ProxyUser        string
+	ProxyPassword    string
+	RootCAs          []string
+	RootMode         tlsconfig.RootMode
+	ClientCert       string
+	ClientKey        string
+	HTTPVersion      version.HTTP
+	BaseDir          string
+	FallbackBaseDirs []string
+	NoFallback       bool
+	Trace            bool
+	TraceBudget      *nettrace.Budget
+	SSH              *ssh.Plan
+	K8s              *k8s.Plan
+	// UnixSocket dials every connection to this socket instead of the URL's
+	// host. A relative path resolves against BaseDir.
+	UnixSocket string
+	// Resolve pins a host to an address without a lookup and ConnectTo sends
+	// a host:port somewhere else. Both only change where the dial goes.
+	Resolve   []AddrOverride
+	ConnectTo []AddrOverride
+	CookieJar http.CookieJar
+}
+
+// ProxyConfig is the proxy setup the options describe, for transports that
+// dial through netproxy themselves.
+func (o Options) ProxyConfig() netproxy.Config {
+	return netproxy.Config{
+		URL:      o.ProxyURL,
+		Rules:    o.ProxyRules,
+		User:     o.ProxyUser,
+		Password: o.ProxyPassword,
+	}
 }
 
 type HTTPClientFactory func(Options) (*http.Client, error)
@@ -54,6 +78,8 @@ type Client struct {
 	httpFactory HTTPClientFactory
 	wsDial      WebSocketDialer
 	telemetry   telemetry.Instrumenter
+	digest      *digestCache
+	altSvc      *altSvcCache
 }
 
 func (c *Client) resolveHTTPFactory() HTTPClientFactory {
@@ -78,6 +104,13 @@ func (c *Client) httpClient(opts Options) (*http.Client, error) {
 	return factory(opts)
 }
 
+// HTTPClient builds a client that dials, proxies and verifies TLS the way
+// Execute would for opts. Callers outside a request, such as a JWKS fetch,
+// use it so they reach what the request reached.
+func (c *Client) HTTPClient(opts Options) (*http.Client, error) {
+	return c.httpClient(opts)
+}
+
 func (c *Client) streamClient(opts Options) (*http.Client, error) {
 	client, err := c.httpClient(opts)
 	if err != nil {
@@ -107,6 +140,8 @@ func NewClientWithOptions(opts ...ClientOption) *Client {
 	if c.telemetry == nil {
 		c.telemetry = telemetry.Noop()
 	}
+	c.digest = newDigestCache()
+	c.altSvc = newAltSvcCache()
 	return c
 }
 
@@ -142,7 +177,9 @@ func WithTelemetry(instr telemetry.Instrumenter) ClientOption {
 }
 
 // Clone returns a snapshot of c's client configuration.
-// Later field updates on c do not affect the clone.
+// Later field updates on c do not affect the clone. The digest nonce cache is
+// shared so a clone does not have to answer a fresh 401, and the Alt-Svc cache
+// so it does not have to rediscover HTTP/3.
 func (c *Client) Clone() *Client {
 	if c == nil {
 		return nil
@@ -152,6 +189,8 @@ func (c *Client) Clone() *Client {
 		httpFactory: c.httpFactory,
 		wsDial:      c.wsDial,
 		telemetry:   c.telemetry,
+		digest:      c.digest,
+		altSvc:      c.altSvc,
 	}
 }
 
@@ -191,6 +230,9 @@ func (c *Client) Execute(
 	if err != nil {
 		return nil, err
 	}
+	if err := c.applyDigest(client, httpReq, req.Metadata.Auth, resolver); err != nil {
+		return nil, err
+	}
 
 	proxy := proxyForRequest(httpReq, effectiveOpts, client)
 
@@ -0,0 +1,484 @@
+package httpx
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+// Digest auth (RFC 7616) cannot be built before the request is sent because
+// the hash covers a nonce the server hands out in its 401. The transport below
+// answers that challenge and replays the request. Challenges are cached per
+// origin, so later requests to the same host authenticate on the first try
+// and only see a 401 again once the server rotates its nonce.
+
+const (
+	wwwAuthenticateHeader = "WWW-Authenticate"
+	digestScheme          = "Digest"
+
+	digestQopAuth    = "auth"
+	digestQopAuthInt = "auth-int"
+)
+
+var errDigestAlgorithm = errors.New("unsupported digest algorithm")
+
+// Server preference is ignored in favour of the strongest algorithm offered,
+// so a server advertising SHA-256 and MD5 is answered with SHA-256.
+var digestAlgorithms = []string{"SHA-512-256", "SHA-256", "MD5"}
+
+type digestCreds struct {
+	user string
+	pass string
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       []string
+	stale     bool
+	userhash  bool
+}
+
+type digestState struct {
+	ch digestChallenge
+	nc uint32
+}
+
+// digestCache is shared by clones of a Client so the nonce survives across
+// requests. Keys are scheme://host, matching the protection space a browser
+// would reuse.
+type digestCache struct {
+	mu    sync.Mutex
+	hosts map[string]*digestState
+}
+
+func newDigestCache() *digestCache {
+	return &digestCache{hosts: make(map[string]*digestState)}
+}
+
+func digestKey(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
+
+func (c *digestCache) store(key string, ch digestChallenge) {
+	if c == nil || key == "" {
+		return
+	}
+	c.mu.Lock()
+	c.hosts[key] = &digestState{ch: ch}
+	c.mu.Unlock()
+}
+
+func (c *digestCache) forget(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	delete(c.hosts, key)
+	c.mu.Unlock()
+}
+
+// next returns the cached challenge with the nonce count already advanced, so
+// two concurrent requests never send the same nc for one nonce.
+func (c *digestCache) next(key string) (digestChallenge, uint32, bool) {
+	if c == nil || key == "" {
+		return digestChallenge{}, 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st, ok := c.hosts[key]
+	if !ok {
+		return digestChallenge{}, 0, false
+	}
+	st.nc++
+	return st.ch, st.nc, true
+}
+
+func digestCredentials(
+	auth *restfile.AuthSpec,
+	resolver *vars.Resolver,
+) (digestCreds, error) {
+	user, err := expandAuthParam(auth, resolver, authParamUsername, diag.ComponentHTTP)
+	if err != nil {
+		return digestCreds{}, err
+	}
+	pass, err := expandAuthParam(auth, resolver, authParamPassword, diag.ComponentHTTP)
+	if err != nil {
+		return digestCreds{}, err
+	}
+	return digestCreds{user: user.Value, pass: pass.Value}, nil
+}
+
+// applyDigest wraps the client transport when the request uses digest auth.
+// An explicit Authorization header wins, as it does for every other form.
+func (c *Client) applyDigest(
+	client *http.Client,
+	httpReq *http.Request,
+	auth *restfile.AuthSpec,
+	resolver *vars.Resolver,
+) error {
+	if client == nil || httpReq == nil || auth.Kind() != restfile.AuthDigest {
+		return nil
+	}
+	if httpReq.Header.Get(authorizationHeader) != "" {
+		return nil
+	}
+	creds, err := digestCredentials(auth, resolver)
+	if err != nil {
+		return err
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &digestTransport{
+		base:  base,
+		cache: c.digest,
+		creds: creds,
+		host:  digestKey(httpReq.URL),
+	}
+	return nil
+}
+
+// digestTransport only answers challenges from the host the request was
+// addressed to. A redirect to another origin is sent without credentials.
+type digestTransport struct {
+	base  http.RoundTripper
+	cache *digestCache
+	creds digestCreds
+	host  string
+}
+
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := digestKey(req.URL)
+	if key != t.host {
+		return t.base.RoundTrip(req)
+	}
+
+	first := req
+	sentNonce := ""
+	if ch, nc, ok := t.cache.next(key); ok {
+		authReq, err := t.authorize(req, ch, nc)
+		if err != nil {
+			return nil, err
+		}
+		first = authReq
+		sentNonce = ch.nonce
+	}
+
+	resp, err := t.base.RoundTrip(first)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	ch, ok := parseDigestChallenges(resp.Header.Values(wwwAuthenticateHeader))
+	if !ok {
+		return resp, nil
+	}
+	// The same nonce refused without stale=true means the credentials are
+	// wrong. Retrying would only repeat the 401.
+	if sentNonce != "" && ch.nonce == sentNonce && !ch.stale {
+		t.cache.forget(key)
+		return resp, nil
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	t.cache.store(key, ch)
+	ch, nc, _ := t.cache.next(key)
+	retry, err := t.authorize(req, ch, nc)
+	if err != nil {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	resp, err = t.base.RoundTrip(retry)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		t.cache.forget(key)
+	}
+	return resp, err
+}
+
+func (t *digestTransport) unwrap() http.RoundTripper { return t.base }
+
+// authorize clones req with a fresh body and the computed Authorization
+// header. The original request is left untouched per the RoundTripper
+// contract.
+func (t *digestTransport) authorize(
+	req *http.Request,
+	ch digestChallenge,
+	nc uint32,
+) (*http.Request, error) {
+	var body []byte
+	out := req.Clone(req.Context())
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		body, err = io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if out.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	cnonce, err := digestCnonce()
+	if err != nil {
+		return nil, err
+	}
+	value, err := ch.authorization(t.creds, req.Method, req.URL.RequestURI(), body, nc, cnonce)
+	if err != nil {
+		return nil, diag.WrapAs(
+			diag.ClassAuth,
+			err,
+			"digest auth",
+			diag.WithComponent(diag.ComponentHTTP),
+		)
+	}
+	out.Header.Set(authorizationHeader, value)
+	return out, nil
+}
+
+func digestCnonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// authorization builds the credentials for one request. Without a qop the
+// legacy RFC 2069 form is used, which omits nc and cnonce.
+func (ch digestChallenge) authorization(
+	creds digestCreds,
+	method, uri string,
+	body []byte,
+	nc uint32,
+	cnonce string,
+) (string, error) {
+	newHash, sess, err := digestHash(ch.algorithm)
+	if err != nil {
+		return "", err
+	}
+	h := func(parts ...string) string {
+		hw := newHash()
+		_, _ = io.WriteString(hw, strings.Join(parts, ":"))
+		return hex.EncodeToString(hw.Sum(nil))
+	}
+
+	ha1 := h(creds.user, ch.realm, creds.pass)
+	if sess {
+		ha1 = h(ha1, ch.nonce, cnonce)
+	}
+	qop := ch.pickQop()
+	ha2 := h(method, uri)
+	if qop == digestQopAuthInt {
+		ha2 = h(method, uri, h(string(body)))
+	}
+
+	ncHex := fmt.Sprintf("%08x", nc)
+	var response string
+	if qop == "" {
+		response = h(ha1, ch.nonce, ha2)
+	} else {
+		response = h(ha1, ch.nonce, ncHex, cnonce, qop, ha2)
+	}
+
+	user := creds.user
+	if ch.userhash {
+		user = h(creds.user, ch.realm)
+	}
+
+	var b strings.Builder
+	b.WriteString(digestScheme)
+	b.WriteString(" ")
+	writeDigestParam(&b, "username", user, true)
+	writeDigestParam(&b, "realm", ch.realm, true)
+	writeDigestParam(&b, "nonce", ch.nonce, true)
+	writeDigestParam(&b, "uri", uri, true)
+	if ch.algorithm != "" {
+		writeDigestParam(&b, "algorithm", ch.algorithm, false)
+	}
+	writeDigestParam(&b, "response", response, true)
+	if ch.opaque != "" {
+		writeDigestParam(&b, "opaque", ch.opaque, true)
+	}
+	if qop != "" {
+		writeDigestParam(&b, "qop", qop, false)
+		writeDigestParam(&b, "nc", ncHex, false)
+		writeDigestParam(&b, "cnonce", cnonce, true)
+	}
+	if ch.userhash {
+		writeDigestParam(&b, "userhash", "true", false)
+	}
+	return b.String(), nil
+}
+
+// Plain auth is preferred when both are offered, matching curl. auth-int is
+// only chosen when the server insists on it.
+func (ch digestChallenge) pickQop() string {
+	intOnly := false
+	for _, q := range ch.qop {
+		switch q {
+		case digestQopAuth:
+			return digestQopAuth
+		case digestQopAuthInt:
+			intOnly = true
+		}
+	}
+	if intOnly {
+		return digestQopAuthInt
+	}
+	return ""
+}
+
+func writeDigestParam(b *strings.Builder, name, value string, quote bool) {
+	if !strings.HasSuffix(b.String(), " ") {
+		b.WriteString(", ")
+	}
+	b.WriteString(name)
+	b.WriteString("=")
+	if !quote {
+		b.WriteString(value)
+		return
+	}
+	b.WriteString(`"`)
+	b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value))
+	b.WriteString(`"`)
+}
+
+func digestHash(algorithm string) (func() hash.Hash, bool, error) {
+	name := strings.ToUpper(strings.TrimSpace(algorithm))
+	base, sess := strings.CutSuffix(name, "-SESS")
+	switch base {
+	case "", "MD5":
+		return md5.New, sess, nil
+	case "SHA-256":
+		return sha256.New, sess, nil
+	case "SHA-512-256":
+		return sha512.New512_256, sess, nil
+	default:
+		return nil, false, fmt.Errorf("%w %q", errDigestAlgorithm, algorithm)
+	}
+}
+
+// parseDigestChallenges picks the strongest supported Digest challenge. A
+// server may send one WWW-Authenticate line per algorithm, and each line may
+// also carry other schemes such as Basic.
+func parseDigestChallenges(values []string) (digestChallenge, bool) {
+	var found []digestChallenge
+	for _, v := range values {
+		if ch, ok := parseDigestChallenge(v); ok {
+			found = append(found, ch)
+		}
+	}
+	for _, alg := range digestAlgorithms {
+		for _, ch := range found {
+			base, _ := strings.CutSuffix(strings.ToUpper(ch.algorithm), "-SESS")
+			if base == alg || (base == "" && alg == "MD5") {
+				return ch, true
+			}
+		}
+	}
+	return digestChallenge{}, false
+}
+
+func parseDigestChallenge(value string) (digestChallenge, bool) {
+	value = strings.TrimSpace(value)
+	idx := indexFold(value, digestScheme+" ")
+	if idx < 0 {
+		return digestChallenge{}, false
+	}
+	params := parseAuthParams(value[idx+len(digestScheme)+1:])
+	ch := digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+		stale:     strings.EqualFold(params["stale"], "true"),
+		userhash:  strings.EqualFold(params["userhash"], "true"),
+	}
+	if ch.nonce == "" {
+		return digestChallenge{}, false
+	}
+	for q := range strings.SplitSeq(params["qop"], ",") {
+		if q = strings.ToLower(strings.TrimSpace(q)); q != "" {
+			ch.qop = append(ch.qop, q)
+		}
+	}
+	return ch, true
+}
+
+func indexFold(s, sub string) int {
+	return strings.Index(strings.ToLower(s), strings.ToLower(sub))
+}
+
+// parseAuthParams reads a comma separated auth-param list. It stops at a bare
+// token, which is where the next challenge scheme begins.
+func parseAuthParams(s string) map[string]string {
+	out := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return out
+		}
+		name := strings.ToLower(strings.TrimSpace(s[:eq]))
+		if strings.ContainsAny(name, " \t") {
+			return out
+		}
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		var val string
+		if strings.HasPrefix(s, `"`) {
+			val, s = readQuoted(s[1:])
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			val, s = strings.TrimSpace(s[:end]), s[end:]
+		}
+		out[name] = val
+	}
+}
+
+func readQuoted(s string) (string, string) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:]
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), ""
+}
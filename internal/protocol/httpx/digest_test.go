@@ -0,0 +1,174 @@
+package httpx
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func TestParseDigestChallengesPrefersStrongest(t *testing.T) {
+	ch, ok := parseDigestChallenges([]string{
+		`Digest realm="api", nonce="n1", algorithm=MD5, qop="auth"`,
+		`Digest realm="api", nonce="n2", algorithm=SHA-256, qop="auth,auth-int", opaque="op"`,
+		`Basic realm="api"`,
+	})
+	if !ok {
+		t.Fatal("no digest challenge parsed")
+	}
+	if ch.algorithm != "SHA-256" || ch.nonce != "n2" || ch.opaque != "op" {
+		t.Fatalf("unexpected challenge: %+v", ch)
+	}
+	if got := ch.pickQop(); got != digestQopAuth {
+		t.Fatalf("pickQop = %q, want auth", got)
+	}
+}
+
+func TestParseDigestChallengeAfterOtherScheme(t *testing.T) {
+	ch, ok := parseDigestChallenge(`Basic realm="x", Digest realm="a \"b\"", nonce="abc", stale=TRUE`)
+	if !ok {
+		t.Fatal("no digest challenge parsed")
+	}
+	if ch.realm != `a "b"` || !ch.stale {
+		t.Fatalf("unexpected challenge: %+v", ch)
+	}
+}
+
+// Values from the worked example in RFC 2617 section 3.5, which RFC 7616
+// keeps for MD5.
+func TestDigestAuthorizationMatchesRFCExample(t *testing.T) {
+	ch := digestChallenge{
+		realm:  "testrealm@host.com",
+		nonce:  "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+		opaque: "5ccc069c403ebaf9f0171e9517f40e41",
+		qop:    []string{"auth", "auth-int"},
+	}
+	got, err := ch.authorization(
+		digestCreds{user: "Mufasa", pass: "Circle Of Life"},
+		http.MethodGet,
+		"/dir/index.html",
+		nil,
+		1,
+		"0a4f113b",
+	)
+	if err != nil {
+		t.Fatalf("authorization: %v", err)
+	}
+	if !strings.Contains(got, `response="6629fae49393a05397450978507c4ef1"`) {
+		t.Fatalf("unexpected response hash in %s", got)
+	}
+	if !strings.Contains(got, "nc=00000001") || !strings.Contains(got, "qop=auth,") {
+		t.Fatalf("missing nc or qop in %s", got)
+	}
+}
+
+func TestDigestHashRejectsUnknownAlgorithm(t *testing.T) {
+	if _, _, err := digestHash("SHA-1"); err == nil {
+		t.Fatal("expected error for unsupported algorithm")
+	}
+}
+
+func TestExecuteDigestAuthCachesNonce(t *testing.T) {
+	const (
+		realm = "devices"
+		nonce = "fixed-nonce"
+	)
+	var (
+		challenges atomic.Int32
+		lastNC     atomic.Value
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		params := parseAuthParams(strings.TrimPrefix(r.Header.Get("Authorization"), "Digest "))
+		if params["response"] == "" {
+			challenges.Add(1)
+			w.Header().Set(
+				"WWW-Authenticate",
+				`Digest realm="`+realm+`", nonce="`+nonce+`", qop="auth-int"`,
+			)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h := func(s string) string {
+			sum := md5.Sum([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}
+		ha1 := h("admin:" + realm + ":s3cret")
+		ha2 := h(r.Method + ":" + r.URL.RequestURI() + ":" + h(string(body)))
+		want := h(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth-int:" + ha2)
+		if params["response"] != want {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		lastNC.Store(params["nc"])
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	client := NewClient(nil)
+	req := &restfile.Request{
+		Method: http.MethodPost,
+		URL:    srv.URL + "/config?x=1",
+		Body:   restfile.BodySource{Text: `{"reboot":true}`},
+		Metadata: restfile.RequestMetadata{Auth: &restfile.AuthSpec{
+			Type:   restfile.AuthDigest,
+			Params: map[string]string{"username": "admin", "password": "s3cret"},
+		}},
+	}
+
+	for i, wantNC := range []string{"00000001", "00000002"} {
+		resp, err := client.Execute(context.Background(), req, nil, Options{})
+		if err != nil {
+			t.Fatalf("execute %d: %v", i, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("execute %d: status %d", i, resp.StatusCode)
+		}
+		if string(resp.Body) != `{"reboot":true}` {
+			t.Fatalf("execute %d: body %q", i, resp.Body)
+		}
+		if got := lastNC.Load(); got != wantNC {
+			t.Fatalf("execute %d: nc = %v, want %s", i, got, wantNC)
+		}
+	}
+	if got := challenges.Load(); got != 1 {
+		t.Fatalf("expected one 401 round trip, got %d", got)
+	}
+}
+
+func TestExecuteDigestAuthStopsOnRejectedCredentials(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("WWW-Authenticate", `Digest realm="r", nonce="n"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	client := NewClient(nil)
+	req := &restfile.Request{
+		Method: http.MethodGet,
+		URL:    srv.URL,
+		Metadata: restfile.RequestMetadata{Auth: &restfile.AuthSpec{
+			Type:   restfile.AuthDigest,
+			Params: map[string]string{"username": "u", "password": "wrong"},
+		}},
+	}
+	resp, err := client.Execute(context.Background(), req, nil, Options{})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", resp.StatusCode)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("expected challenge and one answer, got %d requests", got)
+	}
+}
@@ -0,0 +1,433 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/unkn0wn-root/resterm/internal/diag"
+)
+
+// defaultAltSvcMaxAge is how long an Alt-Svc entry without ma= stays fresh.
+const defaultAltSvcMaxAge = 24 * time.Hour
+
+// An origin whose QUIC endpoint failed is kept on TCP for altSvcBrokenFor,
+// doubling with each further failure up to altSvcBrokenMax.
+const (
+	altSvcBrokenFor = 5 * time.Minute
+	altSvcBrokenMax = 48 * time.Hour
+)
+
+// http3HandshakeTimeout bounds a QUIC handshake, so an endpoint that drops
+// UDP falls back to TCP as quickly as a stalled TLS handshake would fail.
+var http3HandshakeTimeout = defaultTLSHandshakeTimeout
+
+// envProxy is the proxy the environment picks for a request, and
+// lookupIPAddr resolves a QUIC target. Tests swap them, since net/http reads
+// the proxy variables once per process.
+var (
+	envProxy     = http.ProxyFromEnvironment
+	lookupIPAddr = net.DefaultResolver.LookupIPAddr
+)
+
+// buildHTTP3Client speaks HTTP/3 only. QUIC runs over UDP, which proxies,
+// ssh and k8s tunnels and unix sockets cannot carry, so those are refused
+// rather than silently bypassed. A proxy from the environment is only known
+// per request, so strictHTTP3Transport refuses that one.
+func buildHTTP3Client(opts Options) (*http.Client, error) {
+	switch {
+	case opts.ProxyConfig().Explicit():
+		return nil, diag.New(diag.ClassRoute, "http-version=3 cannot be combined with a proxy")
+	case (opts.SSH != nil && opts.SSH.Active()) || (opts.K8s != nil && opts.K8s.Active()):
+		return nil, diag.New(
+			diag.ClassRoute,
+			"http-version=3 cannot be combined with ssh or k8s tunneling",
+		)
+	case unixSocketPath(opts) != "":
+		return nil, diag.New(diag.ClassRoute, "http-version=3 cannot be combined with a unix socket")
+	}
+	transport, err := newHTTP3Transport(opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newHTTPClient(&strictHTTP3Transport{h3: transport}, opts), nil
+}
+
+// strictHTTP3Transport is http-version=3: it fails a request the
+// environment would send through a proxy instead of going around it.
+type strictHTTP3Transport struct {
+	h3 *http3.Transport
+}
+
+func (t *strictHTTP3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p, err := envProxy(req)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		return nil, diag.New(
+			diag.ClassRoute,
+			"http-version=3 cannot be combined with a proxy; HTTPS_PROXY routes "+req.URL.Host+
+				" through "+p.Host+", add it to NO_PROXY",
+		)
+	}
+	return t.h3.RoundTrip(req)
+}
+
+func (t *strictHTTP3Transport) CloseIdleConnections() {
+	t.h3.CloseIdleConnections()
+}
+
+// http3Reachable reports whether an alt-svc upgrade could reach the origin.
+// Requests routed through a proxy, tunnel or socket stay on TCP; an
+// environment proxy is checked per request by altSvcTransport.
+func http3Reachable(opts Options) bool {
+	return !opts.ProxyConfig().Explicit() &&
+		(opts.SSH == nil || !opts.SSH.Active()) &&
+		(opts.K8s == nil || !opts.K8s.Active()) &&
+		unixSocketPath(opts) == ""
+}
+
+// newHTTP3Transport builds an HTTP/3 transport. route, when set, maps the
+// origin's host:port to the address that serves it over QUIC.
+func newHTTP3Transport(opts Options, route func(string) string) (*http3.Transport, error) {
+	tlsCfg, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &http3.Transport{
+		TLSClientConfig: tlsCfg,
+		QUICConfig:      &quic.Config{HandshakeIdleTimeout: http3HandshakeTimeout},
+		Dial:            http3Dialer(opts, route),
+	}, nil
+}
+
+// http3DialError marks a QUIC connection that never came up, so nothing of
+// the request was sent and it is safe to retry over TCP.
+type http3DialError struct {
+	err error
+}
+
+func (e *http3DialError) Error() string { return e.err.Error() }
+
+func (e *http3DialError) Unwrap() error { return e.err }
+
+// http3Dialer resolves the address itself so the trace sees the lookup, and
+// honors http-resolve and http-connect-to the way the TCP dialer does.
+func http3Dialer(
+	opts Options,
+	route func(string) string,
+) func(context.Context, string, *tls.Config, *quic.Config) (*quic.Conn, error) {
+	return func(
+		ctx context.Context,
+		addr string,
+		tlsCfg *tls.Config,
+		cfg *quic.Config,
+	) (*quic.Conn, error) {
+		if route != nil {
+			addr = route(addr)
+		}
+		to, skipped := overrideAddr(addr, opts.ConnectTo, opts.Resolve)
+		host, port, err := net.SplitHostPort(to)
+		if err != nil {
+			return nil, &http3DialError{err: err}
+		}
+		sess := traceSessionFrom(ctx)
+		if skipped && sess != nil {
+			sess.onDNSOverride(host)
+		}
+		hosts := []string{host}
+		if net.ParseIP(host) == nil {
+			ips, err := lookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, &http3DialError{err: err}
+			}
+			hosts = hosts[:0]
+			for _, ip := range ips {
+				hosts = append(hosts, ip.IP.String())
+			}
+		}
+
+		// A dual-stack host may list an address the client cannot reach
+		// first, so every address is tried before the endpoint counts as
+		// dead.
+		var errs []error
+		for _, h := range hosts {
+			conn, err := dialQUIC(ctx, sess, net.JoinHostPort(h, port), tlsCfg, cfg)
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, &http3DialError{err: errors.Join(errs...)}
+	}
+}
+
+func dialQUIC(
+	ctx context.Context,
+	sess *traceSession,
+	udpAddr string,
+	tlsCfg *tls.Config,
+	cfg *quic.Config,
+) (*quic.Conn, error) {
+	sess.onQUICStart(udpAddr)
+	conn, err := quic.DialAddr(ctx, udpAddr, tlsCfg, cfg)
+	var state tls.ConnectionState
+	if conn != nil {
+		state = conn.ConnectionState().TLS
+	}
+	sess.onQUICDone(state, err)
+	return conn, err
+}
+
+// altSvcCache remembers which origins advertised HTTP/3. It is shared by
+// clones of a Client, like the digest cache, because every request builds a
+// fresh transport. Keys are the origin's host:port.
+type altSvcCache struct {
+	mu    sync.Mutex
+	hosts map[string]altSvcEntry
+	// broken holds origins whose HTTP/3 endpoint failed to connect. Their
+	// Alt-Svc headers are ignored until the entry's time is up, or the TCP
+	// fallback would advertise the dead endpoint again on every response.
+	broken map[string]altSvcBroken
+}
+
+type altSvcEntry struct {
+	addr    string
+	expires time.Time
+}
+
+type altSvcBroken struct {
+	until   time.Time
+	backoff time.Duration
+}
+
+func newAltSvcCache() *altSvcCache {
+	return &altSvcCache{
+		hosts:  make(map[string]altSvcEntry),
+		broken: make(map[string]altSvcBroken),
+	}
+}
+
+func (c *altSvcCache) lookup(origin string) (string, bool) {
+	if c == nil || origin == "" {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.hosts[origin]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.hosts, origin)
+		return "", false
+	}
+	return e.addr, true
+}
+
+// update applies the Alt-Svc headers of a response from origin. A response
+// without the header leaves the cache alone.
+func (c *altSvcCache) update(origin string, values []string) {
+	if c == nil || origin == "" || len(values) == 0 {
+		return
+	}
+	host, _, _ := net.SplitHostPort(origin)
+	addr, maxAge, ok := parseAltSvc(values, host)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, isBroken := c.broken[origin]; isBroken && time.Now().Before(b.until) {
+		return
+	}
+	if !ok || maxAge <= 0 {
+		delete(c.hosts, origin)
+		return
+	}
+	c.hosts[origin] = altSvcEntry{addr: addr, expires: time.Now().Add(maxAge)}
+}
+
+// markBroken drops origin's entry after a failed QUIC connection and keeps
+// it on TCP for a while. The wait doubles each time the endpoint fails again.
+func (c *altSvcCache) markBroken(origin string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.hosts, origin)
+	backoff := altSvcBrokenFor
+	if b, ok := c.broken[origin]; ok {
+		backoff = min(b.backoff*2, altSvcBrokenMax)
+	}
+	c.broken[origin] = altSvcBroken{until: time.Now().Add(backoff), backoff: backoff}
+}
+
+// markWorking resets the backoff once origin has served a request over QUIC.
+func (c *altSvcCache) markWorking(origin string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	delete(c.broken, origin)
+	c.mu.Unlock()
+}
+
+// parseAltSvc returns the first h3 alternative in Alt-Svc header values, as
+// host:port with an omitted host filled in from the origin. "clear" and a
+// header without h3 both report no alternative.
+func parseAltSvc(values []string, originHost string) (string, time.Duration, bool) {
+	for _, v := range values {
+		for entry := range strings.SplitSeq(v, ",") {
+			entry = strings.TrimSpace(entry)
+			if strings.EqualFold(entry, "clear") {
+				return "", 0, false
+			}
+			params := strings.Split(entry, ";")
+			proto, auth, ok := strings.Cut(strings.TrimSpace(params[0]), "=")
+			if !ok || strings.TrimSpace(proto) != http3.NextProtoH3 {
+				continue
+			}
+			host, port, err := net.SplitHostPort(strings.Trim(strings.TrimSpace(auth), `"`))
+			if err != nil || port == "" {
+				continue
+			}
+			if host == "" {
+				host = originHost
+			}
+			maxAge := defaultAltSvcMaxAge
+			for _, p := range params[1:] {
+				k, val, _ := strings.Cut(strings.TrimSpace(p), "=")
+				if !strings.EqualFold(k, "ma") {
+					continue
+				}
+				if n, err := strconv.ParseInt(strings.Trim(val, `"`), 10, 64); err == nil {
+					maxAge = time.Duration(n) * time.Second
+				}
+			}
+			return net.JoinHostPort(host, port), maxAge, true
+		}
+	}
+	return "", 0, false
+}
+
+// altSvcOrigin keys the cache the way the HTTP/3 transport names the address
+// it dials. Only https origins can move to HTTP/3.
+func altSvcOrigin(u *url.URL) string {
+	if u == nil || !strings.EqualFold(u.Scheme, "https") {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+	return net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+// altSvcTransport sends requests over TCP until the origin advertises h3,
+// then over QUIC. If the QUIC connection cannot be set up, the origin is
+// marked broken and the request goes out over TCP instead.
+type altSvcTransport struct {
+	cache *altSvcCache
+	base  *http.Transport
+	h3    *http3.Transport
+}
+
+func newAltSvcTransport(
+	cache *altSvcCache,
+	base *http.Transport,
+	opts Options,
+) (*altSvcTransport, error) {
+	h3, err := newHTTP3Transport(opts, func(addr string) string {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return addr
+		}
+		if alt, ok := cache.lookup(net.JoinHostPort(strings.ToLower(host), port)); ok {
+			return alt
+		}
+		return addr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &altSvcTransport{cache: cache, base: base, h3: h3}, nil
+}
+
+func (t *altSvcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	origin := altSvcOrigin(req.URL)
+	if t.proxied(req) {
+		// The proxy cannot carry QUIC, so what the origin advertises
+		// through it is of no use to this request.
+		return t.base.RoundTrip(req)
+	}
+	if _, ok := t.cache.lookup(origin); ok {
+		resp, err := t.h3.RoundTrip(req)
+		var dialErr *http3DialError
+		if !errors.As(err, &dialErr) {
+			if err == nil {
+				t.cache.markWorking(origin)
+				t.cache.update(origin, resp.Header.Values("Alt-Svc"))
+			}
+			return resp, err
+		}
+		t.cache.markBroken(origin)
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.cache.update(origin, resp.Header.Values("Alt-Svc"))
+	}
+	return resp, err
+}
+
+// proxied reports whether the environment sends req through a proxy, which
+// cannot carry QUIC.
+func (t *altSvcTransport) proxied(req *http.Request) bool {
+	if t.base.Proxy == nil {
+		return false
+	}
+	p, err := t.base.Proxy(req)
+	return err != nil || p != nil
+}
+
+func (t *altSvcTransport) unwrap() http.RoundTripper { return t.base }
+
+func (t *altSvcTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+	t.h3.CloseIdleConnections()
+}
+
+// rewindRequest gives back a request whose body can be sent again, since the
+// failed HTTP/3 attempt closed the original one.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, diag.New(
+			diag.ClassNetwork,
+			"http/3 connection failed and the request body cannot be resent over tcp",
+		)
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Body = body
+	return out, nil
+}
@@ -0,0 +1,317 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/unkn0wn-root/resterm/internal/nettrace"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+// startDualServer serves the same handler over TLS on TCP and over HTTP/3 on
+// UDP, and has the TCP side advertise the UDP port in Alt-Svc.
+func startDualServer(t *testing.T) (tcpURL string, udpPort string) {
+	t.Helper()
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	_, udpPort, _ = net.SplitHostPort(udp.LocalAddr().String())
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor < 3 {
+			w.Header().Set("Alt-Svc", fmt.Sprintf(`h3=":%s"; ma=60`, udpPort))
+		}
+		_, _ = fmt.Fprint(w, r.Proto)
+	})
+	tcp := httptest.NewTLSServer(handler)
+	t.Cleanup(tcp.Close)
+
+	h3 := &http3.Server{
+		Handler:   handler,
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: tcp.TLS.Certificates}),
+	}
+	go func() { _ = h3.Serve(udp) }()
+	t.Cleanup(func() {
+		_ = h3.Close()
+		_ = udp.Close()
+	})
+	return tcp.URL, udpPort
+}
+
+func TestExecuteHTTP3(t *testing.T) {
+	_, udpPort := startDualServer(t)
+
+	req := &restfile.Request{
+		Method:   http.MethodGet,
+		URL:      "https://127.0.0.1:" + udpPort + "/",
+		Settings: map[string]string{"http-version": "3"},
+	}
+	opts := Options{InsecureSkipVerify: true, Trace: true, Timeout: 5 * time.Second}
+	resp, err := NewClient(nil).Execute(context.Background(), req, vars.NewResolver(), opts)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if resp.Proto != "HTTP/3.0" || string(resp.Body) != "HTTP/3.0" {
+		t.Fatalf("proto = %q, body = %q", resp.Proto, resp.Body)
+	}
+
+	if resp.Timeline == nil {
+		t.Fatal("expected a trace timeline")
+	}
+	i := slices.IndexFunc(resp.Timeline.Phases, func(p nettrace.Phase) bool {
+		return p.Kind == nettrace.PhaseQUIC
+	})
+	if i < 0 {
+		t.Fatalf("expected a quic phase, got %+v", resp.Timeline.Phases)
+	}
+	if p := resp.Timeline.Phases[i]; p.Err != "" || p.Meta.Addr != "127.0.0.1:"+udpPort {
+		t.Fatalf("quic phase = %+v", p)
+	}
+	d := resp.Timeline.Details
+	if d == nil || d.Connection == nil || d.Connection.Network != "udp" {
+		t.Fatalf("connection details = %+v", d)
+	}
+	if d.TLS == nil || d.TLS.ALPN != "h3" {
+		t.Fatalf("tls details = %+v", d.TLS)
+	}
+}
+
+func TestExecuteAltSvcUpgradesToHTTP3(t *testing.T) {
+	tcpURL, _ := startDualServer(t)
+
+	client := NewClient(nil)
+	opts := Options{InsecureSkipVerify: true, Timeout: 5 * time.Second}
+	send := func() string {
+		t.Helper()
+		req := &restfile.Request{
+			Method:   http.MethodGet,
+			URL:      tcpURL + "/",
+			Settings: map[string]string{"http-version": "alt-svc"},
+		}
+		resp, err := client.Execute(context.Background(), req, vars.NewResolver(), opts)
+		if err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+		return string(resp.Body)
+	}
+
+	if got := send(); got == "HTTP/3.0" {
+		t.Fatalf("first request should go over tcp, got %s", got)
+	}
+	if got := send(); got != "HTTP/3.0" {
+		t.Fatalf("second request should use the advertised h3 endpoint, got %s", got)
+	}
+	// A clone keeps what the original learned.
+	req := &restfile.Request{
+		Method:   http.MethodGet,
+		URL:      tcpURL + "/",
+		Settings: map[string]string{"http-version": "alt-svc"},
+	}
+	resp, err := client.Clone().Execute(context.Background(), req, vars.NewResolver(), opts)
+	if err != nil || string(resp.Body) != "HTTP/3.0" {
+		t.Fatalf("clone: %v / %+v", err, resp)
+	}
+}
+
+// An advertised HTTP/3 endpoint that never answers must not be retried on
+// every request just because the TCP fallback advertises it again.
+func TestExecuteAltSvcKeepsBrokenOriginOnTCP(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	t.Cleanup(func() { _ = udp.Close() })
+	_, udpPort, _ := net.SplitHostPort(udp.LocalAddr().String())
+	tcp := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", fmt.Sprintf(`h3=":%s"; ma=60`, udpPort))
+		_, _ = fmt.Fprint(w, r.Proto)
+	}))
+	t.Cleanup(tcp.Close)
+
+	prev := http3HandshakeTimeout
+	http3HandshakeTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { http3HandshakeTimeout = prev })
+
+	client := NewClient(nil)
+	opts := Options{InsecureSkipVerify: true, Timeout: 5 * time.Second}
+	send := func() string {
+		t.Helper()
+		req := &restfile.Request{
+			Method:   http.MethodGet,
+			URL:      tcp.URL + "/",
+			Settings: map[string]string{"http-version": "alt-svc"},
+		}
+		resp, err := client.Execute(context.Background(), req, vars.NewResolver(), opts)
+		if err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+		return string(resp.Body)
+	}
+
+	u, _ := url.Parse(tcp.URL)
+	origin := altSvcOrigin(u)
+	send()
+	if _, ok := client.altSvc.lookup(origin); !ok {
+		t.Fatal("first response should record the advertised endpoint")
+	}
+	if got := send(); got == "HTTP/3.0" {
+		t.Fatalf("dead h3 endpoint served %s", got)
+	}
+	if _, ok := client.altSvc.lookup(origin); ok {
+		t.Fatal("the fallback response re-armed the broken endpoint")
+	}
+	start := time.Now()
+	send()
+	if d := time.Since(start); d >= http3HandshakeTimeout {
+		t.Fatalf("third request waited %s, want it straight on tcp", d)
+	}
+
+	first := client.altSvc.broken[origin].backoff
+	client.altSvc.markBroken(origin)
+	if first != altSvcBrokenFor || client.altSvc.broken[origin].backoff != 2*altSvcBrokenFor {
+		t.Fatalf("backoff = %s then %s", first, client.altSvc.broken[origin].backoff)
+	}
+}
+
+// A host that lists an address the client cannot reach ahead of the one
+// serving QUIC must still be reached.
+func TestExecuteHTTP3TriesEveryAddress(t *testing.T) {
+	_, udpPort := startDualServer(t)
+
+	prevLookup, prevTimeout := lookupIPAddr, http3HandshakeTimeout
+	lookupIPAddr = func(context.Context, string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("127.0.0.2")}, {IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+	http3HandshakeTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { lookupIPAddr, http3HandshakeTimeout = prevLookup, prevTimeout })
+
+	req := &restfile.Request{
+		Method:   http.MethodGet,
+		URL:      "https://h3.test:" + udpPort + "/",
+		Settings: map[string]string{"http-version": "3"},
+	}
+	opts := Options{InsecureSkipVerify: true, Timeout: 5 * time.Second}
+	resp, err := NewClient(nil).Execute(context.Background(), req, vars.NewResolver(), opts)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if resp.Proto != "HTTP/3.0" {
+		t.Fatalf("proto = %q", resp.Proto)
+	}
+}
+
+// A proxy from the environment cannot carry QUIC either, so http-version=3
+// refuses it and alt-svc keeps the request on TCP through it.
+func TestHTTP3HonorsEnvironmentProxy(t *testing.T) {
+	tcpURL, udpPort := startDualServer(t)
+
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	proxy := &url.URL{Scheme: "http", Host: dead.Addr().String()}
+	_ = dead.Close()
+	useProxy := false
+	prev := envProxy
+	envProxy = func(*http.Request) (*url.URL, error) {
+		if useProxy {
+			return proxy, nil
+		}
+		return nil, nil
+	}
+	t.Cleanup(func() { envProxy = prev })
+
+	client := NewClient(nil)
+	opts := Options{InsecureSkipVerify: true, Timeout: 5 * time.Second}
+	send := func(target, ver string) (*Response, error) {
+		req := &restfile.Request{
+			Method:   http.MethodGet,
+			URL:      target,
+			Settings: map[string]string{"http-version": ver},
+		}
+		return client.Execute(context.Background(), req, vars.NewResolver(), opts)
+	}
+
+	useProxy = true
+	if _, err := send("https://127.0.0.1:"+udpPort+"/", "3"); err == nil ||
+		!strings.Contains(err.Error(), "proxy") {
+		t.Fatalf("http-version=3 through a proxy: %v", err)
+	}
+
+	useProxy = false
+	if _, err := send(tcpURL+"/", "alt-svc"); err != nil {
+		t.Fatalf("learn alt-svc: %v", err)
+	}
+	// With the endpoint learned, a proxied request must still try the
+	// (unreachable) proxy rather than going straight to QUIC.
+	useProxy = true
+	if resp, err := send(tcpURL+"/", "alt-svc"); err == nil {
+		t.Fatalf("proxied alt-svc request bypassed the proxy: %s", resp.Proto)
+	}
+}
+
+func TestExecuteHTTP3RejectsTCPOnlyRoutes(t *testing.T) {
+	tests := []struct {
+		name string
+		set  map[string]string
+	}{
+		{name: "proxy", set: map[string]string{"proxy": "http://proxy:3128"}},
+		{name: "unix socket", set: map[string]string{"http-unix-socket": "/tmp/api.sock"}},
+		{name: "plain http", set: nil},
+	}
+	for _, tc := range tests {
+		set := map[string]string{"http-version": "3"}
+		for k, v := range tc.set {
+			set[k] = v
+		}
+		target := "https://example.com/"
+		if tc.set == nil {
+			target = "http://example.com/"
+		}
+		req := &restfile.Request{Method: http.MethodGet, URL: target, Settings: set}
+		_, err := NewClient(nil).Execute(context.Background(), req, vars.NewResolver(), Options{})
+		if err == nil {
+			t.Fatalf("%s: expected http-version=3 to be rejected", tc.name)
+		}
+	}
+}
+
+func TestParseAltSvc(t *testing.T) {
+	tests := []struct {
+		in     string
+		addr   string
+		maxAge time.Duration
+		ok     bool
+	}{
+		{in: `h3=":443"; ma=3600`, addr: "api.example.com:443", maxAge: time.Hour, ok: true},
+		{in: `h3-29=":443", h3="alt.example.net:8443"`, addr: "alt.example.net:8443", maxAge: defaultAltSvcMaxAge, ok: true},
+		{in: `h2=":443"`},
+		{in: `clear`},
+		{in: `h3=""`},
+	}
+	for _, tc := range tests {
+		addr, maxAge, ok := parseAltSvc([]string{tc.in}, "api.example.com")
+		if addr != tc.addr || maxAge != tc.maxAge || ok != tc.ok {
+			t.Fatalf("parseAltSvc(%q) = %q, %v, %v", tc.in, addr, maxAge, ok)
+		}
+	}
+
+	if got := altSvcOrigin(&url.URL{Scheme: "https", Host: "API.example.com"}); got != "api.example.com:443" {
+		t.Fatalf("origin = %q", got)
+	}
+	if got := altSvcOrigin(&url.URL{Scheme: "http", Host: "api.example.com"}); got != "" {
+		t.Fatalf("http origin = %q, want none", got)
+	}
+}
@@ -2,13 +2,13 @@ package httpx
 
 import (
 	"fmt"
-	"net/url"
 	"strings"
 	"time"
 
 	"github.com/unkn0wn-root/resterm/internal/diag"
 	"github.com/unkn0wn-root/resterm/internal/directive"
 	"github.com/unkn0wn-root/resterm/internal/http/version"
+	"github.com/unkn0wn-root/resterm/internal/netproxy"
 )
 
 type optionSettingKey string
@@ -16,9 +16,13 @@ type optionSettingKey string
 const (
 	optionSettingTimeout         optionSettingKey = "timeout"
 	optionSettingProxy           optionSettingKey = "proxy"
+	optionSettingProxyRules      optionSettingKey = "proxy-rules"
 	optionSettingFollowRedirects optionSettingKey = "followredirects"
 	optionSettingInsecure        optionSettingKey = "insecure"
 	optionSettingNoCookies       optionSettingKey = "no-cookies"
+	optionSettingUnixSocket      optionSettingKey = "http-unix-socket"
+	optionSettingResolve         optionSettingKey = "http-resolve"
+	optionSettingConnectTo       optionSettingKey = "http-connect-to"
 )
 
 // Settings come from a file the user edits, so name the key and what it takes.
@@ -60,7 +64,7 @@ func applyOptionSettings(opts *Options, settings map[string]string, strict bool)
 		case valid:
 			opts.HTTPVersion = v
 		case strict:
-			return invalidSetting(version.Key, val, "1.0, 1.1, 2 or HTTP/1.1, HTTP/2")
+			return invalidSetting(version.Key, val, "1.0, 1.1, 2, 3, alt-svc or HTTP/1.1, HTTP/2, HTTP/3")
 		}
 	}
 
@@ -76,11 +80,62 @@ func applyOptionSettings(opts *Options, settings map[string]string, strict bool)
 	// An empty proxy reads as "not set". Anything else has to be a URL the
 	// transport can dial, or it fails much later with a bare dial error.
 	if val, ok := settingValue(norm, optionSettingProxy); ok && strings.TrimSpace(val) != "" {
-		switch u, err := url.Parse(val); {
-		case err == nil && u.Scheme != "" && u.Host != "":
-			opts.ProxyURL = val
+		switch _, err := netproxy.ParseURL(val); {
+		case err == nil:
+			opts.ProxyURL = strings.TrimSpace(val)
+		case strict:
+			return invalidSetting(
+				optionSettingProxy,
+				val,
+				"an http, https, socks5 or socks5h URL such as http://host:8080",
+			)
+		}
+	}
+
+	if val, ok := settingValue(norm, optionSettingProxyRules); ok {
+		switch rules, err := netproxy.ParseRules(val); {
+		case err == nil:
+			opts.ProxyRules = rules
+		case strict:
+			return invalidSetting(
+				optionSettingProxyRules,
+				val,
+				"host-glob=proxy-url or host-glob=DIRECT pairs such as *.corp=DIRECT",
+			)
+		}
+	}
+
+	// The socket only has to exist once the request dials it, so any path
+	// will do here.
+	if val, ok := settingValue(norm, optionSettingUnixSocket); ok {
+		if p := strings.TrimSpace(val); p != "" {
+			opts.UnixSocket = p
+		}
+	}
+
+	if val, ok := settingValue(norm, optionSettingResolve); ok {
+		switch rules, valid := parseResolve(val); {
+		case valid:
+			opts.Resolve = rules
+		case strict:
+			return invalidSetting(
+				optionSettingResolve,
+				val,
+				"host:port=address such as api.example.com:443=127.0.0.1",
+			)
+		}
+	}
+
+	if val, ok := settingValue(norm, optionSettingConnectTo); ok {
+		switch rules, valid := parseConnectTo(val); {
+		case valid:
+			opts.ConnectTo = rules
 		case strict:
-			return invalidSetting(optionSettingProxy, val, "a URL such as http://host:8080")
+			return invalidSetting(
+				optionSettingConnectTo,
+				val,
+				"host:port=host:port such as api.example.com:443=lb.internal:8443",
+			)
 		}
 	}
 
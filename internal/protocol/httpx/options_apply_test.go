@@ -21,6 +21,8 @@ func TestApplyOptionSettingsRejectsInvalidValues(t *testing.T) {
 		{name: "insecure", key: "insecure", val: "maybe", want: `invalid insecure "maybe" (use true or false)`},
 		{name: "no-cookies", key: "no-cookies", val: "maybe", want: `invalid no-cookies "maybe" (use true or false)`},
 		{name: "http-version", key: "http-version", val: "unsupported", want: `invalid http-version "unsupported"`},
+		{name: "http-resolve", key: "http-resolve", val: "api.example.com=10.0.0.1", want: `invalid http-resolve "api.example.com=10.0.0.1"`},
+		{name: "http-connect-to", key: "http-connect-to", val: "true", want: `invalid http-connect-to "true"`},
 		// A bare "@setting insecure" writes no value at all, so say it is missing.
 		{name: "bare bool", key: "insecure", val: "", want: "missing insecure value (use true or false)"},
 		{
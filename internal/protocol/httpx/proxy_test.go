@@ -0,0 +1,57 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+func TestExecuteProxyRulesPickPerHost(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "proxy %s %s", r.URL, r.Header.Get("Proxy-Authorization"))
+	}))
+	defer proxy.Close()
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "direct")
+	}))
+	defer direct.Close()
+
+	opts := Options{ProxyUser: "ops", ProxyPassword: "pw", Trace: true}
+	settings := map[string]string{
+		"proxy-rules": "127.0.0.1=DIRECT, *.example.test=" + proxy.URL,
+	}
+	tests := []struct {
+		url   string
+		want  string
+		proxy string
+	}{
+		{url: direct.URL + "/ping", want: "direct"},
+		{
+			url:   "http://api.example.test/ping",
+			want:  "proxy http://api.example.test/ping Basic b3BzOnB3",
+			proxy: proxy.URL,
+		},
+	}
+	for _, tc := range tests {
+		req := &restfile.Request{Method: http.MethodGet, URL: tc.url, Settings: settings}
+		resp, err := NewClient(nil).Execute(context.Background(), req, vars.NewResolver(), opts)
+		if err != nil {
+			t.Fatalf("execute %s: %v", tc.url, err)
+		}
+		if got := string(resp.Body); got != tc.want {
+			t.Fatalf("%s: body = %q, want %q", tc.url, got, tc.want)
+		}
+		var got string
+		if d := resp.Timeline.Details; d != nil && d.Connection != nil {
+			got = d.Connection.Proxy
+		}
+		if got != tc.proxy {
+			t.Fatalf("%s: traced proxy = %q, want %q", tc.url, got, tc.proxy)
+		}
+	}
+}
@@ -42,8 +42,11 @@ func (c *Client) prepareHTTPRequest(
 		)
 	}
 
+	// Digest answers a 401 by replaying the request, which needs a body that
+	// can be read twice.
+	replay := req.Metadata.Auth.Kind() == restfile.AuthDigest
 	effective := applyRequestSettings(opts, req.Settings)
-	prepared, err := c.prepareRequest(ctx, req, resolver, effective, false)
+	prepared, err := c.prepareRequest(ctx, req, resolver, effective, replay)
 	if err != nil {
 		return nil, effective, err
 	}
@@ -171,7 +174,7 @@ func applyHTTPVersion(req *http.Request, v version.HTTP) {
 		req.Proto = "HTTP/1.1"
 		req.ProtoMajor = 1
 		req.ProtoMinor = 1
-	case version.V2:
-		// HTTP/2 is negotiated by the transport; net/http ignores req.Proto for h2.
+	case version.V2, version.V3, version.AltSvc:
+		// The transport picks the protocol; req.Proto only matters for HTTP/1.x.
 	}
 }
@@ -153,6 +153,14 @@ func (c *Client) buildHTTPRequest(
 		}
 	}
 
+	expandedURL, sock, err := unixTarget(expandedURL)
+	if err != nil {
+		return nil, opts, err
+	}
+	if sock != "" {
+		opts.UnixSocket = sock
+	}
+
 	httpReq, err := http.NewRequestWithContext(ctx, req.Method, expandedURL, body)
 	if err != nil {
 		return nil, opts, diag.WrapAs(
@@ -1,6 +1,7 @@
 package httpx
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
 	"net/http"
@@ -46,9 +47,17 @@ func (s *traceSession) bind(req *http.Request) *http.Request {
 	}
 
 	ctx := httptrace.WithClientTrace(req.Context(), s.trace)
+	ctx = context.WithValue(ctx, traceSessionKey{}, s)
 	return req.WithContext(ctx)
 }
 
+type traceSessionKey struct{}
+
+func traceSessionFrom(ctx context.Context) *traceSession {
+	s, _ := ctx.Value(traceSessionKey{}).(*traceSession)
+	return s
+}
+
 func (s *traceSession) onGetConn(hostPort string) {
 	if hostPort == "" {
 		return
@@ -93,6 +102,23 @@ func (s *traceSession) onDNSDone(info httptrace.DNSDoneInfo) {
 	}
 }
 
+// onDNSOverride records the lookup a resolve or connect-to rule stood in for,
+// so the timeline shows where the address came from instead of a gap.
+func (s *traceSession) onDNSOverride(ip string) {
+	now := time.Now()
+	s.collector.Begin(nettrace.PhaseDNS, now)
+	s.collector.UpdateMeta(nettrace.PhaseDNS, func(meta *nettrace.PhaseMeta) {
+		meta.Addr = ip
+		meta.Override = true
+	})
+	s.collector.End(nettrace.PhaseDNS, now, nil)
+	if addr := net.ParseIP(ip); addr != nil {
+		s.withConn(func(conn *nettrace.ConnDetails) {
+			conn.ResolvedAddrs = mergeIPs(conn.ResolvedAddrs, []net.IPAddr{{IP: addr}})
+		})
+	}
+}
+
 func (s *traceSession) onConnectStart(network, addr string) {
 	now := time.Now()
 	s.collector.Begin(nettrace.PhaseConnect, now)
@@ -157,6 +183,34 @@ func (s *traceSession) onTLSHandshakeDone(state tls.ConnectionState, err error)
 	s.withTLS(state)
 }
 
+// onQUICStart and onQUICDone time an HTTP/3 handshake, which sets up the
+// connection and TLS in one exchange and so has no separate connect phase.
+func (s *traceSession) onQUICStart(addr string) {
+	if s == nil {
+		return
+	}
+	s.collector.Begin(nettrace.PhaseQUIC, time.Now())
+	s.collector.UpdateMeta(nettrace.PhaseQUIC, func(meta *nettrace.PhaseMeta) {
+		meta.Addr = addr
+	})
+	s.withConn(func(conn *nettrace.ConnDetails) {
+		conn.Network = "udp"
+		conn.DialAddr = addr
+	})
+}
+
+// onQUICDone leaves failing the timeline to the caller: an alt-svc upgrade
+// that cannot connect falls back to TCP and the request still succeeds.
+func (s *traceSession) onQUICDone(state tls.ConnectionState, err error) {
+	if s == nil {
+		return
+	}
+	s.collector.End(nettrace.PhaseQUIC, time.Now(), err)
+	if err == nil {
+		s.withTLS(state)
+	}
+}
+
 func (s *traceSession) onWroteHeaders() {
 	now := time.Now()
 	s.collector.Begin(nettrace.PhaseReqHdrs, now)
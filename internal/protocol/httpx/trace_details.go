@@ -130,6 +130,15 @@ func proxyForRequest(req *http.Request, opts Options, client *http.Client) strin
 	if (opts.SSH != nil && opts.SSH.Active()) || (opts.K8s != nil && opts.K8s.Active()) {
 		return ""
 	}
+	// Rules pick per host, so ask them rather than trusting ProxyURL, which
+	// a DIRECT rule may have skipped.
+	if cfg := opts.ProxyConfig(); cfg.Explicit() && req != nil {
+		proxyURL, err := cfg.Lookup(req.URL)
+		if err != nil {
+			return ""
+		}
+		return sanitizeProxyURL(proxyURL)
+	}
 	if opts.ProxyURL != "" {
 		if parsed, err := url.Parse(opts.ProxyURL); err == nil {
 			return sanitizeProxyURL(parsed)
@@ -139,8 +148,8 @@ func proxyForRequest(req *http.Request, opts Options, client *http.Client) strin
 	if req == nil || client == nil {
 		return ""
 	}
-	tr, ok := client.Transport.(*http.Transport)
-	if !ok || tr.Proxy == nil {
+	tr := baseTransport(client.Transport)
+	if tr == nil || tr.Proxy == nil {
 		return ""
 	}
 	proxyURL, err := tr.Proxy(req)
@@ -150,6 +159,28 @@ func proxyForRequest(req *http.Request, opts Options, client *http.Client) strin
 	return sanitizeProxyURL(proxyURL)
 }
 
+// wrappingTransport is a RoundTripper layered over another one, such as the
+// digest and alt-svc transports.
+type wrappingTransport interface {
+	unwrap() http.RoundTripper
+}
+
+// baseTransport peels wrappers off rt until it reaches the *http.Transport
+// that owns the proxy setting.
+func baseTransport(rt http.RoundTripper) *http.Transport {
+	for rt != nil {
+		switch t := rt.(type) {
+		case *http.Transport:
+			return t
+		case wrappingTransport:
+			rt = t.unwrap()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
 func sanitizeProxyURL(proxyURL *url.URL) string {
 	if proxyURL == nil {
 		return ""
@@ -1,6 +1,8 @@
 package httpx
 
 import (
+	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/unkn0wn-root/resterm/internal/k8s"
@@ -57,3 +59,15 @@ func TestFormatK8sUsesNormalizedPodResolveOutput(t *testing.T) {
 		t.Fatalf("unexpected k8s format: got %q want %q", got, want)
 	}
 }
+
+func TestProxyForRequestSeesThroughWrappers(t *testing.T) {
+	proxy, _ := url.Parse("http://ops:pw@proxy:3128")
+	base := &http.Transport{Proxy: http.ProxyURL(proxy)}
+	client := &http.Client{Transport: &digestTransport{
+		base: &altSvcTransport{base: base},
+	}}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/", nil)
+	if got := proxyForRequest(req, Options{}, client); got != "http://proxy:3128" {
+		t.Fatalf("proxy = %q", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/unkn0wn-root/resterm/internal/diag"
 	"github.com/unkn0wn-root/resterm/internal/http/version"
+	"github.com/unkn0wn-root/resterm/internal/netproxy"
 	"github.com/unkn0wn-root/resterm/internal/tlsconfig"
 	"github.com/unkn0wn-root/resterm/internal/tunnel"
 )
@@ -23,6 +24,10 @@ const (
 )
 
 func (c *Client) buildHTTPClient(opts Options) (*http.Client, error) {
+	if opts.HTTPVersion == version.V3 {
+		return buildHTTP3Client(opts)
+	}
+
 	transport := newBaseTransport()
 	applyTransportHTTPVersion(transport, opts.HTTPVersion)
 
@@ -35,13 +40,24 @@ func (c *Client) buildHTTPClient(opts Options) (*http.Client, error) {
 	if err := applyTunnels(transport, opts); err != nil {
 		return nil, err
 	}
+	if err := applyUnixSocket(transport, opts); err != nil {
+		return nil, err
+	}
+	applyAddrOverrides(transport, opts)
 
+	if opts.HTTPVersion == version.AltSvc && http3Reachable(opts) {
+		rt, err := newAltSvcTransport(c.altSvc, transport, opts)
+		if err != nil {
+			return nil, err
+		}
+		return newHTTPClient(rt, opts), nil
+	}
 	return newHTTPClient(transport, opts), nil
 }
 
 func newBaseTransport() *http.Transport {
 	return &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: envProxy,
 		DialContext: (&net.Dialer{
 			Timeout:   defaultDialTimeout,
 			KeepAlive: defaultDialKeepAlive,
@@ -64,41 +80,51 @@ func applyTransportHTTPVersion(transport *http.Transport, v version.HTTP) {
 	}
 }
 
+// applyProxy leaves the environment's proxy in place unless the request
+// names one or brings credentials for it. Rules are looked up per request,
+// so redirects to another host get that host's proxy.
 func applyProxy(transport *http.Transport, opts Options) error {
-	if opts.ProxyURL == "" {
+	cfg := opts.ProxyConfig()
+	if !cfg.Explicit() && cfg.User == "" {
 		return nil
 	}
-
-	proxyURL, err := url.Parse(opts.ProxyURL)
-	if err != nil {
-		return diag.WrapAs(
-			diag.ClassProtocol,
-			err,
-			"parse proxy url",
-			diag.WithComponent(diag.ComponentHTTP),
-		)
+	if opts.ProxyURL != "" {
+		if _, err := netproxy.ParseURL(opts.ProxyURL); err != nil {
+			return diag.WrapAs(
+				diag.ClassProtocol,
+				err,
+				"parse proxy url",
+				diag.WithComponent(diag.ComponentHTTP),
+			)
+		}
+	}
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return cfg.Lookup(req.URL)
 	}
-	transport.Proxy = http.ProxyURL(proxyURL)
 	return nil
 }
 
 func applyTLS(transport *http.Transport, opts Options) error {
-	if !needsTLSConfig(opts) {
-		return nil
+	tlsCfg, err := buildTLSConfig(opts)
+	if err != nil || tlsCfg == nil {
+		return err
 	}
+	transport.TLSClientConfig = tlsCfg
+	return nil
+}
 
-	tlsCfg, err := tlsconfig.Build(tlsconfig.Files{
+// buildTLSConfig returns nil when the options leave TLS at its defaults.
+func buildTLSConfig(opts Options) (*tls.Config, error) {
+	if !needsTLSConfig(opts) {
+		return nil, nil
+	}
+	return tlsconfig.Build(tlsconfig.Files{
 		RootCAs:    opts.RootCAs,
 		RootMode:   opts.RootMode,
 		ClientCert: opts.ClientCert,
 		ClientKey:  opts.ClientKey,
 		Insecure:   opts.InsecureSkipVerify,
 	}, opts.BaseDir)
-	if err != nil {
-		return err
-	}
-	transport.TLSClientConfig = tlsCfg
-	return nil
 }
 
 func needsTLSConfig(opts Options) bool {
@@ -114,7 +140,7 @@ func applyTunnels(transport *http.Transport, opts Options) error {
 	if tunnel.HasConflict(sshOn, k8sOn) {
 		return diag.New(diag.ClassRoute, "ssh and k8s transports cannot be combined")
 	}
-	if opts.ProxyURL != "" && (sshOn || k8sOn) {
+	if opts.ProxyConfig().Explicit() && (sshOn || k8sOn) {
 		return diag.New(
 			diag.ClassRoute,
 			"proxy cannot be combined with ssh or k8s tunneling",
@@ -154,7 +180,26 @@ func applyTunnel(
 	return nil
 }
 
-func newHTTPClient(transport *http.Transport, opts Options) *http.Client {
+// applyUnixSocket sends every connection to the socket. The URL's host only
+// fills in the Host header, so routing it anywhere else makes no sense.
+func applyUnixSocket(transport *http.Transport, opts Options) error {
+	sock := unixSocketPath(opts)
+	if sock == "" {
+		return nil
+	}
+	if opts.ProxyConfig().Explicit() {
+		return diag.New(diag.ClassRoute, "proxy cannot be combined with a unix socket")
+	}
+	if (opts.SSH != nil && opts.SSH.Active()) || (opts.K8s != nil && opts.K8s.Active()) {
+		return diag.New(
+			diag.ClassRoute,
+			"unix socket cannot be combined with ssh or k8s tunneling",
+		)
+	}
+	return applyTunnel(transport, opts.HTTPVersion, "unix socket", unixDialer(sock))
+}
+
+func newHTTPClient(transport http.RoundTripper, opts Options) *http.Client {
 	client := &http.Client{Transport: transport, Jar: opts.CookieJar}
 	if opts.Timeout > 0 {
 		client.Timeout = opts.Timeout
@@ -0,0 +1,54 @@
+package httpx
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/tunnel"
+)
+
+// A unix:// URL names the socket and then, after a colon, the request path:
+// unix:///var/run/docker.sock:/v1.43/info. It is sent as a plain http request
+// to localhost, which is what daemons on a socket expect in the Host header.
+const (
+	unixScheme = "unix://"
+	unixHost   = "localhost"
+)
+
+// unixTarget rewrites a unix:// URL to the URL the request goes out with and
+// returns its socket path. Any other URL comes back as is.
+func unixTarget(raw string) (string, string, error) {
+	if len(raw) < len(unixScheme) || !strings.EqualFold(raw[:len(unixScheme)], unixScheme) {
+		return raw, "", nil
+	}
+	sock, path, _ := strings.Cut(raw[len(unixScheme):], ":")
+	if strings.TrimSpace(sock) == "" {
+		return "", "", diag.New(
+			diag.ClassProtocol,
+			"unix url "+raw+" has no socket path (use unix:///path/to.sock:/request/path)",
+			diag.WithComponent(diag.ComponentHTTP),
+		)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return "http://" + unixHost + path, sock, nil
+}
+
+func unixSocketPath(opts Options) string {
+	p := strings.TrimSpace(opts.UnixSocket)
+	if p == "" || filepath.IsAbs(p) || opts.BaseDir == "" {
+		return p
+	}
+	return filepath.Join(opts.BaseDir, p)
+}
+
+func unixDialer(sock string) tunnel.DialContextFunc {
+	d := &net.Dialer{Timeout: defaultDialTimeout}
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return d.DialContext(ctx, "unix", sock)
+	}
+}
@@ -0,0 +1,203 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+func startUnixServer(t *testing.T, h http.Handler) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "d.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(h)
+	srv.Listener = ln
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return dir, sock
+}
+
+func TestUnixTarget(t *testing.T) {
+	tests := []struct {
+		raw  string
+		url  string
+		sock string
+	}{
+		{
+			raw:  "unix:///var/run/docker.sock:/v1.43/info?all=1",
+			url:  "http://localhost/v1.43/info?all=1",
+			sock: "/var/run/docker.sock",
+		},
+		{raw: "UNIX:///run/d.sock", url: "http://localhost/", sock: "/run/d.sock"},
+		{raw: "unix://d.sock:events", url: "http://localhost/events", sock: "d.sock"},
+		{raw: "https://example.com/unix://x", url: "https://example.com/unix://x"},
+	}
+	for _, tc := range tests {
+		u, sock, err := unixTarget(tc.raw)
+		if err != nil {
+			t.Fatalf("unixTarget(%q): %v", tc.raw, err)
+		}
+		if u != tc.url || sock != tc.sock {
+			t.Fatalf("unixTarget(%q) = %q, %q; want %q, %q", tc.raw, u, sock, tc.url, tc.sock)
+		}
+	}
+	if _, _, err := unixTarget("unix://:/info"); err == nil ||
+		!strings.Contains(err.Error(), "no socket path") {
+		t.Fatalf("expected missing socket error, got %v", err)
+	}
+}
+
+func TestExecuteOverUnixSocketURL(t *testing.T) {
+	_, sock := startUnixServer(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, "%s %s", r.Host, r.URL.RequestURI())
+		}),
+	)
+
+	req := &restfile.Request{Method: http.MethodGet, URL: "unix://" + sock + ":/v1.43/info?all=1"}
+	resp, err := NewClient(nil).Execute(context.Background(), req, vars.NewResolver(), Options{})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := string(resp.Body); got != "localhost /v1.43/info?all=1" {
+		t.Fatalf("body = %q", got)
+	}
+}
+
+func TestExecuteUnixSocketSettingResolvesAgainstBaseDir(t *testing.T) {
+	dir, _ := startUnixServer(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, "%s %s", r.Host, r.URL.Path)
+		}),
+	)
+
+	req := &restfile.Request{
+		Method:   http.MethodGet,
+		URL:      "http://daemon/ping",
+		Settings: map[string]string{"http-unix-socket": "d.sock"},
+	}
+	opts := Options{BaseDir: dir}
+	resp, err := NewClient(nil).Execute(context.Background(), req, vars.NewResolver(), opts)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := string(resp.Body); got != "daemon /ping" {
+		t.Fatalf("body = %q", got)
+	}
+}
+
+func TestExecuteSSEOverUnixSocket(t *testing.T) {
+	_, sock := startUnixServer(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "event: status\ndata: running\n\n")
+		}),
+	)
+
+	req := &restfile.Request{
+		Method: http.MethodGet,
+		URL:    "unix://" + sock + ":/events",
+		SSE:    &restfile.SSERequest{},
+	}
+	resp, err := NewClient(nil).ExecuteSSE(context.Background(), req, vars.NewResolver(), Options{})
+	if err != nil {
+		t.Fatalf("execute sse: %v", err)
+	}
+	var transcript struct {
+		Events []struct {
+			Event string `json:"event"`
+			Data  string `json:"data"`
+		}
+	}
+	if err := json.Unmarshal(resp.Body, &transcript); err != nil {
+		t.Fatalf("unmarshal transcript: %v", err)
+	}
+	if len(transcript.Events) != 1 || transcript.Events[0].Data != "running" {
+		t.Fatalf("events = %+v", transcript.Events)
+	}
+}
+
+func TestExecuteWebSocketOverUnixSocket(t *testing.T) {
+	_, sock := startUnixServer(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := websocket.Accept(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close(websocket.StatusNormalClosure, "bye") }()
+			for {
+				typ, data, err := conn.Read(r.Context())
+				if err != nil {
+					return
+				}
+				if err := conn.Write(r.Context(), typ, data); err != nil {
+					return
+				}
+			}
+		}),
+	)
+
+	req := &restfile.Request{
+		Method:   http.MethodGet,
+		URL:      "ws://localhost/attach",
+		Settings: map[string]string{"http-unix-socket": sock},
+		WebSocket: &restfile.WebSocketRequest{
+			Options: restfile.WebSocketOptions{IdleTimeout: 500 * time.Millisecond},
+			Steps: []restfile.WebSocketStep{
+				{Type: restfile.WebSocketStepSendText, Value: "hello"},
+				{Type: restfile.WebSocketStepWait, Duration: 100 * time.Millisecond},
+				{Type: restfile.WebSocketStepClose, Code: 1000},
+			},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := NewClient(nil).ExecuteWebSocket(ctx, req, nil, Options{})
+	if err != nil {
+		t.Fatalf("execute websocket: %v", err)
+	}
+	var transcript struct {
+		Events []struct {
+			Direction string `json:"direction"`
+			Text      string `json:"text"`
+		}
+	}
+	if err := json.Unmarshal(resp.Body, &transcript); err != nil {
+		t.Fatalf("unmarshal transcript: %v", err)
+	}
+	for _, evt := range transcript.Events {
+		if evt.Direction == "receive" && evt.Text == "hello" {
+			return
+		}
+	}
+	t.Fatalf("expected echoed message in transcript: %+v", transcript.Events)
+}
+
+func TestBuildHTTPClientRejectsProxyWithUnixSocket(t *testing.T) {
+	opts := Options{ProxyURL: "http://localhost:8080", UnixSocket: "/run/d.sock"}
+	_, err := NewClient(nil).buildHTTPClient(opts)
+	if err == nil || !strings.Contains(err.Error(), "proxy cannot be combined with a unix socket") {
+		t.Fatalf("expected proxy+socket validation error, got %v", err)
+	}
+}
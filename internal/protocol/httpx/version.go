@@ -9,10 +9,16 @@ import (
 )
 
 func checkHTTPVersion(resp *http.Response, v version.HTTP) error {
-	if v != version.V2 {
+	var major int
+	switch v {
+	case version.V2:
+		major = 2
+	case version.V3:
+		major = 3
+	default:
 		return nil
 	}
-	if resp == nil || resp.ProtoMajor != 2 {
+	if resp == nil || resp.ProtoMajor != major {
 		proto := ""
 		if resp != nil {
 			proto = resp.Proto
@@ -20,25 +26,26 @@ func checkHTTPVersion(resp *http.Response, v version.HTTP) error {
 		if proto == "" {
 			proto = "unknown"
 		}
-		return diag.Newf(diag.ClassProtocol, "expected HTTP/2 response, got %s", proto)
+		return diag.Newf(diag.ClassProtocol, "expected HTTP/%d response, got %s", major, proto)
 	}
 	return nil
 }
 
 func checkHTTPVersionRequest(req *http.Request, v version.HTTP) error {
-	if v != version.V2 {
+	if req == nil || req.URL == nil || !strings.EqualFold(req.URL.Scheme, "http") {
 		return nil
 	}
-	if req == nil || req.URL == nil {
-		return nil
-	}
-	if strings.EqualFold(req.URL.Scheme, "http") {
+	switch v {
+	case version.V2:
 		return diag.Newf(
 			diag.ClassProtocol,
 			"http-version=2 requires https (h2c is not supported)",
 		)
+	case version.V3:
+		return diag.Newf(diag.ClassProtocol, "http-version=3 requires https")
+	default:
+		return nil
 	}
-	return nil
 }
 
 func checkWebSocketHTTPVersion(v version.HTTP) error {
@@ -53,6 +60,11 @@ func checkWebSocketHTTPVersion(v version.HTTP) error {
 			diag.ClassProtocol,
 			"http-version=2 is not supported for WebSocket requests",
 		)
+	case version.V3:
+		return diag.Newf(
+			diag.ClassProtocol,
+			"http-version=3 is not supported for WebSocket requests",
+		)
 	default:
 		return nil
 	}
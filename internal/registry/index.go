@@ -386,6 +386,36 @@ func (ix *Index) DefaultAuth(doc *restfile.Document) (*restfile.AuthProfile, boo
 	return &cp, true
 }
 
+func (ix *Index) NamedAuth(doc *restfile.Document, name string) (*restfile.AuthProfile, bool) {
+	if ix == nil {
+		ds := ixSplitAuth(doc)
+		if v, ok := findNamed(
+			ds.fs,
+			nameKey(name),
+			func(v restfile.AuthProfile) string { return v.Name },
+		); ok {
+			cp := cloneAuth(v)
+			return &cp, true
+		}
+		if v, ok := findNamed(
+			ds.gs,
+			nameKey(name),
+			func(v restfile.AuthProfile) string { return v.Name },
+		); ok {
+			cp := cloneAuth(v)
+			return &cp, true
+		}
+		return nil, false
+	}
+
+	v, ok := ix.auth.named(docPath(doc), docAuth(doc), name)
+	if !ok {
+		return nil, false
+	}
+	cp := cloneAuth(v)
+	return &cp, true
+}
+
 func cloneAuth(v restfile.AuthProfile) restfile.AuthProfile {
 	v.Spec = *v.Spec.Clone()
 	if v.Spec.SourcePath == "" {
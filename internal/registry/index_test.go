@@ -76,6 +76,42 @@ func TestIndexDefaultAuthUsesCurrentFileFirst(t *testing.T) {
 	}
 }
 
+func TestIndexNamedAuthIsNotDefault(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	defs := filepath.Join(dir, "defs.http")
+	if err := os.WriteFile(
+		defs,
+		[]byte("# @auth global name=viewer bearer viewer-token\n"),
+		0o644,
+	); err != nil {
+		t.Fatalf("write defs: %v", err)
+	}
+
+	ix := New()
+	ix.Load(dir, false)
+
+	src := "# @auth file name=admin bearer admin-token\n# @auth file bearer default-token\n\nGET https://example.com\n"
+	doc := parser.Parse(filepath.Join(dir, "use.http"), []byte(src))
+
+	pf, ok := ix.DefaultAuth(doc)
+	if !ok || pf.Spec.Params["token"] != "default-token" {
+		t.Fatalf("expected the unnamed profile as default, got %+v", pf)
+	}
+	pf, ok = ix.NamedAuth(doc, "ADMIN")
+	if !ok || pf.Spec.Params["token"] != "admin-token" {
+		t.Fatalf("expected admin profile, got %+v", pf)
+	}
+	pf, ok = ix.NamedAuth(doc, "viewer")
+	if !ok || pf.Spec.Params["token"] != "viewer-token" {
+		t.Fatalf("expected workspace viewer profile, got %+v", pf)
+	}
+	if _, ok := ix.NamedAuth(doc, "anonymous"); ok {
+		t.Fatalf("unexpected anonymous profile")
+	}
+}
+
 func TestIndexSSHUsesCurrentDocOverStoredFile(t *testing.T) {
 	t.Parallel()
 
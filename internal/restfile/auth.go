@@ -15,6 +15,7 @@ type AuthKind string
 const (
 	AuthBasic   AuthKind = "basic"
 	AuthBearer  AuthKind = "bearer"
+	AuthDigest  AuthKind = "digest"
 	AuthAPIKey  AuthKind = "apikey"
 	AuthHeader  AuthKind = "header"
 	AuthCommand AuthKind = "command"
@@ -30,6 +31,7 @@ const AuthDisableWord = "none"
 var authKeywords = map[AuthKind]struct{}{
 	AuthBasic:   {},
 	AuthBearer:  {},
+	AuthDigest:  {},
 	AuthAPIKey:  {},
 	AuthCommand: {},
 	AuthOAuth2:  {},
@@ -40,6 +40,9 @@ func (meta RequestMetadata) Clone() RequestMetadata {
 	meta.Profile = clonePtr(meta.Profile)
 	meta.Trace = meta.Trace.Clone()
 	meta.Compare = meta.Compare.Clone()
+	meta.Identities = meta.Identities.Clone()
+	meta.Contract = clonePtr(meta.Contract)
+	meta.Snapshot = meta.Snapshot.Clone()
 	return meta
 }
 
@@ -75,6 +78,26 @@ func (spec *CompareSpec) Clone() *CompareSpec {
 	}
 	dst := *spec
 	dst.Environments = slices.Clone(spec.Environments)
+	dst.Ignore = slices.Clone(spec.Ignore)
+	dst.Match = slices.Clone(spec.Match)
+	return &dst
+}
+
+func (spec *IdentitySpec) Clone() *IdentitySpec {
+	if spec == nil {
+		return nil
+	}
+	dst := *spec
+	dst.Names = slices.Clone(spec.Names)
+	return &dst
+}
+
+func (spec *SnapshotSpec) Clone() *SnapshotSpec {
+	if spec == nil {
+		return nil
+	}
+	dst := *spec
+	dst.Ignore = slices.Clone(spec.Ignore)
 	return &dst
 }
 
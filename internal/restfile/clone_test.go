@@ -20,7 +20,8 @@ func TestRequestCloneIsIndependent(t *testing.T) {
 			Trace: &TraceSpec{
 				Budgets: TraceBudget{Phases: map[string]time.Duration{"dns": time.Second}},
 			},
-			Compare: &CompareSpec{Environments: []string{"dev", "prod"}},
+			Compare:    &CompareSpec{Environments: []string{"dev", "prod"}},
+			Identities: &IdentitySpec{Names: []string{"admin", "viewer"}},
 		},
 		Body: BodySource{GraphQL: &GraphQLBody{Query: "query One"}},
 		GRPC: &GRPCRequest{Metadata: []MetadataPair{{Key: "x-id", Value: "one"}}},
@@ -43,6 +44,7 @@ func TestRequestCloneIsIndependent(t *testing.T) {
 	got.Metadata.Profile.Count = 2
 	got.Metadata.Trace.Budgets.Phases["dns"] = 2 * time.Second
 	got.Metadata.Compare.Environments[0] = "stage"
+	got.Metadata.Identities.Names[0] = "owner"
 	got.Body.GraphQL.Query = "query Two"
 	got.GRPC.Metadata[0].Value = "two"
 	got.WebSocket.Options.Subprotocols[0] = "other"
@@ -60,6 +62,7 @@ func TestRequestCloneIsIndependent(t *testing.T) {
 		req.Metadata.Profile.Count != 1 ||
 		req.Metadata.Trace.Budgets.Phases["dns"] != time.Second ||
 		req.Metadata.Compare.Environments[0] != "dev" ||
+		req.Metadata.Identities.Names[0] != "admin" ||
 		req.Body.GraphQL.Query != "query One" ||
 		req.GRPC.Metadata[0].Value != "one" ||
 		req.WebSocket.Options.Subprotocols[0] != "chat" ||
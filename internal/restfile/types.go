@@ -328,6 +328,9 @@ type RequestMetadata struct {
 	Profile               *ProfileSpec
 	Trace                 *TraceSpec
 	Compare               *CompareSpec
+	Identities            *IdentitySpec
+	Contract              *ContractSpec
+	Snapshot              *SnapshotSpec
 }
 
 type ProfileSpec struct {
@@ -347,10 +350,42 @@ type TraceBudget struct {
 	Phases    map[string]time.Duration
 }
 
+// CompareSpec is a parsed @compare. Ignore holds JSONPath rules for body
+// fields left out of the diff, and Match the key fields arrays of objects are
+// matched by, both as written.
 type CompareSpec struct {
 	Environments []string
 	Baseline     string
 	Group        string
+	Ignore       []string
+	Match        []string
+}
+
+// AnonymousIdentity runs an @as row without credentials unless a profile of
+// that name exists.
+const AnonymousIdentity = "anonymous"
+
+// IdentitySpec lists the named @auth profiles an @as request runs under, in
+// row order. Baseline is the row the others are diffed against.
+type IdentitySpec struct {
+	Names    []string
+	Baseline string
+}
+
+// ContractSpec checks the response against an OpenAPI spec. An empty Spec
+// uses the run-wide --contract spec, and Disabled opts a request out of it.
+type ContractSpec struct {
+	Spec      string
+	Operation string
+	Disabled  bool
+}
+
+// SnapshotSpec compares the response body with a golden file. An empty Path
+// uses __snapshots__/<request name>.json beside the request file. Ignore lists
+// JSONPath-style paths whose values are masked before comparing.
+type SnapshotSpec struct {
+	Path   string
+	Ignore []string
 }
 
 type CaptureExprMode uint8
@@ -0,0 +1,104 @@
+// Package restimport holds what the collection importers share when they turn
+// another tool's requests into resterm ones.
+package restimport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const boundaryPrefix = "resterm-"
+
+// Part is one multipart field. File is a source path and replaces Value.
+type Part struct {
+	Name        string
+	Value       string
+	File        string
+	ContentType string
+}
+
+// Multipart writes the body the way the curl importer does: file parts
+// reference their source with @path, which resterm reads at send time. The
+// boundary is derived from the parts so a re-import writes the same file.
+func Multipart(parts []Part) (body, boundary string) {
+	if len(parts) == 0 {
+		return "", ""
+	}
+
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p.Name + "\x00" + p.Value + "\x00" + p.File + "\x00" + p.ContentType + "\x00"))
+	}
+	boundary = boundaryPrefix + hex.EncodeToString(h.Sum(nil)[:12])
+
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString("--" + boundary + "\r\n")
+		b.WriteString(`Content-Disposition: form-data; name="` + escapeQuotes(p.Name) + `"`)
+		if p.File != "" {
+			b.WriteString(`; filename="` + escapeQuotes(filepath.Base(p.File)) + `"`)
+		}
+		b.WriteString("\r\n")
+		if p.ContentType != "" {
+			b.WriteString("Content-Type: " + p.ContentType + "\r\n")
+		}
+		b.WriteString("\r\n")
+		if p.File != "" {
+			b.WriteString("@" + p.File)
+		} else {
+			b.WriteString(p.Value)
+		}
+		b.WriteString("\r\n")
+	}
+	b.WriteString("--" + boundary + "--\r\n")
+	return b.String(), boundary
+}
+
+// FormEscape encodes a form value but leaves {{...}} references alone so they
+// still resolve when the request is sent.
+func FormEscape(s string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "{{")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(s[i:], "}}")
+		if j < 0 {
+			break
+		}
+		b.WriteString(url.QueryEscape(s[:i]))
+		b.WriteString(s[i : i+j+2])
+		s = s[i+j+2:]
+	}
+	b.WriteString(url.QueryEscape(s))
+	return b.String()
+}
+
+// SetDefaultHeader sets name unless the request already has it in any case.
+func SetDefaultHeader(h http.Header, name, value string) {
+	for k := range h {
+		if strings.EqualFold(k, name) {
+			return
+		}
+	}
+	h[name] = []string{value}
+}
+
+// DelHeader removes name in any case, as imported headers keep the case they
+// were written in.
+func DelHeader(h http.Header, name string) {
+	for k := range h {
+		if strings.EqualFold(k, name) {
+			delete(h, k)
+		}
+	}
+}
+
+func escapeQuotes(v string) string {
+	return strings.ReplaceAll(v, `"`, `\"`)
+}
@@ -0,0 +1,56 @@
+package restimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func TestFormEscapeKeepsTemplates(t *testing.T) {
+	got := FormEscape("a b&{{token}}=c {{unclosed")
+	if want := "a+b%26{{token}}%3Dc+%7B%7Bunclosed"; got != want {
+		t.Fatalf("FormEscape = %q, want %q", got, want)
+	}
+}
+
+func TestMultipartBoundaryFollowsParts(t *testing.T) {
+	parts := []Part{
+		{Name: "note", Value: "hi"},
+		{Name: "doc", File: "files/a.pdf", ContentType: "application/pdf"},
+	}
+	body, boundary := Multipart(parts)
+	if again, b2 := Multipart(parts); again != body || b2 != boundary {
+		t.Fatal("the same parts should give the same body")
+	}
+	if !strings.Contains(body, `name="doc"; filename="a.pdf"`+"\r\nContent-Type: application/pdf\r\n\r\n@files/a.pdf") {
+		t.Fatalf("body = %q", body)
+	}
+	if _, other := Multipart(parts[:1]); other == boundary {
+		t.Fatal("different parts should give a different boundary")
+	}
+	if body, boundary := Multipart(nil); body != "" || boundary != "" {
+		t.Fatalf("empty parts = %q, %q", body, boundary)
+	}
+}
+
+func TestScopeCopiesOnEachStep(t *testing.T) {
+	root := Scope[string]{}.Folder("basic", true, []restfile.Variable{{Name: "a"}})
+	left := root.Tag("left").Folder("", false, []restfile.Variable{{Name: "b"}})
+	right := root.Tag("right")
+
+	var req restfile.Request
+	left.Apply(&req)
+	if strings.Join(req.Metadata.Tags, ",") != "left" || len(req.Variables) != 2 {
+		t.Fatalf("left = %+v / %+v", req.Metadata.Tags, req.Variables)
+	}
+	if len(right.Tags) != 1 || right.Tags[0] != "right" || len(right.Vars) != 1 {
+		t.Fatalf("right = %+v", right)
+	}
+	if got := left.AuthFor("", false); got != "basic" {
+		t.Fatalf("inherited auth = %q", got)
+	}
+	if got := left.AuthFor("bearer", true); got != "bearer" {
+		t.Fatalf("own auth = %q", got)
+	}
+}
@@ -0,0 +1,49 @@
+package restimport
+
+import "github.com/unkn0wn-root/resterm/internal/restfile"
+
+// Scope is what a folder passes down to the requests below it. A is the
+// importer's own auth value: a folder or request without auth of its own
+// takes the nearest one above it. Each step copies the slices, so sibling
+// folders never share them.
+type Scope[A any] struct {
+	Tags []string
+	Auth A
+	Vars []restfile.Variable
+}
+
+// Tag returns the scope inside a folder tagged tag. An empty tag adds nothing.
+func (s Scope[A]) Tag(tag string) Scope[A] {
+	if tag != "" {
+		s.Tags = append(append([]string(nil), s.Tags...), tag)
+	}
+	return s
+}
+
+// Folder returns the scope under a folder's own settings. set reports whether
+// the folder has auth of its own.
+func (s Scope[A]) Folder(auth A, set bool, vars []restfile.Variable) Scope[A] {
+	if set {
+		s.Auth = auth
+	}
+	if len(vars) > 0 {
+		s.Vars = append(append([]restfile.Variable(nil), s.Vars...), vars...)
+	}
+	return s
+}
+
+// AuthFor is the auth a request uses: its own when set, the folder's
+// otherwise.
+func (s Scope[A]) AuthFor(own A, set bool) A {
+	if set {
+		return own
+	}
+	return s.Auth
+}
+
+// Apply gives req the folder tags and the folder variables, ahead of any the
+// request adds itself.
+func (s Scope[A]) Apply(req *restfile.Request) {
+	req.Metadata.Tags = append([]string(nil), s.Tags...)
+	req.Variables = append(req.Variables, s.Vars...)
+}
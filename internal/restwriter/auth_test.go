@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/unkn0wn-root/resterm/internal/directive"
 	"github.com/unkn0wn-root/resterm/internal/parser"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 )
@@ -23,6 +24,12 @@ func TestRenderRoundTripsEveryAuthForm(t *testing.T) {
 			want:   restfile.AuthBasic,
 			params: map[string]string{"username": "user", "password": "pass"},
 		},
+		{
+			name:   "digest",
+			source: "# @auth digest user pass",
+			want:   restfile.AuthDigest,
+			params: map[string]string{"username": "user", "password": "pass"},
+		},
 		{
 			name:   "bearer",
 			source: "# @auth bearer tok-123",
@@ -123,7 +130,7 @@ func TestRenderRejectsAuthItCannotWrite(t *testing.T) {
 	}{
 		{
 			name: "unsupported type",
-			auth: restfile.AuthSpec{Type: "digest", Params: map[string]string{"user": "u"}},
+			auth: restfile.AuthSpec{Type: "ntlm", Params: map[string]string{"user": "u"}},
 		},
 		{
 			name: "custom header with no name",
@@ -161,7 +168,7 @@ func TestRenderRejectsAuthItCannotWrite(t *testing.T) {
 // a different way.
 func TestRenderRejectsReservedCustomHeaderNames(t *testing.T) {
 	reserved := []string{
-		"basic", "bearer", "apikey", "api-key", "oauth2", "command",
+		"basic", "bearer", "digest", "apikey", "api-key", "oauth2", "command",
 		"none", "request", "file", "global",
 		"Bearer", "BASIC", "None", "File", "Api-Key",
 	}
@@ -187,7 +194,7 @@ func TestRenderRejectsReservedCustomHeaderNames(t *testing.T) {
 
 // "header" is the only kind with no keyword of its own, so it stays usable.
 func TestRenderKeepsHeaderNamesThatAreNotReserved(t *testing.T) {
-	names := []string{"X-Release-Auth", "Authorization", "X-API-Token", "x-custom", "header", "digest", "token"}
+	names := []string{"X-Release-Auth", "Authorization", "X-API-Token", "x-custom", "header", "ntlm", "token"}
 	values := []string{"secret", "user pass", "a b c"}
 
 	for _, name := range names {
@@ -217,3 +224,52 @@ func headerAuthDoc(name, value string) *restfile.Document {
 		}},
 	}}}
 }
+
+func TestRenderRoundTripsAuthProfilesAndNone(t *testing.T) {
+	src := strings.Join([]string{
+		"# @auth file bearer {{token}}",
+		"# @auth global name=admin basic root secret",
+		"",
+		"### open",
+		"# @name open",
+		"# @auth none",
+		"GET https://example.com/health",
+		"",
+	}, "\n")
+	doc := parser.Parse("r.http", []byte(src))
+	if len(doc.Errors) != 0 {
+		t.Fatalf("source did not parse: %v", doc.Errors)
+	}
+
+	out := mustRender(t, doc)
+	back := parser.Parse("r.http", []byte(out))
+	if len(back.Errors) != 0 {
+		t.Fatalf("rendered document did not parse: %v\n%s", back.Errors, out)
+	}
+	if len(back.Auth) != 2 {
+		t.Fatalf("auth profiles = %d, want 2:\n%s", len(back.Auth), out)
+	}
+	if p := back.Auth[0]; p.Name != "" || p.Spec.Kind() != restfile.AuthBearer || p.Spec.Params["token"] != "{{token}}" {
+		t.Fatalf("file profile = %+v:\n%s", p, out)
+	}
+	if p := back.Auth[1]; p.Name != "admin" || p.Spec.Kind() != restfile.AuthBasic {
+		t.Fatalf("named profile = %+v:\n%s", p, out)
+	}
+	if !back.Requests[0].Metadata.AuthDisabled {
+		t.Fatalf("@auth none was dropped:\n%s", out)
+	}
+	if again := mustRender(t, back); again != out {
+		t.Fatalf("render is not idempotent:\nfirst:\n%s\nsecond:\n%s", out, again)
+	}
+}
+
+func TestRenderRejectsRequestScopedAuthProfile(t *testing.T) {
+	doc := &restfile.Document{Auth: []restfile.AuthProfile{{
+		Scope: directive.ScopeRequest,
+		Name:  "x",
+		Spec:  restfile.AuthSpec{Type: restfile.AuthBearer, Params: map[string]string{"token": "t"}},
+	}}}
+	if out, err := Render(doc, Options{}); err == nil {
+		t.Fatalf("Render accepted a request-scoped profile:\n%s", out)
+	}
+}
@@ -0,0 +1,65 @@
+package restwriter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvFileName is the environment file resterm picks up next to .http files.
+const EnvFileName = "resterm.env.json"
+
+// Environment is one named entry of an env file. Nested values are kept as
+// objects, which resterm flattens to dotted names when it loads them.
+type Environment struct {
+	Name   string
+	Values map[string]any
+}
+
+// MergeEnvironments adds each environment to the env file at path, replacing
+// one of the same name and keeping every other entry as it was.
+func MergeEnvironments(path string, envs []Environment) error {
+	if len(envs) == 0 {
+		return nil
+	}
+	existing := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("writer: read %s: %w", path, err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+	default:
+		return fmt.Errorf("writer: read %s: %w", path, err)
+	}
+
+	for _, env := range envs {
+		name := strings.TrimSpace(env.Name)
+		vals := env.Values
+		if vals == nil {
+			vals = map[string]any{}
+		}
+		raw, err := json.Marshal(vals)
+		if err != nil {
+			return fmt.Errorf("writer: encode environment %q: %w", name, err)
+		}
+		existing[name] = raw
+	}
+
+	out, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return fmt.Errorf("writer: encode %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("writer: create directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writer: write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package restwriter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/directive"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+// renderGraphQL writes the directives that switch the body collector into
+// GraphQL mode. The query and variables themselves follow the request line.
+func renderGraphQL(w directiveWriter, gql *restfile.GraphQLBody) {
+	if gql == nil {
+		return
+	}
+	w.line(directive.GraphQL, "")
+	if op := strings.TrimSpace(gql.OperationName); op != "" {
+		w.line(directive.GraphQLOperation, op)
+	}
+}
+
+// The parser reads the body as the query until @variables switches it over,
+// so the variables block has to come last.
+func renderGraphQLBody(w directiveWriter, gql *restfile.GraphQLBody) {
+	if f := strings.TrimSpace(gql.QueryFile); f != "" {
+		fmt.Fprintf(w.b, "< %s\n", f)
+	} else if q := strings.TrimSpace(gql.Query); q != "" {
+		w.b.WriteString(q)
+		w.b.WriteString("\n")
+	}
+	switch {
+	case strings.TrimSpace(gql.VariablesFile) != "":
+		w.b.WriteString("\n")
+		w.line(directive.Variables, "< "+strings.TrimSpace(gql.VariablesFile))
+	case strings.TrimSpace(gql.Variables) != "":
+		w.b.WriteString("\n")
+		w.line(directive.Variables, "")
+		w.b.WriteString(strings.TrimSpace(gql.Variables))
+		w.b.WriteString("\n")
+	}
+}
+
+func renderGRPC(w directiveWriter, g *restfile.GRPCRequest) error {
+	if g == nil {
+		return nil
+	}
+	method := strings.TrimPrefix(strings.TrimSpace(g.FullMethod), "/")
+	if method == "" && g.Service != "" && g.Method != "" {
+		method = g.Service + "/" + g.Method
+		if g.Package != "" {
+			method = g.Package + "." + method
+		}
+	}
+	if method == "" {
+		return fmt.Errorf("grpc request to %q has no method", g.Target)
+	}
+	w.line(directive.GRPC, method)
+	if d := strings.TrimSpace(g.DescriptorSet); d != "" {
+		w.line(directive.GRPCDescriptor, d)
+	}
+	if !g.UseReflection {
+		w.line(directive.GRPCReflection, "false")
+	}
+	if on, ok := g.Plaintext.Get(); ok {
+		w.line(directive.GRPCPlaintext, fmt.Sprint(on))
+	}
+	if a := strings.TrimSpace(g.Authority); a != "" {
+		w.line(directive.GRPCAuthority, a)
+	}
+	for _, md := range g.Metadata {
+		w.line(directive.GRPCMetadata, md.Key+": "+md.Value)
+	}
+	return nil
+}
+
+// grpcBody falls back to the message fields when the caller only filled in the
+// gRPC request, which is how the parser itself mirrors them.
+func grpcBody(req *restfile.Request) restfile.BodySource {
+	body := req.Body
+	if body.FilePath == "" && strings.TrimSpace(body.Text) == "" {
+		body.FilePath = req.GRPC.MessageFile
+		if body.FilePath == "" {
+			body.Text = req.GRPC.Message
+		}
+	}
+	return body
+}
@@ -0,0 +1,85 @@
+package restwriter
+
+import (
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func parseOne(t *testing.T, req *restfile.Request) *restfile.Request {
+	t.Helper()
+	out := mustRender(t, &restfile.Document{Requests: []*restfile.Request{req}})
+	back := parser.Parse("r.http", []byte(out))
+	if len(back.Errors) != 0 || len(back.Requests) != 1 {
+		t.Fatalf("rendered document did not parse: %v\n%s", back.Errors, out)
+	}
+	return back.Requests[0]
+}
+
+func TestRenderRoundTripsGraphQL(t *testing.T) {
+	req := testRequest()
+	req.Body = restfile.BodySource{GraphQL: &restfile.GraphQLBody{
+		Query:         "query User($id: ID!) {\n  user(id: $id) { name }\n}",
+		Variables:     "{\n  \"id\": 1\n}",
+		OperationName: "User",
+	}}
+	got := parseOne(t, req).Body.GraphQL
+	if got == nil {
+		t.Fatalf("request lost its GraphQL body")
+	}
+	want := req.Body.GraphQL
+	if got.Query != want.Query || got.Variables != want.Variables || got.OperationName != want.OperationName {
+		t.Fatalf("graphql = %+v, want %+v", got, want)
+	}
+
+	req.Body.GraphQL = &restfile.GraphQLBody{QueryFile: "q/user.graphql", VariablesFile: "q/vars.json"}
+	got = parseOne(t, req).Body.GraphQL
+	if got == nil || got.QueryFile != "q/user.graphql" || got.VariablesFile != "q/vars.json" {
+		t.Fatalf("graphql files = %+v", got)
+	}
+}
+
+func TestRenderRoundTripsGRPC(t *testing.T) {
+	req := &restfile.Request{
+		Method:   "GRPC",
+		URL:      "localhost:50051",
+		Metadata: restfile.RequestMetadata{Name: "Say"},
+		GRPC: &restfile.GRPCRequest{
+			Target:        "localhost:50051",
+			FullMethod:    "/demo.v1.Greeter/Say",
+			DescriptorSet: "protos/demo.pb",
+			Plaintext:     restfile.OptOf(true),
+			Authority:     "greeter.local",
+			Message:       "{\"name\": \"ada\"}",
+			Metadata:      []restfile.MetadataPair{{Key: "x-trace", Value: "{{trace}}"}},
+		},
+	}
+	got := parseOne(t, req)
+	g := got.GRPC
+	if g == nil {
+		t.Fatalf("request lost its gRPC settings")
+	}
+	if g.Target != "localhost:50051" || g.FullMethod != "/demo.v1.Greeter/Say" || g.Package != "demo.v1" {
+		t.Fatalf("grpc target/method = %+v", g)
+	}
+	if g.UseReflection || g.DescriptorSet != "protos/demo.pb" || g.Authority != "greeter.local" {
+		t.Fatalf("grpc options = %+v", g)
+	}
+	if on, ok := g.Plaintext.Get(); !ok || !on {
+		t.Fatalf("plaintext = %+v", g.Plaintext)
+	}
+	if len(g.Metadata) != 1 || g.Metadata[0] != req.GRPC.Metadata[0] {
+		t.Fatalf("metadata = %+v", g.Metadata)
+	}
+	if g.Message != req.GRPC.Message || got.Body.Text != req.GRPC.Message {
+		t.Fatalf("message = %q, body = %q", g.Message, got.Body.Text)
+	}
+}
+
+func TestRenderRejectsGRPCWithoutMethod(t *testing.T) {
+	req := &restfile.Request{Method: "GRPC", GRPC: &restfile.GRPCRequest{Target: "localhost:50051"}}
+	if _, err := Render(&restfile.Document{Requests: []*restfile.Request{req}}, Options{}); err == nil {
+		t.Fatalf("Render accepted a gRPC request without a method")
+	}
+}
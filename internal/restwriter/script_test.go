@@ -0,0 +1,49 @@
+package restwriter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func TestRenderRoundTripsScripts(t *testing.T) {
+	req := testRequest()
+	req.Metadata.Scripts = []restfile.ScriptBlock{
+		{Kind: "pre-request", Lang: "js", Body: "vars.set(\"a\", 1);\n\nrequest.setHeader(\"X\", \"y\");"},
+		{Kind: "test", Lang: "rts", Body: "assert response.statusCode == 200"},
+		{Kind: "test", Lang: "js", FilePath: "scripts/check.js"},
+	}
+	out := mustRender(t, &restfile.Document{Requests: []*restfile.Request{req}})
+
+	back := parser.Parse("r.http", []byte(out))
+	if len(back.Errors) != 0 {
+		t.Fatalf("rendered document did not parse: %v\n%s", back.Errors, out)
+	}
+	got := back.Requests[0].Metadata.Scripts
+	if len(got) != len(req.Metadata.Scripts) {
+		t.Fatalf("scripts = %d, want %d:\n%s", len(got), len(req.Metadata.Scripts), out)
+	}
+	for i, want := range req.Metadata.Scripts {
+		g := got[i]
+		if g.Kind != want.Kind || g.Lang != want.Lang || g.Body != want.Body || g.FilePath != want.FilePath {
+			t.Fatalf("script %d = %+v, want %+v:\n%s", i, g, want, out)
+		}
+	}
+	if back.Requests[0].Body.Text != req.Body.Text {
+		t.Fatalf("body = %q, want %q:\n%s", back.Requests[0].Body.Text, req.Body.Text, out)
+	}
+}
+
+func TestRenderRejectsScriptLineReadAsInclude(t *testing.T) {
+	req := testRequest()
+	req.Metadata.Scripts = []restfile.ScriptBlock{{Kind: "test", Body: "  < other.js"}}
+	out, err := Render(&restfile.Document{Requests: []*restfile.Request{req}}, Options{})
+	if err == nil {
+		t.Fatalf("Render accepted a line that reads back as an include:\n%s", out)
+	}
+	if !strings.Contains(err.Error(), "@script") {
+		t.Fatalf("error = %v, want it to name @script", err)
+	}
+}
@@ -81,13 +81,16 @@ func Render(doc *restfile.Document, opts Options) (string, error) {
 	renderHeader(w.b, opts.HeaderComment)
 	renderScopeVariables(w, doc.Variables)
 	renderScopeVariables(w, doc.Globals)
+	if err := renderAuthProfiles(w, doc.Auth); err != nil {
+		return "", err
+	}
 	renderSettings(w, doc.Settings)
 	if err := renderPatches(w, doc.Patches); err != nil {
 		return "", err
 	}
 
 	// Without this the preamble reads as part of the block below it.
-	if len(doc.Variables)+len(doc.Globals)+len(doc.Settings)+len(doc.Patches) > 0 {
+	if len(doc.Variables)+len(doc.Globals)+len(doc.Auth)+len(doc.Settings)+len(doc.Patches) > 0 {
 		b.WriteString("\n")
 	}
 
@@ -176,7 +179,9 @@ func renderRequest(w directiveWriter, req *restfile.Request) error {
 	renderDescription(w, req.Metadata.Description)
 	renderTags(w, req.Metadata.Tags)
 	renderLoggingDirectives(w, req.Metadata)
-	if err := renderAuth(w, req.Metadata.Auth); err != nil {
+	if req.Metadata.AuthDisabled {
+		w.line(directive.Auth, restfile.AuthDisableWord)
+	} else if err := renderAuth(w, req.Metadata.Auth); err != nil {
 		return err
 	}
 	renderSettings(w, req.Settings)
@@ -187,15 +192,30 @@ func renderRequest(w directiveWriter, req *restfile.Request) error {
 	writeEach(w, req.Metadata.Captures, captureArg)
 	writeEach(w, req.Metadata.Asserts, assertArg)
 	renderBodyOptions(w, req)
+	renderGraphQL(w, req.Body.GraphQL)
+	if err := renderGRPC(w, req.GRPC); err != nil {
+		return err
+	}
+	if err := renderScripts(w, req.Metadata.Scripts); err != nil {
+		return err
+	}
 
 	w.b.WriteString(reqLine(req))
 	renderHeaders(w.b, req.Headers)
 	w.b.WriteString("\n")
-	if req.Body.FilePath != "" {
-		fmt.Fprintf(w.b, "< %s\n", strings.TrimSpace(req.Body.FilePath))
-	} else if strings.TrimSpace(req.Body.Text) != "" {
-		w.b.WriteString(req.Body.Text)
-		if !strings.HasSuffix(req.Body.Text, "\n") {
+	if req.Body.GraphQL != nil {
+		renderGraphQLBody(w, req.Body.GraphQL)
+		return nil
+	}
+	body := req.Body
+	if req.GRPC != nil {
+		body = grpcBody(req)
+	}
+	if body.FilePath != "" {
+		fmt.Fprintf(w.b, "< %s\n", strings.TrimSpace(body.FilePath))
+	} else if strings.TrimSpace(body.Text) != "" {
+		w.b.WriteString(body.Text)
+		if !strings.HasSuffix(body.Text, "\n") {
 			w.b.WriteString("\n")
 		}
 	}
@@ -269,14 +289,73 @@ func renderAuth(w directiveWriter, auth *restfile.AuthSpec) error {
 	return nil
 }
 
+// Script lines are written with the ">" marker rather than as a {% %} block so
+// a body line never has to be checked against the block terminator. Adjacent
+// blocks of the same kind and language would be read back as one, which runs
+// the same code either way.
+func renderScripts(w directiveWriter, scripts []restfile.ScriptBlock) error {
+	for _, s := range scripts {
+		arg := strings.TrimSpace(s.Kind)
+		if arg == "" {
+			arg = "test"
+		}
+		if lang := strings.ToLower(strings.TrimSpace(s.Lang)); lang != "" && lang != "js" && lang != "javascript" {
+			arg += " lang=" + directive.Quote(lang)
+		}
+		if path := strings.TrimSpace(s.FilePath); path != "" {
+			w.line(directive.Script, arg)
+			fmt.Fprintf(w.b, "> < %s\n", path)
+			continue
+		}
+		if strings.TrimSpace(s.Body) == "" {
+			continue
+		}
+		w.line(directive.Script, arg)
+		for line := range strings.SplitSeq(s.Body, "\n") {
+			line = strings.TrimRight(line, " \t\r")
+			if strings.HasPrefix(strings.TrimSpace(line), "<") {
+				return fmt.Errorf("writer: @script line %q would be read back as a file include", line)
+			}
+			if line == "" {
+				w.b.WriteString(">\n")
+				continue
+			}
+			w.b.WriteString("> ")
+			w.b.WriteString(line)
+			w.b.WriteString("\n")
+		}
+	}
+	return nil
+}
+
+// A profile names its scope first so the parser does not attach it to the
+// request that follows, and name= only ever follows an explicit scope.
+func renderAuthProfiles(w directiveWriter, profiles []restfile.AuthProfile) error {
+	for _, p := range profiles {
+		if p.Scope != directive.ScopeFile && p.Scope != directive.ScopeGlobal {
+			return fmt.Errorf("writer: @auth profile %q needs file or global scope", p.Name)
+		}
+		args, err := authArgs(p.Spec)
+		if err != nil {
+			return err
+		}
+		head := []string{p.Scope.String()}
+		if name := strings.TrimSpace(p.Name); name != "" {
+			head = append(head, "name="+directive.Quote(name))
+		}
+		w.line(directive.Auth, strings.Join(append(head, args...), " "))
+	}
+	return nil
+}
+
 // Every form names itself first and then its parameters in the order @auth
 // reads them back. A form with no case here fails the render rather than being
 // dropped, because dropping it would send the request unauthenticated.
 func authArgs(auth restfile.AuthSpec) ([]string, error) {
 	p := auth.Params
 	switch kind := auth.Kind(); kind {
-	case restfile.AuthBasic:
-		return []string{"basic", strings.TrimSpace(p["username"]), strings.TrimSpace(p["password"])}, nil
+	case restfile.AuthBasic, restfile.AuthDigest:
+		return []string{kind.String(), strings.TrimSpace(p["username"]), strings.TrimSpace(p["password"])}, nil
 	case restfile.AuthBearer:
 		return []string{"bearer", strings.TrimSpace(p["token"])}, nil
 	case restfile.AuthAPIKey:
@@ -431,6 +510,13 @@ func renderRequestVariables(w directiveWriter, vars []restfile.Variable) {
 }
 
 func reqLine(req *restfile.Request) string {
+	if req.GRPC != nil {
+		target := strings.TrimSpace(req.GRPC.Target)
+		if target == "" {
+			target = strings.TrimSpace(req.URL)
+		}
+		return fmt.Sprintf("GRPC %s\n", target)
+	}
 	m := strings.ToUpper(strings.TrimSpace(req.Method))
 	if m == "" {
 		m = "GET"
@@ -0,0 +1,245 @@
+package stdlib
+
+import (
+	"encoding/json"
+
+	"github.com/unkn0wn-root/resterm/internal/rts"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
+)
+
+const (
+	sigCompareEqual = "compare.equal(a[, b][, opts])"
+	sigCompareDiff  = "compare.diff(a[, b][, opts])"
+)
+
+var compareSpec = nsSpec{name: "compare", top: true, fns: map[string]rts.NativeFunc{
+	"equal": (&compareRow{}).equal,
+	"diff":  (&compareRow{}).diff,
+}}
+
+// CompareRowInput is what one row of a compare run knows about its baseline.
+// Rules come from the request's @compare ignore= and match= options.
+type CompareRowInput struct {
+	Rules semdiff.Options
+	// Baseline names the baseline row, for error messages.
+	Baseline string
+	// IsBaseline is set on the baseline row itself.
+	IsBaseline bool
+	// Body and ContentType are the baseline response once it has run.
+	Body        []byte
+	ContentType string
+	HasBody     bool
+}
+
+// CompareRow builds the compare namespace bound in a compare run row. It
+// shadows the standard one: a single argument is compared with the baseline
+// response, and the @compare rules apply when no opts are given.
+func CompareRow(in CompareRowInput) rts.Value {
+	row := &compareRow{in: in, bound: true}
+	if in.HasBody {
+		row.base = decodeBody(in.Body, in.ContentType)
+	}
+	m := mkFns("compare", map[string]rts.NativeFunc{
+		"equal": row.equal,
+		"diff":  row.diff,
+	})
+	return rts.Obj(&compareObj{objMap: objMap{name: "compare", m: m}, row: row})
+}
+
+// compareObj adds compare.baseline, the decoded baseline body or null before
+// the baseline has run. It is converted when read so RTS limits apply.
+type compareObj struct {
+	objMap
+	row *compareRow
+}
+
+func (o *compareObj) Member(ctx *rts.Ctx, pos rts.Pos, name string) (rts.Value, bool, error) {
+	if name != "baseline" {
+		return o.objMap.Member(ctx, pos, name)
+	}
+	if !o.row.in.HasBody {
+		return rts.Null(), true, nil
+	}
+	v, err := rts.FromIface(ctx, pos, plainJSON(o.row.base))
+	return v, true, err
+}
+
+type compareRow struct {
+	in    CompareRowInput
+	bound bool
+	base  any
+}
+
+func (r *compareRow) equal(ctx *rts.Ctx, pos rts.Pos, args []rts.Value) (rts.Value, error) {
+	changes, err := r.run(ctx, pos, args, sigCompareEqual)
+	if err != nil {
+		return rts.Null(), err
+	}
+	return rts.Bool(len(changes) == 0), nil
+}
+
+// diff lists changes as dicts with path, kind, old and new.
+func (r *compareRow) diff(ctx *rts.Ctx, pos rts.Pos, args []rts.Value) (rts.Value, error) {
+	changes, err := r.run(ctx, pos, args, sigCompareDiff)
+	if err != nil {
+		return rts.Null(), err
+	}
+	if ctx != nil && ctx.Lim.MaxList > 0 && len(changes) > ctx.Lim.MaxList {
+		changes = changes[:ctx.Lim.MaxList]
+	}
+	out := make([]rts.Value, 0, len(changes))
+	for _, c := range changes {
+		oldV, err := rts.FromIface(ctx, pos, plainJSON(c.Old))
+		if err != nil {
+			return rts.Null(), err
+		}
+		newV, err := rts.FromIface(ctx, pos, plainJSON(c.New))
+		if err != nil {
+			return rts.Null(), err
+		}
+		out = append(out, rts.Dict(map[string]rts.Value{
+			"path": rts.Str(c.Path),
+			"kind": rts.Str(string(c.Kind)),
+			"old":  oldV,
+			"new":  newV,
+		}))
+	}
+	return rts.List(out), nil
+}
+
+// run takes (value), (a, b) or (a, b, opts). Strings holding JSON or XML are
+// compared as documents, so response.text() works for XML bodies.
+func (r *compareRow) run(
+	ctx *rts.Ctx,
+	pos rts.Pos,
+	args []rts.Value,
+	sig string,
+) ([]semdiff.Change, error) {
+	na := rts.NewArgs(ctx, pos, args, sig)
+	if err := na.CountRange(1, 3); err != nil {
+		return nil, err
+	}
+	if na.Len() == 1 {
+		return r.againstBaseline(ctx, pos, na.Arg(0), sig)
+	}
+	a, err := compareArg(ctx, pos, na.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	b, err := compareArg(ctx, pos, na.Arg(1))
+	if err != nil {
+		return nil, err
+	}
+	opt := r.in.Rules
+	if na.Has(2) {
+		if opt, err = compareOpts(ctx, pos, na.Arg(2), sig); err != nil {
+			return nil, err
+		}
+	}
+	return semdiff.Compare(a, b, opt), nil
+}
+
+func (r *compareRow) againstBaseline(
+	ctx *rts.Ctx,
+	pos rts.Pos,
+	arg rts.Value,
+	sig string,
+) ([]semdiff.Change, error) {
+	switch {
+	case !r.bound:
+		return nil, rts.Errf(
+			ctx,
+			pos,
+			"%s compares one value with the baseline only in compare runs; pass two values",
+			sig,
+		)
+	case r.in.IsBaseline:
+		// The baseline agrees with itself.
+		return nil, nil
+	case !r.in.HasBody:
+		return nil, rts.Errf(ctx, pos, "%s: baseline %q has not run yet", sig, r.in.Baseline)
+	}
+	v, err := compareArg(ctx, pos, arg)
+	if err != nil {
+		return nil, err
+	}
+	return semdiff.Compare(r.base, v, r.in.Rules), nil
+}
+
+func compareArg(ctx *rts.Ctx, pos rts.Pos, v rts.Value) (any, error) {
+	if v.K == rts.VStr {
+		if ctx != nil && ctx.Lim.MaxStr > 0 && len(v.S) > ctx.Lim.MaxStr {
+			return nil, rts.Errf(ctx, pos, "text too long")
+		}
+		return decodeBody([]byte(v.S), ""), nil
+	}
+	return jsonIface(ctx, pos, v)
+}
+
+func decodeBody(body []byte, contentType string) any {
+	if v, _, ok := semdiff.Decode(body, contentType); ok {
+		return v
+	}
+	return string(body)
+}
+
+// compareOpts reads {ignore: [...], match: "id"}. Either key may be a list or
+// a comma-separated string.
+func compareOpts(ctx *rts.Ctx, pos rts.Pos, v rts.Value, sig string) (semdiff.Options, error) {
+	if v.K != rts.VDict {
+		return semdiff.Options{}, rts.Errf(ctx, pos, "%s expects opts dict", sig)
+	}
+	var lists [2][]string
+	for i, key := range []string{"ignore", "match"} {
+		switch it := v.M[key]; it.K {
+		case rts.VNull:
+		case rts.VStr:
+			lists[i] = semdiff.SplitList(it.S)
+		case rts.VList:
+			for _, s := range it.L {
+				if s.K != rts.VStr {
+					return semdiff.Options{}, rts.Errf(ctx, pos, "%s: %s must hold strings", sig, key)
+				}
+				lists[i] = append(lists[i], s.S)
+			}
+		default:
+			return semdiff.Options{}, rts.Errf(ctx, pos, "%s: %s must be a string or list", sig, key)
+		}
+	}
+	for k := range v.M {
+		if k != "ignore" && k != "match" {
+			return semdiff.Options{}, rts.Errf(ctx, pos, "%s: unknown option %q", sig, k)
+		}
+	}
+	opt, err := semdiff.Compile(lists[0], lists[1])
+	if err != nil {
+		return semdiff.Options{}, rts.Errf(ctx, pos, "%s: %v", sig, err)
+	}
+	return opt, nil
+}
+
+// plainJSON turns the json.Number values semdiff decodes into float64, which
+// is how RTS holds numbers.
+func plainJSON(v any) any {
+	switch x := v.(type) {
+	case json.Number:
+		f, err := x.Float64()
+		if err != nil {
+			return x.String()
+		}
+		return f
+	case []any:
+		out := make([]any, len(x))
+		for i, it := range x {
+			out[i] = plainJSON(it)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, it := range x {
+			out[k] = plainJSON(it)
+		}
+		return out
+	}
+	return v
+}
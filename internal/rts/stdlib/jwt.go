@@ -0,0 +1,107 @@
+package stdlib
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/unkn0wn-root/resterm/internal/jwt"
+	"github.com/unkn0wn-root/resterm/internal/rts"
+)
+
+const (
+	sigJWTDecode = "jwt.decode(token)"
+	sigJWTVerify = "jwt.verify(token, jwks)"
+)
+
+var jwtSpec = nsSpec{name: "jwt", top: true, fns: map[string]rts.NativeFunc{
+	"decode": jwtDecode,
+	"verify": jwtVerify,
+}}
+
+func jwtDecode(ctx *rts.Ctx, pos rts.Pos, args []rts.Value) (rts.Value, error) {
+	na := rts.NewArgs(ctx, pos, args, sigJWTDecode)
+	if err := na.Count(1); err != nil {
+		return rts.Null(), err
+	}
+
+	tok, err := jwtArg(ctx, pos, na)
+	if err != nil {
+		return rts.Null(), err
+	}
+
+	header, err := rts.FromIface(ctx, pos, tok.Header)
+	if err != nil {
+		return rts.Null(), err
+	}
+	claims, err := rts.FromIface(ctx, pos, tok.Claims)
+	if err != nil {
+		return rts.Null(), err
+	}
+	return rts.Dict(map[string]rts.Value{
+		"header":    header,
+		"claims":    claims,
+		"signature": rts.Str(base64.RawURLEncoding.EncodeToString(tok.Signature)),
+	}), nil
+}
+
+// jwks is either a parsed key set or a path read the way json.file reads one.
+// Fetching a key set over the network is left to the caller, because RTS has
+// no network access.
+func jwtVerify(ctx *rts.Ctx, pos rts.Pos, args []rts.Value) (rts.Value, error) {
+	na := rts.NewArgs(ctx, pos, args, sigJWTVerify)
+	if err := na.Count(2); err != nil {
+		return rts.Null(), err
+	}
+
+	tok, err := jwtArg(ctx, pos, na)
+	if err != nil {
+		return rts.Null(), err
+	}
+
+	var data []byte
+	switch arg := na.Arg(1); arg.K {
+	case rts.VStr:
+		if ctx == nil || ctx.ReadFile == nil {
+			return rts.Null(), rts.Errf(ctx, pos, "file access not available")
+		}
+		path := arg.S
+		if !filepath.IsAbs(path) && ctx.BaseDir != "" {
+			path = filepath.Join(ctx.BaseDir, path)
+		}
+		data, err = ctx.ReadFile(path)
+		if err != nil {
+			return rts.Null(), rts.Errf(ctx, pos, "file read failed")
+		}
+	case rts.VDict:
+		raw, err := jsonIface(ctx, pos, arg)
+		if err != nil {
+			return rts.Null(), err
+		}
+		data, err = json.Marshal(raw)
+		if err != nil {
+			return rts.Null(), rts.Errf(ctx, pos, "invalid jwks")
+		}
+	default:
+		return rts.Null(), rts.Errf(ctx, pos, "%s expects jwks dict or path", sigJWTVerify)
+	}
+
+	set, err := jwt.ParseKeySet(data)
+	if err != nil {
+		return rts.Null(), rts.Errf(ctx, pos, "invalid jwks")
+	}
+	_, err = jwt.Verify(tok, set)
+	return rts.Bool(err == nil), nil
+}
+
+func jwtArg(ctx *rts.Ctx, pos rts.Pos, na rts.Args) (jwt.Token, error) {
+	s, err := na.Str(0)
+	if err != nil {
+		return jwt.Token{}, err
+	}
+	tok, err := jwt.Decode(s)
+	if err != nil {
+		return jwt.Token{}, rts.Errf(ctx, pos, "invalid jwt")
+	}
+	return tok, nil
+}
@@ -0,0 +1,112 @@
+package stdlib
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/unkn0wn-root/resterm/internal/jsonschema"
+	"github.com/unkn0wn-root/resterm/internal/rts"
+)
+
+const (
+	sigSchemaValidate = "schema.validate(value, schema)"
+	sigSchemaErrors   = "schema.errors(value, schema)"
+)
+
+var schemaSpec = nsSpec{name: "schema", top: true, fns: map[string]rts.NativeFunc{
+	"validate": schemaValidate,
+	"errors":   schemaErrors,
+}}
+
+func schemaValidate(ctx *rts.Ctx, pos rts.Pos, args []rts.Value) (rts.Value, error) {
+	errs, err := schemaCheck(ctx, pos, args, sigSchemaValidate)
+	if err != nil {
+		return rts.Null(), err
+	}
+	return rts.Bool(len(errs) == 0), nil
+}
+
+func schemaErrors(ctx *rts.Ctx, pos rts.Pos, args []rts.Value) (rts.Value, error) {
+	errs, err := schemaCheck(ctx, pos, args, sigSchemaErrors)
+	if err != nil {
+		return rts.Null(), err
+	}
+	if ctx != nil && ctx.Lim.MaxList > 0 && len(errs) > ctx.Lim.MaxList {
+		errs = errs[:ctx.Lim.MaxList]
+	}
+	out := make([]rts.Value, len(errs))
+	for i, e := range errs {
+		out[i] = rts.Str(e.String())
+	}
+	return rts.List(out), nil
+}
+
+// schema is either a path read the way json.file reads one, or an inline
+// schema dict whose relative $refs resolve against the request base directory.
+func schemaCheck(
+	ctx *rts.Ctx,
+	pos rts.Pos,
+	args []rts.Value,
+	sig string,
+) ([]jsonschema.Error, error) {
+	na := rts.NewArgs(ctx, pos, args, sig)
+	if err := na.Count(2); err != nil {
+		return nil, err
+	}
+
+	val, err := jsonIface(ctx, pos, na.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	var s *jsonschema.Schema
+	switch arg := na.Arg(1); arg.K {
+	case rts.VStr:
+		if ctx == nil || ctx.ReadFile == nil {
+			return nil, rts.Errf(ctx, pos, "file access not available")
+		}
+		path := arg.S
+		if !filepath.IsAbs(path) && ctx.BaseDir != "" {
+			path = filepath.Join(ctx.BaseDir, path)
+		}
+		s, err = jsonschema.Load(path, schemaReader(ctx))
+	case rts.VDict, rts.VBool:
+		raw, rerr := jsonIface(ctx, pos, arg)
+		if rerr != nil {
+			return nil, rerr
+		}
+		var read jsonschema.ReadFunc
+		if ctx != nil && ctx.ReadFile != nil {
+			read = schemaReader(ctx)
+		}
+		s, err = jsonschema.Compile(raw, schemaDir(ctx), read)
+	default:
+		return nil, rts.Errf(ctx, pos, "%s expects schema dict or path", sig)
+	}
+	if err != nil {
+		return nil, rts.Errf(ctx, pos, "invalid schema: %v", err)
+	}
+	return s.Validate(val), nil
+}
+
+var errSchemaTooLarge = errors.New("file too large")
+
+func schemaReader(ctx *rts.Ctx) jsonschema.ReadFunc {
+	return func(path string) ([]byte, error) {
+		data, err := ctx.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if ctx.Lim.MaxStr > 0 && len(data) > ctx.Lim.MaxStr {
+			return nil, errSchemaTooLarge
+		}
+		return data, nil
+	}
+}
+
+func schemaDir(ctx *rts.Ctx) string {
+	if ctx == nil || ctx.BaseDir == "" {
+		return "."
+	}
+	return ctx.BaseDir
+}
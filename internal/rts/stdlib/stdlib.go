@@ -26,6 +26,9 @@ var rtsNamespaces = []nsSpec{
 	listSpec,
 	dictSpec,
 	mathSpec,
+	jwtSpec,
+	schemaSpec,
+	compareSpec,
 }
 
 type objMap struct {
@@ -2,11 +2,17 @@ package stdlib
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/unkn0wn-root/resterm/internal/rts"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
 )
 
 func evalExprCtx(t *testing.T, ctx *rts.Ctx, src string) rts.Value {
@@ -525,3 +531,141 @@ func TestStdlibMathHelpers(t *testing.T) {
 		t.Fatalf("expected math.round 2")
 	}
 }
+
+func TestStdlibJWTHelpers(t *testing.T) {
+	ctx := rts.NewCtx(context.Background(), rts.Limits{MaxStr: 4096, MaxList: 1024, MaxDict: 1024})
+	seg := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	in := seg(`{"alg":"HS256","kid":"k1"}`) + "." + seg(`{"sub":"42","scope":"read"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(in))
+	tok := in + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	v := evalExprCtx(t, ctx, `jwt.decode("`+tok+`").claims.scope`)
+	if v.K != rts.VStr || v.S != "read" {
+		t.Fatalf("jwt.decode claims.scope = %+v", v)
+	}
+	v = evalExprCtx(t, ctx, `rts.jwt.decode("Bearer `+tok+`").header.kid`)
+	if v.K != rts.VStr || v.S != "k1" {
+		t.Fatalf("jwt.decode header.kid = %+v", v)
+	}
+
+	jwks := `{keys: [{kty: "oct", kid: "k1", k: "` + seg("secret") + `"}]}`
+	v = evalExprCtx(t, ctx, `jwt.verify("`+tok+`", `+jwks+`)`)
+	if v.K != rts.VBool || !v.B {
+		t.Fatalf("jwt.verify with matching key = %+v", v)
+	}
+	wrong := `{keys: [{kty: "oct", k: "` + seg("other") + `"}]}`
+	v = evalExprCtx(t, ctx, `jwt.verify("`+tok+`", `+wrong+`)`)
+	if v.K != rts.VBool || v.B {
+		t.Fatalf("jwt.verify with wrong key = %+v", v)
+	}
+
+	if err := evalErr(t, ctx, `jwt.decode("not-a-token")`); err == nil || !strings.Contains(err.Error(), "invalid jwt") {
+		t.Fatalf("jwt.decode error = %v", err)
+	}
+}
+
+func TestStdlibSchemaHelpers(t *testing.T) {
+	ctx := rts.NewCtx(context.Background(), rts.Limits{MaxStr: 4096, MaxList: 1024, MaxDict: 1024})
+	ctx.BaseDir = "/work"
+	files := map[string]string{
+		"/work/schemas/order.json": `{"type": "object", "required": ["items"], "properties": {"items": {"type": "array", "items": {"$ref": "item.json"}}}}`,
+		"/work/schemas/item.json":  `{"type": "object", "properties": {"price": {"type": "number"}}}`,
+	}
+	ctx.ReadFile = func(path string) ([]byte, error) {
+		src, ok := files[filepath.ToSlash(path)]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(src), nil
+	}
+
+	const order = `{items: [{price: 1}, {price: 2.5}, {price: 3}, {price: "4"}]}`
+	v := evalExprCtx(t, ctx, `schema.validate(`+order+`, "./schemas/order.json")`)
+	if v.K != rts.VBool || v.B {
+		t.Fatalf("schema.validate = %+v, want false", v)
+	}
+	v = evalExprCtx(t, ctx, `schema.errors(`+order+`, "./schemas/order.json")`)
+	if v.K != rts.VList || len(v.L) != 1 || v.L[0].S != "/items/3/price: expected number" {
+		t.Fatalf("schema.errors = %+v", v)
+	}
+	v = evalExprCtx(t, ctx, `rts.schema.validate({items: []}, "schemas/order.json")`)
+	if v.K != rts.VBool || !v.B {
+		t.Fatalf("rts.schema.validate = %+v, want true", v)
+	}
+
+	v = evalExprCtx(t, ctx, `schema.errors({}, {required: ["id"]})`)
+	if v.K != rts.VList || len(v.L) != 1 || v.L[0].S != `(root): missing required property "id"` {
+		t.Fatalf("inline schema.errors = %+v", v)
+	}
+
+	if err := evalErr(t, ctx, `schema.validate({}, "missing.json")`); err == nil ||
+		!strings.Contains(err.Error(), "invalid schema") {
+		t.Fatalf("missing schema error = %v", err)
+	}
+	if err := evalErr(t, ctx, `schema.validate({}, 1)`); err == nil ||
+		!strings.Contains(err.Error(), "expects schema dict or path") {
+		t.Fatalf("bad schema arg error = %v", err)
+	}
+}
+
+func TestCompareHelpers(t *testing.T) {
+	ctx := testCtx()
+	v := evalExprCtx(t, ctx, `compare.equal({a: 1, b: [1, 2]}, "{\"b\": [1, 2.0], \"a\": 1}")`)
+	if v.K != rts.VBool || !v.B {
+		t.Fatalf("compare.equal = %+v, want true", v)
+	}
+	v = evalExprCtx(t, ctx, `compare.equal({a: 1, id: "x"}, {a: 2, id: "x"}, {ignore: "$.a"})`)
+	if v.K != rts.VBool || !v.B {
+		t.Fatalf("compare.equal with ignore = %+v, want true", v)
+	}
+	v = evalExprCtx(t, ctx, `compare.diff([{id: 1, n: 1}], [{id: 1, n: 2}], {match: ["id"]})[0].path`)
+	if v.K != rts.VStr || v.S != "$[id=1].n" {
+		t.Fatalf("compare.diff path = %+v", v)
+	}
+	if err := evalErr(t, ctx, `compare.equal({})`); err == nil ||
+		!strings.Contains(err.Error(), "only in compare runs") {
+		t.Fatalf("one-argument error = %v", err)
+	}
+	if err := evalErr(t, ctx, `compare.equal(1, 1, {ignore: "$items"})`); err == nil {
+		t.Fatalf("expected an error for a bad ignore path")
+	}
+}
+
+func TestCompareRowUsesBaseline(t *testing.T) {
+	ctx := testCtx()
+	rules, err := semdiff.Compile([]string{"$.meta"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := CompareRow(CompareRowInput{
+		Rules:       rules,
+		Baseline:    "dev",
+		Body:        []byte(`{"total": 2, "meta": {"requestId": "a"}}`),
+		ContentType: "application/json",
+		HasBody:     true,
+	})
+	eval := func(src string) rts.Value {
+		t.Helper()
+		mod, err := rts.ParseModule("test", []byte("export let __v = "+src))
+		if err != nil {
+			t.Fatalf("parse %s: %v", src, err)
+		}
+		prelude := New()
+		prelude["compare"] = row
+		comp, err := rts.Exec(ctx, mod, prelude)
+		if err != nil {
+			t.Fatalf("eval %s: %v", src, err)
+		}
+		return comp.Exp["__v"]
+	}
+	if v := eval(`compare.equal({total: 2.0, meta: {requestId: "b"}})`); v.K != rts.VBool || !v.B {
+		t.Fatalf("equal to baseline = %+v", v)
+	}
+	if v := eval(`compare.equal({total: 3})`); v.K != rts.VBool || v.B {
+		t.Fatalf("different total = %+v", v)
+	}
+	if v := eval(`compare.baseline.total`); v.K != rts.VNum || v.N != 2 {
+		t.Fatalf("compare.baseline.total = %+v", v)
+	}
+}
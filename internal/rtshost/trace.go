@@ -319,9 +319,10 @@ func (o *traceObj) hasBud() bool {
 
 func (o *traceObj) segMap(s trSeg) map[string]any {
 	meta := map[string]any{
-		"addr":   s.meta.Addr,
-		"reused": s.meta.Reused,
-		"cached": s.meta.Cached,
+		"addr":     s.meta.Addr,
+		"reused":   s.meta.Reused,
+		"cached":   s.meta.Cached,
+		"override": s.meta.Override,
 	}
 	res := map[string]any{
 		"name":            s.name,
@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/unkn0wn-root/resterm/internal/snippet"
+)
+
+// Emit builds the one selected request without sending it and renders it in
+// format f. Persisted runner state is loaded first, so cached auth applies.
+// The warnings cover parse warnings and preparation steps that were skipped.
+func Emit(ctx context.Context, opts Options, f snippet.Format, mask bool) (string, []string, error) {
+	if ctx == nil {
+		return "", nil, ErrNilContext
+	}
+	pl, err := Build(opts)
+	if err != nil {
+		return "", nil, err
+	}
+	doc := cloneDoc(pl.doc)
+	tg, err := pl.sel.resolve(doc)
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case tg.workflow != nil:
+		return "", nil, usageError("--emit renders a single request, not a workflow")
+	case len(tg.requests) != 1:
+		return "", nil, usageError("--emit renders a single request; %d were selected", len(tg.requests))
+	}
+
+	opt := pl.opt
+	exec := newEngine(opt, nil)
+	defer func() { _ = exec.Close() }()
+	if err := loadRunnerState(exec, pl.state, opt); err != nil {
+		return "", nil, fmt.Errorf("load runner state: %w", err)
+	}
+	prep, err := exec.PrepareRequestContext(ctx, doc, tg.requests[0], opt.Selection, mask)
+	if err != nil {
+		return "", nil, err
+	}
+	out, err := snippet.Render(f, snippet.Request{
+		Method: prep.Method,
+		URL:    prep.URL,
+		Header: prep.Header,
+		Body:   prep.Body,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return out, append(slices.Clone(pl.warns), prep.Warnings...), nil
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/curl"
+	"github.com/unkn0wn-root/resterm/internal/snippet"
+)
+
+func writeEmitFile(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "emit.http")
+	src := strings.Join([]string{
+		"# @file-secret apiKey s3cr3t",
+		"",
+		"### Create",
+		"# @name create",
+		"# @apply {headers: {\"X-Patched\": \"yes\"}}",
+		"# @auth bearer tok-123",
+		"POST https://api.example.com/items?key={{apiKey}}",
+		"Content-Type: application/json",
+		"",
+		"{\"key\": \"{{apiKey}}\"}",
+		"",
+		"### Other",
+		"GET https://api.example.com/other",
+		"",
+	}, "\n")
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	return file
+}
+
+func TestEmitRendersResolvedRequest(t *testing.T) {
+	file := writeEmitFile(t)
+	opts := Options{FilePath: file, Select: Select{Request: "create"}}
+
+	out, _, err := Emit(context.Background(), opts, snippet.Curl, false)
+	if err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	req, err := curl.ParseCommand(out)
+	if err != nil {
+		t.Fatalf("parse snippet: %v\n%s", err, out)
+	}
+	if req.URL != "https://api.example.com/items?key=s3cr3t" || req.Body.Text != `{"key": "s3cr3t"}` {
+		t.Fatalf("url/body = %q %q", req.URL, req.Body.Text)
+	}
+	if req.Headers.Get("Authorization") != "Bearer tok-123" || req.Headers.Get("X-Patched") != "yes" {
+		t.Fatalf("headers = %v", req.Headers)
+	}
+
+	masked, _, err := Emit(context.Background(), opts, snippet.Curl, true)
+	if err != nil {
+		t.Fatalf("Emit masked: %v", err)
+	}
+	if strings.Contains(masked, "s3cr3t") || strings.Contains(masked, "tok-123") {
+		t.Fatalf("secrets leaked:\n%s", masked)
+	}
+	if !strings.Contains(masked, "X-Patched: yes") {
+		t.Fatalf("masked snippet lost plain headers:\n%s", masked)
+	}
+}
+
+func TestEmitNeedsOneRequest(t *testing.T) {
+	file := writeEmitFile(t)
+	_, _, err := Emit(context.Background(), Options{FilePath: file, Select: Select{All: true}}, snippet.Curl, false)
+	if !IsUsageError(err) || !strings.Contains(err.Error(), "2 were selected") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	engheadless "github.com/unkn0wn-root/resterm/internal/engine/headless"
+	"github.com/unkn0wn-root/resterm/internal/history"
 	"github.com/unkn0wn-root/resterm/internal/parser"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
@@ -125,19 +126,7 @@ func RunPlan(ctx context.Context, pl *Plan) (*Report, error) {
 	start := time.Now()
 	hist := openHistoryStore(pl.state, opt)
 
-	exec := engheadless.New(engine.Config{
-		FilePath:        opt.FilePath,
-		Client:          opt.Client,
-		Catalog:         opt.Catalog,
-		Selection:       opt.Selection,
-		EnvironmentFile: opt.EnvironmentFile,
-		Compare:         opt.Compare.Clone(),
-		HTTPOptions:     cloneHTTPOptions(opt.HTTPOptions),
-		GRPCOptions:     cloneGRPCOptions(opt.GRPCOptions),
-		WorkspaceRoot:   opt.WorkspaceRoot,
-		Recursive:       opt.Recursive,
-		History:         hist,
-	})
+	exec := newEngine(opt, hist)
 
 	defer func() { _ = exec.Close() }()
 
@@ -207,6 +196,24 @@ func RunPlan(ctx context.Context, pl *Plan) (*Report, error) {
 	return finishRun(rep, exec, pl.state, opt)
 }
 
+func newEngine(opt Options, hist history.Store) *engheadless.Engine {
+	return engheadless.New(engine.Config{
+		FilePath:        opt.FilePath,
+		Client:          opt.Client,
+		Catalog:         opt.Catalog,
+		Selection:       opt.Selection,
+		EnvironmentFile: opt.EnvironmentFile,
+		Compare:         opt.Compare.Clone(),
+		Contract:        opt.Contract,
+		UpdateSnapshots: opt.UpdateSnapshots,
+		HTTPOptions:     cloneHTTPOptions(opt.HTTPOptions),
+		GRPCOptions:     cloneGRPCOptions(opt.GRPCOptions),
+		WorkspaceRoot:   opt.WorkspaceRoot,
+		Recursive:       opt.Recursive,
+		History:         hist,
+	})
+}
+
 func finishRun(
 	rep *Report,
 	exec engine.Executor,
@@ -237,6 +244,7 @@ func clonePlanOptions(opts Options, path, work, art string) Options {
 	out.StateDir = str.Trim(opts.StateDir)
 	out.EnvironmentFile = str.Trim(opts.EnvironmentFile)
 	out.Compare = opts.Compare.Clone()
+	out.Contract = str.Trim(opts.Contract)
 	out.HTTPOptions = cloneHTTPOptions(opts.HTTPOptions)
 	out.GRPCOptions = cloneGRPCOptions(opts.GRPCOptions)
 	out.Client = opts.Client.Clone()
@@ -7,11 +7,13 @@ import (
 	"sort"
 
 	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/openapi/contract"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/runx/fail"
 	"github.com/unkn0wn-root/resterm/internal/runx/report"
 	"github.com/unkn0wn-root/resterm/internal/scripts"
+	"github.com/unkn0wn-root/resterm/internal/snapshot"
 	str "github.com/unkn0wn-root/resterm/internal/util"
 )
 
@@ -87,6 +89,8 @@ func toFormatResult(res Result) runfmt.Result {
 		GRPC:                 formatGRPC(res.GRPC),
 		Stream:               formatStream(res.Stream),
 		Trace:                formatTrace(res.Trace),
+		Contract:             formatContract(res.Contract),
+		Snapshot:             formatSnapshot(res.Snapshot),
 		Tests:                formatTests(res.Tests),
 		Compare:              formatCompare(res.Compare),
 		Profile:              formatProfile(res.Profile),
@@ -120,6 +124,8 @@ func toFormatStep(step StepResult) runfmt.Step {
 		GRPC:                 formatGRPC(step.GRPC),
 		Stream:               formatStream(step.Stream),
 		Trace:                formatTrace(step.Trace),
+		Contract:             formatContract(step.Contract),
+		Snapshot:             formatSnapshot(step.Snapshot),
 		Tests:                formatTests(step.Tests),
 	}
 	return out
@@ -379,3 +385,36 @@ func formatTrace(info *TraceInfo) *runfmt.Trace {
 	}
 	return out
 }
+
+func formatContract(res *contract.Result) *runfmt.Contract {
+	if res == nil {
+		return nil
+	}
+	out := &runfmt.Contract{
+		Spec:      str.Trim(res.Spec),
+		Operation: str.Trim(res.Operation),
+	}
+	if len(res.Violations) > 0 {
+		out.Violations = make([]runfmt.ContractViolation, 0, len(res.Violations))
+		for _, v := range res.Violations {
+			out.Violations = append(out.Violations, runfmt.ContractViolation{
+				Kind:    string(v.Kind),
+				Path:    v.Path,
+				Message: v.Message,
+			})
+		}
+	}
+	return out
+}
+
+func formatSnapshot(res *snapshot.Result) *runfmt.Snapshot {
+	if res == nil {
+		return nil
+	}
+	return &runfmt.Snapshot{
+		Path:   res.Name,
+		Status: string(res.Status),
+		Diff:   res.Diff,
+		Error:  res.Err,
+	}
+}
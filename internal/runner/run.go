@@ -13,12 +13,14 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	xplain "github.com/unkn0wn-root/resterm/internal/explain"
 	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/openapi/contract"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/runx/fail"
 	"github.com/unkn0wn-root/resterm/internal/runx/report"
 	"github.com/unkn0wn-root/resterm/internal/scripts"
+	"github.com/unkn0wn-root/resterm/internal/snapshot"
 	str "github.com/unkn0wn-root/resterm/internal/util"
 	"github.com/unkn0wn-root/resterm/internal/vars"
 )
@@ -32,26 +34,30 @@ type Select struct {
 }
 
 type Options struct {
-	Version         string
-	FilePath        string
-	FileContent     []byte
-	WorkspaceRoot   string
-	Recursive       bool
-	ArtifactDir     string
-	StateDir        string
-	PersistGlobals  bool
-	PersistAuth     bool
-	History         bool
-	FailFast        bool
-	Catalog         vars.Catalog
-	Selection       vars.Selection
-	EnvironmentFile string
-	Compare         engine.CompareConfig
-	Profile         bool
-	HTTPOptions     httpx.Options
-	GRPCOptions     grpcx.Options
-	Client          *httpx.Client
-	Select          Select
+	Version        string
+	FilePath       string
+	FileContent    []byte
+	WorkspaceRoot  string
+	Recursive      bool
+	ArtifactDir    string
+	StateDir       string
+	PersistGlobals bool
+	PersistAuth    bool
+	History        bool
+	// HistoryRetention is enforced when the history store opens.
+	HistoryRetention history.Retention
+	FailFast         bool
+	Catalog          vars.Catalog
+	Selection        vars.Selection
+	EnvironmentFile  string
+	Compare          engine.CompareConfig
+	Contract         string
+	UpdateSnapshots  bool
+	Profile          bool
+	HTTPOptions      httpx.Options
+	GRPCOptions      grpcx.Options
+	Client           *httpx.Client
+	Select           Select
 }
 
 const stopReasonFailFast = "fail_fast"
@@ -106,6 +112,8 @@ type Result struct {
 	SkipReason                string
 	Stream                    *StreamInfo
 	Trace                     *TraceInfo
+	Contract                  *contract.Result
+	Snapshot                  *snapshot.Result
 	Compare                   *CompareInfo
 	Profile                   *ProfileInfo
 	Steps                     []StepResult
@@ -175,6 +183,8 @@ type StepResult struct {
 	Canceled             bool
 	Stream               *StreamInfo
 	Trace                *TraceInfo
+	Contract             *contract.Result
+	Snapshot             *snapshot.Result
 	Failure              runfail.Failure
 	transcript           []byte
 }
@@ -219,7 +229,8 @@ func resultFailed(item Result) bool {
 	if item.Failure.Code != "" {
 		return true
 	}
-	if item.Canceled || item.Err != nil || item.ScriptErr != nil || traceFailed(item.Trace) {
+	if item.Canceled || item.Err != nil || item.ScriptErr != nil || traceFailed(item.Trace) ||
+		item.Contract.Failed() {
 		return true
 	}
 	for _, test := range item.Tests {
@@ -333,6 +344,8 @@ func requestRunResult(req *restfile.Request, res engine.RequestResult, fallbackE
 		SkipReason:           str.Trim(res.SkipReason),
 		Stream:               streamResult(res.Stream),
 		Trace:                traceResult(res.Response),
+		Contract:             res.Contract,
+		Snapshot:             res.Snapshot,
 		transcript:           bytes.Clone(res.Transcript),
 	}
 	if res.Explain != nil {
@@ -367,7 +380,7 @@ func requestFailed(item Result) bool {
 			return true
 		}
 	}
-	return traceFailed(item.Trace)
+	return traceFailed(item.Trace) || item.Contract.Failed()
 }
 
 func compareRunResult(req *restfile.Request, res engine.CompareResult, fallbackEnv string) Result {
@@ -408,7 +421,7 @@ func compareDuration(rows []engine.CompareRow) time.Duration {
 
 func compareStepResult(req *restfile.Request, row engine.CompareRow) StepResult {
 	step := StepResult{
-		Name:                 str.Trim(row.Environment),
+		Name:                 str.Trim(row.Label()),
 		Method:               requestMethod(req),
 		Target:               requestSourceTarget(req),
 		EffectiveTarget:      requestTarget(req, row.Response),
@@ -551,6 +564,8 @@ func workflowStepResult(step engine.WorkflowStep) StepResult {
 		Canceled:             step.Canceled,
 		Stream:               streamResult(step.Stream),
 		Trace:                traceResult(step.Response),
+		Contract:             step.Contract,
+		Snapshot:             step.Snapshot,
 		transcript:           bytes.Clone(step.Transcript),
 	}
 	out.Failure = stepFailure(out)
@@ -661,7 +676,8 @@ func stepFailed(step StepResult) bool {
 	if step.Failure.Code != "" {
 		return true
 	}
-	if step.Canceled || step.Err != nil || step.ScriptErr != nil || traceFailed(step.Trace) {
+	if step.Canceled || step.Err != nil || step.ScriptErr != nil || traceFailed(step.Trace) ||
+		step.Contract.Failed() {
 		return true
 	}
 	for _, test := range step.Tests {
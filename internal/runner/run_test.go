@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -14,9 +15,12 @@ import (
 
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	xplain "github.com/unkn0wn-root/resterm/internal/explain"
+	"github.com/unkn0wn-root/resterm/internal/history"
 	histdb "github.com/unkn0wn-root/resterm/internal/history/sqlite"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
+	runfail "github.com/unkn0wn-root/resterm/internal/runx/fail"
+	"github.com/unkn0wn-root/resterm/internal/snapshot"
 	"github.com/unkn0wn-root/resterm/internal/vars"
 )
 
@@ -1913,11 +1917,27 @@ func TestRunPersistsHistory(t *testing.T) {
 		t.Fatalf("write file: %v", err)
 	}
 
+	// An entry past the retention window is pruned when the run opens the store.
+	store := histdb.New(filepath.Join(stateDir, "history.db"))
+	if err := store.Append(history.Entry{
+		ID:          "stale",
+		ExecutedAt:  time.Now().Add(-48 * time.Hour),
+		RequestName: "hist",
+	}); err != nil {
+		t.Fatalf("seed history: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("close history: %v", err)
+	}
+
 	rep, err := RunContext(context.Background(), Options{
 		FilePath:      file,
 		WorkspaceRoot: dir,
 		StateDir:      stateDir,
 		History:       true,
+		HistoryRetention: history.Retention{
+			RetentionLimits: history.RetentionLimits{MaxAge: 24 * time.Hour},
+		},
 	})
 	if err != nil {
 		t.Fatalf("Run: %v", err)
@@ -1926,7 +1946,7 @@ func TestRunPersistsHistory(t *testing.T) {
 		t.Fatalf("expected history run to pass, got %+v", rep)
 	}
 
-	store := histdb.New(filepath.Join(stateDir, "history.db"))
+	store = histdb.New(filepath.Join(stateDir, "history.db"))
 	t.Cleanup(func() { _ = store.Close() })
 	entries, err := store.Entries()
 	if err != nil {
@@ -1983,3 +2003,133 @@ func TestRunRejectsUnseededHeadlessOAuthAuthorizationCode(t *testing.T) {
 		t.Fatalf("expected request to stop before network call, got %d calls", calls)
 	}
 }
+
+func TestRunComparesSnapshotsAndUpdatesThem(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "users.http")
+	name := "Ada"
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id": %d, "name": %q}`, calls, name)
+	}))
+	defer srv.Close()
+
+	src := strings.Join([]string{
+		"### User",
+		"# @name get-user",
+		"# @snapshot ignore=$.id",
+		"GET " + srv.URL + "/users/1",
+		"",
+	}, "\n")
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	run := func(update bool) Result {
+		t.Helper()
+		rep, err := RunContext(context.Background(), Options{
+			FilePath:        file,
+			WorkspaceRoot:   dir,
+			UpdateSnapshots: update,
+			Select:          Select{All: true},
+		})
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if len(rep.Results) != 1 || rep.Results[0].Snapshot == nil {
+			t.Fatalf("expected one snapshot result, got %+v", rep.Results)
+		}
+		return rep.Results[0]
+	}
+
+	if res := run(false); !res.Passed || res.Snapshot.Status != snapshot.StatusCreated {
+		t.Fatalf("first run = %+v", res.Snapshot)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "__snapshots__", "get-user.json")); err != nil {
+		t.Fatalf("expected snapshot file: %v", err)
+	}
+	if res := run(false); !res.Passed || res.Snapshot.Status != snapshot.StatusMatched {
+		t.Fatalf("second run = %+v", res.Snapshot)
+	}
+
+	name = "Grace"
+	res := run(false)
+	if res.Passed || res.Snapshot.Status != snapshot.StatusMismatch ||
+		!strings.Contains(res.Snapshot.Diff, `+  "name": "Grace"`) {
+		t.Fatalf("changed response = %+v", res.Snapshot)
+	}
+	if res.Failure.Code != runfail.CodeAssertion {
+		t.Fatalf("expected assertion failure, got %+v", res.Failure)
+	}
+	if res := run(true); !res.Passed || res.Snapshot.Status != snapshot.StatusUpdated {
+		t.Fatalf("update run = %+v", res.Snapshot)
+	}
+	if res := run(false); !res.Passed || res.Snapshot.Status != snapshot.StatusMatched {
+		t.Fatalf("run after update = %+v", res.Snapshot)
+	}
+}
+
+// Compare rows answer differently by design, so each row keeps its own
+// snapshot file instead of fighting over one.
+func TestRunCompareKeepsSnapshotPerRow(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "status.http")
+	src := strings.Join([]string{
+		"### Status",
+		"# @name status",
+		"# @compare dev stage",
+		"# @snapshot",
+		"GET https://{{host}}/status",
+		"",
+	}, "\n")
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	client := newHTTPClientWithFactory(func(httpx.Options) (*http.Client, error) {
+		return &http.Client{
+			Transport: transportFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Status:     "200 OK",
+					StatusCode: http.StatusOK,
+					Proto:      "HTTP/1.1",
+					Header:     make(http.Header),
+					Body:       io.NopCloser(strings.NewReader(req.URL.Host)),
+					Request:    req,
+				}, nil
+			}),
+		}, nil
+	})
+	cat, err := vars.NewCatalog(vars.EnvironmentSet{
+		"dev":   {"host": "dev.example.com"},
+		"stage": {"host": "stage.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("environment catalog: %v", err)
+	}
+	run := func() *Report {
+		t.Helper()
+		rep, err := RunContext(context.Background(), Options{
+			FilePath:      file,
+			WorkspaceRoot: dir,
+			Client:        client,
+			Catalog:       cat,
+			Selection:     cat.DefaultSelection(),
+		})
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		return rep
+	}
+
+	run()
+	for _, env := range []string{"dev", "stage"} {
+		data, err := os.ReadFile(filepath.Join(dir, "__snapshots__", "status."+env+".json"))
+		if err != nil || !strings.Contains(string(data), env+".example.com") {
+			t.Fatalf("%s snapshot = %q, %v", env, data, err)
+		}
+	}
+	if rep := run(); rep.Passed != 1 || rep.Failed != 0 {
+		t.Fatalf("second run should match every row: %+v", rep)
+	}
+}
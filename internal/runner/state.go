@@ -84,7 +84,9 @@ func openHistoryStore(paths statePaths, opts Options) history.Store {
 	if !opts.History || str.Trim(paths.History) == "" {
 		return nil
 	}
-	return histdb.New(paths.History)
+	s := histdb.New(paths.History)
+	s.SetRetention(opts.HistoryRetention)
+	return s
 }
 
 func loadRunnerState(h engine.Executor, paths statePaths, opts Options) error {
@@ -24,6 +24,7 @@ const (
 	ExitFilesystem = 25
 	ExitProtocol   = 26
 	ExitRoute      = 27
+	ExitContract   = 28
 	ExitCanceled   = 130
 )
 
@@ -40,6 +41,7 @@ const (
 	CodeFilesystem  Code = "filesystem"
 	CodeProtocol    Code = "protocol"
 	CodeRoute       Code = "route"
+	CodeContract    Code = "contract"
 	CodeCanceled    Code = "canceled"
 	CodeInternal    Code = "internal"
 	CodeUnknown     Code = "unknown"
@@ -57,6 +59,7 @@ const (
 	CategoryFilesystem Category = "filesystem"
 	CategoryProtocol   Category = "protocol"
 	CategoryRoute      Category = "route"
+	CategoryContract   Category = "contract"
 	CategoryCanceled   Category = "canceled"
 	CategoryInternal   Category = "internal"
 )
@@ -86,6 +89,7 @@ var catalog = map[Code]Meta{
 	CodeFilesystem:  {Category: CategoryFilesystem, ExitCode: ExitFilesystem, Rank: 60},
 	CodeProtocol:    {Category: CategoryProtocol, ExitCode: ExitProtocol, Rank: 70},
 	CodeRoute:       {Category: CategoryRoute, ExitCode: ExitRoute, Rank: 80},
+	CodeContract:    {Category: CategoryContract, ExitCode: ExitContract, Rank: 95},
 	CodeCanceled:    {Category: CategoryCanceled, ExitCode: ExitCanceled, Rank: 0},
 	CodeInternal:    {Category: CategoryInternal, ExitCode: ExitInternal, Rank: 90},
 	CodeUnknown:     {Category: CategoryInternal, ExitCode: ExitInternal, Rank: 90},
@@ -160,6 +164,13 @@ func TraceBudget(message string) Failure {
 	return New(CodeTraceBudget, message, "trace")
 }
 
+func Contract(message string) Failure {
+	if message == "" {
+		message = "response breaks the OpenAPI contract"
+	}
+	return New(CodeContract, message, "contract")
+}
+
 func Script(message, source string) Failure {
 	if source == "" {
 		source = "scriptError"
@@ -163,3 +163,19 @@ func TestReportExitCodeModes(t *testing.T) {
 		t.Fatalf("summary exit code = %d, want %d", got, ExitFailure)
 	}
 }
+
+// A contract violation outranks a failed assertion on the same run but yields
+// to transport failures, which usually explain the response that broke it.
+func TestContractFailureRank(t *testing.T) {
+	got := ExitCode([]Failure{Assertion("test failed", "tests"), Contract("")}, true, ExitDetailed)
+	if got != ExitContract {
+		t.Fatalf("contract vs assertion exit code = %d, want %d", got, ExitContract)
+	}
+	got = ExitCode([]Failure{Contract("body: expected number"), New(CodeNetwork, "reset", "error")}, true, "")
+	if got != ExitNetwork {
+		t.Fatalf("contract vs network exit code = %d, want %d", got, ExitNetwork)
+	}
+	if f := Contract(""); f.Category != CategoryContract || f.Source != "contract" || f.Message == "" {
+		t.Fatalf("Contract() = %+v", f)
+	}
+}
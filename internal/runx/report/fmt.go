@@ -155,6 +155,9 @@ func resultLine(res Result) string {
 		return fmt.Sprintf("%s [%s]", base, res.ScriptError)
 	}
 
+	if msg := contractFailureText(res.Contract); msg != "" {
+		return fmt.Sprintf("%s [%s]", base, msg)
+	}
 	if n := failedTestCount(res.Tests); n > 0 {
 		return fmt.Sprintf("%s [%d test(s) failed]", base, n)
 	}
@@ -271,6 +274,9 @@ func stepLine(step Step) string {
 		return fmt.Sprintf("%s [%s]", base, step.ScriptError)
 	}
 
+	if msg := contractFailureText(step.Contract); msg != "" {
+		return fmt.Sprintf("%s [%s]", base, msg)
+	}
 	if n := failedTestCount(step.Tests); n > 0 {
 		return fmt.Sprintf("%s [%d test(s) failed]", base, n)
 	}
@@ -335,6 +341,53 @@ func traceFailureText(info *Trace) string {
 	return fmt.Sprintf("trace budget breach %s", label)
 }
 
+func contractFailureText(info *Contract) string {
+	if msg := ContractMessage(info); msg != "" {
+		return "contract " + msg
+	}
+	return ""
+}
+
+// ContractMessage names the operation and the first violation, with a count
+// of the rest. It is empty when the contract holds.
+func ContractMessage(info *Contract) string {
+	if !contractFailed(info) {
+		return ""
+	}
+	msg := info.Violations[0].text()
+	if n := len(info.Violations) - 1; n > 0 {
+		msg += fmt.Sprintf(" (+%d more)", n)
+	}
+	if info.Operation != "" {
+		msg = info.Operation + ": " + msg
+	}
+	return msg
+}
+
+func (v ContractViolation) text() string {
+	switch {
+	case v.Kind == "body" && v.Path != "":
+		return "body " + v.Path + ": " + v.Message
+	case v.Kind == "body":
+		return "body: " + v.Message
+	case v.Kind == "header" && v.Path != "":
+		return "header " + v.Path + ": " + v.Message
+	default:
+		return v.Message
+	}
+}
+
+func contractViolationLines(info *Contract) []string {
+	if !contractFailed(info) {
+		return nil
+	}
+	out := make([]string, 0, len(info.Violations))
+	for _, v := range info.Violations {
+		out = append(out, v.text())
+	}
+	return out
+}
+
 func suiteName(res Result) string {
 	return requestMethodValue(res.Method) + " " + resultName(res)
 }
@@ -346,6 +399,9 @@ func resultFailureMessage(res Result) string {
 	case res.ScriptError != "":
 		return res.ScriptError
 	}
+	if msg := contractFailureText(res.Contract); msg != "" {
+		return msg
+	}
 	if failed := failedTests(res.Tests); len(failed) > 0 {
 		return testFailureMessage(failed)
 	}
@@ -373,6 +429,9 @@ func stepFailureMessage(step Step) string {
 		}
 		return "canceled"
 	}
+	if msg := contractFailureText(step.Contract); msg != "" {
+		return msg
+	}
 	if failed := failedTests(step.Tests); len(failed) > 0 {
 		return testFailureMessage(failed)
 	}
@@ -51,6 +51,8 @@ type jsonResult struct {
 	GRPC                 *jsonGRPC         `json:"grpc,omitempty"`
 	Stream               *jsonStream       `json:"stream,omitempty"`
 	Trace                *jsonTrace        `json:"trace,omitempty"`
+	Contract             *jsonContract     `json:"contract,omitempty"`
+	Snapshot             *jsonSnapshot     `json:"snapshot,omitempty"`
 	Tests                []jsonTest        `json:"tests,omitempty"`
 	Compare              *jsonCompare      `json:"compare,omitempty"`
 	Profile              *jsonProfile      `json:"profile,omitempty"`
@@ -183,6 +185,27 @@ type jsonTraceBreach struct {
 	OverMs   int64  `json:"overMs,omitempty"`
 }
 
+type jsonContract struct {
+	Spec       string                  `json:"spec,omitempty"`
+	Operation  string                  `json:"operation,omitempty"`
+	Passed     bool                    `json:"passed"`
+	Violations []jsonContractViolation `json:"violations,omitempty"`
+}
+
+type jsonContractViolation struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+type jsonSnapshot struct {
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+	Passed bool   `json:"passed"`
+	Diff   string `json:"diff,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
 type jsonStep struct {
 	Name                 string            `json:"name,omitempty"`
 	Method               string            `json:"method,omitempty"`
@@ -205,6 +228,8 @@ type jsonStep struct {
 	GRPC                 *jsonGRPC         `json:"grpc,omitempty"`
 	Stream               *jsonStream       `json:"stream,omitempty"`
 	Trace                *jsonTrace        `json:"trace,omitempty"`
+	Contract             *jsonContract     `json:"contract,omitempty"`
+	Snapshot             *jsonSnapshot     `json:"snapshot,omitempty"`
 	Tests                []jsonTest        `json:"tests,omitempty"`
 }
 
@@ -271,6 +296,8 @@ func (res Result) json() jsonResult {
 		GRPC:                 res.GRPC.json(),
 		Stream:               res.Stream.json(),
 		Trace:                res.Trace.json(),
+		Contract:             res.Contract.json(),
+		Snapshot:             res.Snapshot.json(),
 		Compare:              res.Compare.json(),
 		Profile:              res.Profile.json(),
 	}
@@ -316,6 +343,8 @@ func (step Step) json() jsonStep {
 		GRPC:                 step.GRPC.json(),
 		Stream:               step.Stream.json(),
 		Trace:                step.Trace.json(),
+		Contract:             step.Contract.json(),
+		Snapshot:             step.Snapshot.json(),
 	}
 	if len(step.Tests) > 0 {
 		out.Tests = make([]jsonTest, 0, len(step.Tests))
@@ -560,3 +589,34 @@ func (breach TraceBreach) json() jsonTraceBreach {
 		OverMs:   durMS(breach.Over),
 	}
 }
+
+func (c *Contract) json() *jsonContract {
+	if c == nil {
+		return nil
+	}
+	out := &jsonContract{
+		Spec:      c.Spec,
+		Operation: c.Operation,
+		Passed:    len(c.Violations) == 0,
+	}
+	if len(c.Violations) > 0 {
+		out.Violations = make([]jsonContractViolation, 0, len(c.Violations))
+		for _, v := range c.Violations {
+			out.Violations = append(out.Violations, jsonContractViolation(v))
+		}
+	}
+	return out
+}
+
+func (s *Snapshot) json() *jsonSnapshot {
+	if s == nil {
+		return nil
+	}
+	return &jsonSnapshot{
+		Path:   s.Path,
+		Status: s.Status,
+		Passed: !snapshotFailed(s),
+		Diff:   s.Diff,
+		Error:  s.Error,
+	}
+}
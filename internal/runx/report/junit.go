@@ -3,6 +3,7 @@ package runfmt
 import (
 	"encoding/xml"
 	"io"
+	"strings"
 )
 
 type junitSuites struct {
@@ -136,7 +137,10 @@ func resultFailureBody(res Result, fallback string) string {
 	if res.ScriptErrorDetail != nil {
 		return errorDetailText(res.ScriptErrorDetail, fallback)
 	}
-	return fallback
+	if contractFailed(res.Contract) {
+		return contractFailureBody(res.Contract, fallback)
+	}
+	return snapshotFailureBody(res.Snapshot, fallback)
 }
 
 func stepFailureBody(step Step, fallback string) string {
@@ -146,7 +150,33 @@ func stepFailureBody(step Step, fallback string) string {
 	if step.ScriptErrorDetail != nil {
 		return errorDetailText(step.ScriptErrorDetail, fallback)
 	}
-	return fallback
+	if contractFailed(step.Contract) {
+		return contractFailureBody(step.Contract, fallback)
+	}
+	return snapshotFailureBody(step.Snapshot, fallback)
+}
+
+// contractFailureBody lists every violation, since the failure message only
+// carries the first.
+func contractFailureBody(info *Contract, fallback string) string {
+	lines := contractViolationLines(info)
+	if len(lines) == 0 {
+		return fallback
+	}
+	body := fallback
+	for _, line := range lines {
+		body += "\n- " + line
+	}
+	return body
+}
+
+// snapshotFailureBody appends the snapshot diff, which the failed test row
+// only summarizes.
+func snapshotFailureBody(info *Snapshot, fallback string) string {
+	if !snapshotFailed(info) || info.Diff == "" {
+		return fallback
+	}
+	return fallback + "\n" + strings.TrimRight(info.Diff, "\n")
 }
 
 func junitSystemOut(text, target, effective string) string {
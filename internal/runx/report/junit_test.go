@@ -36,3 +36,120 @@ func TestWriteJUnitIncludesTargetDetailsInSystemOut(t *testing.T) {
 		}
 	}
 }
+
+func TestContractViolationsInReports(t *testing.T) {
+	contract := &Contract{
+		Spec:      "openapi.yml",
+		Operation: "getUser",
+		Violations: []ContractViolation{
+			{Kind: "body", Path: "/items/3/price", Message: "expected number"},
+			{Kind: "header", Path: "X-Rate-Limit", Message: "missing required header"},
+		},
+	}
+	rep := &Report{
+		Results: []Result{{
+			Kind:     "request",
+			Name:     "getUser",
+			Method:   "GET",
+			Status:   StatusFail,
+			HTTP:     &HTTP{Status: "200 OK", StatusCode: 200},
+			Contract: contract,
+			Failure:  &Failure{Code: "contract", Category: "contract", ExitCode: 28},
+		}},
+		Total:  1,
+		Failed: 1,
+	}
+
+	var xml strings.Builder
+	if err := WriteJUnit(&xml, rep); err != nil {
+		t.Fatalf("WriteJUnit(...): %v", err)
+	}
+	for _, want := range []string{
+		`<failure message="contract getUser: body /items/3/price: expected number (+1 more)">`,
+		"- header X-Rate-Limit: missing required header",
+	} {
+		if !strings.Contains(xml.String(), want) {
+			t.Fatalf("expected %q in junit, got %q", want, xml.String())
+		}
+	}
+
+	var js strings.Builder
+	if err := WriteJSON(&js, rep); err != nil {
+		t.Fatalf("WriteJSON(...): %v", err)
+	}
+	for _, want := range []string{
+		`"contract": {`,
+		`"operation": "getUser"`,
+		`"path": "/items/3/price"`,
+		`"passed": false`,
+	} {
+		if !strings.Contains(js.String(), want) {
+			t.Fatalf("expected %q in json, got %s", want, js.String())
+		}
+	}
+
+	var text strings.Builder
+	if err := WriteText(&text, rep); err != nil {
+		t.Fatalf("WriteText(...): %v", err)
+	}
+	for _, want := range []string{
+		"GET getUser [contract getUser: body /items/3/price: expected number (+1 more)]",
+		"Contract: getUser (openapi.yml)",
+		"- body /items/3/price: expected number",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Fatalf("expected %q in text, got %s", want, text.String())
+		}
+	}
+}
+
+func TestSnapshotDiffInReports(t *testing.T) {
+	diff := "--- snapshot user.json\n+++ response\n@@ -1,3 +1,3 @@\n {\n-  \"name\": \"Ada\"\n+  \"name\": \"Grace\"\n }\n"
+	rep := &Report{
+		Results: []Result{{
+			Kind:     "request",
+			Name:     "getUser",
+			Method:   "GET",
+			Status:   StatusFail,
+			HTTP:     &HTTP{Status: "200 OK", StatusCode: 200},
+			Snapshot: &Snapshot{Path: "user.json", Status: "mismatch", Diff: diff},
+			Tests: []Test{{
+				Name:    "snapshot user.json",
+				Message: "response differs from snapshot (+1 -1 lines)",
+			}},
+		}},
+		Total:  1,
+		Failed: 1,
+	}
+
+	var xml strings.Builder
+	if err := WriteJUnit(&xml, rep); err != nil {
+		t.Fatalf("WriteJUnit(...): %v", err)
+	}
+	if !strings.Contains(xml.String(), `+  &#34;name&#34;: &#34;Grace&#34;`) {
+		t.Fatalf("expected diff in junit failure body, got %q", xml.String())
+	}
+
+	var js strings.Builder
+	if err := WriteJSON(&js, rep); err != nil {
+		t.Fatalf("WriteJSON(...): %v", err)
+	}
+	for _, want := range []string{`"snapshot": {`, `"status": "mismatch"`, `"diff": "--- snapshot user.json`} {
+		if !strings.Contains(js.String(), want) {
+			t.Fatalf("expected %q in json, got %s", want, js.String())
+		}
+	}
+
+	var text strings.Builder
+	if err := WriteText(&text, rep); err != nil {
+		t.Fatalf("WriteText(...): %v", err)
+	}
+	for _, want := range []string{
+		"Snapshot: user.json (mismatch)",
+		`    +  "name": "Grace"`,
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Fatalf("expected %q in text, got %s", want, text.String())
+		}
+	}
+}
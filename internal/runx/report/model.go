@@ -54,6 +54,8 @@ type Result struct {
 	GRPC                 *GRPC
 	Stream               *Stream
 	Trace                *Trace
+	Contract             *Contract
+	Snapshot             *Snapshot
 	Tests                []Test
 	Compare              *Compare
 	Profile              *Profile
@@ -84,6 +86,8 @@ type Step struct {
 	GRPC                 *GRPC
 	Stream               *Stream
 	Trace                *Trace
+	Contract             *Contract
+	Snapshot             *Snapshot
 	Tests                []Test
 }
 
@@ -230,3 +234,34 @@ type TraceBreach struct {
 func traceFailed(info *Trace) bool {
 	return info != nil && len(info.Breaches) > 0
 }
+
+// Contract lists how a response broke its OpenAPI operation. Path is a header
+// name or a JSON pointer into the body, depending on Kind.
+type Contract struct {
+	Spec       string
+	Operation  string
+	Violations []ContractViolation
+}
+
+type ContractViolation struct {
+	Kind    string
+	Path    string
+	Message string
+}
+
+func contractFailed(info *Contract) bool {
+	return info != nil && len(info.Violations) > 0
+}
+
+// Snapshot is the outcome of an @snapshot check. Status is matched, created,
+// updated, mismatch or error, and Diff is set only on a mismatch.
+type Snapshot struct {
+	Path   string
+	Status string
+	Diff   string
+	Error  string
+}
+
+func snapshotFailed(info *Snapshot) bool {
+	return info != nil && (info.Status == "mismatch" || info.Status == "error")
+}
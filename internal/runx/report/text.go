@@ -85,6 +85,12 @@ func writeText(w io.Writer, rep *Report, painter TextPainter) error {
 		); err != nil {
 			return err
 		}
+		if err := writeTextContractDetails(w, "  ", res.Contract, st); err != nil {
+			return err
+		}
+		if err := writeTextSnapshotDetails(w, "  ", res.Snapshot, st); err != nil {
+			return err
+		}
 		for i, step := range res.Steps {
 			if _, err := fmt.Fprintf(
 				w,
@@ -113,6 +119,12 @@ func writeText(w io.Writer, rep *Report, painter TextPainter) error {
 			); err != nil {
 				return err
 			}
+			if err := writeTextContractDetails(w, "    ", step.Contract, st); err != nil {
+				return err
+			}
+			if err := writeTextSnapshotDetails(w, "    ", step.Snapshot, st); err != nil {
+				return err
+			}
 		}
 	}
 	_, err := fmt.Fprintf(
@@ -161,6 +173,48 @@ func writeTextErrorDetail(
 	return err
 }
 
+func writeTextContractDetails(w io.Writer, indent string, info *Contract, st textStyler) error {
+	lines := contractViolationLines(info)
+	if len(lines) == 0 {
+		return nil
+	}
+	label := info.Operation
+	if info.Spec != "" {
+		label = strings.TrimSpace(label + " (" + info.Spec + ")")
+	}
+	if _, err := fmt.Fprintf(w, "%s%s\n", indent, st.detail("Contract", label)); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(w, "%s  %s\n", indent, st.value("- "+line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTextSnapshotDetails notes written snapshots and prints the diff of a
+// mismatch. A matching snapshot prints nothing.
+func writeTextSnapshotDetails(w io.Writer, indent string, info *Snapshot, st textStyler) error {
+	if info == nil || info.Status == "matched" {
+		return nil
+	}
+	label := strings.TrimSpace(info.Path + " (" + info.Status + ")")
+	if _, err := fmt.Fprintf(w, "%s%s\n", indent, st.detail("Snapshot", label)); err != nil {
+		return err
+	}
+	body := info.Diff
+	if info.Error != "" {
+		body = info.Error
+	}
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "%s\n", indentBlock(styleErrorDetailBlock(body, st), indent+"  "))
+	return err
+}
+
 func styleErrorDetailBlock(text string, st textStyler) string {
 	if text == "" {
 		return ""
@@ -194,7 +194,29 @@ func requestIssues(res runner.Result, st styler) string {
 	}
 
 	tests := testsText(res, st)
-	return bodyfmt.JoinSections(errs, tests)
+	return bodyfmt.JoinSections(errs, tests, snapshotDiffText(res, st))
+}
+
+// snapshotDiffText shows what changed against the @snapshot file, since the
+// failed test row only counts the lines.
+func snapshotDiffText(res runner.Result, st styler) string {
+	if res.Snapshot == nil || res.Snapshot.Diff == "" {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(res.Snapshot.Diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = st.label(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = st.value(line, toneSuccess)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = st.value(line, toneWarn)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = st.value(line, toneNeutral)
+		}
+	}
+	return st.sectionWarn("Snapshot Diff:") + "\n" + indent(strings.Join(lines, "\n"), "  ")
 }
 
 func diagnosticIssue(label string, err error, st styler) string {
@@ -18,6 +18,7 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/binaryview"
 	"github.com/unkn0wn-root/resterm/internal/diag"
 	"github.com/unkn0wn-root/resterm/internal/filelookup"
+	"github.com/unkn0wn-root/resterm/internal/jsonschema"
 	"github.com/unkn0wn-root/resterm/internal/prerequest"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/util"
@@ -184,6 +185,7 @@ func (r *Runner) executeTestScript(
 	streamInfo := input.Stream.Clone()
 	tester := newTestAPI(input, streamInfo)
 	tester.vm = vm
+	tester.readFile = r.fs.ReadFile
 	streamBinding := newStreamAPI(vm, streamInfo)
 
 	if err := bindCommon(vm); err != nil {
@@ -459,6 +461,8 @@ type testAPI struct {
 	stream    *StreamInfo
 	trace     *traceBinding
 	vm        *goja.Runtime
+	baseDir   string
+	readFile  jsonschema.ReadFunc
 }
 
 func newTestAPI(in TestInput, stream *StreamInfo) *testAPI {
@@ -469,6 +473,7 @@ func newTestAPI(in TestInput, stream *StreamInfo) *testAPI {
 		secrets:   in.Secrets,
 		stream:    stream,
 		trace:     newTraceBinding(in.Trace),
+		baseDir:   in.BaseDir,
 	}
 }
 
@@ -569,8 +574,9 @@ func (api *streamAPI) replay() error {
 
 func (api *testAPI) testsAPI() map[string]any {
 	return map[string]any{
-		"assert": api.assert,
-		"fail":   api.fail,
+		"assert":        api.assert,
+		"fail":          api.fail,
+		"matchesSchema": api.matchesSchema,
 	}
 }
 
@@ -835,6 +841,97 @@ func (api *testAPI) fail(message string) {
 	})
 }
 
+// matchesSchema records a test for value against a JSON Schema, given as a
+// path relative to the request file or as an inline object.
+func (api *testAPI) matchesSchema(value, schema goja.Value, message string) bool {
+	name := message
+	if name == "" {
+		name = "matches schema"
+		if path, ok := schema.Export().(string); ok {
+			name += " " + path
+		}
+	}
+	result := TestResult{Name: name}
+	errs, err := api.schemaErrors(value, schema)
+	switch {
+	case err != nil:
+		result.Message = err.Error()
+	case len(errs) > 0:
+		result.Message = schemaMessage(errs)
+	default:
+		result.Passed = true
+	}
+	api.cases = append(api.cases, result)
+	return result.Passed
+}
+
+func (api *testAPI) schemaErrors(value, schema goja.Value) ([]jsonschema.Error, error) {
+	if schema == nil || goja.IsUndefined(schema) || goja.IsNull(schema) {
+		return nil, errors.New("tests.matchesSchema requires a schema path or object")
+	}
+	var (
+		s   *jsonschema.Schema
+		err error
+	)
+	dir := api.baseDir
+	if dir == "" {
+		dir = "."
+	}
+	if path, ok := schema.Export().(string); ok {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		s, err = jsonschema.Load(path, api.readFile)
+	} else {
+		var doc any
+		doc, err = exportJSON(schema)
+		if err == nil {
+			s, err = jsonschema.Compile(doc, dir, api.readFile)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	v, err := exportJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	return s.Validate(v), nil
+}
+
+// exportJSON turns a script value into plain decoded JSON, so Go maps handed
+// to the script and objects built in it look the same to the validator.
+func exportJSON(v goja.Value) (any, error) {
+	if v == nil || goja.IsUndefined(v) {
+		return nil, nil
+	}
+	data, err := json.Marshal(v.Export())
+	if err != nil {
+		return nil, fmt.Errorf("value is not JSON: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("value is not JSON: %w", err)
+	}
+	return out, nil
+}
+
+// maxSchemaErrors is how many schema errors a test message lists.
+const maxSchemaErrors = 3
+
+func schemaMessage(errs []jsonschema.Error) string {
+	n := min(len(errs), maxSchemaErrors)
+	parts := make([]string, n)
+	for i := range n {
+		parts[i] = errs[i].String()
+	}
+	msg := strings.Join(parts, "; ")
+	if rest := len(errs) - n; rest > 0 {
+		msg += fmt.Sprintf(" (+%d more)", rest)
+	}
+	return msg
+}
+
 func (api *testAPI) namedTest(name string, callable goja.Callable) {
 	start := time.Now()
 	passed := true
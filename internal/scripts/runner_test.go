@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -293,6 +294,58 @@ func TestRunTestsScripts(t *testing.T) {
 	}
 }
 
+func TestRunTestsMatchesSchema(t *testing.T) {
+	dir := t.TempDir()
+	schema := `{
+	  "type": "object",
+	  "required": ["items"],
+	  "properties": {"items": {"type": "array", "items": {"$ref": "#/$defs/item"}}},
+	  "$defs": {"item": {"type": "object", "properties": {"price": {"type": "number"}}}}
+	}`
+	if err := os.MkdirAll(filepath.Join(dir, "schemas"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "schemas", "order.json"), []byte(schema), 0o600); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	response := &Response{
+		Kind: ResponseKindHTTP,
+		Code: 200,
+		Body: []byte(`{"items":[{"price":1},{"price":"2"}]}`),
+	}
+	script := `
+var ok = tests.matchesSchema(response.json(), "./schemas/order.json");
+tests.assert(ok === false, "returns the outcome");
+tests.matchesSchema({items: [{price: 3}]}, "schemas/order.json", "built in script");
+tests.matchesSchema({id: 1.5}, {properties: {id: {type: "integer"}}}, "inline schema");
+tests.matchesSchema({}, "missing.json");
+`
+	results, _, err := NewRunner(nil).RunTests(
+		[]restfile.ScriptBlock{{Kind: "test", Body: script}},
+		TestInput{Response: response, BaseDir: dir},
+	)
+	if err != nil {
+		t.Fatalf("run tests: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected five results, got %+v", results)
+	}
+	if r := results[0]; r.Passed || r.Name != "matches schema ./schemas/order.json" ||
+		r.Message != "/items/1/price: expected number" {
+		t.Fatalf("response check = %+v", r)
+	}
+	if !results[1].Passed || !results[2].Passed {
+		t.Fatalf("expected passing checks, got %+v", results[1:3])
+	}
+	if r := results[3]; r.Passed || r.Name != "inline schema" || r.Message != "/id: expected integer" {
+		t.Fatalf("inline check = %+v", r)
+	}
+	if r := results[4]; r.Passed || !strings.Contains(r.Message, "invalid schema") {
+		t.Fatalf("missing schema = %+v", r)
+	}
+}
+
 func TestRunTestsScriptsStream(t *testing.T) {
 	runner := NewRunner(nil)
 	response := &Response{
@@ -299,9 +299,10 @@ func (tb *traceBinding) exportBreaches() []map[string]any {
 
 func exportSegment(seg traceSegment) map[string]any {
 	meta := map[string]any{
-		"addr":   seg.Meta.Addr,
-		"reused": seg.Meta.Reused,
-		"cached": seg.Meta.Cached,
+		"addr":     seg.Meta.Addr,
+		"reused":   seg.Meta.Reused,
+		"cached":   seg.Meta.Cached,
+		"override": seg.Meta.Override,
 	}
 
 	result := map[string]any{
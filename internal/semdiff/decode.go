@@ -0,0 +1,128 @@
+package semdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Format is the document type a body was decoded as.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatXML  Format = "xml"
+)
+
+// Decode parses body as JSON or XML. The content type picks the format when
+// it names one; otherwise the first non-space byte does. ok is false for
+// bodies that are neither.
+func Decode(body []byte, contentType string) (any, Format, bool) {
+	ct := strings.ToLower(contentType)
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, "", false
+	}
+	switch {
+	case strings.Contains(ct, "json"):
+		v, err := DecodeJSON(trimmed)
+		return v, FormatJSON, err == nil
+	case strings.Contains(ct, "xml"):
+		v, err := DecodeXML(trimmed)
+		return v, FormatXML, err == nil
+	}
+	switch trimmed[0] {
+	case '{', '[':
+		v, err := DecodeJSON(trimmed)
+		return v, FormatJSON, err == nil
+	case '<':
+		v, err := DecodeXML(trimmed)
+		return v, FormatXML, err == nil
+	}
+	return nil, "", false
+}
+
+// DecodeJSON keeps numbers as json.Number so 1.0 and 1 compare equal without
+// losing precision on large integers.
+func DecodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("trailing data after JSON value")
+	}
+	return v, nil
+}
+
+// DecodeXML turns a document into the same shape as decoded JSON, so both
+// share one diff. The root element becomes a single-key object. An element
+// with only text becomes that text; otherwise it is an object holding "@name"
+// for attributes, child elements by local name (an array when repeated) and
+// "#text" for its trimmed text.
+func DecodeXML(data []byte) (any, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			v, err := xmlElement(dec, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{start.Name.Local: v}, nil
+		}
+	}
+}
+
+func xmlElement(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	obj := map[string]any{}
+	for _, a := range start.Attr {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		obj["@"+a.Name.Local] = a.Value
+	}
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := xmlElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch prev := obj[name].(type) {
+			case nil:
+				obj[name] = child
+			case []any:
+				obj[name] = append(prev, child)
+			default:
+				obj[name] = []any{prev, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(obj) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				obj["#text"] = s
+			}
+			return obj, nil
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package semdiff
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// step is one segment of a path to a value. Array elements matched by a key
+// field carry that field and its value instead of a position, so the same
+// element keeps the same path when the array is reordered.
+type step struct {
+	key   string
+	index int
+	isIdx bool
+	field string
+	value string
+	// ends holds the element's position counted from the end of each array
+	// that has it, as negative indexes, so [-1] finds the last element.
+	ends []int
+}
+
+func keyStep(k string) step { return step{key: k} }
+
+func indexStep(i int, lens ...int) step {
+	s := step{index: i, isIdx: true}
+	for _, n := range lens {
+		if i < n {
+			s.ends = append(s.ends, i-n)
+		}
+	}
+	return s
+}
+
+func fieldStep(field, value string) step { return step{isIdx: true, field: field, value: value} }
+
+var plainKey = regexp.MustCompile(`^[A-Za-z_@#$][A-Za-z0-9_@#$-]*$`)
+
+func (s step) String() string {
+	switch {
+	case s.field != "":
+		return "[" + s.field + "=" + s.value + "]"
+	case s.isIdx:
+		return "[" + strconv.Itoa(s.index) + "]"
+	case plainKey.MatchString(s.key):
+		return "." + s.key
+	default:
+		return "[" + strconv.Quote(s.key) + "]"
+	}
+}
+
+func pathString(steps []step) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, s := range steps {
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+// Pattern is a parsed ignore rule, shared by @compare and @snapshot. It is a
+// JSONPath subset: $ for the root, .name or ["name"] for a member, [n] for an
+// element with [-1] the last, .* and [*] for any member or element, and ..
+// before any of those to match it at any depth. A pattern that matches a
+// value also matches everything inside it.
+type Pattern struct {
+	raw   string
+	parts []part
+}
+
+type partKind int
+
+const (
+	partKey partKind = iota
+	partIndex
+	partAny
+)
+
+type part struct {
+	kind  partKind
+	key   string
+	index int
+	deep  bool
+}
+
+func (p Pattern) String() string { return p.raw }
+
+// ParsePattern parses an ignore rule such as $.items[*].updatedAt. The
+// leading $ may be left out.
+func ParsePattern(s string) (Pattern, error) {
+	raw := strings.TrimSpace(s)
+	if raw == "" {
+		return Pattern{}, fmt.Errorf("empty path")
+	}
+	rest, rooted := strings.CutPrefix(raw, "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		if rooted {
+			return Pattern{}, fmt.Errorf("path %q: expected . or [ after $", raw)
+		}
+		rest = "." + rest
+	}
+	p := Pattern{raw: raw}
+	for rest != "" {
+		deep := false
+		if after, ok := strings.CutPrefix(rest, ".."); ok {
+			deep = true
+			rest = after
+			if rest == "" || rest[0] == '.' {
+				return Pattern{}, fmt.Errorf("path %q: expected a name after ..", raw)
+			}
+			if rest[0] != '[' {
+				rest = "." + rest
+			}
+		}
+		var pt part
+		switch {
+		case rest[0] == '.':
+			name, n := readName(rest[1:])
+			switch name {
+			case "":
+				return Pattern{}, fmt.Errorf("path %q: expected a name after .", raw)
+			case "*":
+				pt = part{kind: partAny}
+			default:
+				pt = part{kind: partKey, key: name}
+			}
+			rest = rest[1+n:]
+		case rest[0] == '[':
+			end := closing(rest)
+			if end < 0 {
+				return Pattern{}, fmt.Errorf("path %q: unclosed [", raw)
+			}
+			var err error
+			if pt, err = bracket(rest[1:end]); err != nil {
+				return Pattern{}, fmt.Errorf("path %q: %w", raw, err)
+			}
+			rest = rest[end+1:]
+		default:
+			return Pattern{}, fmt.Errorf("path %q: unexpected %q", raw, rest[:1])
+		}
+		pt.deep = deep
+		p.parts = append(p.parts, pt)
+	}
+	return p, nil
+}
+
+// Root reports whether the pattern is $ alone, which matches the whole value.
+func (p Pattern) Root() bool { return len(p.parts) == 0 }
+
+// SplitList splits a comma-separated list of paths or fields, leaving commas
+// inside brackets alone.
+func SplitList(s string) []string {
+	var out []string
+	depth, quote, start := 0, byte(0), 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case c == ',' && depth == 0:
+			if item := strings.TrimSpace(s[start:i]); item != "" {
+				out = append(out, item)
+			}
+			start = i + 1
+		}
+	}
+	if item := strings.TrimSpace(s[start:]); item != "" {
+		out = append(out, item)
+	}
+	return out
+}
+
+func readName(s string) (string, int) {
+	n := 0
+	for n < len(s) && s[n] != '.' && s[n] != '[' {
+		n++
+	}
+	return strings.TrimSpace(s[:n]), n
+}
+
+// closing finds the ] that ends the bracket at s[0], skipping quoted names.
+func closing(s string) int {
+	quote := byte(0)
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func bracket(inner string) (part, error) {
+	inner = strings.TrimSpace(inner)
+	switch {
+	case inner == "*":
+		return part{kind: partAny}, nil
+	case strings.HasPrefix(inner, `"`):
+		key, err := strconv.Unquote(inner)
+		if err != nil {
+			return part{}, fmt.Errorf("bad quoted name %s", inner)
+		}
+		return part{kind: partKey, key: key}, nil
+	case strings.HasPrefix(inner, "'") && strings.HasSuffix(inner, "'") && len(inner) >= 2:
+		return part{kind: partKey, key: inner[1 : len(inner)-1]}, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return part{}, fmt.Errorf("bad index [%s]", inner)
+	}
+	return part{kind: partIndex, index: n}, nil
+}
+
+// matches reports whether the pattern matches steps or one of its parents.
+func (p Pattern) matches(steps []step) bool {
+	return matchFrom(p.parts, steps)
+}
+
+func matchFrom(parts []part, steps []step) bool {
+	if len(parts) == 0 {
+		return true
+	}
+	pt := parts[0]
+	for i := range steps {
+		if pt.matches(steps[i]) && matchFrom(parts[1:], steps[i+1:]) {
+			return true
+		}
+		if !pt.deep {
+			break
+		}
+	}
+	return false
+}
+
+func (pt part) matches(s step) bool {
+	switch pt.kind {
+	case partKey:
+		return !s.isIdx && s.key == pt.key
+	case partIndex:
+		if !s.isIdx || s.field != "" {
+			return false
+		}
+		if pt.index < 0 {
+			return slices.Contains(s.ends, pt.index)
+		}
+		return s.index == pt.index
+	}
+	return true
+}
+
+// Replace sets every value the pattern matches in v to repl and returns the
+// possibly new v. Objects and arrays are changed in place.
+func (p Pattern) Replace(v, repl any) any {
+	return replace(p.parts, v, repl)
+}
+
+func replace(parts []part, v, repl any) any {
+	if len(parts) == 0 {
+		return repl
+	}
+	pt, rest := parts[0], parts[1:]
+	switch x := v.(type) {
+	case map[string]any:
+		for k, c := range x {
+			if pt.kind == partAny || (pt.kind == partKey && k == pt.key) {
+				c = replace(rest, c, repl)
+				x[k] = c
+			}
+			if pt.deep {
+				x[k] = replace(parts, c, repl)
+			}
+		}
+	case []any:
+		for i, c := range x {
+			if pt.kind == partAny || (pt.kind == partIndex && (i == pt.index || i-len(x) == pt.index)) {
+				c = replace(rest, c, repl)
+				x[i] = c
+			}
+			if pt.deep {
+				x[i] = replace(parts, c, repl)
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,315 @@
+// Package semdiff compares JSON and XML documents by structure rather than
+// by text. Key order, whitespace and number formatting do not count as
+// changes; each change is addressed by a JSONPath-like path. Ignore rules
+// drop volatile fields such as timestamps, and arrays of objects can be
+// matched by a key field instead of by position.
+package semdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Change is one difference between the old and the new document. Old is nil
+// for an added value and New is nil for a removed one.
+type Change struct {
+	Path string
+	Kind Kind
+	Old  any
+	New  any
+}
+
+// Options control what counts as a change. Match lists key fields, such as
+// id, tried in order: an array whose elements are all objects carrying the
+// same field is matched on that field's value.
+type Options struct {
+	Ignore []Pattern
+	Match  []string
+}
+
+// Compile parses ignore rules and match fields as written in @compare.
+func Compile(ignore, match []string) (Options, error) {
+	var opt Options
+	for _, raw := range ignore {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		p, err := ParsePattern(raw)
+		if err != nil {
+			return Options{}, err
+		}
+		opt.Ignore = append(opt.Ignore, p)
+	}
+	for _, f := range match {
+		if f = strings.TrimSpace(f); f != "" {
+			opt.Match = append(opt.Match, f)
+		}
+	}
+	return opt, nil
+}
+
+// Compare lists the changes from a to b, both decoded JSON values.
+func Compare(a, b any, opt Options) []Change {
+	d := &differ{opt: opt}
+	d.walk(nil, a, b)
+	return d.out
+}
+
+// Bodies decodes two response bodies and compares them. ok is false when
+// either body is not JSON or XML, or the two are different formats; callers
+// then fall back to a text diff.
+func Bodies(a, b []byte, typeA, typeB string, opt Options) ([]Change, Format, bool) {
+	va, fa, ok := Decode(a, typeA)
+	if !ok {
+		return nil, "", false
+	}
+	vb, fb, ok := Decode(b, typeB)
+	if !ok || fa != fb {
+		return nil, "", false
+	}
+	return Compare(va, vb, opt), fa, true
+}
+
+type differ struct {
+	opt Options
+	out []Change
+}
+
+func (d *differ) ignored(steps []step) bool {
+	for _, p := range d.opt.Ignore {
+		if p.matches(steps) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *differ) add(steps []step, kind Kind, a, b any) {
+	d.out = append(d.out, Change{Path: pathString(steps), Kind: kind, Old: a, New: b})
+}
+
+func (d *differ) walk(steps []step, a, b any) {
+	if d.ignored(steps) {
+		return
+	}
+	switch x := a.(type) {
+	case map[string]any:
+		if y, ok := b.(map[string]any); ok {
+			d.object(steps, x, y)
+			return
+		}
+	case []any:
+		if y, ok := b.([]any); ok {
+			d.array(steps, x, y)
+			return
+		}
+	}
+	if !scalarEqual(a, b) {
+		d.add(steps, Changed, a, b)
+	}
+}
+
+func (d *differ) object(steps []step, a, b map[string]any) {
+	keys := slices.Sorted(maps.Keys(a))
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		next := append(slices.Clip(steps), keyStep(k))
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case inA && inB:
+			d.walk(next, va, vb)
+		case d.ignored(next):
+		case inA:
+			d.add(next, Removed, va, nil)
+		default:
+			d.add(next, Added, nil, vb)
+		}
+	}
+}
+
+func (d *differ) array(steps []step, a, b []any) {
+	if field := d.matchField(a, b); field != "" {
+		d.keyed(steps, field, a, b)
+		return
+	}
+	for i := range max(len(a), len(b)) {
+		next := append(slices.Clip(steps), indexStep(i, len(a), len(b)))
+		switch {
+		case i < len(a) && i < len(b):
+			d.walk(next, a[i], b[i])
+		case d.ignored(next):
+		case i < len(a):
+			d.add(next, Removed, a[i], nil)
+		default:
+			d.add(next, Added, nil, b[i])
+		}
+	}
+}
+
+// matchField picks the first match field that every element of both arrays
+// carries as a scalar with a unique value.
+func (d *differ) matchField(a, b []any) string {
+	if len(d.opt.Match) == 0 || len(a)+len(b) == 0 {
+		return ""
+	}
+	for _, f := range d.opt.Match {
+		if uniqueKeys(a, f) && uniqueKeys(b, f) {
+			return f
+		}
+	}
+	return ""
+}
+
+func uniqueKeys(items []any, field string) bool {
+	seen := map[string]bool{}
+	for _, it := range items {
+		k, ok := keyOf(it, field)
+		if !ok || seen[k] {
+			return false
+		}
+		seen[k] = true
+	}
+	return true
+}
+
+func keyOf(v any, field string) (string, bool) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	switch k := obj[field].(type) {
+	case string:
+		return k, true
+	case json.Number:
+		return k.String(), true
+	case float64, int, bool:
+		return fmt.Sprint(k), true
+	}
+	return "", false
+}
+
+// keyed compares elements with the same key value. Elements are reported in
+// the old array's order, then new elements in the new array's order; a
+// reordering alone is not a change.
+func (d *differ) keyed(steps []step, field string, a, b []any) {
+	byKey := make(map[string]any, len(b))
+	for _, it := range b {
+		k, _ := keyOf(it, field)
+		byKey[k] = it
+	}
+	seen := make(map[string]bool, len(a))
+	for _, it := range a {
+		k, _ := keyOf(it, field)
+		seen[k] = true
+		next := append(slices.Clip(steps), fieldStep(field, k))
+		if other, ok := byKey[k]; ok {
+			d.walk(next, it, other)
+		} else if !d.ignored(next) {
+			d.add(next, Removed, it, nil)
+		}
+	}
+	for _, it := range b {
+		k, _ := keyOf(it, field)
+		next := append(slices.Clip(steps), fieldStep(field, k))
+		if !seen[k] && !d.ignored(next) {
+			d.add(next, Added, nil, it)
+		}
+	}
+}
+
+// scalarEqual compares numbers by value, so 1, 1.0 and 1e0 are equal.
+func scalarEqual(a, b any) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x.Cmp(y) == 0
+	}
+	switch a.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	switch b.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return a == b
+}
+
+func number(v any) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(n.String())
+	case float64:
+		// The shortest decimal form, so 0.1 equals the JSON text 0.1.
+		return new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	}
+	return nil, false
+}
+
+// String renders the change as one line: "+ path: value" for additions,
+// "- path: value" for removals and "~ path: old → new" otherwise.
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return "+ " + c.Path + ": " + Render(c.New)
+	case Removed:
+		return "- " + c.Path + ": " + Render(c.Old)
+	default:
+		return "~ " + c.Path + ": " + Render(c.Old) + " → " + Render(c.New)
+	}
+}
+
+const maxRender = 120
+
+// Render shows a value as compact JSON, shortened past maxRender bytes.
+func Render(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := string(data)
+	if len(s) > maxRender {
+		n := maxRender - 3
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n] + "..."
+	}
+	return s
+}
+
+// Summary names the first changed path and how many more there are, such
+// as "$.price (+2 more)".
+func Summary(changes []Change) string {
+	switch len(changes) {
+	case 0:
+		return ""
+	case 1:
+		return changes[0].Path
+	default:
+		return fmt.Sprintf("%s (+%d more)", changes[0].Path, len(changes)-1)
+	}
+}
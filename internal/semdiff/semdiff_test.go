@@ -0,0 +1,133 @@
+package semdiff
+
+import (
+	"slices"
+	"testing"
+)
+
+func lines(changes []Change) []string {
+	out := make([]string, len(changes))
+	for i, c := range changes {
+		out[i] = c.String()
+	}
+	return out
+}
+
+func TestBodiesIgnoresFormattingAndOrder(t *testing.T) {
+	a := []byte(`{"total": 2, "meta": {"requestId": "a1"}, "items": [{"id": 1, "price": 10}, {"id": 2, "price": 5.0}]}`)
+	b := []byte(`{
+  "items": [{"price": 5, "id": 2}, {"id": 1, "price": 1e1}],
+  "meta": {"requestId": "b2"},
+  "total": 2.0
+}`)
+	opt, err := Compile([]string{"$.meta.requestId"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	changes, format, ok := Bodies(a, b, "application/json", "", opt)
+	if !ok || format != FormatJSON {
+		t.Fatalf("Bodies() ok=%t format=%q", ok, format)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("changes = %v", lines(changes))
+	}
+
+	changes, _, _ = Bodies(a, b, "application/json", "", Options{})
+	want := []string{
+		`~ $.items[0].id: 1 → 2`,
+		`~ $.items[0].price: 10 → 5`,
+		`~ $.items[1].id: 2 → 1`,
+		`~ $.items[1].price: 5.0 → 1e1`,
+		`~ $.meta.requestId: "a1" → "b2"`,
+	}
+	if got := lines(changes); !slices.Equal(got, want) {
+		t.Fatalf("changes = %q\nwant %q", got, want)
+	}
+}
+
+func TestCompareKeyedArrays(t *testing.T) {
+	a, _ := DecodeJSON([]byte(`{"items": [{"id": "a", "n": 1, "updatedAt": "x"}, {"id": "b", "n": 2}]}`))
+	b, _ := DecodeJSON([]byte(`{"items": [{"id": "c", "n": 3}, {"id": "a", "n": 4, "updatedAt": "y"}]}`))
+	opt, err := Compile([]string{"$.items[*].updatedAt"}, []string{"sku", "id"})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	want := []string{
+		`~ $.items[id=a].n: 1 → 4`,
+		`- $.items[id=b]: {"id":"b","n":2}`,
+		`+ $.items[id=c]: {"id":"c","n":3}`,
+	}
+	if got := lines(Compare(a, b, opt)); !slices.Equal(got, want) {
+		t.Fatalf("changes = %q\nwant %q", got, want)
+	}
+	if got := Summary(Compare(a, b, opt)); got != "$.items[id=a].n (+2 more)" {
+		t.Fatalf("Summary() = %q", got)
+	}
+}
+
+func TestBodiesXML(t *testing.T) {
+	a := []byte(`<order id="7"><item sku="a">1</item><item sku="b">2</item><stamp>t1</stamp></order>`)
+	b := []byte("<order id=\"7\">\n  <stamp>t2</stamp>\n  <item sku=\"b\">3</item>\n  <item sku=\"a\">1</item>\n</order>")
+	opt, err := Compile([]string{"..stamp"}, []string{"@sku"})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	changes, format, ok := Bodies(a, b, "application/xml", "text/xml", opt)
+	if !ok || format != FormatXML {
+		t.Fatalf("Bodies() ok=%t format=%q", ok, format)
+	}
+	want := []string{`~ $.order.item[@sku=b].#text: "2" → "3"`}
+	if got := lines(changes); !slices.Equal(got, want) {
+		t.Fatalf("changes = %q\nwant %q", got, want)
+	}
+	if _, _, ok := Bodies(a, []byte(`{"order": 1}`), "", "", Options{}); ok {
+		t.Fatalf("expected mixed formats to fall back")
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	for _, bad := range []string{"", "$.", "$.items[", "$.items[x]", "$..", "$items"} {
+		if _, err := ParsePattern(bad); err == nil {
+			t.Errorf("ParsePattern(%q) succeeded", bad)
+		}
+	}
+	p, err := ParsePattern(`$["display.name"].tags[*]`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !p.matches([]step{keyStep("display.name"), keyStep("tags"), indexStep(3), keyStep("x")}) {
+		t.Fatalf("pattern should match a child of an element")
+	}
+	if p.matches([]step{keyStep("display.name"), keyStep("tags")}) {
+		t.Fatalf("pattern should not match the parent")
+	}
+
+	last, _ := ParsePattern("items[-1]")
+	if !last.matches([]step{keyStep("items"), indexStep(2, 3, 5)}) || last.matches([]step{keyStep("items"), indexStep(2)}) {
+		t.Fatalf("[-1] should match the last element of either array only")
+	}
+	anyDeep, _ := ParsePattern("$..*.id")
+	if !anyDeep.matches([]step{keyStep("a"), indexStep(0), keyStep("id")}) {
+		t.Fatalf(".* should match elements as well as members")
+	}
+}
+
+// @snapshot ignore= reads the same paths and masks what they match in place.
+func TestPatternReplace(t *testing.T) {
+	v := map[string]any{
+		"id":    "1",
+		"items": []any{map[string]any{"id": "a"}, map[string]any{"id": "b", "meta": map[string]any{"id": "c"}}},
+	}
+	for _, raw := range []string{"$.id", "items[-1].id", "$..meta.id"} {
+		p, err := ParsePattern(raw)
+		if err != nil {
+			t.Fatalf("parse %s: %v", raw, err)
+		}
+		p.Replace(v, "x")
+	}
+	items := v["items"].([]any)
+	if v["id"] != "x" || items[0].(map[string]any)["id"] != "a" || items[1].(map[string]any)["id"] != "x" ||
+		items[1].(map[string]any)["meta"].(map[string]any)["id"] != "x" {
+		t.Fatalf("replaced = %v", v)
+	}
+}
@@ -67,9 +67,48 @@ func ApplyHTTPSettings(
 	if len(settings) == 0 {
 		return nil
 	}
+	if err := applyProxyAuth(opts, settings, resolver); err != nil {
+		return err
+	}
 	return httpx.ApplyOptionSettings(opts, settings)
 }
 
+// applyProxyAuth reads the proxy credentials. They usually come from a
+// secret env value, so templates expand here, as they do for the TLS paths.
+func applyProxyAuth(
+	opts *httpx.Options,
+	settings map[string]string,
+	resolver *vars.Resolver,
+) error {
+	norm := normalize(settings)
+	for _, s := range []struct {
+		key string
+		dst *string
+	}{
+		{key: "proxy-user", dst: &opts.ProxyUser},
+		{key: "proxy-password", dst: &opts.ProxyPassword},
+	} {
+		val, ok := norm[s.key]
+		if !ok {
+			continue
+		}
+		if resolver != nil {
+			expanded, err := resolver.ExpandTemplates(val)
+			if err != nil {
+				return diag.WrapAs(
+					diag.ClassProtocol,
+					err,
+					"expand "+s.key,
+					diag.WithComponent(diag.ComponentHTTP),
+				)
+			}
+			val = expanded
+		}
+		*s.dst = strings.TrimSpace(val)
+	}
+	return nil
+}
+
 func applyTLSSettings(
 	cfg *tlsconfig.Files,
 	settings map[string]string,
@@ -5,6 +5,9 @@ import "strings"
 var httpSettingKeys = map[string]struct{}{
 	"timeout":         {},
 	"proxy":           {},
+	"proxy-rules":     {},
+	"proxy-user":      {},
+	"proxy-password":  {},
 	"followredirects": {},
 	"insecure":        {},
 	"no-cookies":      {},
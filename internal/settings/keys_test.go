@@ -6,6 +6,8 @@ func TestIsHTTPKey(t *testing.T) {
 	ok := []string{
 		"timeout",
 		"proxy",
+		"proxy-rules",
+		"PROXY-PASSWORD",
 		"followredirects",
 		"insecure",
 		"no-cookies",
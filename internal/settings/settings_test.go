@@ -10,6 +10,7 @@ import (
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/tlsconfig"
+	"github.com/unkn0wn-root/resterm/internal/vars"
 )
 
 func TestApplyAllDispatchesHandlers(t *testing.T) {
@@ -115,6 +116,28 @@ func TestApplyAllHTTPAggregated(t *testing.T) {
 	}
 }
 
+func TestApplyHTTPSettingsExpandsProxyCredentials(t *testing.T) {
+	res := vars.NewResolver(vars.NewMapProvider("env", map[string]string{
+		"proxy.user": "svc-api",
+		"proxy.pass": "s3cr3t",
+	}))
+	opts := httpx.Options{}
+	err := ApplyHTTPSettings(&opts, map[string]string{
+		"proxy-rules":    "*.corp.internal=DIRECT, *=socks5h://gw.corp:1080",
+		"proxy-user":     "{{proxy.user}}",
+		"proxy-password": "{{proxy.pass}}",
+	}, res)
+	if err != nil {
+		t.Fatalf("apply proxy settings: %v", err)
+	}
+	if opts.ProxyUser != "svc-api" || opts.ProxyPassword != "s3cr3t" {
+		t.Fatalf("proxy credentials = %q / %q", opts.ProxyUser, opts.ProxyPassword)
+	}
+	if len(opts.ProxyRules) != 2 || opts.ProxyRules[1].Proxy != "socks5h://gw.corp:1080" {
+		t.Fatalf("proxy rules = %+v", opts.ProxyRules)
+	}
+}
+
 func TestApplyAllHTTPInvalidVersionReturnsError(t *testing.T) {
 	httpOpts := httpx.Options{}
 	applier := New(HTTPHandler(&httpOpts, nil))
@@ -0,0 +1,31 @@
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
+)
+
+// Ignored replaces every value an ignore path matches, so the key stays in
+// the snapshot and its presence is still checked.
+const Ignored = "<ignored>"
+
+// CheckPath reports whether raw is an ignore path parsePath accepts.
+func CheckPath(raw string) error {
+	_, err := parsePath(raw)
+	return err
+}
+
+// parsePath reads an ignore path in the grammar @compare ignore= uses, so
+// one path works in both. Only $ alone is refused: it would mask the whole
+// body and leave nothing to compare.
+func parsePath(raw string) (semdiff.Pattern, error) {
+	p, err := semdiff.ParsePattern(raw)
+	if err != nil {
+		return semdiff.Pattern{}, fmt.Errorf("ignore path: %w", err)
+	}
+	if p.Root() {
+		return semdiff.Pattern{}, fmt.Errorf("ignore path %q matches the whole body", raw)
+	}
+	return p, nil
+}
@@ -0,0 +1,201 @@
+// Package snapshot compares response bodies with golden files. JSON bodies
+// are stored normalized, with sorted keys, two-space indentation and ignored
+// values masked, so a mismatch diffs structurally line by line.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	udiff "github.com/aymanbagabas/go-udiff"
+
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
+)
+
+type Status string
+
+const (
+	StatusMatched  Status = "matched"
+	StatusCreated  Status = "created"
+	StatusUpdated  Status = "updated"
+	StatusMismatch Status = "mismatch"
+	StatusError    Status = "error"
+)
+
+// Result is the outcome of one snapshot check. Name is the path as the
+// request file wrote it and Path the file on disk. Diff is a unified diff from
+// the snapshot to the response and is only set on a mismatch.
+type Result struct {
+	Name    string
+	Path    string
+	Status  Status
+	Diff    string
+	Added   int
+	Removed int
+	Err     string
+}
+
+func (r *Result) Failed() bool {
+	return r != nil && (r.Status == StatusMismatch || r.Status == StatusError)
+}
+
+// Message is a one-line summary for test rows and reports.
+func (r *Result) Message() string {
+	if r == nil {
+		return ""
+	}
+	switch r.Status {
+	case StatusCreated:
+		return "wrote new snapshot"
+	case StatusUpdated:
+		return "updated snapshot"
+	case StatusMismatch:
+		if r.Added == 0 && r.Removed == 0 {
+			return "response differs from snapshot"
+		}
+		return fmt.Sprintf("response differs from snapshot (+%d -%d lines)", r.Added, r.Removed)
+	case StatusError:
+		return r.Err
+	default:
+		return ""
+	}
+}
+
+// Options control a check. Update rewrites a snapshot that differs instead
+// of failing.
+type Options struct {
+	Name   string
+	Ignore []string
+	Update bool
+}
+
+// Check compares body with the snapshot at path. A missing snapshot is
+// written and passes, so the first run records the baseline.
+func Check(path string, body []byte, opt Options) *Result {
+	res := &Result{Name: opt.Name, Path: path}
+	if res.Name == "" {
+		res.Name = path
+	}
+	got, err := Normalize(body, opt.Ignore)
+	if err != nil {
+		return res.fail(err)
+	}
+	want, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := write(path, got); err != nil {
+			return res.fail(err)
+		}
+		res.Status = StatusCreated
+		return res
+	case err != nil:
+		return res.fail(fmt.Errorf("read snapshot: %w", err))
+	}
+	if bytes.Equal(want, got) {
+		res.Status = StatusMatched
+		return res
+	}
+	if opt.Update {
+		if err := write(path, got); err != nil {
+			return res.fail(err)
+		}
+		res.Status = StatusUpdated
+		return res
+	}
+	res.Status = StatusMismatch
+	res.diff(want, got)
+	return res
+}
+
+func (r *Result) fail(err error) *Result {
+	r.Status = StatusError
+	r.Err = err.Error()
+	return r
+}
+
+func (r *Result) diff(want, got []byte) {
+	if !utf8.Valid(want) || !utf8.Valid(got) {
+		r.Diff = fmt.Sprintf("binary content differs: snapshot %d bytes, response %d bytes\n", len(want), len(got))
+		return
+	}
+	r.Diff = udiff.Unified("snapshot "+r.Name, "response", string(want), string(got))
+	for line := range strings.SplitSeq(r.Diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			r.Added++
+		case strings.HasPrefix(line, "-"):
+			r.Removed++
+		}
+	}
+}
+
+func write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Normalize renders body the way it is stored. JSON is re-encoded with sorted
+// keys and ignored paths masked; text only has its line endings unified.
+// Binary bodies are kept as they are.
+func Normalize(body []byte, ignore []string) ([]byte, error) {
+	paths := make([]semdiff.Pattern, 0, len(ignore))
+	for _, raw := range ignore {
+		p, err := parsePath(raw)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	v, ok := decode(body)
+	if !ok {
+		if len(paths) > 0 {
+			return nil, fmt.Errorf("ignore= needs a JSON response body")
+		}
+		if !utf8.Valid(body) {
+			return body, nil
+		}
+		text := strings.ReplaceAll(string(body), "\r\n", "\n")
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		return []byte(text), nil
+	}
+	for _, p := range paths {
+		v = p.Replace(v, Ignored)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(body []byte) (any, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,98 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeMasksIgnoredPaths(t *testing.T) {
+	body := `{"z": 1, "id": 7, "items": [{"id": 1, "at": "x"}, {"id": 2, "meta": {"id": 9}}], "odd key": true, "html": "<b>"}`
+	got, err := Normalize([]byte(body), []string{"$.id", "$.items[*].at", "$..meta.id", "$['odd key']", "items[-1].id"})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	want := `{
+  "html": "<b>",
+  "id": "<ignored>",
+  "items": [
+    {
+      "at": "<ignored>",
+      "id": 1
+    },
+    {
+      "id": "<ignored>",
+      "meta": {
+        "id": "<ignored>"
+      }
+    }
+  ],
+  "odd key": "<ignored>",
+  "z": 1
+}
+`
+	if string(got) != want {
+		t.Fatalf("normalized:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = Normalize([]byte(`{"a": {"id": 1, "b": [{"id": 2}]}}`), []string{"$..id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(got), Ignored) != 2 {
+		t.Fatalf("recursive ignore:\n%s", got)
+	}
+
+	if got, _ := Normalize([]byte("line\r\nnext"), nil); string(got) != "line\nnext\n" {
+		t.Fatalf("text = %q", got)
+	}
+	if _, err := Normalize([]byte("plain"), []string{"$.id"}); err == nil {
+		t.Fatalf("expected ignore on text body to fail")
+	}
+	for _, bad := range []string{"$", "$.items[x]", "$.a[", "$['a"} {
+		if _, err := Normalize([]byte(`{}`), []string{bad}); err == nil {
+			t.Errorf("ignore path %q parsed", bad)
+		}
+	}
+}
+
+func TestCheckLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "__snapshots__", "user.json")
+	opt := Options{Name: "./__snapshots__/user.json", Ignore: []string{"$.createdAt"}}
+
+	res := Check(path, []byte(`{"id": 1, "name": "Ada", "createdAt": "t1"}`), opt)
+	if res.Status != StatusCreated || res.Failed() {
+		t.Fatalf("first run = %+v", res)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"createdAt": "<ignored>"`) {
+		t.Fatalf("written snapshot = %q (%v)", data, err)
+	}
+
+	res = Check(path, []byte(`{"createdAt": "t2", "name": "Ada", "id": 1}`), opt)
+	if res.Status != StatusMatched {
+		t.Fatalf("reordered body = %+v", res)
+	}
+
+	res = Check(path, []byte(`{"id": 1, "name": "Grace", "createdAt": "t3"}`), opt)
+	if !res.Failed() || res.Status != StatusMismatch || res.Added != 1 || res.Removed != 1 {
+		t.Fatalf("changed body = %+v", res)
+	}
+	if !strings.Contains(res.Diff, `-  "name": "Ada"`) || !strings.Contains(res.Diff, `+  "name": "Grace"`) {
+		t.Fatalf("diff:\n%s", res.Diff)
+	}
+	if res.Message() != "response differs from snapshot (+1 -1 lines)" {
+		t.Fatalf("message = %q", res.Message())
+	}
+
+	opt.Update = true
+	res = Check(path, []byte(`{"id": 1, "name": "Grace", "createdAt": "t3"}`), opt)
+	if res.Status != StatusUpdated || res.Failed() {
+		t.Fatalf("update = %+v", res)
+	}
+	opt.Update = false
+	if res := Check(path, []byte(`{"id": 1, "name": "Grace"}`), opt); res.Status != StatusMismatch {
+		t.Fatalf("missing ignored key should still differ: %+v", res)
+	}
+}
@@ -0,0 +1,100 @@
+package snippet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// renderGo writes a complete program, so the snippet runs with `go run`.
+func renderGo(b *strings.Builder, req Request) {
+	hs := headers(req.Header)
+	b.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"log\"\n\t\"net/http\"\n")
+	if len(req.Body) > 0 {
+		b.WriteString("\t\"strings\"\n")
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+	body := "nil"
+	if len(req.Body) > 0 {
+		fmt.Fprintf(b, "\tbody := strings.NewReader(%s)\n", goString(string(req.Body)))
+		body = "body"
+	}
+	fmt.Fprintf(b, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(req.Method), strconv.Quote(req.URL), body)
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	for _, h := range hs {
+		if strings.EqualFold(h.name, "Host") {
+			// Go ignores a Host entry in the header map.
+			fmt.Fprintf(b, "\treq.Host = %s\n", strconv.Quote(h.vals[0]))
+			continue
+		}
+		for _, v := range h.vals {
+			fmt.Fprintf(b, "\treq.Header.Add(%s, %s)\n", strconv.Quote(h.name), strconv.Quote(v))
+		}
+	}
+	b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tdata, err := io.ReadAll(resp.Body)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.Status)\n\tfmt.Println(string(data))\n}\n")
+}
+
+// goString prefers a raw literal so JSON bodies stay readable.
+func goString(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+func renderPython(b *strings.Builder, req Request) {
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(b, "url = %s\n", jsonQuote(req.URL))
+	args := []string{jsonQuote(req.Method), "url"}
+	if hs := headers(req.Header); len(hs) > 0 {
+		b.WriteString("headers = {\n")
+		for _, h := range hs {
+			fmt.Fprintf(b, "    %s: %s,\n", jsonQuote(h.name), jsonQuote(strings.Join(h.vals, ", ")))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+	if len(req.Body) > 0 {
+		// requests encodes str bodies as Latin-1, so anything wider goes as bytes.
+		lit := jsonQuote(string(req.Body))
+		if !isASCII(req.Body) {
+			lit += ".encode()"
+		}
+		fmt.Fprintf(b, "data = %s\n", lit)
+		args = append(args, "data=data")
+	}
+	fmt.Fprintf(b, "\nresponse = requests.request(%s)\n", strings.Join(args, ", "))
+	b.WriteString("print(response.status_code)\nprint(response.text)\n")
+}
+
+// renderFetch targets runtimes with top-level await, such as Node modules,
+// Deno and browser consoles.
+func renderFetch(b *strings.Builder, req Request) {
+	fmt.Fprintf(b, "const response = await fetch(%s, {\n", jsonQuote(req.URL))
+	fmt.Fprintf(b, "  method: %s,\n", jsonQuote(req.Method))
+	if hs := headers(req.Header); len(hs) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range hs {
+			fmt.Fprintf(b, "    %s: %s,\n", jsonQuote(h.name), jsonQuote(strings.Join(h.vals, ", ")))
+		}
+		b.WriteString("  },\n")
+	}
+	if len(req.Body) > 0 {
+		fmt.Fprintf(b, "  body: %s,\n", jsonQuote(string(req.Body)))
+	}
+	b.WriteString("});\n\nconsole.log(response.status);\nconsole.log(await response.text());\n")
+}
+
+func isASCII(p []byte) bool {
+	for _, c := range p {
+		if c >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
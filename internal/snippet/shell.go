@@ -0,0 +1,58 @@
+package snippet
+
+import (
+	"net/http"
+	"strings"
+)
+
+func renderCurl(b *strings.Builder, req Request) {
+	args := []string{"curl"}
+	switch {
+	case req.Method == http.MethodHead:
+		args = append(args, "--head")
+	case req.Method != http.MethodGet || len(req.Body) > 0:
+		args = append(args, "-X "+req.Method)
+	}
+	args = append(args, shellQuote(req.URL))
+	for _, h := range headers(req.Header) {
+		for _, v := range h.vals {
+			args = append(args, "-H "+shellQuote(h.name+": "+v))
+		}
+	}
+	if len(req.Body) > 0 {
+		args = append(args, "--data-raw "+shellQuote(string(req.Body)))
+	}
+	writeShell(b, args)
+}
+
+// HTTPie sends --raw bodies as given, so it never adds its own JSON headers.
+func renderHTTPie(b *strings.Builder, req Request) {
+	args := []string{"http", req.Method, shellQuote(req.URL)}
+	for _, h := range headers(req.Header) {
+		for _, v := range h.vals {
+			args = append(args, shellQuote(h.name+":"+v))
+		}
+	}
+	if len(req.Body) > 0 {
+		args = append(args, "--raw "+shellQuote(string(req.Body)))
+	}
+	writeShell(b, args)
+}
+
+// writeShell puts the URL on the first line and every further argument on its
+// own continuation line.
+func writeShell(b *strings.Builder, args []string) {
+	head := 0
+	for i, a := range args {
+		if strings.HasPrefix(a, "'") {
+			head = i
+			break
+		}
+	}
+	b.WriteString(strings.Join(args[:head+1], " "))
+	for _, a := range args[head+1:] {
+		b.WriteString(" \\\n  ")
+		b.WriteString(a)
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,113 @@
+// Package snippet renders a prepared HTTP request as code for other tools.
+package snippet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type Format string
+
+const (
+	Curl   Format = "curl"
+	HTTPie Format = "httpie"
+	Go     Format = "go"
+	Python Format = "python"
+	Fetch  Format = "fetch"
+)
+
+// Formats lists every supported format in the order help text shows them.
+var Formats = []Format{Curl, HTTPie, Go, Python, Fetch}
+
+// Request is a fully resolved HTTP request. Header order is not kept, so
+// output lists headers by name.
+type Request struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, known := range Formats {
+		names[i] = string(known)
+	}
+	return "", fmt.Errorf("snippet: unknown format %q (use %s)", s, strings.Join(names, ", "))
+}
+
+// Render returns req as a snippet ending in a newline. Bodies have to be text;
+// binary uploads are better copied as files than inlined.
+func Render(f Format, req Request) (string, error) {
+	if strings.TrimSpace(req.URL) == "" {
+		return "", fmt.Errorf("snippet: request has no URL")
+	}
+	if !utf8.Valid(req.Body) {
+		return "", fmt.Errorf("snippet: request body is binary and cannot be inlined")
+	}
+	req.Method = strings.ToUpper(strings.TrimSpace(req.Method))
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	var b strings.Builder
+	switch f {
+	case Curl:
+		renderCurl(&b, req)
+	case HTTPie:
+		renderHTTPie(&b, req)
+	case Go:
+		renderGo(&b, req)
+	case Python:
+		renderPython(&b, req)
+	case Fetch:
+		renderFetch(&b, req)
+	default:
+		return "", fmt.Errorf("snippet: unknown format %q", f)
+	}
+	return b.String(), nil
+}
+
+type header struct {
+	name string
+	vals []string
+}
+
+// headers drops Content-Length, which every target computes from the body.
+func headers(h http.Header) []header {
+	out := make([]header, 0, len(h))
+	for name, vals := range h {
+		if strings.EqualFold(name, "Content-Length") || len(vals) == 0 {
+			continue
+		}
+		out = append(out, header{name: name, vals: vals})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// shellQuote wraps s in single quotes, which keep everything literal in POSIX
+// shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// jsonQuote produces a double-quoted literal that Python and JavaScript read
+// the same way.
+func jsonQuote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
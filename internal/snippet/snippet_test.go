@@ -0,0 +1,131 @@
+package snippet
+
+import (
+	"go/format"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/resterm/internal/curl"
+)
+
+func sample() Request {
+	return Request{
+		Method: "post",
+		URL:    "https://api.example.com/users?team=a&b=it's",
+		Header: http.Header{
+			"Content-Type":   {"application/json"},
+			"Content-Length": {"27"},
+			"X-Tag":          {"one", "two"},
+		},
+		Body: []byte("{\n  \"name\": \"O'Brien é\"\n}"),
+	}
+}
+
+func render(t *testing.T, f Format, req Request) string {
+	t.Helper()
+	out, err := Render(f, req)
+	if err != nil {
+		t.Fatalf("render %s: %v", f, err)
+	}
+	return out
+}
+
+// The curl importer has to read the snippet back into the same request.
+func TestCurlRoundTrips(t *testing.T) {
+	out := render(t, Curl, sample())
+	if strings.Contains(out, "Content-Length") {
+		t.Fatalf("content length was kept:\n%s", out)
+	}
+	req, err := curl.ParseCommand(out)
+	if err != nil {
+		t.Fatalf("parse: %v\n%s", err, out)
+	}
+	if req.Method != "POST" || req.URL != sample().URL {
+		t.Fatalf("method/url = %s %s", req.Method, req.URL)
+	}
+	if got := req.Headers.Values("X-Tag"); len(got) != 2 || got[1] != "two" {
+		t.Fatalf("headers = %v", req.Headers)
+	}
+	if req.Body.Text != string(sample().Body) {
+		t.Fatalf("body = %q", req.Body.Text)
+	}
+
+	head := render(t, Curl, Request{Method: "HEAD", URL: "https://x"})
+	if head != "curl --head 'https://x'\n" {
+		t.Fatalf("head = %q", head)
+	}
+	get := render(t, Curl, Request{URL: "https://x"})
+	if get != "curl 'https://x'\n" {
+		t.Fatalf("get = %q", get)
+	}
+}
+
+func TestGoIsValidSource(t *testing.T) {
+	req := sample()
+	req.Header.Set("Host", "internal.example")
+	out := render(t, Go, req)
+	if _, err := format.Source([]byte(out)); err != nil {
+		t.Fatalf("go output does not parse: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"http.NewRequest(\"POST\", \"https://api.example.com/users?team=a&b=it's\", body)",
+		"strings.NewReader(`{\n  \"name\": \"O'Brien é\"\n}`)",
+		`req.Header.Add("X-Tag", "two")`,
+		`req.Host = "internal.example"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("go output missing %q:\n%s", want, out)
+		}
+	}
+
+	bare := render(t, Go, Request{URL: "https://x"})
+	if _, err := format.Source([]byte(bare)); err != nil || strings.Contains(bare, `"strings"`) {
+		t.Fatalf("bodyless go output: %v\n%s", err, bare)
+	}
+}
+
+func TestScriptFormats(t *testing.T) {
+	cases := map[Format][]string{
+		HTTPie: {
+			`http POST 'https://api.example.com/users?team=a&b=it'\''s' \`,
+			`  'X-Tag:one' \`,
+			`  --raw '{` + "\n" + `  "name": "O'\''Brien é"` + "\n" + `}'`,
+		},
+		Python: {
+			`url = "https://api.example.com/users?team=a&b=it's"`,
+			`    "X-Tag": "one, two",`,
+			`data = "{\n  \"name\": \"O'Brien é\"\n}".encode()`,
+			`requests.request("POST", url, headers=headers, data=data)`,
+		},
+		Fetch: {
+			`await fetch("https://api.example.com/users?team=a&b=it's", {`,
+			`  method: "POST",`,
+			`    "Content-Type": "application/json",`,
+			`  body: "{\n  \"name\": \"O'Brien é\"\n}",`,
+		},
+	}
+	for f, wants := range cases {
+		out := render(t, f, sample())
+		for _, want := range wants {
+			if !strings.Contains(out, want) {
+				t.Fatalf("%s output missing %q:\n%s", f, want, out)
+			}
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render(Curl, Request{URL: "https://x", Body: []byte{0xff, 0x00}}); err == nil {
+		t.Fatalf("binary body was rendered")
+	}
+	if _, err := Render(Curl, Request{}); err == nil {
+		t.Fatalf("request without URL was rendered")
+	}
+	if _, err := ParseFormat("wget"); err == nil || !strings.Contains(err.Error(), "curl, httpie, go, python, fetch") {
+		t.Fatalf("err = %v", err)
+	}
+	if f, err := ParseFormat(" HTTPie "); err != nil || f != HTTPie {
+		t.Fatalf("ParseFormat = %q, %v", f, err)
+	}
+}
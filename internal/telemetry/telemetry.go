@@ -178,6 +178,9 @@ func (rs *requestSpan) RecordTrace(tl *nettrace.Timeline, report *nettrace.Repor
 		if phase.Meta.Cached {
 			attrs = append(attrs, attribute.Bool("resterm.trace.cached", true))
 		}
+		if phase.Meta.Override {
+			attrs = append(attrs, attribute.Bool("resterm.trace.dns_override", true))
+		}
 		if strings.TrimSpace(phase.Err) != "" {
 			attrs = append(attrs, attribute.String("resterm.trace.phase_error", phase.Err))
 		}
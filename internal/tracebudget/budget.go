@@ -16,6 +16,7 @@ var phaseMap = map[string]string{
 	"dial":            string(nettrace.PhaseConnect),
 	"tls":             string(nettrace.PhaseTLS),
 	"handshake":       string(nettrace.PhaseTLS),
+	"quic":            string(nettrace.PhaseQUIC),
 	"headers":         string(nettrace.PhaseReqHdrs),
 	"request_headers": string(nettrace.PhaseReqHdrs),
 	"req_headers":     string(nettrace.PhaseReqHdrs),
@@ -221,6 +221,10 @@ func (m Model) helpSections() []helpSection {
 					m.helpActionKey(bindings.ActionExplainRequest, "g x"),
 					"Prepare Explain preview (no request sent)",
 				},
+				{
+					m.helpActionKey(bindings.ActionCopyRequestSnippet, "g Shift+Y"),
+					"Copy active request as curl (secrets masked)",
+				},
 				{
 					m.helpActionKey(bindings.ActionCancelRun, "Ctrl+C"),
 					"Cancel in-flight run/request",
@@ -413,6 +417,7 @@ func (m Model) helpSections() []helpSection {
 				{"p", "History: preview entry"},
 				{"d", "History: delete selection"},
 				{"r", "History: replay entry"},
+				{"x", "History: share entry as a bug-report bundle"},
 			}),
 		},
 		{
@@ -450,6 +455,7 @@ func (m Model) helpSections() []helpSection {
 				{":noh", "Clear search highlights"},
 				{":help [topic] / :man [topic]", "Open embedded help or a documentation topic"},
 				{":docs [topic]", "Open version-matched web documentation"},
+				{":jwt [token] [--jwks path|url]", "Decode and check JWTs from the response and auth caches"},
 				{"Up / Down / Tab / Enter", "Select, complete, or run command suggestions"},
 			},
 		},
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/unkn0wn-root/resterm/internal/collection"
+	"github.com/unkn0wn-root/resterm/internal/history"
+)
+
+// shareHistorySelection writes the selected entry as a bug-report bundle
+// next to the workspace, named after the entry like the CLI default.
+func (m *Model) shareHistorySelection() {
+	entry, ok := m.selectedHistoryEntry()
+	if !ok {
+		m.setStatusMessage(statusMsg{text: "No history entry selected", level: statusWarn})
+		return
+	}
+	c, err := history.LoadCapture(m.historyStore(), entry)
+	if err != nil {
+		m.setStatusMessage(
+			statusMsg{text: fmt.Sprintf("history share error: %v", err), level: statusError},
+		)
+		return
+	}
+	out := filepath.Join(m.ws.root, "resterm-share-"+entry.ID+".zip")
+	res, err := collection.ShareEntry(collection.ShareOptions{
+		Entry:   entry,
+		Capture: c,
+		OutFile: out,
+		Force:   true,
+	})
+	if err != nil {
+		m.setStatusMessage(
+			statusMsg{text: fmt.Sprintf("history share error: %v", err), level: statusError},
+		)
+		return
+	}
+	text := "Shared history entry to " + res.OutFile
+	if len(res.Placeholders) > 0 {
+		text += " (placeholders: " + strings.Join(res.Placeholders, ", ") + ")"
+	}
+	m.setStatusMessage(statusMsg{text: text, level: statusSuccess})
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/unkn0wn-root/resterm/internal/history"
+)
+
+func TestHistoryShareKeyWritesBundle(t *testing.T) {
+	dir := t.TempDir()
+	model := New(Config{WorkspaceRoot: dir})
+	model.historyEntries = []history.Entry{{
+		ID:          "7",
+		ExecutedAt:  time.Now(),
+		RequestName: "GetUser",
+		Method:      "GET",
+		Status:      "500 Internal Server Error",
+		StatusCode:  500,
+		RequestText: "GET https://api.example.com/users/1\nAuthorization: •••\n",
+	}}
+	model.historyList.SetItems(makeHistoryItems(model.historyEntries, model.historyScope))
+	model.historyList.Select(0)
+	model.focus = focusResponse
+	model.focusedPane().activeTab = responseTabHistory
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	model = updated.(Model)
+
+	if _, err := os.Stat(filepath.Join(dir, "resterm-share-7.zip")); err != nil {
+		t.Fatalf("expected share bundle: %v", err)
+	}
+	if !strings.Contains(model.statusMessage.text, "placeholders: authorization") {
+		t.Fatalf("status = %q", model.statusMessage.text)
+	}
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/analysis"
+	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/vars"
+)
+
+const (
+	trendMinSamples = 2
+	trendHistBins   = 8
+	trendIndent     = "  "
+	trendLabelWidth = 16
+)
+
+// historyTrendCache holds the trend of one request in one environment. It is
+// keyed rather than tied to a pane because the tab describes the request's
+// recorded runs, not the response on screen; syncHistory drops it whenever
+// history changes.
+type historyTrendCache struct {
+	key   string
+	valid bool
+	trend analysis.Trend
+}
+
+func (m *Model) invalidateHistoryTrend() {
+	m.historyTrend = historyTrendCache{}
+	for _, id := range m.visiblePaneIDs() {
+		if pane := m.pane(id); pane != nil {
+			pane.wrapCache[responseTabTrends] = cachedWrap{}
+		}
+	}
+}
+
+func (m *Model) trendTarget() (string, string) {
+	if m.currentRequest == nil {
+		return "", ""
+	}
+	id := requestIdentifier(m.currentRequest)
+	env, err := m.environment(vars.Selection{})
+	if err != nil {
+		return id, ""
+	}
+	return id, env.Label()
+}
+
+// currentTrend computes the trend for the request under the cursor in the
+// active environment, reusing the cached one while history is unchanged.
+func (m *Model) currentTrend() (analysis.Trend, bool) {
+	hs := m.historyStore()
+	id, env := m.trendTarget()
+	if hs == nil || id == "" {
+		return analysis.Trend{}, false
+	}
+	key := id + "\x00" + env
+	if m.historyTrend.valid && m.historyTrend.key == key {
+		tr := m.historyTrend.trend
+		return tr, tr.Samples >= trendMinSamples
+	}
+	var tr analysis.Trend
+	// A failed read only hides the tab; the History tab reports store errors.
+	if es, err := history.TrendEntries(hs, id, env); err == nil {
+		tr = analysis.ComputeTrend(history.TrendSamples(es), analysis.TrendOptions{})
+	}
+	m.historyTrend = historyTrendCache{key: key, valid: true, trend: tr}
+	return tr, tr.Samples >= trendMinSamples
+}
+
+func (m *Model) trendsContent(w int) string {
+	tr, ok := m.currentTrend()
+	if !ok {
+		return "Not enough recorded runs for a trend.\n"
+	}
+	id, env := m.trendTarget()
+	return renderTrend(tr, id, env, w)
+}
+
+func renderTrend(tr analysis.Trend, request, env string, w int) string {
+	if len(tr.Metrics) == 0 {
+		return ""
+	}
+	label := request
+	if env != "" {
+		label += " (" + env + ")"
+	}
+	total := tr.Metrics[0]
+	var b strings.Builder
+	fmt.Fprintf(&b, "Trends: %s\n", label)
+	fmt.Fprintf(
+		&b,
+		"%d runs, %s to %s\n",
+		tr.Samples,
+		tr.First.Local().Format("2006-01-02 15:04"),
+		tr.Last.Local().Format("2006-01-02 15:04"),
+	)
+	fmt.Fprintf(
+		&b,
+		"Baseline %d runs vs recent %d (alpha %g, min shift %.0f%%)\n",
+		total.Baseline.Count,
+		total.Recent.Count,
+		tr.Options.Alpha,
+		tr.Options.MinShift*100,
+	)
+
+	spark := max(w-len(trendIndent)-trendLabelWidth-1, 8)
+	b.WriteString("\nLatency:\n")
+	for _, mt := range tr.Metrics {
+		writeTrendMetric(&b, mt, spark)
+	}
+
+	if len(tr.Buckets) > 1 {
+		b.WriteString("\nPercentile bands (total):\n")
+		writeTrendBands(&b, tr.Buckets)
+	}
+
+	if recent := trendRecent(total); len(recent) > 0 {
+		stats := analysis.ComputeLatencyStats(recent, nil, trendHistBins)
+		if len(stats.Histogram) > 0 {
+			fmt.Fprintf(&b, "\nRecent distribution (%d runs):\n", len(recent))
+			b.WriteString(renderHistogram(stats.Histogram, ""))
+		}
+	}
+
+	b.WriteString("\n")
+	if regs := tr.Regressions(); len(regs) > 0 {
+		names := make([]string, len(regs))
+		for i, mt := range regs {
+			names[i] = mt.Name
+		}
+		fmt.Fprintf(&b, "Regressed: %s\n", strings.Join(names, ", "))
+	} else if !total.Tested {
+		b.WriteString("Too few runs in a window to test for regressions.\n")
+	} else {
+		b.WriteString("No significant regressions.\n")
+	}
+	return b.String()
+}
+
+func writeTrendMetric(b *strings.Builder, mt analysis.MetricTrend, width int) {
+	vals := mt.Values
+	if len(vals) > width {
+		vals = vals[len(vals)-width:]
+	}
+	sorted := slices.Clone(vals)
+	slices.Sort(sorted)
+	lo, hi := latBounds(sorted)
+	fmt.Fprintf(b, "%s%-*s %s\n", trendIndent, trendLabelWidth, mt.Name, sparkline(vals, lo, hi))
+
+	line := fmt.Sprintf(
+		"p50 %s -> %s  p90 %s -> %s  %+.1f%%",
+		formatLatencyDuration(mt.Baseline.Median),
+		formatLatencyDuration(mt.Recent.Median),
+		formatLatencyDuration(mt.Baseline.Percentiles[90]),
+		formatLatencyDuration(mt.Recent.Percentiles[90]),
+		mt.Shift*100,
+	)
+	if mt.Tested {
+		line += "  " + formatTrendP(mt.P)
+	}
+	if mt.Regressed {
+		line += "  REGRESSED"
+	}
+	fmt.Fprintf(b, "%s%-*s %s\n", trendIndent, trendLabelWidth, "", line)
+}
+
+// writeTrendBands draws p50, p90 and p99 on one shared scale so the gap
+// between the rows reads as the spread of each bucket.
+func writeTrendBands(b *strings.Builder, buckets []analysis.TrendBucket) {
+	rows := []struct {
+		name string
+		get  func(analysis.TrendBucket) time.Duration
+	}{
+		{"p99", func(bk analysis.TrendBucket) time.Duration { return bk.P99 }},
+		{"p90", func(bk analysis.TrendBucket) time.Duration { return bk.P90 }},
+		{"p50", func(bk analysis.TrendBucket) time.Duration { return bk.P50 }},
+	}
+	var lo, hi time.Duration
+	for i, bk := range buckets {
+		if i == 0 || bk.P50 < lo {
+			lo = bk.P50
+		}
+		hi = max(hi, bk.P99)
+	}
+	for _, row := range rows {
+		vals := make([]time.Duration, len(buckets))
+		for i, bk := range buckets {
+			vals[i] = row.get(bk)
+		}
+		fmt.Fprintf(
+			b,
+			"%s%-4s %s  %s -> %s\n",
+			trendIndent,
+			row.name,
+			sparkline(vals, lo, hi),
+			formatLatencyDuration(vals[0]),
+			formatLatencyDuration(vals[len(vals)-1]),
+		)
+	}
+}
+
+// trendRecent returns the recent window of a metric. The window is always the
+// tail of Values because both keep sample order.
+func trendRecent(mt analysis.MetricTrend) []time.Duration {
+	n := mt.Recent.Count
+	if n <= 0 || n > len(mt.Values) {
+		return nil
+	}
+	return mt.Values[len(mt.Values)-n:]
+}
+
+func formatTrendP(p float64) string {
+	if p < 0.0001 {
+		return "p<0.0001"
+	}
+	return fmt.Sprintf("p=%.4f", p)
+}
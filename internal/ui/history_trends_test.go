@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/history"
+	histdb "github.com/unkn0wn-root/resterm/internal/history/sqlite"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func TestTrendsTabShowsRegression(t *testing.T) {
+	store := histdb.New(filepath.Join(t.TempDir(), "history.db"))
+	t.Cleanup(func() { _ = store.Close() })
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 30 {
+		d := time.Duration(100+i%5) * time.Millisecond
+		if i >= 20 {
+			d += 80 * time.Millisecond
+		}
+		if err := store.Append(history.Entry{
+			ID:          strconv.Itoa(i),
+			ExecutedAt:  at.Add(time.Duration(i) * time.Hour),
+			RequestName: "GetUser",
+			Method:      "GET",
+			Duration:    d,
+		}); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+
+	model := New(Config{History: store})
+	if tabs := model.availableResponseTabs(); indexOfResponseTab(tabs, responseTabTrends) != -1 {
+		t.Fatalf("trends tab without a request: %v", tabs)
+	}
+	model.currentRequest = &restfile.Request{Metadata: restfile.RequestMetadata{Name: "GetUser"}}
+	if tabs := model.availableResponseTabs(); indexOfResponseTab(tabs, responseTabTrends) == -1 {
+		t.Fatalf("expected trends tab, got %v", tabs)
+	}
+
+	content, _ := model.paneContentBase(responsePanePrimary, responseTabTrends, 100)
+	for _, want := range []string{"Trends: GetUser", "30 runs", "Percentile bands", "REGRESSED", "Regressed: total"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("trends content missing %q:\n%s", want, content)
+		}
+	}
+
+	if err := store.Append(history.Entry{
+		ID:          "new",
+		ExecutedAt:  at.Add(31 * time.Hour),
+		RequestName: "GetUser",
+		Method:      "GET",
+		Duration:    180 * time.Millisecond,
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	model.syncHistory()
+	if tr, _ := model.currentTrend(); tr.Samples != 31 {
+		t.Fatalf("expected refreshed trend, got %d samples", tr.Samples)
+	}
+}
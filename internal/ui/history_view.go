@@ -140,7 +140,7 @@ func (h historyItem) FilterValue() string {
 	}
 	if h.entry.Compare != nil {
 		for _, res := range h.entry.Compare.Results {
-			parts = append(parts, res.Environment, res.Status)
+			parts = append(parts, res.Label(), res.Status)
 		}
 	}
 	return strings.Join(parts, " ")
@@ -158,10 +158,10 @@ func compareSummary(entry history.Entry) string {
 	if entry.Compare == nil || len(entry.Compare.Results) == 0 {
 		return "compare: none"
 	}
-	baseline := entry.Compare.BaselineResult().Environment
+	baseline := entry.Compare.BaselineResult().Label()
 	segments := make([]string, 0, len(entry.Compare.Results))
 	for _, res := range entry.Compare.Results {
-		label := res.Environment
+		label := res.Label()
 		if label == baseline {
 			label += "*"
 		}
@@ -52,6 +52,7 @@ type responseMsg struct {
 	skipReason     string
 	preview        bool
 	explain        *xplain.Report
+	snapshotDiff   string
 	historyDone    bool
 	latGen         int
 	target         runTarget
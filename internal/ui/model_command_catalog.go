@@ -74,6 +74,11 @@ var exCommands = exCatalog{
 			kind: exCommandDocs, name: "docs",
 			usage: "docs [topic]", summary: "Open version-matched web documentation", hasArgs: true, noBang: true,
 		},
+		{
+			kind: exCommandJWT, name: "jwt",
+			usage: "jwt [token] [--jwks path|url]", summary: "Decode and check JWTs in the response and auth caches",
+			hasArgs: true, noBang: true,
+		},
 	},
 	mock: []mockCommandDef{
 		{name: "status", summary: "Show server address and counters"},
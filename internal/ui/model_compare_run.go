@@ -24,6 +24,7 @@ type compareState struct {
 	base         *restfile.Request
 	options      httpx.Options
 	targets      []vars.Target
+	identities   []string
 	group        string
 	baseline     string
 	index        int
@@ -47,25 +48,64 @@ func compareStateFromPlan(
 	if pl == nil {
 		return nil
 	}
+	ids := make([]string, len(pl.Identities))
+	for i, id := range pl.Identities {
+		ids[i] = id.Name
+	}
 	return &compareState{
 		id:          strings.TrimSpace(pl.Run.ID),
 		base:        pl.Request.Clone(),
 		options:     opts,
 		targets:     slices.Clone(pl.Targets),
+		identities:  ids,
 		group:       pl.Group,
 		baseline:    pl.Baseline,
-		results:     make([]compareResult, 0, len(pl.Targets)),
+		results:     make([]compareResult, 0, pl.Rows()),
 		label:       label,
 		statusLabel: statusLabel,
 	}
 }
 
-// envAt is the environment label of target i, empty when i is out of range.
+// rows counts every target and identity pair; core runs all identities of a
+// target before moving on.
+func (s *compareState) rows() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.targets) * max(len(s.identities), 1)
+}
+
+// envAt is the environment label of row i, empty when i is out of range.
 func (s *compareState) envAt(i int) string {
-	if s == nil || i < 0 || i >= len(s.targets) {
+	if s == nil || i < 0 || i >= s.rows() {
+		return ""
+	}
+	return s.targets[i/max(len(s.identities), 1)].Env.Label()
+}
+
+func (s *compareState) identityAt(i int) string {
+	if s == nil || len(s.identities) == 0 || i < 0 || i >= s.rows() {
+		return ""
+	}
+	return s.identities[i%len(s.identities)]
+}
+
+// nameAt is what the baseline of row i is matched against.
+func (s *compareState) nameAt(i int) string {
+	if id := s.identityAt(i); id != "" {
+		return id
+	}
+	if i < 0 || i >= s.rows() {
 		return ""
 	}
-	return s.targets[i].Env.Label()
+	return s.targets[i].Name()
+}
+
+func (s *compareState) labelAt(i int) string {
+	if id := s.identityAt(i); id != "" {
+		return id
+	}
+	return s.envAt(i)
 }
 
 func (m *Model) startCompareRun(
@@ -126,6 +166,64 @@ func (m *Model) startCompareRun(
 	return m.startCompareCoreRun(pl, state)
 }
 
+// startIdentityRun repeats an @as request under each identity in the active
+// environment. It reuses the compare panes, with one row per identity.
+func (m *Model) startIdentityRun(
+	doc *restfile.Document,
+	req *restfile.Request,
+	options httpx.Options,
+) tea.Cmd {
+	if cmd := m.runBlocked(); cmd != nil {
+		return cmd
+	}
+	if err := docErr(doc); err != nil {
+		return batchCommands(m.restorePane(paneRegionResponse), m.failErr(err))
+	}
+	if m.compareRun != nil {
+		m.setStatusMessage(
+			statusMsg{level: statusWarn, text: "Another compare run is already active"},
+		)
+		return nil
+	}
+
+	as := req.Metadata.Identities
+	ids, err := core.ResolveIdentities(as, func(name string) (*restfile.AuthSpec, bool) {
+		pf, ok := m.registryIndex().NamedAuth(doc, name)
+		if !ok {
+			return nil, false
+		}
+		return pf.Spec.Clone(), true
+	})
+	if err != nil {
+		m.setStatusMessage(statusMsg{text: err.Error(), level: statusError})
+		return nil
+	}
+	env := m.ws.active
+	pl, err := core.PrepareCompare(core.CompareInput{
+		Doc:        doc,
+		Request:    req,
+		Targets:    []vars.Target{{Env: env}},
+		Identities: ids,
+		Baseline:   as.Baseline,
+		Run: core.RunMeta{
+			ID:  fmt.Sprintf("%d", time.Now().UnixNano()),
+			Env: env,
+		},
+	})
+	if err != nil {
+		m.setStatusMessage(statusMsg{text: err.Error(), level: statusError})
+		return nil
+	}
+	title, short := m.statusRunTitles(doc, req)
+	state := compareStateFromPlan(
+		pl,
+		options,
+		fmt.Sprintf("Identities %s", title),
+		fmt.Sprintf("Identities %s", short),
+	)
+	return m.startCompareCoreRun(pl, state)
+}
+
 func (m *Model) beginCompareRun(state *compareState) []tea.Cmd {
 	if state == nil {
 		return nil
@@ -159,7 +257,7 @@ func (m *Model) startCompareCoreRun(pl *core.ComparePlan, state *compareState) t
 		return nil
 	}
 	cmds := m.beginCompareRun(state)
-	if len(state.targets) > 0 {
+	if state.rows() > 0 {
 		state.currentEnv = state.envAt(0)
 		state.current = state.base.Clone()
 		state.requestText = rqeng.RenderRequestText(state.current)
@@ -226,7 +324,7 @@ func (m *Model) handleCompareRowDone(st *compareState, evt core.CmpRowDone) tea.
 		env = compareEnvAt(st, evt.Row.Index, evt.Row.Env)
 	}
 	canceled, cmd := m.consumeCompareRow(st, st.current, env, msg)
-	if canceled || st.index >= len(st.targets) {
+	if canceled || st.index >= st.rows() {
 		return batchCmds([]tea.Cmd{cmd, m.finalizeCompareRun(st)})
 	}
 	return cmd
@@ -281,6 +379,7 @@ func (m *Model) consumeCompareRow(
 	}
 	result := compareResult{
 		Environment:    currentEnv,
+		Identity:       state.identityAt(state.index),
 		Selection:      msg.selection,
 		Stream:         cloneStreamInfo(msg.stream),
 		Transcript:     append([]byte(nil), msg.transcript...),
@@ -339,8 +438,8 @@ func (m *Model) consumeCompareRow(
 	}
 
 	state.results = append(state.results, result)
-	m.storeCompareSnapshot(result.Environment)
-	m.compareFocusedEnv = strings.TrimSpace(result.Environment)
+	m.storeCompareSnapshot(result.label())
+	m.compareFocusedEnv = strings.TrimSpace(result.label())
 	m.pinCompareReferencePane(state)
 	state.index++
 
@@ -367,7 +466,11 @@ func (m *Model) finalizeCompareRun(state *compareState) tea.Cmd {
 		m.setPaneSnapshot(responsePaneSecondary, m.responseLatest)
 	}
 
-	if bundle := buildCompareBundle(state.results, state.baseline); bundle != nil {
+	if bundle := buildCompareBundle(
+		state.results,
+		state.baseline,
+		core.CompareRules(state.base),
+	); bundle != nil {
 		m.compareBundle = bundle
 		if m.responseLatest != nil {
 			m.responseLatest.compareBundle = bundle
@@ -388,7 +491,7 @@ func (m *Model) finalizeCompareRun(state *compareState) tea.Cmd {
 			snap.compareBundle = bundle
 		}
 		if len(bundle.Rows) > 0 {
-			m.compareSelectedEnv = strings.TrimSpace(bundle.Rows[0].Result.Environment)
+			m.compareSelectedEnv = strings.TrimSpace(bundle.Rows[0].Result.label())
 			m.compareFocusedEnv = m.compareSelectedEnv
 			m.compareRowIndex = compareRowIndexForEnv(bundle, m.compareSelectedEnv)
 		} else {
@@ -485,7 +588,7 @@ func (m *Model) recordCompareHistory(state *compareState) {
 	entry.Environment = m.ws.active.Label()
 	entry.EnvironmentSelection = history.EnvironmentSelection(m.ws.active.Selection().Groups())
 	if state.canceled {
-		status := fmt.Sprintf("canceled after %d/%d", len(state.results), len(state.targets))
+		status := fmt.Sprintf("canceled after %d/%d", len(state.results), state.rows())
 		if strings.TrimSpace(state.label) != "" {
 			status = fmt.Sprintf("%s | %s", strings.TrimSpace(state.label), status)
 		}
@@ -526,6 +629,7 @@ func (m *Model) buildCompareHistoryResult(result compareResult) history.CompareR
 	entry := history.CompareResult{
 		Environment:          env,
 		Profile:              result.Profile,
+		Identity:             result.Identity,
 		EnvironmentSelection: history.EnvironmentSelection(result.Selection.Groups()),
 		Status:               status,
 		Duration:             compareRowDuration(&result),
@@ -608,14 +712,14 @@ func (m *Model) compareRowSecrets(row compareResult, req *restfile.Request) []st
 }
 
 func (s *compareState) progressSummary() string {
-	if s == nil || len(s.targets) == 0 {
+	if s == nil || s.rows() == 0 {
 		return ""
 	}
 
-	parts := make([]string, len(s.targets))
-	for idx, target := range s.targets {
-		label := target.Env.Label()
-		if s.baseline != "" && strings.EqualFold(target.Name(), s.baseline) {
+	parts := make([]string, s.rows())
+	for idx := range parts {
+		label := s.labelAt(idx)
+		if s.baseline != "" && strings.EqualFold(s.nameAt(idx), s.baseline) {
 			label += "*"
 		}
 		switch {
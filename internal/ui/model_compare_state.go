@@ -1,23 +1,25 @@
 package ui
 
 import (
-	"bytes"
 	"fmt"
 	"net/http"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/unkn0wn-root/resterm/internal/engine/core"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
 	"github.com/unkn0wn-root/resterm/internal/scripts"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
 	"github.com/unkn0wn-root/resterm/internal/vars"
 )
 
 type compareResult struct {
 	Environment string
 	Profile     string
+	Identity    string
 	Selection   vars.Selection
 	Response    *httpx.Response
 	GRPC        *grpcx.Response
@@ -35,6 +37,18 @@ type compareResult struct {
 	SkipReason     string
 }
 
+// label keys the row in the compare panes and snapshots. Identity rows share
+// one environment, so they are keyed by identity instead.
+func (r *compareResult) label() string {
+	if r == nil {
+		return ""
+	}
+	if r.Identity != "" {
+		return r.Identity
+	}
+	return r.Environment
+}
+
 func (m *Model) resetCompareState() {
 	if m.compareSnapshots != nil {
 		for k := range m.compareSnapshots {
@@ -75,6 +89,8 @@ func (m *Model) compareSnapshot(env string) *responseSnapshot {
 type compareBundle struct {
 	Baseline string
 	Rows     []compareRow
+	// Rules are the @compare ignore and match options the diff view applies.
+	Rules semdiff.Options
 }
 
 type compareRow struct {
@@ -85,7 +101,11 @@ type compareRow struct {
 	Summary  string
 }
 
-func buildCompareBundle(results []compareResult, baseline string) *compareBundle {
+func buildCompareBundle(
+	results []compareResult,
+	baseline string,
+	rules semdiff.Options,
+) *compareBundle {
 	if len(results) == 0 {
 		return nil
 	}
@@ -94,8 +114,9 @@ func buildCompareBundle(results []compareResult, baseline string) *compareBundle
 
 	base := &results[baseIdx]
 	out := &compareBundle{
-		Baseline: base.Environment,
+		Baseline: base.label(),
 		Rows:     make([]compareRow, 0, len(results)),
+		Rules:    rules,
 	}
 	for i := range results {
 		res := &results[i]
@@ -105,7 +126,7 @@ func buildCompareBundle(results []compareResult, baseline string) *compareBundle
 			Status:   status,
 			Code:     code,
 			Duration: compareRowDuration(res),
-			Summary:  summarizeCompareDelta(base, res),
+			Summary:  summarizeCompareDelta(base, res, rules),
 		})
 	}
 	return out
@@ -117,8 +138,8 @@ func findBaselineIndex(results []compareResult, baseline string) int {
 	}
 	for i := range results {
 		name := results[i].Profile
-		if name == "" {
-			name = results[i].Environment
+		if name == "" || results[i].Identity != "" {
+			name = results[i].label()
 		}
 		if strings.EqualFold(name, baseline) {
 			return i
@@ -159,14 +180,14 @@ func compareRowDuration(result *compareResult) time.Duration {
 	}
 }
 
-func summarizeCompareDelta(base, target *compareResult) string {
+func summarizeCompareDelta(base, target *compareResult, rules semdiff.Options) string {
 	if target == nil {
 		return "unavailable"
 	}
 	if target.Canceled {
 		return "canceled"
 	}
-	if base != nil && strings.EqualFold(base.Environment, target.Environment) {
+	if base != nil && strings.EqualFold(base.label(), target.label()) {
 		return "baseline"
 	}
 	if target.Skipped {
@@ -191,7 +212,7 @@ func summarizeCompareDelta(base, target *compareResult) string {
 
 	switch {
 	case target.Response != nil && base != nil && base.Response != nil:
-		return summarizeHTTPDelta(base.Response, target.Response)
+		return summarizeHTTPDelta(base.Response, target.Response, rules)
 	case target.GRPC != nil && base != nil && base.GRPC != nil:
 		return base.GRPC.DiffSummary(target.GRPC)
 	default:
@@ -209,7 +230,7 @@ func countTestFailures(tests []scripts.TestResult) int {
 	return n
 }
 
-func summarizeHTTPDelta(base, target *httpx.Response) string {
+func summarizeHTTPDelta(base, target *httpx.Response, rules semdiff.Options) string {
 	if base == nil || target == nil {
 		return "unavailable"
 	}
@@ -217,7 +238,14 @@ func summarizeHTTPDelta(base, target *httpx.Response) string {
 	if target.StatusCode != base.StatusCode {
 		ds = append(ds, "status")
 	}
-	if !bytes.Equal(target.Body, base.Body) {
+	body, at := core.BodyDelta(
+		base.Body,
+		target.Body,
+		base.Headers.Get("Content-Type"),
+		target.Headers.Get("Content-Type"),
+		rules,
+	)
+	if body {
 		ds = append(ds, "body")
 	}
 	if !headersEqual(target.Headers, base.Headers) {
@@ -226,7 +254,11 @@ func summarizeHTTPDelta(base, target *httpx.Response) string {
 	if len(ds) == 0 {
 		return "match"
 	}
-	return strings.Join(ds, ", ") + " differ"
+	out := strings.Join(ds, ", ") + " differ"
+	if at != "" {
+		out += " at " + at
+	}
+	return out
 }
 
 func headersEqual(a, b http.Header) bool {
@@ -58,7 +58,7 @@ func (m *Model) setCompareFocusAt(pane *responsePaneState, index int) tea.Cmd {
 		return nil
 	}
 	m.compareRowIndex = index
-	m.compareFocusedEnv = strings.TrimSpace(bundle.Rows[index].Result.Environment)
+	m.compareFocusedEnv = strings.TrimSpace(bundle.Rows[index].Result.label())
 	m.invalidateCompareTabCaches()
 	m.ensureCompareRowVisible(pane, bundle)
 	return m.syncResponsePane(m.responsePaneFocus)
@@ -73,14 +73,14 @@ func (m *Model) selectCompareFocus() tea.Cmd {
 	}
 	targetEnv := strings.TrimSpace(m.compareFocusedEnv)
 	if targetEnv == "" && m.compareRowIndex >= 0 && m.compareRowIndex < len(bundle.Rows) {
-		targetEnv = strings.TrimSpace(bundle.Rows[m.compareRowIndex].Result.Environment)
+		targetEnv = strings.TrimSpace(bundle.Rows[m.compareRowIndex].Result.label())
 	}
 	if targetEnv == "" {
 		return nil
 	}
 	baselineEnv := strings.TrimSpace(bundle.Baseline)
 	if baselineEnv == "" && len(bundle.Rows) > 0 {
-		baselineEnv = strings.TrimSpace(bundle.Rows[0].Result.Environment)
+		baselineEnv = strings.TrimSpace(bundle.Rows[0].Result.label())
 	}
 	targetSnap := m.compareSnapshot(targetEnv)
 	if targetSnap == nil {
@@ -177,10 +177,7 @@ func compareRowIndexForEnv(bundle *compareBundle, env string) int {
 		return 0
 	}
 	for idx := range bundle.Rows {
-		rowEnv := ""
-		if bundle.Rows[idx].Result != nil {
-			rowEnv = strings.ToLower(strings.TrimSpace(bundle.Rows[idx].Result.Environment))
-		}
+		rowEnv := strings.ToLower(strings.TrimSpace(bundle.Rows[idx].Result.label()))
 		if rowEnv != "" && rowEnv == trimmed {
 			return idx
 		}
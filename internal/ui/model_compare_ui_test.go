@@ -6,6 +6,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 
 	"github.com/unkn0wn-root/resterm/internal/history"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
 )
 
 func TestSelectCompareFocusPinsSnapshots(t *testing.T) {
@@ -161,7 +162,7 @@ func TestBuildCompareBundleMatchesGroupedBaselineProfile(t *testing.T) {
 		{Environment: "api=dev, auth=ci", Profile: "dev"},
 		{Environment: "api=prod, auth=ci", Profile: "prod"},
 	}
-	bundle := buildCompareBundle(results, "prod")
+	bundle := buildCompareBundle(results, "prod", semdiff.Options{})
 	if bundle == nil {
 		t.Fatal("expected compare bundle")
 	}
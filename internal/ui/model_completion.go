@@ -55,6 +55,11 @@ func buildCompletionScope(
 			SSH:   profileNames(doc.SSH, func(p restfile.SSHProfile) string { return p.Name }),
 			K8s:   profileNames(doc.K8s, func(p restfile.K8sProfile) string { return p.Name }),
 		}
+		for _, p := range doc.Auth {
+			if p.Name != "" {
+				scope.Profiles.Auth = append(scope.Profiles.Auth, p.Name)
+			}
+		}
 	}
 
 	// Environment keys fill in only where a declared variable did not.
@@ -61,6 +61,7 @@ const (
 	responseTabStream
 	responseTabStats
 	responseTabTimeline
+	responseTabTrends
 	responseTabCompare
 	responseTabDiff
 	responseTabHistory
@@ -274,6 +275,9 @@ type Model struct {
 	showMockVerification     bool
 	mockVerificationText     string
 	mockVerificationViewport *viewport.Model
+	showJWTInspector         bool
+	jwtInspectorText         string
+	jwtInspectorViewport     *viewport.Model
 
 	showSearchPrompt      bool
 	searchInput           textinput.Model
@@ -326,6 +330,7 @@ type Model struct {
 	settingsHandle      config.SettingsHandle
 	historyEntries      []history.Entry
 	historyScopeCount   int
+	historyTrend        historyTrendCache
 	historySelectedID   string
 	historySelected     map[string]struct{}
 	historyJumpToLatest bool
@@ -508,7 +513,7 @@ func New(cfg Config) Model {
 	navFilter := newNavigatorFilterInput()
 
 	helpFilter := newPromptInput("Search...", "")
-	historyFilter := newPromptInput("method:GET date:05-Jun-2024 users", "Filter: ")
+	historyFilter := newPromptInput(`status:5xx env:prod body:"timeout"`, "Filter: ")
 
 	primaryViewport := viewport.New(0, 0)
 	primaryViewport.SetContent(logoPlaceholder(0, 0))
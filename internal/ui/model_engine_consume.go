@@ -8,6 +8,7 @@ import (
 
 	"github.com/unkn0wn-root/resterm/internal/diag"
 	"github.com/unkn0wn-root/resterm/internal/engine"
+	"github.com/unkn0wn-root/resterm/internal/openapi/contract"
 	"github.com/unkn0wn-root/resterm/internal/protocol/grpcx"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/scripts"
@@ -47,7 +48,7 @@ func (m *Model) responseMsgFromRunState(res engine.RequestResult, done bool) res
 		streamID:       res.StreamID,
 		transcript:     append([]byte(nil), res.Transcript...),
 		err:            res.Err,
-		tests:          append([]scripts.TestResult(nil), res.Tests...),
+		tests:          withContractTests(res.Tests, res.Contract),
 		scriptErr:      res.ScriptErr,
 		executed:       res.Executed,
 		requestText:    res.RequestText,
@@ -58,10 +59,38 @@ func (m *Model) responseMsgFromRunState(res engine.RequestResult, done bool) res
 		skipReason:     res.SkipReason,
 		preview:        res.Preview,
 		explain:        res.Explain,
+		snapshotDiff:   snapshotDiff(res),
 		historyDone:    done,
 	}
 }
 
+func snapshotDiff(res engine.RequestResult) string {
+	if res.Snapshot == nil {
+		return ""
+	}
+	return res.Snapshot.Diff
+}
+
+// withContractTests shows a contract check beside the script tests: one passing
+// row when the response conforms, one failing row per violation otherwise.
+func withContractTests(tests []scripts.TestResult, ct *contract.Result) []scripts.TestResult {
+	out := append([]scripts.TestResult(nil), tests...)
+	if ct == nil {
+		return out
+	}
+	name := "contract"
+	if ct.Operation != "" {
+		name += " " + ct.Operation
+	}
+	if !ct.Failed() {
+		return append(out, scripts.TestResult{Name: name, Passed: true})
+	}
+	for _, v := range ct.Violations {
+		out = append(out, scripts.TestResult{Name: name, Message: v.String()})
+	}
+	return out
+}
+
 func (m *Model) applyRunSnapshot(
 	sn *responseSnapshot,
 	hr *httpx.Response,
@@ -7,8 +7,11 @@ import (
 
 	"github.com/unkn0wn-root/resterm/internal/engine"
 	xplain "github.com/unkn0wn-root/resterm/internal/explain"
+	"github.com/unkn0wn-root/resterm/internal/openapi/contract"
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/scripts"
+	"github.com/unkn0wn-root/resterm/internal/snapshot"
 )
 
 func TestResponseMsgFromRunStateUsesEngineExplainAndCarriesRuntimeSecrets(t *testing.T) {
@@ -42,6 +45,35 @@ func TestResponseMsgFromRunStateUsesEngineExplainAndCarriesRuntimeSecrets(t *tes
 	}
 }
 
+func TestResponseMsgFromRunStateAddsContractTests(t *testing.T) {
+	model := New(Config{})
+	res := engine.RequestResult{
+		Tests: []scripts.TestResult{{Name: "status", Passed: true}},
+		Contract: &contract.Result{Operation: "getItem", Violations: []contract.Violation{
+			{Kind: contract.KindStatus, Message: "status 500 is not documented (expected 200)"},
+			{Kind: contract.KindBody, Path: "/id", Message: "expected integer"},
+		}},
+	}
+
+	msg := model.responseMsgFromRunState(res, true)
+	if len(msg.tests) != 3 {
+		t.Fatalf("expected script test plus two contract rows, got %+v", msg.tests)
+	}
+	if got := msg.tests[2]; got.Passed || got.Name != "contract getItem" ||
+		got.Message != "body /id: expected integer" {
+		t.Fatalf("unexpected contract row: %+v", got)
+	}
+	if len(res.Tests) != 1 {
+		t.Fatalf("expected engine tests to stay untouched, got %d", len(res.Tests))
+	}
+
+	res.Contract = &contract.Result{Operation: "getItem"}
+	msg = model.responseMsgFromRunState(res, true)
+	if len(msg.tests) != 2 || !msg.tests[1].Passed {
+		t.Fatalf("expected a passing contract row, got %+v", msg.tests)
+	}
+}
+
 func TestHandleResponseMessageRunHTTPKeepsCurrentTabAndExposesExplain(t *testing.T) {
 	model := New(Config{})
 	req := &restfile.Request{
@@ -75,6 +107,36 @@ func TestHandleResponseMessageRunHTTPKeepsCurrentTabAndExposesExplain(t *testing
 	}
 }
 
+func TestHandleResponseMessageSnapshotMismatchAddsDiffTab(t *testing.T) {
+	model := New(Config{})
+	diff := "--- snapshot user.json\n+++ response\n@@ -1,3 +1,3 @@\n {\n-  \"name\": \"Ada\"\n+  \"name\": \"Grace\"\n }\n"
+	res := engine.RequestResult{
+		Response: compareHTTPResponse("https://example.com/users/1", []byte(`{"name":"Grace"}`)),
+		Executed: &restfile.Request{Method: "GET", URL: "https://example.com/users/1"},
+		Snapshot: &snapshot.Result{Name: "user.json", Status: snapshot.StatusMismatch, Diff: diff},
+	}
+
+	model.handleResponseMessage(model.responseMsgFromRunState(res, false))
+	pane := model.pane(responsePanePrimary)
+	if pane.activeTab != responseTabPretty {
+		t.Fatalf("expected active tab to remain Pretty, got %v", pane.activeTab)
+	}
+	if !containsResponseTab(model.availableResponseTabs(), responseTabDiff) {
+		t.Fatalf("expected diff tab for a snapshot mismatch")
+	}
+	pane.snapshot.ready = true
+	content, _ := model.paneContentBase(responsePanePrimary, responseTabDiff, 80)
+	if !strings.Contains(stripANSIEscape(content), `+  "name": "Grace"`) {
+		t.Fatalf("expected snapshot diff in Diff tab, got %q", content)
+	}
+
+	res.Snapshot = &snapshot.Result{Name: "user.json", Status: snapshot.StatusMatched}
+	model.handleResponseMessage(model.responseMsgFromRunState(res, false))
+	if containsResponseTab(model.availableResponseTabs(), responseTabDiff) {
+		t.Fatalf("expected no diff tab when the snapshot matches")
+	}
+}
+
 func TestHandleResponseMessageSkippedRunKeepsCurrentTabAndExposesExplain(t *testing.T) {
 	model := New(Config{})
 	req := &restfile.Request{
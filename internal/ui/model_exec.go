@@ -271,6 +271,12 @@ func (m *Model) sendActiveRequest() tea.Cmd {
 			)
 			return st.wrap(nil)
 		}
+		if st.req.Metadata.Identities != nil {
+			m.setStatusMessage(
+				statusMsg{level: statusWarn, text: "@as cannot run alongside @for-each"},
+			)
+			return st.wrap(nil)
+		}
 		if st.req.Metadata.Trace != nil && st.req.Metadata.Trace.Enabled {
 			st.opts.Trace = true
 			if budget, ok := tracebudget.FromSpec(st.req.Metadata.Trace); ok {
@@ -280,6 +286,21 @@ func (m *Model) sendActiveRequest() tea.Cmd {
 		return st.wrap(m.startForEachRun(st.doc, st.req, st.opts))
 	}
 
+	if st.req.Metadata.Identities != nil {
+		var msg string
+		switch {
+		case st.req.Metadata.Compare != nil:
+			msg = "@as cannot run alongside @compare"
+		case st.req.Metadata.Profile != nil:
+			msg = "@as cannot run alongside @profile"
+		}
+		if msg != "" {
+			m.setStatusMessage(statusMsg{level: statusWarn, text: msg})
+			return st.wrap(nil)
+		}
+		return st.wrap(m.startIdentityRun(st.doc, st.req, st.opts))
+	}
+
 	if spec := m.compareSpecForRequest(st.req); spec != nil {
 		if st.req.Metadata.Profile != nil {
 			m.setStatusMessage(
@@ -387,6 +408,10 @@ func (m *Model) startConfigCompareFromEditor() tea.Cmd {
 		m.setStatusMessage(statusMsg{level: statusWarn, text: "@profile cannot run during compare"})
 		return nil
 	}
+	if req.Metadata.Identities != nil {
+		m.setStatusMessage(statusMsg{level: statusWarn, text: "@as cannot run alongside @compare"})
+		return nil
+	}
 
 	spec := core.BuildCompareSpec(m.cfg.Compare)
 	if spec == nil && req.Metadata.Compare != nil {
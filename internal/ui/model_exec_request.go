@@ -123,5 +123,6 @@ func looksLikeHTTPRequestURL(url string) bool {
 	return strings.HasPrefix(lower, "http://") ||
 		strings.HasPrefix(lower, "https://") ||
 		strings.HasPrefix(lower, "ws://") ||
-		strings.HasPrefix(lower, "wss://")
+		strings.HasPrefix(lower, "wss://") ||
+		strings.HasPrefix(lower, "unix://")
 }
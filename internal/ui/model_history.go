@@ -10,8 +10,10 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/grpc/codes"
 
 	"github.com/unkn0wn-root/resterm/internal/binaryview"
+	"github.com/unkn0wn-root/resterm/internal/bodyfmt"
 	"github.com/unkn0wn-root/resterm/internal/diag"
 	rqeng "github.com/unkn0wn-root/resterm/internal/engine/request"
 	xplain "github.com/unkn0wn-root/resterm/internal/explain"
@@ -77,6 +79,7 @@ func (m *Model) handleResponseMessage(msg responseMsg) tea.Cmd {
 			m.recordGRPCHistory(
 				msg.grpc,
 				msg.executed,
+				msg.transcript,
 				msg.requestText,
 				msg.environment,
 				msg.selection,
@@ -412,7 +415,8 @@ func (m *Model) consumeHTTPResponse(msg responseMsg) tea.Cmd {
 		explain: explainState{
 			report: msg.explain,
 		},
-		source: newHTTPResponseRenderSource(resp, tests, scriptErr),
+		source:       newHTTPResponseRenderSource(resp, tests, scriptErr),
+		snapshotDiff: msg.snapshotDiff,
 	}
 	m.responseLatest = snapshot
 	m.bindSnapshotStream(snapshot, msg.streamID)
@@ -781,6 +785,9 @@ func (m *Model) recordHTTPHistory(
 		Tags:        tags,
 	}
 	entry.Trace = history.NewTraceSummary(resp.Timeline, resp.TraceReport)
+	if history.Capturing(hs) {
+		entry.Capture = rqeng.CaptureHTTP(resp, req, secrets)
+	}
 	if err := hs.Append(entry); err != nil {
 		m.setStatusMessage(
 			statusMsg{text: fmt.Sprintf("history error: %v", err), level: statusWarn},
@@ -860,6 +867,7 @@ func formatBinaryHistorySnippet(meta binaryview.Meta, size int) string {
 func (m *Model) recordGRPCHistory(
 	resp *grpcx.Response,
 	req *restfile.Request,
+	transcript []byte,
 	requestText string,
 	environment string,
 	sel vars.Selection,
@@ -906,6 +914,9 @@ func (m *Model) recordGRPCHistory(
 		Description: desc,
 		Tags:        tags,
 	}
+	if history.Capturing(hs) {
+		entry.Capture = rqeng.CaptureGRPC(resp, req, transcript, secrets)
+	}
 
 	if err := hs.Append(entry); err != nil {
 		m.setStatusMessage(
@@ -928,7 +939,7 @@ func (m *Model) secretValuesForEnv(
 	return rqeng.SecretSources{
 		Doc:      m.doc,
 		Req:      req,
-		Env:      rqeng.ResolveEnvironment(env, m.doc, req),
+		Env:      rqeng.CachedEnvironment(env, m.doc, req),
 		FilePath: m.documentRuntimePath(m.doc),
 		Files:    m.fileStore(),
 		Globals:  m.globalsStore(),
@@ -1021,7 +1032,7 @@ func bundleFromHistory(entry history.Entry) *compareBundle {
 		return nil
 	}
 
-	bundle := &compareBundle{Baseline: entry.Compare.BaselineResult().Environment}
+	bundle := &compareBundle{Baseline: entry.Compare.BaselineResult().Label()}
 	rows := make([]compareRow, 0, len(entry.Compare.Results))
 	for idx := range entry.Compare.Results {
 		res := entry.Compare.Results[idx]
@@ -1043,6 +1054,7 @@ func bundleFromHistory(entry history.Entry) *compareBundle {
 			Result: &compareResult{
 				Environment: res.Environment,
 				Profile:     res.Profile,
+				Identity:    res.Identity,
 				Selection:   historySelection(res.EnvironmentSelection),
 			},
 			Status:   res.Status,
@@ -1081,7 +1093,7 @@ func (m *Model) populateCompareSnapshotsFromHistory(
 		if snap == nil {
 			continue
 		}
-		env := strings.TrimSpace(res.Environment)
+		env := strings.TrimSpace(res.Label())
 		m.setCompareSnapshot(env, snap)
 		if selected == "" {
 			selected = env
@@ -1094,7 +1106,7 @@ func buildHistoryCompareSnapshot(
 	res history.CompareResult,
 	bundle *compareBundle,
 ) *responseSnapshot {
-	env := strings.TrimSpace(res.Environment)
+	env := strings.TrimSpace(res.Label())
 	if env == "" {
 		return nil
 	}
@@ -1166,6 +1178,7 @@ func historyResultStatus(res history.CompareResult) string {
 }
 
 func (m *Model) syncHistory() {
+	m.invalidateHistoryTrend()
 	hs := m.historyStore()
 	if hs == nil {
 		m.historyEntries = nil
@@ -1194,7 +1207,16 @@ func (m *Model) syncHistory() {
 	m.historyScopeCount = len(entries)
 	filter := strings.TrimSpace(m.historyFilterInput.Value())
 	if filter != "" {
-		entries = filterHistoryEntries(entries, filter)
+		q := parseHistoryQuery(filter, time.Now())
+		entries, err = filterHistoryEntries(hs, entries, q)
+		if err != nil {
+			m.setStatusMessage(
+				statusMsg{
+					text:  fmt.Sprintf("History search failed: %v", err),
+					level: statusWarn,
+				},
+			)
+		}
 	}
 	entries = sortHistoryEntries(entries, m.historySort)
 	m.historyEntries = entries
@@ -1428,14 +1450,10 @@ func (m *Model) handleHistoryFilterKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 		if val == "" {
 			m.setStatusMessage(statusMsg{text: "History filter cleared", level: statusInfo})
 		} else {
-			_, invalid := parseHistoryFilterAt(val, time.Now())
-			if len(invalid) > 0 {
+			if _, err := history.ParseQuery(val, time.Now()); err != nil {
 				m.setStatusMessage(
 					statusMsg{
-						text: fmt.Sprintf(
-							"Invalid date filter: %s (use DD-MM-YYYY, MM-DD-YYYY, or DD-MMM-YYYY)",
-							strings.Join(invalid, ", "),
-						),
+						text:  fmt.Sprintf("Invalid history filter: %v", err),
 						level: statusWarn,
 					},
 				)
@@ -1951,7 +1969,7 @@ func (m *Model) loadHistorySelection(send bool) tea.Cmd {
 		if compareBundle != nil {
 			focusEnv := strings.TrimSpace(targetEnv)
 			if focusEnv == "" && len(compareBundle.Rows) > 0 {
-				focusEnv = strings.TrimSpace(compareBundle.Rows[0].Result.Environment)
+				focusEnv = strings.TrimSpace(compareBundle.Rows[0].Result.label())
 			}
 			m.resetCompareState()
 			hydrated := m.populateCompareSnapshotsFromHistory(entry, compareBundle, focusEnv)
@@ -2008,6 +2026,9 @@ func (m *Model) loadHistoryDocument(doc *restfile.Document, requestText string)
 }
 
 func (m *Model) presentHistoryEntry(entry history.Entry, req *restfile.Request) tea.Cmd {
+	if cmd, ok := m.presentHistoryCapture(entry, req); ok {
+		return cmd
+	}
 	if entry.Trace == nil {
 		return nil
 	}
@@ -2046,6 +2067,75 @@ func (m *Model) presentHistoryEntry(entry history.Entry, req *restfile.Request)
 	return m.syncResponsePanes()
 }
 
+// presentHistoryCapture rebuilds the full response of an entry recorded in
+// capture mode and renders it like a live one. Entries without a capture, or
+// whose capture retention already dropped, fall back to the summary view.
+func (m *Model) presentHistoryCapture(
+	entry history.Entry,
+	req *restfile.Request,
+) (tea.Cmd, bool) {
+	if entry.Capture == nil {
+		return nil, false
+	}
+	c, err := history.LoadCapture(m.historyStore(), entry)
+	if err != nil {
+		m.setStatusMessage(statusMsg{
+			text:  fmt.Sprintf("History capture error: %v", err),
+			level: statusWarn,
+		})
+		return nil, false
+	}
+	if c == nil {
+		return nil, false
+	}
+
+	msg := responseMsg{executed: req, environment: entry.Environment}
+	var cmd tea.Cmd
+	if (req != nil && req.GRPC != nil) || strings.EqualFold(entry.Method, "GRPC") {
+		msg.grpc = &grpcx.Response{
+			Message:       string(c.Body),
+			Body:          c.Body,
+			ContentType:   c.ContentType,
+			Headers:       c.Headers,
+			Trailers:      c.Trailers,
+			StatusCode:    codes.Code(entry.StatusCode),
+			StatusMessage: c.StatusMessage,
+			Duration:      entry.Duration,
+		}
+		cmd = m.consumeGRPCResponse(msg)
+	} else {
+		resp := &httpx.Response{
+			Status:       entry.Status,
+			StatusCode:   entry.StatusCode,
+			Proto:        c.Proto,
+			Headers:      c.Headers,
+			Body:         c.Body,
+			Duration:     entry.Duration,
+			EffectiveURL: c.EffectiveURL,
+			Request:      req,
+		}
+		if entry.Trace != nil {
+			resp.Timeline = entry.Trace.Timeline()
+			resp.TraceReport = entry.Trace.Report()
+		}
+		msg.response = resp
+		cmd = m.consumeHTTPResponse(msg)
+	}
+
+	status := m.statusMessage
+	status.text += " - from history"
+	if c.Truncated {
+		status.text += fmt.Sprintf(
+			" (body cut to %s of %s)",
+			bodyfmt.FormatByteSize(int64(len(c.Body))),
+			bodyfmt.FormatByteSize(c.BodySize),
+		)
+		status.level = maxStatusLevel(status.level, statusWarn)
+	}
+	m.setStatusMessage(status)
+	return cmd, true
+}
+
 func (m *Model) applyHistorySnapshot(snap *responseSnapshot) {
 	if snap == nil {
 		return
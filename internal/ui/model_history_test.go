@@ -114,6 +114,39 @@ func TestRecordHTTPHistoryRedactsRuntimeSecretInCustomHeader(t *testing.T) {
 	}
 }
 
+// A streaming call's messages live only in the transcript, so the capture
+// has to carry it or the stored response is just the last message.
+func TestRecordGRPCHistoryCapturesTranscript(t *testing.T) {
+	store := histdb.New(filepath.Join(t.TempDir(), "history.db"))
+	store.SetCapture(history.CaptureOptions{Enabled: true})
+	t.Cleanup(func() { _ = store.Close() })
+	model := New(Config{History: store})
+
+	req := &restfile.Request{Method: "GRPC", URL: "localhost:50051"}
+	resp := &grpcx.Response{Message: `{"n":2}`}
+	model.recordGRPCHistory(
+		resp,
+		req,
+		[]byte(`{"n":1,"token":"tok-9"}`+"\n"+`{"n":2}`),
+		"GRPC localhost:50051\n",
+		"dev",
+		vars.Selection{},
+		"tok-9",
+	)
+
+	entries, err := store.Entries()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries = %v, %v", entries, err)
+	}
+	c, err := store.LoadCapture(entries[0].ID)
+	if err != nil || c == nil {
+		t.Fatalf("capture = %v, %v", c, err)
+	}
+	if got := string(c.Transcript); !strings.Contains(got, `{"n":1,`) || strings.Contains(got, "tok-9") {
+		t.Fatalf("transcript = %q", got)
+	}
+}
+
 func TestLoadHistorySelectionComparePrefersFailure(t *testing.T) {
 	model := New(Config{})
 	entry := history.Entry{
@@ -976,6 +1009,47 @@ func TestPresentHistoryEntryPopulatesTimeline(t *testing.T) {
 	}
 }
 
+func TestPresentHistoryEntryRestoresCapturedResponse(t *testing.T) {
+	store := histdb.New(filepath.Join(t.TempDir(), "history.db"))
+	store.SetCapture(history.CaptureOptions{Enabled: true, MaxBody: 8})
+	t.Cleanup(func() { _ = store.Close() })
+	if err := store.Append(history.Entry{
+		ID:          "1",
+		ExecutedAt:  time.Now(),
+		Method:      "GET",
+		URL:         "https://example.com",
+		Status:      "200 OK",
+		StatusCode:  200,
+		BodySnippet: "snippet",
+		Capture: &history.Capture{
+			Headers: http.Header{"X-Trace": {"abc"}},
+			Body:    []byte(`{"full":"response"}`),
+		},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	es, err := store.Entries()
+	if err != nil || len(es) != 1 {
+		t.Fatalf("entries = %v, %v", es, err)
+	}
+
+	model := New(Config{History: store})
+	if cmd := model.presentHistoryEntry(es[0], nil); cmd != nil {
+		collectMsgs(cmd)
+	}
+	resp := model.lastResponse
+	if resp == nil || string(resp.Body) != `{"full":` {
+		t.Fatalf("expected truncated captured body, got %+v", resp)
+	}
+	if resp.Headers.Get("X-Trace") != "abc" {
+		t.Fatalf("expected captured headers, got %v", resp.Headers)
+	}
+	text := model.statusMessage.text
+	if !strings.Contains(text, "from history") || !strings.Contains(text, "body cut") {
+		t.Fatalf("unexpected status %q", text)
+	}
+}
+
 func TestDiffTabAvailableAfterDualResponses(t *testing.T) {
 	model := New(Config{})
 	model.ready = true
@@ -0,0 +1,384 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/unkn0wn-root/resterm/internal/jwt"
+	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
+	"github.com/unkn0wn-root/resterm/internal/settings"
+)
+
+const (
+	jwksFetchTimeout = 10 * time.Second
+	maxJWKSBytes     = 1 << 20
+)
+
+// jwtSource is one place a token was found. A token seen in several places is
+// listed once with every label.
+type jwtSource struct {
+	labels []string
+	raw    string
+}
+
+type jwtInspectMsg struct {
+	text  string
+	count int
+	err   error
+}
+
+type jwtArgs struct {
+	token string
+	jwks  string
+}
+
+func parseJWTArgs(args []string) (jwtArgs, error) {
+	var out jwtArgs
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--jwks":
+			if i+1 >= len(args) {
+				return jwtArgs{}, errors.New("--jwks needs a path or URL")
+			}
+			i++
+			out.jwks = args[i]
+		case strings.HasPrefix(arg, "--jwks="):
+			out.jwks = strings.TrimPrefix(arg, "--jwks=")
+		case strings.HasPrefix(arg, "-"):
+			return jwtArgs{}, fmt.Errorf("unknown flag %s", arg)
+		case out.token != "":
+			return jwtArgs{}, errors.New("usage: :jwt [token] [--jwks path|url]")
+		default:
+			out.token = arg
+		}
+	}
+	return out, nil
+}
+
+// inspectJWT collects tokens on the update loop, where the response and the
+// auth caches are safe to read, then decodes and verifies them off it because
+// a JWKS URL means a network round trip.
+func (m *Model) inspectJWT(args []string) tea.Cmd {
+	opts, err := parseJWTArgs(args)
+	if err != nil {
+		return statusCmd(statusWarn, err.Error())
+	}
+	srcs := m.jwtSources(opts.token)
+	if len(srcs) == 0 {
+		return statusCmd(
+			statusInfo,
+			"No JWT found in the response, auth caches or globals. Pass one with :jwt <token>",
+		)
+	}
+	jwks := opts.jwks
+	var client *http.Client
+	switch {
+	case jwks == "":
+	case isJWKSURL(jwks):
+		c, err := m.jwksClient()
+		if err != nil {
+			return statusCmd(statusWarn, err.Error())
+		}
+		client = c
+	default:
+		path, err := m.resolveOpenPath(jwks)
+		if err != nil {
+			return statusCmd(statusWarn, err.Error())
+		}
+		jwks = path
+	}
+	return func() tea.Msg {
+		var (
+			set    *jwt.KeySet
+			setErr error
+		)
+		if jwks != "" {
+			ks, err := loadJWKS(client, jwks)
+			set, setErr = &ks, err
+		}
+		return jwtInspectMsg{
+			text:  renderJWTReport(srcs, set, setErr, time.Now()),
+			count: len(srcs),
+			err:   setErr,
+		}
+	}
+}
+
+func (m *Model) jwtSources(explicit string) []jwtSource {
+	var srcs []jwtSource
+	add := func(label, text string) {
+		for _, raw := range jwt.Find(text) {
+			i := slices.IndexFunc(srcs, func(s jwtSource) bool { return s.raw == raw })
+			if i < 0 {
+				srcs = append(srcs, jwtSource{raw: raw})
+				i = len(srcs) - 1
+			}
+			if !slices.Contains(srcs[i].labels, label) {
+				srcs[i].labels = append(srcs[i].labels, label)
+			}
+		}
+	}
+
+	if explicit != "" {
+		// Decode errors are rendered in the report rather than hidden.
+		raw := explicit
+		if tok, err := jwt.Decode(explicit); err == nil {
+			raw = tok.Raw
+		}
+		srcs = append(srcs, jwtSource{labels: []string{"argument"}, raw: raw})
+	}
+
+	if snap := m.mockCaptureSnapshot(); snap != nil && snap.ready {
+		add("response body", string(snap.body))
+		for _, name := range sortedHeaderNames(snap.responseHeaders) {
+			add("response header "+name, strings.Join(snap.responseHeaders.Values(name), "\n"))
+		}
+		if resp := snap.source.http; resp != nil {
+			for _, name := range sortedHeaderNames(resp.RequestHeaders) {
+				add("request header "+name, strings.Join(resp.RequestHeaders.Values(name), "\n"))
+			}
+		}
+	}
+
+	if rt := m.runtimeSvc(); rt != nil {
+		state := rt.AuthState()
+		for _, e := range state.OAuth {
+			label := "oauth2 cache " + e.Key
+			add(label+" access_token", e.Token.AccessToken)
+			if id, ok := e.Token.Raw["id_token"].(string); ok {
+				add(label+" id_token", id)
+			}
+		}
+		for _, e := range state.Command {
+			add("command cache "+e.Key, e.Token)
+		}
+	}
+
+	globals := m.globalsSnapshot()
+	keys := make([]string, 0, len(globals))
+	for key := range globals {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		add("global "+globals[key].Name, globals[key].Value)
+	}
+	return srcs
+}
+
+func sortedHeaderNames(h http.Header) []string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func isJWKSURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// jwksClient reaches the JWKS endpoint the way the focused request reached
+// its server: same proxy, CAs, client certificate and insecure setting.
+// Without a response the run defaults apply.
+func (m *Model) jwksClient() (*http.Client, error) {
+	opts := m.runOptions()
+	var resp *httpx.Response
+	if snap := m.mockCaptureSnapshot(); snap != nil {
+		resp = snap.source.http
+	}
+	if resp != nil && resp.Request != nil {
+		withReq := opts
+		if err := settings.ApplyHTTPSettings(&withReq, resp.Request.Settings, nil); err == nil {
+			opts = withReq
+		}
+	}
+	client := m.client
+	if client == nil {
+		client = httpx.NewClientWithOptions()
+	}
+	return client.HTTPClient(opts)
+}
+
+func loadJWKS(client *http.Client, src string) (jwt.KeySet, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if isJWKSURL(src) {
+		data, err = fetchJWKS(client, src)
+	} else {
+		data, err = os.ReadFile(src)
+	}
+	if err != nil {
+		return jwt.KeySet{}, err
+	}
+	return jwt.ParseKeySet(data)
+}
+
+func fetchJWKS(client *http.Client, url string) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+}
+
+func renderJWTReport(srcs []jwtSource, set *jwt.KeySet, setErr error, now time.Time) string {
+	var b strings.Builder
+	if setErr != nil {
+		fmt.Fprintf(&b, "JWKS could not be loaded: %v\n\n", setErr)
+	}
+	for i, src := range srcs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, strings.Join(src.labels, ", "))
+		tok, err := jwt.Decode(src.raw)
+		if err != nil {
+			fmt.Fprintf(&b, "    %v\n", err)
+			continue
+		}
+		fmt.Fprintf(&b, "    token  %s\n", clipToken(tok.Raw))
+		fmt.Fprintf(&b, "    alg    %s", valueOrDash(tok.Alg()))
+		if kid := tok.Kid(); kid != "" {
+			fmt.Fprintf(&b, "  kid %s", kid)
+		}
+		b.WriteString("\n")
+		for _, claim := range []string{"iat", "nbf", "exp"} {
+			if at, ok := tok.Time(claim); ok {
+				fmt.Fprintf(&b, "    %-6s %s\n", claim, at.Local().Format(time.RFC3339))
+			}
+		}
+		for _, f := range tok.Check(now) {
+			fmt.Fprintf(&b, "    %s %s\n", jwtFindingMark(f.Level), f.Message)
+		}
+		b.WriteString("    " + jwtSignatureLine(tok, set, setErr) + "\n")
+		writeJWTSection(&b, "header", tok.Header)
+		writeJWTSection(&b, "claims", tok.Claims)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func jwtFindingMark(l jwt.Level) string {
+	switch l {
+	case jwt.LevelProblem:
+		return "✗"
+	case jwt.LevelWarn:
+		return "!"
+	default:
+		return "✓"
+	}
+}
+
+func jwtSignatureLine(tok jwt.Token, set *jwt.KeySet, setErr error) string {
+	switch {
+	case set == nil && setErr == nil:
+		return "- signature not verified (use :jwt --jwks path|url)"
+	case setErr != nil:
+		return "- signature not verified"
+	}
+	key, err := jwt.Verify(tok, *set)
+	if err != nil {
+		return "✗ signature: " + strings.TrimPrefix(err.Error(), "jwt: ")
+	}
+	if key.Kid != "" {
+		return fmt.Sprintf("✓ signature verified with key %q", key.Kid)
+	}
+	return "✓ signature verified"
+}
+
+func writeJWTSection(b *strings.Builder, name string, v map[string]any) {
+	data, err := json.MarshalIndent(v, "      ", "  ")
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(b, "    %s\n      %s\n", name, data)
+}
+
+// The token is a credential. Enough of it is shown to tell tokens apart.
+func clipToken(raw string) string {
+	const keep = 24
+	if len(raw) <= keep {
+		return raw
+	}
+	return fmt.Sprintf("%s… (%d chars)", raw[:keep], len(raw))
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (m *Model) handleJWTInspect(msg jwtInspectMsg) tea.Cmd {
+	m.jwtInspectorText = msg.text
+	m.showJWTInspector = true
+	m.closeHelp()
+	m.showEnvSelector = false
+	m.showThemeSelector = false
+	if m.jwtInspectorViewport == nil {
+		vp := viewport.New(0, 0)
+		m.jwtInspectorViewport = &vp
+	}
+	m.jwtInspectorViewport.SetContent(m.jwtInspectorText)
+	m.jwtInspectorViewport.GotoTop()
+	if msg.err != nil {
+		return statusCmd(statusWarn, "JWKS could not be loaded: "+oneLine(msg.err.Error()))
+	}
+	return statusCmd(statusInfo, fmt.Sprintf("Decoded %d JWT(s)", msg.count))
+}
+
+func (m *Model) closeJWTInspector() {
+	m.showJWTInspector = false
+	m.jwtInspectorText = ""
+}
+
+func (m Model) renderJWTInspectorModal() string {
+	size := m.modalSize(120, 30)
+	body := m.jwtInspectorText
+	if vp := m.jwtInspectorViewport; vp != nil {
+		if vp.Width != size.view || vp.Height != size.body {
+			vp.Width = size.view
+			vp.Height = size.body
+			vp.SetContent(m.jwtInspectorText)
+		}
+		body = vp.View()
+	}
+	bodyView := lipgloss.NewStyle().Padding(0, 2).Width(size.content).Render(body)
+	instructions := fmt.Sprintf(
+		"%s / %s Close  j/k Scroll",
+		m.theme.CommandBarHint.Render("Esc"),
+		m.theme.CommandBarHint.Render("Enter"),
+	)
+	return m.renderModalBox("JWT Inspector", bodyView, instructions, size.width)
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/jwt"
+	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
+	"github.com/unkn0wn-root/resterm/internal/restfile"
+)
+
+func testJWT(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	in := enc(map[string]any{"alg": "HS256", "kid": "k1"}) + "." + enc(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(in))
+	return in + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseJWTArgs(t *testing.T) {
+	got, err := parseJWTArgs([]string{"abc", "--jwks=keys.json"})
+	if err != nil || got.token != "abc" || got.jwks != "keys.json" {
+		t.Fatalf("parseJWTArgs = %+v, %v", got, err)
+	}
+	got, err = parseJWTArgs([]string{"--jwks", "https://issuer/jwks"})
+	if err != nil || got.token != "" || got.jwks != "https://issuer/jwks" {
+		t.Fatalf("parseJWTArgs = %+v, %v", got, err)
+	}
+	for _, args := range [][]string{{"--jwks"}, {"--bogus"}, {"a", "b"}} {
+		if _, err := parseJWTArgs(args); err == nil {
+			t.Errorf("parseJWTArgs(%q) succeeded", args)
+		}
+	}
+}
+
+func TestJWTCommandInspectsFocusedResponse(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	token := testJWT(t, "secret", map[string]any{"sub": "42", "exp": exp})
+	dir := t.TempDir()
+	jwks := `{"keys":[{"kty":"oct","kid":"k1","k":"` +
+		base64.RawURLEncoding.EncodeToString([]byte("secret")) + `"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "jwks.json"), []byte(jwks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	model := newTestModelWithDoc(sampleRequestDoc)
+	model.ws.root = dir
+	model.responseLatest = &responseSnapshot{
+		id:              "snap",
+		ready:           true,
+		body:            []byte(`{"access_token":"` + token + `"}`),
+		responseHeaders: http.Header{"X-Token": {token}},
+		source: newHTTPResponseRenderSource(&httpx.Response{
+			RequestHeaders: http.Header{"Authorization": {"Bearer " + token}},
+		}, nil, nil),
+	}
+
+	cmd := model.executeExCommand("jwt --jwks jwks.json")
+	if cmd == nil {
+		t.Fatal("jwt command is nil")
+	}
+	msg, ok := cmd().(jwtInspectMsg)
+	if !ok {
+		t.Fatal("jwt command did not produce a jwtInspectMsg")
+	}
+	_ = model.handleJWTInspect(msg)
+	if !model.showJWTInspector || msg.count != 1 {
+		t.Fatalf("inspector = %t, count = %d", model.showJWTInspector, msg.count)
+	}
+	text := model.jwtInspectorText
+	for _, want := range []string{
+		"response body, response header X-Token, request header Authorization",
+		"expires in",
+		`signature verified with key "k1"`,
+		`"sub": "42"`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, token) {
+		t.Error("report shows the full token")
+	}
+	model.closeJWTInspector()
+	if model.showJWTInspector {
+		t.Fatal("inspector still open")
+	}
+}
+
+func TestRenderJWTReportFlagsExpiredAndBadSignature(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	token := testJWT(t, "secret", map[string]any{"exp": now.Add(-time.Minute).Unix()})
+	set, err := jwt.ParseKeySet([]byte(`{"kty":"oct","k":"` +
+		base64.RawURLEncoding.EncodeToString([]byte("other")) + `"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := renderJWTReport([]jwtSource{{labels: []string{"argument"}, raw: token}}, &set, nil, now)
+	for _, want := range []string{"✗ expired 1m0s ago", "✗ signature: signature does not match"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestJWKSClientUsesRequestTLSSettings(t *testing.T) {
+	jwks := `{"keys":[{"kty":"oct","kid":"k1","k":"c2VjcmV0"}]}`
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(jwks))
+	}))
+	defer srv.Close()
+
+	model := newTestModelWithDoc(sampleRequestDoc)
+	if _, err := loadJWKS(mustJWKSClient(t, model), srv.URL); err == nil {
+		t.Fatal("expected the self-signed JWKS server to be rejected by default")
+	}
+
+	model.responseLatest = &responseSnapshot{
+		id:    "snap",
+		ready: true,
+		source: newHTTPResponseRenderSource(&httpx.Response{
+			Request: &restfile.Request{Settings: map[string]string{"http-insecure": "true"}},
+		}, nil, nil),
+	}
+	set, err := loadJWKS(mustJWKSClient(t, model), srv.URL)
+	if err != nil || len(set.Keys) != 1 {
+		t.Fatalf("load jwks with the request's settings: %v / %+v", err, set)
+	}
+}
+
+func mustJWKSClient(t *testing.T, m *Model) *http.Client {
+	t.Helper()
+	client, err := m.jwksClient()
+	if err != nil {
+		t.Fatalf("jwks client: %v", err)
+	}
+	return client
+}
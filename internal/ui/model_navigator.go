@@ -372,6 +372,9 @@ func requestBadges(req *restfile.Request) []string {
 	if req.Metadata.Compare != nil {
 		b = append(b, "CMP")
 	}
+	if req.Metadata.Identities != nil {
+		b = append(b, "AS")
+	}
 	if req.Metadata.Auth != nil {
 		b = append(b, "AUTH")
 	}
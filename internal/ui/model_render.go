@@ -97,6 +97,9 @@ func (m Model) View() string {
 	if m.showMockVerification {
 		return m.renderWithinAppFrame(m.renderMockVerificationModal())
 	}
+	if m.showJWTInspector {
+		return m.renderWithinAppFrame(m.renderJWTInspectorModal())
+	}
 	if m.showMockLogs {
 		return m.renderWithinAppFrame(m.renderMockLogsModal())
 	}
@@ -220,6 +220,9 @@ func detailMeta(req *restfile.Request) string {
 	if req.Metadata.Compare != nil {
 		parts = append(parts, "Compare")
 	}
+	if req.Metadata.Identities != nil {
+		parts = append(parts, "As "+strings.Join(req.Metadata.Identities.Names, ","))
+	}
 	if req.Metadata.Trace != nil && req.Metadata.Trace.Enabled {
 		parts = append(parts, "Trace")
 	}
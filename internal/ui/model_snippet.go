@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/unkn0wn-root/resterm/internal/parser"
+	"github.com/unkn0wn-root/resterm/internal/snippet"
+)
+
+// copyRequestSnippet copies the request at the cursor as a curl command. It is
+// prepared like an Explain preview, so nothing is sent, and secrets are masked
+// because the command usually ends up in a ticket or a chat.
+func (m *Model) copyRequestSnippet() tea.Cmd {
+	if cmd := m.runBlocked(); cmd != nil {
+		return cmd
+	}
+	content := m.editor.Value()
+	doc := parser.Parse(m.currentFile, []byte(content))
+	if err := docErr(doc); err != nil {
+		return statusCmd(statusError, err.Error())
+	}
+	req, _ := m.requestAtCursor(doc, content, currentCursorLine(m.editor))
+	if req == nil {
+		return statusCmd(statusWarn, "No request at cursor")
+	}
+	env, err := m.environment(m.ws.sel)
+	if err != nil {
+		return statusCmd(statusError, err.Error())
+	}
+	svc := m.requestSvc(m.runOptions())
+	if svc == nil {
+		return nil
+	}
+	req = req.Clone()
+	return func() tea.Msg {
+		prep, err := svc.PrepareHTTP(context.Background(), doc, req, env, true)
+		if err != nil {
+			return snippetMsg{err: err}
+		}
+		out, err := snippet.Render(snippet.Curl, snippet.Request{
+			Method: prep.Method,
+			URL:    prep.URL,
+			Header: prep.Header,
+			Body:   prep.Body,
+		})
+		return snippetMsg{text: out, warnings: prep.Warnings, err: err}
+	}
+}
+
+// snippetMsg carries a rendered snippet back to Update, which owns the editor
+// register the clipboard write falls back to.
+type snippetMsg struct {
+	text     string
+	warnings []string
+	err      error
+}
+
+func (m *Model) handleSnippet(msg snippetMsg) {
+	if msg.err != nil {
+		m.setStatusMessage(statusMsg{
+			level: statusError,
+			text:  "Copy as curl failed: " + msg.err.Error(),
+		})
+		return
+	}
+	status := m.editor.writeClipboardWithFallback(msg.text, "Copied request as curl (secrets masked)")
+	if len(msg.warnings) > 0 && status.level == statusInfo {
+		status.level = statusWarn
+		status.text += "; " + strings.Join(msg.warnings, "; ")
+	}
+	m.setStatusMessage(status)
+}
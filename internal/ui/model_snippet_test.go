@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The snippet is rendered off the update loop, so the register write has to
+// happen when Update receives the result, on the model that is kept.
+func TestSnippetMsgWritesRegisterInUpdate(t *testing.T) {
+	model := New(Config{})
+	next, _ := model.Update(snippetMsg{
+		text:     "curl https://example.com",
+		warnings: []string{"token masked"},
+	})
+	got := next.(Model)
+	if got.editor.registerText != "curl https://example.com" {
+		t.Fatalf("register = %q", got.editor.registerText)
+	}
+	// Without a clipboard the register fallback is reported instead.
+	if got.statusMessage.level != statusWarn {
+		t.Fatalf("status = %+v", got.statusMessage)
+	}
+
+	next, _ = got.Update(snippetMsg{err: errors.New("boom")})
+	if got = next.(Model); got.statusMessage.level != statusError ||
+		!strings.Contains(got.statusMessage.text, "boom") {
+		t.Fatalf("error status = %+v", got.statusMessage)
+	}
+}
@@ -89,10 +89,13 @@ func (m *Model) availableResponseTabsFor(id responsePaneID) []responseTab {
 	if snapshotHasTrace(snap) {
 		tabs = append(tabs, responseTabTimeline)
 	}
+	if _, ok := m.currentTrend(); ok {
+		tabs = append(tabs, responseTabTrends)
+	}
 	if m.compareTabAvailable() {
 		tabs = append(tabs, responseTabCompare)
 	}
-	if m.diffAvailable() {
+	if m.diffAvailable() || snap != nil && snap.snapshotDiff != "" {
 		tabs = append(tabs, responseTabDiff)
 	}
 	tabs = append(tabs, responseTabHistory)
@@ -129,6 +132,8 @@ func responseTabLabelForSnapshot(tab responseTab, snapshot *responseSnapshot) st
 		}
 	case responseTabTimeline:
 		return "Timeline"
+	case responseTabTrends:
+		return "Trends"
 	case responseTabCompare:
 		return "Compare"
 	case responseTabDiff:
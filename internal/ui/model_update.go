@@ -106,6 +106,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.setStatusMessage(typed)
 	case docsOpenedMsg:
 		m.handleDocsOpened(typed)
+	case snippetMsg:
+		m.handleSnippet(typed)
 	case statusPulseMsg:
 		if cmd := m.handleStatusPulse(typed); cmd != nil {
 			cmds = append(cmds, cmd)
@@ -219,6 +221,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if cmd := m.handleMockVerify(typed); cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+	case jwtInspectMsg:
+		if cmd := m.handleJWTInspect(typed); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 	case pathReadMsg:
 		m.handlePathRead(typed)
 	case wsConsoleResultMsg:
@@ -242,6 +248,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, batchCommands(cmds...)
 	}
 
+	if m.showJWTInspector {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			cmd := modalKey(keyMsg.String(), m.closeJWTInspector, m.jwtInspectorViewport)
+			return m, batchCommands(append(cmds, cmd)...)
+		}
+		return m, batchCommands(cmds...)
+	}
+
 	if m.showMockLogs {
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
 			return m, batchCommands(append(cmds, m.handleMockLogsKey(keyMsg))...)
@@ -1096,6 +1110,8 @@ func (m *Model) runShortcutBinding(binding bindings.Binding, msg tea.KeyMsg) (te
 		return m.copyResponseTab(), true
 	case bindings.ActionExplainRequest:
 		return m.explainActiveRequest(), true
+	case bindings.ActionCopyRequestSnippet:
+		return m.copyRequestSnippet(), true
 	case bindings.ActionToggleHeaderPreview:
 		return m.activateHeaderSubviewFromBinding(), true
 	case bindings.ActionCycleRawView:
@@ -1184,7 +1200,8 @@ func (m *Model) modalCapturesGlobalKeys() bool {
 		m.showLayoutSaveModal,
 		m.showFileChangeModal,
 		m.showMockLogs,
-		m.showMockVerification:
+		m.showMockVerification,
+		m.showJWTInspector:
 		return true
 	default:
 		return false
@@ -1782,6 +1799,10 @@ func (m *Model) handleKeyWithChord(msg tea.KeyMsg, allowChord bool) tea.Cmd {
 					)
 				}
 				return combine(nil)
+			case "x":
+				m.shareHistorySelection()
+				m.blockHistoryKey()
+				return combine(nil)
 			case "r", "R", "ctrl+r", "ctrl+R":
 				return combine(m.replayHistorySelection())
 			case "enter":
@@ -583,6 +583,7 @@ func (m *Model) wfConsume(st *workflowState, msg responseMsg) []tea.Cmd {
 			m.recordGRPCHistory(
 				msg.grpc,
 				msg.executed,
+				msg.transcript,
 				msg.requestText,
 				msg.environment,
 				msg.selection,
@@ -312,10 +312,16 @@ func requestTypeBadge(req *restfile.Request) string {
 }
 
 func requestCompareBadge(req *restfile.Request) string {
-	if req == nil || req.Metadata.Compare == nil {
+	switch {
+	case req == nil:
+		return ""
+	case req.Metadata.Compare != nil:
+		return "[CMP]"
+	case req.Metadata.Identities != nil:
+		return "[AS]"
+	default:
 		return ""
 	}
-	return "[CMP]"
 }
 
 func joinTags(tags []string, max int) string {
@@ -856,10 +856,7 @@ func padStyled(content string, width int) string {
 }
 
 func formatCompareEnvLabel(row compareRow, baseline, focused string) string {
-	env := ""
-	if row.Result != nil {
-		env = strings.TrimSpace(row.Result.Environment)
-	}
+	env := strings.TrimSpace(row.Result.label())
 	if env == "" {
 		env = "(env)"
 	}
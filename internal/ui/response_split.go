@@ -2,6 +2,7 @@ package ui
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -17,6 +18,7 @@ import (
 	xplain "github.com/unkn0wn-root/resterm/internal/explain"
 	"github.com/unkn0wn-root/resterm/internal/nettrace"
 	"github.com/unkn0wn-root/resterm/internal/restfile"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
 )
 
 type responsePaneID int
@@ -40,34 +42,36 @@ type explainState struct {
 }
 
 type responseSnapshot struct {
-	id              string
-	streamID        string
-	stream          *liveSession
-	pretty          string
-	raw             string
-	rawSummary      string
-	rawText         string
-	rawHex          string
-	rawBase64       string
-	rawMode         rawViewMode
-	rawLoading      bool
-	rawLoadingMode  rawViewMode
-	headers         string
-	requestHeaders  string
-	explain         explainState
-	stats           string
-	statsColored    string
-	statsColorize   bool
-	statsKind       statsReportKind
-	profileStats    *analysis.LatencyStats
-	workflowStats   *workflowStatsView
-	ready           bool
-	timeline        *nettrace.Timeline
-	traceData       *nettrace.Report
-	traceReport     timelineReport
-	traceSpec       *restfile.TraceSpec
-	environment     string
-	compareBundle   *compareBundle
+	id             string
+	streamID       string
+	stream         *liveSession
+	pretty         string
+	raw            string
+	rawSummary     string
+	rawText        string
+	rawHex         string
+	rawBase64      string
+	rawMode        rawViewMode
+	rawLoading     bool
+	rawLoadingMode rawViewMode
+	headers        string
+	requestHeaders string
+	explain        explainState
+	stats          string
+	statsColored   string
+	statsColorize  bool
+	statsKind      statsReportKind
+	profileStats   *analysis.LatencyStats
+	workflowStats  *workflowStatsView
+	ready          bool
+	timeline       *nettrace.Timeline
+	traceData      *nettrace.Report
+	traceReport    timelineReport
+	traceSpec      *restfile.TraceSpec
+	environment    string
+	compareBundle  *compareBundle
+	// snapshotDiff is set when the response differs from its @snapshot file.
+	snapshotDiff    string
 	body            []byte
 	bodyMeta        binaryview.Meta
 	contentType     string
@@ -950,6 +954,9 @@ func (m *Model) paneContentBase(
 		}
 		return content, tab
 	}
+	if tab == responseTabTrends {
+		return m.trendsContent(w), tab
+	}
 	snapshot := pane.snapshot
 	if snapshot == nil {
 		return "", tab
@@ -1020,6 +1027,10 @@ func (m *Model) paneContentBase(
 		return content, tab
 	case responseTabDiff:
 		baseTab := pane.ensureContentTab()
+		// Outside a split the tab shows how the response broke its @snapshot.
+		if !m.diffAvailable() && snapshot.snapshotDiff != "" {
+			return colorizeDiff(strings.TrimRight(snapshot.snapshotDiff, "\n")), tab
+		}
 		if diff, ok := m.computeDiffFor(id, baseTab); ok {
 			return diff, tab
 		}
@@ -1085,12 +1096,23 @@ func (m *Model) computeDiffFor(id responsePaneID, baseTab responseTab) (string,
 		sections = append(sections, diff)
 	}
 
+	// JSON and XML bodies are diffed by structure; the Raw tab keeps the text
+	// diff for everything else.
+	appendBody := func() {
+		if sec, ok := semanticBodyDiff(left, right); ok {
+			appendDiff("", prettyHead(left.pretty), prettyHead(right.pretty), leftLabel, rightLabel)
+			sections = append(sections, sec)
+			return
+		}
+		appendDiff("", left.pretty, right.pretty, leftLabel, rightLabel)
+	}
+
 	switch baseTab {
 	case responseTabRaw:
 		appendDiff("", left.raw, right.raw, leftLabel, rightLabel)
 	case responseTabHeaders:
 		// Always include the response body diff when users land here from Headers.
-		appendDiff("", left.pretty, right.pretty, leftLabel, rightLabel)
+		appendBody()
 		leftHeaders := left.headers
 		if leftHeaders == "" {
 			leftHeaders = "<no headers>\n"
@@ -1107,7 +1129,7 @@ func (m *Model) computeDiffFor(id responsePaneID, baseTab responseTab) (string,
 			rightLabel+" headers",
 		)
 	default:
-		appendDiff("", left.pretty, right.pretty, leftLabel, rightLabel)
+		appendBody()
 	}
 
 	if len(sections) == 0 {
@@ -1117,12 +1139,50 @@ func (m *Model) computeDiffFor(id responsePaneID, baseTab responseTab) (string,
 	return colorizeDiff(combined), true
 }
 
+// semanticBodyDiff lists body changes path by path when both bodies decode as
+// the same document format, applying the @compare rules of a compare run.
+func semanticBodyDiff(left, right *responseSnapshot) (string, bool) {
+	var rules semdiff.Options
+	switch {
+	case left.compareBundle != nil:
+		rules = left.compareBundle.Rules
+	case right.compareBundle != nil:
+		rules = right.compareBundle.Rules
+	}
+	changes, format, ok := semdiff.Bodies(
+		left.body,
+		right.body,
+		left.contentType,
+		right.contentType,
+		rules,
+	)
+	if !ok {
+		return "", false
+	}
+	if len(changes) == 0 {
+		return fmt.Sprintf("Semantic diff (%s) · bodies match", format), true
+	}
+	lines := make([]string, 0, len(changes)+1)
+	lines = append(lines, fmt.Sprintf("Semantic diff (%s) · %d change(s)", format, len(changes)))
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// prettyHead is the status block the pretty view prints above the body.
+func prettyHead(pretty string) string {
+	head, _, _ := strings.Cut(pretty, "\n\n")
+	return head
+}
+
 func colorizeDiff(diff string) string {
 	lines := strings.Split(diff, "\n")
 	green := lipgloss.NewStyle().Foreground(lipgloss.Color("#44C25B"))
 	red := lipgloss.NewStyle().Foreground(lipgloss.Color("#F25F5C"))
 	hunk := lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")).Bold(true)
 	meta := lipgloss.NewStyle().Foreground(lipgloss.Color("#A6A1BB")).Italic(true)
+	changed := lipgloss.NewStyle().Foreground(lipgloss.Color("#E5C07B"))
 
 	var builder strings.Builder
 	for i, line := range lines {
@@ -1136,6 +1196,8 @@ func colorizeDiff(diff string) string {
 			styled = green.Render(stripANSIEscape(line))
 		case strings.HasPrefix(line, "-"):
 			styled = red.Render(stripANSIEscape(line))
+		case strings.HasPrefix(line, "~ "):
+			styled = changed.Render(line)
 		}
 		builder.WriteString(styled)
 		if i < len(lines)-1 {
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/unkn0wn-root/resterm/internal/protocol/httpx"
+	"github.com/unkn0wn-root/resterm/internal/semdiff"
 )
 
 func TestWrapDiffContentPreservesMarkers(t *testing.T) {
@@ -110,6 +111,53 @@ func TestComputeDiffForHeadersIncludesBody(t *testing.T) {
 	}
 }
 
+func TestComputeDiffUsesSemanticBodyDiff(t *testing.T) {
+	model := New(Config{})
+	model.responseSplit = true
+
+	rules, err := semdiff.Compile([]string{"$.requestId"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := &compareBundle{Baseline: "dev", Rules: rules}
+	left := &responseSnapshot{
+		pretty:        "Status: 200 OK\n\n{\"id\": 1, \"price\": 10, \"requestId\": \"a\"}\n",
+		raw:           "raw-1\n",
+		body:          []byte(`{"id": 1, "price": 10, "requestId": "a"}`),
+		contentType:   "application/json",
+		compareBundle: bundle,
+		ready:         true,
+	}
+	right := &responseSnapshot{
+		pretty:        "Status: 200 OK\n\n{\"requestId\": \"b\", \"price\": 12, \"id\": 1}\n",
+		raw:           "raw-2\n",
+		body:          []byte(`{"requestId": "b", "price": 12, "id": 1}`),
+		contentType:   "application/json",
+		compareBundle: bundle,
+		ready:         true,
+	}
+	model.responsePanes[0].snapshot = left
+	model.responsePanes[1].snapshot = right
+
+	diff, ok := model.computeDiffFor(responsePanePrimary, responseTabPretty)
+	if !ok {
+		t.Fatal("expected diff availability")
+	}
+	plain := stripANSIEscape(diff)
+	if !strings.Contains(plain, "Semantic diff (json) · 1 change(s)") ||
+		!strings.Contains(plain, "~ $.price: 10 → 12") {
+		t.Fatalf("expected semantic body diff, got %q", plain)
+	}
+	if strings.Contains(plain, "requestId") {
+		t.Fatalf("ignored path leaked into diff: %q", plain)
+	}
+
+	raw, _ := model.computeDiffFor(responsePanePrimary, responseTabRaw)
+	if !strings.Contains(stripANSIEscape(raw), "raw-2") {
+		t.Fatalf("raw tab should keep the text diff, got %q", raw)
+	}
+}
+
 func TestComputeDiffRawUsesRawView(t *testing.T) {
 	model := New(Config{})
 	model.responseSplit = true
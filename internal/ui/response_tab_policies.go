@@ -12,6 +12,7 @@ func tabAllowsOverlay(tab responseTab) bool {
 		responseTabStream,
 		responseTabStats,
 		responseTabTimeline,
+		responseTabTrends,
 		responseTabCompare,
 		responseTabDiff:
 		return true
@@ -27,6 +28,7 @@ func tabAllowsAsyncWrap(tab responseTab) bool {
 		responseTabExplain,
 		responseTabStats,
 		responseTabTimeline,
+		responseTabTrends,
 		responseTabCompare,
 		responseTabDiff:
 		return true
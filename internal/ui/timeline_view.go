@@ -260,18 +260,20 @@ func phaseOrder(kind nettrace.PhaseKind) int {
 		return 1
 	case nettrace.PhaseTLS:
 		return 2
-	case nettrace.PhaseReqHdrs:
+	case nettrace.PhaseQUIC:
 		return 3
-	case nettrace.PhaseReqBody:
+	case nettrace.PhaseReqHdrs:
 		return 4
-	case nettrace.PhaseTTFB:
+	case nettrace.PhaseReqBody:
 		return 5
-	case nettrace.PhaseTransfer:
+	case nettrace.PhaseTTFB:
 		return 6
-	case nettrace.PhaseTotal:
+	case nettrace.PhaseTransfer:
 		return 7
-	default:
+	case nettrace.PhaseTotal:
 		return 8
+	default:
+		return 9
 	}
 }
 
@@ -283,6 +285,8 @@ func humanPhaseName(kind nettrace.PhaseKind) string {
 		return "TCP connect"
 	case nettrace.PhaseTLS:
 		return "TLS handshake"
+	case nettrace.PhaseQUIC:
+		return "QUIC handshake"
 	case nettrace.PhaseReqHdrs:
 		return "Request headers"
 	case nettrace.PhaseReqBody:
@@ -517,6 +521,9 @@ func renderPhaseMeta(meta nettrace.PhaseMeta) string {
 	if meta.Cached {
 		parts = append(parts, "cached")
 	}
+	if meta.Override {
+		parts = append(parts, "override")
+	}
 	return strings.Join(parts, " ")
 }
 
@@ -23,6 +23,9 @@ type Catalog struct {
 	groups []Group
 	shared map[string]string
 	source string
+	// encrypted marks a catalog decrypted from an encrypted file. Every value
+	// in it is a secret, as if each were an enc: reference.
+	encrypted bool
 }
 
 type Group struct {
@@ -76,16 +79,19 @@ func NewCatalog(set EnvironmentSet) (Catalog, error) {
 	return Catalog{envs: envs}, nil
 }
 
-// checkEnvRefs rejects an env: reference with no variable name. A JSON file has
-// no line to report against, so the catalog refuses to load instead.
+// checkEnvRefs rejects a reference with an empty key, such as env: with no
+// variable name. A JSON file has no line to report against, so the catalog
+// refuses to load instead.
 func checkEnvRefs(scope string, values map[string]string) error {
 	for _, name := range slices.Sorted(maps.Keys(values)) {
-		if key, ok := EnvRefKey(values[name]); ok && key == "" {
+		if ref, ok := ParseRef(values[name]); ok && ref.Key == "" {
 			return diag.Newf(
 				diag.ClassParse,
-				"%s: %q has an env: reference with no variable name",
+				"%s: %q: %s: reference with no %s",
 				scope,
 				name,
+				ref.Scheme,
+				ref.Noun(),
 			)
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/envcrypt"
 	str "github.com/unkn0wn-root/resterm/internal/util"
 )
 
@@ -20,9 +21,12 @@ const (
 
 type EnvironmentSet map[string]map[string]string
 
+// The encrypted file comes last, so a decrypted copy kept out of git takes
+// precedence on the machine that has it.
 var environmentFileCandidates = [...]string{
 	"rest-client.env.json",
 	"resterm.env.json",
+	envcrypt.DefaultFile,
 }
 
 // IsEnvFileName reports whether path names one of the files discovery opens.
@@ -69,13 +73,29 @@ func LoadEnvironmentFile(path string) (Catalog, error) {
 	if err != nil {
 		return Catalog{}, diag.WrapAsf(diag.ClassFilesystem, err, "read env file %s", path)
 	}
+	encrypted := envcrypt.IsEncryptedPath(path)
+	if encrypted {
+		if data, err = decryptEnvFile(data); err != nil {
+			return Catalog{}, diag.WrapAsf(diag.ClassConfig, err, "decrypt env file %s", path)
+		}
+	}
 	cat, err := parseCatalog(data)
 	if err != nil {
 		return Catalog{}, diag.WrapAsf(diag.ClassParse, err, "parse env file %s", path)
 	}
+	cat.encrypted = encrypted
 	return cat.withSource(path), nil
 }
 
+// decryptEnvFile keeps the plaintext in memory only.
+func decryptEnvFile(data []byte) ([]byte, error) {
+	keys, err := envcrypt.LoadKeys()
+	if err != nil {
+		return nil, err
+	}
+	return envcrypt.Decrypt(data, keys)
+}
+
 // Discover loads the first environment file found directly under roots, tried
 // in order. Nothing is added implicitly, so the caller decides whether ambient
 // directories such as the working directory take part.
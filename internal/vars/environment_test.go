@@ -4,8 +4,13 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+
+	"filippo.io/age"
+
+	"github.com/unkn0wn-root/resterm/internal/envcrypt"
 )
 
 func TestLoadEnvironmentFileFlattensNestedObjects(t *testing.T) {
@@ -290,6 +295,65 @@ func compareErr(cat Catalog, group string) error {
 	return err
 }
 
+func TestLoadEnvironmentFileDecryptsEncryptedFile(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "age.key")
+	if err := os.WriteFile(keyPath, []byte(id.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envcrypt.EnvIdentity, keyPath)
+	t.Setenv(envcrypt.EnvPassphrase, "")
+
+	ref, err := envcrypt.EncryptValue("inner", id.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := `{"dev":{"url":"https://api.dev","token":"` + ref + `"}}`
+	data, err := envcrypt.Encrypt([]byte(plain), id.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, envcrypt.DefaultFile)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cat, err := LoadEnvironmentFile(path)
+	if err != nil {
+		t.Fatalf("load encrypted env: %v", err)
+	}
+	sel, err := cat.Select("dev", nil)
+	if err != nil {
+		t.Fatalf("select dev: %v", err)
+	}
+	env, err := cat.Resolve(sel)
+	if err != nil {
+		t.Fatalf("resolve dev: %v", err)
+	}
+	dev := env.Resolve()
+	if got := dev.Values(); got["url"] != "https://api.dev" || got["token"] != "inner" {
+		t.Fatalf("decrypted values = %#v", got)
+	}
+	// Every value of an encrypted file is a secret, not only enc: values.
+	if secrets := dev.Secrets(); !slices.Contains(secrets, "inner") ||
+		!slices.Contains(secrets, "https://api.dev") || len(secrets) != 2 {
+		t.Fatalf("Secrets() = %#v, want every decrypted value", secrets)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	if err := os.WriteFile(keyPath, []byte(other.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEnvironmentFile(path); err == nil ||
+		!strings.Contains(err.Error(), "decrypt env file") {
+		t.Fatalf("wrong key error = %v, want a decrypt failure", err)
+	}
+}
+
 func resolveValues(t *testing.T, cat Catalog, name string) map[string]string {
 	t.Helper()
 	sel, err := cat.Select(name, nil)
@@ -1,32 +1,76 @@
 package vars
 
 import (
-	"os"
-	"strings"
+	"github.com/unkn0wn-root/resterm/internal/vars/secret"
 )
 
+// Ref is a declared reference to a value kept outside the file, such as
+// env:TOKEN or file:/run/secrets/token. Scheme is lowercased.
+type Ref struct {
+	Scheme string
+	Key    string
+}
+
+// ParseRef parses a reference for any registered secret provider. An empty key
+// is still a reference.
+func ParseRef(raw string) (Ref, bool) {
+	scheme, key, ok := secret.Parse(raw)
+	return Ref{Scheme: scheme, Key: key}, ok
+}
+
+func (r Ref) String() string {
+	return r.Scheme + ":" + r.Key
+}
+
+// Noun says what the key identifies, for messages about an empty key.
+func (r Ref) Noun() string {
+	if p, ok := secret.Get(r.Scheme); ok {
+		return p.Noun()
+	}
+	return "key"
+}
+
 // EnvRefKey parses an env: reference. An empty name is still a reference.
 func EnvRefKey(raw string) (string, bool) {
-	trimmed := strings.TrimSpace(raw)
-	if len(trimmed) < 4 || !strings.EqualFold(trimmed[:4], "env:") {
+	ref, ok := ParseRef(raw)
+	if !ok || ref.Scheme != "env" {
 		return "", false
 	}
-	return strings.TrimSpace(trimmed[4:]), true
+	return ref.Key, true
 }
 
+// EnvRefs resolves the references of one run, whatever their provider. Each
+// reference is read once, so a command or a keyring prompt runs at most once
+// per run.
 type EnvRefs struct {
-	seen    map[string]Value
-	named   map[string]Value
+	seen    map[Ref]Value
+	named   map[Ref]Value
 	secrets Secrets
 	// names excludes runtime data so a response cannot select an OS variable.
 	names    *Resolver
 	withheld bool
+	// peek answers only from values providers already hold. See CachedEnvRefs.
+	peek bool
 }
 
 func NewEnvRefs(names *Resolver) *EnvRefs {
 	return &EnvRefs{names: names}
 }
 
+// WithheldEnvRefs reads no reference at all. Labels and previews use it, so
+// drawing the request list never reads a file, runs a command or opens a
+// keyring prompt.
+func WithheldEnvRefs() *EnvRefs {
+	return &EnvRefs{withheld: true}
+}
+
+// CachedEnvRefs sees only values that a run already read, so code on the UI
+// goroutine can redact them without blocking. A reference no run has read yet
+// stays missing.
+func CachedEnvRefs(names *Resolver) *EnvRefs {
+	return &EnvRefs{names: names, peek: true}
+}
+
 // withhold hides referenced values but retains them for redaction.
 func (s *EnvRefs) withhold() *EnvRefs {
 	if s == nil {
@@ -35,56 +79,72 @@ func (s *EnvRefs) withhold() *EnvRefs {
 	return &EnvRefs{secrets: s.secrets, withheld: true}
 }
 
-// ResolveDeclared resolves env: references authored in configuration or request
-// files. Runtime values must not call it or they could select an OS variable.
+// ResolveDeclared resolves references authored in configuration or request
+// files. Runtime values must not call it or they could select an OS variable,
+// a file or a command.
 func (s *EnvRefs) ResolveDeclared(text string) Value {
-	ref, ok := EnvRefKey(text)
+	ref, ok := ParseRef(text)
 	if !ok {
 		return Value{Text: text}
 	}
-	if s == nil || s.withheld || ref == "" {
+	if s == nil || s.withheld || ref.Key == "" {
 		return Value{Missing: true}
 	}
-	if !HasPlaceholder(ref) {
+	if !HasPlaceholder(ref.Key) {
 		return s.resolve(ref)
 	}
 	return s.resolveNamed(ref)
 }
 
-func (s *EnvRefs) resolveNamed(ref string) Value {
+func (s *EnvRefs) resolveNamed(ref Ref) Value {
 	if v, ok := s.named[ref]; ok {
 		return v
 	}
 	v := Value{Missing: true}
 	if s.names != nil {
-		if expanded, err := s.names.ExpandTemplatesStatic(ref); err == nil {
-			v = s.resolve(expanded)
+		if expanded, err := s.names.ExpandTemplatesStatic(ref.Key); err == nil {
+			v = s.resolve(Ref{Scheme: ref.Scheme, Key: expanded})
 		}
 	}
 	if s.named == nil {
-		s.named = make(map[string]Value)
+		s.named = make(map[Ref]Value)
 	}
 	s.named[ref] = v
 	return v
 }
 
-func (s *EnvRefs) resolve(key string) Value {
-	if v, ok := s.seen[key]; ok {
+// A provider error is kept on the missing value, so a request that uses the
+// variable reports why instead of calling it undefined.
+func (s *EnvRefs) resolve(ref Ref) Value {
+	if v, ok := s.seen[ref]; ok {
 		return v
 	}
 	v := Value{Missing: true}
-	if text, ok := lookupEnv(key); ok {
+	var (
+		text string
+		ok   bool
+		err  error
+	)
+	if s.peek {
+		text, ok = secret.Peek(ref.Scheme, ref.Key)
+	} else {
+		text, ok, err = secret.Lookup(ref.Scheme, ref.Key)
+	}
+	switch {
+	case err != nil:
+		v.Err = err
+	case ok:
 		v = Value{Text: text, Final: true}
 		s.secrets.Add(text)
 	}
 	if s.seen == nil {
-		s.seen = make(map[string]Value)
+		s.seen = make(map[Ref]Value)
 	}
-	s.seen[key] = v
+	s.seen[ref] = v
 	return v
 }
 
-// Secrets returns every OS value read by this snapshot.
+// Secrets returns every referenced value read by this snapshot.
 func (s *EnvRefs) Secrets() []string {
 	if s == nil {
 		return nil
@@ -92,11 +152,6 @@ func (s *EnvRefs) Secrets() []string {
 	return s.secrets.Values()
 }
 
-// lookupEnv tries the key as-is first, then uppercased, so lowercase variable
-// names can match conventional uppercase OS environment variables.
 func lookupEnv(key string) (string, bool) {
-	if value, ok := os.LookupEnv(key); ok {
-		return value, true
-	}
-	return os.LookupEnv(strings.ToUpper(key))
+	return secret.LookupEnv(key)
 }
@@ -27,7 +27,7 @@ func (e Environment) ResolveWith(refs *EnvRefs) ResolvedEnv {
 	}
 	for _, name := range slices.Sorted(maps.Keys(e.values)) {
 		raw := e.values[name]
-		if _, ok := EnvRefKey(raw); ok {
+		if _, ok := ParseRef(raw); ok {
 			out.refNames = append(out.refNames, name)
 		}
 		v := refs.ResolveDeclared(raw)
@@ -35,6 +35,11 @@ func (e Environment) ResolveWith(refs *EnvRefs) ResolvedEnv {
 		if !v.Missing {
 			out.plain[name] = v.Text
 		}
+		// A reference adds its own value. The rest of an encrypted file is
+		// just as secret.
+		if e.encrypted && !v.Missing && refs != nil {
+			refs.secrets.Add(v.Text)
+		}
 	}
 	return out
 }
@@ -54,7 +59,7 @@ func (r ResolvedEnv) ProviderValues() NameMap[Value] {
 	return r.vals
 }
 
-// Secrets returns every OS value read by this environment.
+// Secrets returns every referenced value read by this environment.
 func (r ResolvedEnv) Secrets() []string {
 	return r.refs.Secrets()
 }
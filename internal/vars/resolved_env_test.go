@@ -1,11 +1,17 @@
 package vars
 
 import (
+	"errors"
 	"fmt"
 	"maps"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/vars/secret"
 )
 
 func authored(refs *EnvRefs, src map[string]string) NameMap[Value] {
@@ -123,14 +129,15 @@ func TestZeroResolvedEnvHasNoReferences(t *testing.T) {
 
 func TestEnvRefsReadEachVariableOnce(t *testing.T) {
 	key := "RESTERM_ENV_REFS_ONCE"
+	ref := Ref{Scheme: "env", Key: key}
 	t.Setenv(key, "first")
 
 	var refs EnvRefs
-	if got := refs.resolve(key); got.Text != "first" || !got.Final {
+	if got := refs.resolve(ref); got.Text != "first" || !got.Final {
 		t.Fatalf("first read = %#v, want the process value marked final", got)
 	}
 	t.Setenv(key, "second")
-	if got := refs.resolve(key); got.Text != "first" {
+	if got := refs.resolve(ref); got.Text != "first" {
 		t.Fatalf("second read = %q, want the captured value", got.Text)
 	}
 	if secrets := refs.Secrets(); len(secrets) != 1 || secrets[0] != "first" {
@@ -190,3 +197,78 @@ func testEnvironment(t *testing.T, name string, values map[string]string) Enviro
 	}
 	return env
 }
+
+func TestResolveReadsSecretProviders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("mounted\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := testEnvironment(t, "dev", map[string]string{
+		"auth.token": "file:" + path,
+		"auth.gone":  "file:" + path + ".missing",
+		"auth.cmd":   "cmd:ttl=0 false",
+	}).Resolve()
+
+	if got := env.Values()["auth.token"]; got != "mounted" {
+		t.Fatalf("auth.token = %q, want the file content", got)
+	}
+	if secrets := env.Secrets(); len(secrets) != 1 || secrets[0] != "mounted" {
+		t.Fatalf("Secrets() = %#v, want the file content", secrets)
+	}
+
+	res := NewResolver(NewValueMapProvider("environment", env.ProviderValues()))
+	_, err := res.ExpandTemplates("{{auth.gone}}")
+	if !errors.Is(err, ErrUndefinedVariable) {
+		t.Fatalf("missing file error = %v, want undefined", err)
+	}
+	_, err = res.ExpandTemplates("{{auth.cmd}}")
+	if err == nil || errors.Is(err, ErrUndefinedVariable) || !strings.Contains(err.Error(), "auth.cmd") {
+		t.Fatalf("failed command error = %v, want the provider failure", err)
+	}
+}
+
+// blockingProvider stands in for cmd: or keyring:, whose reads can block.
+type blockingProvider struct {
+	calls int
+	peek  map[string]string
+}
+
+func (p *blockingProvider) Lookup(key string) (string, bool, error) {
+	p.calls++
+	p.peek[key] = "read-" + key
+	return p.peek[key], true, nil
+}
+
+func (*blockingProvider) Noun() string { return "key" }
+
+func (p *blockingProvider) Peek(key string) (string, bool) {
+	v, ok := p.peek[key]
+	return v, ok
+}
+
+func TestWithheldAndCachedRefsNeverReadTheProvider(t *testing.T) {
+	p := &blockingProvider{peek: map[string]string{}}
+	secret.Register("slowtest", p)
+	src := testEnvironment(t, "dev", map[string]string{"token": "slowtest:api"})
+
+	if env := src.ResolveWith(WithheldEnvRefs()); p.calls != 0 {
+		t.Fatalf("withheld resolve read the provider %d times", p.calls)
+	} else if _, ok := env.Values()["token"]; ok {
+		t.Fatal("withheld resolve exposed the value")
+	}
+	if env := src.ResolveWith(CachedEnvRefs(nil)); p.calls != 0 || len(env.Secrets()) != 0 {
+		t.Fatalf("cached resolve before any run: calls=%d secrets=%v", p.calls, env.Secrets())
+	}
+
+	_ = src.Resolve()
+	if p.calls != 1 {
+		t.Fatalf("send-time resolve calls = %d, want 1", p.calls)
+	}
+	env := src.ResolveWith(CachedEnvRefs(nil))
+	if p.calls != 1 {
+		t.Fatal("cached resolve read the provider again")
+	}
+	if got := env.Secrets(); len(got) != 1 || got[0] != "read-api" {
+		t.Fatalf("cached Secrets() = %v, want the value the run read", got)
+	}
+}
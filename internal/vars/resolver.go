@@ -21,6 +21,8 @@ type Value struct {
 	Missing bool
 	// Final prevents another round of template expansion.
 	Final bool
+	// Err explains a Missing value that a secret provider failed to read.
+	Err error
 }
 
 // ValueProvider returns structured provider results.
@@ -157,6 +159,9 @@ func (r *Resolver) resolve(
 	}
 	if hit.val.Missing {
 		r.traceHit(name, hit, "", true)
+		if hit.val.Err != nil {
+			return "", false, fmt.Errorf("%s: %w", name, hit.val.Err)
+		}
 		return "", false, nil
 	}
 
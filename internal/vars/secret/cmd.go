@@ -0,0 +1,169 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/duration"
+	"github.com/unkn0wn-root/resterm/internal/extedit"
+)
+
+const (
+	// DefaultCommandTTL is how long cmd: output is reused. Every request
+	// resolves the environment, and tools like op or vault are too slow, or
+	// prompt too often, to run each time.
+	DefaultCommandTTL = 5 * time.Minute
+	commandTimeout    = 30 * time.Second
+	maxCommandOutput  = 64 << 10
+)
+
+// cmdProvider runs a command without a shell and caches its stdout. A key may
+// start with ttl=DURATION to override DefaultCommandTTL. ttl=0 runs the command
+// every time. The last output of each command is kept apart from the cache so
+// redaction can still find it after the ttl ran out.
+type cmdProvider struct {
+	mu    sync.Mutex
+	cache map[string]cmdEntry
+	last  map[string]string
+	now   func() time.Time
+	run   func(ctx context.Context, argv []string) (string, error)
+}
+
+type cmdEntry struct {
+	value   string
+	expires time.Time
+}
+
+func newCmdProvider() *cmdProvider {
+	return &cmdProvider{
+		cache: make(map[string]cmdEntry),
+		last:  make(map[string]string),
+		now:   time.Now,
+		run:   runCommand,
+	}
+}
+
+func (*cmdProvider) Noun() string { return "command" }
+
+func (p *cmdProvider) Lookup(key string) (string, bool, error) {
+	ttl, argv, err := commandArgs(key)
+	if err != nil {
+		return "", false, err
+	}
+
+	cacheKey := strings.Join(argv, "\x00")
+	if v, ok := p.cached(cacheKey); ok {
+		return v, true, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	out, err := p.run(ctx, argv)
+	if err != nil {
+		return "", false, err
+	}
+	p.mu.Lock()
+	if p.last == nil {
+		p.last = make(map[string]string)
+	}
+	p.last[cacheKey] = out
+	if ttl > 0 {
+		p.cache[cacheKey] = cmdEntry{value: out, expires: p.now().Add(ttl)}
+	}
+	p.mu.Unlock()
+	return out, true, nil
+}
+
+// Peek returns the last output of the command, however old, and never runs
+// it.
+func (p *cmdProvider) Peek(key string) (string, bool) {
+	_, argv, err := commandArgs(key)
+	if err != nil {
+		return "", false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	v, ok := p.last[strings.Join(argv, "\x00")]
+	return v, ok
+}
+
+func (p *cmdProvider) cached(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.cache[key]
+	if !ok {
+		return "", false
+	}
+	if !p.now().Before(e.expires) {
+		delete(p.cache, key)
+		return "", false
+	}
+	return e.value, true
+}
+
+func commandArgs(key string) (time.Duration, []string, error) {
+	ttl, line, err := commandTTL(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	argv, err := extedit.Split(line)
+	if err != nil {
+		return 0, nil, diag.WrapAsf(diag.ClassConfig, err, "cmd:%s", line)
+	}
+	if len(argv) == 0 {
+		return 0, nil, diag.New(diag.ClassConfig, "cmd: reference is missing a command")
+	}
+	return ttl, argv, nil
+}
+
+func commandTTL(key string) (time.Duration, string, error) {
+	head, rest, _ := strings.Cut(key, " ")
+	raw, ok := strings.CutPrefix(head, "ttl=")
+	if !ok {
+		return DefaultCommandTTL, key, nil
+	}
+	ttl, ok := duration.Parse(raw)
+	if !ok || ttl < 0 {
+		return 0, "", diag.Newf(diag.ClassConfig, "cmd: invalid ttl %q", raw)
+	}
+	return ttl, strings.TrimSpace(rest), nil
+}
+
+func runCommand(ctx context.Context, argv []string) (string, error) {
+	name := filepath.Base(argv[0])
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "", diag.Newf(diag.ClassTimeout, "cmd: %q timed out after %s", name, commandTimeout)
+	case err != nil:
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			msg := strings.TrimSpace(stderr.String())
+			if msg != "" {
+				msg = ": " + msg
+			}
+			return "", diag.Newf(
+				diag.ClassConfig,
+				"cmd: %q exited with status %d%s",
+				name,
+				exitErr.ExitCode(),
+				msg,
+			)
+		}
+		return "", diag.WrapAsf(diag.ClassConfig, err, "cmd: run %q", name)
+	case stdout.Len() > maxCommandOutput:
+		return "", diag.Newf(diag.ClassConfig, "cmd: %q printed more than 64 KiB", name)
+	}
+	return trimNewline(stdout.String()), nil
+}
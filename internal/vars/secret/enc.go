@@ -0,0 +1,48 @@
+package secret
+
+import (
+	"sync"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/envcrypt"
+)
+
+// encProvider decrypts enc: values. A passphrase costs about a second of
+// scrypt per value, and the environment is resolved for every request, so
+// plaintext is kept in memory for the life of the process.
+type encProvider struct {
+	mu    sync.Mutex
+	plain map[string]string
+	keys  func() (envcrypt.Keys, error)
+}
+
+func newEncProvider() *encProvider {
+	return &encProvider{plain: make(map[string]string), keys: envcrypt.LoadKeys}
+}
+
+func (*encProvider) Noun() string { return "ciphertext" }
+
+func (p *encProvider) Peek(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	v, ok := p.plain[key]
+	return v, ok
+}
+
+func (p *encProvider) Lookup(key string) (string, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if v, ok := p.plain[key]; ok {
+		return v, true, nil
+	}
+	keys, err := p.keys()
+	if err != nil {
+		return "", false, err
+	}
+	v, err := envcrypt.DecryptValue(key, keys)
+	if err != nil {
+		return "", false, diag.WrapAs(diag.ClassConfig, err, "decrypt enc: value")
+	}
+	p.plain[key] = v
+	return v, true, nil
+}
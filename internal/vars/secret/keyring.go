@@ -0,0 +1,72 @@
+package secret
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+)
+
+// keyringProvider reads the OS keyring. A key is either service/account, the
+// pair most tools store (secret-tool, go-keyring, Python keyring), or explicit
+// attributes such as service=api,username=ci. A read can open an unlock
+// prompt, so values are kept for the life of the process and a changed item
+// is picked up on the next start.
+type keyringProvider struct {
+	mu    sync.Mutex
+	cache map[string]string
+	read  func(key string) (string, bool, error)
+}
+
+func newKeyringProvider() *keyringProvider {
+	return &keyringProvider{cache: make(map[string]string), read: readKeyring}
+}
+
+func (*keyringProvider) Noun() string { return "service name" }
+
+func (p *keyringProvider) Lookup(key string) (string, bool, error) {
+	if v, ok := p.Peek(key); ok {
+		return v, true, nil
+	}
+	v, ok, err := p.read(key)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	p.mu.Lock()
+	p.cache[key] = v
+	p.mu.Unlock()
+	return v, true, nil
+}
+
+func (p *keyringProvider) Peek(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	v, ok := p.cache[key]
+	return v, ok
+}
+
+func keyringAttrs(key string) (map[string]string, error) {
+	if !strings.Contains(key, "=") {
+		service, account, _ := strings.Cut(key, "/")
+		service = strings.TrimSpace(service)
+		if service == "" {
+			return nil, diag.Newf(diag.ClassConfig, "keyring:%s is missing a service name", key)
+		}
+		attrs := map[string]string{"service": service}
+		if account = strings.TrimSpace(account); account != "" {
+			attrs["username"] = account
+		}
+		return attrs, nil
+	}
+
+	attrs := make(map[string]string)
+	for pair := range strings.SplitSeq(key, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, diag.Newf(diag.ClassConfig, "keyring:%s: expected attr=value, got %q", key, pair)
+		}
+		attrs[name] = strings.TrimSpace(value)
+	}
+	return attrs, nil
+}
@@ -0,0 +1,142 @@
+//go:build linux
+
+package secret
+
+import (
+	"context"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+)
+
+const (
+	secretDest    = "org.freedesktop.secrets"
+	secretPath    = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretService = "org.freedesktop.Secret.Service"
+	secretItem    = "org.freedesktop.Secret.Item"
+	secretPrompt  = "org.freedesktop.Secret.Prompt"
+	secretSession = "org.freedesktop.Secret.Session"
+
+	keyringTimeout = 10 * time.Second
+	// unlockTimeout leaves time to type the keyring password into the
+	// desktop prompt.
+	unlockTimeout = 2 * time.Minute
+)
+
+// secretValue is the Secret struct (oayays) of the Secret Service API.
+type secretValue struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// readKeyring talks to the Secret Service (GNOME Keyring, KWallet, KeePassXC)
+// over the session bus. The plain session algorithm is enough because the bus
+// is local to the user.
+func readKeyring(key string) (string, bool, error) {
+	attrs, err := keyringAttrs(key)
+	if err != nil {
+		return "", false, err
+	}
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return "", false, diag.WrapAs(diag.ClassConfig, err, "keyring: connect to the session bus")
+	}
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyringTimeout)
+	defer cancel()
+	svc := conn.Object(secretDest, secretPath)
+
+	var (
+		ignored dbus.Variant
+		session dbus.ObjectPath
+	)
+	err = svc.CallWithContext(ctx, secretService+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&ignored, &session)
+	if err != nil {
+		return "", false, diag.WrapAs(diag.ClassConfig, err, "keyring: open secret service session")
+	}
+	defer conn.Object(secretDest, session).Call(secretSession+".Close", 0)
+
+	var unlocked, locked []dbus.ObjectPath
+	err = svc.CallWithContext(ctx, secretService+".SearchItems", 0, attrs).Store(&unlocked, &locked)
+	if err != nil {
+		return "", false, diag.WrapAs(diag.ClassConfig, err, "keyring: search items")
+	}
+	if len(unlocked) == 0 && len(locked) > 0 {
+		unlocked, err = unlockItems(conn, locked)
+		if err != nil {
+			return "", false, err
+		}
+	}
+	if len(unlocked) == 0 {
+		return "", false, nil
+	}
+
+	var sec secretValue
+	err = conn.Object(secretDest, unlocked[0]).
+		CallWithContext(ctx, secretItem+".GetSecret", 0, session).
+		Store(&sec)
+	if err != nil {
+		return "", false, diag.WrapAs(diag.ClassConfig, err, "keyring: read secret")
+	}
+	return string(sec.Value), true, nil
+}
+
+// unlockItems asks the service to unlock the items. When the collection needs
+// a password the service returns a prompt object, and the answer arrives as
+// its Completed signal.
+func unlockItems(conn *dbus.Conn, items []dbus.ObjectPath) ([]dbus.ObjectPath, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+	defer cancel()
+
+	var (
+		unlocked []dbus.ObjectPath
+		prompt   dbus.ObjectPath
+	)
+	err := conn.Object(secretDest, secretPath).
+		CallWithContext(ctx, secretService+".Unlock", 0, items).
+		Store(&unlocked, &prompt)
+	if err != nil {
+		return nil, diag.WrapAs(diag.ClassConfig, err, "keyring: unlock")
+	}
+	if prompt == "/" {
+		return unlocked, nil
+	}
+
+	if err := conn.AddMatchSignalContext(
+		ctx,
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(secretPrompt),
+		dbus.WithMatchMember("Completed"),
+	); err != nil {
+		return nil, diag.WrapAs(diag.ClassConfig, err, "keyring: watch unlock prompt")
+	}
+	signals := make(chan *dbus.Signal, 1)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	if err := conn.Object(secretDest, prompt).CallWithContext(ctx, secretPrompt+".Prompt", 0, "").Err; err != nil {
+		return nil, diag.WrapAs(diag.ClassConfig, err, "keyring: unlock prompt")
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, diag.Newf(diag.ClassTimeout, "keyring: unlock prompt timed out after %s", unlockTimeout)
+		case sig := <-signals:
+			if sig == nil || sig.Path != prompt || len(sig.Body) < 2 {
+				continue
+			}
+			if dismissed, _ := sig.Body[0].(bool); dismissed {
+				return nil, diag.New(diag.ClassConfig, "keyring: unlock was dismissed")
+			}
+			result, _ := sig.Body[1].(dbus.Variant)
+			paths, _ := result.Value().([]dbus.ObjectPath)
+			return paths, nil
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package secret
+
+import "github.com/unkn0wn-root/resterm/internal/diag"
+
+func readKeyring(key string) (string, bool, error) {
+	if _, err := keyringAttrs(key); err != nil {
+		return "", false, err
+	}
+	return "", false, diag.New(
+		diag.ClassConfig,
+		"keyring: references use the Secret Service API, which is only available on Linux",
+	)
+}
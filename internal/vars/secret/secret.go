@@ -0,0 +1,181 @@
+// Package secret resolves the references a declaration can hold instead of a
+// literal value: env:NAME, file:PATH, cmd:COMMAND, keyring:ITEM and enc:DATA
+// for values encrypted with resterm env encrypt. Each scheme is a Provider.
+// Whatever a provider returns is a secret, and callers redact it like any
+// other.
+package secret
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/unkn0wn-root/resterm/internal/diag"
+	"github.com/unkn0wn-root/resterm/internal/util"
+)
+
+// Provider reads the value a reference names. ok is false when nothing is
+// stored under key, which leaves the variable undefined like a missing OS
+// variable. An error means the provider could not answer and is shown to the
+// user.
+type Provider interface {
+	Lookup(key string) (value string, ok bool, err error)
+	// Noun says what a key identifies, for "missing a ..." messages.
+	Noun() string
+}
+
+// Peeker is a Provider that can answer from what an earlier Lookup read,
+// without running a command or asking the keyring. Redaction on the UI
+// goroutine uses it so it never blocks.
+type Peeker interface {
+	Peek(key string) (value string, ok bool)
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{
+		"env":     envProvider{},
+		"file":    fileProvider{},
+		"cmd":     newCmdProvider(),
+		"keyring": newKeyringProvider(),
+		"enc":     newEncProvider(),
+	}
+)
+
+// Register installs p for scheme, replacing a built-in of the same name.
+// Schemes are matched case-insensitively.
+func Register(scheme string, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[strings.ToLower(scheme)] = p
+}
+
+// Get returns the provider registered for scheme.
+func Get(scheme string) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[strings.ToLower(scheme)]
+	return p, ok
+}
+
+// Schemes lists the registered schemes in order.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]string, 0, len(providers))
+	for scheme := range providers {
+		out = append(out, scheme)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Parse splits a reference into its lowercased scheme and trimmed key. Text
+// whose prefix is not a registered scheme is not a reference. An empty key is
+// still a reference, so callers can report it.
+func Parse(raw string) (scheme, key string, ok bool) {
+	trimmed := strings.TrimSpace(raw)
+	head, rest, found := strings.Cut(trimmed, ":")
+	if !found || head == "" {
+		return "", "", false
+	}
+	scheme = strings.ToLower(head)
+	if _, ok := Get(scheme); !ok {
+		return "", "", false
+	}
+	key = strings.TrimSpace(rest)
+	// file:///path is a URL people store as data, not a secret mount.
+	if scheme == "file" && strings.HasPrefix(key, "//") {
+		return "", "", false
+	}
+	return scheme, key, true
+}
+
+// Lookup reads key through the provider registered for scheme.
+func Lookup(scheme, key string) (string, bool, error) {
+	p, ok := Get(scheme)
+	if !ok {
+		return "", false, diag.Newf(diag.ClassConfig, "unknown secret provider %q", scheme)
+	}
+	return p.Lookup(key)
+}
+
+// Peek reads key only if the provider for scheme can do so without blocking.
+// Providers that are not Peekers report nothing.
+func Peek(scheme, key string) (string, bool) {
+	p, ok := Get(scheme)
+	if !ok {
+		return "", false
+	}
+	if pk, ok := p.(Peeker); ok {
+		return pk.Peek(key)
+	}
+	return "", false
+}
+
+type envProvider struct{}
+
+func (envProvider) Lookup(key string) (string, bool, error) {
+	v, ok := LookupEnv(key)
+	return v, ok, nil
+}
+
+func (envProvider) Noun() string { return "variable name" }
+
+func (envProvider) Peek(key string) (string, bool) { return LookupEnv(key) }
+
+// LookupEnv tries the key as-is first, then uppercased, so lowercase variable
+// names can match conventional uppercase OS environment variables.
+func LookupEnv(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	return os.LookupEnv(strings.ToUpper(key))
+}
+
+// fileProvider reads secret mounts such as /run/secrets/token. A missing file
+// is an undefined value, like a missing OS variable.
+type fileProvider struct{}
+
+// maxFileBytes keeps a mistyped path from loading a large file into every
+// request.
+const maxFileBytes = 1 << 20
+
+func (fileProvider) Lookup(key string) (string, bool, error) {
+	path := util.ExpandHome(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, diag.WrapAsf(diag.ClassFilesystem, err, "file:%s", key)
+	}
+	if info.IsDir() {
+		return "", false, diag.Newf(diag.ClassFilesystem, "file:%s is a directory", key)
+	}
+	if info.Size() > maxFileBytes {
+		return "", false, diag.Newf(diag.ClassFilesystem, "file:%s is larger than 1 MiB", key)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, diag.WrapAsf(diag.ClassFilesystem, err, "file:%s", key)
+	}
+	return trimNewline(string(data)), true, nil
+}
+
+func (fileProvider) Noun() string { return "path" }
+
+// Peek reads the file again. A local read does not block the way a command
+// or a keyring prompt can.
+func (p fileProvider) Peek(key string) (string, bool) {
+	v, ok, err := p.Lookup(key)
+	return v, ok && err == nil
+}
+
+// Secret files and command output usually end with one newline that is not
+// part of the value.
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
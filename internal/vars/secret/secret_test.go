@@ -0,0 +1,191 @@
+package secret
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRecognizesRegisteredSchemes(t *testing.T) {
+	for raw, want := range map[string][2]string{
+		"env:TOKEN":                {"env", "TOKEN"},
+		" FILE: /run/secrets/api ": {"file", "/run/secrets/api"},
+		"cmd:pass show api/token":  {"cmd", "pass show api/token"},
+		"keyring:resterm/ci":       {"keyring", "resterm/ci"},
+		"cmd:":                     {"cmd", ""},
+	} {
+		scheme, key, ok := Parse(raw)
+		if !ok || scheme != want[0] || key != want[1] {
+			t.Errorf("Parse(%q) = %q, %q, %t, want %q, %q", raw, scheme, key, ok, want[0], want[1])
+		}
+	}
+	for _, raw := range []string{"plain", "https://example.com", "file:///tmp/x", "vault:x", ":x", ""} {
+		if scheme, key, ok := Parse(raw); ok {
+			t.Errorf("Parse(%q) = %q, %q, want plain text", raw, scheme, key)
+		}
+	}
+}
+
+func TestRegisterAddsAScheme(t *testing.T) {
+	Register("test-secret", fakeProvider{"value"})
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(providers, "test-secret")
+		mu.Unlock()
+	})
+	got, ok, err := Lookup("TEST-SECRET", "x")
+	if err != nil || !ok || got != "value" {
+		t.Fatalf("Lookup = %q, %t, %v", got, ok, err)
+	}
+}
+
+type fakeProvider struct{ value string }
+
+func (p fakeProvider) Lookup(string) (string, bool, error) { return p.value, true, nil }
+func (fakeProvider) Noun() string                          { return "key" }
+
+func TestFileProviderTrimsOneNewline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("s3cret\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := Lookup("file", path)
+	if err != nil || !ok || got != "s3cret\n" {
+		t.Fatalf("Lookup = %q, %t, %v", got, ok, err)
+	}
+	if _, ok, err := Lookup("file", filepath.Join(dir, "missing")); ok || err != nil {
+		t.Fatalf("missing file = %t, %v, want undefined without error", ok, err)
+	}
+	if _, _, err := Lookup("file", dir); err == nil {
+		t.Fatal("a directory was read as a secret")
+	}
+}
+
+func TestCmdProviderCachesUntilTTL(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	calls := 0
+	p := &cmdProvider{
+		cache: make(map[string]cmdEntry),
+		now:   func() time.Time { return now },
+		run: func(_ context.Context, argv []string) (string, error) {
+			calls++
+			return strings.Join(argv, "|"), nil
+		},
+	}
+
+	got, ok, err := p.Lookup(`ttl=1m op read "op://vault/api token"`)
+	if err != nil || !ok || got != "op|read|op://vault/api token" {
+		t.Fatalf("Lookup = %q, %t, %v", got, ok, err)
+	}
+	_, _, _ = p.Lookup(`ttl=1m op read "op://vault/api token"`)
+	if calls != 1 {
+		t.Fatalf("calls = %d, want the cached value reused", calls)
+	}
+	now = now.Add(time.Minute)
+	_, _, _ = p.Lookup(`ttl=1m op read "op://vault/api token"`)
+	if calls != 2 {
+		t.Fatalf("calls = %d, want a rerun after the ttl", calls)
+	}
+
+	_, _, _ = p.Lookup("ttl=0 date")
+	_, _, _ = p.Lookup("ttl=0 date")
+	if calls != 4 {
+		t.Fatalf("calls = %d, want ttl=0 to skip the cache", calls)
+	}
+	if _, _, err := p.Lookup("ttl=soon date"); err == nil {
+		t.Fatal("an invalid ttl was accepted")
+	}
+}
+
+func TestRunCommandReportsFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	got, err := runCommand(context.Background(), []string{"sh", "-c", "printf 'tok\\n'"})
+	if err != nil || got != "tok" {
+		t.Fatalf("runCommand = %q, %v", got, err)
+	}
+	_, err = runCommand(context.Background(), []string{"sh", "-c", "echo locked >&2; exit 3"})
+	if err == nil || !strings.Contains(err.Error(), "status 3: locked") {
+		t.Fatalf("runCommand error = %v, want status and stderr", err)
+	}
+}
+
+func TestKeyringAttrs(t *testing.T) {
+	tests := map[string]map[string]string{
+		"resterm/ci":                   {"service": "resterm", "username": "ci"},
+		"resterm":                      {"service": "resterm"},
+		"service=api, username=ci":     {"service": "api", "username": "ci"},
+		"application=resterm,env=prod": {"application": "resterm", "env": "prod"},
+	}
+	for key, want := range tests {
+		got, err := keyringAttrs(key)
+		if err != nil {
+			t.Fatalf("keyringAttrs(%q): %v", key, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("keyringAttrs(%q) = %v, want %v", key, got, want)
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Fatalf("keyringAttrs(%q) = %v, want %v", key, got, want)
+			}
+		}
+	}
+	for _, key := range []string{"/ci", "service=api,=x"} {
+		if _, err := keyringAttrs(key); err == nil {
+			t.Errorf("keyringAttrs(%q) succeeded", key)
+		}
+	}
+}
+
+func TestCmdProviderPeekNeverRuns(t *testing.T) {
+	calls := 0
+	p := &cmdProvider{
+		cache: make(map[string]cmdEntry),
+		now:   time.Now,
+		run: func(context.Context, []string) (string, error) {
+			calls++
+			return "tok", nil
+		},
+	}
+	if _, ok := p.Peek("ttl=0 op read x"); ok || calls != 0 {
+		t.Fatalf("Peek before a run = %t, calls = %d", ok, calls)
+	}
+	_, _, _ = p.Lookup("ttl=0 op read x")
+	if v, ok := p.Peek("op read x"); !ok || v != "tok" || calls != 1 {
+		t.Fatalf("Peek after a run = %q, %t, calls = %d", v, ok, calls)
+	}
+}
+
+func TestKeyringProviderKeepsValuesForTheSession(t *testing.T) {
+	calls := 0
+	p := &keyringProvider{
+		cache: make(map[string]string),
+		read: func(key string) (string, bool, error) {
+			calls++
+			if key == "absent" {
+				return "", false, nil
+			}
+			return "pw", true, nil
+		},
+	}
+	for range 2 {
+		if v, ok, err := p.Lookup("resterm/ci"); v != "pw" || !ok || err != nil {
+			t.Fatalf("Lookup = %q, %t, %v", v, ok, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("keyring reads = %d, want one per session", calls)
+	}
+	_, _, _ = p.Lookup("absent")
+	_, _, _ = p.Lookup("absent")
+	if calls != 3 {
+		t.Fatalf("keyring reads = %d, want a missing item asked again", calls)
+	}
+}
@@ -41,10 +41,11 @@ type Selection struct {
 // Environment is an immutable selected environment. Its accessors return maps
 // that callers must not modify. Resolve captures env: references for execution.
 type Environment struct {
-	values map[string]string
-	label  string
-	scope  string
-	sel    Selection
+	values    map[string]string
+	label     string
+	scope     string
+	sel       Selection
+	encrypted bool
 }
 
 func GroupSelection(profiles map[string]string) Selection {
@@ -165,10 +166,11 @@ func (c Catalog) Resolve(sel Selection) (Environment, error) {
 			values = collapseNames(env.values)
 		}
 		return Environment{
-			values: values,
-			label:  next.name,
-			scope:  flatScope(next.name, c.source),
-			sel:    next,
+			values:    values,
+			label:     next.name,
+			scope:     flatScope(next.name, c.source),
+			sel:       next,
+			encrypted: c.encrypted,
 		}, nil
 	}
 
@@ -181,10 +183,11 @@ func (c Catalog) Resolve(sel Selection) (Environment, error) {
 		parts = append(parts, g.Name+"="+p)
 	}
 	return Environment{
-		values: mergeValues(layers...),
-		label:  strings.Join(parts, ", "),
-		scope:  groupScope(c.groups, next, c.source),
-		sel:    next,
+		values:    mergeValues(layers...),
+		label:     strings.Join(parts, ", "),
+		scope:     groupScope(c.groups, next, c.source),
+		sel:       next,
+		encrypted: c.encrypted,
 	}, nil
 }
 